@@ -27,7 +27,7 @@ func (finder commandFinder) FindCommand(input []string) CommandFinderResult {
 	commandFound := false
 	for index, word := range input {
 		if !strings.HasPrefix(word, "-") {
-			if previousCommand != "--state-dir" && previousCommand != "-state-dir" {
+			if !takesValue(previousCommand) {
 				commandIndex = index
 				commandFound = true
 				break
@@ -46,3 +46,12 @@ func (finder commandFinder) FindCommand(input []string) CommandFinderResult {
 
 	return commandFinderResult
 }
+
+func takesValue(flag string) bool {
+	switch flag {
+	case "--state-dir", "-state-dir", "--output", "-output":
+		return true
+	}
+
+	return false
+}
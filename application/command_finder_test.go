@@ -22,6 +22,9 @@ var _ = Describe("CommandFinder", func() {
 		Entry("parses the first non-hyphenated word as the state-dir if it directly follows state-dir",
 			[]string{"-state-dir", "help", "delete-errthing", "--other-flag"},
 			application.CommandFinderResult{GlobalFlags: []string{"-state-dir", "help"}, Command: "delete-errthing", OtherArgs: []string{"--other-flag"}}),
+		Entry("parses the first non-hyphenated word as the output format if it directly follows output",
+			[]string{"--output", "json", "lbs", "--other-flag"},
+			application.CommandFinderResult{GlobalFlags: []string{"--output", "json"}, Command: "lbs", OtherArgs: []string{"--other-flag"}}),
 		Entry("parses the first non-hyphenated word as the attempted command if --state-dir=x is provided",
 			[]string{"--state-dir=some-dir", "help", "--other-flag"},
 			application.CommandFinderResult{GlobalFlags: []string{"--state-dir=some-dir"}, Command: "help", OtherArgs: []string{"--other-flag"}}),
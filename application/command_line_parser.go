@@ -17,6 +17,9 @@ type CommandLineConfiguration struct {
 	EndpointOverride string
 	StateDir         string
 	Debug            bool
+	Output           string
+
+	SkipDirectorSSLValidation bool
 
 	help    bool
 	version bool
@@ -88,6 +91,8 @@ func (c CommandLineParser) parseGlobalFlags(commandLineConfiguration CommandLine
 	globalFlags.String(&commandLineConfiguration.EndpointOverride, "endpoint-override", "")
 	globalFlags.String(&commandLineConfiguration.StateDir, "state-dir", "")
 	globalFlags.Bool(&commandLineConfiguration.Debug, "d", "debug", false)
+	globalFlags.String(&commandLineConfiguration.Output, "output", "text")
+	globalFlags.Bool(&commandLineConfiguration.SkipDirectorSSLValidation, "", "skip-director-ssl-validation", false)
 
 	globalFlags.Bool(&commandLineConfiguration.help, "h", "help", false)
 	globalFlags.Bool(&commandLineConfiguration.version, "v", "version", false)
@@ -97,6 +102,12 @@ func (c CommandLineParser) parseGlobalFlags(commandLineConfiguration CommandLine
 		return CommandLineConfiguration{}, []string{}, err
 	}
 
+	switch commandLineConfiguration.Output {
+	case "text", "json", "yaml":
+	default:
+		return CommandLineConfiguration{}, []string{}, fmt.Errorf("Invalid usage: unknown output format '%s', valid options are \"text\", \"json\" or \"yaml\".", commandLineConfiguration.Output)
+	}
+
 	return commandLineConfiguration, globalFlags.Args(), nil
 }
 
@@ -48,6 +48,45 @@ var _ = Describe("CommandLineParser", func() {
 			Expect(commandLineConfiguration.Debug).To(BeTrue())
 		})
 
+		It("parses the skip director ssl validation flag", func() {
+			commandLineConfiguration, err := commandLineParser.Parse([]string{"--skip-director-ssl-validation", "up"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(commandLineConfiguration.SkipDirectorSSLValidation).To(BeTrue())
+		})
+
+		It("validates the director certificate by default", func() {
+			commandLineConfiguration, err := commandLineParser.Parse([]string{"up"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(commandLineConfiguration.SkipDirectorSSLValidation).To(BeFalse())
+		})
+
+		It("defaults the output format to text", func() {
+			commandLineConfiguration, err := commandLineParser.Parse([]string{"up"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(commandLineConfiguration.Output).To(Equal("text"))
+		})
+
+		DescribeTable("parses the output format", func(arguments string, expectedOutput string) {
+			commandLineConfiguration, err := commandLineParser.Parse(strings.Split(arguments, " "))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(commandLineConfiguration.Output).To(Equal(expectedOutput))
+			Expect(commandLineConfiguration.Command).To(Equal("up"))
+		},
+			Entry("json with spaces", "--output json up", "json"),
+			Entry("yaml with equal signs", "--output=yaml up", "yaml"),
+			Entry("text", "--output text up", "text"),
+		)
+
+		It("returns an error when the output format is unknown", func() {
+			_, err := commandLineParser.Parse([]string{"--output", "xml", "up"})
+			Expect(err).To(MatchError(`Invalid usage: unknown output format 'xml', valid options are "text", "json" or "yaml".`))
+			Expect(usageCallCount).To(Equal(1))
+		})
+
 		It("returns a command line configuration with correct command with subcommand flags based on arguments passed in", func() {
 			args := []string{
 				"up",
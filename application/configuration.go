@@ -6,6 +6,9 @@ type GlobalConfiguration struct {
 	EndpointOverride string
 	StateDir         string
 	Debug            bool
+	Output           string
+
+	SkipDirectorSSLValidation bool
 }
 
 type StringSlice []string
@@ -33,6 +33,9 @@ func (p ConfigurationParser) Parse(arguments []string) (Configuration, error) {
 			StateDir:         commandLineConfiguration.StateDir,
 			EndpointOverride: commandLineConfiguration.EndpointOverride,
 			Debug:            commandLineConfiguration.Debug,
+			Output:           commandLineConfiguration.Output,
+
+			SkipDirectorSSLValidation: commandLineConfiguration.SkipDirectorSSLValidation,
 		},
 		Command:         commandLineConfiguration.Command,
 		SubcommandFlags: commandLineConfiguration.SubcommandFlags,
@@ -38,6 +38,9 @@ var _ = Describe("ConfigurationParser", func() {
 				StateDir:         "some/state/dir",
 				EndpointOverride: "some-endpoint-override",
 				Debug:            true,
+				Output:           "json",
+
+				SkipDirectorSSLValidation: true,
 			}
 			configuration, err := configurationParser.Parse([]string{"up"})
 			Expect(err).NotTo(HaveOccurred())
@@ -48,6 +51,9 @@ var _ = Describe("ConfigurationParser", func() {
 				EndpointOverride: "some-endpoint-override",
 				StateDir:         "some/state/dir",
 				Debug:            true,
+				Output:           "json",
+
+				SkipDirectorSSLValidation: true,
 			}))
 
 			Expect(commandLineParser.ParseCall.Receives.Arguments).To(Equal([]string{"up"}))
@@ -0,0 +1,209 @@
+package acm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsacm "github.com/aws/aws-sdk-go/service/acm"
+)
+
+// NamePrefix marks the names of certificates that bbl imports into ACM.
+// IAM server certificate names cannot contain a colon, so the prefix tells
+// both kinds of certificates apart in the state. The state keeps an imported
+// certificate as its arn behind the prefix, which tells it apart from the
+// certificates the operator references by their bare arn.
+const NamePrefix = "acm:"
+
+const nameTag = "Name"
+
+type acmClientProvider interface {
+	GetACMClient() Client
+}
+
+// CertificateManager imports lb certificates into ACM and describes them,
+// as well as certificates issued by ACM that are referenced by their arn.
+type CertificateManager struct {
+	acmClientProvider acmClientProvider
+}
+
+func NewCertificateManager(acmClientProvider acmClientProvider) CertificateManager {
+	return CertificateManager{
+		acmClientProvider: acmClientProvider,
+	}
+}
+
+// IsCertificate tells whether the certificate name or arn belongs to ACM.
+func IsCertificate(certificateName string) bool {
+	return strings.HasPrefix(certificateName, NamePrefix) || IsCertificateARN(certificateName)
+}
+
+// IsCertificateARN tells whether the value is the arn of an ACM certificate.
+func IsCertificateARN(value string) bool {
+	parts := strings.SplitN(value, ":", 6)
+	return len(parts) == 6 && parts[0] == "arn" && parts[2] == "acm" && strings.HasPrefix(parts[5], "certificate/")
+}
+
+// CertificateARNRegion returns the region of the arn of an ACM certificate.
+func CertificateARNRegion(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 {
+		return ""
+	}
+	return parts[3]
+}
+
+// Create imports the certificate tagged with certificateName and returns
+// the name the state keeps for it, the arn ACM assigned behind NamePrefix.
+func (c CertificateManager) Create(certificatePath, privateKeyPath, chainPath, certificateName string) (string, error) {
+	if !strings.HasPrefix(certificateName, NamePrefix) {
+		return "", fmt.Errorf("%q is not a valid acm certificate name, it has to start with %q", certificateName, NamePrefix)
+	}
+
+	certificate, err := ioutil.ReadFile(certificatePath)
+	if err != nil {
+		return "", err
+	}
+
+	privateKey, err := ioutil.ReadFile(privateKeyPath)
+	if err != nil {
+		return "", err
+	}
+
+	input := &awsacm.ImportCertificateInput{
+		Certificate: certificate,
+		PrivateKey:  privateKey,
+		Tags: []*awsacm.Tag{{
+			Key:   aws.String(nameTag),
+			Value: aws.String(certificateName),
+		}},
+	}
+
+	if chainPath != "" {
+		input.CertificateChain, err = ioutil.ReadFile(chainPath)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	output, err := c.acmClientProvider.GetACMClient().ImportCertificate(input)
+	if err != nil {
+		return "", err
+	}
+
+	return NamePrefix + aws.StringValue(output.CertificateArn), nil
+}
+
+// Describe describes a certificate imported by bbl by its name or any ACM
+// certificate by its arn. Only issued certificates can be attached to lbs.
+func (c CertificateManager) Describe(certificateName string) (iam.Certificate, error) {
+	client := c.acmClientProvider.GetACMClient()
+
+	arn, err := c.arn(client, certificateName)
+	if err != nil {
+		return iam.Certificate{}, err
+	}
+
+	description, err := client.DescribeCertificate(&awsacm.DescribeCertificateInput{
+		CertificateArn: aws.String(arn),
+	})
+	if err != nil {
+		return iam.Certificate{}, err
+	}
+
+	if description.Certificate == nil {
+		return iam.Certificate{}, iam.CertificateDescriptionFailure
+	}
+
+	status := aws.StringValue(description.Certificate.Status)
+	if status != awsacm.CertificateStatusIssued {
+		return iam.Certificate{}, fmt.Errorf("acm certificate %q is %s, only issued certificates can be attached to lbs", arn, strings.ToLower(status))
+	}
+
+	output, err := client.GetCertificate(&awsacm.GetCertificateInput{
+		CertificateArn: aws.String(arn),
+	})
+	if err != nil {
+		return iam.Certificate{}, err
+	}
+
+	return iam.Certificate{
+		Name:  certificateName,
+		ARN:   arn,
+		Body:  aws.StringValue(output.Certificate),
+		Chain: aws.StringValue(output.CertificateChain),
+	}, nil
+}
+
+// Delete deletes a certificate imported by bbl. Certificates referenced by
+// their arn belong to the operator and are left alone.
+func (c CertificateManager) Delete(certificateName string) error {
+	if IsCertificateARN(certificateName) {
+		return nil
+	}
+
+	client := c.acmClientProvider.GetACMClient()
+
+	arn, err := c.arn(client, certificateName)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.DeleteCertificate(&awsacm.DeleteCertificateInput{
+		CertificateArn: aws.String(arn),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// arn returns the arn of a certificate referenced by its arn or imported by
+// bbl. Only the certificates imported by earlier bbl versions, which kept
+// the tagged name in the state, are looked up by their Name tag.
+func (c CertificateManager) arn(client Client, certificateName string) (string, error) {
+	if IsCertificateARN(certificateName) {
+		return certificateName, nil
+	}
+
+	if arn := strings.TrimPrefix(certificateName, NamePrefix); IsCertificateARN(arn) {
+		return arn, nil
+	}
+
+	return c.findARN(client, certificateName)
+}
+
+// findARN looks up the arn of an imported certificate by its Name tag.
+func (c CertificateManager) findARN(client Client, certificateName string) (string, error) {
+	input := &awsacm.ListCertificatesInput{}
+	for {
+		output, err := client.ListCertificates(input)
+		if err != nil {
+			return "", err
+		}
+
+		for _, summary := range output.CertificateSummaryList {
+			tags, err := client.ListTagsForCertificate(&awsacm.ListTagsForCertificateInput{
+				CertificateArn: summary.CertificateArn,
+			})
+			if err != nil {
+				return "", err
+			}
+
+			for _, tag := range tags.Tags {
+				if aws.StringValue(tag.Key) == nameTag && aws.StringValue(tag.Value) == certificateName {
+					return aws.StringValue(summary.CertificateArn), nil
+				}
+			}
+		}
+
+		if aws.StringValue(output.NextToken) == "" {
+			return "", iam.CertificateNotFound
+		}
+		input.NextToken = output.NextToken
+	}
+}
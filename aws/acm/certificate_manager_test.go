@@ -0,0 +1,257 @@
+package acm_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws/acm"
+	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsacm "github.com/aws/aws-sdk-go/service/acm"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CertificateManager", func() {
+	const certificateARN = "arn:aws:acm:us-east-1:123456789012:certificate/some-id"
+
+	var (
+		acmClient      *fakes.ACMClient
+		clientProvider *fakes.ClientProvider
+		manager        acm.CertificateManager
+	)
+
+	BeforeEach(func() {
+		acmClient = &fakes.ACMClient{}
+		acmClient.ListCertificatesCall.Returns.Outputs = []*awsacm.ListCertificatesOutput{{
+			CertificateSummaryList: []*awsacm.CertificateSummary{
+				{CertificateArn: aws.String("arn:aws:acm:us-east-1:123456789012:certificate/other-id")},
+				{CertificateArn: aws.String(certificateARN)},
+			},
+		}}
+		acmClient.ListTagsForCertificateCall.Returns.Outputs = []*awsacm.ListTagsForCertificateOutput{
+			{Tags: []*awsacm.Tag{{Key: aws.String("Name"), Value: aws.String("acm:other-certificate")}}},
+			{Tags: []*awsacm.Tag{{Key: aws.String("Name"), Value: aws.String("acm:some-certificate")}}},
+		}
+		acmClient.ImportCertificateCall.Returns.Output = &awsacm.ImportCertificateOutput{
+			CertificateArn: aws.String(certificateARN),
+		}
+		acmClient.DescribeCertificateCall.Returns.Output = &awsacm.DescribeCertificateOutput{
+			Certificate: &awsacm.CertificateDetail{Status: aws.String(awsacm.CertificateStatusIssued)},
+		}
+		acmClient.GetCertificateCall.Returns.Output = &awsacm.GetCertificateOutput{
+			Certificate:      aws.String("some-certificate-body"),
+			CertificateChain: aws.String("some-chain"),
+		}
+
+		clientProvider = &fakes.ClientProvider{}
+		clientProvider.GetACMClientCall.Returns.ACMClient = acmClient
+
+		manager = acm.NewCertificateManager(clientProvider)
+	})
+
+	Describe("Create", func() {
+		var certificatePath, privateKeyPath, chainPath string
+
+		BeforeEach(func() {
+			writeFile := func(contents string) string {
+				file, err := ioutil.TempFile("", "")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = file.WriteString(contents)
+				Expect(err).NotTo(HaveOccurred())
+
+				return file.Name()
+			}
+
+			certificatePath = writeFile("some-certificate-body")
+			privateKeyPath = writeFile("some-private-key")
+			chainPath = writeFile("some-chain")
+		})
+
+		AfterEach(func() {
+			os.Remove(certificatePath)
+			os.Remove(privateKeyPath)
+			os.Remove(chainPath)
+		})
+
+		It("imports the certificate tagged with its name and returns its arn behind the acm prefix", func() {
+			certificateName, err := manager.Create(certificatePath, privateKeyPath, chainPath, "acm:some-certificate")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(certificateName).To(Equal("acm:" + certificateARN))
+
+			Expect(acmClient.ImportCertificateCall.Receives.Input).To(Equal(&awsacm.ImportCertificateInput{
+				Certificate:      []byte("some-certificate-body"),
+				PrivateKey:       []byte("some-private-key"),
+				CertificateChain: []byte("some-chain"),
+				Tags: []*awsacm.Tag{{
+					Key:   aws.String("Name"),
+					Value: aws.String("acm:some-certificate"),
+				}},
+			}))
+		})
+
+		It("imports the certificate without a chain", func() {
+			_, err := manager.Create(certificatePath, privateKeyPath, "", "acm:some-certificate")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(acmClient.ImportCertificateCall.Receives.Input.CertificateChain).To(BeNil())
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the name lacks the acm prefix", func() {
+				_, err := manager.Create(certificatePath, privateKeyPath, chainPath, "some-certificate")
+				Expect(err).To(MatchError(`"some-certificate" is not a valid acm certificate name, it has to start with "acm:"`))
+			})
+
+			It("returns an error when the certificate cannot be read", func() {
+				_, err := manager.Create("/some/missing/path", privateKeyPath, chainPath, "acm:some-certificate")
+				Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+			})
+
+			It("returns an error when the certificate cannot be imported", func() {
+				acmClient.ImportCertificateCall.Returns.Error = errors.New("failed to import")
+
+				_, err := manager.Create(certificatePath, privateKeyPath, chainPath, "acm:some-certificate")
+				Expect(err).To(MatchError("failed to import"))
+			})
+		})
+	})
+
+	Describe("Describe", func() {
+		It("describes an imported certificate by the arn in its name without looking it up", func() {
+			certificate, err := manager.Describe("acm:" + certificateARN)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(acmClient.ListCertificatesCall.CallCount).To(Equal(0))
+			Expect(acmClient.ListTagsForCertificateCall.CallCount).To(Equal(0))
+			Expect(acmClient.DescribeCertificateCall.Receives.Input.CertificateArn).To(Equal(aws.String(certificateARN)))
+			Expect(certificate.Name).To(Equal("acm:" + certificateARN))
+			Expect(certificate.ARN).To(Equal(certificateARN))
+		})
+
+		It("looks up a certificate imported by an earlier bbl version by its name", func() {
+			certificate, err := manager.Describe("acm:some-certificate")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(acmClient.ListTagsForCertificateCall.CallCount).To(Equal(2))
+			Expect(acmClient.DescribeCertificateCall.Receives.Input.CertificateArn).To(Equal(aws.String(certificateARN)))
+			Expect(acmClient.GetCertificateCall.Receives.Input.CertificateArn).To(Equal(aws.String(certificateARN)))
+			Expect(certificate).To(Equal(iam.Certificate{
+				Name:  "acm:some-certificate",
+				ARN:   certificateARN,
+				Body:  "some-certificate-body",
+				Chain: "some-chain",
+			}))
+		})
+
+		It("describes a certificate referenced by its arn", func() {
+			certificate, err := manager.Describe(certificateARN)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(acmClient.ListCertificatesCall.CallCount).To(Equal(0))
+			Expect(certificate.ARN).To(Equal(certificateARN))
+			Expect(certificate.Body).To(Equal("some-certificate-body"))
+		})
+
+		It("follows the pages of certificates", func() {
+			acmClient.ListCertificatesCall.Returns.Outputs = []*awsacm.ListCertificatesOutput{
+				{
+					CertificateSummaryList: []*awsacm.CertificateSummary{{CertificateArn: aws.String("arn:aws:acm:us-east-1:123456789012:certificate/other-id")}},
+					NextToken:              aws.String("some-token"),
+				},
+				{
+					CertificateSummaryList: []*awsacm.CertificateSummary{{CertificateArn: aws.String(certificateARN)}},
+				},
+			}
+
+			certificate, err := manager.Describe("acm:some-certificate")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(acmClient.ListCertificatesCall.Receives.Inputs[1].NextToken).To(Equal(aws.String("some-token")))
+			Expect(certificate.ARN).To(Equal(certificateARN))
+		})
+
+		Context("failure cases", func() {
+			It("returns CertificateNotFound when no certificate has the name", func() {
+				_, err := manager.Describe("acm:missing-certificate")
+				Expect(err).To(MatchError(iam.CertificateNotFound))
+			})
+
+			It("returns an error when the certificate is not issued", func() {
+				acmClient.DescribeCertificateCall.Returns.Output.Certificate.Status = aws.String(awsacm.CertificateStatusPendingValidation)
+
+				_, err := manager.Describe(certificateARN)
+				Expect(err).To(MatchError(`acm certificate "arn:aws:acm:us-east-1:123456789012:certificate/some-id" is pending_validation, only issued certificates can be attached to lbs`))
+			})
+
+			It("returns an error when the certificates cannot be listed", func() {
+				acmClient.ListCertificatesCall.Returns.Error = errors.New("failed to list")
+
+				_, err := manager.Describe("acm:some-certificate")
+				Expect(err).To(MatchError("failed to list"))
+			})
+
+			It("returns an error when the certificate cannot be described", func() {
+				acmClient.DescribeCertificateCall.Returns.Error = errors.New("failed to describe")
+
+				_, err := manager.Describe(certificateARN)
+				Expect(err).To(MatchError("failed to describe"))
+			})
+
+			It("returns an error when the certificate cannot be fetched", func() {
+				acmClient.GetCertificateCall.Returns.Error = errors.New("failed to get")
+
+				_, err := manager.Describe(certificateARN)
+				Expect(err).To(MatchError("failed to get"))
+			})
+		})
+	})
+
+	Describe("Delete", func() {
+		It("deletes an imported certificate by the arn in its name", func() {
+			err := manager.Delete("acm:" + certificateARN)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(acmClient.ListCertificatesCall.CallCount).To(Equal(0))
+			Expect(acmClient.DeleteCertificateCall.Receives.Input.CertificateArn).To(Equal(aws.String(certificateARN)))
+		})
+
+		It("deletes a certificate imported by an earlier bbl version", func() {
+			err := manager.Delete("acm:some-certificate")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(acmClient.DeleteCertificateCall.Receives.Input.CertificateArn).To(Equal(aws.String(certificateARN)))
+		})
+
+		It("leaves a certificate referenced by its arn alone", func() {
+			err := manager.Delete(certificateARN)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(acmClient.DeleteCertificateCall.CallCount).To(Equal(0))
+		})
+
+		It("returns an error when the certificate cannot be deleted", func() {
+			acmClient.DeleteCertificateCall.Returns.Error = errors.New("failed to delete")
+
+			err := manager.Delete("acm:some-certificate")
+			Expect(err).To(MatchError("failed to delete"))
+		})
+	})
+
+	Describe("IsCertificateARN", func() {
+		It("tells acm certificate arns apart", func() {
+			Expect(acm.IsCertificateARN(certificateARN)).To(BeTrue())
+			Expect(acm.IsCertificateARN("arn:aws:iam::123456789012:server-certificate/some-certificate")).To(BeFalse())
+			Expect(acm.IsCertificateARN("acm:some-certificate")).To(BeFalse())
+		})
+
+		It("returns the region of an arn", func() {
+			Expect(acm.CertificateARNRegion(certificateARN)).To(Equal("us-east-1"))
+		})
+	})
+})
@@ -0,0 +1,43 @@
+package acm
+
+import "github.com/cloudfoundry/bosh-bootloader/aws/iam"
+
+type certificateManager interface {
+	Create(certificatePath, privateKeyPath, chainPath, certificateName string) (string, error)
+	Describe(certificateName string) (iam.Certificate, error)
+	Delete(certificateName string) error
+}
+
+// CertificateRouter hands ACM certificate names and arns to the ACM
+// certificate manager and every other name to the IAM one, so that lbs
+// can mix both kinds of certificates.
+type CertificateRouter struct {
+	iamCertificateManager certificateManager
+	acmCertificateManager certificateManager
+}
+
+func NewCertificateRouter(iamCertificateManager, acmCertificateManager certificateManager) CertificateRouter {
+	return CertificateRouter{
+		iamCertificateManager: iamCertificateManager,
+		acmCertificateManager: acmCertificateManager,
+	}
+}
+
+func (c CertificateRouter) Create(certificatePath, privateKeyPath, chainPath, certificateName string) (string, error) {
+	return c.managerFor(certificateName).Create(certificatePath, privateKeyPath, chainPath, certificateName)
+}
+
+func (c CertificateRouter) Describe(certificateName string) (iam.Certificate, error) {
+	return c.managerFor(certificateName).Describe(certificateName)
+}
+
+func (c CertificateRouter) Delete(certificateName string) error {
+	return c.managerFor(certificateName).Delete(certificateName)
+}
+
+func (c CertificateRouter) managerFor(certificateName string) certificateManager {
+	if IsCertificate(certificateName) {
+		return c.acmCertificateManager
+	}
+	return c.iamCertificateManager
+}
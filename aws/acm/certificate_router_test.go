@@ -0,0 +1,66 @@
+package acm_test
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/aws/acm"
+	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CertificateRouter", func() {
+	var (
+		iamCertificateManager *fakes.CertificateManager
+		acmCertificateManager *fakes.CertificateManager
+		router                acm.CertificateRouter
+	)
+
+	BeforeEach(func() {
+		iamCertificateManager = &fakes.CertificateManager{}
+		iamCertificateManager.DescribeCall.Returns.Certificate = iam.Certificate{ARN: "some-iam-arn"}
+		acmCertificateManager = &fakes.CertificateManager{}
+		acmCertificateManager.DescribeCall.Returns.Certificate = iam.Certificate{ARN: "some-acm-arn"}
+
+		router = acm.NewCertificateRouter(iamCertificateManager, acmCertificateManager)
+	})
+
+	It("routes other certificate names to iam", func() {
+		_, err := router.Create("some-cert", "some-key", "some-chain", "some-certificate")
+		Expect(err).NotTo(HaveOccurred())
+
+		certificate, err := router.Describe("some-certificate")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(certificate.ARN).To(Equal("some-iam-arn"))
+
+		err = router.Delete("some-certificate")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(iamCertificateManager.CreateCall.Receives.CertificateName).To(Equal("some-certificate"))
+		Expect(iamCertificateManager.DeleteCall.Receives.CertificateName).To(Equal("some-certificate"))
+		Expect(acmCertificateManager.CreateCall.CallCount).To(Equal(0))
+		Expect(acmCertificateManager.DescribeCall.CallCount).To(Equal(0))
+		Expect(acmCertificateManager.DeleteCall.CallCount).To(Equal(0))
+	})
+
+	It("routes acm certificate names and arns to acm", func() {
+		acmCertificateManager.CreateCall.Returns.CertificateName = "acm:arn:aws:acm:us-east-1:123456789012:certificate/some-id"
+
+		certificateName, err := router.Create("some-cert", "some-key", "some-chain", "acm:some-certificate")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(certificateName).To(Equal("acm:arn:aws:acm:us-east-1:123456789012:certificate/some-id"))
+
+		certificate, err := router.Describe("arn:aws:acm:us-east-1:123456789012:certificate/some-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(certificate.ARN).To(Equal("some-acm-arn"))
+
+		err = router.Delete("acm:some-certificate")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(acmCertificateManager.CreateCall.Receives.CertificateName).To(Equal("acm:some-certificate"))
+		Expect(acmCertificateManager.DeleteCall.Receives.CertificateName).To(Equal("acm:some-certificate"))
+		Expect(iamCertificateManager.CreateCall.CallCount).To(Equal(0))
+		Expect(iamCertificateManager.DescribeCall.CallCount).To(Equal(0))
+		Expect(iamCertificateManager.DeleteCall.CallCount).To(Equal(0))
+	})
+})
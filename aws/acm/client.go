@@ -0,0 +1,21 @@
+package acm
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/aws"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	awsacm "github.com/aws/aws-sdk-go/service/acm"
+)
+
+type Client interface {
+	ImportCertificate(*awsacm.ImportCertificateInput) (*awsacm.ImportCertificateOutput, error)
+	DescribeCertificate(*awsacm.DescribeCertificateInput) (*awsacm.DescribeCertificateOutput, error)
+	GetCertificate(*awsacm.GetCertificateInput) (*awsacm.GetCertificateOutput, error)
+	DeleteCertificate(*awsacm.DeleteCertificateInput) (*awsacm.DeleteCertificateOutput, error)
+	ListCertificates(*awsacm.ListCertificatesInput) (*awsacm.ListCertificatesOutput, error)
+	ListTagsForCertificate(*awsacm.ListTagsForCertificateInput) (*awsacm.ListTagsForCertificateOutput, error)
+}
+
+func NewClient(config aws.Config) Client {
+	return awsacm.New(session.New(config.ClientConfig()))
+}
@@ -0,0 +1,13 @@
+package acm_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestACM(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "aws/acm")
+}
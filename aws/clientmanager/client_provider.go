@@ -2,9 +2,12 @@ package clientmanager
 
 import (
 	"github.com/cloudfoundry/bosh-bootloader/aws"
+	"github.com/cloudfoundry/bosh-bootloader/aws/acm"
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
 	"github.com/cloudfoundry/bosh-bootloader/aws/ec2"
 	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
+	"github.com/cloudfoundry/bosh-bootloader/aws/route53"
+	"github.com/cloudfoundry/bosh-bootloader/aws/sts"
 )
 
 type ClientProvider struct {
@@ -12,6 +15,9 @@ type ClientProvider struct {
 	ec2Client            ec2.Client
 	cloudformationClient cloudformation.Client
 	iamClient            iam.Client
+	route53Client        route53.Client
+	acmClient            acm.Client
+	stsClient            sts.Client
 }
 
 func (c *ClientProvider) SetConfig(config aws.Config) {
@@ -19,6 +25,9 @@ func (c *ClientProvider) SetConfig(config aws.Config) {
 	c.ec2Client = ec2.NewClient(config)
 	c.cloudformationClient = cloudformation.NewClient(config)
 	c.iamClient = iam.NewClient(config)
+	c.route53Client = route53.NewClient(config)
+	c.acmClient = acm.NewClient(config)
+	c.stsClient = sts.NewClient(config)
 }
 
 func (c *ClientProvider) GetEC2Client() ec2.Client {
@@ -32,3 +41,15 @@ func (c *ClientProvider) GetCloudFormationClient() cloudformation.Client {
 func (c *ClientProvider) GetIAMClient() iam.Client {
 	return c.iamClient
 }
+
+func (c *ClientProvider) GetRoute53Client() route53.Client {
+	return c.route53Client
+}
+
+func (c *ClientProvider) GetACMClient() acm.Client {
+	return c.acmClient
+}
+
+func (c *ClientProvider) GetSTSClient() sts.Client {
+	return c.stsClient
+}
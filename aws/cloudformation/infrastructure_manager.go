@@ -2,16 +2,18 @@ package cloudformation
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
 const bblTagKey = "bbl-env-id"
 
 type templateBuilder interface {
-	Build(keypairName string, azs []string, lbType string, lbCertificateARN string, iamUserName string, envID string, boshAZ string) templates.Template
+	Build(keypairName string, azs []string, lbType string, lbCertificateARN string, lbSpecs []storage.LBSpec, iamUserName string, envID string, boshAZ string) templates.Template
 }
 
 type stackManager interface {
@@ -36,7 +38,7 @@ func NewInfrastructureManager(builder templateBuilder, stackManager stackManager
 }
 
 func (m InfrastructureManager) Create(keyPairName string, azs []string, stackName, boshAZ,
-	lbType, lbCertificateARN, envID string) (Stack, error) {
+	lbType, lbCertificateARN string, lbSpecs []storage.LBSpec, envID string, tags map[string]string) (Stack, error) {
 
 	iamUserName := generateIAMUserName(envID)
 
@@ -52,15 +54,9 @@ func (m InfrastructureManager) Create(keyPairName string, azs []string, stackNam
 		}
 	}
 
-	template := m.templateBuilder.Build(keyPairName, azs, lbType, lbCertificateARN, iamUserName, envID, boshAZ)
-	tags := Tags{
-		{
-			Key:   bblTagKey,
-			Value: envID,
-		},
-	}
+	template := m.templateBuilder.Build(keyPairName, azs, lbType, lbCertificateARN, lbSpecs, iamUserName, envID, boshAZ)
 
-	if err := m.stackManager.CreateOrUpdate(stackName, template, tags); err != nil {
+	if err := m.stackManager.CreateOrUpdate(stackName, template, stackTags(envID, tags)); err != nil {
 		return Stack{}, err
 	}
 
@@ -72,16 +68,16 @@ func (m InfrastructureManager) Create(keyPairName string, azs []string, stackNam
 }
 
 func (m InfrastructureManager) Update(keyPairName string, azs []string, stackName, boshAZ, lbType,
-	lbCertificateARN, envID string) (Stack, error) {
+	lbCertificateARN string, lbSpecs []storage.LBSpec, envID string, tags map[string]string) (Stack, error) {
 
 	iamUserName, err := m.stackManager.GetPhysicalIDForResource(stackName, "BOSHUser")
 	if err != nil {
 		return Stack{}, err
 	}
 
-	template := m.templateBuilder.Build(keyPairName, azs, lbType, lbCertificateARN, iamUserName, envID, boshAZ)
+	template := m.templateBuilder.Build(keyPairName, azs, lbType, lbCertificateARN, lbSpecs, iamUserName, envID, boshAZ)
 
-	if err := m.stackManager.Update(stackName, template, Tags{{Key: bblTagKey, Value: envID}}); err != nil {
+	if err := m.stackManager.Update(stackName, template, stackTags(envID, tags)); err != nil {
 		return Stack{}, err
 	}
 
@@ -123,6 +119,24 @@ func (m InfrastructureManager) Delete(stackName string) error {
 	return nil
 }
 
+// stackTags returns the tags of the stack, which cloudformation propagates to
+// the resources of the stack: the env id followed by the tags given to up,
+// sorted by key.
+func stackTags(envID string, tags map[string]string) Tags {
+	var keys []string
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	stackTags := Tags{{Key: bblTagKey, Value: envID}}
+	for _, key := range keys {
+		stackTags = append(stackTags, Tag{Key: key, Value: tags[key]})
+	}
+
+	return stackTags
+}
+
 func generateIAMUserName(envID string) string {
 	return fmt.Sprintf("bosh-iam-user-%s", strings.Replace(envID, ":", "-", -1))
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -52,7 +53,8 @@ var _ = Describe("InfrastructureManager", func() {
 			}
 
 			stack, err := infrastructureManager.Create("some-key-pair-name", azs, "some-stack-name", "some-bosh-az",
-				"some-lb-type", "some-lb-certificate-arn", "some-env-id-time-stamp")
+				"some-lb-type", "some-lb-certificate-arn", []storage.LBSpec{{Name: "some-lb"}}, "some-env-id-time-stamp",
+				map[string]string{"owner": "platform", "cost-center": "1234"})
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(stack).To(Equal(cloudformation.Stack{Name: "some-stack-name"}))
@@ -61,6 +63,7 @@ var _ = Describe("InfrastructureManager", func() {
 			Expect(builder.BuildCall.Receives.BOSHAZ).To(Equal("some-bosh-az"))
 			Expect(builder.BuildCall.Receives.LBType).To(Equal("some-lb-type"))
 			Expect(builder.BuildCall.Receives.LBCertificateARN).To(Equal("some-lb-certificate-arn"))
+			Expect(builder.BuildCall.Receives.LBSpecs).To(Equal([]storage.LBSpec{{Name: "some-lb"}}))
 			Expect(builder.BuildCall.Receives.IAMUserName).To(Equal("bosh-iam-user-some-env-id-time-stamp"))
 			Expect(builder.BuildCall.Receives.EnvID).To(Equal("some-env-id-time-stamp"))
 
@@ -74,6 +77,14 @@ var _ = Describe("InfrastructureManager", func() {
 					Key:   "bbl-env-id",
 					Value: "some-env-id-time-stamp",
 				},
+				{
+					Key:   "cost-center",
+					Value: "1234",
+				},
+				{
+					Key:   "owner",
+					Value: "platform",
+				},
 			}))
 
 			Expect(stackManager.WaitForCompletionCall.Receives.StackName).To(Equal("some-stack-name"))
@@ -87,7 +98,7 @@ var _ = Describe("InfrastructureManager", func() {
 			stackManager.GetPhysicalIDForResourceCall.Returns.PhysicalResourceID = "some-bosh-user-id"
 
 			_, err := infrastructureManager.Create("some-key-pair-name", azs, "some-stack-name", "some-bosh-az",
-				"some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp")
+				"some-lb-type", "some-lb-certificate-arn", nil, "some-env-id-time:stamp", nil)
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(stackManager.GetPhysicalIDForResourceCall.Receives.StackName).To(Equal("some-stack-name"))
@@ -100,14 +111,14 @@ var _ = Describe("InfrastructureManager", func() {
 			It("returns an error when stack can't be created or updated", func() {
 				stackManager.CreateOrUpdateCall.Returns.Error = errors.New("stack create or update failed")
 
-				_, err := infrastructureManager.Create("some-key-pair-name", azs, "some-stack-name", "some-bosh-az", "", "", "")
+				_, err := infrastructureManager.Create("some-key-pair-name", azs, "some-stack-name", "some-bosh-az", "", "", nil, "", nil)
 				Expect(err).To(MatchError("stack create or update failed"))
 			})
 
 			It("returns an error when waiting for stack completion fails", func() {
 				stackManager.WaitForCompletionCall.Returns.Error = errors.New("stack wait for completion failed")
 
-				_, err := infrastructureManager.Create("some-key-pair-name", azs, "some-stack-name", "some-bosh-az", "", "", "")
+				_, err := infrastructureManager.Create("some-key-pair-name", azs, "some-stack-name", "some-bosh-az", "", "", nil, "", nil)
 				Expect(err).To(MatchError("stack wait for completion failed"))
 			})
 
@@ -115,7 +126,7 @@ var _ = Describe("InfrastructureManager", func() {
 				stackManager.GetPhysicalIDForResourceCall.Returns.Error = errors.New("get physical id for resource failed")
 
 				_, err := infrastructureManager.Create("some-key-pair-name", azs, "some-stack-name", "some-bosh-az",
-					"some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp")
+					"some-lb-type", "some-lb-certificate-arn", nil, "some-env-id-time:stamp", nil)
 				Expect(err).To(MatchError("get physical id for resource failed"))
 
 			})
@@ -124,7 +135,7 @@ var _ = Describe("InfrastructureManager", func() {
 				It("returns an error when describing the stack fails", func() {
 					stackManager.DescribeCall.Returns.Error = errors.New("stack describe failed")
 
-					_, err := infrastructureManager.Create("some-key-pair-name", azs, "some-stack-name", "some-bosh-az", "", "", "")
+					_, err := infrastructureManager.Create("some-key-pair-name", azs, "some-stack-name", "some-bosh-az", "", "", nil, "", nil)
 					Expect(err).To(MatchError("stack describe failed"))
 				})
 			})
@@ -140,7 +151,7 @@ var _ = Describe("InfrastructureManager", func() {
 						return cloudformation.Stack{}, errors.New("stack describe failed")
 					}
 
-					_, err := infrastructureManager.Create("some-key-pair-name", azs, "some-stack-name", "some-bosh-az", "", "", "")
+					_, err := infrastructureManager.Create("some-key-pair-name", azs, "some-stack-name", "some-bosh-az", "", "", nil, "", nil)
 					Expect(err).To(MatchError("stack describe failed"))
 				})
 			})
@@ -155,7 +166,7 @@ var _ = Describe("InfrastructureManager", func() {
 		It("updates the stack and returns the stack", func() {
 			stackManager.GetPhysicalIDForResourceCall.Returns.PhysicalResourceID = "some-bosh-user-id"
 
-			stack, err := infrastructureManager.Update("some-key-pair-name", azs, "some-stack-name", "some-bosh-az", "some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp")
+			stack, err := infrastructureManager.Update("some-key-pair-name", azs, "some-stack-name", "some-bosh-az", "some-lb-type", "some-lb-certificate-arn", []storage.LBSpec{{Name: "some-lb"}}, "some-env-id-time:stamp", map[string]string{"owner": "platform"})
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(stackManager.GetPhysicalIDForResourceCall.Receives.StackName).To(Equal("some-stack-name"))
@@ -166,6 +177,7 @@ var _ = Describe("InfrastructureManager", func() {
 			Expect(builder.BuildCall.Receives.AZs).To(Equal(azs))
 			Expect(builder.BuildCall.Receives.LBType).To(Equal("some-lb-type"))
 			Expect(builder.BuildCall.Receives.LBCertificateARN).To(Equal("some-lb-certificate-arn"))
+			Expect(builder.BuildCall.Receives.LBSpecs).To(Equal([]storage.LBSpec{{Name: "some-lb"}}))
 			Expect(builder.BuildCall.Receives.IAMUserName).To(Equal("some-bosh-user-id"))
 			Expect(builder.BuildCall.Receives.EnvID).To(Equal("some-env-id-time:stamp"))
 			Expect(builder.BuildCall.Receives.BOSHAZ).To(Equal("some-bosh-az"))
@@ -180,6 +192,10 @@ var _ = Describe("InfrastructureManager", func() {
 					Key:   "bbl-env-id",
 					Value: "some-env-id-time:stamp",
 				},
+				{
+					Key:   "owner",
+					Value: "platform",
+				},
 			}))
 
 			Expect(stackManager.WaitForCompletionCall.Receives.StackName).To(Equal("some-stack-name"))
@@ -193,21 +209,21 @@ var _ = Describe("InfrastructureManager", func() {
 			It("returns an error when it cannot get physical id for BOSHUser", func() {
 				stackManager.GetPhysicalIDForResourceCall.Returns.Error = errors.New("failed to get physical id for resource")
 
-				_, err := infrastructureManager.Update("some-key-pair-name", azs, "some-stack-name", "some-bosh-az", "some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp")
+				_, err := infrastructureManager.Update("some-key-pair-name", azs, "some-stack-name", "some-bosh-az", "some-lb-type", "some-lb-certificate-arn", nil, "some-env-id-time:stamp", nil)
 				Expect(err).To(MatchError("failed to get physical id for resource"))
 			})
 
 			It("returns an error when the update stack call fails", func() {
 				stackManager.UpdateCall.Returns.Error = errors.New("stack update call failed")
 
-				_, err := infrastructureManager.Update("some-key-pair-name", azs, "some-stack-name", "some-bosh-az", "some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp")
+				_, err := infrastructureManager.Update("some-key-pair-name", azs, "some-stack-name", "some-bosh-az", "some-lb-type", "some-lb-certificate-arn", nil, "some-env-id-time:stamp", nil)
 				Expect(err).To(MatchError("stack update call failed"))
 			})
 
 			It("returns an error when the wait for completion call fails", func() {
 				stackManager.WaitForCompletionCall.Returns.Error = errors.New("failed to wait for completion")
 
-				_, err := infrastructureManager.Update("some-key-pair-name", azs, "some-stack-name", "some-bosh-az", "some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp")
+				_, err := infrastructureManager.Update("some-key-pair-name", azs, "some-stack-name", "some-bosh-az", "some-lb-type", "some-lb-certificate-arn", nil, "some-env-id-time:stamp", nil)
 				Expect(err).To(MatchError("failed to wait for completion"))
 			})
 		})
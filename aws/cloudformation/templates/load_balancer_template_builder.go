@@ -1,6 +1,12 @@
 package templates
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
 
 type LoadBalancerTemplateBuilder struct{}
 
@@ -136,6 +142,309 @@ func (l LoadBalancerTemplateBuilder) ConcourseLoadBalancer(numberOfAvailabilityZ
 	}
 }
 
+// SpecLoadBalancer builds the load balancer of a spec, a classic ELB unless
+// the flavor of the spec is alb or nlb. Secure listeners terminate TLS with
+// the certificate of the spec.
+func (l LoadBalancerTemplateBuilder) SpecLoadBalancer(numberOfAvailabilityZones int, spec storage.LBSpec) Template {
+	if !isClassicLBFlavor(spec.Flavor) {
+		return l.targetGroupLoadBalancer(numberOfAvailabilityZones, spec)
+	}
+
+	prefix := LBSpecResourcePrefix(spec.Name)
+	loadBalancerName := LBSpecLoadBalancerName(spec)
+
+	listeners := []Listener{}
+	for _, port := range spec.Ports {
+		listener := Listener{
+			Protocol:         port.Protocol,
+			LoadBalancerPort: strconv.Itoa(port.Port),
+			InstanceProtocol: l.instanceProtocol(port.Protocol),
+			InstancePort:     strconv.Itoa(port.InstancePort),
+		}
+		if port.Protocol == "https" || port.Protocol == "ssl" {
+			listener.SSLCertificateID = spec.CertificateARN
+		}
+		listeners = append(listeners, listener)
+	}
+
+	target := fmt.Sprintf("%s:%d", spec.HealthCheck.Protocol, spec.HealthCheck.Port)
+	if spec.HealthCheck.Protocol == "http" || spec.HealthCheck.Protocol == "https" {
+		target += spec.HealthCheck.Path
+	}
+
+	return Template{
+		Outputs: l.outputsFor(loadBalancerName),
+		Resources: map[string]Resource{
+			loadBalancerName: {
+				Type:      "AWS::ElasticLoadBalancing::LoadBalancer",
+				DependsOn: "VPCGatewayAttachment",
+				Properties: ElasticLoadBalancingLoadBalancer{
+					CrossZone:      true,
+					Subnets:        l.loadBalancerSubnets(numberOfAvailabilityZones),
+					SecurityGroups: []interface{}{Ref{prefix + "SecurityGroup"}},
+
+					HealthCheck: HealthCheck{
+						HealthyThreshold:   "5",
+						Interval:           "12",
+						Target:             target,
+						Timeout:            "2",
+						UnhealthyThreshold: "2",
+					},
+
+					Listeners: listeners,
+				},
+			},
+		},
+	}
+}
+
+// targetGroupLoadBalancer builds an alb or an nlb with a target group for
+// every instance port of the spec. The vms of the lb register with the
+// target groups through the lb_target_groups of their vm_extension.
+func (l LoadBalancerTemplateBuilder) targetGroupLoadBalancer(numberOfAvailabilityZones int, spec storage.LBSpec) Template {
+	loadBalancerName := LBSpecLoadBalancerName(spec)
+
+	properties := ElasticLoadBalancingV2LoadBalancer{
+		Type:           "application",
+		Scheme:         "internet-facing",
+		Subnets:        l.loadBalancerSubnets(numberOfAvailabilityZones),
+		SecurityGroups: []interface{}{Ref{LBSpecResourcePrefix(spec.Name) + "SecurityGroup"}},
+	}
+	targetProtocol := "HTTP"
+
+	if spec.Flavor == "nlb" {
+		properties.Type = "network"
+		properties.SecurityGroups = nil
+		targetProtocol = "TCP"
+	}
+
+	template := Template{
+		Outputs: map[string]Output{
+			loadBalancerName: {
+				Value: FnGetAtt{[]string{loadBalancerName, "LoadBalancerName"}},
+			},
+			loadBalancerName + "URL": {
+				Value: FnGetAtt{[]string{loadBalancerName, "DNSName"}},
+			},
+		},
+		Resources: map[string]Resource{
+			loadBalancerName: {
+				Type:       "AWS::ElasticLoadBalancingV2::LoadBalancer",
+				DependsOn:  "VPCGatewayAttachment",
+				Properties: properties,
+			},
+		},
+	}
+
+	for _, port := range spec.Ports {
+		targetGroupName := fmt.Sprintf("%sTargetGroup%d", loadBalancerName, port.InstancePort)
+		if _, ok := template.Resources[targetGroupName]; !ok {
+			template.Resources[targetGroupName] = Resource{
+				Type:       "AWS::ElasticLoadBalancingV2::TargetGroup",
+				Properties: l.targetGroup(spec, port.InstancePort, targetProtocol),
+			}
+			template.Outputs[targetGroupName] = Output{
+				Value: FnGetAtt{[]string{targetGroupName, "TargetGroupName"}},
+			}
+		}
+
+		listener := ElasticLoadBalancingV2Listener{
+			LoadBalancerArn: Ref{loadBalancerName},
+			Port:            strconv.Itoa(port.Port),
+			Protocol:        l.listenerProtocol(spec.Flavor, port.Protocol),
+			DefaultActions: []ListenerAction{
+				{Type: "forward", TargetGroupArn: Ref{targetGroupName}},
+			},
+		}
+		listenerName := fmt.Sprintf("%sListener%d", loadBalancerName, port.Port)
+		if port.Protocol == "https" || port.Protocol == "ssl" {
+			listener.Certificates = []ListenerCertificate{{CertificateArn: spec.CertificateARN}}
+
+			// The certificate of the listener is its default, the SNI
+			// certificates are served to clients asking for their names.
+			if len(spec.SNICertificates) > 0 {
+				sniCertificates := ElasticLoadBalancingV2ListenerCertificate{ListenerArn: Ref{listenerName}}
+				for _, certificate := range spec.SNICertificates {
+					sniCertificates.Certificates = append(sniCertificates.Certificates, ListenerCertificate{CertificateArn: certificate.ARN})
+				}
+
+				template.Resources[listenerName+"SNICertificates"] = Resource{
+					Type:       "AWS::ElasticLoadBalancingV2::ListenerCertificate",
+					Properties: sniCertificates,
+				}
+			}
+		}
+
+		template.Resources[listenerName] = Resource{
+			Type:       "AWS::ElasticLoadBalancingV2::Listener",
+			Properties: listener,
+		}
+	}
+
+	return template
+}
+
+func (LoadBalancerTemplateBuilder) targetGroup(spec storage.LBSpec, instancePort int, protocol string) ElasticLoadBalancingV2TargetGroup {
+	targetGroup := ElasticLoadBalancingV2TargetGroup{
+		Port:                       strconv.Itoa(instancePort),
+		Protocol:                   protocol,
+		VpcId:                      Ref{"VPC"},
+		HealthCheckPort:            strconv.Itoa(spec.HealthCheck.Port),
+		HealthCheckIntervalSeconds: "10",
+		HealthyThresholdCount:      "3",
+		UnhealthyThresholdCount:    "3",
+	}
+
+	switch {
+	case spec.HealthCheck.Protocol == "http" || spec.HealthCheck.Protocol == "https":
+		targetGroup.HealthCheckProtocol = strings.ToUpper(spec.HealthCheck.Protocol)
+		targetGroup.HealthCheckPath = spec.HealthCheck.Path
+	case spec.Flavor == "alb":
+		// An alb can only health check over http, so a tcp health check
+		// becomes a request that any answer short of a server error passes.
+		targetGroup.HealthCheckProtocol = "HTTP"
+		targetGroup.HealthCheckPath = "/"
+		targetGroup.Matcher = &TargetGroupMatcher{HttpCode: "200-499"}
+	default:
+		targetGroup.HealthCheckProtocol = "TCP"
+	}
+
+	return targetGroup
+}
+
+func (LoadBalancerTemplateBuilder) listenerProtocol(flavor, protocol string) string {
+	secure := protocol == "https" || protocol == "ssl"
+
+	switch {
+	case flavor == "alb" && secure:
+		return "HTTPS"
+	case flavor == "alb":
+		return "HTTP"
+	case secure:
+		return "TLS"
+	default:
+		return "TCP"
+	}
+}
+
+// LBSpecLoadBalancerName is the resource name of the load balancer of a
+// spec. Albs and nlbs are named apart from classic ELBs so that an lb can
+// keep its previous load balancer while it migrates to another flavor.
+func LBSpecLoadBalancerName(spec storage.LBSpec) string {
+	prefix := LBSpecResourcePrefix(spec.Name)
+
+	switch spec.Flavor {
+	case "alb":
+		return prefix + "ApplicationLoadBalancer"
+	case "nlb":
+		return prefix + "NetworkLoadBalancer"
+	default:
+		return prefix + "LoadBalancer"
+	}
+}
+
+// LBSpecFlavors returns the spec as its current flavor, followed by its
+// previous flavor while it migrates between flavors.
+func LBSpecFlavors(spec storage.LBSpec) []storage.LBSpec {
+	flavors := []storage.LBSpec{spec}
+
+	previous := spec
+	previous.Flavor = spec.PreviousFlavor
+	if spec.PreviousFlavor != "" && LBSpecLoadBalancerName(previous) != LBSpecLoadBalancerName(spec) {
+		flavors = append(flavors, previous)
+	}
+
+	return flavors
+}
+
+func isClassicLBFlavor(flavor string) bool {
+	return flavor == "" || flavor == "elb"
+}
+
+// LBSpecResourcePrefix turns a load balancer spec name like "credhub-uaa"
+// into the prefix of its resource names, "CredhubUaa".
+func LBSpecResourcePrefix(name string) string {
+	var prefix string
+	for _, part := range strings.Split(name, "-") {
+		if part != "" {
+			prefix += strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return prefix
+}
+
+// ExpandLBSpecs replaces the named cf and concourse lbs among the specs
+// with the ELB specs they are made of. A cf lb named "foo" becomes the
+// "foo-router" and "foo-ssh-proxy" ELBs, a concourse lb keeps its name.
+// The ssh proxy of a cf alb is an nlb, albs cannot proxy plain tcp.
+func ExpandLBSpecs(specs []storage.LBSpec) []storage.LBSpec {
+	expanded := []storage.LBSpec{}
+	for _, spec := range specs {
+		switch spec.Type {
+		case "cf":
+			expanded = append(expanded, storage.LBSpec{
+				Name:           spec.Name + "-router",
+				Flavor:         spec.Flavor,
+				PreviousFlavor: spec.PreviousFlavor,
+				Ports: []storage.LBPort{
+					{Port: 80, InstancePort: 80, Protocol: "http"},
+					{Port: 443, InstancePort: 80, Protocol: "https"},
+					{Port: 4443, InstancePort: 80, Protocol: "ssl"},
+				},
+				HealthCheck:     storage.LBHealthCheck{Protocol: "tcp", Port: 80},
+				CertificateName: spec.CertificateName,
+				CertificateARN:  spec.CertificateARN,
+				SNICertificates: spec.SNICertificates,
+			}, storage.LBSpec{
+				Name:           spec.Name + "-ssh-proxy",
+				Flavor:         tcpLBFlavor(spec.Flavor),
+				PreviousFlavor: tcpLBFlavor(spec.PreviousFlavor),
+				Ports: []storage.LBPort{
+					{Port: 2222, InstancePort: 2222, Protocol: "tcp"},
+				},
+				HealthCheck: storage.LBHealthCheck{Protocol: "tcp", Port: 2222},
+			})
+		case "concourse":
+			expanded = append(expanded, storage.LBSpec{
+				Name:           spec.Name,
+				Flavor:         spec.Flavor,
+				PreviousFlavor: spec.PreviousFlavor,
+				Ports: []storage.LBPort{
+					{Port: 80, InstancePort: 8080, Protocol: "tcp"},
+					{Port: 2222, InstancePort: 2222, Protocol: "tcp"},
+					{Port: 443, InstancePort: 8080, Protocol: "ssl"},
+				},
+				HealthCheck:     storage.LBHealthCheck{Protocol: "tcp", Port: 8080},
+				CertificateName: spec.CertificateName,
+				CertificateARN:  spec.CertificateARN,
+				SNICertificates: spec.SNICertificates,
+			})
+		default:
+			expanded = append(expanded, spec)
+		}
+	}
+
+	return expanded
+}
+
+func tcpLBFlavor(flavor string) string {
+	if flavor == "alb" {
+		return "nlb"
+	}
+	return flavor
+}
+
+func (LoadBalancerTemplateBuilder) instanceProtocol(protocol string) string {
+	switch protocol {
+	case "https":
+		return "http"
+	case "ssl":
+		return "tcp"
+	default:
+		return protocol
+	}
+}
+
 func (LoadBalancerTemplateBuilder) outputsFor(loadBalancerName string) map[string]Output {
 	return map[string]Output{
 		loadBalancerName: {Value: Ref{loadBalancerName}},
@@ -2,6 +2,7 @@ package templates_test
 
 import (
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -183,4 +184,326 @@ var _ = Describe("LoadBalancerTemplateBuilder", func() {
 			}))
 		})
 	})
+
+	Describe("SpecLoadBalancer", func() {
+		It("returns a template containing a load balancer for the spec", func() {
+			specLoadBalancer := builder.SpecLoadBalancer(2, storage.LBSpec{
+				Name: "credhub-uaa",
+				Ports: []storage.LBPort{
+					{Port: 8844, InstancePort: 8844, Protocol: "tcp"},
+					{Port: 443, InstancePort: 8443, Protocol: "ssl"},
+					{Port: 80, InstancePort: 8080, Protocol: "http"},
+					{Port: 8443, InstancePort: 8080, Protocol: "https"},
+				},
+				HealthCheck: storage.LBHealthCheck{
+					Protocol: "http",
+					Port:     8080,
+					Path:     "/healthz",
+				},
+				CertificateARN: "some-certificate-arn",
+			})
+
+			Expect(specLoadBalancer.Outputs).To(HaveLen(2))
+			Expect(specLoadBalancer.Outputs).To(HaveKeyWithValue("CredhubUaaLoadBalancer", templates.Output{
+				Value: templates.Ref{"CredhubUaaLoadBalancer"},
+			}))
+			Expect(specLoadBalancer.Outputs).To(HaveKey("CredhubUaaLoadBalancerURL"))
+
+			Expect(specLoadBalancer.Resources).To(HaveLen(1))
+			Expect(specLoadBalancer.Resources).To(HaveKeyWithValue("CredhubUaaLoadBalancer", templates.Resource{
+				Type:      "AWS::ElasticLoadBalancing::LoadBalancer",
+				DependsOn: "VPCGatewayAttachment",
+				Properties: templates.ElasticLoadBalancingLoadBalancer{
+					CrossZone:      true,
+					Subnets:        []interface{}{templates.Ref{"LoadBalancerSubnet1"}, templates.Ref{"LoadBalancerSubnet2"}},
+					SecurityGroups: []interface{}{templates.Ref{"CredhubUaaSecurityGroup"}},
+
+					HealthCheck: templates.HealthCheck{
+						HealthyThreshold:   "5",
+						Interval:           "12",
+						Target:             "http:8080/healthz",
+						Timeout:            "2",
+						UnhealthyThreshold: "2",
+					},
+
+					Listeners: []templates.Listener{
+						{
+							Protocol:         "tcp",
+							LoadBalancerPort: "8844",
+							InstanceProtocol: "tcp",
+							InstancePort:     "8844",
+						},
+						{
+							Protocol:         "ssl",
+							LoadBalancerPort: "443",
+							InstanceProtocol: "tcp",
+							InstancePort:     "8443",
+							SSLCertificateID: "some-certificate-arn",
+						},
+						{
+							Protocol:         "http",
+							LoadBalancerPort: "80",
+							InstanceProtocol: "http",
+							InstancePort:     "8080",
+						},
+						{
+							Protocol:         "https",
+							LoadBalancerPort: "8443",
+							InstanceProtocol: "http",
+							InstancePort:     "8080",
+							SSLCertificateID: "some-certificate-arn",
+						},
+					},
+				},
+			}))
+		})
+
+		It("uses a tcp health check target without a path", func() {
+			specLoadBalancer := builder.SpecLoadBalancer(1, storage.LBSpec{
+				Name:        "vault",
+				Ports:       []storage.LBPort{{Port: 8200, InstancePort: 8200, Protocol: "tcp"}},
+				HealthCheck: storage.LBHealthCheck{Protocol: "tcp", Port: 8200, Path: "/ignored"},
+			})
+
+			properties := specLoadBalancer.Resources["VaultLoadBalancer"].Properties.(templates.ElasticLoadBalancingLoadBalancer)
+			Expect(properties.HealthCheck.Target).To(Equal("tcp:8200"))
+		})
+	})
+
+	Describe("SpecLoadBalancer with the alb flavor", func() {
+		It("returns a template containing an alb with a target group per instance port", func() {
+			specLoadBalancer := builder.SpecLoadBalancer(2, storage.LBSpec{
+				Name:   "credhub",
+				Flavor: "alb",
+				Ports: []storage.LBPort{
+					{Port: 80, InstancePort: 8080, Protocol: "http"},
+					{Port: 443, InstancePort: 8080, Protocol: "https"},
+				},
+				HealthCheck:    storage.LBHealthCheck{Protocol: "https", Port: 8844, Path: "/health"},
+				CertificateARN: "some-certificate-arn",
+			})
+
+			Expect(specLoadBalancer).To(Equal(templates.Template{
+				Outputs: map[string]templates.Output{
+					"CredhubApplicationLoadBalancer": {
+						Value: templates.FnGetAtt{[]string{"CredhubApplicationLoadBalancer", "LoadBalancerName"}},
+					},
+					"CredhubApplicationLoadBalancerURL": {
+						Value: templates.FnGetAtt{[]string{"CredhubApplicationLoadBalancer", "DNSName"}},
+					},
+					"CredhubApplicationLoadBalancerTargetGroup8080": {
+						Value: templates.FnGetAtt{[]string{"CredhubApplicationLoadBalancerTargetGroup8080", "TargetGroupName"}},
+					},
+				},
+				Resources: map[string]templates.Resource{
+					"CredhubApplicationLoadBalancer": {
+						Type:      "AWS::ElasticLoadBalancingV2::LoadBalancer",
+						DependsOn: "VPCGatewayAttachment",
+						Properties: templates.ElasticLoadBalancingV2LoadBalancer{
+							Type:           "application",
+							Scheme:         "internet-facing",
+							Subnets:        []interface{}{templates.Ref{"LoadBalancerSubnet1"}, templates.Ref{"LoadBalancerSubnet2"}},
+							SecurityGroups: []interface{}{templates.Ref{"CredhubSecurityGroup"}},
+						},
+					},
+					"CredhubApplicationLoadBalancerTargetGroup8080": {
+						Type: "AWS::ElasticLoadBalancingV2::TargetGroup",
+						Properties: templates.ElasticLoadBalancingV2TargetGroup{
+							Port:                       "8080",
+							Protocol:                   "HTTP",
+							VpcId:                      templates.Ref{"VPC"},
+							HealthCheckProtocol:        "HTTPS",
+							HealthCheckPort:            "8844",
+							HealthCheckPath:            "/health",
+							HealthCheckIntervalSeconds: "10",
+							HealthyThresholdCount:      "3",
+							UnhealthyThresholdCount:    "3",
+						},
+					},
+					"CredhubApplicationLoadBalancerListener80": {
+						Type: "AWS::ElasticLoadBalancingV2::Listener",
+						Properties: templates.ElasticLoadBalancingV2Listener{
+							LoadBalancerArn: templates.Ref{"CredhubApplicationLoadBalancer"},
+							Port:            "80",
+							Protocol:        "HTTP",
+							DefaultActions: []templates.ListenerAction{
+								{Type: "forward", TargetGroupArn: templates.Ref{"CredhubApplicationLoadBalancerTargetGroup8080"}},
+							},
+						},
+					},
+					"CredhubApplicationLoadBalancerListener443": {
+						Type: "AWS::ElasticLoadBalancingV2::Listener",
+						Properties: templates.ElasticLoadBalancingV2Listener{
+							LoadBalancerArn: templates.Ref{"CredhubApplicationLoadBalancer"},
+							Port:            "443",
+							Protocol:        "HTTPS",
+							Certificates:    []templates.ListenerCertificate{{CertificateArn: "some-certificate-arn"}},
+							DefaultActions: []templates.ListenerAction{
+								{Type: "forward", TargetGroupArn: templates.Ref{"CredhubApplicationLoadBalancerTargetGroup8080"}},
+							},
+						},
+					},
+				},
+			}))
+		})
+
+		It("health checks tcp ports over http, accepting any answer short of a server error", func() {
+			specLoadBalancer := builder.SpecLoadBalancer(1, storage.LBSpec{
+				Name:        "router",
+				Flavor:      "alb",
+				Ports:       []storage.LBPort{{Port: 80, InstancePort: 80, Protocol: "http"}},
+				HealthCheck: storage.LBHealthCheck{Protocol: "tcp", Port: 80},
+			})
+
+			properties := specLoadBalancer.Resources["RouterApplicationLoadBalancerTargetGroup80"].Properties.(templates.ElasticLoadBalancingV2TargetGroup)
+			Expect(properties.HealthCheckProtocol).To(Equal("HTTP"))
+			Expect(properties.HealthCheckPath).To(Equal("/"))
+			Expect(properties.Matcher).To(Equal(&templates.TargetGroupMatcher{HttpCode: "200-499"}))
+		})
+	})
+
+	Describe("SpecLoadBalancer with the nlb flavor", func() {
+		It("returns an nlb without security groups that forwards tcp and terminates tls", func() {
+			specLoadBalancer := builder.SpecLoadBalancer(1, storage.LBSpec{
+				Name:   "vault",
+				Flavor: "nlb",
+				Ports: []storage.LBPort{
+					{Port: 8200, InstancePort: 8200, Protocol: "tcp"},
+					{Port: 443, InstancePort: 8200, Protocol: "ssl"},
+				},
+				HealthCheck:    storage.LBHealthCheck{Protocol: "tcp", Port: 8200},
+				CertificateARN: "some-certificate-arn",
+			})
+
+			properties := specLoadBalancer.Resources["VaultNetworkLoadBalancer"].Properties.(templates.ElasticLoadBalancingV2LoadBalancer)
+			Expect(properties.Type).To(Equal("network"))
+			Expect(properties.SecurityGroups).To(BeNil())
+
+			targetGroup := specLoadBalancer.Resources["VaultNetworkLoadBalancerTargetGroup8200"].Properties.(templates.ElasticLoadBalancingV2TargetGroup)
+			Expect(targetGroup.Protocol).To(Equal("TCP"))
+			Expect(targetGroup.HealthCheckProtocol).To(Equal("TCP"))
+
+			tcpListener := specLoadBalancer.Resources["VaultNetworkLoadBalancerListener8200"].Properties.(templates.ElasticLoadBalancingV2Listener)
+			Expect(tcpListener.Protocol).To(Equal("TCP"))
+			Expect(tcpListener.Certificates).To(BeEmpty())
+
+			tlsListener := specLoadBalancer.Resources["VaultNetworkLoadBalancerListener443"].Properties.(templates.ElasticLoadBalancingV2Listener)
+			Expect(tlsListener.Protocol).To(Equal("TLS"))
+			Expect(tlsListener.Certificates).To(Equal([]templates.ListenerCertificate{{CertificateArn: "some-certificate-arn"}}))
+		})
+	})
+
+	Describe("SpecLoadBalancer with sni certificates", func() {
+		It("attaches the sni certificates to every secure listener", func() {
+			specLoadBalancer := builder.SpecLoadBalancer(1, storage.LBSpec{
+				Name:   "router",
+				Flavor: "alb",
+				Ports: []storage.LBPort{
+					{Port: 80, InstancePort: 80, Protocol: "http"},
+					{Port: 443, InstancePort: 80, Protocol: "https"},
+				},
+				HealthCheck:    storage.LBHealthCheck{Protocol: "tcp", Port: 80},
+				CertificateARN: "some-certificate-arn",
+				SNICertificates: []storage.LBCertificate{
+					{Name: "some-sni-certificate", ARN: "some-sni-certificate-arn"},
+					{Name: "other-sni-certificate", ARN: "other-sni-certificate-arn"},
+				},
+			})
+
+			Expect(specLoadBalancer.Resources).NotTo(HaveKey("RouterApplicationLoadBalancerListener80SNICertificates"))
+			Expect(specLoadBalancer.Resources["RouterApplicationLoadBalancerListener443SNICertificates"]).To(Equal(templates.Resource{
+				Type: "AWS::ElasticLoadBalancingV2::ListenerCertificate",
+				Properties: templates.ElasticLoadBalancingV2ListenerCertificate{
+					ListenerArn: templates.Ref{"RouterApplicationLoadBalancerListener443"},
+					Certificates: []templates.ListenerCertificate{
+						{CertificateArn: "some-sni-certificate-arn"},
+						{CertificateArn: "other-sni-certificate-arn"},
+					},
+				},
+			}))
+		})
+	})
+
+	Describe("LBSpecFlavors", func() {
+		It("returns the previous flavor of a migrating spec after the current one", func() {
+			spec := storage.LBSpec{Name: "vault", Flavor: "nlb", PreviousFlavor: "elb"}
+
+			Expect(templates.LBSpecFlavors(spec)).To(Equal([]storage.LBSpec{
+				spec,
+				{Name: "vault", Flavor: "elb", PreviousFlavor: "elb"},
+			}))
+		})
+
+		It("ignores a previous flavor that builds the same load balancer", func() {
+			spec := storage.LBSpec{Name: "vault", Flavor: "", PreviousFlavor: "elb"}
+
+			Expect(templates.LBSpecFlavors(spec)).To(Equal([]storage.LBSpec{spec}))
+		})
+	})
+
+	Describe("LBSpecResourcePrefix", func() {
+		It("camel cases the spec name", func() {
+			Expect(templates.LBSpecResourcePrefix("vault")).To(Equal("Vault"))
+			Expect(templates.LBSpecResourcePrefix("credhub-uaa")).To(Equal("CredhubUaa"))
+		})
+	})
+
+	Describe("ExpandLBSpecs", func() {
+		It("replaces named cf and concourse lbs with the elb specs they are made of", func() {
+			vault := storage.LBSpec{
+				Name:        "vault",
+				Ports:       []storage.LBPort{{Port: 8200, InstancePort: 8200, Protocol: "tcp"}},
+				HealthCheck: storage.LBHealthCheck{Protocol: "tcp", Port: 8200},
+			}
+
+			specs := templates.ExpandLBSpecs([]storage.LBSpec{
+				vault,
+				{Name: "foundation", Type: "cf", CertificateName: "some-cert-name", CertificateARN: "some-cert-arn"},
+				{Name: "ci", Type: "concourse", CertificateName: "other-cert-name", CertificateARN: "other-cert-arn"},
+			})
+
+			Expect(specs).To(Equal([]storage.LBSpec{
+				vault,
+				{
+					Name: "foundation-router",
+					Ports: []storage.LBPort{
+						{Port: 80, InstancePort: 80, Protocol: "http"},
+						{Port: 443, InstancePort: 80, Protocol: "https"},
+						{Port: 4443, InstancePort: 80, Protocol: "ssl"},
+					},
+					HealthCheck:     storage.LBHealthCheck{Protocol: "tcp", Port: 80},
+					CertificateName: "some-cert-name",
+					CertificateARN:  "some-cert-arn",
+				},
+				{
+					Name:        "foundation-ssh-proxy",
+					Ports:       []storage.LBPort{{Port: 2222, InstancePort: 2222, Protocol: "tcp"}},
+					HealthCheck: storage.LBHealthCheck{Protocol: "tcp", Port: 2222},
+				},
+				{
+					Name: "ci",
+					Ports: []storage.LBPort{
+						{Port: 80, InstancePort: 8080, Protocol: "tcp"},
+						{Port: 2222, InstancePort: 2222, Protocol: "tcp"},
+						{Port: 443, InstancePort: 8080, Protocol: "ssl"},
+					},
+					HealthCheck:     storage.LBHealthCheck{Protocol: "tcp", Port: 8080},
+					CertificateName: "other-cert-name",
+					CertificateARN:  "other-cert-arn",
+				},
+			}))
+		})
+
+		It("puts the ssh proxy of a cf alb behind an nlb", func() {
+			specs := templates.ExpandLBSpecs([]storage.LBSpec{
+				{Name: "foundation", Type: "cf", Flavor: "alb", PreviousFlavor: "elb"},
+			})
+
+			Expect(specs).To(HaveLen(2))
+			Expect(specs[0].Flavor).To(Equal("alb"))
+			Expect(specs[0].PreviousFlavor).To(Equal("elb"))
+			Expect(specs[1].Flavor).To(Equal("nlb"))
+			Expect(specs[1].PreviousFlavor).To(Equal("elb"))
+		})
+	})
 })
@@ -1,5 +1,7 @@
 package templates
 
+import "sort"
+
 type SecurityGroupTemplateBuilder struct{}
 
 func NewSecurityGroupTemplateBuilder() SecurityGroupTemplateBuilder {
@@ -10,9 +12,7 @@ func (s SecurityGroupTemplateBuilder) LBSecurityGroup(securityGroupName, securit
 	loadBalancerName string, template Template) Template {
 	securityGroupIngress := []SecurityGroupIngress{}
 
-	properties := template.Resources[loadBalancerName].Properties.(ElasticLoadBalancingLoadBalancer)
-
-	for _, listener := range properties.Listeners {
+	for _, listener := range s.loadBalancerListeners(loadBalancerName, template) {
 		securityGroupIngress = append(securityGroupIngress, s.securityGroupIngress(
 			"0.0.0.0/0",
 			s.determineSecurityGroupProtocol(listener.Protocol),
@@ -43,11 +43,17 @@ func (s SecurityGroupTemplateBuilder) LBInternalSecurityGroup(securityGroupName,
 	securityGroupIngress := []SecurityGroupIngress{}
 	securityGroupPorts := map[string]bool{}
 
-	properties := template.Resources[loadBalancerName].Properties.(ElasticLoadBalancingLoadBalancer)
-	for _, listener := range properties.Listeners {
+	// An nlb has no security group, the vms behind it see its clients.
+	var sourceSecurityGroupID, cidrIP interface{} = Ref{lbSecurityGroupName}, nil
+	if properties, ok := template.Resources[loadBalancerName].Properties.(ElasticLoadBalancingV2LoadBalancer); ok && properties.Type == "network" {
+		sourceSecurityGroupID, cidrIP = nil, "0.0.0.0/0"
+	}
+
+	for _, listener := range s.loadBalancerListeners(loadBalancerName, template) {
 		if !securityGroupPorts[listener.InstancePort] {
 			securityGroupIngress = append(securityGroupIngress, SecurityGroupIngress{
-				SourceSecurityGroupId: Ref{lbSecurityGroupName},
+				SourceSecurityGroupId: sourceSecurityGroupID,
+				CidrIp:                cidrIP,
 				IpProtocol:            s.determineSecurityGroupProtocol(listener.Protocol),
 				FromPort:              listener.InstancePort,
 				ToPort:                listener.InstancePort,
@@ -163,6 +169,40 @@ func (SecurityGroupTemplateBuilder) securityGroupIngress(
 	}
 }
 
+// loadBalancerListeners returns the listeners of a classic ELB, or those of
+// an alb or nlb along with the ports of the target groups they forward to.
+func (SecurityGroupTemplateBuilder) loadBalancerListeners(loadBalancerName string, template Template) []Listener {
+	if properties, ok := template.Resources[loadBalancerName].Properties.(ElasticLoadBalancingLoadBalancer); ok {
+		return properties.Listeners
+	}
+
+	resourceNames := []string{}
+	for name := range template.Resources {
+		resourceNames = append(resourceNames, name)
+	}
+	sort.Strings(resourceNames)
+
+	listeners := []Listener{}
+	for _, name := range resourceNames {
+		listener, ok := template.Resources[name].Properties.(ElasticLoadBalancingV2Listener)
+		if !ok || listener.LoadBalancerArn != (Ref{loadBalancerName}) {
+			continue
+		}
+
+		targetGroupName := listener.DefaultActions[0].TargetGroupArn.(Ref).Ref
+		targetGroup := template.Resources[targetGroupName].Properties.(ElasticLoadBalancingV2TargetGroup)
+
+		listeners = append(listeners, Listener{
+			Protocol:         "tcp",
+			LoadBalancerPort: listener.Port,
+			InstanceProtocol: "tcp",
+			InstancePort:     targetGroup.Port,
+		})
+	}
+
+	return listeners
+}
+
 func (SecurityGroupTemplateBuilder) determineSecurityGroupProtocol(listenerProtocol string) string {
 	switch listenerProtocol {
 	case "ssl":
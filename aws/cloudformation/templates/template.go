@@ -216,3 +216,49 @@ type HealthCheck struct {
 	Timeout            string `json:"Timeout,omitempty"`
 	UnhealthyThreshold string `json:"UnhealthyThreshold,omitempty"`
 }
+
+type ElasticLoadBalancingV2LoadBalancer struct {
+	Type           string        `json:"Type,omitempty"`
+	Scheme         string        `json:"Scheme,omitempty"`
+	Subnets        []interface{} `json:"Subnets,omitempty"`
+	SecurityGroups []interface{} `json:"SecurityGroups,omitempty"`
+}
+
+type ElasticLoadBalancingV2TargetGroup struct {
+	Port                       string              `json:"Port,omitempty"`
+	Protocol                   string              `json:"Protocol,omitempty"`
+	VpcId                      interface{}         `json:"VpcId,omitempty"`
+	HealthCheckProtocol        string              `json:"HealthCheckProtocol,omitempty"`
+	HealthCheckPort            string              `json:"HealthCheckPort,omitempty"`
+	HealthCheckPath            string              `json:"HealthCheckPath,omitempty"`
+	HealthCheckIntervalSeconds string              `json:"HealthCheckIntervalSeconds,omitempty"`
+	HealthyThresholdCount      string              `json:"HealthyThresholdCount,omitempty"`
+	UnhealthyThresholdCount    string              `json:"UnhealthyThresholdCount,omitempty"`
+	Matcher                    *TargetGroupMatcher `json:"Matcher,omitempty"`
+}
+
+type TargetGroupMatcher struct {
+	HttpCode string `json:"HttpCode,omitempty"`
+}
+
+type ElasticLoadBalancingV2Listener struct {
+	LoadBalancerArn interface{}           `json:"LoadBalancerArn,omitempty"`
+	Port            string                `json:"Port,omitempty"`
+	Protocol        string                `json:"Protocol,omitempty"`
+	Certificates    []ListenerCertificate `json:"Certificates,omitempty"`
+	DefaultActions  []ListenerAction      `json:"DefaultActions,omitempty"`
+}
+
+type ElasticLoadBalancingV2ListenerCertificate struct {
+	ListenerArn  interface{}           `json:"ListenerArn,omitempty"`
+	Certificates []ListenerCertificate `json:"Certificates,omitempty"`
+}
+
+type ListenerCertificate struct {
+	CertificateArn string `json:"CertificateArn,omitempty"`
+}
+
+type ListenerAction struct {
+	Type           string      `json:"Type,omitempty"`
+	TargetGroupArn interface{} `json:"TargetGroupArn,omitempty"`
+}
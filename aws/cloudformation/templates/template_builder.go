@@ -1,5 +1,7 @@
 package templates
 
+import "github.com/cloudfoundry/bosh-bootloader/storage"
+
 type logger interface {
 	Step(message string, a ...interface{})
 	Dot()
@@ -15,7 +17,7 @@ func NewTemplateBuilder(logger logger) TemplateBuilder {
 	}
 }
 
-func (t TemplateBuilder) Build(keyPairName string, availablityZones []string, lbType, lbCertificateARN string, iamUserName string, envID string, boshAZ string) Template {
+func (t TemplateBuilder) Build(keyPairName string, availablityZones []string, lbType, lbCertificateARN string, lbSpecs []storage.LBSpec, iamUserName string, envID string, boshAZ string) Template {
 	t.logger.Step("generating cloudformation template")
 
 	boshIAMTemplateBuilder := NewBOSHIAMTemplateBuilder()
@@ -73,5 +75,33 @@ func (t TemplateBuilder) Build(keyPairName string, availablityZones []string, lb
 		)
 	}
 
+	if len(lbSpecs) > 0 {
+		template.Merge(loadBalancerSubnetsTemplateBuilder.LoadBalancerSubnets(availablityZones))
+	}
+
+	for _, spec := range ExpandLBSpecs(lbSpecs) {
+		prefix := LBSpecResourcePrefix(spec.Name)
+
+		// While an lb migrates between flavors both of its load balancers
+		// share its security groups. The nlb, if any, shapes the internal
+		// security group as it has no security group to be let in by.
+		var lbName string
+		var lbTemplate Template
+		for _, flavor := range LBSpecFlavors(spec) {
+			flavorTemplate := loadBalancerTemplateBuilder.SpecLoadBalancer(len(availablityZones), flavor)
+			template.Merge(flavorTemplate)
+
+			if lbName == "" || flavor.Flavor == "nlb" {
+				lbName = LBSpecLoadBalancerName(flavor)
+				lbTemplate = flavorTemplate
+			}
+		}
+
+		template.Merge(
+			securityGroupTemplateBuilder.LBSecurityGroup(prefix+"SecurityGroup", spec.Name, lbName, lbTemplate),
+			securityGroupTemplateBuilder.LBInternalSecurityGroup(prefix+"InternalSecurityGroup", prefix+"SecurityGroup", spec.Name+"-internal", lbName, lbTemplate),
+		)
+	}
+
 	return template
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -33,7 +34,7 @@ var _ = Describe("TemplateBuilder", func() {
 	Describe("Build", func() {
 		Context("concourse elb template", func() {
 			It("builds a cloudformation template", func() {
-				template := builder.Build("keypair-name", azs, "concourse", "", "", "", "")
+				template := builder.Build("keypair-name", azs, "concourse", "", nil, "", "", "")
 				Expect(template.AWSTemplateFormatVersion).To(Equal("2010-09-09"))
 				Expect(template.Description).To(Equal("Infrastructure for a BOSH deployment with a Concourse ELB."))
 
@@ -61,7 +62,7 @@ var _ = Describe("TemplateBuilder", func() {
 
 		Context("cf elb template", func() {
 			It("builds a cloudformation template", func() {
-				template := builder.Build("keypair-name", azs, "cf", "", "", "", "")
+				template := builder.Build("keypair-name", azs, "cf", "", nil, "", "", "")
 				Expect(template.AWSTemplateFormatVersion).To(Equal("2010-09-09"))
 				Expect(template.Description).To(Equal("Infrastructure for a BOSH deployment with a CloudFoundry ELB."))
 
@@ -92,7 +93,7 @@ var _ = Describe("TemplateBuilder", func() {
 
 		Context("no elb template", func() {
 			It("builds a cloudformation template", func() {
-				template := builder.Build("keypair-name", azs, "", "", "", "", "")
+				template := builder.Build("keypair-name", azs, "", "", nil, "", "", "")
 				Expect(template.AWSTemplateFormatVersion).To(Equal("2010-09-09"))
 				Expect(template.Description).To(Equal("Infrastructure for a BOSH deployment."))
 
@@ -122,8 +123,113 @@ var _ = Describe("TemplateBuilder", func() {
 			})
 		})
 
+		Context("lb spec templates", func() {
+			It("builds an elb and security groups for every spec", func() {
+				template := builder.Build("keypair-name", azs, "concourse", "", []storage.LBSpec{
+					{
+						Name:        "vault",
+						Ports:       []storage.LBPort{{Port: 8200, InstancePort: 8200, Protocol: "tcp"}},
+						HealthCheck: storage.LBHealthCheck{Protocol: "tcp", Port: 8200},
+					},
+					{
+						Name:        "credhub-uaa",
+						Ports:       []storage.LBPort{{Port: 8844, InstancePort: 8844, Protocol: "tcp"}},
+						HealthCheck: storage.LBHealthCheck{Protocol: "tcp", Port: 8844},
+					},
+				}, "", "", "")
+
+				Expect(template.Resources).To(HaveKey("LoadBalancerSubnet1"))
+				Expect(template.Resources).To(HaveKey("ConcourseLoadBalancer"))
+				Expect(template.Resources).To(HaveKey("VaultLoadBalancer"))
+				Expect(template.Resources).To(HaveKey("VaultSecurityGroup"))
+				Expect(template.Resources).To(HaveKey("VaultInternalSecurityGroup"))
+				Expect(template.Resources).To(HaveKey("CredhubUaaLoadBalancer"))
+				Expect(template.Resources).To(HaveKey("CredhubUaaSecurityGroup"))
+				Expect(template.Resources).To(HaveKey("CredhubUaaInternalSecurityGroup"))
+				Expect(template.Outputs).To(HaveKey("VaultLoadBalancerURL"))
+				Expect(template.Outputs).To(HaveKey("CredhubUaaInternalSecurityGroup"))
+			})
+
+			It("builds the elbs of named cf and concourse lbs alongside the lb type", func() {
+				template := builder.Build("keypair-name", azs, "cf", "some-cert-arn", []storage.LBSpec{
+					{Name: "foundation", Type: "cf", CertificateARN: "some-foundation-cert-arn"},
+					{Name: "ci", Type: "concourse", CertificateARN: "some-ci-cert-arn"},
+				}, "", "", "")
+
+				Expect(template.Resources).To(HaveKey("CFRouterLoadBalancer"))
+				Expect(template.Resources).To(HaveKey("FoundationRouterLoadBalancer"))
+				Expect(template.Resources).To(HaveKey("FoundationRouterInternalSecurityGroup"))
+				Expect(template.Resources).To(HaveKey("FoundationSshProxyLoadBalancer"))
+				Expect(template.Resources).To(HaveKey("FoundationSshProxyInternalSecurityGroup"))
+				Expect(template.Resources).To(HaveKey("CiLoadBalancer"))
+				Expect(template.Resources).To(HaveKey("CiInternalSecurityGroup"))
+			})
+
+			It("builds albs and nlbs for specs of those flavors", func() {
+				template := builder.Build("keypair-name", azs, "", "", []storage.LBSpec{
+					{Name: "foundation", Type: "cf", Flavor: "alb", CertificateARN: "some-foundation-cert-arn"},
+				}, "", "", "")
+
+				Expect(template.Resources).To(HaveKey("FoundationRouterApplicationLoadBalancer"))
+				Expect(template.Resources).To(HaveKey("FoundationRouterApplicationLoadBalancerTargetGroup80"))
+				Expect(template.Resources).To(HaveKey("FoundationRouterSecurityGroup"))
+				Expect(template.Resources).To(HaveKey("FoundationSshProxyNetworkLoadBalancer"))
+				Expect(template.Resources).To(HaveKey("FoundationSshProxyNetworkLoadBalancerTargetGroup2222"))
+				Expect(template.Resources).NotTo(HaveKey("FoundationRouterLoadBalancer"))
+
+				routerInternal := template.Resources["FoundationRouterInternalSecurityGroup"].Properties.(templates.SecurityGroup)
+				Expect(routerInternal.SecurityGroupIngress).To(Equal([]templates.SecurityGroupIngress{
+					{SourceSecurityGroupId: templates.Ref{"FoundationRouterSecurityGroup"}, IpProtocol: "tcp", FromPort: "80", ToPort: "80"},
+				}))
+
+				sshProxyInternal := template.Resources["FoundationSshProxyInternalSecurityGroup"].Properties.(templates.SecurityGroup)
+				Expect(sshProxyInternal.SecurityGroupIngress).To(Equal([]templates.SecurityGroupIngress{
+					{CidrIp: "0.0.0.0/0", IpProtocol: "tcp", FromPort: "2222", ToPort: "2222"},
+				}))
+			})
+
+			It("keeps the previous load balancer of a spec that migrates between flavors", func() {
+				template := builder.Build("keypair-name", azs, "", "", []storage.LBSpec{
+					{
+						Name:           "vault",
+						Flavor:         "nlb",
+						PreviousFlavor: "elb",
+						Ports:          []storage.LBPort{{Port: 8200, InstancePort: 8200, Protocol: "tcp"}},
+						HealthCheck:    storage.LBHealthCheck{Protocol: "tcp", Port: 8200},
+					},
+				}, "", "", "")
+
+				Expect(template.Resources).To(HaveKey("VaultLoadBalancer"))
+				Expect(template.Resources).To(HaveKey("VaultNetworkLoadBalancer"))
+
+				lbSecurityGroup := template.Resources["VaultSecurityGroup"].Properties.(templates.SecurityGroup)
+				Expect(lbSecurityGroup.SecurityGroupIngress).To(Equal([]templates.SecurityGroupIngress{
+					{CidrIp: "0.0.0.0/0", IpProtocol: "tcp", FromPort: "8200", ToPort: "8200"},
+				}))
+
+				internal := template.Resources["VaultInternalSecurityGroup"].Properties.(templates.SecurityGroup)
+				Expect(internal.SecurityGroupIngress).To(Equal([]templates.SecurityGroupIngress{
+					{CidrIp: "0.0.0.0/0", IpProtocol: "tcp", FromPort: "8200", ToPort: "8200"},
+				}))
+			})
+
+			It("adds the load balancer subnets without an lb type", func() {
+				template := builder.Build("keypair-name", azs, "", "", []storage.LBSpec{
+					{
+						Name:        "vault",
+						Ports:       []storage.LBPort{{Port: 8200, InstancePort: 8200, Protocol: "tcp"}},
+						HealthCheck: storage.LBHealthCheck{Protocol: "tcp", Port: 8200},
+					},
+				}, "", "", "")
+
+				Expect(template.Description).To(Equal("Infrastructure for a BOSH deployment."))
+				Expect(template.Resources).To(HaveKey("LoadBalancerSubnet4"))
+				Expect(template.Resources).To(HaveKey("VaultLoadBalancer"))
+			})
+		})
+
 		It("logs that the cloudformation template is being generated", func() {
-			builder.Build("keypair-name", []string{}, "", "", "", "", "")
+			builder.Build("keypair-name", []string{}, "", "", nil, "", "", "")
 
 			Expect(logger.StepCall.Receives.Message).To(Equal("generating cloudformation template"))
 		})
@@ -131,7 +237,7 @@ var _ = Describe("TemplateBuilder", func() {
 
 	Describe("template marshaling", func() {
 		DescribeTable("marshals template to JSON", func(lbType string, fixture string) {
-			template := builder.Build("keypair-name", azs, lbType, "some-certificate-arn", "bosh-iam-user-some-env-id", "bbl-env-id", "us-east-1a")
+			template := builder.Build("keypair-name", azs, lbType, "some-certificate-arn", nil, "bosh-iam-user-some-env-id", "bbl-env-id", "us-east-1a")
 
 			buf, err := ioutil.ReadFile("fixtures/" + fixture)
 			Expect(err).NotTo(HaveOccurred())
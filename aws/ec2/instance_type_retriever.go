@@ -0,0 +1,52 @@
+package ec2
+
+import (
+	"errors"
+
+	goaws "github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+)
+
+type InstanceTypeRetriever struct {
+	ec2ClientProvider ec2ClientProvider
+}
+
+func NewInstanceTypeRetriever(ec2ClientProvider ec2ClientProvider) InstanceTypeRetriever {
+	return InstanceTypeRetriever{
+		ec2ClientProvider: ec2ClientProvider,
+	}
+}
+
+func (r InstanceTypeRetriever) Retrieve(region string) ([]string, error) {
+	input := &awsec2.DescribeInstanceTypeOfferingsInput{
+		LocationType: goaws.String("region"),
+		Filters: []*awsec2.Filter{{
+			Name:   goaws.String("location"),
+			Values: []*string{goaws.String(region)},
+		}},
+	}
+
+	instanceTypes := []string{}
+	for {
+		output, err := r.ec2ClientProvider.GetEC2Client().DescribeInstanceTypeOfferings(input)
+		if err != nil {
+			return []string{}, err
+		}
+
+		for _, offering := range output.InstanceTypeOfferings {
+			if offering == nil || offering.InstanceType == nil {
+				return []string{}, errors.New("aws returned instance type offering with nil instance type")
+			}
+
+			instanceTypes = append(instanceTypes, *offering.InstanceType)
+		}
+
+		if goaws.StringValue(output.NextToken) == "" {
+			break
+		}
+
+		input.NextToken = output.NextToken
+	}
+
+	return instanceTypes, nil
+}
@@ -0,0 +1,68 @@
+package ec2_test
+
+import (
+	"errors"
+
+	goaws "github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudfoundry/bosh-bootloader/aws/ec2"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InstanceTypeRetriever", func() {
+	var (
+		instanceTypeRetriever ec2.InstanceTypeRetriever
+		ec2Client             *fakes.EC2Client
+		ec2ClientProvider     *fakes.ClientProvider
+	)
+
+	BeforeEach(func() {
+		ec2Client = &fakes.EC2Client{}
+		ec2ClientProvider = &fakes.ClientProvider{}
+		ec2ClientProvider.GetEC2ClientCall.Returns.EC2Client = ec2Client
+		instanceTypeRetriever = ec2.NewInstanceTypeRetriever(ec2ClientProvider)
+	})
+
+	It("fetches the instance types offered in a given region", func() {
+		ec2Client.DescribeInstanceTypeOfferingsCall.Returns.Output = &awsec2.DescribeInstanceTypeOfferingsOutput{
+			InstanceTypeOfferings: []*awsec2.InstanceTypeOffering{
+				{InstanceType: goaws.String("m4.large")},
+				{InstanceType: goaws.String("t2.small")},
+			},
+		}
+
+		instanceTypes, err := instanceTypeRetriever.Retrieve("us-east-1")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(instanceTypes).To(ConsistOf("m4.large", "t2.small"))
+		Expect(ec2Client.DescribeInstanceTypeOfferingsCall.CallCount).To(Equal(1))
+		Expect(ec2Client.DescribeInstanceTypeOfferingsCall.Receives.Input).To(Equal(&awsec2.DescribeInstanceTypeOfferingsInput{
+			LocationType: goaws.String("region"),
+			Filters: []*awsec2.Filter{{
+				Name:   goaws.String("location"),
+				Values: []*string{goaws.String("us-east-1")},
+			}},
+		}))
+	})
+
+	Describe("failure cases", func() {
+		It("returns an error when AWS returns an offering without an instance type", func() {
+			ec2Client.DescribeInstanceTypeOfferingsCall.Returns.Output = &awsec2.DescribeInstanceTypeOfferingsOutput{
+				InstanceTypeOfferings: []*awsec2.InstanceTypeOffering{{}},
+			}
+
+			_, err := instanceTypeRetriever.Retrieve("us-east-1")
+			Expect(err).To(MatchError("aws returned instance type offering with nil instance type"))
+		})
+
+		It("returns an error when describing the instance type offerings fails", func() {
+			ec2Client.DescribeInstanceTypeOfferingsCall.Returns.Error = errors.New("describe failed")
+
+			_, err := instanceTypeRetriever.Retrieve("us-east-1")
+			Expect(err).To(MatchError("describe failed"))
+		})
+	})
+})
@@ -0,0 +1,25 @@
+package ec2
+
+import "github.com/aws/aws-sdk-go/service/ec2"
+
+type KeyPairImporter struct {
+	ec2ClientProvider ec2ClientProvider
+}
+
+func NewKeyPairImporter(ec2ClientProvider ec2ClientProvider) KeyPairImporter {
+	return KeyPairImporter{
+		ec2ClientProvider: ec2ClientProvider,
+	}
+}
+
+func (i KeyPairImporter) Import(keyPair KeyPair) (KeyPair, error) {
+	_, err := i.ec2ClientProvider.GetEC2Client().ImportKeyPair(&ec2.ImportKeyPairInput{
+		KeyName:           &keyPair.Name,
+		PublicKeyMaterial: []byte(keyPair.PublicKey),
+	})
+	if err != nil {
+		return KeyPair{}, err
+	}
+
+	return keyPair, nil
+}
@@ -0,0 +1,61 @@
+package ec2_test
+
+import (
+	"errors"
+
+	goaws "github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudfoundry/bosh-bootloader/aws/ec2"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("KeyPairImporter", func() {
+	var (
+		keyPairImporter ec2.KeyPairImporter
+		ec2Client       *fakes.EC2Client
+		clientProvider  *fakes.ClientProvider
+	)
+
+	BeforeEach(func() {
+		clientProvider = &fakes.ClientProvider{}
+		ec2Client = &fakes.EC2Client{}
+		clientProvider.GetEC2ClientCall.Returns.EC2Client = ec2Client
+		keyPairImporter = ec2.NewKeyPairImporter(clientProvider)
+	})
+
+	Describe("Import", func() {
+		It("imports the public key of the keypair into ec2", func() {
+			keyPair, err := keyPairImporter.Import(ec2.KeyPair{
+				Name:       "keypair-some-env-id",
+				PrivateKey: "some-private-key",
+				PublicKey:  "ssh-rsa some-public-key",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(keyPair).To(Equal(ec2.KeyPair{
+				Name:       "keypair-some-env-id",
+				PrivateKey: "some-private-key",
+				PublicKey:  "ssh-rsa some-public-key",
+			}))
+
+			Expect(ec2Client.ImportKeyPairCall.Receives.Input).To(Equal(&awsec2.ImportKeyPairInput{
+				KeyName:           goaws.String("keypair-some-env-id"),
+				PublicKeyMaterial: []byte("ssh-rsa some-public-key"),
+			}))
+		})
+
+		Context("failure cases", func() {
+			Context("when the import keypair request fails", func() {
+				It("returns an error", func() {
+					ec2Client.ImportKeyPairCall.Returns.Error = errors.New("failed to import keypair")
+
+					_, err := keyPairImporter.Import(ec2.KeyPair{})
+					Expect(err).To(MatchError("failed to import keypair"))
+				})
+			})
+		})
+	})
+})
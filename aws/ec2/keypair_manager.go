@@ -1,15 +1,20 @@
 package ec2
 
 type KeyPairManager struct {
-	creator keypairCreator
-	checker keypairChecker
-	logger  logger
+	creator  keypairCreator
+	importer keypairImporter
+	checker  keypairChecker
+	logger   logger
 }
 
 type keypairCreator interface {
 	Create(keyPairName string) (KeyPair, error)
 }
 
+type keypairImporter interface {
+	Import(keyPair KeyPair) (KeyPair, error)
+}
+
 type keypairChecker interface {
 	HasKeyPair(keypairName string) (bool, error)
 }
@@ -18,11 +23,12 @@ type logger interface {
 	Step(message string, a ...interface{})
 }
 
-func NewKeyPairManager(creator keypairCreator, checker keypairChecker, logger logger) KeyPairManager {
+func NewKeyPairManager(creator keypairCreator, importer keypairImporter, checker keypairChecker, logger logger) KeyPairManager {
 	return KeyPairManager{
-		creator: creator,
-		checker: checker,
-		logger:  logger,
+		creator:  creator,
+		importer: importer,
+		checker:  checker,
+		logger:   logger,
 	}
 }
 
@@ -35,7 +41,14 @@ func (m KeyPairManager) Sync(keypair KeyPair) (KeyPair, error) {
 		return KeyPair{}, err
 	}
 
-	if !hasLocalKeyPair || !hasRemoteKeyPair {
+	if hasLocalKeyPair && !hasRemoteKeyPair && len(keypair.PublicKey) != 0 {
+		m.logger.Step("importing keypair")
+
+		keypair, err = m.importer.Import(keypair)
+		if err != nil {
+			return KeyPair{}, err
+		}
+	} else if !hasLocalKeyPair || !hasRemoteKeyPair {
 		keyPairName := keypair.Name
 		m.logger.Step("creating keypair")
 
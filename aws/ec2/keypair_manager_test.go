@@ -16,6 +16,7 @@ var _ = Describe("KeyPairManager", func() {
 		var (
 			stateKeyPair ec2.KeyPair
 			creator      *fakes.KeyPairCreator
+			importer     *fakes.KeyPairImporter
 			checker      *fakes.KeyPairChecker
 			logger       *fakes.Logger
 			manager      ec2.KeyPairManager
@@ -23,9 +24,10 @@ var _ = Describe("KeyPairManager", func() {
 
 		BeforeEach(func() {
 			creator = &fakes.KeyPairCreator{}
+			importer = &fakes.KeyPairImporter{}
 			checker = &fakes.KeyPairChecker{}
 			logger = &fakes.Logger{}
-			manager = ec2.NewKeyPairManager(creator, checker, logger)
+			manager = ec2.NewKeyPairManager(creator, importer, checker, logger)
 		})
 
 		It("checks if keypair already exists", func() {
@@ -93,7 +95,6 @@ var _ = Describe("KeyPairManager", func() {
 			BeforeEach(func() {
 				stateKeyPair = ec2.KeyPair{
 					Name:       "my-keypair",
-					PublicKey:  "public",
 					PrivateKey: "private",
 				}
 				checker.HasKeyPairCall.Stub = func(name string) (bool, error) {
@@ -108,19 +109,56 @@ var _ = Describe("KeyPairManager", func() {
 			It("creates a keypair", func() {
 				creator.CreateCall.Returns.KeyPair = ec2.KeyPair{
 					Name:       "my-keypair",
-					PublicKey:  "public",
-					PrivateKey: "private",
+					PrivateKey: "new-private",
 				}
 
 				keypair, err := manager.Sync(stateKeyPair)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(keypair).To(Equal(ec2.KeyPair{
 					Name:       "my-keypair",
-					PublicKey:  "public",
-					PrivateKey: "private",
+					PrivateKey: "new-private",
 				}))
 
 				Expect(checker.HasKeyPairCall.CallCount).To(Equal(1))
+				Expect(importer.ImportCall.CallCount).To(Equal(0))
+			})
+
+			Context("when the keypair in the state file has a public key", func() {
+				BeforeEach(func() {
+					stateKeyPair.PublicKey = "public"
+				})
+
+				It("imports the keypair", func() {
+					importer.ImportCall.Returns.KeyPair = ec2.KeyPair{
+						Name:       "my-keypair",
+						PublicKey:  "public",
+						PrivateKey: "private",
+					}
+
+					keypair, err := manager.Sync(stateKeyPair)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(keypair).To(Equal(ec2.KeyPair{
+						Name:       "my-keypair",
+						PublicKey:  "public",
+						PrivateKey: "private",
+					}))
+
+					Expect(importer.ImportCall.Receives.KeyPair).To(Equal(stateKeyPair))
+					Expect(creator.CreateCall.Receives.KeyPairName).To(BeEmpty())
+					Expect(logger.StepCall.Messages).To(ContainSequence([]string{
+						`checking if keypair "my-keypair" exists`,
+						"importing keypair",
+					}))
+				})
+
+				Context("when the keypair cannot be imported", func() {
+					It("returns an error", func() {
+						importer.ImportCall.Returns.Error = errors.New("failed to import key pair")
+
+						_, err := manager.Sync(stateKeyPair)
+						Expect(err).To(MatchError("failed to import key pair"))
+					})
+				})
 			})
 
 			Context("failure cases", func() {
@@ -0,0 +1,31 @@
+package ec2
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// VPCChecker looks for vpcs by the Name tag that terraform gives them.
+type VPCChecker struct {
+	ec2ClientProvider ec2ClientProvider
+}
+
+func NewVPCChecker(ec2ClientProvider ec2ClientProvider) VPCChecker {
+	return VPCChecker{
+		ec2ClientProvider: ec2ClientProvider,
+	}
+}
+
+func (v VPCChecker) Exists(name string) (bool, error) {
+	output, err := v.ec2ClientProvider.GetEC2Client().DescribeVpcs(&awsec2.DescribeVpcsInput{
+		Filters: []*awsec2.Filter{{
+			Name:   aws.String("tag:Name"),
+			Values: []*string{aws.String(name)},
+		}},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return len(output.Vpcs) > 0, nil
+}
@@ -0,0 +1,63 @@
+package ec2_test
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws/ec2"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VPCChecker", func() {
+	var (
+		vpcChecker     ec2.VPCChecker
+		ec2Client      *fakes.EC2Client
+		clientProvider *fakes.ClientProvider
+	)
+
+	BeforeEach(func() {
+		clientProvider = &fakes.ClientProvider{}
+		ec2Client = &fakes.EC2Client{}
+		clientProvider.GetEC2ClientCall.Returns.EC2Client = ec2Client
+		vpcChecker = ec2.NewVPCChecker(clientProvider)
+	})
+
+	Describe("Exists", func() {
+		It("returns true when a vpc has the name", func() {
+			ec2Client.DescribeVpcsCall.Returns.Output = &awsec2.DescribeVpcsOutput{
+				Vpcs: []*awsec2.Vpc{{VpcId: aws.String("some-vpc-id")}},
+			}
+
+			exists, err := vpcChecker.Exists("some-env-id-vpc")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeTrue())
+
+			Expect(ec2Client.DescribeVpcsCall.Receives.Input).To(Equal(&awsec2.DescribeVpcsInput{
+				Filters: []*awsec2.Filter{{
+					Name:   aws.String("tag:Name"),
+					Values: []*string{aws.String("some-env-id-vpc")},
+				}},
+			}))
+		})
+
+		It("returns false when no vpc has the name", func() {
+			ec2Client.DescribeVpcsCall.Returns.Output = &awsec2.DescribeVpcsOutput{}
+
+			exists, err := vpcChecker.Exists("some-env-id-vpc")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+		})
+
+		It("returns an error when the vpcs cannot be described", func() {
+			ec2Client.DescribeVpcsCall.Returns.Error = errors.New("failed to describe vpcs")
+
+			_, err := vpcChecker.Exists("some-env-id-vpc")
+			Expect(err).To(MatchError("failed to describe vpcs"))
+		})
+	})
+})
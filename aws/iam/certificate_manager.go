@@ -33,8 +33,15 @@ func NewCertificateManager(certificateUploader certificateUploader, certificateD
 	}
 }
 
-func (c CertificateManager) Create(certificatePath, privateKeyPath, chainPath, certificateName string) error {
-	return c.certificateUploader.Upload(certificatePath, privateKeyPath, chainPath, certificateName)
+// Create uploads the certificate under certificateName, which is also the
+// name the state keeps.
+func (c CertificateManager) Create(certificatePath, privateKeyPath, chainPath, certificateName string) (string, error) {
+	err := c.certificateUploader.Upload(certificatePath, privateKeyPath, chainPath, certificateName)
+	if err != nil {
+		return "", err
+	}
+
+	return certificateName, nil
 }
 
 func (c CertificateManager) Delete(certificateName string) error {
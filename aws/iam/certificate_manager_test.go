@@ -42,9 +42,9 @@ var _ = Describe("CertificateManager", func() {
 
 	Describe("Create", func() {
 		It("creates the given certificate", func() {
-			certificateName := "certificate-name"
-			err := manager.Create(certificateFile.Name(), privateKeyFile.Name(), chainFile.Name(), certificateName)
+			certificateName, err := manager.Create(certificateFile.Name(), privateKeyFile.Name(), chainFile.Name(), "certificate-name")
 			Expect(err).NotTo(HaveOccurred())
+			Expect(certificateName).To(Equal("certificate-name"))
 
 			Expect(certificateUploader.UploadCall.CallCount).To(Equal(1))
 			Expect(certificateUploader.UploadCall.Receives.CertificatePath).To(Equal(certificateFile.Name()))
@@ -58,7 +58,7 @@ var _ = Describe("CertificateManager", func() {
 				It("returns an error", func() {
 					certificateUploader.UploadCall.Returns.Error = errors.New("upload failed")
 
-					err := manager.Create(certificateFile.Name(), privateKeyFile.Name(), chainFile.Name(), "cert-name")
+					_, err := manager.Create(certificateFile.Name(), privateKeyFile.Name(), chainFile.Name(), "cert-name")
 					Expect(err).To(MatchError("upload failed"))
 				})
 			})
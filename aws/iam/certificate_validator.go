@@ -1,6 +1,9 @@
 package iam
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
@@ -119,9 +122,22 @@ func (CertificateValidator) validateFileAndFormat(propertyName string, flagName
 	return fileData, nil
 }
 
-func (c CertificateValidator) validateCertAndKey(certificate *x509.Certificate, privateKey *rsa.PrivateKey) error {
-	publicKey := certificate.PublicKey.(*rsa.PublicKey)
-	if privateKey.PublicKey.N.Cmp(publicKey.N) != 0 || privateKey.PublicKey.E != publicKey.E {
+// validateCertAndKey checks that the private key belongs to the public key
+// of the certificate.
+func (c CertificateValidator) validateCertAndKey(certificate *x509.Certificate, privateKey crypto.Signer) error {
+	var matches bool
+	switch publicKey := privateKey.Public().(type) {
+	case *rsa.PublicKey:
+		matches = publicKey.Equal(certificate.PublicKey)
+	case *ecdsa.PublicKey:
+		matches = publicKey.Equal(certificate.PublicKey)
+	case ed25519.PublicKey:
+		matches = publicKey.Equal(certificate.PublicKey)
+	default:
+		return fmt.Errorf("unsupported private key type %T", publicKey)
+	}
+
+	if !matches {
 		return errors.New("certificate and key mismatch")
 	}
 
@@ -140,14 +156,40 @@ func (CertificateValidator) validateCertAndChain(certificate *x509.Certificate,
 	return nil
 }
 
-func (CertificateValidator) parsePrivateKey(keyData []byte) (*rsa.PrivateKey, error) {
+// parsePrivateKey parses PKCS#1 rsa, SEC1 ec and PKCS#8 private keys.
+func (CertificateValidator) parsePrivateKey(keyData []byte) (crypto.Signer, error) {
 	pemKeyData, _ := pem.Decode(keyData)
-	privateKey, err := x509.ParsePKCS1PrivateKey(pemKeyData.Bytes)
+
+	if pemKeyData.Type == "ENCRYPTED PRIVATE KEY" {
+		return nil, errors.New("encrypted PKCS#8 private keys are not supported, decrypt the key with `openssl pkcs8 -in <key>` instead")
+	}
+
+	if x509.IsEncryptedPEMBlock(pemKeyData) {
+		return nil, errors.New("private key is encrypted, provide its passphrase with --key-passphrase")
+	}
+
+	var (
+		privateKey interface{}
+		err        error
+	)
+	switch pemKeyData.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(pemKeyData.Bytes)
+	case "EC PRIVATE KEY":
+		privateKey, err = x509.ParseECPrivateKey(pemKeyData.Bytes)
+	default:
+		privateKey, err = x509.ParsePKCS8PrivateKey(pemKeyData.Bytes)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse private key: %s", err)
 	}
 
-	return privateKey, nil
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", privateKey)
+	}
+
+	return signer, nil
 }
 
 func (CertificateValidator) parseCertificate(certificateData []byte) (*x509.Certificate, error) {
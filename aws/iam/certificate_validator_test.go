@@ -1,11 +1,20 @@
 package iam_test
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/big"
 	"os"
+	"time"
 
 	"github.com/cloudfoundry/multierror"
 	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
@@ -165,6 +174,117 @@ var _ = Describe("CertificateValidator", func() {
 			})
 		})
 
+		Context("when the key is not a PKCS#1 rsa key", func() {
+			writeSelfSignedCertificate := func(key crypto.Signer) string {
+				template := &x509.Certificate{
+					SerialNumber: big.NewInt(1),
+					Subject:      pkix.Name{CommonName: "some-common-name"},
+					NotBefore:    time.Now(),
+					NotAfter:     time.Now().Add(time.Hour),
+				}
+
+				der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+				Expect(err).NotTo(HaveOccurred())
+
+				path, err := testhelpers.WriteContentsToTempFile(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+				Expect(err).NotTo(HaveOccurred())
+
+				return path
+			}
+
+			writeKey := func(blockType string, der []byte) string {
+				path, err := testhelpers.WriteContentsToTempFile(string(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})))
+				Expect(err).NotTo(HaveOccurred())
+
+				return path
+			}
+
+			var (
+				ecdsaKey      *ecdsa.PrivateKey
+				ecdsaCertPath string
+			)
+
+			BeforeEach(func() {
+				var err error
+				ecdsaKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+
+				ecdsaCertPath = writeSelfSignedCertificate(ecdsaKey)
+			})
+
+			It("accepts a SEC1 ec key", func() {
+				der, err := x509.MarshalECPrivateKey(ecdsaKey)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = certificateValidator.Validate("some-command-name", ecdsaCertPath, writeKey("EC PRIVATE KEY", der), "")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("accepts a PKCS#8 ec key", func() {
+				der, err := x509.MarshalPKCS8PrivateKey(ecdsaKey)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = certificateValidator.Validate("some-command-name", ecdsaCertPath, writeKey("PRIVATE KEY", der), "")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("accepts a PKCS#8 rsa key", func() {
+				block, _ := pem.Decode([]byte(testhelpers.BBL_KEY))
+				rsaKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+				Expect(err).NotTo(HaveOccurred())
+
+				der, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = certificateValidator.Validate("some-command-name", certFilePath, writeKey("PRIVATE KEY", der), "")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns an error when the ec key does not match the certificate", func() {
+				otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+
+				der, err := x509.MarshalECPrivateKey(otherKey)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = certificateValidator.Validate("some-command-name", ecdsaCertPath, writeKey("EC PRIVATE KEY", der), "")
+				expectedErr := multierror.NewMultiError("some-command-name")
+				expectedErr.Add(errors.New("certificate and key mismatch"))
+				Expect(err).To(Equal(expectedErr))
+			})
+
+			It("returns an error when the key type does not match the certificate", func() {
+				der, err := x509.MarshalECPrivateKey(ecdsaKey)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = certificateValidator.Validate("some-command-name", certFilePath, writeKey("EC PRIVATE KEY", der), "")
+				expectedErr := multierror.NewMultiError("some-command-name")
+				expectedErr.Add(errors.New("certificate and key mismatch"))
+				Expect(err).To(Equal(expectedErr))
+			})
+
+			It("returns an error when the key is encrypted with a passphrase", func() {
+				block, _ := pem.Decode([]byte(testhelpers.BBL_KEY))
+				encryptedBlock, err := x509.EncryptPEMBlock(rand.Reader, block.Type, block.Bytes, []byte("some-passphrase"), x509.PEMCipherAES256)
+				Expect(err).NotTo(HaveOccurred())
+
+				keyPath, err := testhelpers.WriteContentsToTempFile(string(pem.EncodeToMemory(encryptedBlock)))
+				Expect(err).NotTo(HaveOccurred())
+
+				err = certificateValidator.Validate("some-command-name", certFilePath, keyPath, "")
+				expectedErr := multierror.NewMultiError("some-command-name")
+				expectedErr.Add(errors.New("private key is encrypted, provide its passphrase with --key-passphrase"))
+				Expect(err).To(Equal(expectedErr))
+			})
+
+			It("returns an error when the key is an encrypted PKCS#8 key", func() {
+				err := certificateValidator.Validate("some-command-name", certFilePath, writeKey("ENCRYPTED PRIVATE KEY", []byte("some-key")), "")
+				expectedErr := multierror.NewMultiError("some-command-name")
+				expectedErr.Add(errors.New("encrypted PKCS#8 private keys are not supported, decrypt the key with `openssl pkcs8 -in <key>` instead"))
+				Expect(err).To(Equal(expectedErr))
+			})
+		})
+
 		Context("failure cases", func() {
 			It("returns an error when the certificate, key, and chain cannot be read", func() {
 				createTempFile := func() string {
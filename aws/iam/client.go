@@ -11,6 +11,7 @@ type Client interface {
 	UploadServerCertificate(*awsiam.UploadServerCertificateInput) (*awsiam.UploadServerCertificateOutput, error)
 	GetServerCertificate(*awsiam.GetServerCertificateInput) (*awsiam.GetServerCertificateOutput, error)
 	DeleteServerCertificate(*awsiam.DeleteServerCertificateInput) (*awsiam.DeleteServerCertificateOutput, error)
+	SimulatePrincipalPolicy(*awsiam.SimulatePrincipalPolicyInput) (*awsiam.SimulatePolicyResponse, error)
 }
 
 func NewClient(config aws.Config) Client {
@@ -0,0 +1,106 @@
+package iam
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsiam "github.com/aws/aws-sdk-go/service/iam"
+	awssts "github.com/aws/aws-sdk-go/service/sts"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws/sts"
+)
+
+const allowedDecision = "allowed"
+
+type permissionClientProvider interface {
+	GetIAMClient() Client
+	GetSTSClient() sts.Client
+}
+
+type PermissionChecker struct {
+	clientProvider permissionClientProvider
+}
+
+func NewPermissionChecker(clientProvider permissionClientProvider) PermissionChecker {
+	return PermissionChecker{
+		clientProvider: clientProvider,
+	}
+}
+
+// MissingActions simulates the policies of the user or role behind the
+// credentials and returns the given actions they do not allow, sorted.
+func (p PermissionChecker) MissingActions(actions []string) ([]string, error) {
+	identity, err := p.clientProvider.GetSTSClient().GetCallerIdentity(&awssts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	principalARN, err := policySourceARN(aws.StringValue(identity.Arn))
+	if err != nil {
+		return nil, err
+	}
+
+	// The policies of the root user cannot be simulated, it is allowed to
+	// call every action.
+	if principalARN == "" {
+		return nil, nil
+	}
+
+	var (
+		missing []string
+		marker  *string
+	)
+	for {
+		output, err := p.clientProvider.GetIAMClient().SimulatePrincipalPolicy(&awsiam.SimulatePrincipalPolicyInput{
+			PolicySourceArn: aws.String(principalARN),
+			ActionNames:     aws.StringSlice(actions),
+			Marker:          marker,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate the policies of %s: %s", principalARN, err)
+		}
+
+		for _, result := range output.EvaluationResults {
+			if aws.StringValue(result.EvalDecision) != allowedDecision {
+				missing = append(missing, aws.StringValue(result.EvalActionName))
+			}
+		}
+
+		if !aws.BoolValue(output.IsTruncated) {
+			break
+		}
+		marker = output.Marker
+	}
+
+	sort.Strings(missing)
+
+	return missing, nil
+}
+
+// policySourceARN returns the arn of the user or role whose policies apply
+// to the caller. Callers with assumed role credentials are identified by an
+// sts arn, arn:aws:sts::ACCOUNT:assumed-role/ROLE/SESSION, which maps to the
+// iam arn of the role. Roles with a path cannot be told apart this way and
+// are expected at the root path.
+func policySourceARN(callerARN string) (string, error) {
+	parts := strings.SplitN(callerARN, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" {
+		return "", fmt.Errorf("invalid caller arn %q", callerARN)
+	}
+
+	partition, service, account, resource := parts[1], parts[2], parts[4], parts[5]
+
+	switch {
+	case service == "iam" && resource == "root":
+		return "", nil
+	case service == "iam" && strings.HasPrefix(resource, "user/"):
+		return callerARN, nil
+	case service == "sts" && strings.HasPrefix(resource, "assumed-role/"):
+		role := strings.Split(strings.TrimPrefix(resource, "assumed-role/"), "/")[0]
+		return fmt.Sprintf("arn:%s:iam::%s:role/%s", partition, account, role), nil
+	}
+
+	return "", fmt.Errorf("the policies of %s cannot be simulated, only iam users and assumed roles are supported", callerARN)
+}
@@ -0,0 +1,147 @@
+package iam_test
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+
+	awsiam "github.com/aws/aws-sdk-go/service/iam"
+	awssts "github.com/aws/aws-sdk-go/service/sts"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PermissionChecker", func() {
+	var (
+		iamClient      *fakes.IAMClient
+		stsClient      *fakes.STSClient
+		clientProvider *fakes.ClientProvider
+		checker        iam.PermissionChecker
+	)
+
+	BeforeEach(func() {
+		iamClient = &fakes.IAMClient{}
+		stsClient = &fakes.STSClient{}
+		clientProvider = &fakes.ClientProvider{}
+		clientProvider.GetIAMClientCall.Returns.IAMClient = iamClient
+		clientProvider.GetSTSClientCall.Returns.STSClient = stsClient
+
+		stsClient.GetCallerIdentityCall.Returns.Output = &awssts.GetCallerIdentityOutput{
+			Arn: aws.String("arn:aws:iam::123456789012:user/bbl-user"),
+		}
+
+		iamClient.SimulatePrincipalPolicyCall.Returns.Output = &awsiam.SimulatePolicyResponse{
+			EvaluationResults: []*awsiam.EvaluationResult{
+				{EvalActionName: aws.String("ec2:RunInstances"), EvalDecision: aws.String("allowed")},
+				{EvalActionName: aws.String("route53:CreateHostedZone"), EvalDecision: aws.String("implicitDeny")},
+				{EvalActionName: aws.String("ec2:CreateVpc"), EvalDecision: aws.String("explicitDeny")},
+			},
+		}
+
+		checker = iam.NewPermissionChecker(clientProvider)
+	})
+
+	Describe("MissingActions", func() {
+		It("simulates the policies of the caller and returns the actions they deny", func() {
+			missing, err := checker.MissingActions([]string{"ec2:RunInstances", "ec2:CreateVpc", "route53:CreateHostedZone"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(missing).To(Equal([]string{"ec2:CreateVpc", "route53:CreateHostedZone"}))
+
+			Expect(stsClient.GetCallerIdentityCall.CallCount).To(Equal(1))
+			Expect(iamClient.SimulatePrincipalPolicyCall.Receives.Inputs).To(Equal([]*awsiam.SimulatePrincipalPolicyInput{
+				{
+					PolicySourceArn: aws.String("arn:aws:iam::123456789012:user/bbl-user"),
+					ActionNames:     aws.StringSlice([]string{"ec2:RunInstances", "ec2:CreateVpc", "route53:CreateHostedZone"}),
+				},
+			}))
+		})
+
+		It("simulates the policies of the role of assumed role credentials", func() {
+			stsClient.GetCallerIdentityCall.Returns.Output = &awssts.GetCallerIdentityOutput{
+				Arn: aws.String("arn:aws-cn:sts::123456789012:assumed-role/bbl-role/some-session"),
+			}
+
+			_, err := checker.MissingActions([]string{"ec2:RunInstances"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(iamClient.SimulatePrincipalPolicyCall.Receives.Inputs[0].PolicySourceArn).To(Equal(aws.String("arn:aws-cn:iam::123456789012:role/bbl-role")))
+		})
+
+		It("follows the pages of the simulation", func() {
+			iamClient.SimulatePrincipalPolicyCall.Stub = func(input *awsiam.SimulatePrincipalPolicyInput) (*awsiam.SimulatePolicyResponse, error) {
+				if input.Marker == nil {
+					return &awsiam.SimulatePolicyResponse{
+						EvaluationResults: []*awsiam.EvaluationResult{
+							{EvalActionName: aws.String("ec2:RunInstances"), EvalDecision: aws.String("implicitDeny")},
+						},
+						IsTruncated: aws.Bool(true),
+						Marker:      aws.String("some-marker"),
+					}, nil
+				}
+
+				return &awsiam.SimulatePolicyResponse{
+					EvaluationResults: []*awsiam.EvaluationResult{
+						{EvalActionName: aws.String("ec2:CreateVpc"), EvalDecision: aws.String("implicitDeny")},
+					},
+				}, nil
+			}
+
+			missing, err := checker.MissingActions([]string{"ec2:RunInstances", "ec2:CreateVpc"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(missing).To(Equal([]string{"ec2:CreateVpc", "ec2:RunInstances"}))
+			Expect(iamClient.SimulatePrincipalPolicyCall.CallCount).To(Equal(2))
+			Expect(iamClient.SimulatePrincipalPolicyCall.Receives.Inputs[1].Marker).To(Equal(aws.String("some-marker")))
+		})
+
+		It("does not simulate the policies of the root user", func() {
+			stsClient.GetCallerIdentityCall.Returns.Output = &awssts.GetCallerIdentityOutput{
+				Arn: aws.String("arn:aws:iam::123456789012:root"),
+			}
+
+			missing, err := checker.MissingActions([]string{"ec2:RunInstances"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(missing).To(BeEmpty())
+			Expect(iamClient.SimulatePrincipalPolicyCall.CallCount).To(Equal(0))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the caller identity cannot be retrieved", func() {
+				stsClient.GetCallerIdentityCall.Returns.Error = errors.New("failed to get caller identity")
+
+				_, err := checker.MissingActions([]string{"ec2:RunInstances"})
+				Expect(err).To(MatchError("failed to get caller identity"))
+			})
+
+			It("returns an error when the caller cannot be simulated", func() {
+				stsClient.GetCallerIdentityCall.Returns.Output = &awssts.GetCallerIdentityOutput{
+					Arn: aws.String("arn:aws:sts::123456789012:federated-user/someone"),
+				}
+
+				_, err := checker.MissingActions([]string{"ec2:RunInstances"})
+				Expect(err).To(MatchError("the policies of arn:aws:sts::123456789012:federated-user/someone cannot be simulated, only iam users and assumed roles are supported"))
+			})
+
+			It("returns an error when the caller arn is invalid", func() {
+				stsClient.GetCallerIdentityCall.Returns.Output = &awssts.GetCallerIdentityOutput{
+					Arn: aws.String("some-arn"),
+				}
+
+				_, err := checker.MissingActions([]string{"ec2:RunInstances"})
+				Expect(err).To(MatchError(`invalid caller arn "some-arn"`))
+			})
+
+			It("returns an error when the simulation fails", func() {
+				iamClient.SimulatePrincipalPolicyCall.Returns.Error = errors.New("access denied")
+
+				_, err := checker.MissingActions([]string{"ec2:RunInstances"})
+				Expect(err).To(MatchError("failed to simulate the policies of arn:aws:iam::123456789012:user/bbl-user: access denied"))
+			})
+		})
+	})
+})
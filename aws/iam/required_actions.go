@@ -0,0 +1,292 @@
+package iam
+
+import "sort"
+
+// resourceActions are the actions bbl, cloudformation, terraform and the
+// BOSH AWS CPI call on behalf of the provided credentials to create or
+// update, describe and delete one kind of resource bbl manages.
+type resourceActions struct {
+	create   []string
+	describe []string
+	delete   []string
+}
+
+var (
+	// the cloudformation stack of environments that do not use terraform
+	stackActions = resourceActions{
+		create:   []string{"cloudformation:CreateStack", "cloudformation:UpdateStack"},
+		describe: []string{"cloudformation:DescribeStackResource", "cloudformation:DescribeStacks"},
+		delete:   []string{"cloudformation:DeleteStack"},
+	}
+
+	keyPairActions = resourceActions{
+		create:   []string{"ec2:CreateKeyPair", "ec2:ImportKeyPair"},
+		describe: []string{"ec2:DescribeKeyPairs"},
+		delete:   []string{"ec2:DeleteKeyPair"},
+	}
+
+	regionActions = resourceActions{
+		describe: []string{
+			"ec2:DescribeAccountAttributes",
+			"ec2:DescribeAvailabilityZones",
+			"ec2:DescribeInstanceTypeOfferings",
+			"ec2:DescribeRegions",
+		},
+	}
+
+	vpcActions = resourceActions{
+		create: []string{
+			"ec2:AssociateRouteTable",
+			"ec2:AttachInternetGateway",
+			"ec2:CreateEgressOnlyInternetGateway",
+			"ec2:CreateInternetGateway",
+			"ec2:CreateRoute",
+			"ec2:CreateRouteTable",
+			"ec2:CreateSubnet",
+			"ec2:CreateVpc",
+			"ec2:ModifySubnetAttribute",
+			"ec2:ModifyVpcAttribute",
+		},
+		describe: []string{
+			"ec2:DescribeEgressOnlyInternetGateways",
+			"ec2:DescribeInternetGateways",
+			"ec2:DescribeRouteTables",
+			"ec2:DescribeSubnets",
+			"ec2:DescribeVpcAttribute",
+			"ec2:DescribeVpcs",
+		},
+		delete: []string{
+			"ec2:DeleteEgressOnlyInternetGateway",
+			"ec2:DeleteInternetGateway",
+			"ec2:DeleteRoute",
+			"ec2:DeleteRouteTable",
+			"ec2:DeleteSubnet",
+			"ec2:DeleteVpc",
+			"ec2:DetachInternetGateway",
+			"ec2:DisassociateRouteTable",
+		},
+	}
+
+	securityGroupActions = resourceActions{
+		create: []string{
+			"ec2:AuthorizeSecurityGroupEgress",
+			"ec2:AuthorizeSecurityGroupIngress",
+			"ec2:CreateSecurityGroup",
+		},
+		describe: []string{"ec2:DescribeSecurityGroups"},
+		delete: []string{
+			"ec2:DeleteSecurityGroup",
+			"ec2:RevokeSecurityGroupEgress",
+			"ec2:RevokeSecurityGroupIngress",
+		},
+	}
+
+	// the nat instance and the elastic ips of the nat and the director
+	natActions = resourceActions{
+		create: []string{
+			"ec2:AllocateAddress",
+			"ec2:AssociateAddress",
+			"ec2:CreateTags",
+			"ec2:ModifyInstanceAttribute",
+			"ec2:RunInstances",
+		},
+		describe: []string{"ec2:DescribeAddresses", "ec2:DescribeInstances"},
+		delete: []string{
+			"ec2:DisassociateAddress",
+			"ec2:ReleaseAddress",
+			"ec2:TerminateInstances",
+		},
+	}
+
+	// the iam user and access key the director uses
+	directorUserActions = resourceActions{
+		create: []string{
+			"iam:CreateAccessKey",
+			"iam:CreateUser",
+			"iam:PutUserPolicy",
+		},
+		describe: []string{
+			"iam:GetUser",
+			"iam:GetUserPolicy",
+			"iam:ListAccessKeys",
+		},
+		delete: []string{
+			"iam:DeleteAccessKey",
+			"iam:DeleteUser",
+			"iam:DeleteUserPolicy",
+		},
+	}
+
+	// the vms, disks and stemcells of the director and its deployments
+	cpiActions = resourceActions{
+		create: []string{
+			"ec2:AttachVolume",
+			"ec2:CreateSnapshot",
+			"ec2:CreateTags",
+			"ec2:CreateVolume",
+			"ec2:DetachVolume",
+			"ec2:RegisterImage",
+			"ec2:RunInstances",
+			"elasticloadbalancing:DeregisterInstancesFromLoadBalancer",
+			"elasticloadbalancing:RegisterInstancesWithLoadBalancer",
+			"elasticloadbalancing:RegisterTargets",
+		},
+		describe: []string{
+			"ec2:DescribeImages",
+			"ec2:DescribeInstances",
+			"ec2:DescribeSnapshots",
+			"ec2:DescribeVolumes",
+			"elasticloadbalancing:DescribeLoadBalancers",
+			"elasticloadbalancing:DescribeTargetGroups",
+		},
+		delete: []string{
+			"ec2:DeleteSnapshot",
+			"ec2:DeleteVolume",
+			"ec2:DeregisterImage",
+			"ec2:DetachVolume",
+			"ec2:TerminateInstances",
+		},
+	}
+
+	classicELBActions = resourceActions{
+		create: []string{
+			"elasticloadbalancing:AddTags",
+			"elasticloadbalancing:ConfigureHealthCheck",
+			"elasticloadbalancing:CreateLoadBalancer",
+			"elasticloadbalancing:CreateLoadBalancerListeners",
+			"elasticloadbalancing:DeleteLoadBalancerListeners",
+			"elasticloadbalancing:ModifyLoadBalancerAttributes",
+			"elasticloadbalancing:SetLoadBalancerListenerSSLCertificate",
+		},
+		describe: []string{
+			"elasticloadbalancing:DescribeLoadBalancerAttributes",
+			"elasticloadbalancing:DescribeLoadBalancers",
+		},
+		delete: []string{"elasticloadbalancing:DeleteLoadBalancer"},
+	}
+
+	// the albs and nlbs of lbs with --aws-lb-flavor alb or nlb
+	lbV2Actions = resourceActions{
+		create: []string{
+			"elasticloadbalancing:AddListenerCertificates",
+			"elasticloadbalancing:AddTags",
+			"elasticloadbalancing:CreateListener",
+			"elasticloadbalancing:CreateLoadBalancer",
+			"elasticloadbalancing:CreateTargetGroup",
+			"elasticloadbalancing:ModifyListener",
+			"elasticloadbalancing:ModifyLoadBalancerAttributes",
+			"elasticloadbalancing:ModifyTargetGroup",
+			"elasticloadbalancing:RemoveListenerCertificates",
+		},
+		describe: []string{
+			"elasticloadbalancing:DescribeListenerCertificates",
+			"elasticloadbalancing:DescribeListeners",
+			"elasticloadbalancing:DescribeLoadBalancerAttributes",
+			"elasticloadbalancing:DescribeLoadBalancers",
+			"elasticloadbalancing:DescribeTargetGroups",
+		},
+		delete: []string{
+			"elasticloadbalancing:DeleteListener",
+			"elasticloadbalancing:DeleteLoadBalancer",
+			"elasticloadbalancing:DeleteTargetGroup",
+			"elasticloadbalancing:RemoveListenerCertificates",
+		},
+	}
+
+	serverCertificateActions = resourceActions{
+		create:   []string{"iam:UploadServerCertificate"},
+		describe: []string{"iam:GetServerCertificate"},
+		delete:   []string{"iam:DeleteServerCertificate"},
+	}
+
+	// the certificates of lbs created with --aws-acm or --aws-acm-cert-arn
+	acmActions = resourceActions{
+		create: []string{"acm:AddTagsToCertificate", "acm:ImportCertificate"},
+		describe: []string{
+			"acm:DescribeCertificate",
+			"acm:GetCertificate",
+			"acm:ListCertificates",
+			"acm:ListTagsForCertificate",
+		},
+		delete: []string{"acm:DeleteCertificate"},
+	}
+
+	infrastructureActions = []resourceActions{
+		stackActions,
+		keyPairActions,
+		regionActions,
+		vpcActions,
+		securityGroupActions,
+		natActions,
+		directorUserActions,
+		cpiActions,
+	}
+
+	lbActions = []resourceActions{
+		classicELBActions,
+		lbV2Actions,
+		serverCertificateActions,
+	}
+)
+
+// UpActions are the actions up needs to create or update the infrastructure,
+// the lbs of the state and the director.
+func UpActions(acm bool) []string {
+	resources := withACM(append(append([]resourceActions{}, infrastructureActions...), lbActions...), acm)
+	return collect(resources, true, false)
+}
+
+// CreateLBsActions are the actions create-lbs and update-lbs need to create
+// or update lbs and their certificates in the existing infrastructure.
+func CreateLBsActions(acm bool) []string {
+	resources := withACM(append([]resourceActions{stackActions, regionActions, securityGroupActions}, lbActions...), acm)
+	return collect(resources, true, false)
+}
+
+// DestroyActions are the actions destroy needs to delete the director, its
+// vms and disks, the lbs and the infrastructure.
+func DestroyActions(acm bool) []string {
+	resources := withACM(append(append([]resourceActions{}, infrastructureActions...), lbActions...), acm)
+	return collect(resources, false, true)
+}
+
+// RequiredActions are all the actions bbl and the BOSH AWS CPI call over the
+// lifetime of an environment, which is what preflight checks.
+func RequiredActions(acm bool) []string {
+	resources := withACM(append(append([]resourceActions{}, infrastructureActions...), lbActions...), acm)
+	return collect(resources, true, true)
+}
+
+func withACM(resources []resourceActions, acm bool) []resourceActions {
+	if acm {
+		return append(resources, acmActions)
+	}
+	return resources
+}
+
+// collect returns the sorted, deduplicated actions to describe the
+// resources and, as asked, to create or delete them.
+func collect(resources []resourceActions, create, delete bool) []string {
+	unique := map[string]bool{}
+	for _, resource := range resources {
+		actions := resource.describe
+		if create {
+			actions = append(append([]string{}, actions...), resource.create...)
+		}
+		if delete {
+			actions = append(append([]string{}, actions...), resource.delete...)
+		}
+
+		for _, action := range actions {
+			unique[action] = true
+		}
+	}
+
+	var actions []string
+	for action := range unique {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	return actions
+}
@@ -0,0 +1,73 @@
+package iam_test
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RequiredActions", func() {
+	It("includes the actions of the iam user, access key and policy of the director", func() {
+		Expect(iam.RequiredActions(false)).To(ContainElement("iam:CreateUser"))
+		Expect(iam.RequiredActions(false)).To(ContainElement("iam:PutUserPolicy"))
+		Expect(iam.RequiredActions(false)).To(ContainElement("iam:CreateAccessKey"))
+		Expect(iam.RequiredActions(false)).To(ContainElement("iam:DeleteUser"))
+		Expect(iam.RequiredActions(false)).To(ContainElement("iam:DeleteUserPolicy"))
+		Expect(iam.RequiredActions(false)).To(ContainElement("iam:DeleteAccessKey"))
+	})
+
+	It("includes the listener certificate actions of albs and nlbs", func() {
+		Expect(iam.RequiredActions(false)).To(ContainElement("elasticloadbalancing:AddListenerCertificates"))
+		Expect(iam.RequiredActions(false)).To(ContainElement("elasticloadbalancing:RemoveListenerCertificates"))
+	})
+
+	It("includes the acm actions only when acm is used", func() {
+		Expect(iam.RequiredActions(false)).NotTo(ContainElement("acm:ImportCertificate"))
+		Expect(iam.RequiredActions(true)).To(ContainElement("acm:ImportCertificate"))
+		Expect(iam.RequiredActions(true)).To(ContainElement("acm:DeleteCertificate"))
+	})
+
+	It("does not include route53 actions, bbl does not manage route53 on aws", func() {
+		for _, action := range iam.RequiredActions(true) {
+			Expect(action).NotTo(HavePrefix("route53:"))
+		}
+	})
+
+	It("returns sorted actions without duplicates", func() {
+		actions := iam.RequiredActions(true)
+		for i := 1; i < len(actions); i++ {
+			Expect(actions[i-1] < actions[i]).To(BeTrue(), actions[i])
+		}
+	})
+})
+
+var _ = Describe("DestroyActions", func() {
+	It("does not require the actions that only create resources", func() {
+		Expect(iam.DestroyActions(false)).NotTo(ContainElement("ec2:CreateVpc"))
+		Expect(iam.DestroyActions(false)).NotTo(ContainElement("iam:CreateUser"))
+		Expect(iam.DestroyActions(false)).NotTo(ContainElement("cloudformation:CreateStack"))
+	})
+
+	It("requires the actions that describe and delete resources", func() {
+		Expect(iam.DestroyActions(false)).To(ContainElement("ec2:DescribeVpcs"))
+		Expect(iam.DestroyActions(false)).To(ContainElement("ec2:DeleteVpc"))
+		Expect(iam.DestroyActions(false)).To(ContainElement("iam:DeleteUser"))
+		Expect(iam.DestroyActions(true)).To(ContainElement("acm:DeleteCertificate"))
+	})
+})
+
+var _ = Describe("UpActions", func() {
+	It("does not require the actions that only delete resources", func() {
+		Expect(iam.UpActions(false)).NotTo(ContainElement("ec2:DeleteVpc"))
+		Expect(iam.UpActions(false)).To(ContainElement("ec2:CreateVpc"))
+	})
+})
+
+var _ = Describe("CreateLBsActions", func() {
+	It("requires the lb actions without the vpc actions", func() {
+		Expect(iam.CreateLBsActions(false)).To(ContainElement("elasticloadbalancing:CreateLoadBalancer"))
+		Expect(iam.CreateLBsActions(false)).NotTo(ContainElement("ec2:CreateVpc"))
+		Expect(iam.CreateLBsActions(true)).To(ContainElement("acm:ImportCertificate"))
+	})
+})
@@ -0,0 +1,18 @@
+package route53
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/aws"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	awsroute53 "github.com/aws/aws-sdk-go/service/route53"
+)
+
+type Client interface {
+	ListHostedZonesByName(*awsroute53.ListHostedZonesByNameInput) (*awsroute53.ListHostedZonesByNameOutput, error)
+	ChangeResourceRecordSets(*awsroute53.ChangeResourceRecordSetsInput) (*awsroute53.ChangeResourceRecordSetsOutput, error)
+	WaitUntilResourceRecordSetsChanged(*awsroute53.GetChangeInput) error
+}
+
+func NewClient(config aws.Config) Client {
+	return awsroute53.New(session.New(config.ClientConfig()))
+}
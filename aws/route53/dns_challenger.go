@@ -0,0 +1,89 @@
+package route53
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsroute53 "github.com/aws/aws-sdk-go/service/route53"
+)
+
+type route53ClientProvider interface {
+	GetRoute53Client() Client
+}
+
+// DNSChallenger answers ACME dns-01 challenges with TXT records in the
+// Route53 hosted zone of the domain.
+type DNSChallenger struct {
+	route53ClientProvider route53ClientProvider
+}
+
+func NewDNSChallenger(route53ClientProvider route53ClientProvider) DNSChallenger {
+	return DNSChallenger{
+		route53ClientProvider: route53ClientProvider,
+	}
+}
+
+func (d DNSChallenger) Present(domain, fqdn, value string) error {
+	return d.change("UPSERT", domain, fqdn, value)
+}
+
+func (d DNSChallenger) CleanUp(domain, fqdn, value string) error {
+	return d.change("DELETE", domain, fqdn, value)
+}
+
+func (d DNSChallenger) change(action, domain, fqdn, value string) error {
+	client := d.route53ClientProvider.GetRoute53Client()
+
+	zoneID, err := d.hostedZoneID(client, domain)
+	if err != nil {
+		return err
+	}
+
+	output, err := client.ChangeResourceRecordSets(&awsroute53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(zoneID),
+		ChangeBatch: &awsroute53.ChangeBatch{
+			Changes: []*awsroute53.Change{{
+				Action: aws.String(action),
+				ResourceRecordSet: &awsroute53.ResourceRecordSet{
+					Name: aws.String(fqdn),
+					Type: aws.String("TXT"),
+					TTL:  aws.Int64(60),
+					ResourceRecords: []*awsroute53.ResourceRecord{{
+						Value: aws.String(fmt.Sprintf("%q", value)),
+					}},
+				},
+			}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if action == "DELETE" {
+		return nil
+	}
+
+	return client.WaitUntilResourceRecordSetsChanged(&awsroute53.GetChangeInput{
+		Id: output.ChangeInfo.Id,
+	})
+}
+
+func (DNSChallenger) hostedZoneID(client Client, domain string) (string, error) {
+	zoneName := strings.TrimSuffix(domain, ".") + "."
+
+	output, err := client.ListHostedZonesByName(&awsroute53.ListHostedZonesByNameInput{
+		DNSName: aws.String(zoneName),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, zone := range output.HostedZones {
+		if aws.StringValue(zone.Name) == zoneName {
+			return aws.StringValue(zone.Id), nil
+		}
+	}
+
+	return "", fmt.Errorf("no route53 hosted zone has been found for %q", domain)
+}
@@ -0,0 +1,83 @@
+package route53_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws/route53"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+
+	"github.com/aws/aws-sdk-go/aws"
+	awsroute53 "github.com/aws/aws-sdk-go/service/route53"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DNSChallenger", func() {
+	var (
+		route53Client  *fakes.Route53Client
+		clientProvider *fakes.ClientProvider
+		challenger     route53.DNSChallenger
+	)
+
+	BeforeEach(func() {
+		route53Client = &fakes.Route53Client{}
+		route53Client.ListHostedZonesByNameCall.Returns.Output = &awsroute53.ListHostedZonesByNameOutput{
+			HostedZones: []*awsroute53.HostedZone{
+				{Id: aws.String("/hostedzone/other-zone-id"), Name: aws.String("other.example.com.")},
+				{Id: aws.String("/hostedzone/some-zone-id"), Name: aws.String("example.com.")},
+			},
+		}
+		route53Client.ChangeResourceRecordSetsCall.Returns.Output = &awsroute53.ChangeResourceRecordSetsOutput{
+			ChangeInfo: &awsroute53.ChangeInfo{Id: aws.String("some-change-id")},
+		}
+
+		clientProvider = &fakes.ClientProvider{}
+		clientProvider.GetRoute53ClientCall.Returns.Route53Client = route53Client
+
+		challenger = route53.NewDNSChallenger(clientProvider)
+	})
+
+	Describe("Present", func() {
+		It("upserts the challenge record in the hosted zone of the domain and waits for it", func() {
+			err := challenger.Present("example.com", "_acme-challenge.example.com.", "some-value")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(route53Client.ListHostedZonesByNameCall.Receives.Input.DNSName).To(Equal(aws.String("example.com.")))
+
+			input := route53Client.ChangeResourceRecordSetsCall.Receives.Input
+			Expect(input.HostedZoneId).To(Equal(aws.String("/hostedzone/some-zone-id")))
+			Expect(input.ChangeBatch.Changes).To(HaveLen(1))
+			Expect(input.ChangeBatch.Changes[0].Action).To(Equal(aws.String("UPSERT")))
+
+			recordSet := input.ChangeBatch.Changes[0].ResourceRecordSet
+			Expect(recordSet.Name).To(Equal(aws.String("_acme-challenge.example.com.")))
+			Expect(recordSet.Type).To(Equal(aws.String("TXT")))
+			Expect(recordSet.ResourceRecords[0].Value).To(Equal(aws.String(`"some-value"`)))
+
+			Expect(route53Client.WaitUntilResourceRecordSetsChangedCall.Receives.Input.Id).To(Equal(aws.String("some-change-id")))
+		})
+
+		It("returns an error when there is no hosted zone for the domain", func() {
+			err := challenger.Present("example.org", "_acme-challenge.example.org.", "some-value")
+			Expect(err).To(MatchError(`no route53 hosted zone has been found for "example.org"`))
+		})
+
+		It("returns an error when the record cannot be changed", func() {
+			route53Client.ChangeResourceRecordSetsCall.Returns.Error = errors.New("failed to change")
+
+			err := challenger.Present("example.com", "_acme-challenge.example.com.", "some-value")
+			Expect(err).To(MatchError("failed to change"))
+		})
+	})
+
+	Describe("CleanUp", func() {
+		It("deletes the challenge record without waiting", func() {
+			err := challenger.CleanUp("example.com", "_acme-challenge.example.com.", "some-value")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(route53Client.ChangeResourceRecordSetsCall.Receives.Input.ChangeBatch.Changes[0].Action).To(Equal(aws.String("DELETE")))
+			Expect(route53Client.WaitUntilResourceRecordSetsChangedCall.CallCount).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,16 @@
+package sts
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/aws"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	awssts "github.com/aws/aws-sdk-go/service/sts"
+)
+
+type Client interface {
+	GetCallerIdentity(*awssts.GetCallerIdentityInput) (*awssts.GetCallerIdentityOutput, error)
+}
+
+func NewClient(config aws.Config) Client {
+	return awssts.New(session.New(config.ClientConfig()))
+}
@@ -67,17 +67,42 @@ func (b *fakeBOSHDirector) ServeHTTP(responseWriter http.ResponseWriter, request
 		}`))
 
 		return
-	case "/cloud_configs":
+	case "/configs":
 		if b.GetCloudConfigEndpointFail() {
 			responseWriter.WriteHeader(0)
 			return
 		}
-		buf, err := ioutil.ReadAll(request.Body)
-		if err != nil {
-			panic(err)
+
+		switch request.Method {
+		case "GET":
+			configs := []map[string]string{}
+			if request.URL.Query().Get("type") == "cloud" {
+				if cloudConfig := b.GetCloudConfig(); len(cloudConfig) > 0 {
+					configs = append(configs, map[string]string{"content": string(cloudConfig)})
+				}
+			}
+
+			err := json.NewEncoder(responseWriter).Encode(configs)
+			if err != nil {
+				panic(err)
+			}
+		case "POST":
+			var config struct {
+				Type    string `json:"type"`
+				Content string `json:"content"`
+			}
+			err := json.NewDecoder(request.Body).Decode(&config)
+			if err != nil {
+				panic(err)
+			}
+
+			if config.Type == "cloud" {
+				b.SetCloudConfig([]byte(config.Content))
+			}
+			responseWriter.WriteHeader(http.StatusCreated)
+		case "DELETE":
+			responseWriter.WriteHeader(http.StatusNoContent)
 		}
-		b.SetCloudConfig(buf)
-		responseWriter.WriteHeader(http.StatusCreated)
 
 		return
 	default:
@@ -12,6 +12,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/elb"
 	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
 	"github.com/cloudfoundry/bosh-bootloader/testhelpers"
 	"github.com/rosenhouse/awsfaker"
@@ -118,6 +119,10 @@ func (b *Backend) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.Des
 	}, nil
 }
 
+func (b *Backend) DescribeVpcs(input *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error) {
+	return &ec2.DescribeVpcsOutput{}, nil
+}
+
 func (b *Backend) CreateStack(input *cloudformation.CreateStackInput) (*cloudformation.CreateStackOutput, error) {
 	stack := Stack{
 		Name:     *input.StackName,
@@ -183,6 +188,65 @@ func (b *Backend) DescribeAvailabilityZones(input *ec2.DescribeAvailabilityZones
 	}, nil
 }
 
+func (b *Backend) DescribeInstanceTypeOfferings(input *ec2.DescribeInstanceTypeOfferingsInput) (*ec2.DescribeInstanceTypeOfferingsOutput, error) {
+	instanceTypes := []string{
+		"m3.medium",
+		"t2.small",
+		"m3.large",
+		"m4.xlarge",
+		"m4.2xlarge",
+		"m4.4xlarge",
+		"m3.xlarge",
+		"m3.2xlarge",
+		"m4.large",
+		"m4.10xlarge",
+		"c3.large",
+		"c3.xlarge",
+		"c3.2xlarge",
+		"c3.4xlarge",
+		"c3.8xlarge",
+		"c4.large",
+		"c4.xlarge",
+		"c4.2xlarge",
+		"c4.4xlarge",
+		"c4.8xlarge",
+		"r3.large",
+		"r3.xlarge",
+		"r3.2xlarge",
+		"r3.4xlarge",
+		"r3.8xlarge",
+		"t2.nano",
+		"t2.micro",
+		"t2.medium",
+		"t2.large",
+		"t3.small",
+		"m5.large",
+		"m5.xlarge",
+		"m5.2xlarge",
+		"m5.4xlarge",
+		"c5.large",
+		"c5.xlarge",
+		"c5.2xlarge",
+		"c5.4xlarge",
+		"r5.large",
+		"r5.xlarge",
+		"r5.2xlarge",
+		"r5.4xlarge",
+	}
+
+	offerings := []*ec2.InstanceTypeOffering{}
+	for _, instanceType := range instanceTypes {
+		offerings = append(offerings, &ec2.InstanceTypeOffering{
+			InstanceType: aws.String(instanceType),
+			LocationType: input.LocationType,
+		})
+	}
+
+	return &ec2.DescribeInstanceTypeOfferingsOutput{
+		InstanceTypeOfferings: offerings,
+	}, nil
+}
+
 func (b *Backend) DescribeLoadBalancers(input *elb.DescribeLoadBalancersInput) (*elb.DescribeLoadBalancersOutput, error) {
 	var loadBalancer LoadBalancer
 
@@ -421,3 +485,26 @@ func (b *Backend) DeleteServerCertificate(input *iam.DeleteServerCertificateInpu
 		AWSErrorMessage: fmt.Sprintf("The Server Certificate with name %s cannot be found.", certificateName),
 	}
 }
+
+func (b *Backend) GetCallerIdentity(input *sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+	return &sts.GetCallerIdentityOutput{
+		Account: aws.String("123456789012"),
+		Arn:     aws.String("arn:aws:iam::123456789012:user/some-user"),
+		UserId:  aws.String("some-user-id"),
+	}, nil
+}
+
+func (b *Backend) SimulatePrincipalPolicy(input *iam.SimulatePrincipalPolicyInput) (*iam.SimulatePolicyResponse, error) {
+	var results []*iam.EvaluationResult
+	for _, action := range input.ActionNames {
+		results = append(results, &iam.EvaluationResult{
+			EvalActionName: action,
+			EvalDecision:   aws.String("allowed"),
+		})
+	}
+
+	return &iam.SimulatePolicyResponse{
+		EvaluationResults: results,
+		IsTruncated:       aws.Bool(false),
+	}, nil
+}
@@ -0,0 +1,366 @@
+package director
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Director is a fake BOSH director API that keeps uploaded configs in
+// memory. It authenticates requests with basic auth, or with a bearer token
+// issued by its own /oauth/token endpoint when UAA is enabled.
+type Director struct {
+	mutex sync.Mutex
+
+	username string
+	password string
+	uaaURL   string
+	token    string
+
+	cloudConfig    string
+	runtimeConfigs map[string]string
+	cpiConfig      string
+	configs        map[string]string
+	deployments    []Deployment
+	tasks          map[int][]Task
+	requests       map[string]int
+}
+
+type Deployment struct {
+	Name        string        `json:"name"`
+	CloudConfig string        `json:"cloud_config"`
+	Releases    []NameVersion `json:"releases"`
+	Stemcells   []NameVersion `json:"stemcells"`
+	VMs         []VM          `json:"-"`
+}
+
+type NameVersion struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type VM struct {
+	AgentID string `json:"agent_id"`
+	CID     string `json:"cid"`
+	Job     string `json:"job"`
+	Index   int    `json:"index"`
+	ID      string `json:"id"`
+}
+
+type Task struct {
+	ID          int    `json:"id"`
+	State       string `json:"state"`
+	Description string `json:"description"`
+	Timestamp   int64  `json:"timestamp"`
+	Result      string `json:"result"`
+	User        string `json:"user"`
+	Deployment  string `json:"deployment"`
+}
+
+func New(username, password string) *Director {
+	return &Director{
+		username:       username,
+		password:       password,
+		runtimeConfigs: map[string]string{},
+		configs:        map[string]string{},
+		tasks:          map[int][]Task{},
+		requests:       map[string]int{},
+	}
+}
+
+// EnableUAA makes the director advertise UAA authentication at the given
+// URL. The director itself serves the token endpoint, so the URL is usually
+// the address of the server the director is mounted on.
+func (d *Director) EnableUAA(uaaURL, token string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.uaaURL = uaaURL
+	d.token = token
+}
+
+// SetToken replaces the token the director accepts, which rejects the
+// tokens issued before, as an expired token would be.
+func (d *Director) SetToken(token string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.token = token
+}
+
+// Requests returns how many requests the director received for the path.
+func (d *Director) Requests(path string) int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.requests[path]
+}
+
+func (d *Director) CloudConfig() string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.cloudConfig
+}
+
+func (d *Director) SetCloudConfig(cloudConfig string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.cloudConfig = cloudConfig
+}
+
+func (d *Director) RuntimeConfig(name string) string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.runtimeConfigs[name]
+}
+
+func (d *Director) CPIConfig() string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.cpiConfig
+}
+
+// Config returns the named config of the given type uploaded through the
+// generic /configs endpoint.
+func (d *Director) Config(configType, name string) string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.configs[configKey(configType, name)]
+}
+
+func (d *Director) SetConfig(configType, name, content string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.configs[configKey(configType, name)] = content
+}
+
+func (d *Director) SetDeployments(deployments []Deployment) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.deployments = deployments
+}
+
+// SetTask registers the states a task moves through. Each request for the
+// task returns the next state until the last one is reached.
+func (d *Director) SetTask(states ...Task) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.tasks[states[0].ID] = states
+}
+
+func (d *Director) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.requests[r.URL.Path]++
+
+	switch {
+	case r.URL.Path == "/info":
+		d.info(w)
+		return
+	case r.URL.Path == "/oauth/token":
+		d.oauthToken(w, r)
+		return
+	}
+
+	if !d.authorized(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/cloud_configs" && r.Method == "GET":
+		configs := []map[string]string{}
+		if d.cloudConfig != "" {
+			configs = append(configs, map[string]string{"properties": d.cloudConfig})
+		}
+		writeJSON(w, configs)
+	case r.URL.Path == "/cloud_configs" && r.Method == "POST":
+		d.cloudConfig = readBody(r)
+		w.WriteHeader(http.StatusCreated)
+	case r.URL.Path == "/runtime_configs" && r.Method == "POST":
+		d.runtimeConfigs[r.URL.Query().Get("name")] = readBody(r)
+		w.WriteHeader(http.StatusCreated)
+	case r.URL.Path == "/cpi_configs" && r.Method == "POST":
+		d.cpiConfig = readBody(r)
+		w.WriteHeader(http.StatusCreated)
+	case r.URL.Path == "/configs" && r.Method == "GET":
+		configs := []map[string]string{}
+		key := configKey(r.URL.Query().Get("type"), r.URL.Query().Get("name"))
+		if content, ok := d.configs[key]; ok {
+			configs = append(configs, map[string]string{"content": content})
+		}
+		writeJSON(w, configs)
+	case r.URL.Path == "/configs" && r.Method == "POST":
+		var config struct {
+			Type    string `json:"type"`
+			Name    string `json:"name"`
+			Content string `json:"content"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		d.configs[configKey(config.Type, config.Name)] = config.Content
+		w.WriteHeader(http.StatusCreated)
+	case r.URL.Path == "/configs" && r.Method == "DELETE":
+		key := configKey(r.URL.Query().Get("type"), r.URL.Query().Get("name"))
+		if _, ok := d.configs[key]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(d.configs, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.URL.Path == "/deployments":
+		deployments := d.deployments
+		if deployments == nil {
+			deployments = []Deployment{}
+		}
+		writeJSON(w, deployments)
+	case strings.HasPrefix(r.URL.Path, "/deployments/") && strings.HasSuffix(r.URL.Path, "/vms"):
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/deployments/"), "/vms")
+		for _, deployment := range d.deployments {
+			if deployment.Name == name {
+				vms := deployment.VMs
+				if vms == nil {
+					vms = []VM{}
+				}
+				writeJSON(w, vms)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case r.URL.Path == "/tasks":
+		d.listTasks(w, r)
+	case strings.HasPrefix(r.URL.Path, "/tasks/"):
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/tasks/"))
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		states, ok := d.tasks[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		writeJSON(w, states[0])
+		if len(states) > 1 {
+			d.tasks[id] = states[1:]
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (d *Director) info(w http.ResponseWriter) {
+	info := map[string]interface{}{
+		"name":    "fake-director",
+		"uuid":    "fake-uuid",
+		"version": "fake-version",
+	}
+
+	if d.uaaURL != "" {
+		info["user_authentication"] = map[string]interface{}{
+			"type":    "uaa",
+			"options": map[string]string{"url": d.uaaURL},
+		}
+	} else {
+		info["user_authentication"] = map[string]interface{}{
+			"type": "basic",
+		}
+	}
+
+	writeJSON(w, info)
+}
+
+func (d *Director) oauthToken(w http.ResponseWriter, r *http.Request) {
+	username, password, ok := r.BasicAuth()
+	if d.uaaURL == "" || !ok || username != d.username || password != d.password || r.FormValue("grant_type") != "client_credentials" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	writeJSON(w, map[string]string{
+		"access_token": d.token,
+		"token_type":   "bearer",
+	})
+}
+
+func (d *Director) authorized(r *http.Request) bool {
+	if d.uaaURL != "" {
+		return r.Header.Get("Authorization") == fmt.Sprintf("Bearer %s", d.token)
+	}
+
+	username, password, ok := r.BasicAuth()
+	return ok && username == d.username && password == d.password
+}
+
+func (d *Director) listTasks(w http.ResponseWriter, r *http.Request) {
+	var states []string
+	if state := r.URL.Query().Get("state"); state != "" {
+		states = strings.Split(state, ",")
+	}
+
+	tasks := []Task{}
+	for _, taskStates := range d.tasks {
+		task := taskStates[0]
+		if len(states) == 0 || contains(states, task.State) {
+			tasks = append(tasks, task)
+		}
+	}
+
+	sort.Sort(tasksByID(tasks))
+
+	writeJSON(w, tasks)
+}
+
+type tasksByID []Task
+
+func (t tasksByID) Len() int           { return len(t) }
+func (t tasksByID) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func (t tasksByID) Less(i, j int) bool { return t[i].ID < t[j].ID }
+
+func configKey(configType, name string) string {
+	return fmt.Sprintf("%s/%s", configType, name)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func readBody(r *http.Request) string {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		panic(err)
+	}
+
+	return string(body)
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		panic(err)
+	}
+}
@@ -1,6 +1,7 @@
 package gcpbackend
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -26,6 +27,8 @@ const (
  "user": "user@example.com",
  "selfLink": "https://www.googleapis.com/compute/v1/projects/cf-release-integration/global/operations/operation-1478888342819-5410a865610b9-fa8ffd77-0d4332fc"
  }`
+	GetRegionOutput        = `{"kind": "compute#region", "name": "some-region", "quotas": [ { "limit": 8, "metric": "STATIC_ADDRESSES", "usage": 0 } ]}`
+	ListMachineTypesOutput = `{"kind": "compute#machineTypeList", "items": [{"name":"n1-standard-1"},{"name":"g1-small"},{"name":"n1-standard-2"},{"name":"n1-standard-4"},{"name":"n1-standard-8"},{"name":"n1-standard-16"},{"name":"n1-standard-32"},{"name":"n1-highmem-2"},{"name":"n1-highmem-4"},{"name":"n1-highmem-8"},{"name":"n1-highmem-16"},{"name":"n1-highmem-32"},{"name":"n1-highcpu-2"},{"name":"n1-highcpu-4"},{"name":"n1-highcpu-8"},{"name":"n1-highcpu-16"},{"name":"n1-highcpu-32"},{"name":"f1-micro"}]}`
 )
 
 type GCPBackend struct {
@@ -38,7 +41,27 @@ func (g *GCPBackend) StartFakeGCPBackend() (*httptest.Server, string) {
 	fakeGCPServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		g.handlerMutex.Lock()
 		defer g.handlerMutex.Unlock()
+		if strings.HasPrefix(req.URL.Path, "/some-project-id/zones/") && strings.HasSuffix(req.URL.Path, "/machineTypes") {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(ListMachineTypesOutput))
+			return
+		}
+
+		if strings.HasPrefix(req.URL.Path, "/some-project-id/regions/") {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(GetRegionOutput))
+			return
+		}
+
 		switch req.URL.Path {
+		case "/v1/projects/some-project-id:testIamPermissions":
+			var permissions struct {
+				Permissions []string `json:"permissions"`
+			}
+			json.NewDecoder(req.Body).Decode(&permissions)
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(permissions)
+			return
 		case "/o/oauth2/token":
 			w.WriteHeader(http.StatusOK)
 			w.Write([]byte(`{"token": "my-oauth-token"}`))
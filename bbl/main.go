@@ -10,20 +10,25 @@ import (
 
 	yaml "gopkg.in/yaml.v2"
 
+	"github.com/square/certstrap/pkix"
 	"golang.org/x/crypto/ssh"
 
 	"github.com/cloudfoundry/bosh-bootloader/application"
 	"github.com/cloudfoundry/bosh-bootloader/aws"
+	"github.com/cloudfoundry/bosh-bootloader/aws/acm"
 	"github.com/cloudfoundry/bosh-bootloader/aws/clientmanager"
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
 	"github.com/cloudfoundry/bosh-bootloader/aws/ec2"
 	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
+	"github.com/cloudfoundry/bosh-bootloader/aws/route53"
 	"github.com/cloudfoundry/bosh-bootloader/bosh"
 	"github.com/cloudfoundry/bosh-bootloader/cloudconfig"
 	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/cost"
 	"github.com/cloudfoundry/bosh-bootloader/gcp"
 	"github.com/cloudfoundry/bosh-bootloader/helpers"
+	"github.com/cloudfoundry/bosh-bootloader/ssl"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 	"github.com/cloudfoundry/bosh-bootloader/terraform"
 
@@ -56,16 +61,23 @@ func main() {
 		commands.UpdateLBsCommand:          nil,
 		commands.DeleteLBsCommand:          nil,
 		commands.LBsCommand:                nil,
+		commands.CertsCommand:              nil,
+		commands.DNSCommand:                nil,
+		commands.CostCommand:               nil,
+		commands.PreflightCommand:          nil,
 		commands.EnvIDCommand:              nil,
 		commands.PrintEnvCommand:           nil,
 		commands.CloudConfigCommand:        nil,
 		commands.BOSHDeploymentVarsCommand: nil,
+		commands.OutputsCommand:            nil,
+		commands.StaticIPsCommand:          nil,
 	}
 
 	// Utilities
 	uuidGenerator := helpers.NewUUIDGenerator(rand.Reader)
 	stringGenerator := helpers.NewStringGenerator(rand.Reader)
 	envIDGenerator := helpers.NewEnvIDGenerator(rand.Reader)
+	sshKeyPairGenerator := ssl.NewSSHKeyPairGenerator(rand.Reader, rsa.GenerateKey, ssh.NewPublicKey)
 	logger := application.NewLogger(os.Stdout)
 	stderrLogger := application.NewLogger(os.Stderr)
 
@@ -97,34 +109,42 @@ func main() {
 	vpcStatusChecker := ec2.NewVPCStatusChecker(clientProvider)
 	awsKeyPairCreator := ec2.NewKeyPairCreator(clientProvider)
 	awsKeyPairDeleter := ec2.NewKeyPairDeleter(clientProvider, logger)
+	awsKeyPairImporter := ec2.NewKeyPairImporter(clientProvider)
 	keyPairChecker := ec2.NewKeyPairChecker(clientProvider)
-	keyPairManager := ec2.NewKeyPairManager(awsKeyPairCreator, keyPairChecker, logger)
+	keyPairManager := ec2.NewKeyPairManager(awsKeyPairCreator, awsKeyPairImporter, keyPairChecker, logger)
 	keyPairSynchronizer := ec2.NewKeyPairSynchronizer(keyPairManager)
 	availabilityZoneRetriever := ec2.NewAvailabilityZoneRetriever(clientProvider)
+	instanceTypeRetriever := ec2.NewInstanceTypeRetriever(clientProvider)
 	templateBuilder := templates.NewTemplateBuilder(logger)
 	stackManager := cloudformation.NewStackManager(clientProvider, logger)
 	infrastructureManager := cloudformation.NewInfrastructureManager(templateBuilder, stackManager)
 	certificateUploader := iam.NewCertificateUploader(clientProvider)
 	certificateDescriber := iam.NewCertificateDescriber(clientProvider)
 	certificateDeleter := iam.NewCertificateDeleter(clientProvider)
-	certificateManager := iam.NewCertificateManager(certificateUploader, certificateDescriber, certificateDeleter)
+	permissionChecker := iam.NewPermissionChecker(clientProvider)
+	iamCertificateManager := iam.NewCertificateManager(certificateUploader, certificateDescriber, certificateDeleter)
+	acmCertificateManager := acm.NewCertificateManager(clientProvider)
+	certificateManager := acm.NewCertificateRouter(iamCertificateManager, acmCertificateManager)
 	certificateValidator := iam.NewCertificateValidator()
 
 	// GCP
 	gcpClientProvider := gcp.NewClientProvider(gcpBasePath)
 	gcpClientProvider.SetConfig(configuration.State.GCP.ServiceAccountKey, configuration.State.GCP.ProjectID, configuration.State.GCP.Zone)
-	gcpKeyPairUpdater := gcp.NewKeyPairUpdater(rand.Reader, rsa.GenerateKey, ssh.NewPublicKey, gcpClientProvider, logger)
+	gcpKeyPairUpdater := gcp.NewKeyPairUpdater(sshKeyPairGenerator, gcpClientProvider, logger)
 	gcpKeyPairDeleter := gcp.NewKeyPairDeleter(gcpClientProvider, logger)
 	gcpNetworkInstancesChecker := gcp.NewNetworkInstancesChecker(gcpClientProvider)
+	gcpMachineTypeRetriever := gcp.NewMachineTypeRetriever(gcpClientProvider)
 	zones := gcp.NewZones()
 
 	// EnvID
-	envIDManager := helpers.NewEnvIDManager(envIDGenerator, gcpClientProvider, infrastructureManager)
+	vpcChecker := ec2.NewVPCChecker(clientProvider)
+	envIDManager := helpers.NewEnvIDManager(envIDGenerator, gcpClientProvider, infrastructureManager, vpcChecker)
 
 	// Terraform
 	terraformCmd := terraform.NewCmd(os.Stderr)
 	terraformExecutor := terraform.NewExecutor(terraformCmd, configuration.Global.Debug)
 	gcpTemplateGenerator := gcpterraform.NewTemplateGenerator(zones)
+	gcpPreflightChecker := gcp.NewPreflightChecker(gcpClientProvider, gcpTemplateGenerator)
 	gcpInputGenerator := gcpterraform.NewInputGenerator()
 	gcpOutputGenerator := gcpterraform.NewOutputGenerator(terraformExecutor)
 	awsTemplateGenerator := awsterraform.NewTemplateGenerator()
@@ -140,29 +160,30 @@ func main() {
 	boshExecutor := bosh.NewExecutor(boshCommand, ioutil.TempDir, ioutil.ReadFile, yaml.Unmarshal, json.Unmarshal,
 		json.Marshal, ioutil.WriteFile)
 	boshManager := bosh.NewManager(boshExecutor, terraformManager, stackManager, logger)
-	boshClientProvider := bosh.NewClientProvider()
+	boshClientProvider := bosh.NewClientProvider(configuration.Global.SkipDirectorSSLValidation)
 
 	// Cloud Config
-	awsCloudFormationOpsGenerator := awscloudconfig.NewCloudFormationOpsGenerator(availabilityZoneRetriever, infrastructureManager)
-	awsTerraformOpsGenerator := awscloudconfig.NewTerraformOpsGenerator(availabilityZoneRetriever, terraformManager)
-	gcpOpsGenerator := gcpcloudconfig.NewOpsGenerator(terraformManager, zones)
+	awsCloudFormationOpsGenerator := awscloudconfig.NewCloudFormationOpsGenerator(availabilityZoneRetriever, infrastructureManager, instanceTypeRetriever, stderrLogger)
+	awsTerraformOpsGenerator := awscloudconfig.NewTerraformOpsGenerator(availabilityZoneRetriever, terraformManager, instanceTypeRetriever, stderrLogger)
+	gcpOpsGenerator := gcpcloudconfig.NewOpsGenerator(terraformManager, zones, gcpMachineTypeRetriever)
 	cloudConfigOpsGenerator := cloudconfig.NewOpsGenerator(awsCloudFormationOpsGenerator, awsTerraformOpsGenerator, gcpOpsGenerator)
-	cloudConfigManager := cloudconfig.NewManager(logger, boshCommand, cloudConfigOpsGenerator, boshClientProvider)
+	cloudConfigManager := cloudconfig.NewManager(logger, boshCommand, cloudConfigOpsGenerator, boshClientProvider, os.Stdin)
 
 	// Subcommands
 	awsUp := commands.NewAWSUp(
 		awsCredentialValidator, infrastructureManager, keyPairSynchronizer, boshManager,
-		availabilityZoneRetriever, certificateDescriber,
-		cloudConfigManager, stateStore, clientProvider, envIDManager, terraformManager)
+		availabilityZoneRetriever, certificateManager,
+		cloudConfigManager, stateStore, clientProvider, envIDManager, terraformManager,
+		sshKeyPairGenerator, permissionChecker, logger)
 
 	awsCreateLBs := commands.NewAWSCreateLBs(
 		logger, awsCredentialValidator, certificateManager, infrastructureManager,
 		availabilityZoneRetriever, boshClientProvider, cloudConfigManager, certificateValidator,
-		uuidGenerator, stateStore,
+		uuidGenerator, stateStore, permissionChecker,
 	)
 
 	awsUpdateLBs := commands.NewAWSUpdateLBs(awsCredentialValidator, certificateManager, availabilityZoneRetriever, infrastructureManager,
-		boshClientProvider, logger, uuidGenerator, stateStore)
+		boshClientProvider, logger, cloudConfigManager, uuidGenerator, stateStore)
 
 	awsDeleteLBs := commands.NewAWSDeleteLBs(
 		awsCredentialValidator, availabilityZoneRetriever, certificateManager,
@@ -171,44 +192,57 @@ func main() {
 	gcpDeleteLBs := commands.NewGCPDeleteLBs(stateStore, terraformManager, cloudConfigManager)
 
 	gcpUp := commands.NewGCPUp(commands.NewGCPUpArgs{
-		StateStore:         stateStore,
-		KeyPairUpdater:     gcpKeyPairUpdater,
-		GCPProvider:        gcpClientProvider,
-		TerraformManager:   terraformManager,
-		BoshManager:        boshManager,
-		Logger:             logger,
-		EnvIDManager:       envIDManager,
-		CloudConfigManager: cloudConfigManager,
+		StateStore:          stateStore,
+		KeyPairUpdater:      gcpKeyPairUpdater,
+		GCPProvider:         gcpClientProvider,
+		TerraformManager:    terraformManager,
+		BoshManager:         boshManager,
+		Logger:              logger,
+		EnvIDManager:        envIDManager,
+		CloudConfigManager:  cloudConfigManager,
+		GCPPreflightChecker: gcpPreflightChecker,
 	})
 
-	gcpCreateLBs := commands.NewGCPCreateLBs(terraformManager, boshClientProvider, cloudConfigManager, stateStore, logger)
+	lbCertificateGenerator := commands.NewLBCertificateGenerator(
+		ssl.NewKeyPairGenerator(rsa.GenerateKey, pkix.CreateCertificateAuthority, pkix.CreateCertificateSigningRequest, pkix.CreateCertificateHost),
+		ssl.NewACMEIssuer(rsa.GenerateKey), route53.NewDNSChallenger(clientProvider), gcp.NewDNSChallenger(gcpClientProvider), logger,
+	)
+
+	gcpCreateLBs := commands.NewGCPCreateLBs(terraformManager, boshClientProvider, cloudConfigManager, stateStore, logger, gcpPreflightChecker)
 
 	gcpUpdateLBs := commands.NewGCPUpdateLBs(gcpCreateLBs)
 
 	envGetter := commands.NewEnvGetter()
+	renderer := commands.NewRenderer(os.Stdout, configuration.Global.Output)
 
 	// Commands
 	commandSet[commands.HelpCommand] = commands.NewUsage(os.Stdout)
 	commandSet[commands.VersionCommand] = commands.NewVersion(Version, os.Stdout)
 	commandSet[commands.UpCommand] = commands.NewUp(awsUp, gcpUp, envGetter, boshManager)
 	commandSet[commands.DestroyCommand] = commands.NewDestroy(
-		credentialValidator, logger, os.Stdin, boshManager, vpcStatusChecker, stackManager,
-		stringGenerator, infrastructureManager, awsKeyPairDeleter, gcpKeyPairDeleter, certificateDeleter,
-		stateStore, stateValidator, terraformManager, gcpNetworkInstancesChecker,
+		credentialValidator, logger, os.Stdin, boshManager, cloudConfigManager, vpcStatusChecker, stackManager,
+		stringGenerator, infrastructureManager, awsKeyPairDeleter, gcpKeyPairDeleter, certificateManager,
+		stateStore, stateValidator, terraformManager, gcpNetworkInstancesChecker, permissionChecker,
 	)
-	commandSet[commands.CreateLBsCommand] = commands.NewCreateLBs(awsCreateLBs, gcpCreateLBs, stateValidator, boshManager)
-	commandSet[commands.UpdateLBsCommand] = commands.NewUpdateLBs(awsUpdateLBs, gcpUpdateLBs, certificateValidator, stateValidator, logger, boshManager)
+	commandSet[commands.CreateLBsCommand] = commands.NewCreateLBs(awsCreateLBs, gcpCreateLBs, stateValidator, boshManager, lbCertificateGenerator)
+	commandSet[commands.UpdateLBsCommand] = commands.NewUpdateLBs(awsUpdateLBs, gcpUpdateLBs, certificateValidator, stateValidator, logger, boshManager, lbCertificateGenerator)
 	commandSet[commands.DeleteLBsCommand] = commands.NewDeleteLBs(gcpDeleteLBs, awsDeleteLBs, logger, stateValidator, boshManager)
-	commandSet[commands.LBsCommand] = commands.NewLBs(awsCredentialValidator, stateValidator, infrastructureManager, terraformManager, os.Stdout)
-	commandSet[commands.DirectorAddressCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, commands.DirectorAddressPropertyName)
-	commandSet[commands.DirectorUsernameCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, commands.DirectorUsernamePropertyName)
-	commandSet[commands.DirectorPasswordCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, commands.DirectorPasswordPropertyName)
-	commandSet[commands.DirectorCACertCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, commands.DirectorCACertPropertyName)
-	commandSet[commands.SSHKeyCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, commands.SSHKeyPropertyName)
-	commandSet[commands.EnvIDCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, commands.EnvIDPropertyName)
-	commandSet[commands.PrintEnvCommand] = commands.NewPrintEnv(logger, stateValidator, terraformManager, infrastructureManager)
+	commandSet[commands.LBsCommand] = commands.NewLBs(awsCredentialValidator, stateValidator, infrastructureManager, terraformManager, certificateManager, renderer, os.Stdout)
+	commandSet[commands.CertsCommand] = commands.NewCerts(awsCredentialValidator, stateValidator, certificateManager, renderer, os.Stdout)
+	commandSet[commands.DNSCommand] = commands.NewDNS(stateValidator, terraformManager, renderer, os.Stdout)
+	commandSet[commands.PreflightCommand] = commands.NewPreflight(awsCredentialValidator, clientProvider, permissionChecker, gcpClientProvider, gcpPreflightChecker, envGetter, logger)
+	commandSet[commands.CostCommand] = commands.NewCost(cost.NewEstimator(bosh.Asset), envGetter, renderer, os.Stdout)
+	commandSet[commands.DirectorAddressCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, renderer, commands.DirectorAddressPropertyName)
+	commandSet[commands.DirectorUsernameCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, renderer, commands.DirectorUsernamePropertyName)
+	commandSet[commands.DirectorPasswordCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, renderer, commands.DirectorPasswordPropertyName)
+	commandSet[commands.DirectorCACertCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, renderer, commands.DirectorCACertPropertyName)
+	commandSet[commands.SSHKeyCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, renderer, commands.SSHKeyPropertyName)
+	commandSet[commands.EnvIDCommand] = commands.NewStateQuery(logger, stateValidator, terraformManager, infrastructureManager, renderer, commands.EnvIDPropertyName)
+	commandSet[commands.PrintEnvCommand] = commands.NewPrintEnv(logger, stateValidator, terraformManager, infrastructureManager, renderer, configuration.Global.StateDir)
 	commandSet[commands.CloudConfigCommand] = commands.NewCloudConfig(logger, stateValidator, cloudConfigManager)
-	commandSet[commands.BOSHDeploymentVarsCommand] = commands.NewBOSHDeploymentVars(logger, boshManager)
+	commandSet[commands.BOSHDeploymentVarsCommand] = commands.NewBOSHDeploymentVars(logger, boshManager, renderer)
+	commandSet[commands.OutputsCommand] = commands.NewOutputs(logger, stateValidator, terraformManager, infrastructureManager, renderer)
+	commandSet[commands.StaticIPsCommand] = commands.NewStaticIPs(logger, stateValidator, cloudConfigManager, stateStore, renderer)
 
 	app := application.New(commandSet, configuration, stateStore, usage)
 
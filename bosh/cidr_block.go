@@ -2,17 +2,17 @@ package bosh
 
 import (
 	"fmt"
+	"math/big"
+	"net"
 	"strconv"
 	"strings"
 )
 
 type CIDRBlock struct {
-	CIDRSize int
-	firstIP  IP
+	network *net.IPNet
 }
 
 func ParseCIDRBlock(cidrBlock string) (CIDRBlock, error) {
-	const HIGHEST_BITMASK = 32
 	const CIDR_PARTS = 2
 
 	cidrParts := strings.Split(cidrBlock, "/")
@@ -31,21 +31,83 @@ func ParseCIDRBlock(cidrBlock string) (CIDRBlock, error) {
 		return CIDRBlock{}, err
 	}
 
-	if maskBits < 0 || maskBits > HIGHEST_BITMASK {
+	highestBitmask := len(ip.ip) * 8
+	if maskBits < 0 || maskBits > highestBitmask {
 		return CIDRBlock{}, fmt.Errorf("mask bits out of range")
 	}
 
-	cidrSize := 1 << (HIGHEST_BITMASK - uint(maskBits))
+	mask := net.CIDRMask(maskBits, highestBitmask)
 	return CIDRBlock{
-		CIDRSize: cidrSize,
-		firstIP:  ip,
+		network: &net.IPNet{
+			IP:   ip.ip.Mask(mask),
+			Mask: mask,
+		},
 	}, nil
 }
 
+func (c CIDRBlock) IsIPv6() bool {
+	return len(c.network.IP) == net.IPv6len
+}
+
+// Prefix returns the number of mask bits of the block.
+func (c CIDRBlock) Prefix() int {
+	ones, _ := c.network.Mask.Size()
+	return ones
+}
+
+// Size returns the number of addresses in the block.
+func (c CIDRBlock) Size() *big.Int {
+	ones, bits := c.network.Mask.Size()
+	return new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+}
+
 func (c CIDRBlock) GetFirstIP() IP {
-	return c.firstIP
+	return IP{ip: c.network.IP}
 }
 
 func (c CIDRBlock) GetLastIP() IP {
-	return c.firstIP.Add(c.CIDRSize - 1)
+	size := c.Size()
+	value := new(big.Int).SetBytes(c.network.IP)
+	value.Add(value, size.Sub(size, big.NewInt(1)))
+
+	return IP{ip: fromBigInt(value, len(c.network.IP))}
+}
+
+func (c CIDRBlock) Contains(ip IP) bool {
+	return c.IsIPv6() == ip.IsIPv6() && c.network.Contains(ip.ip)
+}
+
+// Overlaps reports whether the two blocks share any address.
+func (c CIDRBlock) Overlaps(other CIDRBlock) bool {
+	return c.Contains(other.GetFirstIP()) || other.Contains(c.GetFirstIP())
+}
+
+// Subnet splits the block into subnets newBits longer than its prefix and
+// returns the one at index, like terraform's cidrsubnet.
+func (c CIDRBlock) Subnet(newBits, index int) (CIDRBlock, error) {
+	ones, bits := c.network.Mask.Size()
+	prefix := ones + newBits
+	if newBits < 0 || prefix > bits {
+		return CIDRBlock{}, fmt.Errorf("cannot split %s into /%d subnets", c, prefix)
+	}
+
+	if index < 0 || big.NewInt(int64(index)).Cmp(new(big.Int).Lsh(big.NewInt(1), uint(newBits))) >= 0 {
+		return CIDRBlock{}, fmt.Errorf("%s has no /%d subnet at index %d", c, prefix, index)
+	}
+
+	offset := new(big.Int).Lsh(big.NewInt(int64(index)), uint(bits-prefix))
+	value := new(big.Int).SetBytes(c.network.IP)
+	value.Add(value, offset)
+
+	mask := net.CIDRMask(prefix, bits)
+	return CIDRBlock{
+		network: &net.IPNet{
+			IP:   fromBigInt(value, len(c.network.IP)),
+			Mask: mask,
+		},
+	}, nil
+}
+
+func (c CIDRBlock) String() string {
+	return c.network.String()
 }
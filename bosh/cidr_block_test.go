@@ -32,7 +32,107 @@ var _ = Describe("CIDRBlock", func() {
 		})
 	})
 
+	Describe("Size", func() {
+		It("returns the number of addresses in the cidr block", func() {
+			Expect(cidrBlock.Size().Int64()).To(Equal(int64(4096)))
+		})
+
+		It("does not overflow for ipv6 cidr blocks", func() {
+			ipv6Block, err := bosh.ParseCIDRBlock("2001:db8::/64")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ipv6Block.Size().String()).To(Equal("18446744073709551616"))
+		})
+	})
+
+	Describe("ipv6 cidr blocks", func() {
+		It("returns the first and last ip", func() {
+			ipv6Block, err := bosh.ParseCIDRBlock("2001:db8:0:1::/64")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ipv6Block.IsIPv6()).To(BeTrue())
+			Expect(ipv6Block.Prefix()).To(Equal(64))
+			Expect(ipv6Block.GetFirstIP().String()).To(Equal("2001:db8:0:1::"))
+			Expect(ipv6Block.GetLastIP().String()).To(Equal("2001:db8:0:1:ffff:ffff:ffff:ffff"))
+		})
+	})
+
+	Describe("Contains", func() {
+		It("returns true for ips in the cidr block", func() {
+			ip, err := bosh.ParseIP("10.0.31.255")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cidrBlock.Contains(ip)).To(BeTrue())
+		})
+
+		It("returns false for ips outside the cidr block", func() {
+			ip, err := bosh.ParseIP("10.0.32.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cidrBlock.Contains(ip)).To(BeFalse())
+		})
+
+		It("returns false for ips of another address family", func() {
+			ip, err := bosh.ParseIP("::ffff:10.0.16.1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cidrBlock.Contains(ip)).To(BeFalse())
+		})
+	})
+
+	Describe("Overlaps", func() {
+		It("detects overlapping cidr blocks", func() {
+			inner, err := bosh.ParseCIDRBlock("10.0.20.0/24")
+			Expect(err).NotTo(HaveOccurred())
+
+			outer, err := bosh.ParseCIDRBlock("10.0.0.0/16")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cidrBlock.Overlaps(inner)).To(BeTrue())
+			Expect(cidrBlock.Overlaps(outer)).To(BeTrue())
+		})
+
+		It("returns false for disjoint cidr blocks", func() {
+			other, err := bosh.ParseCIDRBlock("10.0.32.0/20")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cidrBlock.Overlaps(other)).To(BeFalse())
+		})
+	})
+
+	Describe("Subnet", func() {
+		It("splits the cidr block like terraform's cidrsubnet", func() {
+			vpc, err := bosh.ParseCIDRBlock("10.0.0.0/16")
+			Expect(err).NotTo(HaveOccurred())
+
+			subnet, err := vpc.Subnet(4, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subnet.String()).To(Equal("10.0.16.0/20"))
+		})
+
+		It("splits ipv6 cidr blocks", func() {
+			vpc, err := bosh.ParseCIDRBlock("2001:db8:1200::/56")
+			Expect(err).NotTo(HaveOccurred())
+
+			subnet, err := vpc.Subnet(8, 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subnet.String()).To(Equal("2001:db8:1200:3::/64"))
+		})
+
+		It("returns an error when the subnets would be too small", func() {
+			_, err := cidrBlock.Subnet(13, 0)
+			Expect(err).To(MatchError("cannot split 10.0.16.0/20 into /33 subnets"))
+		})
+
+		It("returns an error when the index is out of range", func() {
+			_, err := cidrBlock.Subnet(2, 4)
+			Expect(err).To(MatchError("10.0.16.0/20 has no /22 subnet at index 4"))
+		})
+	})
+
 	Describe("ParseCIDRBlock", func() {
+		It("masks the address to the start of the cidr block", func() {
+			block, err := bosh.ParseCIDRBlock("10.0.17.5/20")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(block.String()).To(Equal("10.0.16.0/20"))
+		})
+
 		Context("failure cases", func() {
 			It("returns an error when input string is not a valid CIDR block", func() {
 				_, err := bosh.ParseCIDRBlock("whatever")
@@ -53,6 +153,16 @@ var _ = Describe("CIDRBlock", func() {
 				_, err := bosh.ParseCIDRBlock("0.0.0.0/243")
 				Expect(err).To(MatchError(ContainSubstring("mask bits out of range")))
 			})
+
+			It("returns an error when ipv4 mask bits exceed 32", func() {
+				_, err := bosh.ParseCIDRBlock("10.0.0.0/33")
+				Expect(err).To(MatchError("mask bits out of range"))
+			})
+
+			It("returns an error when ipv6 mask bits exceed 128", func() {
+				_, err := bosh.ParseCIDRBlock("2001:db8::/129")
+				Expect(err).To(MatchError("mask bits out of range"))
+			})
 		})
 	})
 })
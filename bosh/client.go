@@ -3,48 +3,165 @@ package bosh
 import (
 	"bytes"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
+var taskPollInterval = 2 * time.Second
+
+// configsVersion is the first director version with the generic /configs
+// API, which named cloud configs and runtime configs are uploaded through.
+const configsVersion = 263
+
 type Client interface {
 	UpdateCloudConfig(yaml []byte) error
+	CloudConfig() (string, error)
+	UpdateRuntimeConfig(name string, yaml []byte) error
+	UpdateCPIConfig(yaml []byte) error
+	Config(configType, name string) (string, error)
+	UpdateConfig(configType, name string, yaml []byte) error
+	DeleteConfig(configType, name string) error
 	Info() (Info, error)
+	Deployments() ([]Deployment, error)
+	VMs(deployment string) ([]VM, error)
+	Tasks(states ...string) ([]Task, error)
+	Task(id int) (Task, error)
+	WaitForTask(id int) (Task, error)
 }
 
 type Info struct {
+	Name               string             `json:"name"`
+	UUID               string             `json:"uuid"`
+	Version            string             `json:"version"`
+	UserAuthentication UserAuthentication `json:"user_authentication"`
+}
+
+// SupportsConfigs tells whether the director has the generic /configs API.
+// Directors before v263, such as the v260.5 director bbl deploys, only have
+// the unnamed /cloud_configs and /runtime_configs. A version that cannot be
+// parsed is assumed to be recent.
+func (i Info) SupportsConfigs() bool {
+	fields := strings.Fields(i.Version)
+	if len(fields) == 0 {
+		return true
+	}
+
+	major, err := strconv.Atoi(strings.Split(fields[0], ".")[0])
+	if err != nil {
+		return true
+	}
+
+	return major >= configsVersion
+}
+
+type UserAuthentication struct {
+	Type    string `json:"type"`
+	Options struct {
+		URL string `json:"url"`
+	} `json:"options"`
+}
+
+type Deployment struct {
+	Name        string        `json:"name"`
+	CloudConfig string        `json:"cloud_config"`
+	Releases    []NameVersion `json:"releases"`
+	Stemcells   []NameVersion `json:"stemcells"`
+}
+
+type NameVersion struct {
 	Name    string `json:"name"`
-	UUID    string `json:"uuid"`
 	Version string `json:"version"`
 }
 
+type VM struct {
+	AgentID string `json:"agent_id"`
+	CID     string `json:"cid"`
+	Job     string `json:"job"`
+	Index   int    `json:"index"`
+	ID      string `json:"id"`
+}
+
+type Task struct {
+	ID          int    `json:"id"`
+	State       string `json:"state"`
+	Description string `json:"description"`
+	Timestamp   int64  `json:"timestamp"`
+	Result      string `json:"result"`
+	User        string `json:"user"`
+	Deployment  string `json:"deployment"`
+}
+
+func (t Task) IsRunning() bool {
+	switch t.State {
+	case "queued", "processing", "cancelling":
+		return true
+	}
+
+	return false
+}
+
 type client struct {
 	directorAddress string
 	username        string
 	password        string
 	httpClient      *http.Client
+
+	// caCertErr is returned by every request when caCert is not valid PEM,
+	// as NewClient cannot return an error.
+	caCertErr error
+
+	// info and token are fetched on the first authenticated request and
+	// reused until the director rejects the token.
+	info  *Info
+	token string
 }
 
-func NewClient(directorAddress, username, password string) Client {
+// NewClient returns a client for the director API. The director certificate
+// is verified against caCert, or the system roots when caCert is empty,
+// unless skipSSLValidation is set.
+func NewClient(directorAddress, username, password, caCert string, skipSSLValidation bool) Client {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: skipSSLValidation,
+	}
+
+	var caCertErr error
+	if caCert != "" && !skipSSLValidation {
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM([]byte(caCert)) {
+			caCertErr = errors.New("director CA cert is not valid PEM")
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
 	httpClient := &http.Client{
 		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
+			TLSClientConfig: tlsConfig,
 		},
 	}
 
-	return client{
+	return &client{
 		directorAddress: directorAddress,
 		username:        username,
 		password:        password,
 		httpClient:      httpClient,
+		caCertErr:       caCertErr,
 	}
 }
 
-func (c client) Info() (Info, error) {
+// Info fetches the director info. Every other request fetches it first, so
+// it is where an invalid director CA cert is reported.
+func (c *client) Info() (Info, error) {
+	if c.caCertErr != nil {
+		return Info{}, c.caCertErr
+	}
+
 	request, err := http.NewRequest("GET", fmt.Sprintf("%s/info", c.directorAddress), strings.NewReader(""))
 	if err != nil {
 		return Info{}, err
@@ -54,6 +171,7 @@ func (c client) Info() (Info, error) {
 	if err != nil {
 		return Info{}, err
 	}
+	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusOK {
 		return Info{}, fmt.Errorf("unexpected http response %d %s", response.StatusCode, http.StatusText(response.StatusCode))
@@ -67,18 +185,184 @@ func (c client) Info() (Info, error) {
 	return info, nil
 }
 
-func (c client) UpdateCloudConfig(yaml []byte) error {
-	request, err := http.NewRequest("POST", fmt.Sprintf("%s/cloud_configs", c.directorAddress), bytes.NewBuffer(yaml))
+func (c *client) UpdateCloudConfig(yaml []byte) error {
+	return c.post("/cloud_configs", yaml)
+}
+
+func (c *client) CloudConfig() (string, error) {
+	var cloudConfigs []struct {
+		Properties string `json:"properties"`
+	}
+
+	err := c.get("/cloud_configs?limit=1", &cloudConfigs)
 	if err != nil {
+		return "", err
+	}
+
+	if len(cloudConfigs) == 0 {
+		return "", nil
+	}
+
+	return cloudConfigs[0].Properties, nil
+}
+
+func (c *client) UpdateRuntimeConfig(name string, yaml []byte) error {
+	path := "/runtime_configs"
+	if name != "" {
+		path = fmt.Sprintf("%s?name=%s", path, url.QueryEscape(name))
+	}
+
+	return c.post(path, yaml)
+}
+
+func (c *client) UpdateCPIConfig(yaml []byte) error {
+	return c.post("/cpi_configs", yaml)
+}
+
+// Config returns the latest named config of the given type, for example the
+// "bbl" cloud config, or an empty string when it does not exist.
+func (c *client) Config(configType, name string) (string, error) {
+	var configs []struct {
+		Content string `json:"content"`
+	}
+
+	err := c.get(fmt.Sprintf("/configs?latest=true&type=%s&name=%s", url.QueryEscape(configType), url.QueryEscape(name)), &configs)
+	if err != nil {
+		return "", err
+	}
+
+	if len(configs) == 0 {
+		return "", nil
+	}
+
+	return configs[0].Content, nil
+}
+
+func (c *client) UpdateConfig(configType, name string, yaml []byte) error {
+	body, err := json.Marshal(map[string]string{
+		"type":    configType,
+		"name":    name,
+		"content": string(yaml),
+	})
+	if err != nil {
+		// not tested
 		return err
 	}
-	request.Header.Set("Content-Type", "text/yaml")
-	request.SetBasicAuth(c.username, c.password)
 
-	response, err := c.httpClient.Do(request)
+	response, err := c.do("POST", "/configs", "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusCreated && response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected http response %d %s", response.StatusCode, http.StatusText(response.StatusCode))
+	}
+
+	return nil
+}
+
+// DeleteConfig removes a named config. Deleting a config that does not exist
+// is not an error.
+func (c *client) DeleteConfig(configType, name string) error {
+	response, err := c.do("DELETE", fmt.Sprintf("/configs?type=%s&name=%s", url.QueryEscape(configType), url.QueryEscape(name)), "", nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+
+	return fmt.Errorf("unexpected http response %d %s", response.StatusCode, http.StatusText(response.StatusCode))
+}
+
+func (c *client) Deployments() ([]Deployment, error) {
+	var deployments []Deployment
+	err := c.get("/deployments", &deployments)
+	if err != nil {
+		return nil, err
+	}
+
+	return deployments, nil
+}
+
+func (c *client) VMs(deployment string) ([]VM, error) {
+	var vms []VM
+	err := c.get(fmt.Sprintf("/deployments/%s/vms", url.QueryEscape(deployment)), &vms)
+	if err != nil {
+		return nil, err
+	}
+
+	return vms, nil
+}
+
+func (c *client) Tasks(states ...string) ([]Task, error) {
+	path := "/tasks?verbose=1"
+	if len(states) > 0 {
+		path = fmt.Sprintf("%s&state=%s", path, url.QueryEscape(strings.Join(states, ",")))
+	}
+
+	var tasks []Task
+	err := c.get(path, &tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func (c *client) Task(id int) (Task, error) {
+	var task Task
+	err := c.get(fmt.Sprintf("/tasks/%d", id), &task)
+	if err != nil {
+		return Task{}, err
+	}
+
+	return task, nil
+}
+
+func (c *client) WaitForTask(id int) (Task, error) {
+	for {
+		task, err := c.Task(id)
+		if err != nil {
+			return Task{}, err
+		}
+
+		if !task.IsRunning() {
+			if task.State != "done" {
+				return task, fmt.Errorf("task %d %s: %s", task.ID, task.State, task.Result)
+			}
+
+			return task, nil
+		}
+
+		time.Sleep(taskPollInterval)
+	}
+}
+
+func (c *client) get(path string, result interface{}) error {
+	response, err := c.do("GET", path, "", nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected http response %d %s", response.StatusCode, http.StatusText(response.StatusCode))
+	}
+
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+func (c *client) post(path string, yaml []byte) error {
+	response, err := c.do("POST", path, "text/yaml", yaml)
 	if err != nil {
 		return err
 	}
+	defer response.Body.Close()
 
 	if response.StatusCode != http.StatusCreated {
 		return fmt.Errorf("unexpected http response %d %s", response.StatusCode, http.StatusText(response.StatusCode))
@@ -86,3 +370,104 @@ func (c client) UpdateCloudConfig(yaml []byte) error {
 
 	return nil
 }
+
+// do sends an authenticated request to the director. A uaa token that the
+// director rejects, for example because it expired, is fetched again and
+// the request is retried once.
+func (c *client) do(method, path, contentType string, body []byte) (*http.Response, error) {
+	response, err := c.send(method, path, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusUnauthorized && c.token != "" {
+		response.Body.Close()
+
+		c.info = nil
+		c.token = ""
+
+		return c.send(method, path, contentType, body)
+	}
+
+	return response, nil
+}
+
+func (c *client) send(method, path, contentType string, body []byte) (*http.Response, error) {
+	request, err := http.NewRequest(method, fmt.Sprintf("%s%s", c.directorAddress, path), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	if err := c.authorize(request); err != nil {
+		return nil, err
+	}
+
+	return c.httpClient.Do(request)
+}
+
+func (c *client) authorize(request *http.Request) error {
+	if c.info == nil {
+		info, err := c.Info()
+		if err != nil {
+			return err
+		}
+		c.info = &info
+	}
+
+	if c.info.UserAuthentication.Type != "uaa" {
+		request.SetBasicAuth(c.username, c.password)
+		return nil
+	}
+
+	if c.token == "" {
+		token, err := c.uaaToken(c.info.UserAuthentication.Options.URL)
+		if err != nil {
+			return err
+		}
+		c.token = token
+	}
+
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+
+	return nil
+}
+
+func (c *client) uaaToken(uaaURL string) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+
+	request, err := http.NewRequest("POST", fmt.Sprintf("%s/oauth/token", uaaURL), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	request.SetBasicAuth(c.username, c.password)
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get uaa token: unexpected http response %d %s", response.StatusCode, http.StatusText(response.StatusCode))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return "", err
+	}
+
+	if token.AccessToken == "" {
+		return "", errors.New("failed to get uaa token: response did not include an access token")
+	}
+
+	return token.AccessToken, nil
+}
@@ -1,11 +1,15 @@
 package bosh
 
-type ClientProvider struct{}
+type ClientProvider struct {
+	skipSSLValidation bool
+}
 
-func NewClientProvider() ClientProvider {
-	return ClientProvider{}
+func NewClientProvider(skipSSLValidation bool) ClientProvider {
+	return ClientProvider{
+		skipSSLValidation: skipSSLValidation,
+	}
 }
 
-func (ClientProvider) Client(directorAddress, directorUsername, directorPassword string) Client {
-	return NewClient(directorAddress, directorUsername, directorPassword)
+func (c ClientProvider) Client(directorAddress, directorUsername, directorPassword, directorCACert string) Client {
+	return NewClient(directorAddress, directorUsername, directorPassword, directorCACert, c.skipSSLValidation)
 }
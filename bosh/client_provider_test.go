@@ -1,26 +1,53 @@
 package bosh_test
 
 import (
+	"net/http/httptest"
+
+	"github.com/cloudfoundry/bosh-bootloader/bbl/fakebosh/director"
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
-	"github.com/cloudfoundry/bosh-bootloader/bosh"
 )
 
 var _ = Describe("Client Provider", func() {
 	Describe("Client", func() {
 		var (
-			clientProvider bosh.ClientProvider
+			fakeBOSH *httptest.Server
 		)
 
 		BeforeEach(func() {
-			clientProvider = bosh.NewClientProvider()
+			fakeBOSH = httptest.NewTLSServer(director.New("some-director-username", "some-director-password"))
+		})
+
+		AfterEach(func() {
+			fakeBOSH.Close()
 		})
 
 		It("returns a bosh client", func() {
-			boshClient := clientProvider.Client("some-director-address", "some-director-username", "some-director-password")
+			clientProvider := bosh.NewClientProvider(false)
+			boshClient := clientProvider.Client("some-director-address", "some-director-username", "some-director-password", "some-director-ca-cert")
 
 			_, ok := boshClient.(bosh.Client)
 			Expect(ok).To(BeTrue())
 		})
+
+		It("returns a client that validates the director certificate", func() {
+			clientProvider := bosh.NewClientProvider(false)
+			boshClient := clientProvider.Client(fakeBOSH.URL, "some-director-username", "some-director-password", "")
+
+			_, err := boshClient.Info()
+			Expect(err).To(MatchError(ContainSubstring("certificate signed by unknown authority")))
+		})
+
+		Context("when director ssl validation is skipped", func() {
+			It("returns a client that does not validate the director certificate", func() {
+				clientProvider := bosh.NewClientProvider(true)
+				boshClient := clientProvider.Client(fakeBOSH.URL, "some-director-username", "some-director-password", "")
+
+				_, err := boshClient.Info()
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
 	})
 })
@@ -1,18 +1,92 @@
 package bosh_test
 
 import (
+	"encoding/pem"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
+	"time"
 
+	"github.com/cloudfoundry/bosh-bootloader/bbl/fakebosh/director"
 	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/testhelpers"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("Client", func() {
+	Describe("NewClient", func() {
+		var fakeBOSH *httptest.Server
+
+		BeforeEach(func() {
+			fakeBOSH = httptest.NewTLSServer(director.New("some-username", "some-password"))
+		})
+
+		AfterEach(func() {
+			fakeBOSH.Close()
+		})
+
+		It("verifies the director certificate against the given ca cert", func() {
+			caCert := pem.EncodeToMemory(&pem.Block{
+				Type:  "CERTIFICATE",
+				Bytes: fakeBOSH.Certificate().Raw,
+			})
+
+			client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password", string(caCert), false)
+			_, err := client.Info()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns an error when the director certificate is not signed by the given ca cert", func() {
+			client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password", testhelpers.BBL_CHAIN, false)
+			_, err := client.Info()
+			Expect(err).To(MatchError(ContainSubstring("certificate signed by unknown authority")))
+		})
+
+		It("returns an error when the director hostname does not match the certificate", func() {
+			caCert := pem.EncodeToMemory(&pem.Block{
+				Type:  "CERTIFICATE",
+				Bytes: fakeBOSH.Certificate().Raw,
+			})
+
+			directorAddress := strings.Replace(fakeBOSH.URL, "127.0.0.1", "localhost", 1)
+			client := bosh.NewClient(directorAddress, "some-username", "some-password", string(caCert), false)
+			_, err := client.Info()
+			Expect(err).To(MatchError(ContainSubstring("not localhost")))
+		})
+
+		It("verifies the director certificate against the system roots when no ca cert is given", func() {
+			client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password", "", false)
+			_, err := client.Info()
+			Expect(err).To(MatchError(ContainSubstring("certificate signed by unknown authority")))
+		})
+
+		It("returns an error on the first request when the ca cert is not valid PEM", func() {
+			client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password", "some-invalid-ca-cert", false)
+			_, err := client.Info()
+			Expect(err).To(MatchError("director CA cert is not valid PEM"))
+
+			_, err = client.Deployments()
+			Expect(err).To(MatchError("director CA cert is not valid PEM"))
+		})
+
+		It("ignores an invalid ca cert when ssl validation is skipped", func() {
+			client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password", "some-invalid-ca-cert", true)
+			_, err := client.Info()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("skips verification when ssl validation is skipped", func() {
+			client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password", testhelpers.BBL_CHAIN, true)
+			_, err := client.Info()
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
 	Describe("Info", func() {
 		It("returns the director info", func() {
 			fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
@@ -23,7 +97,7 @@ var _ = Describe("Client", func() {
 				}`))
 			}))
 
-			client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password")
+			client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password", "", true)
 			info, err := client.Info()
 			Expect(err).NotTo(HaveOccurred())
 			Expect(info).To(Equal(bosh.Info{
@@ -39,19 +113,19 @@ var _ = Describe("Client", func() {
 					responseWriter.WriteHeader(http.StatusNotFound)
 				}))
 
-				client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password")
+				client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password", "", true)
 				_, err := client.Info()
 				Expect(err).To(MatchError("unexpected http response 404 Not Found"))
 			})
 
 			It("returns an error when the url cannot be parsed", func() {
-				client := bosh.NewClient("%%%", "some-username", "some-password")
+				client := bosh.NewClient("%%%", "some-username", "some-password", "", true)
 				_, err := client.Info()
 				Expect(err.(*url.Error).Op).To(Equal("parse"))
 			})
 
 			It("returns an error when the request fails", func() {
-				client := bosh.NewClient("fake://some-url", "some-username", "some-password")
+				client := bosh.NewClient("fake://some-url", "some-username", "some-password", "", true)
 				_, err := client.Info()
 				Expect(err).To(MatchError(ContainSubstring("unsupported protocol scheme")))
 			})
@@ -61,7 +135,7 @@ var _ = Describe("Client", func() {
 					responseWriter.Write([]byte(`%%%`))
 				}))
 
-				client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password")
+				client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password", "", true)
 				_, err := client.Info()
 				Expect(err).To(MatchError(ContainSubstring("invalid character")))
 			})
@@ -70,6 +144,20 @@ var _ = Describe("Client", func() {
 
 	})
 
+	Describe("Info.SupportsConfigs", func() {
+		DescribeTable("tells whether the director has the configs api from its version",
+			func(version string, supported bool) {
+				Expect(bosh.Info{Version: version}.SupportsConfigs()).To(Equal(supported))
+			},
+			Entry("the director bbl deploys", "260.5.0 (00000000)", false),
+			Entry("a director before the configs api", "262.3.0 (00000000)", false),
+			Entry("an old style version", "1.3262.0.0 (00000000)", false),
+			Entry("the first director with the configs api", "263.0.0 (00000000)", true),
+			Entry("a later director", "270.2.0 (00000000)", true),
+			Entry("a version that cannot be parsed", "some-version", true),
+		)
+	})
+
 	Describe("UpdateCloudConfig", func() {
 		It("uploads the given cloud config", func() {
 			var (
@@ -84,6 +172,11 @@ var _ = Describe("Client", func() {
 					err error
 				)
 
+				if request.URL.Path == "/info" {
+					responseWriter.Write([]byte(`{}`))
+					return
+				}
+
 				username, password, _ = request.BasicAuth()
 				contentType = request.Header.Get("Content-Type")
 
@@ -93,7 +186,7 @@ var _ = Describe("Client", func() {
 				responseWriter.WriteHeader(http.StatusCreated)
 			}))
 
-			client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password")
+			client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password", "", true)
 
 			err := client.UpdateCloudConfig([]byte("cloud: config"))
 			Expect(err).NotTo(HaveOccurred())
@@ -107,17 +200,22 @@ var _ = Describe("Client", func() {
 		Context("failure cases", func() {
 			It("returns an error when the status code is not StatusCreated", func() {
 				fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+					if request.URL.Path == "/info" {
+						responseWriter.Write([]byte(`{}`))
+						return
+					}
+
 					responseWriter.WriteHeader(http.StatusInternalServerError)
 				}))
 
-				client := bosh.NewClient(fakeBOSH.URL, "", "")
+				client := bosh.NewClient(fakeBOSH.URL, "", "", "", true)
 
 				err := client.UpdateCloudConfig([]byte("cloud: config"))
 				Expect(err).To(MatchError("unexpected http response 500 Internal Server Error"))
 			})
 
 			It("returns an error when the director address is malformed", func() {
-				client := bosh.NewClient("%%%%%%%%%%%%%%%", "", "")
+				client := bosh.NewClient("%%%%%%%%%%%%%%%", "", "", "", true)
 
 				err := client.UpdateCloudConfig([]byte("cloud: config"))
 				Expect(err.(*url.Error).Op).To(Equal("parse"))
@@ -128,7 +226,7 @@ var _ = Describe("Client", func() {
 					responseWriter.WriteHeader(http.StatusInternalServerError)
 				}))
 
-				client := bosh.NewClient(fakeBOSH.URL, "", "")
+				client := bosh.NewClient(fakeBOSH.URL, "", "", "", true)
 
 				fakeBOSH.Close()
 
@@ -137,4 +235,300 @@ var _ = Describe("Client", func() {
 			})
 		})
 	})
+
+	Context("against a fake director", func() {
+		var (
+			fakeDirector *director.Director
+			fakeBOSH     *httptest.Server
+			client       bosh.Client
+		)
+
+		BeforeEach(func() {
+			fakeDirector = director.New("some-username", "some-password")
+			fakeBOSH = httptest.NewTLSServer(fakeDirector)
+
+			client = bosh.NewClient(fakeBOSH.URL, "some-username", "some-password", "", true)
+		})
+
+		AfterEach(func() {
+			fakeBOSH.Close()
+		})
+
+		It("fetches the director info once", func() {
+			_, err := client.CloudConfig()
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.Deployments()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeDirector.Requests("/info")).To(Equal(1))
+		})
+
+		It("does not retry requests the director rejects with basic auth", func() {
+			client = bosh.NewClient(fakeBOSH.URL, "some-username", "wrong-password", "", true)
+
+			_, err := client.CloudConfig()
+			Expect(err).To(MatchError("unexpected http response 401 Unauthorized"))
+
+			Expect(fakeDirector.Requests("/cloud_configs")).To(Equal(1))
+		})
+
+		Describe("CloudConfig", func() {
+			It("returns the current cloud config", func() {
+				fakeDirector.SetCloudConfig("vm_types: []")
+
+				cloudConfig, err := client.CloudConfig()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cloudConfig).To(Equal("vm_types: []"))
+			})
+
+			It("returns an empty cloud config when none has been uploaded", func() {
+				cloudConfig, err := client.CloudConfig()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cloudConfig).To(BeEmpty())
+			})
+
+			It("returns an error when the credentials are wrong", func() {
+				client = bosh.NewClient(fakeBOSH.URL, "some-username", "wrong-password", "", true)
+
+				_, err := client.CloudConfig()
+				Expect(err).To(MatchError("unexpected http response 401 Unauthorized"))
+			})
+		})
+
+		Describe("UpdateRuntimeConfig", func() {
+			It("uploads a named runtime config", func() {
+				err := client.UpdateRuntimeConfig("some-name", []byte("addons: []"))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeDirector.RuntimeConfig("some-name")).To(Equal("addons: []"))
+			})
+
+			It("uploads the default runtime config when no name is given", func() {
+				err := client.UpdateRuntimeConfig("", []byte("addons: []"))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeDirector.RuntimeConfig("")).To(Equal("addons: []"))
+			})
+		})
+
+		Describe("Config", func() {
+			It("returns the named config of the given type", func() {
+				fakeDirector.SetConfig("cloud", "bbl", "vm_types: []")
+
+				config, err := client.Config("cloud", "bbl")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(config).To(Equal("vm_types: []"))
+			})
+
+			It("returns an empty config when it does not exist", func() {
+				fakeDirector.SetConfig("cloud", "some-team-config", "vm_types: []")
+
+				config, err := client.Config("cloud", "bbl")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(config).To(BeEmpty())
+			})
+		})
+
+		Describe("UpdateConfig", func() {
+			It("uploads a named config without touching other configs", func() {
+				fakeDirector.SetConfig("cloud", "some-team-config", "vm_types: [{name: team}]")
+
+				err := client.UpdateConfig("cloud", "bbl", []byte("vm_types: []"))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeDirector.Config("cloud", "bbl")).To(Equal("vm_types: []"))
+				Expect(fakeDirector.Config("cloud", "some-team-config")).To(Equal("vm_types: [{name: team}]"))
+			})
+
+			It("returns an error when the credentials are wrong", func() {
+				client = bosh.NewClient(fakeBOSH.URL, "some-username", "wrong-password", "", true)
+
+				err := client.UpdateConfig("runtime", "bbl", []byte("addons: []"))
+				Expect(err).To(MatchError("unexpected http response 401 Unauthorized"))
+			})
+		})
+
+		Describe("DeleteConfig", func() {
+			It("deletes a named config", func() {
+				fakeDirector.SetConfig("runtime", "bbl", "addons: []")
+
+				err := client.DeleteConfig("runtime", "bbl")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeDirector.Config("runtime", "bbl")).To(BeEmpty())
+			})
+
+			It("does not return an error when the config does not exist", func() {
+				err := client.DeleteConfig("runtime", "bbl")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns an error when the credentials are wrong", func() {
+				client = bosh.NewClient(fakeBOSH.URL, "some-username", "wrong-password", "", true)
+
+				err := client.DeleteConfig("runtime", "bbl")
+				Expect(err).To(MatchError("unexpected http response 401 Unauthorized"))
+			})
+		})
+
+		Describe("UpdateCPIConfig", func() {
+			It("uploads the cpi config", func() {
+				err := client.UpdateCPIConfig([]byte("cpis: []"))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeDirector.CPIConfig()).To(Equal("cpis: []"))
+			})
+		})
+
+		Describe("Deployments", func() {
+			It("returns the deployments", func() {
+				fakeDirector.SetDeployments([]director.Deployment{
+					{
+						Name:        "some-deployment",
+						CloudConfig: "latest",
+						Releases:    []director.NameVersion{{Name: "some-release", Version: "1"}},
+						Stemcells:   []director.NameVersion{{Name: "some-stemcell", Version: "2"}},
+					},
+				})
+
+				deployments, err := client.Deployments()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deployments).To(Equal([]bosh.Deployment{
+					{
+						Name:        "some-deployment",
+						CloudConfig: "latest",
+						Releases:    []bosh.NameVersion{{Name: "some-release", Version: "1"}},
+						Stemcells:   []bosh.NameVersion{{Name: "some-stemcell", Version: "2"}},
+					},
+				}))
+			})
+		})
+
+		Describe("VMs", func() {
+			It("returns the vms of a deployment", func() {
+				fakeDirector.SetDeployments([]director.Deployment{
+					{
+						Name: "some-deployment",
+						VMs: []director.VM{
+							{AgentID: "some-agent-id", CID: "some-cid", Job: "some-job", Index: 1, ID: "some-id"},
+						},
+					},
+				})
+
+				vms, err := client.VMs("some-deployment")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(vms).To(Equal([]bosh.VM{
+					{AgentID: "some-agent-id", CID: "some-cid", Job: "some-job", Index: 1, ID: "some-id"},
+				}))
+			})
+
+			It("returns an error when the deployment does not exist", func() {
+				_, err := client.VMs("some-missing-deployment")
+				Expect(err).To(MatchError("unexpected http response 404 Not Found"))
+			})
+		})
+
+		Describe("Tasks", func() {
+			BeforeEach(func() {
+				fakeDirector.SetTask(director.Task{ID: 1, State: "done"})
+				fakeDirector.SetTask(director.Task{ID: 2, State: "processing", Deployment: "some-deployment"})
+			})
+
+			It("returns all tasks", func() {
+				tasks, err := client.Tasks()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tasks).To(Equal([]bosh.Task{
+					{ID: 1, State: "done"},
+					{ID: 2, State: "processing", Deployment: "some-deployment"},
+				}))
+			})
+
+			It("filters tasks by state", func() {
+				tasks, err := client.Tasks("queued", "processing")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tasks).To(Equal([]bosh.Task{
+					{ID: 2, State: "processing", Deployment: "some-deployment"},
+				}))
+			})
+		})
+
+		Describe("WaitForTask", func() {
+			BeforeEach(func() {
+				bosh.SetTaskPollInterval(time.Millisecond)
+			})
+
+			AfterEach(func() {
+				bosh.ResetTaskPollInterval()
+			})
+
+			It("polls the task until it finishes", func() {
+				fakeDirector.SetTask(
+					director.Task{ID: 3, State: "queued"},
+					director.Task{ID: 3, State: "processing"},
+					director.Task{ID: 3, State: "done", Result: "some-result"},
+				)
+
+				task, err := client.WaitForTask(3)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(task).To(Equal(bosh.Task{ID: 3, State: "done", Result: "some-result"}))
+			})
+
+			It("returns an error when the task fails", func() {
+				fakeDirector.SetTask(
+					director.Task{ID: 4, State: "processing"},
+					director.Task{ID: 4, State: "error", Result: "some-error"},
+				)
+
+				_, err := client.WaitForTask(4)
+				Expect(err).To(MatchError("task 4 error: some-error"))
+			})
+
+			It("returns an error when the task cannot be found", func() {
+				_, err := client.WaitForTask(5)
+				Expect(err).To(MatchError("unexpected http response 404 Not Found"))
+			})
+		})
+
+		Context("when the director uses uaa", func() {
+			BeforeEach(func() {
+				fakeDirector.EnableUAA(fakeBOSH.URL, "some-token")
+			})
+
+			It("authenticates with a uaa token", func() {
+				err := client.UpdateCloudConfig([]byte("vm_types: []"))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeDirector.CloudConfig()).To(Equal("vm_types: []"))
+			})
+
+			It("fetches the director info and the uaa token once", func() {
+				Expect(client.UpdateCloudConfig([]byte("vm_types: []"))).To(Succeed())
+				Expect(client.UpdateCPIConfig([]byte("cpi: config"))).To(Succeed())
+				_, err := client.Deployments()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeDirector.Requests("/info")).To(Equal(1))
+				Expect(fakeDirector.Requests("/oauth/token")).To(Equal(1))
+			})
+
+			It("fetches a new uaa token and retries when the director rejects the token", func() {
+				Expect(client.UpdateCloudConfig([]byte("vm_types: []"))).To(Succeed())
+
+				fakeDirector.SetToken("some-other-token")
+
+				Expect(client.UpdateCloudConfig([]byte("vm_types: [other]"))).To(Succeed())
+
+				Expect(fakeDirector.CloudConfig()).To(Equal("vm_types: [other]"))
+				Expect(fakeDirector.Requests("/oauth/token")).To(Equal(2))
+				Expect(fakeDirector.Requests("/cloud_configs")).To(Equal(3))
+			})
+
+			It("returns an error when the uaa credentials are wrong", func() {
+				client = bosh.NewClient(fakeBOSH.URL, "some-username", "wrong-password", "", true)
+
+				err := client.UpdateCloudConfig([]byte("vm_types: []"))
+				Expect(err).To(MatchError("failed to get uaa token: unexpected http response 401 Unauthorized"))
+			})
+		})
+	})
 })
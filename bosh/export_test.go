@@ -0,0 +1,11 @@
+package bosh
+
+import "time"
+
+func SetTaskPollInterval(interval time.Duration) {
+	taskPollInterval = interval
+}
+
+func ResetTaskPollInterval() {
+	taskPollInterval = 2 * time.Second
+}
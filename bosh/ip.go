@@ -1,16 +1,27 @@
 package bosh
 
 import (
+	"bytes"
 	"fmt"
+	"math/big"
+	"net"
 	"strconv"
 	"strings"
 )
 
 type IP struct {
-	ip int
+	ip net.IP
 }
 
 func ParseIP(ip string) (IP, error) {
+	if strings.Contains(ip, ":") {
+		return parseIPv6(ip)
+	}
+
+	return parseIPv4(ip)
+}
+
+func parseIPv4(ip string) (IP, error) {
 	const IP_PARTS = 4
 	const MAX_IP_PART = 256
 
@@ -20,8 +31,8 @@ func ParseIP(ip string) (IP, error) {
 		return IP{}, fmt.Errorf(`'%s' is not a valid ip address`, ip)
 	}
 
-	ipValue := 0
-	for _, ipPart := range ipParts {
+	parsedIP := make(net.IP, net.IPv4len)
+	for i, ipPart := range ipParts {
 		ipPartInt, err := strconv.Atoi(ipPart)
 		if err != nil {
 			return IP{}, err
@@ -31,30 +42,71 @@ func ParseIP(ip string) (IP, error) {
 			return IP{}, fmt.Errorf("invalid ip, %s has values out of range", ip)
 		}
 
-		ipValue = ipValue*MAX_IP_PART + ipPartInt
+		parsedIP[i] = byte(ipPartInt)
 	}
 
 	return IP{
-		ip: ipValue,
+		ip: parsedIP,
 	}, nil
 }
 
-func (i IP) Add(offset int) IP {
-	return IP{
-		ip: i.ip + offset,
+func parseIPv6(ip string) (IP, error) {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return IP{}, fmt.Errorf(`'%s' is not a valid ip address`, ip)
 	}
+
+	return IP{
+		ip: parsedIP.To16(),
+	}, nil
+}
+
+func (i IP) IsIPv6() bool {
+	return len(i.ip) == net.IPv6len
+}
+
+// Add returns the IP offset by the given number of addresses, wrapping
+// around within the address family.
+func (i IP) Add(offset int) IP {
+	return i.offset(big.NewInt(int64(offset)))
 }
 
 func (i IP) Subtract(offset int) IP {
+	return i.offset(big.NewInt(-int64(offset)))
+}
+
+func (i IP) offset(offset *big.Int) IP {
+	size := new(big.Int).Lsh(big.NewInt(1), uint(len(i.ip)*8))
+
+	value := new(big.Int).SetBytes(i.ip)
+	value.Add(value, offset)
+	value.Mod(value, size)
+
 	return IP{
-		ip: i.ip - offset,
+		ip: fromBigInt(value, len(i.ip)),
 	}
 }
 
+// Compare returns -1, 0 or 1 when the IP is lower than, equal to or higher
+// than other. IPv4 addresses are lower than every IPv6 address.
+func (i IP) Compare(other IP) int {
+	switch {
+	case len(i.ip) < len(other.ip):
+		return -1
+	case len(i.ip) > len(other.ip):
+		return 1
+	}
+
+	return bytes.Compare(i.ip, other.ip)
+}
+
 func (i IP) String() string {
-	first := i.ip & 0xff000000 >> 24
-	second := i.ip & 0xff0000 >> 16
-	third := i.ip & 0xff00 >> 8
-	fourth := i.ip & 0xff
-	return fmt.Sprintf("%v.%v.%v.%v", first, second, third, fourth)
+	return i.ip.String()
+}
+
+func fromBigInt(value *big.Int, length int) net.IP {
+	ip := make(net.IP, length)
+	valueBytes := value.Bytes()
+	copy(ip[length-len(valueBytes):], valueBytes)
+	return ip
 }
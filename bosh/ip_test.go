@@ -13,6 +13,14 @@ var _ = Describe("IP", func() {
 			ip, err := bosh.ParseIP("10.0.16.255")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(ip.String()).To(Equal("10.0.16.255"))
+			Expect(ip.IsIPv6()).To(BeFalse())
+		})
+
+		It("parses ipv6 addresses", func() {
+			ip, err := bosh.ParseIP("2001:0db8:0000:0000:0000:0000:0000:0001")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.String()).To(Equal("2001:db8::1"))
+			Expect(ip.IsIPv6()).To(BeTrue())
 		})
 
 		Context("failure cases", func() {
@@ -31,6 +39,11 @@ var _ = Describe("IP", func() {
 				Expect(err).To(MatchError(ContainSubstring("values out of range")))
 			})
 
+			It("returns an error if an ipv6 address is not valid", func() {
+				_, err := bosh.ParseIP("2001:db8::g")
+				Expect(err).To(MatchError("'2001:db8::g' is not a valid ip address"))
+			})
+
 			It("returns an error if ip has too many parts", func() {
 				_, err := bosh.ParseIP("1.1.1.1.1.1.1")
 				Expect(err).To(MatchError(ContainSubstring("not a valid ip address")))
@@ -45,6 +58,18 @@ var _ = Describe("IP", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(ip.String()).To(Equal("10.0.16.2"))
 		})
+
+		It("carries over into the next octet", func() {
+			ip, err := bosh.ParseIP("10.0.16.255")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.Add(2).String()).To(Equal("10.0.17.1"))
+		})
+
+		It("offsets ipv6 addresses", func() {
+			ip, err := bosh.ParseIP("2001:db8::ffff")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.Add(1).String()).To(Equal("2001:db8::1:0"))
+		})
 	})
 
 	Describe("Subtract", func() {
@@ -54,6 +79,37 @@ var _ = Describe("IP", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(ip.String()).To(Equal("10.0.16.1"))
 		})
+
+		It("offsets ipv6 addresses", func() {
+			ip, err := bosh.ParseIP("2001:db8::1:0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ip.Subtract(1).String()).To(Equal("2001:db8::ffff"))
+		})
+	})
+
+	Describe("Compare", func() {
+		It("orders ips numerically", func() {
+			low, err := bosh.ParseIP("10.0.16.9")
+			Expect(err).NotTo(HaveOccurred())
+
+			high, err := bosh.ParseIP("10.0.16.10")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(low.Compare(high)).To(Equal(-1))
+			Expect(high.Compare(low)).To(Equal(1))
+			Expect(low.Compare(low)).To(Equal(0))
+		})
+
+		It("orders ipv4 addresses before ipv6 addresses", func() {
+			ipv4, err := bosh.ParseIP("255.255.255.255")
+			Expect(err).NotTo(HaveOccurred())
+
+			ipv6, err := bosh.ParseIP("::1")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ipv4.Compare(ipv6)).To(Equal(-1))
+			Expect(ipv6.Compare(ipv4)).To(Equal(1))
+		})
 	})
 
 	Describe("String", func() {
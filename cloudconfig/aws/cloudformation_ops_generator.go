@@ -2,25 +2,33 @@ package aws
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
+	"github.com/cloudfoundry/bosh-bootloader/cloudconfig"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
 type CloudFormationOpsGenerator struct {
 	availabilityZoneRetriever availabilityZoneRetriever
 	infrastructureManager     infrastructureManager
+	instanceTypeRetriever     instanceTypeRetriever
+	logger                    logger
 }
 
 type infrastructureManager interface {
 	Describe(stackName string) (cloudformation.Stack, error)
 }
 
-func NewCloudFormationOpsGenerator(availabilityZoneRetriever availabilityZoneRetriever, infrastructureManager infrastructureManager) CloudFormationOpsGenerator {
+func NewCloudFormationOpsGenerator(availabilityZoneRetriever availabilityZoneRetriever, infrastructureManager infrastructureManager, instanceTypeRetriever instanceTypeRetriever, logger logger) CloudFormationOpsGenerator {
 	return CloudFormationOpsGenerator{
 		availabilityZoneRetriever: availabilityZoneRetriever,
 		infrastructureManager:     infrastructureManager,
+		instanceTypeRetriever:     instanceTypeRetriever,
+		logger:                    logger,
 	}
 }
 
@@ -35,9 +43,21 @@ func (a CloudFormationOpsGenerator) Generate(state storage.State) (string, error
 		return "", err
 	}
 
+	vmTypeOps, err := generateVMTypeOps(state, a.instanceTypeRetriever, a.logger)
+	if err != nil {
+		return "", err
+	}
+
+	vmTypeOpsYAML, err := marshal(vmTypeOps)
+	if err != nil {
+		return "", err
+	}
+
 	return strings.Join(
 		[]string{
 			BaseOps,
+			string(vmTypeOpsYAML),
+			BaseVMExtensionOps,
 			string(cloudConfigOpsYAML),
 		},
 		"\n",
@@ -66,33 +86,33 @@ func (a CloudFormationOpsGenerator) generateCloudFormationAWSOps(state storage.S
 		return []op{}, err
 	}
 
-	subnets := []networkSubnet{}
-	for i := range azs {
-		subnet, err := generateNetworkSubnet(
-			fmt.Sprintf("z%d", i+1),
-			stack.Outputs[fmt.Sprintf("InternalSubnet%dCIDR", i+1)],
-			stack.Outputs[fmt.Sprintf("InternalSubnet%dName", i+1)],
-			stack.Outputs["InternalSecurityGroup"],
-		)
-		if err != nil {
-			return []op{}, err
+	for _, networkName := range cloudconfig.NetworkNames {
+		reservedCount, staticCount := cloudconfig.NetworkIPCounts(state.CloudConfig, networkName)
+
+		subnets := []networkSubnet{}
+		for i := range azs {
+			subnet, err := generateNetworkSubnet(
+				fmt.Sprintf("z%d", i+1),
+				stack.Outputs[fmt.Sprintf("InternalSubnet%dCIDR", i+1)],
+				stack.Outputs[fmt.Sprintf("InternalSubnet%dName", i+1)],
+				stack.Outputs["InternalSecurityGroup"],
+				reservedCount,
+				staticCount,
+			)
+			if err != nil {
+				return []op{}, err
+			}
+
+			subnets = append(subnets, subnet)
 		}
 
-		subnets = append(subnets, subnet)
+		ops = append(ops, createOp("replace", "/networks/-", network{
+			Name:    networkName,
+			Subnets: subnets,
+			Type:    "manual",
+		}))
 	}
 
-	ops = append(ops, createOp("replace", "/networks/-", network{
-		Name:    "private",
-		Subnets: subnets,
-		Type:    "manual",
-	}))
-
-	ops = append(ops, createOp("replace", "/networks/-", network{
-		Name:    "default",
-		Subnets: subnets,
-		Type:    "manual",
-	}))
-
 	if value := stack.Outputs["CFRouterLoadBalancer"]; value != "" {
 		ops = append(ops, createOp("replace", "/vm_extensions/-", lb{
 			Name: "router-lb",
@@ -132,5 +152,52 @@ func (a CloudFormationOpsGenerator) generateCloudFormationAWSOps(state storage.S
 		}))
 	}
 
+	for _, spec := range templates.ExpandLBSpecs(state.LBs) {
+		prefix := templates.LBSpecResourcePrefix(spec.Name)
+
+		cloudProperties := lbCloudProperties{
+			SecurityGroups: []string{
+				stack.Outputs[prefix+"InternalSecurityGroup"],
+				stack.Outputs["InternalSecurityGroup"],
+			},
+		}
+
+		for _, flavor := range templates.LBSpecFlavors(spec) {
+			lbName := templates.LBSpecLoadBalancerName(flavor)
+			if flavor.Flavor == "alb" || flavor.Flavor == "nlb" {
+				cloudProperties.LBTargetGroups = append(cloudProperties.LBTargetGroups, targetGroups(stack.Outputs, lbName)...)
+			} else {
+				cloudProperties.ELBs = append(cloudProperties.ELBs, stack.Outputs[lbName])
+			}
+		}
+
+		ops = append(ops, createOp("replace", "/vm_extensions/-", lb{
+			Name:            fmt.Sprintf("%s-lb", spec.Name),
+			CloudProperties: cloudProperties,
+		}))
+	}
+
 	return ops, nil
 }
+
+// targetGroups returns the names of the target groups of an alb or nlb,
+// which the stack outputs as <lb>TargetGroup<instance port>.
+func targetGroups(outputs map[string]string, loadBalancerName string) []string {
+	keys := []string{}
+	for key := range outputs {
+		port := strings.TrimPrefix(key, loadBalancerName+"TargetGroup")
+		if port == key {
+			continue
+		}
+		if _, err := strconv.Atoi(port); err == nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	names := []string{}
+	for _, key := range keys {
+		names = append(names, outputs[key])
+	}
+	return names
+}
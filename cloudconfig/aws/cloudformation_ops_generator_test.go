@@ -13,16 +13,30 @@ import (
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 	"github.com/pivotal-cf-experimental/gomegamatchers"
 
+	yaml "gopkg.in/yaml.v2"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
+var availableInstanceTypes = []string{
+	"m3.medium", "t2.small", "m3.large", "m4.xlarge", "m4.2xlarge", "m4.4xlarge",
+	"m3.xlarge", "m3.2xlarge", "m4.large", "m4.10xlarge", "c3.large", "c3.xlarge",
+	"c3.2xlarge", "c3.4xlarge", "c3.8xlarge", "c4.large", "c4.xlarge", "c4.2xlarge",
+	"c4.4xlarge", "c4.8xlarge", "r3.large", "r3.xlarge", "r3.2xlarge", "r3.4xlarge",
+	"r3.8xlarge", "t2.nano", "t2.micro", "t2.medium", "t2.large", "t3.small",
+	"m5.large", "m5.xlarge", "m5.2xlarge", "m5.4xlarge", "c5.large", "c5.xlarge",
+	"c5.2xlarge", "c5.4xlarge", "r5.large", "r5.xlarge", "r5.2xlarge", "r5.4xlarge",
+}
+
 var _ = Describe("CloudFormationOpsGenerator", func() {
 	Describe("Generate", func() {
 		var (
 			availabilityZoneRetriever *fakes.AvailabilityZoneRetriever
 			infrastructureManager     *fakes.InfrastructureManager
+			instanceTypeRetriever     *fakes.InstanceTypeRetriever
+			logger                    *fakes.Logger
 			opsGenerator              aws.CloudFormationOpsGenerator
 
 			incomingState   storage.State
@@ -32,6 +46,8 @@ var _ = Describe("CloudFormationOpsGenerator", func() {
 		BeforeEach(func() {
 			availabilityZoneRetriever = &fakes.AvailabilityZoneRetriever{}
 			infrastructureManager = &fakes.InfrastructureManager{}
+			instanceTypeRetriever = &fakes.InstanceTypeRetriever{}
+			logger = &fakes.Logger{}
 
 			incomingState = storage.State{
 				IAAS: "aws",
@@ -44,6 +60,7 @@ var _ = Describe("CloudFormationOpsGenerator", func() {
 			}
 
 			availabilityZoneRetriever.RetrieveCall.Returns.AZs = []string{"us-east-1a", "us-east-1b", "us-east-1c"}
+			instanceTypeRetriever.RetrieveCall.Returns.InstanceTypes = availableInstanceTypes
 
 			infrastructureManager.DescribeCall.Returns.Stack = cloudformation.Stack{
 				Outputs: map[string]string{
@@ -61,7 +78,7 @@ var _ = Describe("CloudFormationOpsGenerator", func() {
 			expectedOpsFile, err = ioutil.ReadFile(filepath.Join("fixtures", "aws-ops.yml"))
 			Expect(err).NotTo(HaveOccurred())
 
-			opsGenerator = aws.NewCloudFormationOpsGenerator(availabilityZoneRetriever, infrastructureManager)
+			opsGenerator = aws.NewCloudFormationOpsGenerator(availabilityZoneRetriever, infrastructureManager, instanceTypeRetriever, logger)
 		})
 
 		It("returns an ops file to transform base cloud config into aws specific cloud config", func() {
@@ -70,6 +87,7 @@ var _ = Describe("CloudFormationOpsGenerator", func() {
 
 			Expect(availabilityZoneRetriever.RetrieveCall.Receives.Region).To(Equal("us-east-1"))
 			Expect(infrastructureManager.DescribeCall.Receives.StackName).To(Equal("some-stack"))
+			Expect(instanceTypeRetriever.RetrieveCall.Receives.Region).To(Equal("us-east-1"))
 
 			Expect(opsYAML).To(gomegamatchers.MatchYAML(expectedOpsFile))
 		})
@@ -102,6 +120,288 @@ var _ = Describe("CloudFormationOpsGenerator", func() {
 			}),
 		)
 
+		Context("when lb specs exist", func() {
+			It("adds a vm extension for every lb spec", func() {
+				incomingState.LBs = []storage.LBSpec{{Name: "credhub-uaa"}}
+				infrastructureManager.DescribeCall.Returns.Stack.Outputs["CredhubUaaLoadBalancer"] = "some-credhub-uaa-lb"
+				infrastructureManager.DescribeCall.Returns.Stack.Outputs["CredhubUaaInternalSecurityGroup"] = "some-credhub-uaa-internal-security-group"
+
+				opsYAML, err := opsGenerator.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(opsYAML).To(ContainSubstring(`- type: replace
+  path: /vm_extensions/-
+  value:
+    name: credhub-uaa-lb
+    cloud_properties:
+      elbs:
+      - some-credhub-uaa-lb
+      security_groups:
+      - some-credhub-uaa-internal-security-group
+      - some-internal-security-group
+`))
+			})
+
+			It("adds the vm extensions of the elbs of named cf lbs", func() {
+				incomingState.LBs = []storage.LBSpec{{Name: "foundation", Type: "cf"}}
+				infrastructureManager.DescribeCall.Returns.Stack.Outputs["FoundationRouterLoadBalancer"] = "some-foundation-router-lb"
+				infrastructureManager.DescribeCall.Returns.Stack.Outputs["FoundationRouterInternalSecurityGroup"] = "some-foundation-router-internal-security-group"
+				infrastructureManager.DescribeCall.Returns.Stack.Outputs["FoundationSshProxyLoadBalancer"] = "some-foundation-ssh-proxy-lb"
+				infrastructureManager.DescribeCall.Returns.Stack.Outputs["FoundationSshProxyInternalSecurityGroup"] = "some-foundation-ssh-proxy-internal-security-group"
+
+				opsYAML, err := opsGenerator.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(opsYAML).To(ContainSubstring(`- type: replace
+  path: /vm_extensions/-
+  value:
+    name: foundation-router-lb
+    cloud_properties:
+      elbs:
+      - some-foundation-router-lb
+      security_groups:
+      - some-foundation-router-internal-security-group
+      - some-internal-security-group
+- type: replace
+  path: /vm_extensions/-
+  value:
+    name: foundation-ssh-proxy-lb
+    cloud_properties:
+      elbs:
+      - some-foundation-ssh-proxy-lb
+      security_groups:
+      - some-foundation-ssh-proxy-internal-security-group
+      - some-internal-security-group
+`))
+			})
+			It("attaches the vms of albs and nlbs to their target groups", func() {
+				incomingState.LBs = []storage.LBSpec{{Name: "foundation", Type: "cf", Flavor: "alb"}}
+				infrastructureManager.DescribeCall.Returns.Stack.Outputs["FoundationRouterApplicationLoadBalancer"] = "some-foundation-router-alb"
+				infrastructureManager.DescribeCall.Returns.Stack.Outputs["FoundationRouterApplicationLoadBalancerTargetGroup80"] = "some-foundation-router-target-group"
+				infrastructureManager.DescribeCall.Returns.Stack.Outputs["FoundationRouterInternalSecurityGroup"] = "some-foundation-router-internal-security-group"
+				infrastructureManager.DescribeCall.Returns.Stack.Outputs["FoundationSshProxyNetworkLoadBalancer"] = "some-foundation-ssh-proxy-nlb"
+				infrastructureManager.DescribeCall.Returns.Stack.Outputs["FoundationSshProxyNetworkLoadBalancerTargetGroup2222"] = "some-foundation-ssh-proxy-target-group"
+				infrastructureManager.DescribeCall.Returns.Stack.Outputs["FoundationSshProxyInternalSecurityGroup"] = "some-foundation-ssh-proxy-internal-security-group"
+
+				opsYAML, err := opsGenerator.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(opsYAML).To(ContainSubstring(`- type: replace
+  path: /vm_extensions/-
+  value:
+    name: foundation-router-lb
+    cloud_properties:
+      lb_target_groups:
+      - some-foundation-router-target-group
+      security_groups:
+      - some-foundation-router-internal-security-group
+      - some-internal-security-group
+- type: replace
+  path: /vm_extensions/-
+  value:
+    name: foundation-ssh-proxy-lb
+    cloud_properties:
+      lb_target_groups:
+      - some-foundation-ssh-proxy-target-group
+      security_groups:
+      - some-foundation-ssh-proxy-internal-security-group
+      - some-internal-security-group
+`))
+			})
+
+			It("keeps the elb of an lb attached while it migrates to a target group flavor", func() {
+				incomingState.LBs = []storage.LBSpec{{Name: "credhub", Flavor: "nlb", PreviousFlavor: "elb"}}
+				infrastructureManager.DescribeCall.Returns.Stack.Outputs["CredhubLoadBalancer"] = "some-credhub-elb"
+				infrastructureManager.DescribeCall.Returns.Stack.Outputs["CredhubNetworkLoadBalancerTargetGroup8443"] = "some-credhub-8443-target-group"
+				infrastructureManager.DescribeCall.Returns.Stack.Outputs["CredhubNetworkLoadBalancerTargetGroup8844"] = "some-credhub-8844-target-group"
+				infrastructureManager.DescribeCall.Returns.Stack.Outputs["CredhubInternalSecurityGroup"] = "some-credhub-internal-security-group"
+
+				opsYAML, err := opsGenerator.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(opsYAML).To(ContainSubstring(`- type: replace
+  path: /vm_extensions/-
+  value:
+    name: credhub-lb
+    cloud_properties:
+      elbs:
+      - some-credhub-elb
+      lb_target_groups:
+      - some-credhub-8443-target-group
+      - some-credhub-8844-target-group
+      security_groups:
+      - some-credhub-internal-security-group
+      - some-internal-security-group
+`))
+			})
+		})
+
+		Context("sizing profiles", func() {
+			findOp := func(opsYAML, path string) map[interface{}]interface{} {
+				var ops []map[interface{}]interface{}
+				Expect(yaml.Unmarshal([]byte(opsYAML), &ops)).To(Succeed())
+
+				for _, op := range ops {
+					if op["path"] == path {
+						return op
+					}
+				}
+
+				return nil
+			}
+
+			It("sizes the vm_types with the named built-in profile", func() {
+				incomingState.CloudConfig.SizingProfile = "small"
+
+				opsYAML, err := opsGenerator.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(findOp(opsYAML, "/vm_types/name=default/cloud_properties?")["value"]).To(Equal(map[interface{}]interface{}{
+					"instance_type": "t2.medium",
+					"ephemeral_disk": map[interface{}]interface{}{
+						"size": 10240,
+						"type": "gp2",
+					},
+				}))
+				Expect(findOp(opsYAML, "/vm_types/-")["value"]).To(Equal(map[interface{}]interface{}{
+					"name": "compilation",
+					"cloud_properties": map[interface{}]interface{}{
+						"instance_type": "c4.large",
+						"ephemeral_disk": map[interface{}]interface{}{
+							"size": 10240,
+							"type": "gp2",
+						},
+					},
+				}))
+				Expect(findOp(opsYAML, "/compilation/vm_type")["value"]).To(Equal("compilation"))
+			})
+
+			It("sizes the vm_types with a custom profile", func() {
+				incomingState.CloudConfig.CustomSizingProfile = `
+vm_types:
+- {name: default, instance_type: m4.large}
+- {name: sharedcpu, instance_type: t2.small}
+- {name: small, instance_type: m4.large}
+- {name: medium, instance_type: m4.xlarge}
+- {name: large, instance_type: m4.2xlarge}
+- {name: extra-large, instance_type: m4.4xlarge, disk_type: standard, disk_size_gb: 50}
+- {name: spot-worker, instance_type: c4.xlarge, spot_bid_price: 0.05}
+`
+
+				opsYAML, err := opsGenerator.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(findOp(opsYAML, "/vm_types/name=extra-large/cloud_properties?")["value"]).To(Equal(map[interface{}]interface{}{
+					"instance_type": "m4.4xlarge",
+					"ephemeral_disk": map[interface{}]interface{}{
+						"size": 51200,
+						"type": "standard",
+					},
+				}))
+				Expect(findOp(opsYAML, "/vm_types/-")["value"]).To(Equal(map[interface{}]interface{}{
+					"name": "spot-worker",
+					"cloud_properties": map[interface{}]interface{}{
+						"instance_type":  "c4.xlarge",
+						"spot_bid_price": 0.05,
+						"ephemeral_disk": map[interface{}]interface{}{
+							"size": 10240,
+							"type": "gp2",
+						},
+					},
+				}))
+			})
+
+			It("returns an error when the named profile does not exist", func() {
+				incomingState.CloudConfig.SizingProfile = "enormous"
+
+				_, err := opsGenerator.Generate(incomingState)
+				Expect(err).To(MatchError(`unknown sizing profile "enormous"`))
+			})
+
+			It("sizes the vm_types with newer instance types with the current-generation profile", func() {
+				incomingState.CloudConfig.SizingProfile = "current-generation"
+
+				opsYAML, err := opsGenerator.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(findOp(opsYAML, "/vm_types/name=default/cloud_properties?")["value"]).To(Equal(map[interface{}]interface{}{
+					"instance_type": "m5.large",
+					"ephemeral_disk": map[interface{}]interface{}{
+						"size": 10240,
+						"type": "gp2",
+					},
+				}))
+			})
+
+			It("skips the vm_types of the built-in profile whose instance type is not available in the region", func() {
+				incomingState.CloudConfig.SizingProfile = "current-generation"
+				instanceTypeRetriever.RetrieveCall.Returns.InstanceTypes = []string{"m5.large", "t3.small", "m5.xlarge", "m5.2xlarge", "m5.4xlarge"}
+
+				opsYAML, err := opsGenerator.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(findOp(opsYAML, "/vm_types/name=default/cloud_properties?")).NotTo(BeNil())
+				Expect(findOp(opsYAML, "/compilation/vm_type")).To(BeNil())
+				Expect(strings.Count(opsYAML, "path: /vm_types/-")).To(Equal(4))
+				Expect(logger.PrintlnCall.Messages).To(ContainElement(`instance type "c5.large" for vm_type "compilation" is not available in region "us-east-1", skipping...`))
+				Expect(logger.PrintlnCall.Messages).To(ContainElement(`instance type "m3.medium" for vm_type "m3.medium" is not available in region "us-east-1", skipping...`))
+				Expect(logger.PrintlnCall.Messages).To(ContainElement(`instance type "r5.xlarge" for vm_type "small-highmem" is not available in region "us-east-1", skipping...`))
+			})
+
+			It("returns an error when the instance type of a base vm_type is not available in the region", func() {
+				incomingState.CloudConfig.SizingProfile = "current-generation"
+				instanceTypeRetriever.RetrieveCall.Returns.InstanceTypes = []string{"m5.large"}
+
+				_, err := opsGenerator.Generate(incomingState)
+				Expect(err).To(MatchError(`instance type "t3.small" for vm_type "sharedcpu" is not available in region "us-east-1"`))
+			})
+
+			It("suggests the current-generation profile when a base vm_type of the default profile is not available in the region", func() {
+				instanceTypeRetriever.RetrieveCall.Returns.InstanceTypes = []string{"m5.large", "t3.small"}
+
+				_, err := opsGenerator.Generate(incomingState)
+				Expect(err).To(MatchError(`instance type "m3.medium" for vm_type "default" is not available in region "us-east-1", try --sizing-profile current-generation`))
+			})
+
+			It("returns an error when an instance type of a custom profile is not available in the region", func() {
+				incomingState.CloudConfig.CustomSizingProfile = `
+vm_types:
+- {name: default, instance_type: m5.large}
+- {name: sharedcpu, instance_type: t3.small}
+- {name: small, instance_type: m5.large}
+- {name: medium, instance_type: m5.xlarge}
+- {name: large, instance_type: m5.2xlarge}
+- {name: extra-large, instance_type: m5.4xlarge}
+- {name: gpu-worker, instance_type: p3.2xlarge}
+`
+
+				_, err := opsGenerator.Generate(incomingState)
+				Expect(err).To(MatchError(`instance type "p3.2xlarge" for vm_type "gpu-worker" is not available in region "us-east-1"`))
+			})
+
+			It("returns an error when a vm_type in the profile has no instance type", func() {
+				incomingState.CloudConfig.CustomSizingProfile = `
+vm_types:
+- {name: default, machine_type: n1-standard-1}
+- {name: sharedcpu, instance_type: t2.small}
+- {name: small, instance_type: m4.large}
+- {name: medium, instance_type: m4.xlarge}
+- {name: large, instance_type: m4.2xlarge}
+- {name: extra-large, instance_type: m4.4xlarge}
+`
+
+				_, err := opsGenerator.Generate(incomingState)
+				Expect(err).To(MatchError(`vm_type "default" in sizing profile is missing an instance_type`))
+			})
+
+			It("returns an error when the instance types cannot be retrieved", func() {
+				instanceTypeRetriever.RetrieveCall.Returns.Error = errors.New("failed to retrieve instance types")
+
+				_, err := opsGenerator.Generate(incomingState)
+				Expect(err).To(MatchError("failed to retrieve instance types"))
+			})
+		})
+
 		Context("failure cases", func() {
 			It("returns an error when az retriever fails to retrieve", func() {
 				availabilityZoneRetriever.RetrieveCall.Returns.Error = errors.New("failed to retrieve")
@@ -0,0 +1,162 @@
+package aws
+
+const (
+	defaultDiskType   = "gp2"
+	defaultDiskSizeGB = 10
+)
+
+// sizingProfiles are the built-in sizing profiles. The default profile keeps
+// the instance types bbl has always used so that upgrading bbl does not
+// recreate vms, the current-generation profile opts into newer instance types.
+// The vm_types named after an instance type keep older generations for the
+// deployments that name them, they are left out of the cloud config in regions
+// without the instance type.
+var sizingProfiles = map[string]string{
+	"default": `
+vm_types:
+- name: default
+  instance_type: m3.medium
+- name: sharedcpu
+  instance_type: t2.small
+- name: small
+  instance_type: m3.large
+- name: medium
+  instance_type: m4.xlarge
+- name: large
+  instance_type: m4.2xlarge
+- name: extra-large
+  instance_type: m4.4xlarge
+- name: compilation
+  instance_type: c3.large
+` + namedVMTypes + `- name: small-highmem
+  instance_type: r3.xlarge
+- name: small-highcpu
+  instance_type: c3.large
+`,
+
+	"current-generation": `
+vm_types:
+- name: default
+  instance_type: m5.large
+- name: sharedcpu
+  instance_type: t3.small
+- name: small
+  instance_type: m5.large
+- name: medium
+  instance_type: m5.xlarge
+- name: large
+  instance_type: m5.2xlarge
+- name: extra-large
+  instance_type: m5.4xlarge
+- name: compilation
+  instance_type: c5.large
+` + namedVMTypes + `- name: small-highmem
+  instance_type: r5.xlarge
+- name: small-highcpu
+  instance_type: c5.large
+`,
+
+	"small": `
+vm_types:
+- name: default
+  instance_type: t2.medium
+- name: sharedcpu
+  instance_type: t2.small
+- name: small
+  instance_type: t2.medium
+- name: medium
+  instance_type: t2.large
+- name: large
+  instance_type: m4.xlarge
+- name: extra-large
+  instance_type: m4.2xlarge
+- name: compilation
+  instance_type: c4.large
+`,
+}
+
+// namedVMTypes are the vm_types named after an instance type that the default
+// and current-generation profiles share.
+const namedVMTypes = `- name: m3.medium
+  instance_type: m3.medium
+- name: m3.large
+  instance_type: m3.large
+- name: m3.xlarge
+  instance_type: m3.xlarge
+- name: m3.2xlarge
+  instance_type: m3.2xlarge
+- name: m4.large
+  instance_type: m4.large
+- name: m4.xlarge
+  instance_type: m4.xlarge
+- name: m4.2xlarge
+  instance_type: m4.2xlarge
+- name: m4.4xlarge
+  instance_type: m4.4xlarge
+- name: m4.10xlarge
+  instance_type: m4.10xlarge
+- name: m5.large
+  instance_type: m5.large
+- name: m5.xlarge
+  instance_type: m5.xlarge
+- name: m5.2xlarge
+  instance_type: m5.2xlarge
+- name: m5.4xlarge
+  instance_type: m5.4xlarge
+- name: c3.large
+  instance_type: c3.large
+- name: c3.xlarge
+  instance_type: c3.xlarge
+- name: c3.2xlarge
+  instance_type: c3.2xlarge
+- name: c3.4xlarge
+  instance_type: c3.4xlarge
+- name: c3.8xlarge
+  instance_type: c3.8xlarge
+- name: c4.large
+  instance_type: c4.large
+- name: c4.xlarge
+  instance_type: c4.xlarge
+- name: c4.2xlarge
+  instance_type: c4.2xlarge
+- name: c4.4xlarge
+  instance_type: c4.4xlarge
+- name: c4.8xlarge
+  instance_type: c4.8xlarge
+- name: c5.large
+  instance_type: c5.large
+- name: c5.xlarge
+  instance_type: c5.xlarge
+- name: c5.2xlarge
+  instance_type: c5.2xlarge
+- name: c5.4xlarge
+  instance_type: c5.4xlarge
+- name: r3.large
+  instance_type: r3.large
+- name: r3.xlarge
+  instance_type: r3.xlarge
+- name: r3.2xlarge
+  instance_type: r3.2xlarge
+- name: r3.4xlarge
+  instance_type: r3.4xlarge
+- name: r3.8xlarge
+  instance_type: r3.8xlarge
+- name: r5.large
+  instance_type: r5.large
+- name: r5.xlarge
+  instance_type: r5.xlarge
+- name: r5.2xlarge
+  instance_type: r5.2xlarge
+- name: r5.4xlarge
+  instance_type: r5.4xlarge
+- name: t2.nano
+  instance_type: t2.nano
+- name: t2.micro
+  instance_type: t2.micro
+- name: t2.small
+  instance_type: t2.small
+- name: t2.medium
+  instance_type: t2.medium
+- name: t2.large
+  instance_type: t2.large
+`
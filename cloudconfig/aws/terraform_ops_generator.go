@@ -7,13 +7,15 @@ import (
 
 	yaml "gopkg.in/yaml.v2"
 
-	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/cloudconfig"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
 type TerraformOpsGenerator struct {
 	availabilityZoneRetriever availabilityZoneRetriever
 	terraformManager          terraformManager
+	instanceTypeRetriever     instanceTypeRetriever
+	logger                    logger
 }
 
 type availabilityZoneRetriever interface {
@@ -65,16 +67,19 @@ type lb struct {
 }
 
 type lbCloudProperties struct {
-	ELBs           []string
+	ELBs           []string `yaml:"elbs,omitempty"`
+	LBTargetGroups []string `yaml:"lb_target_groups,omitempty"`
 	SecurityGroups []string `yaml:"security_groups"`
 }
 
 var marshal func(interface{}) ([]byte, error) = yaml.Marshal
 
-func NewTerraformOpsGenerator(availabilityZoneRetriever availabilityZoneRetriever, terraformManager terraformManager) TerraformOpsGenerator {
+func NewTerraformOpsGenerator(availabilityZoneRetriever availabilityZoneRetriever, terraformManager terraformManager, instanceTypeRetriever instanceTypeRetriever, logger logger) TerraformOpsGenerator {
 	return TerraformOpsGenerator{
 		availabilityZoneRetriever: availabilityZoneRetriever,
 		terraformManager:          terraformManager,
+		instanceTypeRetriever:     instanceTypeRetriever,
+		logger:                    logger,
 	}
 }
 
@@ -89,9 +94,21 @@ func (a TerraformOpsGenerator) Generate(state storage.State) (string, error) {
 		return "", err
 	}
 
+	vmTypeOps, err := generateVMTypeOps(state, a.instanceTypeRetriever, a.logger)
+	if err != nil {
+		return "", err
+	}
+
+	vmTypeOpsYAML, err := marshal(vmTypeOps)
+	if err != nil {
+		return "", err
+	}
+
 	return strings.Join(
 		[]string{
 			BaseOps,
+			string(vmTypeOpsYAML),
+			BaseVMExtensionOps,
 			string(cloudConfigOpsYAML),
 		},
 		"\n",
@@ -143,60 +160,69 @@ func (a TerraformOpsGenerator) generateTerraformAWSOps(state storage.State) ([]o
 		return []op{}, errors.New("missing internal security group terraform output")
 	}
 
-	subnets := []networkSubnet{}
-	for i := range azs {
-		subnet, err := generateNetworkSubnet(
-			fmt.Sprintf("z%d", i+1),
-			subnetCIDRs[i].(string),
-			subnetNames[i].(string),
-			internalSecurityGroup,
-		)
+	networkOps, err := generateNetworkOps(state, len(azs), subnetCIDRs, subnetNames, internalSecurityGroup, "")
+	if err != nil {
+		return []op{}, err
+	}
+	ops = append(ops, networkOps...)
+
+	// dual stack environments get an ipv6 twin of every network on the same
+	// subnets, deployments attach to both to get an address of each family
+	if subnetIPv6CIDRs, ok := terraformOutputs["internal_subnet_ipv6_cidrs"].([]interface{}); ok {
+		networkOps, err := generateNetworkOps(state, len(azs), subnetIPv6CIDRs, subnetNames, internalSecurityGroup, "-ipv6")
 		if err != nil {
 			return []op{}, err
 		}
-
-		subnets = append(subnets, subnet)
+		ops = append(ops, networkOps...)
 	}
 
-	ops = append(ops, createOp("replace", "/networks/-", network{
-		Name:    "private",
-		Subnets: subnets,
-		Type:    "manual",
-	}))
+	return ops, nil
+}
 
-	ops = append(ops, createOp("replace", "/networks/-", network{
-		Name:    "default",
-		Subnets: subnets,
-		Type:    "manual",
-	}))
+func generateNetworkOps(state storage.State, azCount int, subnetCIDRs, subnetNames []interface{}, securityGroup, nameSuffix string) ([]op, error) {
+	ops := []op{}
+	for _, networkName := range cloudconfig.NetworkNames {
+		reservedCount, staticCount := cloudconfig.NetworkIPCounts(state.CloudConfig, networkName)
+
+		subnets := []networkSubnet{}
+		for i := 0; i < azCount; i++ {
+			subnet, err := generateNetworkSubnet(
+				fmt.Sprintf("z%d", i+1),
+				subnetCIDRs[i].(string),
+				subnetNames[i].(string),
+				securityGroup,
+				reservedCount,
+				staticCount,
+			)
+			if err != nil {
+				return []op{}, err
+			}
+
+			subnets = append(subnets, subnet)
+		}
+
+		ops = append(ops, createOp("replace", "/networks/-", network{
+			Name:    networkName + nameSuffix,
+			Subnets: subnets,
+			Type:    "manual",
+		}))
+	}
 
 	return ops, nil
 }
 
-func generateNetworkSubnet(az, cidr, subnet, securityGroup string) (networkSubnet, error) {
-	parsedCidr, err := bosh.ParseCIDRBlock(cidr)
+func generateNetworkSubnet(az, cidr, subnet, securityGroup string, reservedCount, staticCount int) (networkSubnet, error) {
+	ranges, err := cloudconfig.GenerateSubnetRanges(cidr, reservedCount, staticCount)
 	if err != nil {
 		return networkSubnet{}, err
 	}
 
-	gateway := parsedCidr.GetFirstIP().Add(1).String()
-	firstReserved := parsedCidr.GetFirstIP().Add(2).String()
-	secondReserved := parsedCidr.GetFirstIP().Add(3).String()
-	lastReserved := parsedCidr.GetLastIP().String()
-	lastStatic := parsedCidr.GetLastIP().Subtract(1).String()
-	firstStatic := parsedCidr.GetLastIP().Subtract(65).String()
-
 	return networkSubnet{
-		AZ:      az,
-		Gateway: gateway,
-		Range:   cidr,
-		Reserved: []string{
-			fmt.Sprintf("%s-%s", firstReserved, secondReserved),
-			fmt.Sprintf("%s", lastReserved),
-		},
-		Static: []string{
-			fmt.Sprintf("%s-%s", firstStatic, lastStatic),
-		},
+		AZ:       az,
+		Gateway:  ranges.Gateway,
+		Range:    cidr,
+		Reserved: ranges.Reserved,
+		Static:   ranges.Static,
 		CloudProperties: networkSubnetCloudProperties{
 			Subnet:         subnet,
 			SecurityGroups: []string{securityGroup},
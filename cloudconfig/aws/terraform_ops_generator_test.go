@@ -10,6 +10,8 @@ import (
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 	"github.com/pivotal-cf-experimental/gomegamatchers"
 
+	yaml "gopkg.in/yaml.v2"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -19,6 +21,8 @@ var _ = Describe("TerraformOpsGenerator", func() {
 		var (
 			availabilityZoneRetriever *fakes.AvailabilityZoneRetriever
 			terraformManager          *fakes.TerraformManager
+			instanceTypeRetriever     *fakes.InstanceTypeRetriever
+			logger                    *fakes.Logger
 			opsGenerator              aws.TerraformOpsGenerator
 
 			incomingState   storage.State
@@ -28,6 +32,8 @@ var _ = Describe("TerraformOpsGenerator", func() {
 		BeforeEach(func() {
 			availabilityZoneRetriever = &fakes.AvailabilityZoneRetriever{}
 			terraformManager = &fakes.TerraformManager{}
+			instanceTypeRetriever = &fakes.InstanceTypeRetriever{}
+			logger = &fakes.Logger{}
 
 			incomingState = storage.State{
 				IAAS: "aws",
@@ -42,6 +48,7 @@ var _ = Describe("TerraformOpsGenerator", func() {
 				"us-east-1b",
 				"us-east-1c",
 			}
+			instanceTypeRetriever.RetrieveCall.Returns.InstanceTypes = availableInstanceTypes
 
 			terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
 				"internal_subnet_cidrs": []interface{}{
@@ -61,7 +68,7 @@ var _ = Describe("TerraformOpsGenerator", func() {
 			expectedOpsFile, err = ioutil.ReadFile(filepath.Join("fixtures", "aws-ops.yml"))
 			Expect(err).NotTo(HaveOccurred())
 
-			opsGenerator = aws.NewTerraformOpsGenerator(availabilityZoneRetriever, terraformManager)
+			opsGenerator = aws.NewTerraformOpsGenerator(availabilityZoneRetriever, terraformManager, instanceTypeRetriever, logger)
 		})
 
 		It("returns an ops file to transform base cloud config into aws specific cloud config", func() {
@@ -70,10 +77,104 @@ var _ = Describe("TerraformOpsGenerator", func() {
 
 			Expect(availabilityZoneRetriever.RetrieveCall.Receives.Region).To(Equal("us-east-1"))
 			Expect(terraformManager.GetOutputsCall.Receives.BBLState).To(Equal(incomingState))
+			Expect(instanceTypeRetriever.RetrieveCall.Receives.Region).To(Equal("us-east-1"))
 
 			Expect(opsYAML).To(gomegamatchers.MatchYAML(expectedOpsFile))
 		})
 
+		Context("when reserved and static ip counts are configured", func() {
+			findNetworkSubnet := func(opsYAML, name string) map[interface{}]interface{} {
+				var ops []struct {
+					Path  string
+					Value interface{}
+				}
+				Expect(yaml.Unmarshal([]byte(opsYAML), &ops)).To(Succeed())
+
+				for _, op := range ops {
+					if value, ok := op.Value.(map[interface{}]interface{}); ok && op.Path == "/networks/-" && value["name"] == name {
+						return value["subnets"].([]interface{})[0].(map[interface{}]interface{})
+					}
+				}
+
+				return nil
+			}
+
+			It("sizes the ranges of that network only", func() {
+				incomingState.CloudConfig.Networks = map[string]storage.CloudConfigNetwork{
+					"private": {
+						ReservedIPCount: 8,
+						StaticIPCount:   128,
+					},
+				}
+
+				opsYAML, err := opsGenerator.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				privateSubnet := findNetworkSubnet(opsYAML, "private")
+				Expect(privateSubnet["reserved"]).To(Equal([]interface{}{"10.0.16.2-10.0.16.9", "10.0.31.255"}))
+				Expect(privateSubnet["static"]).To(Equal([]interface{}{"10.0.31.127-10.0.31.254"}))
+
+				defaultSubnet := findNetworkSubnet(opsYAML, "default")
+				Expect(defaultSubnet["reserved"]).To(Equal([]interface{}{"10.0.16.2-10.0.16.3", "10.0.31.255"}))
+				Expect(defaultSubnet["static"]).To(Equal([]interface{}{"10.0.31.190-10.0.31.254"}))
+			})
+
+			It("returns an error when the ranges do not fit in the subnet", func() {
+				incomingState.CloudConfig.Networks = map[string]storage.CloudConfigNetwork{
+					"default": {StaticIPCount: 4096},
+				}
+
+				_, err := opsGenerator.Generate(incomingState)
+				Expect(err).To(MatchError("2 reserved and 4096 static ips do not fit in subnet 10.0.16.0/20"))
+			})
+		})
+
+		Context("when the environment is dual stack", func() {
+			BeforeEach(func() {
+				terraformManager.GetOutputsCall.Returns.Outputs["internal_subnet_ipv6_cidrs"] = []interface{}{
+					"2600:1f18:1:2201::/64",
+					"2600:1f18:1:2202::/64",
+					"2600:1f18:1:2203::/64",
+				}
+			})
+
+			It("adds an ipv6 network next to every generated network", func() {
+				opsYAML, err := opsGenerator.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				var ops []struct {
+					Path  string
+					Value interface{}
+				}
+				Expect(yaml.Unmarshal([]byte(opsYAML), &ops)).To(Succeed())
+
+				var networkNames []interface{}
+				var privateIPv6Subnets []interface{}
+				for _, op := range ops {
+					if value, ok := op.Value.(map[interface{}]interface{}); ok && op.Path == "/networks/-" {
+						networkNames = append(networkNames, value["name"])
+						if value["name"] == "private-ipv6" {
+							privateIPv6Subnets = value["subnets"].([]interface{})
+						}
+					}
+				}
+				Expect(networkNames).To(Equal([]interface{}{"private", "default", "private-ipv6", "default-ipv6"}))
+
+				Expect(privateIPv6Subnets).To(HaveLen(3))
+				Expect(privateIPv6Subnets[1]).To(Equal(map[interface{}]interface{}{
+					"az":       "z2",
+					"gateway":  "2600:1f18:1:2202::1",
+					"range":    "2600:1f18:1:2202::/64",
+					"reserved": []interface{}{"2600:1f18:1:2202::2-2600:1f18:1:2202::3", "2600:1f18:1:2202:ffff:ffff:ffff:ffff"},
+					"static":   []interface{}{"2600:1f18:1:2202:ffff:ffff:ffff:ffbe-2600:1f18:1:2202:ffff:ffff:ffff:fffe"},
+					"cloud_properties": map[interface{}]interface{}{
+						"subnet":          "some-subnet-2",
+						"security_groups": []interface{}{"some-internal-security-group"},
+					},
+				}))
+			})
+		})
+
 		Context("failure cases", func() {
 			It("returns an error when az retriever fails to retrieve", func() {
 				availabilityZoneRetriever.RetrieveCall.Returns.Error = errors.New("failed to retrieve")
@@ -0,0 +1,131 @@
+package aws
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry/bosh-bootloader/cloudconfig"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+type instanceTypeRetriever interface {
+	Retrieve(region string) ([]string, error)
+}
+
+type vmType struct {
+	Name            string                `yaml:"name"`
+	CloudProperties vmTypeCloudProperties `yaml:"cloud_properties"`
+}
+
+type vmTypeCloudProperties struct {
+	InstanceType  string        `yaml:"instance_type"`
+	EphemeralDisk ephemeralDisk `yaml:"ephemeral_disk"`
+	SpotBidPrice  float64       `yaml:"spot_bid_price,omitempty"`
+}
+
+type ephemeralDisk struct {
+	Size int    `yaml:"size"`
+	Type string `yaml:"type"`
+}
+
+func sizingProfile(cloudConfig storage.CloudConfig) (cloudconfig.SizingProfile, error) {
+	if cloudConfig.CustomSizingProfile != "" {
+		return cloudconfig.ParseSizingProfile(cloudConfig.CustomSizingProfile)
+	}
+
+	name := cloudConfig.SizingProfile
+	if name == "" {
+		name = cloudconfig.DefaultSizingProfile
+	}
+
+	profile, ok := sizingProfiles[name]
+	if !ok {
+		return cloudconfig.SizingProfile{}, fmt.Errorf("unknown sizing profile %q", name)
+	}
+
+	return cloudconfig.ParseSizingProfile(profile)
+}
+
+func isDefaultSizingProfile(cloudConfig storage.CloudConfig) bool {
+	return cloudConfig.SizingProfile == "" || cloudConfig.SizingProfile == cloudconfig.DefaultSizingProfile
+}
+
+type logger interface {
+	Println(string)
+}
+
+// generateVMTypeOps sizes the vm_types of the cloud config with the sizing
+// profile of the state. Every instance type of a custom profile and of the
+// base vm_types must be available in the region, the other vm_types of the
+// built-in profiles are skipped with a warning when they are not. The
+// compilation vms use the compilation vm_type when it is generated and the
+// default vm_type otherwise.
+func generateVMTypeOps(state storage.State, instanceTypeRetriever instanceTypeRetriever, logger logger) ([]op, error) {
+	profile, err := sizingProfile(state.CloudConfig)
+	if err != nil {
+		return []op{}, err
+	}
+
+	instanceTypes, err := instanceTypeRetriever.Retrieve(state.AWS.Region)
+	if err != nil {
+		return []op{}, err
+	}
+
+	available := map[string]bool{}
+	for _, instanceType := range instanceTypes {
+		available[instanceType] = true
+	}
+
+	builtIn := state.CloudConfig.CustomSizingProfile == ""
+
+	ops := []op{}
+	for _, size := range profile.VMTypes {
+		if size.InstanceType == "" {
+			return []op{}, fmt.Errorf("vm_type %q in sizing profile is missing an instance_type", size.Name)
+		}
+
+		if !available[size.InstanceType] {
+			if builtIn && !cloudconfig.IsBaseVMType(size.Name) {
+				logger.Println(fmt.Sprintf("instance type %q for vm_type %q is not available in region %q, skipping...", size.InstanceType, size.Name, state.AWS.Region))
+				continue
+			}
+
+			if builtIn && isDefaultSizingProfile(state.CloudConfig) {
+				return []op{}, fmt.Errorf("instance type %q for vm_type %q is not available in region %q, try --sizing-profile current-generation", size.InstanceType, size.Name, state.AWS.Region)
+			}
+
+			return []op{}, fmt.Errorf("instance type %q for vm_type %q is not available in region %q", size.InstanceType, size.Name, state.AWS.Region)
+		}
+
+		cloudProperties := vmTypeCloudProperties{
+			InstanceType: size.InstanceType,
+			EphemeralDisk: ephemeralDisk{
+				Size: defaultDiskSizeGB * 1024,
+				Type: defaultDiskType,
+			},
+			SpotBidPrice: size.SpotBidPrice,
+		}
+
+		if size.DiskSizeGB != 0 {
+			cloudProperties.EphemeralDisk.Size = size.DiskSizeGB * 1024
+		}
+
+		if size.DiskType != "" {
+			cloudProperties.EphemeralDisk.Type = size.DiskType
+		}
+
+		if cloudconfig.IsBaseVMType(size.Name) {
+			ops = append(ops, createOp("replace", fmt.Sprintf("/vm_types/name=%s/cloud_properties?", size.Name), cloudProperties))
+		} else {
+			ops = append(ops, createOp("replace", "/vm_types/-", vmType{
+				Name:            size.Name,
+				CloudProperties: cloudProperties,
+			}))
+		}
+
+		if size.Name == cloudconfig.CompilationVMType {
+			ops = append(ops, createOp("replace", "/compilation/vm_type", cloudconfig.CompilationVMType))
+		}
+	}
+
+	return ops, nil
+}
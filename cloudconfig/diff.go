@@ -0,0 +1,168 @@
+package cloudconfig
+
+import (
+	"fmt"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// diffSections are the parts of a cloud-config that are compared entry by
+// entry, keyed on each entry's name.
+var diffSections = []string{"azs", "networks", "vm_types", "vm_extensions"}
+
+type namedEntry struct {
+	name  string
+	lines []string
+}
+
+// Diff compares two cloud-configs and returns a readable summary of the
+// azs, networks, vm_types and vm_extensions that would be added (+),
+// removed (-) or changed (~). It returns an empty string when those
+// sections are identical.
+func Diff(current, desired string) (string, error) {
+	currentSections, err := parseDiffSections(current)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse current cloud config: %s", err)
+	}
+
+	desiredSections, err := parseDiffSections(desired)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse desired cloud config: %s", err)
+	}
+
+	var lines []string
+	for _, section := range diffSections {
+		sectionLines := diffEntries(currentSections[section], desiredSections[section])
+		if len(sectionLines) == 0 {
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("%s:", section))
+		lines = append(lines, sectionLines...)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func parseDiffSections(cloudConfig string) (map[string][]namedEntry, error) {
+	var contents struct {
+		AZs          []map[interface{}]interface{} `yaml:"azs"`
+		Networks     []map[interface{}]interface{} `yaml:"networks"`
+		VMTypes      []map[interface{}]interface{} `yaml:"vm_types"`
+		VMExtensions []map[interface{}]interface{} `yaml:"vm_extensions"`
+	}
+	err := yaml.Unmarshal([]byte(cloudConfig), &contents)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := map[string][]map[interface{}]interface{}{
+		"azs":           contents.AZs,
+		"networks":      contents.Networks,
+		"vm_types":      contents.VMTypes,
+		"vm_extensions": contents.VMExtensions,
+	}
+
+	sections := map[string][]namedEntry{}
+	for _, section := range diffSections {
+		for i, entry := range entries[section] {
+			name, ok := entry["name"].(string)
+			if !ok {
+				name = fmt.Sprintf("#%d", i)
+			}
+
+			entryYAML, err := yaml.Marshal(entry)
+			if err != nil {
+				// not tested
+				return nil, err
+			}
+
+			sections[section] = append(sections[section], namedEntry{
+				name:  name,
+				lines: strings.Split(strings.TrimSuffix(string(entryYAML), "\n"), "\n"),
+			})
+		}
+	}
+
+	return sections, nil
+}
+
+func diffEntries(current, desired []namedEntry) []string {
+	currentByName := map[string]namedEntry{}
+	for _, entry := range current {
+		currentByName[entry.name] = entry
+	}
+
+	desiredByName := map[string]bool{}
+
+	var lines []string
+	for _, entry := range desired {
+		desiredByName[entry.name] = true
+
+		currentEntry, ok := currentByName[entry.name]
+		switch {
+		case !ok:
+			lines = append(lines, fmt.Sprintf("  + %s", entry.name))
+			for _, line := range entry.lines {
+				lines = append(lines, fmt.Sprintf("  +   %s", line))
+			}
+		case strings.Join(currentEntry.lines, "\n") != strings.Join(entry.lines, "\n"):
+			lines = append(lines, fmt.Sprintf("  ~ %s", entry.name))
+			lines = append(lines, diffLines(currentEntry.lines, entry.lines)...)
+		}
+	}
+
+	for _, entry := range current {
+		if desiredByName[entry.name] {
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("  - %s", entry.name))
+		for _, line := range entry.lines {
+			lines = append(lines, fmt.Sprintf("  -   %s", line))
+		}
+	}
+
+	return lines
+}
+
+// diffLines produces a line based diff of two entries using their longest
+// common subsequence.
+func diffLines(current, desired []string) []string {
+	lengths := make([][]int, len(current)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(desired)+1)
+	}
+
+	for i := len(current) - 1; i >= 0; i-- {
+		for j := len(desired) - 1; j >= 0; j-- {
+			if current[i] == desired[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(current) || j < len(desired) {
+		switch {
+		case i < len(current) && j < len(desired) && current[i] == desired[j]:
+			lines = append(lines, fmt.Sprintf("      %s", current[i]))
+			i++
+			j++
+		case i < len(current) && (j == len(desired) || lengths[i+1][j] >= lengths[i][j+1]):
+			lines = append(lines, fmt.Sprintf("  -   %s", current[i]))
+			i++
+		default:
+			lines = append(lines, fmt.Sprintf("  +   %s", desired[j]))
+			j++
+		}
+	}
+
+	return lines
+}
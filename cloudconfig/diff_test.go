@@ -0,0 +1,90 @@
+package cloudconfig_test
+
+import (
+	"strings"
+
+	"github.com/cloudfoundry/bosh-bootloader/cloudconfig"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff", func() {
+	It("describes the added, removed and changed entries of each section", func() {
+		current := `
+azs:
+- name: z1
+  cloud_properties: {zone: us-east1-b}
+- name: z2
+  cloud_properties: {zone: us-east1-c}
+networks:
+- name: private
+  type: manual
+vm_types:
+- name: default
+  cloud_properties: {machine_type: n1-standard-1}
+- name: manual-type
+  cloud_properties: {machine_type: n1-highmem-8}
+compilation:
+  workers: 5
+`
+		desired := `
+azs:
+- name: z1
+  cloud_properties: {zone: us-east1-b}
+- name: z2
+  cloud_properties: {zone: us-east1-d}
+networks:
+- name: private
+  type: manual
+vm_types:
+- name: default
+  cloud_properties: {machine_type: n1-standard-1}
+vm_extensions:
+- name: lb
+  cloud_properties: {target_pool: some-pool}
+compilation:
+  workers: 6
+`
+
+		diff, err := cloudconfig.Diff(current, desired)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(diff).To(Equal(strings.Join([]string{
+			"azs:",
+			"  ~ z2",
+			"      cloud_properties:",
+			"  -     zone: us-east1-c",
+			"  +     zone: us-east1-d",
+			"      name: z2",
+			"vm_types:",
+			"  - manual-type",
+			"  -   cloud_properties:",
+			"  -     machine_type: n1-highmem-8",
+			"  -   name: manual-type",
+			"vm_extensions:",
+			"  + lb",
+			"  +   cloud_properties:",
+			"  +     target_pool: some-pool",
+			"  +   name: lb",
+		}, "\n")))
+	})
+
+	It("returns an empty diff when the sections are identical", func() {
+		diff, err := cloudconfig.Diff("vm_types: [{name: default}]", "vm_types: [{name: default}]")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(diff).To(BeEmpty())
+	})
+
+	Context("failure cases", func() {
+		It("returns an error when the current cloud config cannot be parsed", func() {
+			_, err := cloudconfig.Diff("%%%", "")
+			Expect(err).To(MatchError(ContainSubstring("failed to parse current cloud config")))
+		})
+
+		It("returns an error when the desired cloud config cannot be parsed", func() {
+			_, err := cloudconfig.Diff("", "%%%")
+			Expect(err).To(MatchError(ContainSubstring("failed to parse desired cloud config")))
+		})
+	})
+})
@@ -6,13 +6,15 @@ import (
 
 	yaml "gopkg.in/yaml.v2"
 
-	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/cloudconfig"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
+	gcpterraform "github.com/cloudfoundry/bosh-bootloader/terraform/gcp"
 )
 
 type OpsGenerator struct {
-	terraformManager terraformManager
-	zones            zones
+	terraformManager     terraformManager
+	zones                zones
+	machineTypeRetriever machineTypeRetriever
 }
 
 type terraformManager interface {
@@ -73,10 +75,11 @@ type lbCloudProperties struct {
 
 var marshal func(interface{}) ([]byte, error) = yaml.Marshal
 
-func NewOpsGenerator(terraformManager terraformManager, zones zones) OpsGenerator {
+func NewOpsGenerator(terraformManager terraformManager, zones zones, machineTypeRetriever machineTypeRetriever) OpsGenerator {
 	return OpsGenerator{
-		terraformManager: terraformManager,
-		zones:            zones,
+		terraformManager:     terraformManager,
+		zones:                zones,
+		machineTypeRetriever: machineTypeRetriever,
 	}
 }
 
@@ -91,9 +94,21 @@ func (o OpsGenerator) Generate(state storage.State) (string, error) {
 		return "", err
 	}
 
+	vmTypeOps, err := generateVMTypeOps(state, o.zones.Get(state.GCP.Region), o.machineTypeRetriever)
+	if err != nil {
+		return "", err
+	}
+
+	vmTypeOpsYAML, err := marshal(vmTypeOps)
+	if err != nil {
+		return "", err
+	}
+
 	return strings.Join(
 		[]string{
 			BaseOps,
+			string(vmTypeOpsYAML),
+			BaseVMExtensionOps,
 			string(cloudConfigOpsYAML),
 		},
 		"\n",
@@ -126,36 +141,36 @@ func (o *OpsGenerator) generateGCPOps(state storage.State) ([]op, error) {
 		return []op{}, err
 	}
 
-	var subnets []networkSubnet
-	for i, _ := range zones {
-		cidr := fmt.Sprintf("10.0.%d.0/20", 16*(i+1))
-		subnet, err := generateNetworkSubnet(
-			fmt.Sprintf("z%d", i+1),
-			cidr,
-			outputs["network_name"].(string),
-			outputs["subnetwork_name"].(string),
-			outputs["bosh_open_tag_name"].(string),
-			outputs["internal_tag_name"].(string),
-		)
-		if err != nil {
-			return []op{}, err
+	for _, networkName := range cloudconfig.NetworkNames {
+		reservedCount, staticCount := cloudconfig.NetworkIPCounts(state.CloudConfig, networkName)
+
+		var subnets []networkSubnet
+		for i, _ := range zones {
+			cidr := fmt.Sprintf("10.0.%d.0/20", 16*(i+1))
+			subnet, err := generateNetworkSubnet(
+				fmt.Sprintf("z%d", i+1),
+				cidr,
+				outputs["network_name"].(string),
+				outputs["subnetwork_name"].(string),
+				outputs["bosh_open_tag_name"].(string),
+				outputs["internal_tag_name"].(string),
+				reservedCount,
+				staticCount,
+			)
+			if err != nil {
+				return []op{}, err
+			}
+
+			subnets = append(subnets, subnet)
 		}
 
-		subnets = append(subnets, subnet)
+		ops = append(ops, createOp("replace", "/networks/-", network{
+			Name:    networkName,
+			Subnets: subnets,
+			Type:    "manual",
+		}))
 	}
 
-	ops = append(ops, createOp("replace", "/networks/-", network{
-		Name:    "private",
-		Subnets: subnets,
-		Type:    "manual",
-	}))
-
-	ops = append(ops, createOp("replace", "/networks/-", network{
-		Name:    "default",
-		Subnets: subnets,
-		Type:    "manual",
-	}))
-
 	if state.LB.Type == "concourse" {
 		ops = append(ops, createOp("replace", "/vm_extensions/-", lb{
 			Name: "lb",
@@ -199,33 +214,78 @@ func (o *OpsGenerator) generateGCPOps(state storage.State) ([]op, error) {
 		}))
 	}
 
+	for _, spec := range state.LBs {
+		if spec.Type == "cf" {
+			ops = append(ops, namedCFLBOps(spec.Name, outputs)...)
+			continue
+		}
+
+		targetPool := outputs[gcpterraform.LBSpecOutputName(spec.Name, "target_pool")].(string)
+
+		ops = append(ops, createOp("replace", "/vm_extensions/-", lb{
+			Name: fmt.Sprintf("%s-lb", spec.Name),
+			CloudProperties: lbCloudProperties{
+				TargetPool: targetPool,
+				Tags:       []string{targetPool},
+			},
+		}))
+	}
+
 	return ops, nil
 }
 
-func generateNetworkSubnet(az, cidr, networkName, subnetworkName, boshTag, internalTag string) (networkSubnet, error) {
-	parsedCidr, err := bosh.ParseCIDRBlock(cidr)
+// namedCFLBOps returns the vm extensions of a named cf lb, which are named
+// like the ones of the unnamed cf lb with the name of the lb in front.
+func namedCFLBOps(name string, outputs map[string]interface{}) []op {
+	output := func(output string) string {
+		return outputs[gcpterraform.LBSpecOutputName(name, output)].(string)
+	}
+
+	return []op{
+		createOp("replace", "/vm_extensions/-", lb{
+			Name: fmt.Sprintf("%s-cf-router-network-properties", name),
+			CloudProperties: lbCloudProperties{
+				BackendService: output("router_backend_service"),
+				TargetPool:     output("ws_target_pool"),
+				Tags: []string{
+					output("router_backend_service"),
+					output("ws_target_pool"),
+				},
+			},
+		}),
+		createOp("replace", "/vm_extensions/-", lb{
+			Name: fmt.Sprintf("%s-diego-ssh-proxy-network-properties", name),
+			CloudProperties: lbCloudProperties{
+				TargetPool: output("ssh_proxy_target_pool"),
+				Tags: []string{
+					output("ssh_proxy_target_pool"),
+				},
+			},
+		}),
+		createOp("replace", "/vm_extensions/-", lb{
+			Name: fmt.Sprintf("%s-cf-tcp-router-network-properties", name),
+			CloudProperties: lbCloudProperties{
+				TargetPool: output("tcp_router_target_pool"),
+				Tags: []string{
+					output("tcp_router_target_pool"),
+				},
+			},
+		}),
+	}
+}
+
+func generateNetworkSubnet(az, cidr, networkName, subnetworkName, boshTag, internalTag string, reservedCount, staticCount int) (networkSubnet, error) {
+	ranges, err := cloudconfig.GenerateSubnetRanges(cidr, reservedCount, staticCount)
 	if err != nil {
 		return networkSubnet{}, err
 	}
 
-	gateway := parsedCidr.GetFirstIP().Add(1).String()
-	firstReserved := parsedCidr.GetFirstIP().Add(2).String()
-	secondReserved := parsedCidr.GetFirstIP().Add(3).String()
-	lastReserved := parsedCidr.GetLastIP().String()
-	lastStatic := parsedCidr.GetLastIP().Subtract(1).String()
-	firstStatic := parsedCidr.GetLastIP().Subtract(65).String()
-
 	return networkSubnet{
-		AZ:      az,
-		Gateway: gateway,
-		Range:   cidr,
-		Reserved: []string{
-			fmt.Sprintf("%s-%s", firstReserved, secondReserved),
-			fmt.Sprintf("%s", lastReserved),
-		},
-		Static: []string{
-			fmt.Sprintf("%s-%s", firstStatic, lastStatic),
-		},
+		AZ:       az,
+		Gateway:  ranges.Gateway,
+		Range:    cidr,
+		Reserved: ranges.Reserved,
+		Static:   ranges.Static,
 		CloudProperties: subnetCloudProperties{
 			EphemeralExternalIP: true,
 			NetworkName:         networkName,
@@ -12,17 +12,27 @@ import (
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 	"github.com/pivotal-cf-experimental/gomegamatchers"
 
+	yaml "gopkg.in/yaml.v2"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
+var availableMachineTypes = []string{
+	"n1-standard-1", "g1-small", "n1-standard-2", "n1-standard-4", "n1-standard-8",
+	"n1-standard-16", "n1-standard-32", "n1-highmem-2", "n1-highmem-4", "n1-highmem-8",
+	"n1-highmem-16", "n1-highmem-32", "n1-highcpu-2", "n1-highcpu-4", "n1-highcpu-8",
+	"n1-highcpu-16", "n1-highcpu-32", "f1-micro",
+}
+
 var _ = Describe("GCPOpsGenerator", func() {
 	Describe("Generate", func() {
 		var (
-			zones            *fakes.Zones
-			terraformManager *fakes.TerraformManager
-			opsGenerator     gcp.OpsGenerator
+			zones                *fakes.Zones
+			terraformManager     *fakes.TerraformManager
+			machineTypeRetriever *fakes.MachineTypeRetriever
+			opsGenerator         gcp.OpsGenerator
 
 			incomingState   storage.State
 			expectedOpsFile []byte
@@ -31,6 +41,7 @@ var _ = Describe("GCPOpsGenerator", func() {
 		BeforeEach(func() {
 			terraformManager = &fakes.TerraformManager{}
 			zones = &fakes.Zones{}
+			machineTypeRetriever = &fakes.MachineTypeRetriever{}
 
 			incomingState = storage.State{
 				IAAS:    "gcp",
@@ -41,6 +52,7 @@ var _ = Describe("GCPOpsGenerator", func() {
 			}
 
 			zones.GetCall.Returns.Zones = []string{"us-east1-b", "us-east1-c", "us-east1-d"}
+			machineTypeRetriever.RetrieveCall.Returns.MachineTypes = availableMachineTypes
 			terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
 				"network_name":       "some-network-name",
 				"subnetwork_name":    "some-subnetwork-name",
@@ -52,7 +64,7 @@ var _ = Describe("GCPOpsGenerator", func() {
 			expectedOpsFile, err = ioutil.ReadFile(filepath.Join("fixtures", "gcp-ops.yml"))
 			Expect(err).NotTo(HaveOccurred())
 
-			opsGenerator = gcp.NewOpsGenerator(terraformManager, zones)
+			opsGenerator = gcp.NewOpsGenerator(terraformManager, zones, machineTypeRetriever)
 		})
 
 		It("returns an ops file to transform base cloud config into gcp specific cloud config", func() {
@@ -61,6 +73,7 @@ var _ = Describe("GCPOpsGenerator", func() {
 
 			Expect(zones.GetCall.Receives.Region).To(Equal("us-east1"))
 			Expect(terraformManager.GetOutputsCall.Receives.BBLState).To(Equal(incomingState))
+			Expect(machineTypeRetriever.RetrieveCall.Receives.Zones).To(Equal([]string{"us-east1-b", "us-east1-c", "us-east1-d"}))
 
 			Expect(opsYAML).To(gomegamatchers.MatchYAML(expectedOpsFile))
 		})
@@ -104,6 +117,200 @@ var _ = Describe("GCPOpsGenerator", func() {
 				}),
 		)
 
+		Context("when reserved and static ip counts are configured", func() {
+			It("sizes the ranges of that network", func() {
+				incomingState.CloudConfig.Networks = map[string]storage.CloudConfigNetwork{
+					"default": {
+						ReservedIPCount: 1,
+						StaticIPCount:   1,
+					},
+				}
+
+				opsYAML, err := opsGenerator.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				var ops []struct {
+					Path  string
+					Value interface{}
+				}
+				Expect(yaml.Unmarshal([]byte(opsYAML), &ops)).To(Succeed())
+
+				var subnets []interface{}
+				for _, op := range ops {
+					if value, ok := op.Value.(map[interface{}]interface{}); ok && op.Path == "/networks/-" && value["name"] == "default" {
+						subnets = value["subnets"].([]interface{})
+					}
+				}
+				Expect(subnets).To(HaveLen(3))
+
+				subnet := subnets[0].(map[interface{}]interface{})
+				Expect(subnet["reserved"]).To(Equal([]interface{}{"10.0.16.2", "10.0.31.255"}))
+				Expect(subnet["static"]).To(Equal([]interface{}{"10.0.31.254"}))
+			})
+		})
+
+		Context("when lb specs exist", func() {
+			It("adds a vm extension for every lb spec", func() {
+				incomingState.LBs = []storage.LBSpec{{Name: "credhub-uaa"}}
+				terraformManager.GetOutputsCall.Returns.Outputs["lb_credhub_uaa_target_pool"] = "some-credhub-uaa-target-pool"
+
+				opsYAML, err := opsGenerator.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(opsYAML).To(ContainSubstring(`- type: replace
+  path: /vm_extensions/-
+  value:
+    name: credhub-uaa-lb
+    cloud_properties:
+      target_pool: some-credhub-uaa-target-pool
+      tags:
+      - some-credhub-uaa-target-pool
+`))
+			})
+
+			It("adds the cf vm extensions with the name in front for named cf lbs", func() {
+				incomingState.LBs = []storage.LBSpec{{Name: "foundation", Type: "cf"}}
+				for _, output := range []string{"router_backend_service", "ws_target_pool", "ssh_proxy_target_pool", "tcp_router_target_pool"} {
+					terraformManager.GetOutputsCall.Returns.Outputs["lb_foundation_"+output] = "some-foundation-" + output
+				}
+
+				opsYAML, err := opsGenerator.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(opsYAML).To(ContainSubstring(`- type: replace
+  path: /vm_extensions/-
+  value:
+    name: foundation-cf-router-network-properties
+    cloud_properties:
+      backend_service: some-foundation-router_backend_service
+      target_pool: some-foundation-ws_target_pool
+      tags:
+      - some-foundation-router_backend_service
+      - some-foundation-ws_target_pool
+- type: replace
+  path: /vm_extensions/-
+  value:
+    name: foundation-diego-ssh-proxy-network-properties
+    cloud_properties:
+      target_pool: some-foundation-ssh_proxy_target_pool
+      tags:
+      - some-foundation-ssh_proxy_target_pool
+- type: replace
+  path: /vm_extensions/-
+  value:
+    name: foundation-cf-tcp-router-network-properties
+    cloud_properties:
+      target_pool: some-foundation-tcp_router_target_pool
+      tags:
+      - some-foundation-tcp_router_target_pool
+`))
+			})
+		})
+
+		Context("sizing profiles", func() {
+			findOp := func(opsYAML, path string) map[interface{}]interface{} {
+				var ops []map[interface{}]interface{}
+				Expect(yaml.Unmarshal([]byte(opsYAML), &ops)).To(Succeed())
+
+				for _, op := range ops {
+					if op["path"] == path {
+						return op
+					}
+				}
+
+				return nil
+			}
+
+			It("sizes the vm_types with the named built-in profile", func() {
+				incomingState.CloudConfig.SizingProfile = "small"
+
+				opsYAML, err := opsGenerator.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(findOp(opsYAML, "/vm_types/name=sharedcpu/cloud_properties?")["value"]).To(Equal(map[interface{}]interface{}{
+					"machine_type":      "f1-micro",
+					"root_disk_size_gb": 10,
+					"root_disk_type":    "pd-ssd",
+				}))
+				Expect(findOp(opsYAML, "/vm_types/-")["value"]).To(Equal(map[interface{}]interface{}{
+					"name": "compilation",
+					"cloud_properties": map[interface{}]interface{}{
+						"machine_type":      "n1-highcpu-8",
+						"root_disk_size_gb": 10,
+						"root_disk_type":    "pd-ssd",
+					},
+				}))
+				Expect(findOp(opsYAML, "/compilation/vm_type")["value"]).To(Equal("compilation"))
+			})
+
+			It("sizes the vm_types with a custom profile", func() {
+				incomingState.CloudConfig.CustomSizingProfile = `
+vm_types:
+- {name: default, machine_type: n1-standard-1}
+- {name: sharedcpu, machine_type: g1-small}
+- {name: small, machine_type: n1-standard-2}
+- {name: medium, machine_type: n1-standard-4}
+- {name: large, machine_type: n1-standard-8, disk_type: pd-standard, disk_size_gb: 100}
+- {name: extra-large, machine_type: n1-standard-16}
+- {name: preemptible-worker, machine_type: n1-highcpu-8, preemptible: true}
+`
+
+				opsYAML, err := opsGenerator.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(findOp(opsYAML, "/vm_types/name=large/cloud_properties?")["value"]).To(Equal(map[interface{}]interface{}{
+					"machine_type":      "n1-standard-8",
+					"root_disk_size_gb": 100,
+					"root_disk_type":    "pd-standard",
+				}))
+				Expect(findOp(opsYAML, "/vm_types/-")["value"]).To(Equal(map[interface{}]interface{}{
+					"name": "preemptible-worker",
+					"cloud_properties": map[interface{}]interface{}{
+						"machine_type":      "n1-highcpu-8",
+						"root_disk_size_gb": 10,
+						"root_disk_type":    "pd-ssd",
+						"preemptible":       true,
+					},
+				}))
+			})
+
+			It("returns an error when the named profile does not exist", func() {
+				incomingState.CloudConfig.SizingProfile = "enormous"
+
+				_, err := opsGenerator.Generate(incomingState)
+				Expect(err).To(MatchError(`unknown sizing profile "enormous"`))
+			})
+
+			It("returns an error when a machine type is not available in a zone", func() {
+				machineTypeRetriever.RetrieveCall.Returns.MachineTypes = []string{"n1-standard-1"}
+
+				_, err := opsGenerator.Generate(incomingState)
+				Expect(err).To(MatchError(`machine type "g1-small" for vm_type "sharedcpu" is not available in zone "us-east1-b"`))
+			})
+
+			It("returns an error when a vm_type in the profile has no machine type", func() {
+				incomingState.CloudConfig.CustomSizingProfile = `
+vm_types:
+- {name: default, instance_type: m3.medium}
+- {name: sharedcpu, machine_type: g1-small}
+- {name: small, machine_type: n1-standard-2}
+- {name: medium, machine_type: n1-standard-4}
+- {name: large, machine_type: n1-standard-8}
+- {name: extra-large, machine_type: n1-standard-16}
+`
+
+				_, err := opsGenerator.Generate(incomingState)
+				Expect(err).To(MatchError(`vm_type "default" in sizing profile is missing a machine_type`))
+			})
+
+			It("returns an error when the machine types cannot be retrieved", func() {
+				machineTypeRetriever.RetrieveCall.Returns.Error = errors.New("failed to retrieve machine types")
+
+				_, err := opsGenerator.Generate(incomingState)
+				Expect(err).To(MatchError("failed to retrieve machine types"))
+			})
+		})
+
 		Context("failure cases", func() {
 			It("returns an error when terraform output provider fails to retrieve", func() {
 				terraformManager.GetOutputsCall.Returns.Error = errors.New("failed to output")
@@ -0,0 +1,100 @@
+package gcp
+
+const (
+	defaultDiskType   = "pd-ssd"
+	defaultDiskSizeGB = 10
+)
+
+// sizingProfiles are the built-in sizing profiles. The machine types of the
+// default profile are still current, so the current-generation profile that
+// opts into newer aws instance types is the same on gcp.
+var sizingProfiles = map[string]string{
+	"default":            defaultVMTypes,
+	"current-generation": defaultVMTypes,
+
+	"small": `
+vm_types:
+- name: default
+  machine_type: g1-small
+- name: sharedcpu
+  machine_type: f1-micro
+- name: small
+  machine_type: n1-standard-1
+- name: medium
+  machine_type: n1-standard-2
+- name: large
+  machine_type: n1-standard-4
+- name: extra-large
+  machine_type: n1-standard-8
+- name: compilation
+  machine_type: n1-highcpu-8
+`,
+}
+
+const defaultVMTypes = `
+vm_types:
+- name: default
+  machine_type: n1-standard-1
+- name: sharedcpu
+  machine_type: g1-small
+- name: small
+  machine_type: n1-standard-2
+- name: medium
+  machine_type: n1-standard-4
+- name: large
+  machine_type: n1-standard-8
+- name: extra-large
+  machine_type: n1-standard-16
+- name: compilation
+  machine_type: n1-highcpu-8
+- name: n1-standard-1
+  machine_type: n1-standard-1
+- name: n1-standard-2
+  machine_type: n1-standard-2
+- name: n1-standard-4
+  machine_type: n1-standard-4
+- name: n1-standard-8
+  machine_type: n1-standard-8
+- name: n1-standard-16
+  machine_type: n1-standard-16
+- name: n1-standard-32
+  machine_type: n1-standard-32
+- name: n1-highmem-2
+  machine_type: n1-highmem-2
+- name: n1-highmem-4
+  machine_type: n1-highmem-4
+- name: n1-highmem-8
+  machine_type: n1-highmem-8
+- name: n1-highmem-16
+  machine_type: n1-highmem-16
+- name: n1-highmem-32
+  machine_type: n1-highmem-32
+- name: n1-highcpu-2
+  machine_type: n1-highcpu-2
+- name: n1-highcpu-4
+  machine_type: n1-highcpu-4
+- name: n1-highcpu-8
+  machine_type: n1-highcpu-8
+- name: n1-highcpu-16
+  machine_type: n1-highcpu-16
+- name: n1-highcpu-32
+  machine_type: n1-highcpu-32
+- name: f1-micro
+  machine_type: f1-micro
+- name: g1-small
+  machine_type: g1-small
+- name: m3.medium
+  machine_type: n1-standard-1
+- name: m3.large
+  machine_type: n1-standard-2
+- name: c3.large
+  machine_type: n1-highcpu-2
+- name: r3.xlarge
+  machine_type: n1-highmem-4
+- name: t2.small
+  machine_type: g1-small
+- name: small-highmem
+  machine_type: n1-highmem-4
+- name: small-highcpu
+  machine_type: n1-highcpu-2
+`
@@ -0,0 +1,109 @@
+package gcp
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry/bosh-bootloader/cloudconfig"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+type machineTypeRetriever interface {
+	Retrieve(zone string) ([]string, error)
+}
+
+type vmType struct {
+	Name            string                `yaml:"name"`
+	CloudProperties vmTypeCloudProperties `yaml:"cloud_properties"`
+}
+
+type vmTypeCloudProperties struct {
+	MachineType    string `yaml:"machine_type"`
+	RootDiskSizeGB int    `yaml:"root_disk_size_gb"`
+	RootDiskType   string `yaml:"root_disk_type"`
+	Preemptible    bool   `yaml:"preemptible,omitempty"`
+}
+
+func sizingProfile(cloudConfig storage.CloudConfig) (cloudconfig.SizingProfile, error) {
+	if cloudConfig.CustomSizingProfile != "" {
+		return cloudconfig.ParseSizingProfile(cloudConfig.CustomSizingProfile)
+	}
+
+	name := cloudConfig.SizingProfile
+	if name == "" {
+		name = cloudconfig.DefaultSizingProfile
+	}
+
+	profile, ok := sizingProfiles[name]
+	if !ok {
+		return cloudconfig.SizingProfile{}, fmt.Errorf("unknown sizing profile %q", name)
+	}
+
+	return cloudconfig.ParseSizingProfile(profile)
+}
+
+// generateVMTypeOps sizes the vm_types of the cloud config with the sizing
+// profile of the state. The compilation vms use the compilation vm_type of
+// the profile, or the default vm_type when the profile has none.
+func generateVMTypeOps(state storage.State, zones []string, machineTypeRetriever machineTypeRetriever) ([]op, error) {
+	profile, err := sizingProfile(state.CloudConfig)
+	if err != nil {
+		return []op{}, err
+	}
+
+	for _, size := range profile.VMTypes {
+		if size.MachineType == "" {
+			return []op{}, fmt.Errorf("vm_type %q in sizing profile is missing a machine_type", size.Name)
+		}
+	}
+
+	for _, zone := range zones {
+		machineTypes, err := machineTypeRetriever.Retrieve(zone)
+		if err != nil {
+			return []op{}, err
+		}
+
+		available := map[string]bool{}
+		for _, machineType := range machineTypes {
+			available[machineType] = true
+		}
+
+		for _, size := range profile.VMTypes {
+			if !available[size.MachineType] {
+				return []op{}, fmt.Errorf("machine type %q for vm_type %q is not available in zone %q", size.MachineType, size.Name, zone)
+			}
+		}
+	}
+
+	var ops []op
+	for _, size := range profile.VMTypes {
+		cloudProperties := vmTypeCloudProperties{
+			MachineType:    size.MachineType,
+			RootDiskSizeGB: defaultDiskSizeGB,
+			RootDiskType:   defaultDiskType,
+			Preemptible:    size.Preemptible,
+		}
+
+		if size.DiskSizeGB != 0 {
+			cloudProperties.RootDiskSizeGB = size.DiskSizeGB
+		}
+
+		if size.DiskType != "" {
+			cloudProperties.RootDiskType = size.DiskType
+		}
+
+		if cloudconfig.IsBaseVMType(size.Name) {
+			ops = append(ops, createOp("replace", fmt.Sprintf("/vm_types/name=%s/cloud_properties?", size.Name), cloudProperties))
+		} else {
+			ops = append(ops, createOp("replace", "/vm_types/-", vmType{
+				Name:            size.Name,
+				CloudProperties: cloudProperties,
+			}))
+		}
+
+		if size.Name == cloudconfig.CompilationVMType {
+			ops = append(ops, createOp("replace", "/compilation/vm_type", cloudconfig.CompilationVMType))
+		}
+	}
+
+	return ops, nil
+}
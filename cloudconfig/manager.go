@@ -2,16 +2,35 @@ package cloudconfig
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
 
 	"github.com/cloudfoundry/bosh-bootloader/bosh"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
+// ConfigName is the name of the cloud-config and runtime-config bbl manages
+// on the director. Configs with other names belong to operators and are left
+// alone.
+const ConfigName = "bbl"
+
+// TagsConfigName is the name of the runtime-config that has the director tag
+// the vms it creates with the tags of the state. On gcp the cpi turns the
+// tags into labels.
+const TagsConfigName = "bbl-tags"
+
+// emptyCloudConfig replaces the unnamed cloud config that earlier versions of
+// bbl uploaded, whose contents are now in the bbl cloud config, or that the
+// operator confirmed to replace.
+const emptyCloudConfig = "--- {}\n"
+
 var (
 	tempDir   func(string, string) (string, error)    = ioutil.TempDir
 	writeFile func(string, []byte, os.FileMode) error = ioutil.WriteFile
@@ -22,10 +41,13 @@ type Manager struct {
 	command            command
 	opsGenerator       opsGenerator
 	boshClientProvider boshClientProvider
+	stdin              io.Reader
 }
 
 type logger interface {
 	Step(string, ...interface{})
+	Println(string)
+	Prompt(string)
 }
 
 type command interface {
@@ -37,15 +59,16 @@ type opsGenerator interface {
 }
 
 type boshClientProvider interface {
-	Client(directorAddress, directorUsername, directorPassword string) bosh.Client
+	Client(directorAddress, directorUsername, directorPassword, directorCACert string) bosh.Client
 }
 
-func NewManager(logger logger, cmd command, opsGenerator opsGenerator, boshClientProvider boshClientProvider) Manager {
+func NewManager(logger logger, cmd command, opsGenerator opsGenerator, boshClientProvider boshClientProvider, stdin io.Reader) Manager {
 	return Manager{
 		logger:             logger,
 		command:            cmd,
 		opsGenerator:       opsGenerator,
 		boshClientProvider: boshClientProvider,
+		stdin:              stdin,
 	}
 }
 
@@ -76,6 +99,16 @@ func (m Manager) Generate(state storage.State) (string, error) {
 		"-o", fmt.Sprintf("%s/ops.yml", workingDir),
 	}
 
+	for i, userOps := range state.CloudConfig.OpsFiles {
+		userOpsPath := filepath.Join(workingDir, fmt.Sprintf("user-ops-file-%d.yml", i))
+		err = writeFile(userOpsPath, []byte(userOps), os.ModePerm)
+		if err != nil {
+			return "", err
+		}
+
+		args = append(args, "-o", userOpsPath)
+	}
+
 	err = m.command.Run(buf, workingDir, args)
 	if err != nil {
 		return "", err
@@ -84,19 +117,332 @@ func (m Manager) Generate(state storage.State) (string, error) {
 	return buf.String(), nil
 }
 
+// Diff returns the changes between the cloud-config currently on the
+// director and the one bbl would generate for the given state.
+func (m Manager) Diff(state storage.State) (string, error) {
+	cloudConfig, err := m.Generate(state)
+	if err != nil {
+		return "", err
+	}
+
+	boshClient := m.boshClient(state)
+	supportsConfigs, err := m.supportsConfigs(boshClient)
+	if err != nil {
+		return "", err
+	}
+
+	current, err := m.currentCloudConfig(boshClient, supportsConfigs)
+	if err != nil {
+		return "", err
+	}
+
+	return Diff(current.cloudConfig, cloudConfig)
+}
+
 func (m Manager) Update(state storage.State) error {
+	return m.update(state, false)
+}
+
+// UpdateWithConfirmation asks for confirmation on stdin before applying a
+// cloud-config that differs from the one on the director.
+func (m Manager) UpdateWithConfirmation(state storage.State) error {
+	return m.update(state, true)
+}
+
+func (m Manager) update(state storage.State, confirm bool) error {
 	m.logger.Step("generating cloud config")
 	cloudConfig, err := m.Generate(state)
 	if err != nil {
 		return err
 	}
 
+	err = ValidateStaticIPs(cloudConfig, state.CloudConfig.Networks)
+	if err != nil {
+		return err
+	}
+
+	boshClient := m.boshClient(state)
+	supportsConfigs, err := m.supportsConfigs(boshClient)
+	if err != nil {
+		return err
+	}
+
+	current, err := m.currentCloudConfig(boshClient, supportsConfigs)
+	if err != nil {
+		return err
+	}
+
+	if current.foreign {
+		if !confirm {
+			return errors.New("the director has an unnamed cloud config that bbl did not generate, which BOSH rejects alongside the bbl cloud config. Move its contents into a --cloud-config-ops-file and run with --interactive to replace it.")
+		}
+
+		if !m.confirmed("The director has an unnamed cloud config that bbl did not generate, which BOSH rejects alongside the bbl cloud config. Do you want to replace it with an empty one?") {
+			m.logger.Step("skipping cloud config update")
+			return nil
+		}
+	}
+
+	diff, err := Diff(current.cloudConfig, cloudConfig)
+	if err != nil {
+		return err
+	}
+
+	if diff != "" {
+		m.logger.Println(diff)
+
+		if confirm && !m.confirmed("Do you want to apply these changes to the cloud config?") {
+			m.logger.Step("skipping cloud config update")
+			return nil
+		}
+	}
+
 	m.logger.Step("applying cloud config")
-	boshClient := m.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername, state.BOSH.DirectorPassword)
-	err = boshClient.UpdateCloudConfig([]byte(cloudConfig))
+	if !supportsConfigs {
+		err = boshClient.UpdateCloudConfig([]byte(cloudConfig))
+		if err != nil {
+			return err
+		}
+
+		return m.updateLegacyRuntimeConfig(boshClient, state)
+	}
+
+	err = boshClient.UpdateConfig("cloud", ConfigName, []byte(cloudConfig))
+	if err != nil {
+		return err
+	}
+
+	if current.replace {
+		m.logger.Step("replacing the unnamed cloud config with an empty one")
+		err = boshClient.UpdateCloudConfig([]byte(emptyCloudConfig))
+		if err != nil {
+			return err
+		}
+	}
+
+	return m.updateRuntimeConfigs(boshClient, state)
+}
+
+func (m Manager) confirmed(message string) bool {
+	m.logger.Prompt(message)
+
+	var proceed string
+	fmt.Fscanln(m.stdin, &proceed)
+
+	proceed = strings.ToLower(proceed)
+	return proceed == "yes" || proceed == "y"
+}
+
+// updateRuntimeConfigs applies the runtime configs of the state and removes
+// the bbl runtime configs the state no longer has, so that every command that
+// updates the cloud config, such as delete-lbs, leaves the director in sync.
+func (m Manager) updateRuntimeConfigs(boshClient bosh.Client, state storage.State) error {
+	if state.RuntimeConfig.Contents != "" {
+		m.logger.Step("applying runtime config")
+		err := boshClient.UpdateConfig("runtime", ConfigName, []byte(state.RuntimeConfig.Contents))
+		if err != nil {
+			return err
+		}
+	} else {
+		err := boshClient.DeleteConfig("runtime", ConfigName)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(state.Tags) == 0 {
+		return boshClient.DeleteConfig("runtime", TagsConfigName)
+	}
+
+	m.logger.Step("applying tags runtime config")
+	tagsConfig, err := yaml.Marshal(map[string]map[string]string{"tags": state.Tags})
+	if err != nil {
+		// not tested
+		return err
+	}
+
+	return boshClient.UpdateConfig("runtime", TagsConfigName, tagsConfig)
+}
+
+// updateLegacyRuntimeConfig applies the runtime config and the tags of the
+// state as the unnamed runtime config on directors without named configs.
+// Without either the unnamed runtime config is left alone, as bbl cannot
+// tell whether it uploaded it.
+func (m Manager) updateLegacyRuntimeConfig(boshClient bosh.Client, state storage.State) error {
+	if state.RuntimeConfig.Contents == "" && len(state.Tags) == 0 {
+		return nil
+	}
+
+	runtimeConfig := map[string]interface{}{}
+	err := yaml.Unmarshal([]byte(state.RuntimeConfig.Contents), &runtimeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to parse runtime config: %s", err)
+	}
+
+	if len(state.Tags) > 0 {
+		runtimeConfig["tags"] = state.Tags
+	}
+
+	contents, err := yaml.Marshal(runtimeConfig)
+	if err != nil {
+		// not tested
+		return err
+	}
+
+	m.logger.Step("applying runtime config")
+	return boshClient.UpdateRuntimeConfig("", contents)
+}
+
+// Delete removes the cloud-config and runtime-config managed by bbl from the
+// director. Directors without named configs only have the unnamed configs,
+// which go away with the director.
+func (m Manager) Delete(state storage.State) error {
+	boshClient := m.boshClient(state)
+	supportsConfigs, err := m.supportsConfigs(boshClient)
+	if err != nil {
+		return err
+	}
+
+	if !supportsConfigs {
+		return nil
+	}
+
+	m.logger.Step("deleting cloud config and runtime config")
+
+	err = boshClient.DeleteConfig("cloud", ConfigName)
+	if err != nil {
+		return err
+	}
+
+	err = boshClient.DeleteConfig("runtime", ConfigName)
+	if err != nil {
+		return err
+	}
+
+	err = boshClient.DeleteConfig("runtime", TagsConfigName)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func (m Manager) supportsConfigs(boshClient bosh.Client) (bool, error) {
+	info, err := boshClient.Info()
+	if err != nil {
+		return false, err
+	}
+
+	return info.SupportsConfigs(), nil
+}
+
+type currentCloudConfig struct {
+	cloudConfig string
+
+	// replace is set for the unnamed cloud config of earlier versions of
+	// bbl, which is replaced once the bbl cloud config is uploaded.
+	replace bool
+
+	// foreign is set for an unnamed cloud config bbl did not generate.
+	foreign bool
+}
+
+// currentCloudConfig returns the cloud config bbl compares the generated one
+// with. On directors without named configs that is the unnamed cloud config,
+// which bbl uploads. Otherwise it is the bbl cloud config, or, when there is
+// none yet, the unnamed cloud config earlier versions of bbl uploaded.
+func (m Manager) currentCloudConfig(boshClient bosh.Client, supportsConfigs bool) (currentCloudConfig, error) {
+	if !supportsConfigs {
+		cloudConfig, err := boshClient.CloudConfig()
+		if err != nil {
+			return currentCloudConfig{}, err
+		}
+
+		return currentCloudConfig{cloudConfig: cloudConfig}, nil
+	}
+
+	cloudConfig, err := boshClient.Config("cloud", ConfigName)
+	if err != nil {
+		return currentCloudConfig{}, err
+	}
+
+	if cloudConfig != "" {
+		return currentCloudConfig{cloudConfig: cloudConfig}, nil
+	}
+
+	unnamedCloudConfig, err := boshClient.CloudConfig()
+	if err != nil {
+		return currentCloudConfig{}, err
+	}
+
+	switch {
+	case isEmptyYAML(unnamedCloudConfig):
+		return currentCloudConfig{}, nil
+	case generatedByBBL(unnamedCloudConfig):
+		return currentCloudConfig{cloudConfig: unnamedCloudConfig, replace: true}, nil
+	default:
+		return currentCloudConfig{replace: true, foreign: true}, nil
+	}
+}
+
+// generatedByBBL tells whether a cloud config has the compilation network,
+// disk types and vm extensions of the base cloud config of bbl.
+func generatedByBBL(cloudConfig string) bool {
+	names, err := cloudConfigNames(cloudConfig)
+	if err != nil {
+		return false
+	}
+
+	baseNames, err := cloudConfigNames(BaseCloudConfig)
+	if err != nil {
+		// not tested
+		return false
+	}
+
+	for name := range baseNames {
+		if !names[name] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func cloudConfigNames(cloudConfig string) (map[string]bool, error) {
+	var config struct {
+		Compilation struct {
+			Network string `yaml:"network"`
+		} `yaml:"compilation"`
+		DiskTypes []struct {
+			Name string `yaml:"name"`
+		} `yaml:"disk_types"`
+		VMExtensions []struct {
+			Name string `yaml:"name"`
+		} `yaml:"vm_extensions"`
+	}
+
+	err := yaml.Unmarshal([]byte(cloudConfig), &config)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{"compilation/" + config.Compilation.Network: true}
+	for _, diskType := range config.DiskTypes {
+		names["disk_types/"+diskType.Name] = true
+	}
+	for _, vmExtension := range config.VMExtensions {
+		names["vm_extensions/"+vmExtension.Name] = true
+	}
+
+	return names, nil
+}
+
+func isEmptyYAML(contents string) bool {
+	var parsed map[string]interface{}
+	err := yaml.Unmarshal([]byte(contents), &parsed)
+	return err == nil && len(parsed) == 0
+}
+
+func (m Manager) boshClient(state storage.State) bosh.Client {
+	return m.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername, state.BOSH.DirectorPassword, state.BOSH.DirectorSSLCA)
+}
@@ -1,6 +1,7 @@
 package cloudconfig_test
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -8,6 +9,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
 	"github.com/cloudfoundry/bosh-bootloader/cloudconfig"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
@@ -16,6 +18,12 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+const generatedCloudConfig = `vm_types:
+- name: default
+  cloud_properties:
+    machine_type: n1-standard-1
+`
+
 var _ = Describe("Manager", func() {
 	var (
 		logger             *fakes.Logger
@@ -27,6 +35,7 @@ var _ = Describe("Manager", func() {
 
 		tempDir       string
 		incomingState storage.State
+		stdin         *bytes.Buffer
 
 		baseCloudConfig []byte
 	)
@@ -36,6 +45,7 @@ var _ = Describe("Manager", func() {
 		cmd = &fakes.BOSHCommand{}
 		opsGenerator = &fakes.CloudConfigOpsGenerator{}
 		boshClient = &fakes.BOSHClient{}
+		stdin = bytes.NewBuffer([]byte{})
 		boshClientProvider = &fakes.BOSHClientProvider{}
 
 		boshClientProvider.ClientCall.Returns.Client = boshClient
@@ -49,7 +59,7 @@ var _ = Describe("Manager", func() {
 		})
 
 		cmd.RunCall.Stub = func(stdout io.Writer) {
-			stdout.Write([]byte("some-cloud-config"))
+			stdout.Write([]byte(generatedCloudConfig))
 		}
 
 		incomingState = storage.State{
@@ -58,6 +68,7 @@ var _ = Describe("Manager", func() {
 				DirectorAddress:  "some-director-address",
 				DirectorUsername: "some-director-username",
 				DirectorPassword: "some-director-password",
+				DirectorSSLCA:    "some-director-ca-cert",
 			},
 		}
 
@@ -66,7 +77,7 @@ var _ = Describe("Manager", func() {
 		baseCloudConfig, err = ioutil.ReadFile("fixtures/base-cloud-config.yml")
 		Expect(err).NotTo(HaveOccurred())
 
-		manager = cloudconfig.NewManager(logger, cmd, opsGenerator, boshClientProvider)
+		manager = cloudconfig.NewManager(logger, cmd, opsGenerator, boshClientProvider, stdin)
 	})
 
 	AfterEach(func() {
@@ -97,7 +108,33 @@ var _ = Describe("Manager", func() {
 			Expect(cmd.RunCall.Receives.WorkingDirectory).To(Equal(tempDir))
 			Expect(cmd.RunCall.Receives.Args).To(Equal(expectedArgs))
 
-			Expect(cloudConfigYAML).To(Equal("some-cloud-config"))
+			Expect(cloudConfigYAML).To(Equal(generatedCloudConfig))
+		})
+
+		Context("when the state contains user cloud config ops files", func() {
+			BeforeEach(func() {
+				incomingState.CloudConfig.OpsFiles = []string{"some-user-ops", "some-other-user-ops"}
+			})
+
+			It("applies them in order after the generated ops", func() {
+				_, err := manager.Generate(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(cmd.RunCall.Receives.Args).To(Equal([]string{
+					"interpolate", fmt.Sprintf("%s/cloud-config.yml", tempDir),
+					"-o", fmt.Sprintf("%s/ops.yml", tempDir),
+					"-o", fmt.Sprintf("%s/user-ops-file-0.yml", tempDir),
+					"-o", fmt.Sprintf("%s/user-ops-file-1.yml", tempDir),
+				}))
+
+				userOps, err := ioutil.ReadFile(fmt.Sprintf("%s/user-ops-file-0.yml", tempDir))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(userOps)).To(Equal("some-user-ops"))
+
+				otherUserOps, err := ioutil.ReadFile(fmt.Sprintf("%s/user-ops-file-1.yml", tempDir))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(otherUserOps)).To(Equal("some-other-user-ops"))
+			})
 		})
 
 		Context("failure cases", func() {
@@ -169,6 +206,30 @@ var _ = Describe("Manager", func() {
 				})
 			})
 
+			Context("when write file fails to write a user ops file", func() {
+				BeforeEach(func() {
+					cloudconfig.SetWriteFile(func(filename string, body []byte, mode os.FileMode) error {
+						if strings.Contains(filename, "user-ops-file") {
+							return errors.New("failed to write file")
+						}
+						return nil
+					})
+				})
+
+				AfterEach(func() {
+					cloudconfig.ResetWriteFile()
+				})
+
+				It("returns an error", func() {
+					_, err := manager.Generate(storage.State{
+						CloudConfig: storage.CloudConfig{
+							OpsFiles: []string{"some-user-ops"},
+						},
+					})
+					Expect(err).To(MatchError("failed to write file"))
+				})
+			})
+
 			Context("when command fails to run", func() {
 				BeforeEach(func() {
 					cmd.RunCall.Returns.Error = errors.New("failed to run")
@@ -199,11 +260,283 @@ var _ = Describe("Manager", func() {
 			Expect(boshClientProvider.ClientCall.Receives.DirectorAddress).To(Equal("some-director-address"))
 			Expect(boshClientProvider.ClientCall.Receives.DirectorUsername).To(Equal("some-director-username"))
 			Expect(boshClientProvider.ClientCall.Receives.DirectorPassword).To(Equal("some-director-password"))
+			Expect(boshClientProvider.ClientCall.Receives.DirectorCACert).To(Equal("some-director-ca-cert"))
+
+			Expect(boshClient.ConfigCall.Receives.Type).To(Equal("cloud"))
+			Expect(boshClient.ConfigCall.Receives.Name).To(Equal("bbl"))
+			Expect(boshClient.UpdateConfigCall.Receives).To(Equal([]fakes.UpdateConfigReceive{{
+				Type: "cloud",
+				Name: "bbl",
+				Yaml: []byte(generatedCloudConfig),
+			}}))
+		})
+
+		Context("when the state contains a runtime config", func() {
+			BeforeEach(func() {
+				incomingState.RuntimeConfig.Contents = "addons: []"
+			})
+
+			It("applies it as the bbl runtime config after the cloud config", func() {
+				err := manager.Update(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.StepCall.Messages).To(Equal([]string{
+					"generating cloud config",
+					"applying cloud config",
+					"applying runtime config",
+				}))
+				Expect(boshClient.UpdateConfigCall.Receives).To(Equal([]fakes.UpdateConfigReceive{
+					{Type: "cloud", Name: "bbl", Yaml: []byte(generatedCloudConfig)},
+					{Type: "runtime", Name: "bbl", Yaml: []byte("addons: []")},
+				}))
+				Expect(boshClient.DeleteConfigCall.Receives).To(Equal([]fakes.DeleteConfigReceive{
+					{Type: "runtime", Name: "bbl-tags"},
+				}))
+			})
+		})
+
+		It("removes the bbl runtime configs that the state does not contain", func() {
+			err := manager.Update(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(boshClient.DeleteConfigCall.Receives).To(Equal([]fakes.DeleteConfigReceive{
+				{Type: "runtime", Name: "bbl"},
+				{Type: "runtime", Name: "bbl-tags"},
+			}))
+		})
+
+		It("returns an error when a runtime config cannot be removed", func() {
+			boshClient.DeleteConfigCall.Returns.Error = errors.New("failed to delete config")
+
+			err := manager.Update(incomingState)
+			Expect(err).To(MatchError("failed to delete config"))
+		})
+
+		Context("when the state contains tags", func() {
+			BeforeEach(func() {
+				incomingState.Tags = map[string]string{"owner": "platform", "cost-center": "1234"}
+			})
+
+			It("applies them as the bbl-tags runtime config", func() {
+				err := manager.Update(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.StepCall.Messages).To(Equal([]string{
+					"generating cloud config",
+					"applying cloud config",
+					"applying tags runtime config",
+				}))
+				Expect(boshClient.UpdateConfigCall.Receives).To(Equal([]fakes.UpdateConfigReceive{
+					{Type: "cloud", Name: "bbl", Yaml: []byte(generatedCloudConfig)},
+					{Type: "runtime", Name: "bbl-tags", Yaml: []byte("tags:\n  cost-center: \"1234\"\n  owner: platform\n")},
+				}))
+			})
+		})
+
+		It("prints the changes to the director's current cloud config", func() {
+			boshClient.ConfigCall.Returns.Config = `vm_types:
+- name: default
+  cloud_properties:
+    machine_type: n1-standard-2
+`
+
+			err := manager.Update(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(boshClient.ConfigCall.CallCount).To(Equal(1))
+			Expect(logger.PrintlnCall.Messages).To(Equal([]string{strings.Join([]string{
+				"vm_types:",
+				"  ~ default",
+				"      cloud_properties:",
+				"  -     machine_type: n1-standard-2",
+				"  +     machine_type: n1-standard-1",
+				"      name: default",
+			}, "\n")}))
+			Expect(logger.PromptCall.CallCount).To(Equal(0))
+			Expect(boshClient.UpdateConfigCall.CallCount).To(Equal(1))
+		})
+
+		It("does not print anything when the cloud config is unchanged", func() {
+			boshClient.ConfigCall.Returns.Config = generatedCloudConfig
+
+			err := manager.Update(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PrintlnCall.CallCount).To(Equal(0))
+			Expect(boshClient.UpdateConfigCall.CallCount).To(Equal(1))
+		})
+
+		Context("when the director has the unnamed cloud config of an earlier bbl", func() {
+			BeforeEach(func() {
+				boshClient.CloudConfigCall.Returns.CloudConfig = cloudconfig.BaseCloudConfig
+			})
+
+			It("replaces it with an empty cloud config after applying the bbl cloud config", func() {
+				err := manager.Update(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.StepCall.Messages).To(Equal([]string{
+					"generating cloud config",
+					"applying cloud config",
+					"replacing the unnamed cloud config with an empty one",
+				}))
+				Expect(boshClient.UpdateConfigCall.Receives).To(Equal([]fakes.UpdateConfigReceive{{
+					Type: "cloud",
+					Name: "bbl",
+					Yaml: []byte(generatedCloudConfig),
+				}}))
+				Expect(boshClient.UpdateCloudConfigCall.Receives.Yaml).To(Equal([]byte("--- {}\n")))
+			})
+
+			It("compares the generated cloud config with the unnamed cloud config", func() {
+				cmd.RunCall.Stub = func(stdout io.Writer) {
+					stdout.Write([]byte(cloudconfig.BaseCloudConfig))
+				}
+
+				err := manager.Update(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.PrintlnCall.CallCount).To(Equal(0))
+			})
+
+			It("leaves the unnamed cloud config alone once the bbl cloud config exists", func() {
+				boshClient.ConfigCall.Returns.Config = generatedCloudConfig
 
-			Expect(boshClient.UpdateCloudConfigCall.Receives.Yaml).To(Equal([]byte("some-cloud-config")))
+				err := manager.Update(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshClient.CloudConfigCall.CallCount).To(Equal(0))
+				Expect(boshClient.UpdateCloudConfigCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the unnamed cloud config cannot be replaced", func() {
+				boshClient.UpdateCloudConfigCall.Returns.Error = errors.New("failed to update cloud config")
+
+				err := manager.Update(incomingState)
+				Expect(err).To(MatchError("failed to update cloud config"))
+			})
+		})
+
+		Context("when the director has an unnamed cloud config that bbl did not generate", func() {
+			BeforeEach(func() {
+				boshClient.CloudConfigCall.Returns.CloudConfig = "vm_types:\n- name: operator-vm-type\n"
+			})
+
+			It("returns an error without updating the cloud config", func() {
+				err := manager.Update(incomingState)
+				Expect(err).To(MatchError("the director has an unnamed cloud config that bbl did not generate, which BOSH rejects alongside the bbl cloud config. Move its contents into a --cloud-config-ops-file and run with --interactive to replace it."))
+
+				Expect(boshClient.UpdateConfigCall.CallCount).To(Equal(0))
+				Expect(boshClient.UpdateCloudConfigCall.CallCount).To(Equal(0))
+			})
+
+			It("replaces it with an empty cloud config when the replacement is confirmed", func() {
+				stdin.WriteString("yes\nyes\n")
+
+				err := manager.UpdateWithConfirmation(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.PromptCall.Receives.Message).To(Equal("Do you want to apply these changes to the cloud config?"))
+				Expect(boshClient.UpdateConfigCall.Receives[0].Yaml).To(Equal([]byte(generatedCloudConfig)))
+				Expect(boshClient.UpdateCloudConfigCall.Receives.Yaml).To(Equal([]byte("--- {}\n")))
+			})
+
+			It("skips the update when the replacement is declined", func() {
+				stdin.WriteString("no\n")
+
+				err := manager.UpdateWithConfirmation(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.PromptCall.Receives.Message).To(Equal("The director has an unnamed cloud config that bbl did not generate, which BOSH rejects alongside the bbl cloud config. Do you want to replace it with an empty one?"))
+				Expect(logger.StepCall.Messages).To(ContainElement("skipping cloud config update"))
+				Expect(boshClient.UpdateConfigCall.CallCount).To(Equal(0))
+				Expect(boshClient.UpdateCloudConfigCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when the director does not have the configs api", func() {
+			BeforeEach(func() {
+				boshClient.InfoCall.Returns.Info = bosh.Info{Version: "260.5.0 (00000000)"}
+			})
+
+			It("applies the cloud config as the unnamed cloud config", func() {
+				boshClient.CloudConfigCall.Returns.CloudConfig = "vm_types:\n- name: operator-vm-type\n"
+
+				err := manager.Update(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshClient.UpdateCloudConfigCall.Receives.Yaml).To(Equal([]byte(generatedCloudConfig)))
+				Expect(boshClient.ConfigCall.CallCount).To(Equal(0))
+				Expect(boshClient.UpdateConfigCall.CallCount).To(Equal(0))
+				Expect(boshClient.DeleteConfigCall.CallCount).To(Equal(0))
+				Expect(boshClient.UpdateRuntimeConfigCall.CallCount).To(Equal(0))
+			})
+
+			It("compares the generated cloud config with the unnamed cloud config", func() {
+				boshClient.CloudConfigCall.Returns.CloudConfig = generatedCloudConfig
+
+				err := manager.Update(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.PrintlnCall.CallCount).To(Equal(0))
+			})
+
+			It("applies the runtime config and the tags as the unnamed runtime config", func() {
+				incomingState.RuntimeConfig.Contents = "addons: []"
+				incomingState.Tags = map[string]string{"owner": "platform"}
+
+				err := manager.Update(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshClient.UpdateRuntimeConfigCall.Receives.Name).To(Equal(""))
+				Expect(boshClient.UpdateRuntimeConfigCall.Receives.Yaml).To(Equal([]byte("addons: []\ntags:\n  owner: platform\n")))
+				Expect(boshClient.UpdateConfigCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the runtime config is not valid yaml", func() {
+				incomingState.RuntimeConfig.Contents = "%%%"
+
+				err := manager.Update(incomingState)
+				Expect(err).To(MatchError(ContainSubstring("failed to parse runtime config")))
+			})
+
+			It("diffs against the unnamed cloud config", func() {
+				boshClient.CloudConfigCall.Returns.CloudConfig = generatedCloudConfig
+
+				diff, err := manager.Diff(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(diff).To(Equal(""))
+				Expect(boshClient.ConfigCall.CallCount).To(Equal(0))
+			})
+
+			It("does not delete any config", func() {
+				err := manager.Delete(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshClient.DeleteConfigCall.CallCount).To(Equal(0))
+			})
 		})
 
 		Context("failure cases", func() {
+			Context("when bosh client fails to get the director info", func() {
+				It("returns an error", func() {
+					boshClient.InfoCall.Returns.Error = errors.New("failed to get info")
+
+					err := manager.Update(incomingState)
+					Expect(err).To(MatchError("failed to get info"))
+				})
+			})
+
+			Context("when bosh client fails to get the unnamed cloud config", func() {
+				It("returns an error", func() {
+					boshClient.CloudConfigCall.Returns.Error = errors.New("failed to get cloud config")
+
+					err := manager.Update(incomingState)
+					Expect(err).To(MatchError("failed to get cloud config"))
+				})
+			})
+
 			Context("when manager generate's command fails to run", func() {
 				BeforeEach(func() {
 					cmd.RunCall.Returns.Error = errors.New("failed to run")
@@ -215,9 +548,45 @@ var _ = Describe("Manager", func() {
 				})
 			})
 
+			Context("when bosh client fails to get the current cloud config", func() {
+				BeforeEach(func() {
+					boshClient.ConfigCall.Returns.Error = errors.New("failed to get cloud config")
+				})
+
+				It("returns an error", func() {
+					err := manager.Update(storage.State{})
+					Expect(err).To(MatchError("failed to get cloud config"))
+				})
+			})
+
+			Context("when the current cloud config is not valid yaml", func() {
+				BeforeEach(func() {
+					boshClient.ConfigCall.Returns.Config = "%%%"
+				})
+
+				It("returns an error", func() {
+					err := manager.Update(storage.State{})
+					Expect(err).To(MatchError(ContainSubstring("failed to parse current cloud config")))
+				})
+			})
+
+			Context("when a static ip allocated in the state is no longer generated", func() {
+				It("returns an error without updating the cloud config", func() {
+					err := manager.Update(storage.State{
+						CloudConfig: storage.CloudConfig{
+							Networks: map[string]storage.CloudConfigNetwork{
+								"private": {StaticIPs: []string{"10.0.31.190"}},
+							},
+						},
+					})
+					Expect(err).To(MatchError(`network "private" not found in cloud config`))
+					Expect(boshClient.UpdateConfigCall.CallCount).To(Equal(0))
+				})
+			})
+
 			Context("when bosh client fails to update cloud config", func() {
 				BeforeEach(func() {
-					boshClient.UpdateCloudConfigCall.Returns.Error = errors.New("failed to update")
+					boshClient.UpdateConfigCall.Returns.Error = errors.New("failed to update")
 				})
 
 				It("returns an error", func() {
@@ -227,4 +596,92 @@ var _ = Describe("Manager", func() {
 			})
 		})
 	})
+
+	Describe("UpdateWithConfirmation", func() {
+		It("applies the cloud config when the changes are confirmed", func() {
+			stdin.WriteString("yes\n")
+
+			err := manager.UpdateWithConfirmation(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PromptCall.Receives.Message).To(Equal("Do you want to apply these changes to the cloud config?"))
+			Expect(boshClient.UpdateConfigCall.Receives[0].Yaml).To(Equal([]byte(generatedCloudConfig)))
+		})
+
+		It("skips the update when the changes are declined", func() {
+			stdin.WriteString("no\n")
+
+			err := manager.UpdateWithConfirmation(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PromptCall.CallCount).To(Equal(1))
+			Expect(logger.StepCall.Messages).To(ContainElement("skipping cloud config update"))
+			Expect(boshClient.UpdateConfigCall.CallCount).To(Equal(0))
+		})
+
+		It("does not ask for confirmation when the cloud config is unchanged", func() {
+			boshClient.ConfigCall.Returns.Config = generatedCloudConfig
+
+			err := manager.UpdateWithConfirmation(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PromptCall.CallCount).To(Equal(0))
+			Expect(boshClient.UpdateConfigCall.CallCount).To(Equal(1))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("deletes the bbl cloud config and runtime configs", func() {
+			err := manager.Delete(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(boshClientProvider.ClientCall.Receives.DirectorAddress).To(Equal("some-director-address"))
+			Expect(logger.StepCall.Messages).To(Equal([]string{"deleting cloud config and runtime config"}))
+			Expect(boshClient.DeleteConfigCall.Receives).To(Equal([]fakes.DeleteConfigReceive{
+				{Type: "cloud", Name: "bbl"},
+				{Type: "runtime", Name: "bbl"},
+				{Type: "runtime", Name: "bbl-tags"},
+			}))
+		})
+
+		It("returns an error when a config cannot be deleted", func() {
+			boshClient.DeleteConfigCall.Returns.Error = errors.New("failed to delete config")
+
+			err := manager.Delete(incomingState)
+			Expect(err).To(MatchError("failed to delete config"))
+		})
+	})
+
+	Describe("Diff", func() {
+		It("returns the changes to the director's current cloud config", func() {
+			diff, err := manager.Diff(incomingState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(boshClientProvider.ClientCall.Receives.DirectorAddress).To(Equal("some-director-address"))
+			Expect(diff).To(Equal(strings.Join([]string{
+				"vm_types:",
+				"  + default",
+				"  +   cloud_properties:",
+				"  +     machine_type: n1-standard-1",
+				"  +   name: default",
+			}, "\n")))
+			Expect(boshClient.UpdateConfigCall.CallCount).To(Equal(0))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the cloud config cannot be generated", func() {
+				cmd.RunCall.Returns.Error = errors.New("failed to run")
+
+				_, err := manager.Diff(incomingState)
+				Expect(err).To(MatchError("failed to run"))
+			})
+
+			It("returns an error when the current cloud config cannot be fetched", func() {
+				boshClient.ConfigCall.Returns.Error = errors.New("failed to get cloud config")
+
+				_, err := manager.Diff(incomingState)
+				Expect(err).To(MatchError("failed to get cloud config"))
+			})
+		})
+	})
 })
@@ -0,0 +1,257 @@
+package cloudconfig
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	DefaultReservedIPCount = 2
+	DefaultStaticIPCount   = 65
+)
+
+// NetworkNames are the networks bbl generates in the cloud-config.
+var NetworkNames = []string{"private", "default"}
+
+// SubnetRanges are the addresses of a single subnet that bosh must not
+// assign dynamically.
+type SubnetRanges struct {
+	Gateway  string
+	Reserved []string
+	Static   []string
+}
+
+// NetworkIPCounts returns the number of reserved and static ips of each
+// subnet in the given network, falling back to the defaults.
+func NetworkIPCounts(cloudConfig storage.CloudConfig, network string) (int, int) {
+	reservedCount := DefaultReservedIPCount
+	staticCount := DefaultStaticIPCount
+
+	if config, ok := cloudConfig.Networks[network]; ok {
+		if config.ReservedIPCount > 0 {
+			reservedCount = config.ReservedIPCount
+		}
+		if config.StaticIPCount > 0 {
+			staticCount = config.StaticIPCount
+		}
+	}
+
+	return reservedCount, staticCount
+}
+
+// GenerateSubnetRanges lays out a subnet with the gateway on its second
+// address, reservedCount addresses reserved right after the gateway and
+// staticCount static addresses right before its last address, which is
+// always reserved.
+func GenerateSubnetRanges(cidr string, reservedCount, staticCount int) (SubnetRanges, error) {
+	parsedCidr, err := bosh.ParseCIDRBlock(cidr)
+	if err != nil {
+		return SubnetRanges{}, err
+	}
+
+	if parsedCidr.Size().Cmp(big.NewInt(int64(reservedCount+staticCount+3))) < 0 {
+		return SubnetRanges{}, fmt.Errorf("%d reserved and %d static ips do not fit in subnet %s", reservedCount, staticCount, cidr)
+	}
+
+	firstIP := parsedCidr.GetFirstIP()
+	lastIP := parsedCidr.GetLastIP()
+
+	return SubnetRanges{
+		Gateway: firstIP.Add(1).String(),
+		Reserved: []string{
+			ipRange(firstIP.Add(2), firstIP.Add(1+reservedCount)),
+			lastIP.String(),
+		},
+		Static: []string{
+			ipRange(lastIP.Subtract(staticCount), lastIP.Subtract(1)),
+		},
+	}, nil
+}
+
+func ipRange(first, last bosh.IP) string {
+	if first.Compare(last) == 0 {
+		return first.String()
+	}
+	return fmt.Sprintf("%s-%s", first, last)
+}
+
+// AllocateStaticIPs returns count static ips of the network in the given
+// cloud-config that are not allocated yet. The networks bbl generates share
+// their subnets, so an ip allocated on any of the networks is taken in every
+// subnet with the same range.
+func AllocateStaticIPs(cloudConfig, network string, networks map[string]storage.CloudConfigNetwork, count int) ([]string, error) {
+	subnetsByNetwork, err := parseStaticSubnets(cloudConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	subnets, ok := subnetsByNetwork[network]
+	if !ok {
+		return nil, fmt.Errorf("network %q not found in cloud config", network)
+	}
+
+	taken := map[string]bool{}
+	for name, config := range networks {
+		for _, subnet := range subnetsByNetwork[name] {
+			inSubnet := map[string]bool{}
+			for _, ip := range subnet.staticIPs {
+				inSubnet[ip] = true
+			}
+
+			for _, ip := range config.StaticIPs {
+				if inSubnet[ip] {
+					taken[subnet.key(ip)] = true
+				}
+			}
+		}
+	}
+
+	var ips []string
+	for _, subnet := range subnets {
+		for _, ip := range subnet.staticIPs {
+			if len(ips) == count {
+				break
+			}
+			if !taken[subnet.key(ip)] {
+				ips = append(ips, ip)
+			}
+		}
+	}
+
+	if len(ips) < count {
+		return nil, fmt.Errorf("network %q only has %d unallocated static ips, %d requested", network, len(ips), count)
+	}
+
+	return ips, nil
+}
+
+// ValidateStaticIPs makes sure every static ip recorded in the state is
+// still part of its network's static range in the given cloud-config.
+func ValidateStaticIPs(cloudConfig string, networks map[string]storage.CloudConfigNetwork) error {
+	for _, network := range NetworkNames {
+		allocated := networks[network].StaticIPs
+		if len(allocated) == 0 {
+			continue
+		}
+
+		staticIPs, err := networkStaticIPs(cloudConfig, network)
+		if err != nil {
+			return err
+		}
+
+		inRange := map[string]bool{}
+		for _, ip := range staticIPs {
+			inRange[ip] = true
+		}
+
+		for _, ip := range allocated {
+			if !inRange[ip] {
+				return fmt.Errorf("static ip %s allocated on network %q is outside of its static range", ip, network)
+			}
+		}
+	}
+
+	return nil
+}
+
+type staticSubnet struct {
+	cidr      string
+	staticIPs []string
+}
+
+// key identifies an ip of the subnet across the networks that share it.
+func (s staticSubnet) key(ip string) string {
+	return fmt.Sprintf("%s %s", s.cidr, ip)
+}
+
+func networkStaticIPs(cloudConfig, network string) ([]string, error) {
+	subnetsByNetwork, err := parseStaticSubnets(cloudConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	subnets, ok := subnetsByNetwork[network]
+	if !ok {
+		return nil, fmt.Errorf("network %q not found in cloud config", network)
+	}
+
+	var ips []string
+	for _, subnet := range subnets {
+		ips = append(ips, subnet.staticIPs...)
+	}
+
+	return ips, nil
+}
+
+// parseStaticSubnets returns the subnets of each network in the cloud-config
+// with their expanded static ips.
+func parseStaticSubnets(cloudConfig string) (map[string][]staticSubnet, error) {
+	var contents struct {
+		Networks []struct {
+			Name    string `yaml:"name"`
+			Subnets []struct {
+				Range  string   `yaml:"range"`
+				Static []string `yaml:"static"`
+			} `yaml:"subnets"`
+		} `yaml:"networks"`
+	}
+	err := yaml.Unmarshal([]byte(cloudConfig), &contents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cloud config: %s", err)
+	}
+
+	subnetsByNetwork := map[string][]staticSubnet{}
+	for _, n := range contents.Networks {
+		subnets := []staticSubnet{}
+		for _, subnet := range n.Subnets {
+			parsed := staticSubnet{cidr: subnet.Range}
+			for _, static := range subnet.Static {
+				expanded, err := expandIPRange(static)
+				if err != nil {
+					return nil, err
+				}
+				parsed.staticIPs = append(parsed.staticIPs, expanded...)
+			}
+			subnets = append(subnets, parsed)
+		}
+
+		subnetsByNetwork[n.Name] = subnets
+	}
+
+	return subnetsByNetwork, nil
+}
+
+func expandIPRange(ipRange string) ([]string, error) {
+	parts := strings.SplitN(ipRange, "-", 2)
+
+	first, err := bosh.ParseIP(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	last := first
+	if len(parts) == 2 {
+		last, err = bosh.ParseIP(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var ips []string
+	for ip := first; ip.Compare(last) <= 0; ip = ip.Add(1) {
+		ips = append(ips, ip.String())
+
+		// stop before Add wraps around past the highest address
+		if ip.Compare(last) == 0 {
+			break
+		}
+	}
+
+	return ips, nil
+}
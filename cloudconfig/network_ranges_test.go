@@ -0,0 +1,153 @@
+package cloudconfig_test
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/cloudconfig"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NetworkRanges", func() {
+	Describe("NetworkIPCounts", func() {
+		It("returns the defaults when the network is not configured", func() {
+			reservedCount, staticCount := cloudconfig.NetworkIPCounts(storage.CloudConfig{}, "private")
+			Expect(reservedCount).To(Equal(2))
+			Expect(staticCount).To(Equal(65))
+		})
+
+		It("returns the configured counts", func() {
+			reservedCount, staticCount := cloudconfig.NetworkIPCounts(storage.CloudConfig{
+				Networks: map[string]storage.CloudConfigNetwork{
+					"private": {ReservedIPCount: 10, StaticIPCount: 200},
+				},
+			}, "private")
+			Expect(reservedCount).To(Equal(10))
+			Expect(staticCount).To(Equal(200))
+		})
+	})
+
+	Describe("GenerateSubnetRanges", func() {
+		It("lays out the gateway, reserved and static ranges", func() {
+			ranges, err := cloudconfig.GenerateSubnetRanges("10.0.16.0/20", 2, 65)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ranges).To(Equal(cloudconfig.SubnetRanges{
+				Gateway:  "10.0.16.1",
+				Reserved: []string{"10.0.16.2-10.0.16.3", "10.0.31.255"},
+				Static:   []string{"10.0.31.190-10.0.31.254"},
+			}))
+		})
+
+		It("uses every address of a subnet that is exactly large enough", func() {
+			ranges, err := cloudconfig.GenerateSubnetRanges("10.0.0.0/29", 2, 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ranges).To(Equal(cloudconfig.SubnetRanges{
+				Gateway:  "10.0.0.1",
+				Reserved: []string{"10.0.0.2-10.0.0.3", "10.0.0.7"},
+				Static:   []string{"10.0.0.4-10.0.0.6"},
+			}))
+		})
+
+		It("returns an error when the ranges do not fit", func() {
+			_, err := cloudconfig.GenerateSubnetRanges("10.0.0.0/29", 2, 4)
+			Expect(err).To(MatchError("2 reserved and 4 static ips do not fit in subnet 10.0.0.0/29"))
+		})
+
+		It("returns an error when the cidr cannot be parsed", func() {
+			_, err := cloudconfig.GenerateSubnetRanges("****", 2, 65)
+			Expect(err).To(MatchError(`"****" cannot parse CIDR block`))
+		})
+	})
+
+	Describe("AllocateStaticIPs", func() {
+		var cloudConfig string
+
+		BeforeEach(func() {
+			cloudConfig = `networks:
+- name: private
+  subnets:
+  - range: 10.0.16.0/20
+    static: [10.0.31.252-10.0.31.254]
+  - range: 10.0.32.0/20
+    static: [10.0.47.254]
+- name: default
+  subnets:
+  - range: 10.0.16.0/20
+    static: [10.0.31.252-10.0.31.254]
+  - range: 10.0.32.0/20
+    static: [10.0.47.254]
+`
+		})
+
+		It("hands out unallocated static ips across the network's subnets", func() {
+			ips, err := cloudconfig.AllocateStaticIPs(cloudConfig, "private", map[string]storage.CloudConfigNetwork{
+				"private": {StaticIPs: []string{"10.0.31.253"}},
+			}, 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ips).To(Equal([]string{"10.0.31.252", "10.0.31.254", "10.0.47.254"}))
+		})
+
+		It("does not hand out the ips allocated on another network in the same subnets", func() {
+			networks := map[string]storage.CloudConfigNetwork{}
+
+			privateIPs, err := cloudconfig.AllocateStaticIPs(cloudConfig, "private", networks, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(privateIPs).To(Equal([]string{"10.0.31.252", "10.0.31.253"}))
+			networks["private"] = storage.CloudConfigNetwork{StaticIPs: privateIPs}
+
+			defaultIPs, err := cloudconfig.AllocateStaticIPs(cloudConfig, "default", networks, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(defaultIPs).To(Equal([]string{"10.0.31.254", "10.0.47.254"}))
+			networks["default"] = storage.CloudConfigNetwork{StaticIPs: defaultIPs}
+
+			_, err = cloudconfig.AllocateStaticIPs(cloudConfig, "private", networks, 1)
+			Expect(err).To(MatchError(`network "private" only has 0 unallocated static ips, 1 requested`))
+		})
+
+		It("returns an error when there are not enough unallocated static ips", func() {
+			_, err := cloudconfig.AllocateStaticIPs(cloudConfig, "private", map[string]storage.CloudConfigNetwork{
+				"private": {StaticIPs: []string{"10.0.31.253"}},
+			}, 4)
+			Expect(err).To(MatchError(`network "private" only has 3 unallocated static ips, 4 requested`))
+		})
+
+		It("returns an error when the network does not exist", func() {
+			_, err := cloudconfig.AllocateStaticIPs(cloudConfig, "public", nil, 1)
+			Expect(err).To(MatchError(`network "public" not found in cloud config`))
+		})
+
+		It("returns an error when the cloud config cannot be parsed", func() {
+			_, err := cloudconfig.AllocateStaticIPs("%%%", "private", nil, 1)
+			Expect(err).To(MatchError(ContainSubstring("failed to parse cloud config")))
+		})
+	})
+
+	Describe("ValidateStaticIPs", func() {
+		var cloudConfig string
+
+		BeforeEach(func() {
+			cloudConfig = `networks:
+- name: private
+  subnets:
+  - static: [10.0.31.252-10.0.31.254]
+- name: default
+  subnets:
+  - static: [10.0.31.190-10.0.31.254]
+`
+		})
+
+		It("succeeds when every allocated ip is in its static range", func() {
+			err := cloudconfig.ValidateStaticIPs(cloudConfig, map[string]storage.CloudConfigNetwork{
+				"private": {StaticIPs: []string{"10.0.31.252"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns an error when an allocated ip is outside of its static range", func() {
+			err := cloudconfig.ValidateStaticIPs(cloudConfig, map[string]storage.CloudConfigNetwork{
+				"private": {StaticIPs: []string{"10.0.31.190"}},
+			})
+			Expect(err).To(MatchError(`static ip 10.0.31.190 allocated on network "private" is outside of its static range`))
+		})
+	})
+})
@@ -0,0 +1,87 @@
+package cloudconfig
+
+import (
+	"errors"
+	"fmt"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const DefaultSizingProfile = "default"
+
+// SizingProfileNames are the built-in sizing profiles every IaaS provides.
+var SizingProfileNames = []string{DefaultSizingProfile, "current-generation", "small"}
+
+var baseVMTypes = []string{"default", "sharedcpu", "small", "medium", "large", "extra-large"}
+
+// CompilationVMType is the optional vm_type of a sizing profile that the
+// compilation vms use. Without it they use the default vm_type.
+const CompilationVMType = "compilation"
+
+// SizingProfile maps vm_types to the instance sizes of a single IaaS.
+type SizingProfile struct {
+	VMTypes []VMTypeSize `yaml:"vm_types"`
+}
+
+type VMTypeSize struct {
+	Name         string  `yaml:"name"`
+	InstanceType string  `yaml:"instance_type,omitempty"`
+	MachineType  string  `yaml:"machine_type,omitempty"`
+	DiskType     string  `yaml:"disk_type,omitempty"`
+	DiskSizeGB   int     `yaml:"disk_size_gb,omitempty"`
+	SpotBidPrice float64 `yaml:"spot_bid_price,omitempty"`
+	Preemptible  bool    `yaml:"preemptible,omitempty"`
+}
+
+func ParseSizingProfile(contents string) (SizingProfile, error) {
+	var profile SizingProfile
+	err := yaml.Unmarshal([]byte(contents), &profile)
+	if err != nil {
+		return SizingProfile{}, fmt.Errorf("failed to parse sizing profile: %s", err)
+	}
+
+	seen := map[string]bool{}
+	for _, vmType := range profile.VMTypes {
+		switch {
+		case vmType.Name == "":
+			return SizingProfile{}, errors.New("sizing profile contains a vm_type without a name")
+		case seen[vmType.Name]:
+			return SizingProfile{}, fmt.Errorf("sizing profile contains duplicate vm_type %q", vmType.Name)
+		case vmType.DiskSizeGB < 0:
+			return SizingProfile{}, fmt.Errorf("vm_type %q in sizing profile has a negative disk_size_gb", vmType.Name)
+		case vmType.SpotBidPrice < 0:
+			return SizingProfile{}, fmt.Errorf("vm_type %q in sizing profile has a negative spot_bid_price", vmType.Name)
+		}
+		seen[vmType.Name] = true
+	}
+
+	for _, name := range baseVMTypes {
+		if !seen[name] {
+			return SizingProfile{}, fmt.Errorf("sizing profile is missing vm_type %q", name)
+		}
+	}
+
+	return profile, nil
+}
+
+func IsSizingProfileName(name string) bool {
+	for _, profileName := range SizingProfileNames {
+		if profileName == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsBaseVMType reports whether the base cloud config already declares the
+// vm_type, in which case only its cloud_properties need to be filled in.
+func IsBaseVMType(name string) bool {
+	for _, vmType := range baseVMTypes {
+		if vmType == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,70 @@
+package cloudconfig_test
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/cloudconfig"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SizingProfile", func() {
+	Describe("ParseSizingProfile", func() {
+		const baseTypes = `
+- {name: default, instance_type: m3.medium}
+- {name: sharedcpu, instance_type: t2.small}
+- {name: small, instance_type: m3.large}
+- {name: medium, instance_type: m4.xlarge}
+- {name: large, instance_type: m4.2xlarge}
+`
+
+		It("parses the vm_types of the profile", func() {
+			profile, err := cloudconfig.ParseSizingProfile("vm_types:" + baseTypes + `
+- name: extra-large
+  instance_type: m4.4xlarge
+  disk_type: io1
+  disk_size_gb: 50
+  spot_bid_price: 0.25
+- name: worker
+  machine_type: n1-highcpu-8
+  preemptible: true
+`)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(profile.VMTypes).To(HaveLen(7))
+			Expect(profile.VMTypes[5]).To(Equal(cloudconfig.VMTypeSize{
+				Name:         "extra-large",
+				InstanceType: "m4.4xlarge",
+				DiskType:     "io1",
+				DiskSizeGB:   50,
+				SpotBidPrice: 0.25,
+			}))
+			Expect(profile.VMTypes[6]).To(Equal(cloudconfig.VMTypeSize{
+				Name:        "worker",
+				MachineType: "n1-highcpu-8",
+				Preemptible: true,
+			}))
+		})
+
+		DescribeTable("returns an error when the profile is invalid", func(contents, expectedError string) {
+			_, err := cloudconfig.ParseSizingProfile(contents)
+			Expect(err).To(MatchError(expectedError))
+		},
+			Entry("invalid yaml", "%%%", "failed to parse sizing profile: yaml: could not find expected directive name"),
+			Entry("unnamed vm_type", "vm_types:"+baseTypes+"- {instance_type: m4.4xlarge}", "sizing profile contains a vm_type without a name"),
+			Entry("duplicate vm_type", "vm_types:"+baseTypes+"- {name: large, instance_type: m4.4xlarge}", `sizing profile contains duplicate vm_type "large"`),
+			Entry("negative disk size", "vm_types:"+baseTypes+"- {name: extra-large, disk_size_gb: -1}", `vm_type "extra-large" in sizing profile has a negative disk_size_gb`),
+			Entry("negative spot bid price", "vm_types:"+baseTypes+"- {name: extra-large, spot_bid_price: -0.1}", `vm_type "extra-large" in sizing profile has a negative spot_bid_price`),
+			Entry("missing base vm_type", "vm_types:"+baseTypes, `sizing profile is missing vm_type "extra-large"`),
+		)
+	})
+
+	Describe("IsSizingProfileName", func() {
+		It("reports whether the name is a built-in sizing profile", func() {
+			Expect(cloudconfig.IsSizingProfileName("default")).To(BeTrue())
+			Expect(cloudconfig.IsSizingProfileName("current-generation")).To(BeTrue())
+			Expect(cloudconfig.IsSizingProfileName("small")).To(BeTrue())
+			Expect(cloudconfig.IsSizingProfileName("/some/profile.yml")).To(BeFalse())
+		})
+	})
+})
@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
 	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
 	"github.com/cloudfoundry/bosh-bootloader/bosh"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
@@ -20,6 +21,7 @@ type AWSCreateLBs struct {
 	guidGenerator             guidGenerator
 	stateStore                stateStore
 	stateValidator            stateValidator
+	awsPermissionChecker      awsPermissionChecker
 }
 
 type AWSCreateLBsConfig struct {
@@ -27,17 +29,32 @@ type AWSCreateLBsConfig struct {
 	CertPath     string
 	KeyPath      string
 	ChainPath    string
+	SNICerts     []CertBundle
+	Spec         storage.LBSpec
 	SkipIfExists bool
+	Interactive  bool
+
+	// LBFlavor is the flavor update-lbs migrates the named lb of Spec to.
+	LBFlavor string
+
+	// ACM imports the certificates into ACM rather than uploading them to
+	// IAM, ACMCertificateARN references a certificate already in ACM.
+	ACM               bool
+	ACMCertificateARN string
+
+	// ForgetGeneratedCert drops the generated certificate bbl recorded for
+	// the lb once the lb is created with the certificate of CertPath.
+	ForgetGeneratedCert bool
 }
 
 type certificateManager interface {
-	Create(certificate, privateKey, chain, certificateName string) error
+	Create(certificate, privateKey, chain, certificateName string) (string, error)
 	Describe(certificateName string) (iam.Certificate, error)
 	Delete(certificateName string) error
 }
 
 type boshClientProvider interface {
-	Client(directorAddress, directorUsername, directorPassword string) bosh.Client
+	Client(directorAddress, directorUsername, directorPassword, directorCACert string) bosh.Client
 }
 
 type certificateValidator interface {
@@ -51,7 +68,7 @@ type guidGenerator interface {
 func NewAWSCreateLBs(logger logger, credentialValidator credentialValidator, certificateManager certificateManager,
 	infrastructureManager infrastructureManager, availabilityZoneRetriever availabilityZoneRetriever, boshClientProvider boshClientProvider,
 	cloudConfigManager cloudConfigManager, certificateValidator certificateValidator,
-	guidGenerator guidGenerator, stateStore stateStore) AWSCreateLBs {
+	guidGenerator guidGenerator, stateStore stateStore, awsPermissionChecker awsPermissionChecker) AWSCreateLBs {
 	return AWSCreateLBs{
 		logger:                    logger,
 		certificateManager:        certificateManager,
@@ -63,6 +80,7 @@ func NewAWSCreateLBs(logger logger, credentialValidator credentialValidator, cer
 		certificateValidator:      certificateValidator,
 		guidGenerator:             guidGenerator,
 		stateStore:                stateStore,
+		awsPermissionChecker:      awsPermissionChecker,
 	}
 }
 
@@ -72,18 +90,30 @@ func (c AWSCreateLBs) Execute(config AWSCreateLBsConfig, state storage.State) er
 		return err
 	}
 
-	err = c.certificateValidator.Validate(CreateLBsCommand, config.CertPath, config.KeyPath, config.ChainPath)
+	err = preflightAWS(c.awsPermissionChecker, iam.CreateLBsActions(config.ACM || config.ACMCertificateARN != "" || usesACM(state)), c.logger)
 	if err != nil {
 		return err
 	}
 
+	if config.Spec.Name != "" {
+		return c.createFromSpec(config, state)
+	}
+
+	if err := checkSNISupport(storage.LBSpec{}, "aws", config.SNICerts); err != nil {
+		return err
+	}
+
+	if err := c.validateCertificate(config, state.AWS.Region); err != nil {
+		return err
+	}
+
 	if config.SkipIfExists && lbExists(state.Stack.LBType) {
 		c.logger.Println(fmt.Sprintf("lb type %q exists, skipping...", state.Stack.LBType))
 		return nil
 	}
 
 	if !state.NoDirector {
-		boshClient := c.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername, state.BOSH.DirectorPassword)
+		boshClient := c.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername, state.BOSH.DirectorPassword, state.BOSH.DirectorSSLCA)
 		if err := c.checkBOSHClient(state.Stack.Name, boshClient); err != nil {
 			return err
 		}
@@ -93,14 +123,11 @@ func (c AWSCreateLBs) Execute(config AWSCreateLBsConfig, state storage.State) er
 		return err
 	}
 
-	c.logger.Step("uploading certificate")
-
-	certificateName, err := certificateNameFor(config.LBType, c.guidGenerator, state.EnvID)
-	if err != nil {
-		return err
+	if config.ACMCertificateARN == "" {
+		c.logger.Step("uploading certificate")
 	}
 
-	err = c.certificateManager.Create(config.CertPath, config.KeyPath, config.ChainPath, certificateName)
+	certificateName, err := createCertificate(c.certificateManager, c.guidGenerator, config, config.LBType, state.EnvID)
 	if err != nil {
 		return err
 	}
@@ -108,17 +135,113 @@ func (c AWSCreateLBs) Execute(config AWSCreateLBsConfig, state storage.State) er
 	state.Stack.CertificateName = certificateName
 	state.Stack.LBType = config.LBType
 
-	if err := c.updateStack(state.AWS.Region, certificateName, state.KeyPair.Name, state.Stack.Name, state.Stack.BOSHAZ, config.LBType, state.EnvID); err != nil {
+	if err := c.updateStack(state.AWS.Region, certificateName, state.KeyPair.Name, state.Stack.Name, state.Stack.BOSHAZ, config.LBType, state.LBs, state.EnvID, state.Tags); err != nil {
 		return err
 	}
 
+	if config.ForgetGeneratedCert {
+		state.LBCerts.GeneratedCerts = forgetGeneratedCert(state.LBCerts.GeneratedCerts, config.Spec.Name)
+	}
+
 	err = c.stateStore.Set(state)
 	if err != nil {
 		return err
 	}
 
 	if !state.NoDirector {
-		err = c.cloudConfigManager.Update(state)
+		err = updateCloudConfig(c.cloudConfigManager, state, config.Interactive)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c AWSCreateLBs) createFromSpec(config AWSCreateLBsConfig, state storage.State) error {
+	spec := config.Spec
+
+	if err := checkSNISupport(spec, "aws", config.SNICerts); err != nil {
+		return err
+	}
+
+	requiresCert := lbSpecRequiresCert(spec)
+	if requiresCert {
+		if err := c.validateCertificate(config, state.AWS.Region); err != nil {
+			return err
+		}
+
+		err := validateSNICertificates(c.certificateValidator, CreateLBsCommand, config.SNICerts)
+		if err != nil {
+			return err
+		}
+	} else if config.ACM || config.ACMCertificateARN != "" {
+		return fmt.Errorf("lb %q does not terminate tls, it cannot use an acm certificate", spec.Name)
+	}
+
+	if _, ok := findLBSpec(state.LBs, spec.Name); ok {
+		if config.SkipIfExists {
+			c.logger.Println(fmt.Sprintf("lb %q exists, skipping...", spec.Name))
+			return nil
+		}
+		return fmt.Errorf("bbl already has a load balancer named %q attached, please remove it before attaching a new one", spec.Name)
+	}
+
+	attached := templates.ExpandLBSpecs(state.LBs)
+	for _, elb := range templates.ExpandLBSpecs([]storage.LBSpec{spec}) {
+		if _, ok := findLBSpec(attached, elb.Name); ok {
+			return fmt.Errorf("bbl already has a load balancer named %q attached, please remove it before attaching a new one", elb.Name)
+		}
+	}
+
+	if !state.NoDirector {
+		boshClient := c.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername, state.BOSH.DirectorPassword, state.BOSH.DirectorSSLCA)
+		if err := c.checkBOSHClient(state.Stack.Name, boshClient); err != nil {
+			return err
+		}
+	}
+
+	if requiresCert {
+		if config.ACMCertificateARN == "" {
+			c.logger.Step("uploading certificate")
+		}
+
+		certificateName, err := createCertificate(c.certificateManager, c.guidGenerator, config, spec.Name, state.EnvID)
+		if err != nil {
+			return err
+		}
+
+		certificate, err := c.certificateManager.Describe(certificateName)
+		if err != nil {
+			return err
+		}
+
+		spec.CertificateName = certificateName
+		spec.CertificateARN = certificate.ARN
+
+		spec.SNICertificates, err = uploadSNICertificates(c.certificateManager, c.guidGenerator, spec.Name, state.EnvID, config.SNICerts, config.ACM)
+		if err != nil {
+			return err
+		}
+	}
+
+	state.LBs = append(append([]storage.LBSpec{}, state.LBs...), spec)
+
+	if err := c.updateStack(state.AWS.Region, state.Stack.CertificateName, state.KeyPair.Name, state.Stack.Name, state.Stack.BOSHAZ, state.Stack.LBType, state.LBs, state.EnvID, state.Tags); err != nil {
+		return err
+	}
+
+	if config.ForgetGeneratedCert {
+		state.LBCerts.GeneratedCerts = forgetGeneratedCert(state.LBCerts.GeneratedCerts, config.Spec.Name)
+	}
+
+	err := c.stateStore.Set(state)
+	if err != nil {
+		return err
+	}
+
+	if !state.NoDirector {
+		err = updateCloudConfig(c.cloudConfigManager, state, config.Interactive)
 		if err != nil {
 			return err
 		}
@@ -127,6 +250,16 @@ func (c AWSCreateLBs) Execute(config AWSCreateLBsConfig, state storage.State) er
 	return nil
 }
 
+// validateCertificate validates the certificate files of the lb, or the
+// region of the ACM certificate it references.
+func (c AWSCreateLBs) validateCertificate(config AWSCreateLBsConfig, region string) error {
+	if config.ACMCertificateARN != "" {
+		return validateACMCertificateARN(config.ACMCertificateARN, region)
+	}
+
+	return c.certificateValidator.Validate(CreateLBsCommand, config.CertPath, config.KeyPath, config.ChainPath)
+}
+
 func (AWSCreateLBs) isValidLBType(lbType string) bool {
 	return lbType == "concourse" || lbType == "cf"
 }
@@ -153,7 +286,7 @@ func (c AWSCreateLBs) checkFastFails(newLBType string, currentLBType string) err
 
 func (c AWSCreateLBs) updateStack(
 	awsRegion string, certificateName string, keyPairName string, stackName string, boshAZ,
-	lbType string, envID string,
+	lbType string, lbSpecs []storage.LBSpec, envID string, tags map[string]string,
 ) error {
 
 	availabilityZones, err := c.availabilityZoneRetriever.Retrieve(awsRegion)
@@ -161,9 +294,13 @@ func (c AWSCreateLBs) updateStack(
 		return err
 	}
 
-	certificate, err := c.certificateManager.Describe(certificateName)
+	var certificateARN string
+	if lbExists(lbType) {
+		certificate, _ := c.certificateManager.Describe(certificateName)
+		certificateARN = certificate.ARN
+	}
 
-	_, err = c.infrastructureManager.Update(keyPairName, availabilityZones, stackName, boshAZ, lbType, certificate.ARN, envID)
+	_, err = c.infrastructureManager.Update(keyPairName, availabilityZones, stackName, boshAZ, lbType, certificateARN, lbSpecs, envID, tags)
 	if err != nil {
 		return err
 	}
@@ -29,6 +29,7 @@ var _ = Describe("AWS Create LBs", func() {
 			certificateValidator      *fakes.CertificateValidator
 			guidGenerator             *fakes.GuidGenerator
 			stateStore                *fakes.StateStore
+			awsPermissionChecker      *fakes.AWSPermissionChecker
 			incomingState             storage.State
 		)
 
@@ -44,6 +45,7 @@ var _ = Describe("AWS Create LBs", func() {
 			certificateValidator = &fakes.CertificateValidator{}
 			guidGenerator = &fakes.GuidGenerator{}
 			stateStore = &fakes.StateStore{}
+			awsPermissionChecker = &fakes.AWSPermissionChecker{}
 
 			boshClientProvider.ClientCall.Returns.Client = boshClient
 
@@ -68,13 +70,14 @@ var _ = Describe("AWS Create LBs", func() {
 					DirectorAddress:  "some-director-address",
 					DirectorUsername: "some-director-username",
 					DirectorPassword: "some-director-password",
+					DirectorSSLCA:    "some-director-ca-cert",
 				},
 				EnvID: "some-env-id-timestamp",
 			}
 
 			command = commands.NewAWSCreateLBs(logger, credentialValidator, certificateManager, infrastructureManager,
 				availabilityZoneRetriever, boshClientProvider, cloudConfigManager, certificateValidator, guidGenerator,
-				stateStore)
+				stateStore, awsPermissionChecker)
 		})
 
 		It("returns an error if credential validator fails", func() {
@@ -83,6 +86,34 @@ var _ = Describe("AWS Create LBs", func() {
 			Expect(err).To(MatchError("failed to validate aws credentials"))
 		})
 
+		It("returns an error before uploading the certificate when the credentials are missing permissions", func() {
+			awsPermissionChecker.MissingActionsCall.Returns.Missing = []string{"elasticloadbalancing:CreateLoadBalancer"}
+
+			err := command.Execute(commands.AWSCreateLBsConfig{
+				LBType:   "concourse",
+				CertPath: "temp/some-cert.crt",
+				KeyPath:  "temp/some-key.key",
+			}, incomingState)
+			Expect(err).To(MatchError(ContainSubstring("missing permissions required by bbl and the BOSH AWS CPI:\n  elasticloadbalancing:CreateLoadBalancer\n")))
+
+			Expect(awsPermissionChecker.MissingActionsCall.Receives.Actions).To(Equal(iam.CreateLBsActions(false)))
+			Expect(certificateManager.CreateCall.CallCount).To(Equal(0))
+		})
+
+		It("checks the acm actions with --aws-acm", func() {
+			awsPermissionChecker.MissingActionsCall.Returns.Missing = []string{"acm:ImportCertificate"}
+
+			err := command.Execute(commands.AWSCreateLBsConfig{
+				LBType:   "concourse",
+				CertPath: "temp/some-cert.crt",
+				KeyPath:  "temp/some-key.key",
+				ACM:      true,
+			}, incomingState)
+			Expect(err).To(MatchError(ContainSubstring("acm:ImportCertificate")))
+
+			Expect(awsPermissionChecker.MissingActionsCall.Receives.Actions).To(Equal(iam.CreateLBsActions(true)))
+		})
+
 		It("uploads a cert and key", func() {
 			err := command.Execute(commands.AWSCreateLBsConfig{
 				LBType:   "concourse",
@@ -171,6 +202,19 @@ var _ = Describe("AWS Create LBs", func() {
 
 				Expect(cloudConfigManager.UpdateCall.Receives.State.Stack.LBType).To(Equal("concourse"))
 			})
+
+			It("asks for confirmation before updating the cloud config when interactive", func() {
+				err := command.Execute(commands.AWSCreateLBsConfig{
+					LBType:      "concourse",
+					CertPath:    "temp/some-cert.crt",
+					KeyPath:     "temp/some-key.key",
+					Interactive: true,
+				}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(cloudConfigManager.UpdateWithConfirmationCall.Receives.State.Stack.LBType).To(Equal("concourse"))
+				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(0))
+			})
 		})
 
 		Context("when the bbl environment does not have a BOSH director", func() {
@@ -313,6 +357,7 @@ var _ = Describe("AWS Create LBs", func() {
 				Expect(boshClientProvider.ClientCall.Receives.DirectorAddress).To(Equal("some-director-address"))
 				Expect(boshClientProvider.ClientCall.Receives.DirectorUsername).To(Equal("some-director-username"))
 				Expect(boshClientProvider.ClientCall.Receives.DirectorPassword).To(Equal("some-director-password"))
+				Expect(boshClientProvider.ClientCall.Receives.DirectorCACert).To(Equal("some-director-ca-cert"))
 
 				Expect(boshClient.InfoCall.CallCount).To(Equal(1))
 
@@ -375,6 +420,268 @@ var _ = Describe("AWS Create LBs", func() {
 			})
 		})
 
+		Context("when an lb spec is provided", func() {
+			var spec storage.LBSpec
+
+			BeforeEach(func() {
+				spec = storage.LBSpec{
+					Name:        "vault",
+					Ports:       []storage.LBPort{{Port: 8200, InstancePort: 8200, Protocol: "tcp"}},
+					HealthCheck: storage.LBHealthCheck{Protocol: "tcp", Port: 8200},
+				}
+
+				availabilityZoneRetriever.RetrieveCall.Returns.AZs = []string{"a", "b", "c"}
+				incomingState.Stack.LBType = "concourse"
+				incomingState.Stack.CertificateName = "some-concourse-certificate"
+				incomingState.LBs = []storage.LBSpec{{Name: "credhub-uaa"}}
+				certificateManager.DescribeCall.Returns.Certificate = iam.Certificate{
+					ARN: "some-certificate-arn",
+				}
+			})
+
+			It("adds the lb next to the existing lbs without uploading a certificate", func() {
+				err := command.Execute(commands.AWSCreateLBsConfig{Spec: spec}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certificateValidator.ValidateCall.CallCount).To(Equal(0))
+				Expect(certificateManager.CreateCall.CallCount).To(Equal(0))
+
+				Expect(infrastructureManager.UpdateCall.Receives.LBType).To(Equal("concourse"))
+				Expect(infrastructureManager.UpdateCall.Receives.LBCertificateARN).To(Equal("some-certificate-arn"))
+				Expect(infrastructureManager.UpdateCall.Receives.LBSpecs).To(Equal([]storage.LBSpec{{Name: "credhub-uaa"}, spec}))
+				Expect(certificateManager.DescribeCall.Receives.CertificateName).To(Equal("some-concourse-certificate"))
+
+				Expect(stateStore.SetCall.Receives[0].State.LBs).To(Equal([]storage.LBSpec{{Name: "credhub-uaa"}, spec}))
+				Expect(stateStore.SetCall.Receives[0].State.Stack.LBType).To(Equal("concourse"))
+				Expect(cloudConfigManager.UpdateCall.Receives.State.LBs).To(HaveLen(2))
+			})
+
+			It("uploads a certificate when a port terminates tls", func() {
+				spec.Ports = append(spec.Ports, storage.LBPort{Port: 443, InstancePort: 8200, Protocol: "ssl"})
+				incomingState.Stack.LBType = ""
+				incomingState.Stack.CertificateName = ""
+
+				err := command.Execute(commands.AWSCreateLBsConfig{
+					Spec:     spec,
+					CertPath: "temp/some-cert.crt",
+					KeyPath:  "temp/some-key.key",
+				}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certificateValidator.ValidateCall.Receives.CertificatePath).To(Equal("temp/some-cert.crt"))
+				Expect(certificateManager.CreateCall.Receives.CertificateName).To(Equal("vault-elb-cert-abcd-some-env-id-timestamp"))
+				Expect(certificateManager.DescribeCall.CallCount).To(Equal(1))
+
+				spec.CertificateName = "vault-elb-cert-abcd-some-env-id-timestamp"
+				spec.CertificateARN = "some-certificate-arn"
+				Expect(infrastructureManager.UpdateCall.Receives.LBSpecs).To(ContainElement(spec))
+				Expect(infrastructureManager.UpdateCall.Receives.LBCertificateARN).To(Equal(""))
+				Expect(stateStore.SetCall.Receives[0].State.LBs).To(ContainElement(spec))
+			})
+
+			It("uploads a certificate for a named lb of a type next to the lb type", func() {
+				named := storage.LBSpec{Name: "foundation", Type: "cf"}
+
+				err := command.Execute(commands.AWSCreateLBsConfig{
+					LBType:   "cf",
+					Spec:     named,
+					CertPath: "temp/some-cert.crt",
+					KeyPath:  "temp/some-key.key",
+				}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certificateValidator.ValidateCall.CallCount).To(Equal(1))
+				Expect(certificateManager.CreateCall.Receives.CertificateName).To(Equal("foundation-elb-cert-abcd-some-env-id-timestamp"))
+
+				named.CertificateName = "foundation-elb-cert-abcd-some-env-id-timestamp"
+				named.CertificateARN = "some-certificate-arn"
+				Expect(infrastructureManager.UpdateCall.Receives.LBType).To(Equal("concourse"))
+				Expect(infrastructureManager.UpdateCall.Receives.LBSpecs).To(Equal([]storage.LBSpec{{Name: "credhub-uaa"}, named}))
+				Expect(stateStore.SetCall.Receives[0].State.Stack.LBType).To(Equal("concourse"))
+				Expect(stateStore.SetCall.Receives[0].State.LBs).To(Equal([]storage.LBSpec{{Name: "credhub-uaa"}, named}))
+			})
+
+			It("uploads sni certificates for an alb or nlb", func() {
+				spec.Flavor = "alb"
+				spec.Ports = []storage.LBPort{{Port: 443, InstancePort: 8200, Protocol: "https"}}
+
+				err := command.Execute(commands.AWSCreateLBsConfig{
+					Spec:     spec,
+					CertPath: "temp/some-cert.crt",
+					KeyPath:  "temp/some-key.key",
+					SNICerts: []commands.CertBundle{{CertPath: "temp/other-cert.crt", KeyPath: "temp/other-key.key"}},
+				}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certificateValidator.ValidateCall.CallCount).To(Equal(2))
+				Expect(certificateManager.CreateCall.Certificates).To(Equal([]string{"temp/some-cert.crt", "temp/other-cert.crt"}))
+				Expect(certificateManager.CreateCall.CertificateNames).To(Equal([]string{
+					"vault-elb-cert-abcd-some-env-id-timestamp",
+					"vault-sni-elb-cert-abcd-some-env-id-timestamp",
+				}))
+
+				Expect(stateStore.SetCall.Receives[0].State.LBs[1].SNICertificates).To(Equal([]storage.LBCertificate{{
+					Name: "vault-sni-elb-cert-abcd-some-env-id-timestamp",
+					ARN:  "some-certificate-arn",
+				}}))
+			})
+
+			Context("when acm is used", func() {
+				const certificateARN = "arn:aws:acm:some-region:123456789012:certificate/some-id"
+
+				BeforeEach(func() {
+					spec.Flavor = "alb"
+					spec.Ports = []storage.LBPort{{Port: 443, InstancePort: 8200, Protocol: "https"}}
+				})
+
+				It("imports the certificates into acm with --aws-acm and keeps the names the import returns", func() {
+					certificateManager.CreateCall.Returns.CertificateName = "acm:" + certificateARN
+
+					err := command.Execute(commands.AWSCreateLBsConfig{
+						Spec:     spec,
+						CertPath: "temp/some-cert.crt",
+						KeyPath:  "temp/some-key.key",
+						SNICerts: []commands.CertBundle{{CertPath: "temp/other-cert.crt", KeyPath: "temp/other-key.key"}},
+						ACM:      true,
+					}, incomingState)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(certificateManager.CreateCall.CertificateNames).To(Equal([]string{
+						"acm:vault-elb-cert-abcd-some-env-id-timestamp",
+						"acm:vault-sni-elb-cert-abcd-some-env-id-timestamp",
+					}))
+					Expect(stateStore.SetCall.Receives[0].State.LBs[1].CertificateName).To(Equal("acm:" + certificateARN))
+					Expect(stateStore.SetCall.Receives[0].State.LBs[1].SNICertificates[0].Name).To(Equal("acm:" + certificateARN))
+				})
+
+				It("references the acm certificate of --aws-acm-cert-arn without uploading it", func() {
+					certificateManager.DescribeCall.Returns.Certificate = iam.Certificate{ARN: certificateARN}
+
+					err := command.Execute(commands.AWSCreateLBsConfig{
+						Spec:              spec,
+						ACMCertificateARN: certificateARN,
+					}, incomingState)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(certificateValidator.ValidateCall.CallCount).To(Equal(0))
+					Expect(certificateManager.CreateCall.CallCount).To(Equal(0))
+					Expect(logger.StepCall.Messages).NotTo(ContainElement("uploading certificate"))
+
+					spec.CertificateName = certificateARN
+					spec.CertificateARN = certificateARN
+					Expect(stateStore.SetCall.Receives[0].State.LBs).To(ContainElement(spec))
+				})
+
+				It("returns an error when the acm certificate is in another region", func() {
+					err := command.Execute(commands.AWSCreateLBsConfig{
+						Spec:              spec,
+						ACMCertificateARN: "arn:aws:acm:other-region:123456789012:certificate/some-id",
+					}, incomingState)
+					Expect(err).To(MatchError(`acm certificate "arn:aws:acm:other-region:123456789012:certificate/some-id" is in region "other-region", the lbs of this environment are in "some-region"`))
+					Expect(infrastructureManager.UpdateCall.CallCount).To(Equal(0))
+				})
+
+				It("returns an error when the lb does not terminate tls", func() {
+					spec.Flavor = ""
+					spec.Ports = []storage.LBPort{{Port: 8200, InstancePort: 8200, Protocol: "tcp"}}
+
+					err := command.Execute(commands.AWSCreateLBsConfig{
+						Spec:              spec,
+						ACMCertificateARN: certificateARN,
+					}, incomingState)
+					Expect(err).To(MatchError(`lb "vault" does not terminate tls, it cannot use an acm certificate`))
+				})
+			})
+
+			It("returns an error when sni certificates are provided for a classic elb", func() {
+				spec.Ports = []storage.LBPort{{Port: 443, InstancePort: 8200, Protocol: "https"}}
+
+				err := command.Execute(commands.AWSCreateLBsConfig{
+					Spec:     spec,
+					SNICerts: []commands.CertBundle{{CertPath: "temp/other-cert.crt", KeyPath: "temp/other-key.key"}},
+				}, incomingState)
+				Expect(err).To(MatchError(`lb "vault" is a classic elb which serves a single certificate, migrate it with --aws-lb-flavor alb or nlb to serve several`))
+				Expect(certificateManager.CreateCall.CallCount).To(Equal(0))
+			})
+
+			It("does not modify the lbs of the incoming state", func() {
+				err := command.Execute(commands.AWSCreateLBsConfig{Spec: spec}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(incomingState.LBs).To(Equal([]storage.LBSpec{{Name: "credhub-uaa"}}))
+			})
+
+			It("forgets the generated certificate of the lb once it is created", func() {
+				incomingState.LBCerts.GeneratedCerts = []storage.LBGeneratedCert{{LB: "vault"}, {LB: "credhub-uaa"}}
+
+				err := command.Execute(commands.AWSCreateLBsConfig{Spec: spec, ForgetGeneratedCert: true}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stateStore.SetCall.Receives[0].State.LBCerts.GeneratedCerts).To(Equal([]storage.LBGeneratedCert{{LB: "credhub-uaa"}}))
+			})
+
+			It("keeps the generated certificate of the lb when the stack update fails", func() {
+				incomingState.LBCerts.GeneratedCerts = []storage.LBGeneratedCert{{LB: "vault"}}
+				infrastructureManager.UpdateCall.Returns.Error = errors.New("failed to update stack")
+
+				err := command.Execute(commands.AWSCreateLBsConfig{Spec: spec, ForgetGeneratedCert: true}, incomingState)
+				Expect(err).To(MatchError("failed to update stack"))
+
+				Expect(stateStore.SetCall.CallCount).To(Equal(0))
+			})
+
+			It("skips creating the lb when it exists and --skip-if-exists is provided", func() {
+				incomingState.LBs = []storage.LBSpec{spec}
+
+				err := command.Execute(commands.AWSCreateLBsConfig{Spec: spec, SkipIfExists: true}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.PrintlnCall.Receives.Message).To(Equal(`lb "vault" exists, skipping...`))
+				Expect(infrastructureManager.UpdateCall.CallCount).To(Equal(0))
+			})
+
+			Context("failure cases", func() {
+				It("returns an error when an lb with the same name exists", func() {
+					incomingState.LBs = []storage.LBSpec{spec}
+
+					err := command.Execute(commands.AWSCreateLBsConfig{Spec: spec}, incomingState)
+					Expect(err).To(MatchError(`bbl already has a load balancer named "vault" attached, please remove it before attaching a new one`))
+				})
+
+				It("returns an error when the elbs of a named lb clash with an attached lb", func() {
+					incomingState.LBs = []storage.LBSpec{{Name: "foundation-router"}}
+
+					err := command.Execute(commands.AWSCreateLBsConfig{Spec: storage.LBSpec{Name: "foundation", Type: "cf"}}, incomingState)
+					Expect(err).To(MatchError(`bbl already has a load balancer named "foundation-router" attached, please remove it before attaching a new one`))
+					Expect(certificateManager.CreateCall.CallCount).To(Equal(0))
+				})
+
+				It("returns an error when the certificate is invalid", func() {
+					spec.Ports[0].Protocol = "https"
+					certificateValidator.ValidateCall.Returns.Error = errors.New("invalid certificate")
+
+					err := command.Execute(commands.AWSCreateLBsConfig{Spec: spec}, incomingState)
+					Expect(err).To(MatchError("invalid certificate"))
+				})
+
+				It("returns an error when the certificate cannot be described", func() {
+					spec.Ports[0].Protocol = "https"
+					certificateManager.DescribeCall.Returns.Error = errors.New("failed to describe")
+
+					err := command.Execute(commands.AWSCreateLBsConfig{Spec: spec}, incomingState)
+					Expect(err).To(MatchError("failed to describe"))
+				})
+
+				It("returns an error when the stack cannot be updated", func() {
+					infrastructureManager.UpdateCall.Returns.Error = errors.New("failed to update stack")
+
+					err := command.Execute(commands.AWSCreateLBsConfig{Spec: spec}, incomingState)
+					Expect(err).To(MatchError("failed to update stack"))
+					Expect(stateStore.SetCall.CallCount).To(Equal(0))
+				})
+			})
+		})
+
 		Context("failure cases", func() {
 			DescribeTable("returns an error when an lb already exists",
 				func(newLbType, oldLbType string) {
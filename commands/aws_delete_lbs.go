@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"fmt"
+
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
@@ -20,6 +22,7 @@ type AWSDeleteLBs struct {
 }
 
 type deleteLBsConfig struct {
+	name          string
 	skipIfMissing bool
 }
 
@@ -40,16 +43,26 @@ func NewAWSDeleteLBs(credentialValidator credentialValidator, availabilityZoneRe
 	}
 }
 
-func (c AWSDeleteLBs) Execute(state storage.State) error {
+func (c AWSDeleteLBs) Execute(name string, state storage.State) error {
 	err := c.credentialValidator.Validate()
 	if err != nil {
 		return err
 	}
 
-	if err := checkBBLAndLB(state, c.boshClientProvider, c.infrastructureManager); err != nil {
+	if err := checkBBL(state, c.boshClientProvider, c.infrastructureManager); err != nil {
 		return err
 	}
 
+	if name != "" {
+		return c.deleteNamed(name, state)
+	}
+
+	hasLBType := lbExists(state.Stack.LBType)
+	migrating, unnamedMigration := unnamedLBMigration(state)
+	if !hasLBType && len(state.LBs) == 0 {
+		return LBNotFound
+	}
+
 	azs, err := c.availabilityZoneRetriever.Retrieve(state.AWS.Region)
 	if err != nil {
 		return err
@@ -62,6 +75,9 @@ func (c AWSDeleteLBs) Execute(state storage.State) error {
 
 	state.Stack.LBType = "none"
 
+	lbSpecs := state.LBs
+	state.LBs = nil
+
 	if !state.NoDirector {
 		err = c.cloudConfigManager.Update(state)
 		if err != nil {
@@ -69,7 +85,7 @@ func (c AWSDeleteLBs) Execute(state storage.State) error {
 		}
 	}
 
-	_, err = c.infrastructureManager.Update(state.KeyPair.Name, azs, state.Stack.Name, state.Stack.BOSHAZ, "", "", state.EnvID)
+	_, err = c.infrastructureManager.Update(state.KeyPair.Name, azs, state.Stack.Name, state.Stack.BOSHAZ, "", "", nil, state.EnvID, state.Tags)
 	if err != nil {
 		return err
 	}
@@ -79,18 +95,101 @@ func (c AWSDeleteLBs) Execute(state storage.State) error {
 		return err
 	}
 
-	c.logger.Step("deleting certificate")
-	err = c.certificateManager.Delete(state.Stack.CertificateName)
+	if hasLBType {
+		c.logger.Step("deleting certificate")
+		err = c.certificateManager.Delete(state.Stack.CertificateName)
+		if err != nil {
+			return err
+		}
+
+		state.Stack.CertificateName = ""
+	}
+
+	for _, spec := range lbSpecs {
+		// The lb the unnamed lb migrates into shares its certificate,
+		// which has been deleted along with the unnamed lb.
+		if unnamedMigration && spec.Name == migrating.Name {
+			spec.CertificateName = ""
+		}
+
+		err = c.deleteCertificates(spec)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.stateStore.Set(state)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c AWSDeleteLBs) deleteNamed(name string, state storage.State) error {
+	spec, ok := findLBSpec(state.LBs, name)
+	if !ok {
+		return fmt.Errorf("no load balancer named %q has been found for this bbl environment", name)
+	}
+
+	azs, err := c.availabilityZoneRetriever.Retrieve(state.AWS.Region)
 	if err != nil {
 		return err
 	}
 
-	state.Stack.CertificateName = ""
+	var certificateARN string
+	if lbExists(state.Stack.LBType) {
+		certificate, err := c.certificateManager.Describe(state.Stack.CertificateName)
+		if err != nil {
+			return err
+		}
+		certificateARN = certificate.ARN
+	}
+
+	// The certificate stays with the unnamed lb when the lb it migrates
+	// into is deleted.
+	if migrating, ok := unnamedLBMigration(state); ok && migrating.Name == name {
+		spec.CertificateName = ""
+	}
+
+	state.LBs = removeLBSpec(state.LBs, name)
+
+	if !state.NoDirector {
+		err = c.cloudConfigManager.Update(state)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = c.infrastructureManager.Update(state.KeyPair.Name, azs, state.Stack.Name, state.Stack.BOSHAZ, state.Stack.LBType, certificateARN, state.LBs, state.EnvID, state.Tags)
+	if err != nil {
+		return err
+	}
 
 	err = c.stateStore.Set(state)
 	if err != nil {
 		return err
 	}
 
+	return c.deleteCertificates(spec)
+}
+
+func (c AWSDeleteLBs) deleteCertificates(spec storage.LBSpec) error {
+	if spec.CertificateName != "" {
+		c.logger.Step("deleting certificate")
+		err := c.certificateManager.Delete(spec.CertificateName)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, certificate := range spec.SNICertificates {
+		c.logger.Step("deleting sni certificate")
+		err := c.certificateManager.Delete(certificate.Name)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
+	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
 	"github.com/cloudfoundry/bosh-bootloader/commands"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
@@ -77,7 +78,7 @@ var _ = Describe("Delete LBs", func() {
 					Name: "some-stack-name",
 				}
 
-				err := command.Execute(incomingState)
+				err := command.Execute("", incomingState)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(boshClientProvider.ClientCall.Receives.DirectorAddress).To(Equal("some-director-address"))
@@ -108,7 +109,7 @@ var _ = Describe("Delete LBs", func() {
 					},
 					EnvID: "some-env-id",
 				}
-				err := command.Execute(state)
+				err := command.Execute("", state)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(0))
@@ -131,7 +132,7 @@ var _ = Describe("Delete LBs", func() {
 					},
 					EnvID: "some-env-id",
 				}
-				err := command.Execute(state)
+				err := command.Execute("", state)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(boshClientProvider.ClientCall.CallCount).To(Equal(0))
@@ -140,7 +141,7 @@ var _ = Describe("Delete LBs", func() {
 
 		It("delete lbs from cloudformation and deletes certificate", func() {
 			availabilityZoneRetriever.RetrieveCall.Returns.AZs = []string{"a", "b", "c"}
-			err := command.Execute(incomingState)
+			err := command.Execute("", incomingState)
 
 			Expect(err).NotTo(HaveOccurred())
 
@@ -162,7 +163,7 @@ var _ = Describe("Delete LBs", func() {
 		})
 
 		It("checks if the bosh director exists", func() {
-			err := command.Execute(incomingState)
+			err := command.Execute("", incomingState)
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(boshClientProvider.ClientCall.Receives.DirectorAddress).To(Equal("some-director-address"))
@@ -175,20 +176,20 @@ var _ = Describe("Delete LBs", func() {
 		Context("if the user hasn't bbl'd up yet", func() {
 			It("returns an error if the stack does not exist", func() {
 				infrastructureManager.ExistsCall.Returns.Exists = false
-				err := command.Execute(storage.State{})
+				err := command.Execute("", storage.State{})
 				Expect(err).To(MatchError(commands.BBLNotFound))
 			})
 
 			It("returns an error if the bosh director does not exist", func() {
 				boshClient.InfoCall.Returns.Error = errors.New("director not found")
 
-				err := command.Execute(incomingState)
+				err := command.Execute("", incomingState)
 				Expect(err).To(MatchError(commands.BBLNotFound))
 			})
 		})
 
 		It("returns an error if there is no lb", func() {
-			err := command.Execute(storage.State{
+			err := command.Execute("", storage.State{
 				Stack: storage.Stack{
 					LBType: "none",
 				},
@@ -196,10 +197,112 @@ var _ = Describe("Delete LBs", func() {
 			Expect(err).To(MatchError(commands.LBNotFound))
 		})
 
+		Context("when lb specs are attached", func() {
+			BeforeEach(func() {
+				incomingState.Stack.LBType = "none"
+				incomingState.Stack.CertificateName = ""
+				incomingState.LBs = []storage.LBSpec{
+					{Name: "vault"},
+					{Name: "credhub-uaa", CertificateName: "some-credhub-uaa-certificate"},
+				}
+			})
+
+			It("deletes every lb spec and its certificate", func() {
+				err := command.Execute("", incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(cloudConfigManager.UpdateCall.Receives.State.LBs).To(BeEmpty())
+				Expect(infrastructureManager.UpdateCall.Receives.LBSpecs).To(BeEmpty())
+
+				Expect(certificateManager.DeleteCall.CallCount).To(Equal(1))
+				Expect(certificateManager.DeleteCall.Receives.CertificateName).To(Equal("some-credhub-uaa-certificate"))
+
+				Expect(stateStore.SetCall.CallCount).To(Equal(2))
+				Expect(stateStore.SetCall.Receives[0].State.LBs).To(BeEmpty())
+			})
+
+			It("deletes only the named lb and its certificate", func() {
+				incomingState.Stack.LBType = "concourse"
+				incomingState.Stack.CertificateName = "some-certificate"
+				certificateManager.DescribeCall.Returns.Certificate = iam.Certificate{ARN: "some-certificate-arn"}
+
+				err := command.Execute("credhub-uaa", incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(cloudConfigManager.UpdateCall.Receives.State.LBs).To(Equal([]storage.LBSpec{{Name: "vault"}}))
+				Expect(infrastructureManager.UpdateCall.Receives.LBType).To(Equal("concourse"))
+				Expect(infrastructureManager.UpdateCall.Receives.LBCertificateARN).To(Equal("some-certificate-arn"))
+				Expect(infrastructureManager.UpdateCall.Receives.LBSpecs).To(Equal([]storage.LBSpec{{Name: "vault"}}))
+
+				Expect(certificateManager.DeleteCall.CallCount).To(Equal(1))
+				Expect(certificateManager.DeleteCall.Receives.CertificateName).To(Equal("some-credhub-uaa-certificate"))
+
+				Expect(stateStore.SetCall.CallCount).To(Equal(1))
+				Expect(stateStore.SetCall.Receives[0].State.Stack.LBType).To(Equal("concourse"))
+				Expect(stateStore.SetCall.Receives[0].State.LBs).To(Equal([]storage.LBSpec{{Name: "vault"}}))
+			})
+
+			It("deletes the sni certificates of a named lb", func() {
+				incomingState.LBs[1].SNICertificates = []storage.LBCertificate{{Name: "some-sni-certificate"}}
+
+				err := command.Execute("credhub-uaa", incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certificateManager.DeleteCall.CertificateNames).To(Equal([]string{
+					"some-credhub-uaa-certificate",
+					"some-sni-certificate",
+				}))
+			})
+
+			It("returns an error when the named lb does not exist", func() {
+				err := command.Execute("other", incomingState)
+				Expect(err).To(MatchError(`no load balancer named "other" has been found for this bbl environment`))
+				Expect(infrastructureManager.UpdateCall.CallCount).To(Equal(0))
+			})
+
+			It("deletes the lb type and the lb specs together", func() {
+				incomingState.Stack.LBType = "concourse"
+				incomingState.Stack.CertificateName = "some-certificate"
+
+				err := command.Execute("", incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(infrastructureManager.UpdateCall.Receives.LBType).To(Equal(""))
+				Expect(certificateManager.DeleteCall.CallCount).To(Equal(2))
+				Expect(stateStore.SetCall.Receives[1].State.Stack.CertificateName).To(Equal(""))
+			})
+
+			Context("when the unnamed lb migrates into a named lb", func() {
+				BeforeEach(func() {
+					incomingState.Stack.LBType = "concourse"
+					incomingState.Stack.CertificateName = "some-certificate"
+					incomingState.LBs = append(incomingState.LBs, storage.LBSpec{Name: "ci", Type: "concourse", Flavor: "nlb", CertificateName: "some-certificate"})
+				})
+
+				It("deletes the certificate they share once", func() {
+					err := command.Execute("", incomingState)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(certificateManager.DeleteCall.CertificateNames).To(Equal([]string{
+						"some-certificate",
+						"some-credhub-uaa-certificate",
+					}))
+				})
+
+				It("keeps the certificate of the unnamed lb when the named lb is deleted", func() {
+					err := command.Execute("ci", incomingState)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(infrastructureManager.UpdateCall.Receives.LBType).To(Equal("concourse"))
+					Expect(certificateManager.DeleteCall.CallCount).To(Equal(0))
+				})
+			})
+		})
+
 		Context("state management", func() {
 			It("saves state with no lb type before deleting certificate", func() {
 				certificateManager.DeleteCall.Returns.Error = errors.New("failed to delete")
-				err := command.Execute(storage.State{
+				err := command.Execute("", storage.State{
 					Stack: storage.Stack{
 						Name:            "some-stack",
 						LBType:          "cf",
@@ -219,7 +322,7 @@ var _ = Describe("Delete LBs", func() {
 			})
 
 			It("saves state with no lb type nor certificate", func() {
-				err := command.Execute(storage.State{
+				err := command.Execute("", storage.State{
 					Stack: storage.Stack{
 						Name:            "some-stack",
 						LBType:          "cf",
@@ -242,48 +345,48 @@ var _ = Describe("Delete LBs", func() {
 		Context("failure cases", func() {
 			It("returns an error when aws credential validator fails to validate", func() {
 				credentialValidator.ValidateCall.Returns.Error = errors.New("validate failed")
-				err := command.Execute(incomingState)
+				err := command.Execute("", incomingState)
 				Expect(err).To(MatchError("validate failed"))
 			})
 
 			It("return an error when availability zone retriever fails to retrieve", func() {
 				availabilityZoneRetriever.RetrieveCall.Returns.Error = errors.New("retrieve failed")
-				err := command.Execute(incomingState)
+				err := command.Execute("", incomingState)
 				Expect(err).To(MatchError("retrieve failed"))
 			})
 
 			It("return an error when infrastructure manager fails to describe", func() {
 				infrastructureManager.DescribeCall.Returns.Error = errors.New("describe failed")
-				err := command.Execute(incomingState)
+				err := command.Execute("", incomingState)
 				Expect(err).To(MatchError("describe failed"))
 			})
 
 			It("return an error when cloud config manager fails to update", func() {
 				cloudConfigManager.UpdateCall.Returns.Error = errors.New("update failed")
-				err := command.Execute(incomingState)
+				err := command.Execute("", incomingState)
 				Expect(err).To(MatchError("update failed"))
 			})
 
 			It("return an error when infrastructure manager fails to update", func() {
 				infrastructureManager.UpdateCall.Returns.Error = errors.New("update failed")
-				err := command.Execute(incomingState)
+				err := command.Execute("", incomingState)
 				Expect(err).To(MatchError("update failed"))
 			})
 
 			It("return an error when certificate manager fails to delete", func() {
 				certificateManager.DeleteCall.Returns.Error = errors.New("delete failed")
-				err := command.Execute(incomingState)
+				err := command.Execute("", incomingState)
 				Expect(err).To(MatchError("delete failed"))
 			})
 
 			It("returns an error when the state fails to save lb type", func() {
 				stateStore.SetCall.Returns = []fakes.SetCallReturn{{errors.New("failed to save state")}}
-				err := command.Execute(incomingState)
+				err := command.Execute("", incomingState)
 				Expect(err).To(MatchError("failed to save state"))
 			})
 			It("returns an error when the state fails to save certificate deletion", func() {
 				stateStore.SetCall.Returns = []fakes.SetCallReturn{{}, {errors.New("failed to save state")}}
-				err := command.Execute(incomingState)
+				err := command.Execute("", incomingState)
 				Expect(err).To(MatchError("failed to save state"))
 			})
 		})
@@ -24,8 +24,8 @@ type keyPairSynchronizer interface {
 }
 
 type infrastructureManager interface {
-	Create(keyPairName string, azs []string, stackName, boshAZ, lbType, lbCertificateARN, envID string) (cloudformation.Stack, error)
-	Update(keyPairName string, azs []string, stackName, boshAZ, lbType, lbCertificateARN, envID string) (cloudformation.Stack, error)
+	Create(keyPairName string, azs []string, stackName, boshAZ, lbType, lbCertificateARN string, lbSpecs []storage.LBSpec, envID string, tags map[string]string) (cloudformation.Stack, error)
+	Update(keyPairName string, azs []string, stackName, boshAZ, lbType, lbCertificateARN string, lbSpecs []storage.LBSpec, envID string, tags map[string]string) (cloudformation.Stack, error)
 	Exists(stackName string) (bool, error)
 	Delete(stackName string) error
 	Describe(stackName string) (cloudformation.Stack, error)
@@ -59,7 +59,10 @@ type configProvider interface {
 
 type cloudConfigManager interface {
 	Update(state storage.State) error
+	UpdateWithConfirmation(state storage.State) error
 	Generate(state storage.State) (string, error)
+	Diff(state storage.State) (string, error)
+	Delete(state storage.State) error
 }
 
 type AWSUp struct {
@@ -74,17 +77,36 @@ type AWSUp struct {
 	configProvider            configProvider
 	envIDManager              envIDManager
 	terraformManager          terraformManager
+	sshKeyPairGenerator       sshKeyPairGenerator
+	awsPermissionChecker      awsPermissionChecker
+	logger                    logger
 }
 
 type AWSUpConfig struct {
-	AccessKeyID     string
-	SecretAccessKey string
-	Region          string
-	OpsFilePath     string
-	BOSHAZ          string
-	Name            string
-	NoDirector      bool
-	Terraform       bool
+	AccessKeyID       string
+	SecretAccessKey   string
+	Region            string
+	OpsFilePath       string
+	SSHPrivateKeyPath string
+	SSHPublicKeyPath  string
+	SSHKeyType        string
+	SSHKeyBits        int
+	BOSHAZ            string
+	Name              string
+	NamePrefix        string
+	NameTeam          string
+	NamePurpose       string
+	Tags              []string
+	NoDirector        bool
+	Terraform         bool
+	DualStack         bool
+
+	CloudConfigOpsFilePaths []string
+	SizingProfile           string
+	ReservedIPCounts        []string
+	StaticIPCounts          []string
+	RuntimeConfigPath       string
+	Interactive             bool
 }
 
 func NewAWSUp(
@@ -93,7 +115,8 @@ func NewAWSUp(
 	availabilityZoneRetriever availabilityZoneRetriever,
 	certificateDescriber certificateDescriber, cloudConfigManager cloudConfigManager,
 	stateStore stateStore,
-	configProvider configProvider, envIDManager envIDManager, terraformManager terraformManager) AWSUp {
+	configProvider configProvider, envIDManager envIDManager, terraformManager terraformManager,
+	sshKeyPairGenerator sshKeyPairGenerator, awsPermissionChecker awsPermissionChecker, logger logger) AWSUp {
 
 	return AWSUp{
 		credentialValidator:       credentialValidator,
@@ -107,6 +130,9 @@ func NewAWSUp(
 		configProvider:            configProvider,
 		envIDManager:              envIDManager,
 		terraformManager:          terraformManager,
+		sshKeyPairGenerator:       sshKeyPairGenerator,
+		awsPermissionChecker:      awsPermissionChecker,
+		logger:                    logger,
 	}
 }
 
@@ -134,6 +160,11 @@ func (u AWSUp) Execute(config AWSUpConfig, state storage.State) error {
 		return u.awsMissingCredentials(config)
 	}
 
+	err := preflightAWS(u.awsPermissionChecker, iam.UpActions(usesACM(state)), u.logger)
+	if err != nil {
+		return err
+	}
+
 	if config.NoDirector {
 		if !state.BOSH.IsEmpty() {
 			return errors.New(`Director already exists, you must re-create your environment to use "--no-director"`)
@@ -142,12 +173,59 @@ func (u AWSUp) Execute(config AWSUpConfig, state storage.State) error {
 		state.NoDirector = true
 	}
 
-	err := u.checkForFastFails(state, config)
+	if config.DualStack {
+		state.AWS.DualStack = true
+	}
+
+	err = u.checkForFastFails(state, config)
+	if err != nil {
+		return err
+	}
+
+	state.KeyPair, err = loadSSHKeyPair(state.KeyPair, config.SSHPrivateKeyPath, config.SSHPublicKeyPath, config.SSHKeyType, config.SSHKeyBits)
+	if err != nil {
+		return err
+	}
+
+	state.Tags, err = loadTags(state.Tags, config.Tags, "aws")
+	if err != nil {
+		return err
+	}
+
+	state.CloudConfig, err = loadCloudConfigOpsFiles(state.CloudConfig, config.CloudConfigOpsFilePaths)
+	if err != nil {
+		return err
+	}
+
+	state.CloudConfig, err = loadSizingProfile(state.CloudConfig, config.SizingProfile)
+	if err != nil {
+		return err
+	}
+
+	state.CloudConfig, err = loadNetworkIPCounts(state.CloudConfig, config.ReservedIPCounts, config.StaticIPCounts)
 	if err != nil {
 		return err
 	}
 
-	envID, err := u.envIDManager.Sync(state, config.Name)
+	state.RuntimeConfig, err = loadRuntimeConfig(state.RuntimeConfig, config.RuntimeConfigPath)
+	if err != nil {
+		return err
+	}
+
+	if state.KeyPair.PrivateKey == "" && (config.SSHKeyType != "" || config.SSHKeyBits != 0) {
+		state.KeyPair.PrivateKey, state.KeyPair.PublicKey, err = u.sshKeyPairGenerator.Generate(config.SSHKeyType, config.SSHKeyBits)
+		if err != nil {
+			return err
+		}
+	}
+
+	envID, err := u.envIDManager.Sync(state, helpers.EnvIDNaming{
+		Name:      config.Name,
+		Prefix:    config.NamePrefix,
+		Team:      config.NameTeam,
+		Purpose:   config.NamePurpose,
+		Terraform: config.Terraform,
+	})
 	if err != nil {
 		return err
 	}
@@ -211,7 +289,7 @@ func (u AWSUp) Execute(config AWSUpConfig, state storage.State) error {
 				return err
 			}
 		}
-		_, err = u.infrastructureManager.Create(state.KeyPair.Name, availabilityZones, state.Stack.Name, state.Stack.BOSHAZ, state.Stack.LBType, certificateARN, state.EnvID)
+		_, err = u.infrastructureManager.Create(state.KeyPair.Name, availabilityZones, state.Stack.Name, state.Stack.BOSHAZ, state.Stack.LBType, certificateARN, state.LBs, state.EnvID, state.Tags)
 		if err != nil {
 			return err
 		}
@@ -246,7 +324,7 @@ func (u AWSUp) Execute(config AWSUpConfig, state storage.State) error {
 			return err
 		}
 
-		err = u.cloudConfigManager.Update(state)
+		err = updateCloudConfig(u.cloudConfigManager, state, config.Interactive)
 		if err != nil {
 			return err
 		}
@@ -272,6 +350,10 @@ func (u AWSUp) checkForFastFails(state storage.State, config AWSUpConfig) error
 		return errors.New("The --aws-bosh-az cannot be changed for existing environments.")
 	}
 
+	if config.DualStack && !config.Terraform {
+		return errors.New("--aws-dual-stack is only supported with --terraform")
+	}
+
 	return nil
 }
 
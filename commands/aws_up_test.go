@@ -12,9 +12,11 @@ import (
 	"github.com/cloudfoundry/bosh-bootloader/bosh"
 	"github.com/cloudfoundry/bosh-bootloader/commands"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/helpers"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
@@ -33,6 +35,9 @@ var _ = Describe("AWSUp", func() {
 			stateStore                *fakes.StateStore
 			clientProvider            *fakes.ClientProvider
 			envIDManager              *fakes.EnvIDManager
+			sshKeyPairGenerator       *fakes.SSHKeyPairGenerator
+			awsPermissionChecker      *fakes.AWSPermissionChecker
+			logger                    *fakes.Logger
 		)
 
 		BeforeEach(func() {
@@ -91,10 +96,16 @@ var _ = Describe("AWSUp", func() {
 			envIDManager = &fakes.EnvIDManager{}
 			envIDManager.SyncCall.Returns.EnvID = "bbl-lake-time-stamp"
 
+			sshKeyPairGenerator = &fakes.SSHKeyPairGenerator{}
+
+			awsPermissionChecker = &fakes.AWSPermissionChecker{}
+			logger = &fakes.Logger{}
+
 			command = commands.NewAWSUp(
 				credentialValidator, infrastructureManager, keyPairSynchronizer, boshManager,
 				availabilityZoneRetriever, certificateDescriber, cloudConfigManager,
 				stateStore, clientProvider, envIDManager, terraformManager,
+				sshKeyPairGenerator, awsPermissionChecker, logger,
 			)
 		})
 
@@ -104,6 +115,40 @@ var _ = Describe("AWSUp", func() {
 			Expect(err).To(MatchError("failed to validate aws credentials"))
 		})
 
+		Context("when checking the aws permissions", func() {
+			It("checks the required actions before creating anything", func() {
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(awsPermissionChecker.MissingActionsCall.CallCount).To(Equal(1))
+				Expect(awsPermissionChecker.MissingActionsCall.Receives.Actions).To(Equal(iam.UpActions(false)))
+			})
+
+			It("returns an error listing the missing actions", func() {
+				awsPermissionChecker.MissingActionsCall.Returns.Missing = []string{"ec2:CreateVpc", "ec2:RunInstances"}
+
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{})
+				Expect(err).To(MatchError(`The AWS credentials provided are missing permissions required by bbl and the BOSH AWS CPI:
+  ec2:CreateVpc
+  ec2:RunInstances
+Please refer to the bbl README:
+https://github.com/cloudfoundry/bosh-bootloader#configure-aws.`))
+
+				Expect(stateStore.SetCall.CallCount).To(Equal(0))
+				Expect(infrastructureManager.CreateCall.CallCount).To(Equal(0))
+			})
+
+			It("goes on with a warning when the policies cannot be simulated", func() {
+				awsPermissionChecker.MissingActionsCall.Returns.Error = errors.New("access denied")
+
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.PrintlnCall.Messages).To(ContainElement("skipping the aws permission check: access denied"))
+				Expect(infrastructureManager.CreateCall.CallCount).To(Equal(1))
+			})
+		})
+
 		It("retrieves a client with the provided credentials", func() {
 			err := command.Execute(commands.AWSUpConfig{
 				AccessKeyID:     "new-aws-access-key-id",
@@ -145,7 +190,29 @@ var _ = Describe("AWSUp", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(envIDManager.SyncCall.CallCount).To(Equal(1))
-				Expect(envIDManager.SyncCall.Receives.Name).To(Equal("some-other-env-id"))
+				Expect(envIDManager.SyncCall.Receives.Naming.Name).To(Equal("some-other-env-id"))
+			})
+		})
+
+		Context("when name generation options are passed in", func() {
+			It("passes them to the env id manager along with terraform", func() {
+				err := command.Execute(commands.AWSUpConfig{
+					AccessKeyID:     "new-aws-access-key-id",
+					SecretAccessKey: "new-aws-secret-access-key",
+					Region:          "new-aws-region",
+					NamePrefix:      "acme",
+					NameTeam:        "payments",
+					NamePurpose:     "staging",
+					Terraform:       true,
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(envIDManager.SyncCall.Receives.Naming).To(Equal(helpers.EnvIDNaming{
+					Prefix:    "acme",
+					Team:      "payments",
+					Purpose:   "staging",
+					Terraform: true,
+				}))
 			})
 		})
 
@@ -275,6 +342,17 @@ var _ = Describe("AWSUp", func() {
 				}))
 			})
 
+			It("persists dual stack in the state before applying terraform", func() {
+				err := command.Execute(commands.AWSUpConfig{
+					Terraform: true,
+					DualStack: true,
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformManager.ApplyCall.Receives.BBLState.AWS.DualStack).To(BeTrue())
+				Expect(stateStore.SetCall.Receives[0].State.AWS.DualStack).To(BeTrue())
+			})
+
 			Context("failure cases", func() {
 				Context("when the terraform manager fails with terraformManagerError", func() {
 					var (
@@ -494,6 +572,16 @@ var _ = Describe("AWSUp", func() {
 					Expect(err).To(MatchError("The --aws-bosh-az cannot be changed for existing environments."))
 				})
 			})
+
+			Context("when dual stack is requested without terraform", func() {
+				It("returns an error message", func() {
+					err := command.Execute(commands.AWSUpConfig{
+						DualStack: true,
+					}, storage.State{})
+					Expect(err).To(MatchError("--aws-dual-stack is only supported with --terraform"))
+					Expect(stateStore.SetCall.CallCount).To(Equal(0))
+				})
+			})
 		})
 
 		Context("when there is an lb", func() {
@@ -519,6 +607,17 @@ var _ = Describe("AWSUp", func() {
 		})
 
 		Describe("cloud config", func() {
+			It("asks for confirmation before updating the cloud config when interactive", func() {
+				err := command.Execute(commands.AWSUpConfig{
+					Interactive: true,
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(cloudConfigManager.UpdateWithConfirmationCall.CallCount).To(Equal(1))
+				Expect(cloudConfigManager.UpdateWithConfirmationCall.Receives.State.EnvID).To(Equal("bbl-lake-time-stamp"))
+				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(0))
+			})
+
 			It("updates the bosh director with a cloud config provided an up-to-date state", func() {
 				err := command.Execute(commands.AWSUpConfig{}, storage.State{})
 				Expect(err).NotTo(HaveOccurred())
@@ -733,6 +832,397 @@ var _ = Describe("AWSUp", func() {
 						}))
 					})
 				})
+				Context("when an ssh keypair is provided", func() {
+					var (
+						privateKey     string
+						publicKey      string
+						privateKeyPath string
+						publicKeyPath  string
+					)
+
+					BeforeEach(func() {
+						privateKey, publicKey = generateSSHKeyPair()
+
+						privateKeyFile, err := ioutil.TempFile("", "ssh-private-key")
+						Expect(err).NotTo(HaveOccurred())
+						privateKeyPath = privateKeyFile.Name()
+						Expect(ioutil.WriteFile(privateKeyPath, []byte(privateKey), os.ModePerm)).To(Succeed())
+
+						publicKeyFile, err := ioutil.TempFile("", "ssh-public-key")
+						Expect(err).NotTo(HaveOccurred())
+						publicKeyPath = publicKeyFile.Name()
+						Expect(ioutil.WriteFile(publicKeyPath, []byte(publicKey+" someone@example.com\n"), os.ModePerm)).To(Succeed())
+					})
+
+					It("syncs the provided keypair", func() {
+						envIDManager.SyncCall.Returns.EnvID = "bbl-lake-time:stamp"
+
+						err := command.Execute(commands.AWSUpConfig{
+							SSHPrivateKeyPath: privateKeyPath,
+							SSHPublicKeyPath:  publicKeyPath,
+						}, storage.State{})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(keyPairSynchronizer.SyncCall.Receives.KeyPair).To(Equal(ec2.KeyPair{
+							Name:       "keypair-bbl-lake-time:stamp",
+							PrivateKey: privateKey,
+							PublicKey:  publicKey,
+						}))
+						Expect(sshKeyPairGenerator.GenerateCall.CallCount).To(Equal(0))
+					})
+
+					It("derives the public key when only the private key is provided", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							SSHPrivateKeyPath: privateKeyPath,
+						}, storage.State{})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(keyPairSynchronizer.SyncCall.Receives.KeyPair.PublicKey).To(Equal(publicKey))
+					})
+
+					It("returns an error when the keypair differs from the existing keypair", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							SSHPrivateKeyPath: privateKeyPath,
+						}, storage.State{
+							KeyPair: storage.KeyPair{
+								Name:       "some-existing-keypair",
+								PrivateKey: "some-other-private-key",
+							},
+						})
+						Expect(err).To(MatchError("The SSH keypair cannot be changed for an existing environment."))
+
+						Expect(keyPairSynchronizer.SyncCall.Receives.KeyPair).To(Equal(ec2.KeyPair{}))
+					})
+
+					It("returns an error when the public key does not match the private key", func() {
+						_, otherPublicKey := generateSSHKeyPair()
+						Expect(ioutil.WriteFile(publicKeyPath, []byte(otherPublicKey), os.ModePerm)).To(Succeed())
+
+						err := command.Execute(commands.AWSUpConfig{
+							SSHPrivateKeyPath: privateKeyPath,
+							SSHPublicKeyPath:  publicKeyPath,
+						}, storage.State{})
+						Expect(err).To(MatchError("ssh public key does not match ssh private key"))
+					})
+
+					It("returns an error when the private key cannot be read", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							SSHPrivateKeyPath: "/some/missing/key",
+						}, storage.State{})
+						Expect(err).To(MatchError("error reading ssh private key: open /some/missing/key: no such file or directory"))
+					})
+
+					It("returns an error when only a public key is provided", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							SSHPublicKeyPath: publicKeyPath,
+						}, storage.State{})
+						Expect(err).To(MatchError("--ssh-public-key requires --ssh-private-key"))
+					})
+
+					It("returns an error when a key size is also provided", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							SSHPrivateKeyPath: privateKeyPath,
+							SSHKeyBits:        4096,
+						}, storage.State{})
+						Expect(err).To(MatchError("--ssh-key-bits cannot be used with --ssh-private-key"))
+					})
+
+					It("returns an error when a key type is also provided", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							SSHPrivateKeyPath: privateKeyPath,
+							SSHKeyType:        "ed25519",
+						}, storage.State{})
+						Expect(err).To(MatchError("--ssh-key-type cannot be used with --ssh-private-key"))
+					})
+				})
+
+				Context("when cloud config ops files are provided", func() {
+					var opsFilePath string
+
+					BeforeEach(func() {
+						opsFile, err := ioutil.TempFile("", "cloud-config-ops-file")
+						Expect(err).NotTo(HaveOccurred())
+						opsFilePath = opsFile.Name()
+						Expect(ioutil.WriteFile(opsFilePath, []byte("some-cloud-config-ops"), os.ModePerm)).To(Succeed())
+					})
+
+					It("persists them in the state and applies them to the cloud config", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							CloudConfigOpsFilePaths: []string{opsFilePath},
+						}, storage.State{})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(stateStore.SetCall.Receives[0].State.CloudConfig.OpsFiles).To(Equal([]string{"some-cloud-config-ops"}))
+						Expect(cloudConfigManager.UpdateCall.Receives.State.CloudConfig.OpsFiles).To(Equal([]string{"some-cloud-config-ops"}))
+					})
+
+					It("keeps the persisted ops files when none are provided", func() {
+						err := command.Execute(commands.AWSUpConfig{}, storage.State{
+							CloudConfig: storage.CloudConfig{
+								OpsFiles: []string{"some-persisted-ops"},
+							},
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(cloudConfigManager.UpdateCall.Receives.State.CloudConfig.OpsFiles).To(Equal([]string{"some-persisted-ops"}))
+					})
+
+					It("returns an error when an ops file cannot be read", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							CloudConfigOpsFilePaths: []string{"/some/missing/ops-file"},
+						}, storage.State{})
+						Expect(err).To(MatchError("error reading cloud-config-ops-file contents: open /some/missing/ops-file: no such file or directory"))
+					})
+				})
+
+				Context("when a sizing profile is provided", func() {
+					It("persists a built-in profile by name", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							SizingProfile: "small",
+						}, storage.State{
+							CloudConfig: storage.CloudConfig{
+								CustomSizingProfile: "some-old-profile",
+							},
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(cloudConfigManager.UpdateCall.Receives.State.CloudConfig.SizingProfile).To(Equal("small"))
+						Expect(cloudConfigManager.UpdateCall.Receives.State.CloudConfig.CustomSizingProfile).To(BeEmpty())
+					})
+
+					It("persists the contents of a custom profile", func() {
+						profile := `vm_types:
+- {name: default, instance_type: m3.medium}
+- {name: sharedcpu, instance_type: t2.small}
+- {name: small, instance_type: m3.large}
+- {name: medium, instance_type: m4.xlarge}
+- {name: large, instance_type: m4.2xlarge}
+- {name: extra-large, instance_type: m4.4xlarge}
+`
+						profileFile, err := ioutil.TempFile("", "sizing-profile")
+						Expect(err).NotTo(HaveOccurred())
+						Expect(ioutil.WriteFile(profileFile.Name(), []byte(profile), os.ModePerm)).To(Succeed())
+
+						err = command.Execute(commands.AWSUpConfig{
+							SizingProfile: profileFile.Name(),
+						}, storage.State{
+							CloudConfig: storage.CloudConfig{
+								SizingProfile: "small",
+							},
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(stateStore.SetCall.Receives[0].State.CloudConfig.CustomSizingProfile).To(Equal(profile))
+						Expect(cloudConfigManager.UpdateCall.Receives.State.CloudConfig.SizingProfile).To(BeEmpty())
+						Expect(cloudConfigManager.UpdateCall.Receives.State.CloudConfig.CustomSizingProfile).To(Equal(profile))
+					})
+
+					It("returns an error when the custom profile cannot be read", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							SizingProfile: "/some/missing/profile",
+						}, storage.State{})
+						Expect(err).To(MatchError("error reading sizing-profile contents: open /some/missing/profile: no such file or directory"))
+					})
+
+					It("returns an error when the custom profile is invalid", func() {
+						profileFile, err := ioutil.TempFile("", "sizing-profile")
+						Expect(err).NotTo(HaveOccurred())
+						Expect(ioutil.WriteFile(profileFile.Name(), []byte("vm_types: []"), os.ModePerm)).To(Succeed())
+
+						err = command.Execute(commands.AWSUpConfig{
+							SizingProfile: profileFile.Name(),
+						}, storage.State{})
+						Expect(err).To(MatchError(`sizing profile is missing vm_type "default"`))
+					})
+				})
+
+				Context("when reserved and static ip counts are provided", func() {
+					It("persists them per network, keeping the allocated static ips", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							ReservedIPCounts: []string{"private=8"},
+							StaticIPCounts:   []string{"private=128", "default=16"},
+						}, storage.State{
+							CloudConfig: storage.CloudConfig{
+								Networks: map[string]storage.CloudConfigNetwork{
+									"private": {
+										StaticIPCount: 65,
+										StaticIPs:     []string{"10.0.31.190"},
+									},
+								},
+							},
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(stateStore.SetCall.Receives[0].State.CloudConfig.Networks).To(Equal(map[string]storage.CloudConfigNetwork{
+							"private": {
+								ReservedIPCount: 8,
+								StaticIPCount:   128,
+								StaticIPs:       []string{"10.0.31.190"},
+							},
+							"default": {
+								StaticIPCount: 16,
+							},
+						}))
+					})
+
+					DescribeTable("returns an error when a count is invalid",
+						func(reservedIPCounts, staticIPCounts []string, expectedError string) {
+							err := command.Execute(commands.AWSUpConfig{
+								ReservedIPCounts: reservedIPCounts,
+								StaticIPCounts:   staticIPCounts,
+							}, storage.State{})
+							Expect(err).To(MatchError(expectedError))
+						},
+						Entry("missing count", []string{"private"}, nil, `invalid --reserved-ip-count "private", expected NETWORK=COUNT`),
+						Entry("unknown network", nil, []string{"public=2"}, `invalid --static-ip-count "public=2", network must be one of: private, default`),
+						Entry("non-numeric count", nil, []string{"private=many"}, `invalid --static-ip-count "private=many", count must be a positive number`),
+						Entry("zero count", []string{"default=0"}, nil, `invalid --reserved-ip-count "default=0", count must be a positive number`),
+					)
+				})
+
+				Context("when tags are provided", func() {
+					It("persists them and tags the stack with them", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							Tags: []string{"owner=platform", "cost-center=1234"},
+						}, storage.State{
+							Tags: map[string]string{"team": "payments"},
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						expectedTags := map[string]string{"owner": "platform", "cost-center": "1234"}
+						Expect(stateStore.SetCall.Receives[0].State.Tags).To(Equal(expectedTags))
+						Expect(infrastructureManager.CreateCall.Receives.Tags).To(Equal(expectedTags))
+						Expect(cloudConfigManager.UpdateCall.Receives.State.Tags).To(Equal(expectedTags))
+					})
+
+					It("keeps the tags of the state when none are given", func() {
+						err := command.Execute(commands.AWSUpConfig{}, storage.State{
+							Tags: map[string]string{"team": "payments"},
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(infrastructureManager.CreateCall.Receives.Tags).To(Equal(map[string]string{"team": "payments"}))
+					})
+
+					DescribeTable("returns an error when a tag is invalid",
+						func(tags []string, expectedError string) {
+							err := command.Execute(commands.AWSUpConfig{
+								Tags: tags,
+							}, storage.State{})
+							Expect(err).To(MatchError(expectedError))
+						},
+						Entry("missing value", []string{"owner"}, `invalid --tag "owner", expected KEY=VALUE`),
+						Entry("missing key", []string{"=platform"}, `invalid --tag "=platform", expected KEY=VALUE`),
+						Entry("duplicate key", []string{"owner=a", "owner=b"}, `invalid --tag "owner=b", "owner" is given twice`),
+						Entry("name tag", []string{"Name=some-name"}, `invalid --tag "Name=some-name", the "Name" tag is managed by bbl`),
+						Entry("env id tag", []string{"bbl-env-id=some-env"}, `invalid --tag "bbl-env-id=some-env", the "bbl-env-id" tag is managed by bbl`),
+						Entry("aws prefix", []string{"aws:owner=platform"}, `invalid --tag "aws:owner=platform", keys starting with "aws:" are reserved by aws`),
+					)
+				})
+
+				Context("when a runtime config is provided", func() {
+					It("persists its contents and keeps it in sync on the director", func() {
+						runtimeConfigFile, err := ioutil.TempFile("", "runtime-config")
+						Expect(err).NotTo(HaveOccurred())
+						Expect(ioutil.WriteFile(runtimeConfigFile.Name(), []byte("some-runtime-config"), os.ModePerm)).To(Succeed())
+
+						err = command.Execute(commands.AWSUpConfig{
+							RuntimeConfigPath: runtimeConfigFile.Name(),
+						}, storage.State{})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(stateStore.SetCall.Receives[0].State.RuntimeConfig.Contents).To(Equal("some-runtime-config"))
+						Expect(cloudConfigManager.UpdateCall.Receives.State.RuntimeConfig.Contents).To(Equal("some-runtime-config"))
+					})
+
+					It("returns an error when the runtime config cannot be read", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							RuntimeConfigPath: "/some/missing/runtime-config",
+						}, storage.State{})
+						Expect(err).To(MatchError("error reading runtime-config contents: open /some/missing/runtime-config: no such file or directory"))
+					})
+				})
+
+				Context("when an ssh key size is provided", func() {
+					BeforeEach(func() {
+						sshKeyPairGenerator.GenerateCall.Returns.PrivateKey = "some-generated-private-key"
+						sshKeyPairGenerator.GenerateCall.Returns.PublicKey = "some-generated-public-key"
+						envIDManager.SyncCall.Returns.EnvID = "bbl-lake-time:stamp"
+					})
+
+					It("generates a keypair of that size and syncs it", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							SSHKeyBits: 4096,
+						}, storage.State{})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(sshKeyPairGenerator.GenerateCall.Receives.KeyType).To(Equal(""))
+						Expect(sshKeyPairGenerator.GenerateCall.Receives.Bits).To(Equal(4096))
+						Expect(keyPairSynchronizer.SyncCall.Receives.KeyPair).To(Equal(ec2.KeyPair{
+							Name:       "keypair-bbl-lake-time:stamp",
+							PrivateKey: "some-generated-private-key",
+							PublicKey:  "some-generated-public-key",
+						}))
+					})
+
+					It("generates an ed25519 keypair when the key type is ed25519", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							SSHKeyType: "ed25519",
+						}, storage.State{})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(sshKeyPairGenerator.GenerateCall.Receives.KeyType).To(Equal("ed25519"))
+						Expect(sshKeyPairGenerator.GenerateCall.Receives.Bits).To(Equal(0))
+						Expect(keyPairSynchronizer.SyncCall.Receives.KeyPair.PrivateKey).To(Equal("some-generated-private-key"))
+					})
+
+					DescribeTable("returns an error when the keypair already exists",
+						func(config commands.AWSUpConfig) {
+							err := command.Execute(config, storage.State{
+								KeyPair: storage.KeyPair{
+									Name:       "some-existing-keypair",
+									PrivateKey: "some-private-key",
+								},
+							})
+							Expect(err).To(MatchError("--ssh-key-bits and --ssh-key-type cannot be used for an existing environment, the SSH keypair cannot be changed."))
+
+							Expect(sshKeyPairGenerator.GenerateCall.CallCount).To(Equal(0))
+						},
+						Entry("with a key size", commands.AWSUpConfig{SSHKeyBits: 4096}),
+						Entry("with a key type", commands.AWSUpConfig{SSHKeyType: "rsa"}),
+					)
+
+					It("returns an error when the key type is unknown", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							SSHKeyType: "dsa",
+						}, storage.State{})
+						Expect(err).To(MatchError(`--ssh-key-type must be "rsa" or "ed25519"`))
+					})
+
+					It("returns an error when a key size is given for an ed25519 key", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							SSHKeyType: "ed25519",
+							SSHKeyBits: 4096,
+						}, storage.State{})
+						Expect(err).To(MatchError("--ssh-key-bits cannot be used with --ssh-key-type ed25519"))
+					})
+
+					It("returns an error when the key size is too small", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							SSHKeyBits: 1024,
+						}, storage.State{})
+						Expect(err).To(MatchError("--ssh-key-bits must be at least 2048"))
+					})
+
+					It("returns an error when the keypair cannot be generated", func() {
+						sshKeyPairGenerator.GenerateCall.Returns.Error = errors.New("failed to generate keypair")
+
+						err := command.Execute(commands.AWSUpConfig{
+							SSHKeyBits: 4096,
+						}, storage.State{})
+						Expect(err).To(MatchError("failed to generate keypair"))
+					})
+				})
 			})
 
 			Context("cloudformation", func() {
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"strings"
 	"time"
@@ -16,13 +17,14 @@ type AWSUpdateLBs struct {
 	credentialValidator       credentialValidator
 	boshClientProvider        boshClientProvider
 	logger                    logger
+	cloudConfigManager        cloudConfigManager
 	guidGenerator             guidGenerator
 	stateStore                stateStore
 }
 
 func NewAWSUpdateLBs(credentialValidator credentialValidator, certificateManager certificateManager,
 	availabilityZoneRetriever availabilityZoneRetriever, infrastructureManager infrastructureManager, boshClientProvider boshClientProvider,
-	logger logger, guidGenerator guidGenerator, stateStore stateStore) AWSUpdateLBs {
+	logger logger, cloudConfigManager cloudConfigManager, guidGenerator guidGenerator, stateStore stateStore) AWSUpdateLBs {
 
 	return AWSUpdateLBs{
 		credentialValidator:       credentialValidator,
@@ -31,6 +33,7 @@ func NewAWSUpdateLBs(credentialValidator credentialValidator, certificateManager
 		infrastructureManager:     infrastructureManager,
 		boshClientProvider:        boshClientProvider,
 		logger:                    logger,
+		cloudConfigManager:        cloudConfigManager,
 		guidGenerator:             guidGenerator,
 		stateStore:                stateStore,
 	}
@@ -42,33 +45,40 @@ func (c AWSUpdateLBs) Execute(config AWSCreateLBsConfig, state storage.State) er
 		return err
 	}
 
+	if config.LBFlavor != "" {
+		return c.migrateFlavor(config, state)
+	}
+
+	if config.Spec.Name != "" {
+		return c.updateSpec(config, state)
+	}
+
 	if err := checkBBLAndLB(state, c.boshClientProvider, c.infrastructureManager); err != nil {
 		return err
 	}
 
-	if match, err := c.checkCertificateAndChain(config.CertPath, config.ChainPath, state.Stack.CertificateName); err != nil {
+	if match, err := c.checkCertificate(config, state.Stack.CertificateName, state.AWS.Region); err != nil {
 		return err
 	} else if match {
 		c.logger.Println("no updates are to be performed")
 		return nil
 	}
 
-	c.logger.Step("uploading new certificate")
-
-	certificateName, err := certificateNameFor(state.Stack.LBType, c.guidGenerator, state.EnvID)
-	if err != nil {
-		return err
+	if config.ACMCertificateARN == "" {
+		c.logger.Step("uploading new certificate")
 	}
 
-	err = c.certificateManager.Create(config.CertPath, config.KeyPath, config.ChainPath, certificateName)
+	certificateName, err := createCertificate(c.certificateManager, c.guidGenerator, config, state.Stack.LBType, state.EnvID)
 	if err != nil {
 		return err
 	}
 
 	// Temporary fix for IAM propagation. Terraform should have retry logic for this, so we should remove it once we start using terraform on AWS.
-	time.Sleep(9 * time.Second)
+	if config.ACMCertificateARN == "" {
+		time.Sleep(9 * time.Second)
+	}
 
-	if err := c.updateStack(certificateName, state.KeyPair.Name, state.Stack.Name, state.Stack.BOSHAZ, state.Stack.LBType, state.AWS.Region, state.EnvID); err != nil {
+	if err := c.updateStack(certificateName, state.KeyPair.Name, state.Stack.Name, state.Stack.BOSHAZ, state.Stack.LBType, state.LBs, state.AWS.Region, state.EnvID, state.Tags); err != nil {
 		return err
 	}
 
@@ -88,6 +98,193 @@ func (c AWSUpdateLBs) Execute(config AWSCreateLBsConfig, state storage.State) er
 	return nil
 }
 
+func (c AWSUpdateLBs) updateSpec(config AWSCreateLBsConfig, state storage.State) error {
+	spec := config.Spec
+
+	if err := checkBBL(state, c.boshClientProvider, c.infrastructureManager); err != nil {
+		return err
+	}
+
+	// The certificates given replace all certificates of the lb, so the
+	// lb is only left alone without any SNI certificates before or after.
+	if match, err := c.checkCertificate(config, spec.CertificateName, state.AWS.Region); err != nil {
+		return err
+	} else if match && len(config.SNICerts) == 0 && len(spec.SNICertificates) == 0 {
+		c.logger.Println("no updates are to be performed")
+		return nil
+	}
+
+	if config.ACMCertificateARN == "" {
+		c.logger.Step("uploading new certificate")
+	}
+
+	certificateName, err := createCertificate(c.certificateManager, c.guidGenerator, config, spec.Name, state.EnvID)
+	if err != nil {
+		return err
+	}
+
+	sniCertificates, err := uploadSNICertificates(c.certificateManager, c.guidGenerator, spec.Name, state.EnvID, config.SNICerts, config.ACM)
+	if err != nil {
+		return err
+	}
+
+	// Temporary fix for IAM propagation, see Execute.
+	if config.ACMCertificateARN == "" || len(sniCertificates) > 0 {
+		time.Sleep(9 * time.Second)
+	}
+
+	certificate, err := c.certificateManager.Describe(certificateName)
+	if err != nil {
+		return err
+	}
+
+	oldCertificateName := spec.CertificateName
+	oldSNICertificates := spec.SNICertificates
+	spec.CertificateName = certificateName
+	spec.CertificateARN = certificate.ARN
+	spec.SNICertificates = sniCertificates
+	state.LBs = replaceLBSpec(state.LBs, spec)
+
+	if err := c.updateStack(state.Stack.CertificateName, state.KeyPair.Name, state.Stack.Name, state.Stack.BOSHAZ, state.Stack.LBType, state.LBs, state.AWS.Region, state.EnvID, state.Tags); err != nil {
+		return err
+	}
+
+	c.logger.Step("deleting old certificate")
+	err = c.certificateManager.Delete(oldCertificateName)
+	if err != nil {
+		return err
+	}
+
+	for _, oldCertificate := range oldSNICertificates {
+		err = c.certificateManager.Delete(oldCertificate.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.stateStore.Set(state)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// migrateFlavor moves a named lb to another flavor in two runs. The first
+// builds the load balancer of the new flavor alongside the previous one, so
+// that vms register with both on their next deploy, the second detaches the
+// previous load balancer.
+//
+// The unnamed lb, a classic elb, migrates into a new named lb of the same
+// type which serves its certificate. The first run builds the named lb
+// alongside the unnamed one, the second removes the unnamed lb and hands
+// its certificate over to the named lb.
+func (c AWSUpdateLBs) migrateFlavor(config AWSCreateLBsConfig, state storage.State) error {
+	spec := config.Spec
+
+	if err := checkBBL(state, c.boshClientProvider, c.infrastructureManager); err != nil {
+		return err
+	}
+
+	currentFlavor := spec.Flavor
+	if currentFlavor == "" {
+		currentFlavor = "elb"
+	}
+
+	_, exists := findLBSpec(state.LBs, spec.Name)
+	migrating, unnamedMigration := unnamedLBMigration(state)
+	unnamedMigration = unnamedMigration && migrating.Name == spec.Name
+
+	switch {
+	case !exists:
+		certificate, err := c.certificateManager.Describe(state.Stack.CertificateName)
+		if err != nil {
+			return err
+		}
+
+		spec.Flavor = config.LBFlavor
+		spec.CertificateName = state.Stack.CertificateName
+		spec.CertificateARN = certificate.ARN
+		if err := validateLBFlavor(spec); err != nil {
+			return err
+		}
+
+		c.logger.Step("attaching the %s of lb %q alongside the unnamed %s lb", config.LBFlavor, spec.Name, state.Stack.LBType)
+		state.LBs = append(state.LBs, spec)
+	case unnamedMigration && config.LBFlavor != currentFlavor:
+		return fmt.Errorf("the unnamed lb is migrating into the %s of lb %q, finish the migration with --aws-lb-flavor %s first", currentFlavor, spec.Name, currentFlavor)
+	case unnamedMigration:
+		c.logger.Step("detaching the unnamed %s lb, lb %q takes over its certificate", state.Stack.LBType, spec.Name)
+		for i, cert := range state.LBCerts.GeneratedCerts {
+			if cert.LB == "" {
+				state.LBCerts.GeneratedCerts[i].LB = spec.Name
+			}
+		}
+		state.Stack.LBType = "none"
+		state.Stack.CertificateName = ""
+	case config.LBFlavor == currentFlavor && spec.PreviousFlavor == "":
+		c.logger.Println("no updates are to be performed")
+		return nil
+	case config.LBFlavor == currentFlavor:
+		c.logger.Step("detaching the %s of lb %q", spec.PreviousFlavor, spec.Name)
+		spec.PreviousFlavor = ""
+	case spec.PreviousFlavor != "":
+		return fmt.Errorf("lb %q is still migrating from %s to %s, finish the migration with --aws-lb-flavor %s first", spec.Name, spec.PreviousFlavor, currentFlavor, currentFlavor)
+	default:
+		spec.Flavor = config.LBFlavor
+		spec.PreviousFlavor = currentFlavor
+		if err := validateLBFlavor(spec); err != nil {
+			return err
+		}
+		c.logger.Step("attaching the %s of lb %q alongside its %s", config.LBFlavor, spec.Name, currentFlavor)
+	}
+
+	state.LBs = replaceLBSpec(state.LBs, spec)
+
+	if err := c.updateStack(state.Stack.CertificateName, state.KeyPair.Name, state.Stack.Name, state.Stack.BOSHAZ, state.Stack.LBType, state.LBs, state.AWS.Region, state.EnvID, state.Tags); err != nil {
+		return err
+	}
+
+	err := c.stateStore.Set(state)
+	if err != nil {
+		return err
+	}
+
+	if !state.NoDirector {
+		err = updateCloudConfig(c.cloudConfigManager, state, config.Interactive)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !exists {
+		c.logger.Println(fmt.Sprintf("add the %s vm extensions of lb %q alongside those of the unnamed lb, redeploy and point the domain at the %s, then run `bbl update-lbs --name %s --aws-lb-flavor %s` to detach the unnamed lb",
+			strings.Join(lbVMExtensions(spec), " and "), spec.Name, spec.Flavor, spec.Name, spec.Flavor))
+		return nil
+	}
+
+	if spec.PreviousFlavor != "" {
+		c.logger.Println(fmt.Sprintf("redeploy so that the vms of lb %q register with its %s, then run `bbl update-lbs --name %s --aws-lb-flavor %s` to detach the %s",
+			spec.Name, spec.Flavor, spec.Name, spec.Flavor, spec.PreviousFlavor))
+	}
+
+	return nil
+}
+
+// checkCertificate tells whether the lb already uses the certificate given
+// by its files or by the arn of an ACM certificate.
+func (c AWSUpdateLBs) checkCertificate(config AWSCreateLBsConfig, oldCertName, region string) (bool, error) {
+	if config.ACMCertificateARN != "" {
+		if err := validateACMCertificateARN(config.ACMCertificateARN, region); err != nil {
+			return false, err
+		}
+
+		return config.ACMCertificateARN == oldCertName, nil
+	}
+
+	return c.checkCertificateAndChain(config.CertPath, config.ChainPath, oldCertName)
+}
+
 func (c AWSUpdateLBs) checkCertificateAndChain(certPath string, chainPath string, oldCertName string) (bool, error) {
 	localCertificate, err := ioutil.ReadFile(certPath)
 	if err != nil {
@@ -117,18 +314,22 @@ func (c AWSUpdateLBs) checkCertificateAndChain(certPath string, chainPath string
 	return true, nil
 }
 
-func (c AWSUpdateLBs) updateStack(certificateName string, keyPairName string, stackName string, boshAZ string, lbType string, awsRegion, envID string) error {
+func (c AWSUpdateLBs) updateStack(certificateName string, keyPairName string, stackName string, boshAZ string, lbType string, lbSpecs []storage.LBSpec, awsRegion, envID string, tags map[string]string) error {
 	availabilityZones, err := c.availabilityZoneRetriever.Retrieve(awsRegion)
 	if err != nil {
 		return err
 	}
 
-	certificate, err := c.certificateManager.Describe(certificateName)
-	if err != nil {
-		return err
+	var certificateARN string
+	if lbExists(lbType) {
+		certificate, err := c.certificateManager.Describe(certificateName)
+		if err != nil {
+			return err
+		}
+		certificateARN = certificate.ARN
 	}
 
-	_, err = c.infrastructureManager.Update(keyPairName, availabilityZones, stackName, boshAZ, lbType, certificate.ARN, envID)
+	_, err = c.infrastructureManager.Update(keyPairName, availabilityZones, stackName, boshAZ, lbType, certificateARN, lbSpecs, envID, tags)
 	if err != nil {
 		return err
 	}
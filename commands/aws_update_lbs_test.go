@@ -27,6 +27,7 @@ var _ = Describe("AWS Update LBs", func() {
 		boshClientProvider        *fakes.BOSHClientProvider
 		boshClient                *fakes.BOSHClient
 		logger                    *fakes.Logger
+		cloudConfigManager        *fakes.CloudConfigManager
 		guidGenerator             *fakes.GuidGenerator
 		stateStore                *fakes.StateStore
 	)
@@ -47,6 +48,7 @@ var _ = Describe("AWS Update LBs", func() {
 		infrastructureManager = &fakes.InfrastructureManager{}
 		credentialValidator = &fakes.CredentialValidator{}
 		logger = &fakes.Logger{}
+		cloudConfigManager = &fakes.CloudConfigManager{}
 		guidGenerator = &fakes.GuidGenerator{}
 		stateStore = &fakes.StateStore{}
 		boshClient = &fakes.BOSHClient{}
@@ -85,8 +87,8 @@ var _ = Describe("AWS Update LBs", func() {
 		Expect(err).NotTo(HaveOccurred())
 
 		command = commands.NewAWSUpdateLBs(credentialValidator, certificateManager,
-			availabilityZoneRetriever, infrastructureManager, boshClientProvider, logger, guidGenerator,
-			stateStore)
+			availabilityZoneRetriever, infrastructureManager, boshClientProvider, logger, cloudConfigManager,
+			guidGenerator, stateStore)
 	})
 
 	Describe("Execute", func() {
@@ -287,6 +289,236 @@ var _ = Describe("AWS Update LBs", func() {
 			})
 		})
 
+		Context("when a named lb is provided", func() {
+			It("replaces the certificate of the named lb and leaves the lb type alone", func() {
+				spec := storage.LBSpec{Name: "foundation", Type: "cf", CertificateName: "some-foundation-certificate"}
+				incomingState.EnvID = "some-env-timestamp"
+				incomingState.LBs = []storage.LBSpec{{Name: "vault"}, spec}
+
+				err := command.Execute(commands.AWSCreateLBsConfig{
+					LBType:   "cf",
+					CertPath: certFilePath,
+					KeyPath:  keyFilePath,
+					Spec:     spec,
+				}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certificateManager.CreateCall.Receives.CertificateName).To(Equal("foundation-elb-cert-abcd-some-env-timestamp"))
+				Expect(certificateManager.DeleteCall.Receives.CertificateName).To(Equal("some-foundation-certificate"))
+
+				spec.CertificateName = "foundation-elb-cert-abcd-some-env-timestamp"
+				spec.CertificateARN = "some-certificate-arn"
+				Expect(infrastructureManager.UpdateCall.Receives.LBType).To(Equal("concourse"))
+				Expect(infrastructureManager.UpdateCall.Receives.LBSpecs).To(Equal([]storage.LBSpec{{Name: "vault"}, spec}))
+
+				state := stateStore.SetCall.Receives[0].State
+				Expect(state.Stack.CertificateName).To(Equal("some-certificate-name"))
+				Expect(state.LBs).To(Equal([]storage.LBSpec{{Name: "vault"}, spec}))
+			})
+
+			It("replaces the sni certificates of the named lb", func() {
+				spec := storage.LBSpec{
+					Name:            "foundation",
+					Type:            "cf",
+					Flavor:          "alb",
+					CertificateName: "some-foundation-certificate",
+					SNICertificates: []storage.LBCertificate{{Name: "some-old-sni-certificate"}},
+				}
+				incomingState.EnvID = "some-env-timestamp"
+				incomingState.LBs = []storage.LBSpec{spec}
+
+				err := command.Execute(commands.AWSCreateLBsConfig{
+					LBType:   "cf",
+					CertPath: certFilePath,
+					KeyPath:  keyFilePath,
+					SNICerts: []commands.CertBundle{{CertPath: certFilePath, KeyPath: keyFilePath}},
+					Spec:     spec,
+				}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certificateManager.CreateCall.CertificateNames).To(Equal([]string{
+					"foundation-elb-cert-abcd-some-env-timestamp",
+					"foundation-sni-elb-cert-abcd-some-env-timestamp",
+				}))
+				Expect(certificateManager.DeleteCall.CertificateNames).To(Equal([]string{
+					"some-foundation-certificate",
+					"some-old-sni-certificate",
+				}))
+
+				state := stateStore.SetCall.Receives[0].State
+				Expect(state.LBs[0].SNICertificates).To(Equal([]storage.LBCertificate{{
+					Name: "foundation-sni-elb-cert-abcd-some-env-timestamp",
+					ARN:  "some-certificate-arn",
+				}}))
+			})
+		})
+
+		Context("when an acm certificate is referenced", func() {
+			const certificateARN = "arn:aws:acm:some-region:123456789012:certificate/some-id"
+
+			BeforeEach(func() {
+				incomingState.AWS.Region = "some-region"
+			})
+
+			It("replaces the certificate with the acm certificate without uploading it", func() {
+				err := command.Execute(commands.AWSCreateLBsConfig{ACMCertificateARN: certificateARN}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certificateManager.CreateCall.CallCount).To(Equal(0))
+				Expect(logger.StepCall.Messages).NotTo(ContainElement("uploading new certificate"))
+				Expect(certificateManager.DeleteCall.Receives.CertificateName).To(Equal("some-certificate-name"))
+				Expect(stateStore.SetCall.Receives[0].State.Stack.CertificateName).To(Equal(certificateARN))
+			})
+
+			It("does nothing when the lb already uses the acm certificate", func() {
+				spec := storage.LBSpec{Name: "foundation", Type: "cf", CertificateName: certificateARN}
+				incomingState.LBs = []storage.LBSpec{spec}
+
+				err := command.Execute(commands.AWSCreateLBsConfig{Spec: spec, ACMCertificateARN: certificateARN}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.PrintlnCall.Receives.Message).To(Equal("no updates are to be performed"))
+				Expect(infrastructureManager.UpdateCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the acm certificate is in another region", func() {
+				incomingState.AWS.Region = "other-region"
+
+				err := command.Execute(commands.AWSCreateLBsConfig{ACMCertificateARN: certificateARN}, incomingState)
+				Expect(err).To(MatchError(`acm certificate "arn:aws:acm:some-region:123456789012:certificate/some-id" is in region "some-region", the lbs of this environment are in "other-region"`))
+			})
+		})
+
+		Context("when a flavor is provided", func() {
+			var spec storage.LBSpec
+
+			BeforeEach(func() {
+				spec = storage.LBSpec{Name: "foundation", Type: "cf", CertificateName: "some-foundation-certificate"}
+				incomingState.LBs = []storage.LBSpec{{Name: "vault"}, spec}
+			})
+
+			It("attaches the load balancer of the new flavor alongside the classic elb", func() {
+				err := command.Execute(commands.AWSCreateLBsConfig{
+					LBType:   "cf",
+					Spec:     spec,
+					LBFlavor: "alb",
+				}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				migrating := storage.LBSpec{Name: "foundation", Type: "cf", CertificateName: "some-foundation-certificate", Flavor: "alb", PreviousFlavor: "elb"}
+				Expect(certificateManager.CreateCall.CallCount).To(Equal(0))
+				Expect(infrastructureManager.UpdateCall.Receives.LBSpecs).To(Equal([]storage.LBSpec{{Name: "vault"}, migrating}))
+				Expect(stateStore.SetCall.Receives[0].State.LBs).To(Equal([]storage.LBSpec{{Name: "vault"}, migrating}))
+				Expect(cloudConfigManager.UpdateCall.Receives.State.LBs).To(Equal([]storage.LBSpec{{Name: "vault"}, migrating}))
+				Expect(logger.PrintlnCall.Receives.Message).To(Equal("redeploy so that the vms of lb \"foundation\" register with its alb, then run `bbl update-lbs --name foundation --aws-lb-flavor alb` to detach the elb"))
+			})
+
+			It("detaches the previous flavor when the migration is run again", func() {
+				spec.Flavor = "alb"
+				spec.PreviousFlavor = "elb"
+
+				err := command.Execute(commands.AWSCreateLBsConfig{
+					LBType:   "cf",
+					Spec:     spec,
+					LBFlavor: "alb",
+				}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				spec.PreviousFlavor = ""
+				Expect(infrastructureManager.UpdateCall.Receives.LBSpecs).To(Equal([]storage.LBSpec{{Name: "vault"}, spec}))
+				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(1))
+				Expect(logger.StepCall.Messages).To(ContainElement(`detaching the elb of lb "foundation"`))
+			})
+
+			It("does nothing when the lb already has the flavor", func() {
+				err := command.Execute(commands.AWSCreateLBsConfig{
+					LBType:   "cf",
+					Spec:     spec,
+					LBFlavor: "elb",
+				}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(infrastructureManager.UpdateCall.CallCount).To(Equal(0))
+				Expect(logger.PrintlnCall.Receives.Message).To(Equal("no updates are to be performed"))
+			})
+
+			It("returns an error when a migration to another flavor is still going on", func() {
+				spec.Flavor = "alb"
+				spec.PreviousFlavor = "elb"
+
+				err := command.Execute(commands.AWSCreateLBsConfig{
+					LBType:   "cf",
+					Spec:     spec,
+					LBFlavor: "nlb",
+				}, incomingState)
+				Expect(err).To(MatchError(`lb "foundation" is still migrating from elb to alb, finish the migration with --aws-lb-flavor alb first`))
+			})
+
+			It("returns an error when the lb cannot be served by an alb", func() {
+				incomingState.LBs = append(incomingState.LBs, storage.LBSpec{Name: "ci", Type: "concourse"})
+
+				err := command.Execute(commands.AWSCreateLBsConfig{
+					LBType:   "concourse",
+					Spec:     storage.LBSpec{Name: "ci", Type: "concourse"},
+					LBFlavor: "alb",
+				}, incomingState)
+				Expect(err).To(MatchError(`lb "ci" listens for tcp on port 80, which an alb cannot serve, use the nlb flavor instead`))
+			})
+
+			Context("when the unnamed lb migrates into a new named lb", func() {
+				It("attaches the named lb alongside the unnamed lb with its certificate", func() {
+					err := command.Execute(commands.AWSCreateLBsConfig{
+						LBType:   "concourse",
+						Spec:     storage.LBSpec{Name: "ci", Type: "concourse"},
+						LBFlavor: "nlb",
+					}, incomingState)
+					Expect(err).NotTo(HaveOccurred())
+
+					migrated := storage.LBSpec{Name: "ci", Type: "concourse", Flavor: "nlb", CertificateName: "some-certificate-name", CertificateARN: "some-certificate-arn"}
+					Expect(certificateManager.CreateCall.CallCount).To(Equal(0))
+					Expect(infrastructureManager.UpdateCall.Receives.LBType).To(Equal("concourse"))
+					Expect(infrastructureManager.UpdateCall.Receives.LBSpecs).To(Equal([]storage.LBSpec{{Name: "vault"}, spec, migrated}))
+					Expect(stateStore.SetCall.Receives[0].State.Stack.CertificateName).To(Equal("some-certificate-name"))
+					Expect(logger.PrintlnCall.Receives.Message).To(Equal("add the ci-lb vm extensions of lb \"ci\" alongside those of the unnamed lb, redeploy and point the domain at the nlb, then run `bbl update-lbs --name ci --aws-lb-flavor nlb` to detach the unnamed lb"))
+				})
+
+				It("detaches the unnamed lb and hands its certificate over when the migration is run again", func() {
+					migrated := storage.LBSpec{Name: "ci", Type: "concourse", Flavor: "nlb", CertificateName: "some-certificate-name"}
+					incomingState.LBs = append(incomingState.LBs, migrated)
+					incomingState.LBCerts.GeneratedCerts = []storage.LBGeneratedCert{{Mode: "self-signed", Domain: "ci.example.com"}}
+
+					err := command.Execute(commands.AWSCreateLBsConfig{
+						LBType:   "concourse",
+						Spec:     migrated,
+						LBFlavor: "nlb",
+					}, incomingState)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(infrastructureManager.UpdateCall.Receives.LBType).To(Equal("none"))
+					Expect(infrastructureManager.UpdateCall.Receives.LBSpecs).To(Equal([]storage.LBSpec{{Name: "vault"}, spec, migrated}))
+					Expect(certificateManager.DeleteCall.CallCount).To(Equal(0))
+
+					state := stateStore.SetCall.Receives[0].State
+					Expect(state.Stack.LBType).To(Equal("none"))
+					Expect(state.Stack.CertificateName).To(BeEmpty())
+					Expect(state.LBCerts.GeneratedCerts).To(Equal([]storage.LBGeneratedCert{{LB: "ci", Mode: "self-signed", Domain: "ci.example.com"}}))
+					Expect(logger.StepCall.Messages).To(ContainElement(`detaching the unnamed concourse lb, lb "ci" takes over its certificate`))
+				})
+
+				It("returns an error when the migration is run again with another flavor", func() {
+					migrated := storage.LBSpec{Name: "ci", Type: "concourse", Flavor: "nlb", CertificateName: "some-certificate-name"}
+					incomingState.LBs = append(incomingState.LBs, migrated)
+
+					err := command.Execute(commands.AWSCreateLBsConfig{
+						LBType:   "concourse",
+						Spec:     migrated,
+						LBFlavor: "elb",
+					}, incomingState)
+					Expect(err).To(MatchError(`the unnamed lb is migrating into the nlb of lb "ci", finish the migration with --aws-lb-flavor nlb first`))
+				})
+			})
+		})
+
 		Describe("failure cases", func() {
 			It("returns an error when the chain file cannot be opened", func() {
 				certificateManager.DescribeCall.Returns.Certificate = iam.Certificate{
@@ -1,6 +1,12 @@
 package commands
 
-import "github.com/cloudfoundry/bosh-bootloader/storage"
+import (
+	"fmt"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
 
 const (
 	BOSHDeploymentVarsCommand = "bosh-deployment-vars"
@@ -9,12 +15,14 @@ const (
 type BOSHDeploymentVars struct {
 	logger      logger
 	boshManager boshManager
+	renderer    renderer
 }
 
-func NewBOSHDeploymentVars(logger logger, boshManager boshManager) BOSHDeploymentVars {
+func NewBOSHDeploymentVars(logger logger, boshManager boshManager, renderer renderer) BOSHDeploymentVars {
 	return BOSHDeploymentVars{
 		logger:      logger,
 		boshManager: boshManager,
+		renderer:    renderer,
 	}
 }
 
@@ -30,6 +38,17 @@ func (b BOSHDeploymentVars) Execute(args []string, state storage.State) error {
 	if err != nil {
 		return err
 	}
+
+	if b.renderer.Structured() {
+		output := map[string]interface{}{}
+		err = yaml.Unmarshal([]byte(vars), &output)
+		if err != nil {
+			return fmt.Errorf("failed to parse deployment vars: %s", err)
+		}
+
+		return b.renderer.Render(output)
+	}
+
 	b.logger.Println(vars)
 	return nil
 }
@@ -15,6 +15,7 @@ var _ = Describe("BOSHDeploymentVars", func() {
 	var (
 		logger      *fakes.Logger
 		boshManager *fakes.BOSHManager
+		renderer    *fakes.Renderer
 
 		boshDeploymentVars commands.BOSHDeploymentVars
 	)
@@ -24,7 +25,9 @@ var _ = Describe("BOSHDeploymentVars", func() {
 		boshManager = &fakes.BOSHManager{}
 		boshManager.VersionCall.Returns.Version = "2.0.0"
 
-		boshDeploymentVars = commands.NewBOSHDeploymentVars(logger, boshManager)
+		renderer = &fakes.Renderer{}
+
+		boshDeploymentVars = commands.NewBOSHDeploymentVars(logger, boshManager, renderer)
 	})
 
 	It("calls out to bosh manager and prints the resulting information", func() {
@@ -35,6 +38,32 @@ var _ = Describe("BOSHDeploymentVars", func() {
 		Expect(logger.PrintlnCall.Messages).To(ContainElement("some-vars-yaml"))
 	})
 
+	Context("when structured output is requested", func() {
+		BeforeEach(func() {
+			renderer.StructuredCall.Returns.Structured = true
+		})
+
+		It("renders the deployment vars", func() {
+			boshManager.GetDeploymentVarsCall.Returns.Vars = "internal_cidr: 10.0.0.0/24\ntags:\n- some-tag\n"
+
+			err := boshDeploymentVars.Execute([]string{}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(renderer.RenderCall.Receives.Value).To(Equal(map[string]interface{}{
+				"internal_cidr": "10.0.0.0/24",
+				"tags":          []interface{}{"some-tag"},
+			}))
+			Expect(logger.PrintlnCall.CallCount).To(Equal(0))
+		})
+
+		It("returns an error when the deployment vars cannot be parsed", func() {
+			boshManager.GetDeploymentVarsCall.Returns.Vars = "%%%"
+
+			err := boshDeploymentVars.Execute([]string{}, storage.State{})
+			Expect(err).To(MatchError(ContainSubstring("failed to parse deployment vars")))
+		})
+	})
+
 	It("runs successfully if the version is less than 2.0.0 but the state has no director", func() {
 		boshManager.VersionCall.Returns.Version = "1.9.9"
 
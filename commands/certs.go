@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/ssl"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	CertsCommand = "certs"
+
+	lbCertSource       = "lb"
+	directorCertSource = "director"
+	variableCertSource = "variables"
+)
+
+var timeNow = time.Now
+
+type Certs struct {
+	credentialValidator credentialValidator
+	stateValidator      stateValidator
+	certificateManager  certificateManager
+	renderer            renderer
+	stdout              io.Writer
+}
+
+type CertsOutput struct {
+	Certificates []CertOutput `json:"certificates" yaml:"certificates"`
+}
+
+// CertOutput describes a certificate held by bbl. Source tells whether it
+// is served by an lb, secures the director or is a bosh variable.
+type CertOutput struct {
+	Source        string    `json:"source" yaml:"source"`
+	Name          string    `json:"name" yaml:"name"`
+	Subject       string    `json:"subject" yaml:"subject"`
+	SANs          []string  `json:"sans,omitempty" yaml:"sans,omitempty"`
+	Issuer        string    `json:"issuer" yaml:"issuer"`
+	NotAfter      time.Time `json:"not_after" yaml:"not_after"`
+	DaysRemaining int       `json:"days_remaining" yaml:"days_remaining"`
+}
+
+func NewCerts(credentialValidator credentialValidator, stateValidator stateValidator, certificateManager certificateManager,
+	renderer renderer, stdout io.Writer) Certs {
+	return Certs{
+		credentialValidator: credentialValidator,
+		stateValidator:      stateValidator,
+		certificateManager:  certificateManager,
+		renderer:            renderer,
+		stdout:              stdout,
+	}
+}
+
+func (c Certs) Execute(subcommandFlags []string, state storage.State) error {
+	var warnDays int
+	certsFlags := flags.New(CertsCommand)
+	certsFlags.Int(&warnDays, "warn-days", 0)
+	err := certsFlags.Parse(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	if warnDays < 0 {
+		return errors.New("--warn-days must not be negative")
+	}
+
+	err = c.stateValidator.Validate()
+	if err != nil {
+		return err
+	}
+
+	certs, err := c.lbCertificates(state)
+	if err != nil {
+		return err
+	}
+
+	boshCerts, err := boshCertificates(state.BOSH)
+	if err != nil {
+		return err
+	}
+	certs = append(certs, boshCerts...)
+
+	now := timeNow()
+	output := CertsOutput{Certificates: []CertOutput{}}
+	for _, cert := range certs {
+		info, err := ssl.DescribeCertificate([]byte(cert.pem))
+		if err != nil {
+			return fmt.Errorf("failed to describe certificate %q: %s", cert.name, err)
+		}
+
+		output.Certificates = append(output.Certificates, CertOutput{
+			Source:        cert.source,
+			Name:          cert.name,
+			Subject:       info.Subject,
+			SANs:          info.SANs,
+			Issuer:        info.Issuer,
+			NotAfter:      info.NotAfter,
+			DaysRemaining: daysRemaining(now, info.NotAfter),
+		})
+	}
+
+	if c.renderer.Structured() {
+		err = c.renderer.Render(output)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, cert := range output.Certificates {
+			fmt.Fprintf(c.stdout, "[%s] %s: %s", cert.Source, cert.Name, cert.Subject)
+			if len(cert.SANs) > 0 {
+				fmt.Fprintf(c.stdout, " (%s)", strings.Join(cert.SANs, ", "))
+			}
+			fmt.Fprintf(c.stdout, " issued by %s, expires %s [%d days remaining]\n", cert.Issuer, cert.NotAfter.Format(time.RFC3339), cert.DaysRemaining)
+		}
+	}
+
+	if warnDays == 0 {
+		return nil
+	}
+
+	var expiring []string
+	for _, cert := range output.Certificates {
+		if cert.DaysRemaining < warnDays {
+			expiring = append(expiring, fmt.Sprintf("%s (%d days)", cert.Name, cert.DaysRemaining))
+		}
+	}
+
+	if len(expiring) > 0 {
+		return fmt.Errorf("certificates expire within %d days: %s", warnDays, strings.Join(expiring, ", "))
+	}
+
+	return nil
+}
+
+type namedCertificate struct {
+	source string
+	name   string
+	pem    string
+}
+
+// lbCertificates lists the certificates served by the lbs, which are kept
+// in iam on aws and in the state on gcp, and the ca of generated ones.
+func (c Certs) lbCertificates(state storage.State) ([]namedCertificate, error) {
+	var lbCerts []lbCertificatePEM
+	switch state.IAAS {
+	case "aws":
+		if lbExists(state.Stack.LBType) || len(state.LBs) > 0 {
+			err := c.credentialValidator.Validate()
+			if err != nil {
+				return nil, err
+			}
+
+			lbCerts, err = awsLBCertificatePEMs(c.certificateManager, state.Stack.LBType, state.Stack.CertificateName, state.LBs)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case "gcp":
+		lbCerts = gcpLBCertificatePEMs(state.LB.Type, state.LB, state.LBs)
+	}
+
+	var certs []namedCertificate
+	for _, cert := range lbCerts {
+		certs = append(certs, namedCertificate{lbCertSource, cert.lb, cert.cert})
+	}
+
+	if state.LBCerts.CA != "" {
+		certs = append(certs, namedCertificate{lbCertSource, "generated lb ca", state.LBCerts.CA})
+	}
+
+	return certs, nil
+}
+
+// boshCertificates lists the director certificate and ca followed by the
+// certificates among the bosh variables, skipping the ones already listed.
+func boshCertificates(bosh storage.BOSH) ([]namedCertificate, error) {
+	var certs []namedCertificate
+	seen := map[string]bool{}
+	add := func(source, name, pem string) {
+		pem = strings.TrimSpace(pem)
+		if pem == "" || seen[pem] {
+			return
+		}
+		seen[pem] = true
+		certs = append(certs, namedCertificate{source, name, pem})
+	}
+
+	add(directorCertSource, "director_ssl", bosh.DirectorSSLCertificate)
+	add(directorCertSource, "director_ssl ca", bosh.DirectorSSLCA)
+
+	if bosh.Variables == "" {
+		return certs, nil
+	}
+
+	var variables map[string]interface{}
+	err := yaml.Unmarshal([]byte(bosh.Variables), &variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bosh variables: %s", err)
+	}
+
+	var names []string
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		variable, ok := variables[name].(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+
+		if certificate, ok := variable["certificate"].(string); ok {
+			add(variableCertSource, name, certificate)
+		}
+	}
+
+	return certs, nil
+}
+
+// daysRemaining counts the whole days left until notAfter, which is
+// negative once the certificate has expired.
+func daysRemaining(now, notAfter time.Time) int {
+	return int(math.Floor(notAfter.Sub(now).Hours() / 24))
+}
@@ -0,0 +1,222 @@
+package commands_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+	"github.com/cloudfoundry/bosh-bootloader/testhelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Certs", func() {
+	var (
+		credentialValidator *fakes.CredentialValidator
+		stateValidator      *fakes.StateValidator
+		certificateManager  *fakes.CertificateManager
+		renderer            *fakes.Renderer
+		stdout              *bytes.Buffer
+		command             commands.Certs
+
+		boshState storage.BOSH
+		now       time.Time
+	)
+
+	BeforeEach(func() {
+		credentialValidator = &fakes.CredentialValidator{}
+		stateValidator = &fakes.StateValidator{}
+		certificateManager = &fakes.CertificateManager{}
+		renderer = &fakes.Renderer{}
+		stdout = bytes.NewBuffer([]byte{})
+
+		now = time.Date(2018, time.May, 16, 22, 13, 41, 0, time.UTC)
+		commands.SetTimeNow(func() time.Time { return now })
+
+		boshState = storage.BOSH{
+			DirectorSSLCertificate: testhelpers.BBL_CERT,
+			DirectorSSLCA:          testhelpers.BBL_CHAIN,
+			Variables: fmt.Sprintf(`admin_password: some-password
+default_ca:
+  ca: %[1]q
+  certificate: %[1]q
+  private_key: some-key
+nats_server_tls:
+  ca: %[1]q
+  certificate: %[2]q
+  private_key: some-key
+`, testhelpers.BBL_CHAIN, testhelpers.OTHER_BBL_CERT),
+		}
+
+		command = commands.NewCerts(credentialValidator, stateValidator, certificateManager, renderer, stdout)
+	})
+
+	AfterEach(func() {
+		commands.ResetTimeNow()
+	})
+
+	Describe("Execute", func() {
+		It("prints the director certificates and the certificates among the bosh variables", func() {
+			err := command.Execute([]string{}, storage.State{IAAS: "gcp", BOSH: boshState})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stateValidator.ValidateCall.CallCount).To(Equal(1))
+			Expect(stdout.String()).To(Equal(`[director] director_ssl: bbl-intermediate issued by bbl-ca, expires 2018-05-26T22:13:41Z [10 days remaining]
+[director] director_ssl ca: bbl-ca issued by bbl-ca, expires 2026-05-04T23:26:05Z [2910 days remaining]
+[variables] nats_server_tls: server.dc1.cf.internal issued by consulCA, expires 2018-06-08T17:21:00Z [22 days remaining]
+`))
+		})
+
+		It("prints the certificates of the lbs from iam on aws", func() {
+			certificateManager.DescribeCall.Returns.Certificate = iam.Certificate{Body: testhelpers.OTHER_BBL_CERT}
+
+			err := command.Execute([]string{}, storage.State{
+				IAAS: "aws",
+				Stack: storage.Stack{
+					LBType:          "cf",
+					CertificateName: "some-certificate",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(credentialValidator.ValidateCall.CallCount).To(Equal(1))
+			Expect(certificateManager.DescribeCall.Receives.CertificateName).To(Equal("some-certificate"))
+			Expect(stdout.String()).To(Equal("[lb] cf: server.dc1.cf.internal issued by consulCA, expires 2018-06-08T17:21:00Z [22 days remaining]\n"))
+		})
+
+		It("does not validate aws credentials when there are no lbs", func() {
+			err := command.Execute([]string{}, storage.State{IAAS: "aws"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(credentialValidator.ValidateCall.CallCount).To(Equal(0))
+		})
+
+		It("prints the certificates of the lbs and the generated lb ca from the state on gcp", func() {
+			err := command.Execute([]string{}, storage.State{
+				IAAS: "gcp",
+				LB: storage.LB{
+					Type: "cf",
+					Cert: testhelpers.BBL_CERT,
+				},
+				LBs: []storage.LBSpec{{Name: "foundation", Cert: testhelpers.OTHER_BBL_CERT}},
+				LBCerts: storage.LBCerts{
+					CA: testhelpers.BBL_CHAIN,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(Equal(`[lb] cf: bbl-intermediate issued by bbl-ca, expires 2018-05-26T22:13:41Z [10 days remaining]
+[lb] foundation: server.dc1.cf.internal issued by consulCA, expires 2018-06-08T17:21:00Z [22 days remaining]
+[lb] generated lb ca: bbl-ca issued by bbl-ca, expires 2026-05-04T23:26:05Z [2910 days remaining]
+`))
+		})
+
+		It("renders the certificates for structured output", func() {
+			renderer.StructuredCall.Returns.Structured = true
+
+			err := command.Execute([]string{}, storage.State{
+				IAAS: "gcp",
+				BOSH: storage.BOSH{DirectorSSLCertificate: testhelpers.BBL_CERT},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(BeEmpty())
+			Expect(renderer.RenderCall.Receives.Value).To(Equal(commands.CertsOutput{
+				Certificates: []commands.CertOutput{{
+					Source:        "director",
+					Name:          "director_ssl",
+					Subject:       "bbl-intermediate",
+					Issuer:        "bbl-ca",
+					NotAfter:      time.Date(2018, time.May, 26, 22, 13, 41, 0, time.UTC),
+					DaysRemaining: 10,
+				}},
+			}))
+		})
+
+		It("counts expired certificates as negative days remaining", func() {
+			now = time.Date(2018, time.May, 27, 0, 0, 0, 0, time.UTC)
+
+			err := command.Execute([]string{}, storage.State{
+				IAAS: "gcp",
+				BOSH: storage.BOSH{DirectorSSLCertificate: testhelpers.BBL_CERT},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(ContainSubstring("[-1 days remaining]"))
+		})
+
+		Context("when --warn-days is provided", func() {
+			It("returns an error naming the certificates that expire within the threshold", func() {
+				err := command.Execute([]string{"--warn-days", "30"}, storage.State{IAAS: "gcp", BOSH: boshState})
+				Expect(err).To(MatchError("certificates expire within 30 days: director_ssl (10 days), nats_server_tls (22 days)"))
+
+				Expect(stdout.String()).To(ContainSubstring("director_ssl:"))
+			})
+
+			It("does not return an error when no certificate expires within the threshold", func() {
+				err := command.Execute([]string{"--warn-days", "10"}, storage.State{IAAS: "gcp", BOSH: boshState})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when --warn-days is negative", func() {
+				err := command.Execute([]string{"--warn-days", "-1"}, storage.State{})
+				Expect(err).To(MatchError("--warn-days must not be negative"))
+			})
+
+			It("returns an error when the flags cannot be parsed", func() {
+				err := command.Execute([]string{"--unknown-flag"}, storage.State{})
+				Expect(err).To(MatchError("flag provided but not defined: -unknown-flag"))
+			})
+
+			It("returns an error when the state validator fails", func() {
+				stateValidator.ValidateCall.Returns.Error = errors.New("state validator failed")
+
+				err := command.Execute([]string{}, storage.State{})
+				Expect(err).To(MatchError("state validator failed"))
+			})
+
+			It("returns an error when the aws credentials are invalid", func() {
+				credentialValidator.ValidateCall.Returns.Error = errors.New("invalid credentials")
+
+				err := command.Execute([]string{}, storage.State{IAAS: "aws", Stack: storage.Stack{LBType: "cf"}})
+				Expect(err).To(MatchError("invalid credentials"))
+			})
+
+			It("returns an error when the iam certificate cannot be described", func() {
+				certificateManager.DescribeCall.Returns.Error = errors.New("failed to describe")
+
+				err := command.Execute([]string{}, storage.State{
+					IAAS:  "aws",
+					Stack: storage.Stack{LBType: "cf", CertificateName: "some-certificate"},
+				})
+				Expect(err).To(MatchError("failed to describe"))
+			})
+
+			It("returns an error when the bosh variables cannot be parsed", func() {
+				err := command.Execute([]string{}, storage.State{BOSH: storage.BOSH{Variables: "%%%"}})
+				Expect(err).To(MatchError(ContainSubstring("failed to parse bosh variables:")))
+			})
+
+			It("returns an error when a certificate cannot be parsed", func() {
+				err := command.Execute([]string{}, storage.State{BOSH: storage.BOSH{DirectorSSLCertificate: "some-certificate"}})
+				Expect(err).To(MatchError(`failed to describe certificate "director_ssl": certificate is not PEM encoded`))
+			})
+
+			It("returns an error when the renderer fails", func() {
+				renderer.StructuredCall.Returns.Structured = true
+				renderer.RenderCall.Returns.Error = errors.New("failed to render")
+
+				err := command.Execute([]string{}, storage.State{})
+				Expect(err).To(MatchError("failed to render"))
+			})
+		})
+	})
+})
@@ -1,6 +1,9 @@
 package commands
 
-import "github.com/cloudfoundry/bosh-bootloader/storage"
+import (
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
 
 const (
 	CloudConfigCommand = "cloud-config"
@@ -12,6 +15,10 @@ type CloudConfig struct {
 	cloudConfigManager cloudConfigManager
 }
 
+type cloudConfigConfig struct {
+	diff bool
+}
+
 func NewCloudConfig(logger logger, stateValidator stateValidator, cloudConfigManager cloudConfigManager) CloudConfig {
 	return CloudConfig{
 		logger:             logger,
@@ -21,11 +28,31 @@ func NewCloudConfig(logger logger, stateValidator stateValidator, cloudConfigMan
 }
 
 func (c CloudConfig) Execute(args []string, state storage.State) error {
-	err := c.stateValidator.Validate()
+	config, err := c.parseFlags(args)
 	if err != nil {
 		return err
 	}
 
+	err = c.stateValidator.Validate()
+	if err != nil {
+		return err
+	}
+
+	if config.diff {
+		diff, err := c.cloudConfigManager.Diff(state)
+		if err != nil {
+			return err
+		}
+
+		if diff == "" {
+			c.logger.Println("cloud config is up to date")
+			return nil
+		}
+
+		c.logger.Println(diff)
+		return nil
+	}
+
 	contents, err := c.cloudConfigManager.Generate(state)
 	if err != nil {
 		return err
@@ -33,3 +60,25 @@ func (c CloudConfig) Execute(args []string, state storage.State) error {
 	c.logger.Println(string(contents))
 	return nil
 }
+
+func (CloudConfig) parseFlags(args []string) (cloudConfigConfig, error) {
+	cloudConfigFlags := flags.New("cloud-config")
+
+	config := cloudConfigConfig{}
+	cloudConfigFlags.Bool(&config.diff, "", "diff", false)
+
+	err := cloudConfigFlags.Parse(args)
+	if err != nil {
+		return cloudConfigConfig{}, err
+	}
+
+	return config, nil
+}
+
+func updateCloudConfig(cloudConfigManager cloudConfigManager, state storage.State, interactive bool) error {
+	if interactive {
+		return cloudConfigManager.UpdateWithConfirmation(state)
+	}
+
+	return cloudConfigManager.Update(state)
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-bootloader/cloudconfig"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+func loadCloudConfigOpsFiles(cloudConfig storage.CloudConfig, opsFilePaths []string) (storage.CloudConfig, error) {
+	if len(opsFilePaths) == 0 {
+		return cloudConfig, nil
+	}
+
+	opsFiles := []string{}
+	for _, opsFilePath := range opsFilePaths {
+		opsFile, err := ioutil.ReadFile(opsFilePath)
+		if err != nil {
+			return storage.CloudConfig{}, fmt.Errorf("error reading cloud-config-ops-file contents: %v", err)
+		}
+
+		opsFiles = append(opsFiles, string(opsFile))
+	}
+
+	cloudConfig.OpsFiles = opsFiles
+
+	return cloudConfig, nil
+}
+
+func loadSizingProfile(cloudConfig storage.CloudConfig, sizingProfile string) (storage.CloudConfig, error) {
+	if sizingProfile == "" {
+		return cloudConfig, nil
+	}
+
+	if cloudconfig.IsSizingProfileName(sizingProfile) {
+		cloudConfig.SizingProfile = sizingProfile
+		cloudConfig.CustomSizingProfile = ""
+		return cloudConfig, nil
+	}
+
+	contents, err := ioutil.ReadFile(sizingProfile)
+	if err != nil {
+		return storage.CloudConfig{}, fmt.Errorf("error reading sizing-profile contents: %v", err)
+	}
+
+	_, err = cloudconfig.ParseSizingProfile(string(contents))
+	if err != nil {
+		return storage.CloudConfig{}, err
+	}
+
+	cloudConfig.SizingProfile = ""
+	cloudConfig.CustomSizingProfile = string(contents)
+
+	return cloudConfig, nil
+}
+
+func loadRuntimeConfig(runtimeConfig storage.RuntimeConfig, runtimeConfigPath string) (storage.RuntimeConfig, error) {
+	if runtimeConfigPath == "" {
+		return runtimeConfig, nil
+	}
+
+	contents, err := ioutil.ReadFile(runtimeConfigPath)
+	if err != nil {
+		return storage.RuntimeConfig{}, fmt.Errorf("error reading runtime-config contents: %v", err)
+	}
+
+	runtimeConfig.Contents = string(contents)
+
+	return runtimeConfig, nil
+}
+
+func loadNetworkIPCounts(cloudConfig storage.CloudConfig, reservedIPCounts, staticIPCounts []string) (storage.CloudConfig, error) {
+	if len(reservedIPCounts) == 0 && len(staticIPCounts) == 0 {
+		return cloudConfig, nil
+	}
+
+	networks := map[string]storage.CloudConfigNetwork{}
+	for name, network := range cloudConfig.Networks {
+		networks[name] = network
+	}
+
+	for _, value := range reservedIPCounts {
+		name, count, err := parseNetworkIPCount("reserved-ip-count", value)
+		if err != nil {
+			return storage.CloudConfig{}, err
+		}
+
+		network := networks[name]
+		network.ReservedIPCount = count
+		networks[name] = network
+	}
+
+	for _, value := range staticIPCounts {
+		name, count, err := parseNetworkIPCount("static-ip-count", value)
+		if err != nil {
+			return storage.CloudConfig{}, err
+		}
+
+		network := networks[name]
+		network.StaticIPCount = count
+		networks[name] = network
+	}
+
+	cloudConfig.Networks = networks
+
+	return cloudConfig, nil
+}
+
+func parseNetworkIPCount(flagName, value string) (string, int, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return "", 0, fmt.Errorf("invalid --%s %q, expected NETWORK=COUNT", flagName, value)
+	}
+
+	network := parts[0]
+	if !isNetworkName(network) {
+		return "", 0, fmt.Errorf("invalid --%s %q, network must be one of: %s", flagName, value, strings.Join(cloudconfig.NetworkNames, ", "))
+	}
+
+	count, err := strconv.Atoi(parts[1])
+	if err != nil || count < 1 {
+		return "", 0, fmt.Errorf("invalid --%s %q, count must be a positive number", flagName, value)
+	}
+
+	return network, count, nil
+}
+
+func isNetworkName(network string) bool {
+	for _, name := range cloudconfig.NetworkNames {
+		if name == network {
+			return true
+		}
+	}
+	return false
+}
@@ -28,19 +28,27 @@ func bblExists(stackName string, infrastructureManager infrastructureManager, bo
 }
 
 func checkBBLAndLB(state storage.State, boshClientProvider boshClientProvider, infrastructureManager infrastructureManager) error {
+	if err := checkBBL(state, boshClientProvider, infrastructureManager); err != nil {
+		return err
+	}
+
+	if !lbExists(state.Stack.LBType) {
+		return LBNotFound
+	}
+
+	return nil
+}
+
+func checkBBL(state storage.State, boshClientProvider boshClientProvider, infrastructureManager infrastructureManager) error {
 	if !state.NoDirector {
 		boshClient := boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername,
-			state.BOSH.DirectorPassword)
+			state.BOSH.DirectorPassword, state.BOSH.DirectorSSLCA)
 
 		if err := bblExists(state.Stack.Name, infrastructureManager, boshClient); err != nil {
 			return err
 		}
 	}
 
-	if !lbExists(state.Stack.LBType) {
-		return LBNotFound
-	}
-
 	return nil
 }
 
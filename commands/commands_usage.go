@@ -5,13 +5,28 @@ const (
 
   --iaas                     IAAS to deploy your BOSH Director onto. Valid options: "gcp", "aws" (Defaults to environment variable BBL_IAAS)
   [--name]                   Name to assign to your BOSH Director (optional, will be randomly generated)
+  [--name-prefix]            Prefix of the generated name instead of "bbl" (optional)
+  [--name-team]              Team named in the generated name, "<prefix>-<team>-<purpose>-<lake>" (optional)
+  [--name-purpose]           Purpose named in the generated name (optional)
+  [--tag]                    Tag as KEY=VALUE for the infrastructure and the vms of the director, on gcp labels for the vms and the dns zones of lbs with a --domain (needs terraform 0.10.0 or later), may be repeated (optional, persisted in state)
   [--ops-file]               Path to BOSH ops file (optional)
+  [--cloud-config-ops-file]  Path to an ops file applied to the generated cloud-config, may be repeated (optional, persisted in state)
+  [--sizing-profile]         Sizing profile for the cloud-config vm_types: "default", "current-generation" (newer aws instance types), "small" or a path to a profile YAML (optional, persisted in state)
+  [--runtime-config]         Path to a runtime-config uploaded to the director as the "bbl" runtime config (optional, persisted in state)
+  [--reserved-ip-count]      NETWORK=COUNT of ips reserved after the gateway of each subnet, may be repeated (optional, defaults to 2, persisted in state)
+  [--static-ip-count]        NETWORK=COUNT of static ips at the end of each subnet, may be repeated (optional, defaults to 65, persisted in state)
+  [--interactive]            Shows the cloud-config changes and asks for confirmation before applying them (optional)
   [--no-director]            Skips creating BOSH environment
+  [--ssh-private-key]        Path to an existing SSH private key to use for the jumpbox and director (optional)
+  [--ssh-public-key]         Path to the SSH public key matching --ssh-private-key (optional, derived from the private key)
+  [--ssh-key-type]           Type of the generated SSH key when no key is provided: "rsa" or "ed25519" (optional, defaults to "rsa")
+  [--ssh-key-bits]           Size of the generated rsa SSH key in bits when no key is provided (optional, defaults to 2048)
 
   --aws-access-key-id        AWS Access Key ID to use (Defaults to environment variable BBL_AWS_ACCESS_KEY_ID)
   --aws-secret-access-key    AWS Secret Access Key to use (Defaults to environment variable BBL_AWS_SECRET_ACCESS_KEY)
   --aws-region               AWS region to use (Defaults to environment variable BBL_AWS_REGION)
   [--aws-bosh-az]            AWS availability zone to use for BOSH director (Defaults to environment variable BBL_AWS_BOSH_AZ)
+  [--aws-dual-stack]         Adds IPv6 to the VPC and internal subnets and generates "-ipv6" cloud-config networks (optional, requires --terraform, persisted in state)
 
   --gcp-service-account-key  GCP Service Access Key to use (Defaults to environment variable BBL_GCP_SERVICE_ACCOUNT_KEY)
   --gcp-project-id           GCP Project ID to use (Defaults to environment variable BBL_GCP_PROJECT_ID)
@@ -25,26 +40,75 @@ const (
 
 	CreateLBsCommandUsage = `Attaches load balancer(s) with a certificate, key, and optional chain
 
-  --type              Load balancer(s) type. Valid options: "concourse" or "cf"
-  [--cert]            Path to SSL certificate (required when type="cf")
-  [--key]             Path to SSL certificate key (required when type="cf")
-  [--chain]           Path to SSL certificate chain (optional)
-  [--domain]          Creates a nameserver with a zone for given domain
-  [--skip-if-exists]  Skips creating load balancer(s) if it is already attached (optional)`
-
-	UpdateLBsCommandUsage = `Updates load balancer(s) with the supplied certificate, key, and optional chain
-
-  --cert               Path to SSL certificate
-  --key                Path to SSL certificate key
-  [--chain]            Path to SSL certificate chain (optional)
-  [--domain]           Updates domain in the nameserver zone (optional)
-  [--skip-if-missing]  Skips updating load balancer(s) if it is not attached (optional)`
+  --type                Load balancer(s) type. Valid options: "concourse" or "cf"
+  [--name]              Name of an additional load balancer of the given type, which can coexist with other load balancers. Not supported on AWS with --terraform (optional)
+  [--spec]              Path to a YAML spec of an additional load balancer, used instead of --type (name, ports, health_check, cert, key, chain). Not supported on AWS with --terraform
+  [--cert]              Path to SSL certificate (required when type="cf" without --generate-cert), repeat to serve additional certificates through SNI
+  [--key]               Path to SSL certificate key (required when type="cf" without --generate-cert), repeat once for every --cert
+  [--chain]             Path to SSL certificate chain (optional), repeat once for every --cert
+  [--key-passphrase]    Passphrase of encrypted SSL certificate keys (optional)
+  [--aws-acm]           Imports the certificates into AWS Certificate Manager instead of IAM. Not supported with --terraform (optional)
+  [--aws-acm-cert-arn]  ARN of an AWS Certificate Manager certificate to use instead of --cert and --key, such as an ACM-issued certificate renewed by AWS. Not supported with --terraform (optional)
+  [--domain]            Creates a nameserver with a zone for given domain, on aws only names the generated certificate as bbl does not create a route53 zone
+  [--parent-zone]       Name of a GCP managed zone to delegate the zone of --domain from, see bbl dns otherwise, gcp only (optional)
+  [--dns-records]       Path to a YAML list of additional records of the zone of --domain (name, type, ttl, records), gcp only (optional)
+  [--generate-cert]     Generates a wildcard certificate for --domain signed by a CA kept in the state, renewed by update-lbs within 30 days of its expiry (optional)
+  [--acme-email]        Issues the generated certificate from an ACME server such as Let's Encrypt through DNS challenges in the public zone the domain is delegated from, gcp only, requires --parent-zone (optional)
+  [--acme-directory]    Directory URL of the ACME server (optional, defaults to Let's Encrypt)
+  [--aws-lb-flavor]     AWS load balancer flavor of a named or spec load balancer. Valid options: "elb", "alb" or "nlb". Not supported with --terraform (optional, defaults to "elb")
+  [--skip-if-exists]    Skips creating load balancer(s) if it is already attached (optional)
+  [--interactive]       Shows the cloud-config changes and asks for confirmation before applying them (optional)`
+
+	UpdateLBsCommandUsage = `Updates load balancer(s) with the supplied certificate, key, and optional chain, or renews a certificate generated by create-lbs
+
+  --cert                Path to SSL certificate, repeat to serve additional certificates through SNI (renews the generated certificate within 30 days of its expiry when omitted)
+  --key                 Path to SSL certificate key, repeat once for every --cert
+  [--name]              Name of the load balancer to update, instead of the one created without --name (optional)
+  [--chain]             Path to SSL certificate chain (optional), repeat once for every --cert
+  [--key-passphrase]    Passphrase of encrypted SSL certificate keys (optional)
+  [--aws-acm]           Imports the certificates into AWS Certificate Manager instead of IAM. Not supported with --terraform (optional)
+  [--aws-acm-cert-arn]  ARN of an AWS Certificate Manager certificate to use instead of --cert and --key. Not supported with --terraform (optional)
+  [--domain]            Updates domain in the nameserver zone, on aws only the domain of a generated certificate (optional)
+  [--parent-zone]       Name of a GCP managed zone to delegate the zone of --domain from, gcp only (optional)
+  [--dns-records]       Path to a YAML list of additional records of the zone of --domain, replacing the previous ones, gcp only (optional)
+  [--aws-lb-flavor]     Migrates a named load balancer to the given AWS flavor, run twice to detach the previous flavor after redeploying. With a --name no load balancer has yet, migrates the load balancer created without --name into a new one of that name. Not supported with --terraform (optional)
+  [--skip-if-missing]   Skips updating load balancer(s) if it is not attached (optional)`
 
 	DeleteLBsCommandUsage = `Deletes load balancer(s)
 
+  [--name]             Name of the only load balancer to delete, instead of all of them (optional)
   [--skip-if-missing]  Skips deleting load balancer(s) if it is not attached (optional)`
 
-	LBsCommandUsage = "Prints attached load balancer(s)"
+	LBsCommandUsage = `Prints attached load balancer(s) with the subject and expiry of their certificates
+
+  [--name]  Name of the only load balancer to print (optional)
+  [--json]  Prints the load balancer(s) as JSON (optional)`
+
+	CertsCommandUsage = `Prints the subject, SANs, issuer and days remaining of the lb, director and BOSH variable certificates
+
+  [--warn-days]  Exits with an error when a certificate expires within the given number of days (optional)`
+
+	CostCommandUsage = `Estimates the monthly cost of the environment, or of the one up would create, from a bundled price table
+
+  [--iaas]         IAAS of the environment to estimate before up. Valid options: "gcp", "aws" (Defaults to environment variable BBL_IAAS)
+  [--aws-region]   AWS region of the environment to estimate before up (Defaults to environment variable BBL_AWS_REGION)
+  [--gcp-region]   GCP region of the environment to estimate before up (Defaults to environment variable BBL_GCP_REGION)
+  [--lb-type]      Adds the cost of a load balancer that create-lbs would attach. Valid options: "concourse" or "cf" (optional)
+  [--price-table]  Path to a price table YAML whose prices update or extend the bundled ones by region (optional)
+  [--max-monthly]  Exits with an error when the estimated monthly cost exceeds the given number of US dollars (optional)`
+
+	PreflightCommandUsage = `Checks through IAM policy simulation that the AWS credentials allow every action bbl and the BOSH AWS CPI need, or that the GCP project enables the APIs, grants the IAM permissions and has the quotas bbl needs, also run by up, create-lbs and, on AWS, destroy
+
+  [--iaas]                     IAAS of the credentials to check before up. Valid options: "gcp", "aws" (Defaults to environment variable BBL_IAAS)
+  [--aws-access-key-id]        AWS Access Key ID to check before up (Defaults to environment variable BBL_AWS_ACCESS_KEY_ID)
+  [--aws-secret-access-key]    AWS Secret Access Key to check before up (Defaults to environment variable BBL_AWS_SECRET_ACCESS_KEY)
+  [--aws-region]               AWS region to check before up (Defaults to environment variable BBL_AWS_REGION)
+  [--gcp-service-account-key]  GCP Service Access Key to check before up (Defaults to environment variable BBL_GCP_SERVICE_ACCOUNT_KEY)
+  [--gcp-project-id]           GCP Project ID to check before up (Defaults to environment variable BBL_GCP_PROJECT_ID)
+  [--gcp-zone]                 GCP Zone to check before up (Defaults to environment variable BBL_GCP_ZONE)
+  [--gcp-region]               GCP Region whose quotas are checked before up (Defaults to environment variable BBL_GCP_REGION)`
+
+	DNSCommandUsage = "Prints the dns zones created for the --domain of cf load balancers and the NS records delegating them from their parent zone, gcp only"
 
 	VersionCommandUsage = "Prints version"
 
@@ -62,11 +126,25 @@ const (
 
 	DirectorCACertCommandUsage = "Prints BOSH director CA certificate"
 
-	PrintEnvCommandUsage = "Prints required BOSH environment variables"
+	PrintEnvCommandUsage = `Prints required BOSH environment variables
+
+  [--shell]         Shell syntax to print. Valid options: "bash", "fish", "powershell", "dotenv" or "json" (optional, defaults to "bash")
+  [--ca-cert-file]  Writes the BOSH director CA certificate to the given path and exports the path instead (optional)
+
+  For a director on a private address with a jumpbox user, print-env writes the jumpbox private key to jumpbox-private-key in the state directory and exports BOSH_ALL_PROXY with it, json and structured output leave both out`
 
 	BOSHDeploymentVarsCommandUsage = "Prints required variables for BOSH deployment"
 
-	CloudConfigUsage = "Prints suggested cloud configuration for BOSH environment"
+	CloudConfigUsage = `Prints suggested cloud configuration for BOSH environment
+
+  [--diff]  Prints the changes between the director's current cloud-config and the suggested one (optional)`
+
+	OutputsCommandUsage = "Prints infrastructure outputs and BOSH director information"
+
+	StaticIPsCommandUsage = `Allocates static IPs from a generated network and records them in the state
+
+  --network  Network to allocate from. Valid options: "private", "default"
+  [--count]  Number of static IPs to allocate (optional, prints the already allocated IPs when omitted)`
 )
 
 func (Up) Usage() string { return UpCommandUsage }
@@ -81,6 +159,14 @@ func (DeleteLBs) Usage() string { return DeleteLBsCommandUsage }
 
 func (LBs) Usage() string { return LBsCommandUsage }
 
+func (Certs) Usage() string { return CertsCommandUsage }
+
+func (DNS) Usage() string { return DNSCommandUsage }
+
+func (Cost) Usage() string { return CostCommandUsage }
+
+func (Preflight) Usage() string { return PreflightCommandUsage }
+
 func (Version) Usage() string { return VersionCommandUsage }
 
 func (Usage) Usage() string { return UsageCommandUsage }
@@ -91,6 +177,10 @@ func (CloudConfig) Usage() string { return CloudConfigUsage }
 
 func (BOSHDeploymentVars) Usage() string { return BOSHDeploymentVarsCommandUsage }
 
+func (Outputs) Usage() string { return OutputsCommandUsage }
+
+func (StaticIPs) Usage() string { return StaticIPsCommandUsage }
+
 func (s StateQuery) Usage() string {
 	switch s.propertyName {
 	case EnvIDPropertyName:
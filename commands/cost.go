@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/cloudfoundry/bosh-bootloader/cost"
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const CostCommand = "cost"
+
+type Cost struct {
+	costEstimator costEstimator
+	envGetter     envGetter
+	renderer      renderer
+	stdout        io.Writer
+}
+
+type costEstimator interface {
+	Estimate(state storage.State, table cost.PriceTable) (cost.Estimate, error)
+}
+
+type costConfig struct {
+	iaas       string
+	awsRegion  string
+	gcpRegion  string
+	lbType     string
+	priceTable string
+	maxMonthly int
+}
+
+func NewCost(costEstimator costEstimator, envGetter envGetter, renderer renderer, stdout io.Writer) Cost {
+	return Cost{
+		costEstimator: costEstimator,
+		envGetter:     envGetter,
+		renderer:      renderer,
+		stdout:        stdout,
+	}
+}
+
+// Execute estimates the monthly cost of the environment of the state. Before
+// up, the flags describe the environment to estimate instead, so that the
+// cost can be checked before creating it.
+func (c Cost) Execute(subcommandFlags []string, state storage.State) error {
+	var config costConfig
+	costFlags := flags.New(CostCommand)
+	costFlags.String(&config.iaas, "iaas", c.envGetter.Get("BBL_IAAS"))
+	costFlags.String(&config.awsRegion, "aws-region", c.envGetter.Get("BBL_AWS_REGION"))
+	costFlags.String(&config.gcpRegion, "gcp-region", c.envGetter.Get("BBL_GCP_REGION"))
+	costFlags.String(&config.lbType, "lb-type", "")
+	costFlags.String(&config.priceTable, "price-table", "")
+	costFlags.Int(&config.maxMonthly, "max-monthly", 0)
+	err := costFlags.Parse(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	if config.maxMonthly < 0 {
+		return errors.New("--max-monthly must not be negative")
+	}
+
+	state, err = costState(state, config)
+	if err != nil {
+		return err
+	}
+
+	table, err := cost.ParsePriceTable(cost.DefaultPriceTable)
+	if err != nil {
+		// not tested
+		return err
+	}
+
+	if config.priceTable != "" {
+		contents, err := ioutil.ReadFile(config.priceTable)
+		if err != nil {
+			return fmt.Errorf("failed to read price table: %s", err)
+		}
+
+		update, err := cost.ParsePriceTable(string(contents))
+		if err != nil {
+			return err
+		}
+
+		table = table.Merge(update)
+	}
+
+	estimate, err := c.costEstimator.Estimate(state, table)
+	if err != nil {
+		return err
+	}
+
+	if c.renderer.Structured() {
+		err = c.renderer.Render(estimate)
+		if err != nil {
+			return err
+		}
+	} else {
+		c.print(estimate)
+	}
+
+	if config.maxMonthly > 0 && estimate.Total > float64(config.maxMonthly) {
+		return fmt.Errorf("estimated monthly cost of $%.2f exceeds --max-monthly of $%d", estimate.Total, config.maxMonthly)
+	}
+
+	return nil
+}
+
+func costState(state storage.State, config costConfig) (storage.State, error) {
+	switch {
+	case state.IAAS == "" && config.iaas == "":
+		return storage.State{}, errors.New("--iaas [gcp, aws] must be provided or BBL_IAAS must be set")
+	case state.IAAS == "":
+		state.IAAS = config.iaas
+	case config.iaas != "" && config.iaas != state.IAAS:
+		return storage.State{}, fmt.Errorf("--iaas %q does not match the iaas of the environment, %q", config.iaas, state.IAAS)
+	}
+
+	switch state.IAAS {
+	case "aws":
+		if state.AWS.Region == "" {
+			state.AWS.Region = config.awsRegion
+		}
+		if state.AWS.Region == "" {
+			return storage.State{}, errors.New("--aws-region must be provided or BBL_AWS_REGION must be set")
+		}
+	case "gcp":
+		if state.GCP.Region == "" {
+			state.GCP.Region = config.gcpRegion
+		}
+		if state.GCP.Region == "" {
+			return storage.State{}, errors.New("--gcp-region must be provided or BBL_GCP_REGION must be set")
+		}
+	default:
+		return storage.State{}, fmt.Errorf("%q is an invalid iaas type, supported values are: [gcp, aws]", state.IAAS)
+	}
+
+	if config.lbType == "" {
+		return state, nil
+	}
+
+	if !lbExists(config.lbType) {
+		return storage.State{}, errors.New("--lb-type must be \"concourse\" or \"cf\"")
+	}
+
+	if lbExists(state.Stack.LBType) || lbExists(state.LB.Type) {
+		return storage.State{}, errors.New("--lb-type previews a new lb, the environment already has one")
+	}
+
+	// Like create-lbs, aws environments created without --terraform get
+	// their lb from cloudformation.
+	if state.IAAS == "aws" && state.TFState == "" {
+		state.Stack.LBType = config.lbType
+	} else {
+		state.LB.Type = config.lbType
+	}
+
+	return state, nil
+}
+
+func (c Cost) print(estimate cost.Estimate) {
+	fmt.Fprintf(c.stdout, "%s %s, prices as of %s\n\n", estimate.IAAS, estimate.Region, estimate.PricesAsOf)
+
+	for _, item := range estimate.Items {
+		resource := item.Resource
+		if item.Type != "" {
+			resource = fmt.Sprintf("%s (%s)", resource, item.Type)
+		}
+		if item.LB != "" {
+			resource = fmt.Sprintf("%s [lb %s]", resource, item.LB)
+		}
+
+		fmt.Fprintf(c.stdout, "  %-40s %5d %10.2f\n", resource, item.Quantity, item.Monthly)
+	}
+
+	if len(estimate.LBs) > 0 {
+		fmt.Fprintln(c.stdout, "\nby lb type:")
+		for _, lb := range estimate.LBs {
+			fmt.Fprintf(c.stdout, "  %-46s %10.2f\n", lb.Type, lb.Monthly)
+		}
+	}
+
+	fmt.Fprintf(c.stdout, "\ntotal: $%.2f/month\n", estimate.Total)
+}
@@ -0,0 +1,250 @@
+package commands_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/cost"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cost", func() {
+	var (
+		costEstimator *fakes.CostEstimator
+		envGetter     *fakes.EnvGetter
+		renderer      *fakes.Renderer
+		stdout        *bytes.Buffer
+		command       commands.Cost
+
+		state        storage.State
+		defaultTable cost.PriceTable
+	)
+
+	BeforeEach(func() {
+		costEstimator = &fakes.CostEstimator{}
+		envGetter = &fakes.EnvGetter{}
+		renderer = &fakes.Renderer{}
+		stdout = bytes.NewBuffer([]byte{})
+
+		costEstimator.EstimateCall.Returns.Estimate = cost.Estimate{
+			IAAS:       "aws",
+			Region:     "us-east-1",
+			PricesAsOf: "2024-06",
+			Items: []cost.Item{
+				{Resource: "director vm", Type: "m4.xlarge", Quantity: 1, Monthly: 146},
+				{Resource: "nat instance", Type: "t2.medium", Quantity: 1, Monthly: 33.87},
+				{Resource: "elb", LB: "cf", LBType: "cf", Quantity: 2, Monthly: 36.5},
+			},
+			LBs:   []cost.LBCost{{Type: "cf", Monthly: 36.5}},
+			Total: 216.37,
+		}
+
+		state = storage.State{
+			IAAS:  "aws",
+			EnvID: "some-env-id",
+			AWS:   storage.AWS{Region: "us-east-1"},
+			Stack: storage.Stack{LBType: "cf"},
+		}
+
+		var err error
+		defaultTable, err = cost.ParsePriceTable(cost.DefaultPriceTable)
+		Expect(err).NotTo(HaveOccurred())
+
+		command = commands.NewCost(costEstimator, envGetter, renderer, stdout)
+	})
+
+	Describe("Execute", func() {
+		It("prints the estimate of the environment with the bundled price table", func() {
+			err := command.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(costEstimator.EstimateCall.Receives.State).To(Equal(state))
+			Expect(costEstimator.EstimateCall.Receives.PriceTable).To(Equal(defaultTable))
+			Expect(stdout.String()).To(Equal(`aws us-east-1, prices as of 2024-06
+
+  director vm (m4.xlarge)                      1     146.00
+  nat instance (t2.medium)                     1      33.87
+  elb [lb cf]                                  2      36.50
+
+by lb type:
+  cf                                                  36.50
+
+total: $216.37/month
+`))
+		})
+
+		It("renders the estimate with a structured output", func() {
+			renderer.StructuredCall.Returns.Structured = true
+
+			err := command.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(BeEmpty())
+			Expect(renderer.RenderCall.Receives.Value).To(Equal(costEstimator.EstimateCall.Returns.Estimate))
+		})
+
+		It("estimates the environment described by the flags before up", func() {
+			err := command.Execute([]string{"--iaas", "gcp", "--gcp-region", "us-east1", "--lb-type", "concourse"}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(costEstimator.EstimateCall.Receives.State).To(Equal(storage.State{
+				IAAS: "gcp",
+				GCP:  storage.GCP{Region: "us-east1"},
+				LB:   storage.LB{Type: "concourse"},
+			}))
+		})
+
+		It("reads the iaas and region from the environment before up", func() {
+			envGetter.Values = map[string]string{
+				"BBL_IAAS":       "aws",
+				"BBL_AWS_REGION": "eu-west-1",
+			}
+
+			err := command.Execute([]string{"--lb-type", "cf"}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(costEstimator.EstimateCall.Receives.State).To(Equal(storage.State{
+				IAAS:  "aws",
+				AWS:   storage.AWS{Region: "eu-west-1"},
+				Stack: storage.Stack{LBType: "cf"},
+			}))
+		})
+
+		It("previews a terraform lb on aws environments created with --terraform", func() {
+			state.Stack.LBType = ""
+			state.TFState = "some-tf-state"
+
+			err := command.Execute([]string{"--lb-type", "cf"}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(costEstimator.EstimateCall.Receives.State.LB.Type).To(Equal("cf"))
+			Expect(costEstimator.EstimateCall.Receives.State.Stack.LBType).To(BeEmpty())
+		})
+
+		Context("when --price-table is given", func() {
+			var priceTablePath string
+
+			BeforeEach(func() {
+				priceTableFile, err := ioutil.TempFile("", "price-table")
+				Expect(err).NotTo(HaveOccurred())
+				priceTablePath = priceTableFile.Name()
+
+				_, err = priceTableFile.WriteString(`as_of: "2025-01"
+aws:
+  sa-east-1:
+    instances:
+      t2.medium: 0.0744
+`)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(priceTableFile.Close()).To(Succeed())
+			})
+
+			AfterEach(func() {
+				os.Remove(priceTablePath)
+			})
+
+			It("merges it over the bundled price table", func() {
+				err := command.Execute([]string{"--price-table", priceTablePath}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				table := costEstimator.EstimateCall.Receives.PriceTable
+				Expect(table.AsOf).To(Equal("2025-01"))
+				Expect(table.AWS["sa-east-1"].Instances).To(Equal(map[string]float64{"t2.medium": 0.0744}))
+				Expect(table.AWS["us-east-1"]).To(Equal(defaultTable.AWS["us-east-1"]))
+			})
+
+			It("returns an error when the price table cannot be parsed", func() {
+				err := ioutil.WriteFile(priceTablePath, []byte("as_of: 2025-01"), os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = command.Execute([]string{"--price-table", priceTablePath}, state)
+				Expect(err).To(MatchError("price table has no aws or gcp regions"))
+			})
+		})
+
+		Context("when --max-monthly is given", func() {
+			It("succeeds when the estimate is within it", func() {
+				err := command.Execute([]string{"--max-monthly", "250"}, state)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns an error after printing the estimate when it is exceeded", func() {
+				err := command.Execute([]string{"--max-monthly", "200"}, state)
+				Expect(err).To(MatchError("estimated monthly cost of $216.37 exceeds --max-monthly of $200"))
+				Expect(stdout.String()).To(ContainSubstring("total: $216.37/month"))
+			})
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the flags cannot be parsed", func() {
+				err := command.Execute([]string{"--unknown-flag"}, state)
+				Expect(err).To(MatchError("flag provided but not defined: -unknown-flag"))
+			})
+
+			It("returns an error when --max-monthly is negative", func() {
+				err := command.Execute([]string{"--max-monthly", "-1"}, state)
+				Expect(err).To(MatchError("--max-monthly must not be negative"))
+			})
+
+			It("returns an error when no iaas is known", func() {
+				err := command.Execute([]string{}, storage.State{})
+				Expect(err).To(MatchError("--iaas [gcp, aws] must be provided or BBL_IAAS must be set"))
+			})
+
+			It("returns an error when --iaas does not match the environment", func() {
+				err := command.Execute([]string{"--iaas", "gcp"}, state)
+				Expect(err).To(MatchError(`--iaas "gcp" does not match the iaas of the environment, "aws"`))
+			})
+
+			It("returns an error for an invalid iaas", func() {
+				err := command.Execute([]string{"--iaas", "azure"}, storage.State{})
+				Expect(err).To(MatchError(`"azure" is an invalid iaas type, supported values are: [gcp, aws]`))
+			})
+
+			It("returns an error when no region is known", func() {
+				err := command.Execute([]string{"--iaas", "aws"}, storage.State{})
+				Expect(err).To(MatchError("--aws-region must be provided or BBL_AWS_REGION must be set"))
+
+				err = command.Execute([]string{"--iaas", "gcp"}, storage.State{})
+				Expect(err).To(MatchError("--gcp-region must be provided or BBL_GCP_REGION must be set"))
+			})
+
+			It("returns an error for an invalid --lb-type", func() {
+				err := command.Execute([]string{"--lb-type", "other"}, storage.State{IAAS: "aws", AWS: storage.AWS{Region: "us-east-1"}})
+				Expect(err).To(MatchError(`--lb-type must be "concourse" or "cf"`))
+			})
+
+			It("returns an error when --lb-type is given for an environment with an lb", func() {
+				err := command.Execute([]string{"--lb-type", "concourse"}, state)
+				Expect(err).To(MatchError("--lb-type previews a new lb, the environment already has one"))
+			})
+
+			It("returns an error when the price table cannot be read", func() {
+				err := command.Execute([]string{"--price-table", "/some/missing/price-table.yml"}, state)
+				Expect(err).To(MatchError(ContainSubstring("failed to read price table:")))
+			})
+
+			It("returns an error when the estimator fails", func() {
+				costEstimator.EstimateCall.Returns.Error = errors.New("failed to estimate")
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError("failed to estimate"))
+			})
+
+			It("returns an error when the estimate cannot be rendered", func() {
+				renderer.StructuredCall.Returns.Structured = true
+				renderer.RenderCall.Returns.Error = errors.New("failed to render")
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError("failed to render"))
+			})
+		})
+	})
+})
@@ -1,6 +1,11 @@
 package commands
 
 import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws/acm"
 	"github.com/cloudfoundry/bosh-bootloader/flags"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
@@ -12,15 +17,30 @@ type CreateLBs struct {
 	gcpCreateLBs   gcpCreateLBs
 	stateValidator stateValidator
 	boshManager    boshManager
+	certGenerator  lbCertificateGenerator
 }
 
 type lbConfig struct {
 	lbType       string
+	name         string
 	certPath     string
 	keyPath      string
 	chainPath    string
+	sniCerts     []CertBundle
 	domain       string
+	parentZone   string
+	dnsRecords   string
+	specPath     string
+	awsLBFlavor  string
+	awsACM       bool
+	awsACMCert   string
+	generateCert bool
+	passphrase   string
+	acmeEmail    string
+	acmeDir      string
 	skipIfExists bool
+	interactive  bool
+	generatedDir string
 }
 
 type gcpCreateLBs interface {
@@ -31,12 +51,14 @@ type awsCreateLBs interface {
 	Execute(AWSCreateLBsConfig, storage.State) error
 }
 
-func NewCreateLBs(awsCreateLBs awsCreateLBs, gcpCreateLBs gcpCreateLBs, stateValidator stateValidator, boshManager boshManager) CreateLBs {
+func NewCreateLBs(awsCreateLBs awsCreateLBs, gcpCreateLBs gcpCreateLBs, stateValidator stateValidator, boshManager boshManager,
+	certGenerator lbCertificateGenerator) CreateLBs {
 	return CreateLBs{
 		awsCreateLBs:   awsCreateLBs,
 		gcpCreateLBs:   gcpCreateLBs,
 		stateValidator: stateValidator,
 		boshManager:    boshManager,
+		certGenerator:  certGenerator,
 	}
 }
 
@@ -57,24 +79,136 @@ func (c CreateLBs) Execute(args []string, state storage.State) error {
 		return err
 	}
 
+	if (config.name != "" || config.specPath != "") && state.IAAS == "aws" && state.TFState != "" {
+		return errors.New("--name and --spec are not supported on aws environments created with --terraform, which have a single lb")
+	}
+
+	var spec storage.LBSpec
+	if config.name != "" {
+		spec = storage.LBSpec{
+			Name: config.name,
+			Type: config.lbType,
+		}
+	}
+
+	if config.specPath != "" {
+		loaded, err := loadLBSpec(config.specPath)
+		if err != nil {
+			return err
+		}
+
+		spec = loaded.spec
+		if loaded.certPath != "" {
+			config.certPath = loaded.certPath
+		}
+		if loaded.keyPath != "" {
+			config.keyPath = loaded.keyPath
+		}
+		if loaded.chainPath != "" {
+			config.chainPath = loaded.chainPath
+		}
+	}
+
+	if config.awsACM || config.awsACMCert != "" {
+		if state.IAAS != "aws" {
+			return errors.New("--aws-acm and --aws-acm-cert-arn are only supported on aws")
+		}
+
+		if state.TFState != "" {
+			return errors.New("--aws-acm and --aws-acm-cert-arn are not supported on environments created with --terraform, their lbs serve iam server certificates")
+		}
+
+		if config.awsACMCert != "" && config.certPath != "" {
+			return errors.New("--aws-acm-cert-arn cannot be used with a spec that names a certificate")
+		}
+	}
+
+	var dnsRecords []storage.DNSRecord
+	if config.parentZone != "" || config.dnsRecords != "" {
+		if state.IAAS != "gcp" {
+			return errors.New("--parent-zone and --dns-records are only supported on gcp, bbl does not create a route53 zone for the domain of aws lbs")
+		}
+
+		if config.dnsRecords != "" {
+			dnsRecords, err = loadDNSRecords(config.dnsRecords)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if config.acmeEmail != "" {
+		switch {
+		case state.IAAS != "gcp":
+			return errors.New("--acme-email is only supported on gcp, bbl does not create a route53 zone for the domain of aws lbs")
+		case config.parentZone == "":
+			return errors.New("--acme-email requires --parent-zone, the acme server resolves the domain through the delegation from the public parent zone")
+		}
+	}
+
+	if config.passphrase != "" {
+		decryptedDir, err := decryptKeys(config.passphrase, &config.keyPath, config.sniCerts)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(decryptedDir)
+	}
+
+	if config.awsLBFlavor != "" {
+		if state.IAAS != "aws" {
+			return errors.New("--aws-lb-flavor is only supported on aws")
+		}
+
+		spec.Flavor = config.awsLBFlavor
+		if err := validateLBFlavor(spec); err != nil {
+			return err
+		}
+	}
+
+	if config.generateCert {
+		if config.certPath != "" {
+			return errors.New("--generate-cert cannot be used with a spec that names a certificate")
+		}
+
+		state, err = c.generateCertificate(&config, spec.Name, state)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(config.generatedDir)
+	}
+
 	switch state.IAAS {
 	case "gcp":
 		if err := c.gcpCreateLBs.Execute(GCPCreateLBsConfig{
 			LBType:       config.lbType,
 			CertPath:     config.certPath,
 			KeyPath:      config.keyPath,
+			SNICerts:     config.sniCerts,
 			Domain:       config.domain,
+			ParentZone:   config.parentZone,
+			DNSRecords:   dnsRecords,
+			Spec:         spec,
 			SkipIfExists: config.skipIfExists,
+			Interactive:  config.interactive,
+
+			ForgetGeneratedCert: !config.generateCert,
 		}, state); err != nil {
 			return err
 		}
 	case "aws":
 		if err := c.awsCreateLBs.Execute(AWSCreateLBsConfig{
-			LBType:       config.lbType,
-			CertPath:     config.certPath,
-			KeyPath:      config.keyPath,
-			ChainPath:    config.chainPath,
-			SkipIfExists: config.skipIfExists,
+			LBType:            config.lbType,
+			CertPath:          config.certPath,
+			KeyPath:           config.keyPath,
+			ChainPath:         config.chainPath,
+			SNICerts:          config.sniCerts,
+			Spec:              spec,
+			SkipIfExists:      config.skipIfExists,
+			Interactive:       config.interactive,
+			ACM:               config.awsACM,
+			ACMCertificateARN: config.awsACMCert,
+
+			ForgetGeneratedCert: !config.generateCert,
 		}, state); err != nil {
 			return err
 		}
@@ -83,20 +217,130 @@ func (c CreateLBs) Execute(args []string, state storage.State) error {
 	return nil
 }
 
+// generateCertificate generates the certificate of the lb and points the
+// config at its files.
+func (c CreateLBs) generateCertificate(config *lbConfig, lbName string, state storage.State) (storage.State, error) {
+	cert := storage.LBGeneratedCert{
+		LB:     lbName,
+		Mode:   SelfSignedCertMode,
+		Domain: config.domain,
+	}
+
+	if config.acmeEmail != "" {
+		cert.Mode = ACMECertMode
+		state.LBCerts.ACMEEmail = config.acmeEmail
+		state.LBCerts.ACMEDirectoryURL = config.acmeDir
+	}
+
+	state, bundle, err := c.certGenerator.Generate(state, cert)
+	if err != nil {
+		return state, err
+	}
+
+	config.certPath, config.keyPath, config.chainPath = bundle.CertPath, bundle.KeyPath, bundle.ChainPath
+	config.generatedDir = bundle.Dir
+
+	return state, nil
+}
+
 func (CreateLBs) parseFlags(subcommandFlags []string) (lbConfig, error) {
 	lbFlags := flags.New("create-lbs")
 
 	config := lbConfig{}
 	lbFlags.String(&config.lbType, "type", "")
-	lbFlags.String(&config.certPath, "cert", "")
-	lbFlags.String(&config.keyPath, "key", "")
-	lbFlags.String(&config.chainPath, "chain", "")
+	lbFlags.String(&config.name, "name", "")
+	var certPaths, keyPaths, chainPaths []string
+	lbFlags.StringSlice(&certPaths, "cert", nil)
+	lbFlags.StringSlice(&keyPaths, "key", nil)
+	lbFlags.StringSlice(&chainPaths, "chain", nil)
 	lbFlags.String(&config.domain, "domain", "")
+	lbFlags.String(&config.parentZone, "parent-zone", "")
+	lbFlags.String(&config.dnsRecords, "dns-records", "")
+	lbFlags.String(&config.specPath, "spec", "")
+	lbFlags.String(&config.awsLBFlavor, "aws-lb-flavor", "")
+	lbFlags.String(&config.passphrase, "key-passphrase", "")
+	lbFlags.Bool(&config.awsACM, "", "aws-acm", false)
+	lbFlags.String(&config.awsACMCert, "aws-acm-cert-arn", "")
+	lbFlags.Bool(&config.generateCert, "", "generate-cert", false)
+	lbFlags.String(&config.acmeEmail, "acme-email", "")
+	lbFlags.String(&config.acmeDir, "acme-directory", "")
 	lbFlags.Bool(&config.skipIfExists, "skip-if-exists", "", false)
+	lbFlags.Bool(&config.interactive, "", "interactive", false)
 
 	if err := lbFlags.Parse(subcommandFlags); err != nil {
 		return config, err
 	}
 
+	primary, sniCerts, err := splitCertBundles(certPaths, keyPaths, chainPaths)
+	if err != nil {
+		return config, err
+	}
+	config.certPath, config.keyPath, config.chainPath = primary.CertPath, primary.KeyPath, primary.ChainPath
+	config.sniCerts = sniCerts
+
+	if config.lbType != "" && config.specPath != "" {
+		return config, errors.New("--type and --spec cannot be used together")
+	}
+
+	if config.name != "" {
+		if config.specPath != "" {
+			return config, errors.New("--name and --spec cannot be used together, the spec names the lb")
+		}
+
+		if config.lbType == "" {
+			return config, errors.New("--type is a required flag")
+		}
+
+		if config.lbType != "concourse" && config.lbType != "cf" {
+			return config, fmt.Errorf("%q is not a valid lb type, valid lb types are: concourse and cf", config.lbType)
+		}
+
+		if err := validateLBName(config.name); err != nil {
+			return config, err
+		}
+	}
+
+	if config.generateCert {
+		if len(certPaths) > 0 || len(keyPaths) > 0 || len(chainPaths) > 0 || config.passphrase != "" {
+			return config, errors.New("--generate-cert cannot be used with --cert, --key, --chain or --key-passphrase")
+		}
+
+		if config.domain == "" {
+			return config, errors.New("--generate-cert requires --domain, the certificate is generated for the domain and its subdomains")
+		}
+	}
+
+	if config.awsACMCert != "" {
+		if len(certPaths) > 0 || len(keyPaths) > 0 || len(chainPaths) > 0 || config.passphrase != "" || config.generateCert || config.awsACM {
+			return config, errors.New("--aws-acm-cert-arn cannot be used with --cert, --key, --chain, --key-passphrase, --generate-cert or --aws-acm")
+		}
+
+		if !acm.IsCertificateARN(config.awsACMCert) {
+			return config, fmt.Errorf("%q is not the arn of an acm certificate", config.awsACMCert)
+		}
+	}
+
+	if (config.acmeEmail != "" || config.acmeDir != "") && !config.generateCert {
+		return config, errors.New("--acme-email and --acme-directory require --generate-cert")
+	}
+
+	if config.acmeDir != "" && config.acmeEmail == "" {
+		return config, errors.New("--acme-directory requires --acme-email")
+	}
+
+	if config.parentZone != "" && !gcpZoneNameRegexp.MatchString(config.parentZone) {
+		return config, fmt.Errorf("%q is not a valid dns zone name, zone names start with a letter and contain only lowercase letters, numbers and dashes", config.parentZone)
+	}
+
+	if config.awsLBFlavor != "" {
+		if !isValidLBFlavor(config.awsLBFlavor) {
+			return config, fmt.Errorf("%q is not a valid aws lb flavor, valid flavors are: elb, alb and nlb", config.awsLBFlavor)
+		}
+
+		if config.awsLBFlavor != "elb" && config.name == "" && config.specPath == "" {
+			return config, errors.New("--aws-lb-flavor alb and nlb require --name or --spec, the unnamed lb is a classic elb")
+		}
+	}
+
 	return config, nil
 }
@@ -1,13 +1,21 @@
 package commands_test
 
 import (
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 
 	"github.com/cloudfoundry/bosh-bootloader/commands"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
+	"github.com/cloudfoundry/bosh-bootloader/testhelpers"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
@@ -18,6 +26,7 @@ var _ = Describe("create-lbs", func() {
 		gcpCreateLBs   *fakes.GCPCreateLBs
 		stateValidator *fakes.StateValidator
 		boshManager    *fakes.BOSHManager
+		certGenerator  *fakes.LBCertificateGenerator
 	)
 
 	BeforeEach(func() {
@@ -26,8 +35,9 @@ var _ = Describe("create-lbs", func() {
 		stateValidator = &fakes.StateValidator{}
 		boshManager = &fakes.BOSHManager{}
 		boshManager.VersionCall.Returns.Version = "2.0.0"
+		certGenerator = &fakes.LBCertificateGenerator{}
 
-		command = commands.NewCreateLBs(awsCreateLBs, gcpCreateLBs, stateValidator, boshManager)
+		command = commands.NewCreateLBs(awsCreateLBs, gcpCreateLBs, stateValidator, boshManager, certGenerator)
 	})
 
 	Describe("Execute", func() {
@@ -68,9 +78,22 @@ var _ = Describe("create-lbs", func() {
 			Expect(gcpCreateLBs.ExecuteCall.Receives.Config).Should(Equal(commands.GCPCreateLBsConfig{
 				LBType:       "concourse",
 				SkipIfExists: true,
+
+				ForgetGeneratedCert: true,
 			}))
 		})
 
+		It("passes --interactive through to the iaas specific command", func() {
+			err := command.Execute([]string{
+				"--type", "concourse",
+				"--interactive",
+			}, storage.State{
+				IAAS: "gcp",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gcpCreateLBs.ExecuteCall.Receives.Config.Interactive).To(BeTrue())
+		})
+
 		It("creates a GCP cf lb type is the iaas if GCP and type is cf", func() {
 			err := command.Execute([]string{
 				"--type", "cf",
@@ -88,6 +111,8 @@ var _ = Describe("create-lbs", func() {
 				KeyPath:      "my-key",
 				Domain:       "some-domain",
 				SkipIfExists: true,
+
+				ForgetGeneratedCert: true,
 			}))
 		})
 
@@ -109,9 +134,542 @@ var _ = Describe("create-lbs", func() {
 				KeyPath:      "my-key",
 				ChainPath:    "my-chain",
 				SkipIfExists: true,
+
+				ForgetGeneratedCert: true,
 			}))
 		})
 
+		Context("when --cert is provided several times", func() {
+			It("passes the first bundle as the default certificate and the others as sni certificates", func() {
+				err := command.Execute([]string{
+					"--type", "cf",
+					"--name", "foundation",
+					"--cert", "my-cert", "--key", "my-key", "--chain", "my-chain",
+					"--cert", "other-cert", "--key", "other-key", "--chain", "other-chain",
+				}, storage.State{IAAS: "aws"})
+				Expect(err).NotTo(HaveOccurred())
+
+				config := awsCreateLBs.ExecuteCall.Receives.Config
+				Expect(config.CertPath).To(Equal("my-cert"))
+				Expect(config.KeyPath).To(Equal("my-key"))
+				Expect(config.ChainPath).To(Equal("my-chain"))
+				Expect(config.SNICerts).To(Equal([]commands.CertBundle{{
+					CertPath:  "other-cert",
+					KeyPath:   "other-key",
+					ChainPath: "other-chain",
+				}}))
+			})
+
+			It("returns an error when a --cert has no --key", func() {
+				err := command.Execute([]string{
+					"--type", "cf",
+					"--cert", "my-cert", "--key", "my-key",
+					"--cert", "other-cert",
+				}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError("every --cert needs a --key of its own"))
+			})
+
+			It("returns an error when --chain is provided for only some certificates", func() {
+				err := command.Execute([]string{
+					"--type", "cf",
+					"--cert", "my-cert", "--key", "my-key", "--chain", "my-chain",
+					"--cert", "other-cert", "--key", "other-key",
+				}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError("--chain has to be provided for every --cert or not at all"))
+			})
+		})
+
+		Context("when --name is provided", func() {
+			It("passes a named lb of the type to the iaas specific command", func() {
+				err := command.Execute([]string{
+					"--type", "cf",
+					"--name", "foundation",
+					"--cert", "my-cert",
+					"--key", "my-key",
+					"--domain", "some-domain",
+				}, storage.State{IAAS: "gcp"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(gcpCreateLBs.ExecuteCall.Receives.Config).To(Equal(commands.GCPCreateLBsConfig{
+					LBType:   "cf",
+					CertPath: "my-cert",
+					KeyPath:  "my-key",
+					Domain:   "some-domain",
+					Spec: storage.LBSpec{
+						Name: "foundation",
+						Type: "cf",
+					},
+					ForgetGeneratedCert: true,
+				}))
+			})
+
+			It("returns an error when --spec is also provided", func() {
+				err := command.Execute([]string{"--name", "foundation", "--spec", "some-spec"}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError("--name and --spec cannot be used together, the spec names the lb"))
+			})
+
+			DescribeTable("returns an error when the named lb is invalid", func(args []string, expectedError string) {
+				err := command.Execute(args, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError(expectedError))
+				Expect(awsCreateLBs.ExecuteCall.CallCount).To(Equal(0))
+			},
+				Entry("without a type", []string{"--name", "foundation"}, "--type is a required flag"),
+				Entry("with an invalid type", []string{"--name", "foundation", "--type", "other"},
+					`"other" is not a valid lb type, valid lb types are: concourse and cf`),
+				Entry("with an invalid name", []string{"--name", "Foundation", "--type", "cf"},
+					`lb spec name "Foundation" must start with a letter and contain only lowercase letters, numbers and single dashes`),
+				Entry("with a reserved name", []string{"--name", "router", "--type", "cf"}, `lb spec name "router" is reserved`),
+			)
+		})
+
+		Context("when --aws-lb-flavor is provided", func() {
+			It("passes the flavor of the named lb to the aws command", func() {
+				err := command.Execute([]string{
+					"--type", "cf",
+					"--name", "foundation",
+					"--aws-lb-flavor", "alb",
+				}, storage.State{IAAS: "aws"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(awsCreateLBs.ExecuteCall.Receives.Config.Spec).To(Equal(storage.LBSpec{
+					Name:   "foundation",
+					Type:   "cf",
+					Flavor: "alb",
+				}))
+			})
+
+			DescribeTable("returns an error when the flavor cannot be used", func(args []string, iaas, expectedError string) {
+				err := command.Execute(args, storage.State{IAAS: iaas})
+				Expect(err).To(MatchError(expectedError))
+				Expect(awsCreateLBs.ExecuteCall.CallCount).To(Equal(0))
+			},
+				Entry("with an invalid flavor", []string{"--type", "cf", "--name", "foundation", "--aws-lb-flavor", "clb"}, "aws",
+					`"clb" is not a valid aws lb flavor, valid flavors are: elb, alb and nlb`),
+				Entry("without a name", []string{"--type", "cf", "--aws-lb-flavor", "nlb"}, "aws",
+					"--aws-lb-flavor alb and nlb require --name or --spec, the unnamed lb is a classic elb"),
+				Entry("on gcp", []string{"--type", "cf", "--name", "foundation", "--aws-lb-flavor", "nlb"}, "gcp",
+					"--aws-lb-flavor is only supported on aws"),
+				Entry("with an alb for tcp listeners", []string{"--type", "concourse", "--name", "ci", "--aws-lb-flavor", "alb"}, "aws",
+					`lb "ci" listens for tcp on port 80, which an alb cannot serve, use the nlb flavor instead`),
+			)
+		})
+
+		DescribeTable("returns an error for named lbs on aws environments created with --terraform", func(args []string) {
+			err := command.Execute(args, storage.State{IAAS: "aws", TFState: "some-tf-state"})
+			Expect(err).To(MatchError("--name and --spec are not supported on aws environments created with --terraform, which have a single lb"))
+			Expect(awsCreateLBs.ExecuteCall.CallCount).To(Equal(0))
+		},
+			Entry("with --name", []string{"--type", "cf", "--name", "foundation", "--cert", "some-cert", "--key", "some-key"}),
+			Entry("with --spec", []string{"--spec", "some-spec-path"}),
+		)
+
+		Context("when --key-passphrase is provided", func() {
+			var encryptedKeyPath string
+
+			BeforeEach(func() {
+				block, _ := pem.Decode([]byte(testhelpers.BBL_KEY))
+				encryptedBlock, err := x509.EncryptPEMBlock(rand.Reader, block.Type, block.Bytes, []byte("some-passphrase"), x509.PEMCipherAES256)
+				Expect(err).NotTo(HaveOccurred())
+
+				encryptedKeyPath, err = testhelpers.WriteContentsToTempFile(string(pem.EncodeToMemory(encryptedBlock)))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("passes the decrypted key to the iaas specific command and removes it afterwards", func() {
+				var decryptedKey []byte
+				awsCreateLBs.ExecuteCall.Stub = func(config commands.AWSCreateLBsConfig, state storage.State) error {
+					var err error
+					decryptedKey, err = ioutil.ReadFile(config.KeyPath)
+					return err
+				}
+
+				err := command.Execute([]string{
+					"--type", "cf",
+					"--cert", "some-cert",
+					"--key", encryptedKeyPath,
+					"--key-passphrase", "some-passphrase",
+				}, storage.State{IAAS: "aws"})
+				Expect(err).NotTo(HaveOccurred())
+
+				keyPath := awsCreateLBs.ExecuteCall.Receives.Config.KeyPath
+				Expect(keyPath).NotTo(Equal(encryptedKeyPath))
+				Expect(decryptedKey).To(Equal([]byte(testhelpers.BBL_KEY + "\n")))
+
+				_, err = os.Stat(filepath.Dir(keyPath))
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+
+			It("returns an error when the passphrase is wrong", func() {
+				err := command.Execute([]string{
+					"--type", "cf",
+					"--cert", "some-cert",
+					"--key", encryptedKeyPath,
+					"--key-passphrase", "other-passphrase",
+				}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError(ContainSubstring("failed to decrypt private key: x509: decryption password incorrect")))
+				Expect(awsCreateLBs.ExecuteCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error without --key", func() {
+				err := command.Execute([]string{"--type", "concourse", "--key-passphrase", "some-passphrase"}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError("--key-passphrase requires --key"))
+			})
+		})
+
+		Context("when --generate-cert is provided", func() {
+			BeforeEach(func() {
+				certGenerator.GenerateCall.Returns.State = storage.State{
+					IAAS: "aws",
+					LBCerts: storage.LBCerts{
+						CA: "some-ca",
+					},
+				}
+				certGenerator.GenerateCall.Returns.CertBundle = commands.CertBundle{
+					CertPath:  "generated-cert",
+					KeyPath:   "generated-key",
+					ChainPath: "generated-chain",
+				}
+			})
+
+			It("generates a self-signed certificate for the domain", func() {
+				err := command.Execute([]string{
+					"--type", "cf",
+					"--name", "foundation",
+					"--generate-cert",
+					"--domain", "example.com",
+				}, storage.State{IAAS: "aws"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certGenerator.GenerateCall.Receives.State).To(Equal(storage.State{IAAS: "aws"}))
+				Expect(certGenerator.GenerateCall.Receives.Cert).To(Equal(storage.LBGeneratedCert{
+					LB:     "foundation",
+					Mode:   commands.SelfSignedCertMode,
+					Domain: "example.com",
+				}))
+
+				Expect(awsCreateLBs.ExecuteCall.Receives.Config.CertPath).To(Equal("generated-cert"))
+				Expect(awsCreateLBs.ExecuteCall.Receives.Config.KeyPath).To(Equal("generated-key"))
+				Expect(awsCreateLBs.ExecuteCall.Receives.Config.ChainPath).To(Equal("generated-chain"))
+				Expect(awsCreateLBs.ExecuteCall.Receives.State.LBCerts.CA).To(Equal("some-ca"))
+			})
+
+			It("removes the generated certificate files once the lb is created", func() {
+				dir, err := ioutil.TempDir("", "")
+				Expect(err).NotTo(HaveOccurred())
+				certGenerator.GenerateCall.Returns.CertBundle.Dir = dir
+
+				err = command.Execute([]string{"--type", "cf", "--generate-cert", "--domain", "example.com"}, storage.State{IAAS: "aws"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(awsCreateLBs.ExecuteCall.CallCount).To(Equal(1))
+				_, err = os.Stat(dir)
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+
+			It("issues the certificate through acme when --acme-email is provided", func() {
+				err := command.Execute([]string{
+					"--type", "cf",
+					"--generate-cert",
+					"--domain", "example.com",
+					"--parent-zone", "some-parent-zone",
+					"--acme-email", "some-email",
+					"--acme-directory", "some-directory",
+				}, storage.State{IAAS: "gcp"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certGenerator.GenerateCall.Receives.State.LBCerts.ACMEEmail).To(Equal("some-email"))
+				Expect(certGenerator.GenerateCall.Receives.State.LBCerts.ACMEDirectoryURL).To(Equal("some-directory"))
+				Expect(certGenerator.GenerateCall.Receives.Cert).To(Equal(storage.LBGeneratedCert{
+					Mode:   commands.ACMECertMode,
+					Domain: "example.com",
+				}))
+			})
+
+			It("has the lb forget a previously generated certificate once it is created when a certificate is provided", func() {
+				generatedCerts := []storage.LBGeneratedCert{{LB: "foundation", Mode: commands.SelfSignedCertMode}}
+				err := command.Execute([]string{
+					"--type", "cf",
+					"--name", "foundation",
+					"--cert", "some-cert",
+					"--key", "some-key",
+				}, storage.State{
+					IAAS: "aws",
+					LBCerts: storage.LBCerts{
+						GeneratedCerts: generatedCerts,
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certGenerator.GenerateCall.CallCount).To(Equal(0))
+				Expect(awsCreateLBs.ExecuteCall.Receives.Config.ForgetGeneratedCert).To(BeTrue())
+				Expect(awsCreateLBs.ExecuteCall.Receives.State.LBCerts.GeneratedCerts).To(Equal(generatedCerts))
+			})
+
+			It("returns an error when the certificate cannot be generated", func() {
+				certGenerator.GenerateCall.Returns.Error = errors.New("failed to generate")
+
+				err := command.Execute([]string{"--type", "cf", "--generate-cert", "--domain", "example.com"}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError("failed to generate"))
+				Expect(awsCreateLBs.ExecuteCall.CallCount).To(Equal(0))
+			})
+
+			DescribeTable("flag errors",
+				func(args []string, message string) {
+					err := command.Execute(args, storage.State{IAAS: "aws"})
+					Expect(err).To(MatchError(message))
+				},
+				Entry("with --cert", []string{"--type", "cf", "--generate-cert", "--domain", "example.com", "--cert", "some-cert"},
+					"--generate-cert cannot be used with --cert, --key, --chain or --key-passphrase"),
+				Entry("without --domain", []string{"--type", "cf", "--generate-cert"},
+					"--generate-cert requires --domain, the certificate is generated for the domain and its subdomains"),
+				Entry("--acme-email without --generate-cert", []string{"--type", "cf", "--acme-email", "some-email"},
+					"--acme-email and --acme-directory require --generate-cert"),
+				Entry("--acme-directory without --acme-email", []string{"--type", "cf", "--generate-cert", "--domain", "example.com", "--acme-directory", "some-directory"},
+					"--acme-directory requires --acme-email"),
+			)
+
+			It("returns an error when --acme-email is used on aws", func() {
+				err := command.Execute([]string{"--type", "cf", "--generate-cert", "--domain", "example.com", "--acme-email", "some-email"}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError("--acme-email is only supported on gcp, bbl does not create a route53 zone for the domain of aws lbs"))
+				Expect(certGenerator.GenerateCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when --acme-email is used without --parent-zone", func() {
+				err := command.Execute([]string{"--type", "cf", "--generate-cert", "--domain", "example.com", "--acme-email", "some-email"}, storage.State{IAAS: "gcp"})
+				Expect(err).To(MatchError("--acme-email requires --parent-zone, the acme server resolves the domain through the delegation from the public parent zone"))
+				Expect(certGenerator.GenerateCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when acm is used", func() {
+			const certificateARN = "arn:aws:acm:us-east-1:123456789012:certificate/some-id"
+
+			It("passes --aws-acm to the aws command", func() {
+				err := command.Execute([]string{"--type", "cf", "--cert", "some-cert", "--key", "some-key", "--aws-acm"}, storage.State{IAAS: "aws"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(awsCreateLBs.ExecuteCall.Receives.Config.ACM).To(BeTrue())
+			})
+
+			It("passes the arn of --aws-acm-cert-arn to the aws command", func() {
+				err := command.Execute([]string{"--type", "cf", "--aws-acm-cert-arn", certificateARN}, storage.State{IAAS: "aws"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(awsCreateLBs.ExecuteCall.Receives.Config.ACMCertificateARN).To(Equal(certificateARN))
+				Expect(awsCreateLBs.ExecuteCall.Receives.Config.CertPath).To(BeEmpty())
+			})
+
+			DescribeTable("flag errors",
+				func(args []string, iaas, message string) {
+					err := command.Execute(args, storage.State{IAAS: iaas})
+					Expect(err).To(MatchError(message))
+					Expect(awsCreateLBs.ExecuteCall.CallCount).To(Equal(0))
+				},
+				Entry("--aws-acm-cert-arn with --cert", []string{"--type", "cf", "--aws-acm-cert-arn", certificateARN, "--cert", "some-cert"}, "aws",
+					"--aws-acm-cert-arn cannot be used with --cert, --key, --chain, --key-passphrase, --generate-cert or --aws-acm"),
+				Entry("--aws-acm-cert-arn with --aws-acm", []string{"--type", "cf", "--aws-acm-cert-arn", certificateARN, "--aws-acm"}, "aws",
+					"--aws-acm-cert-arn cannot be used with --cert, --key, --chain, --key-passphrase, --generate-cert or --aws-acm"),
+				Entry("an iam arn", []string{"--type", "cf", "--aws-acm-cert-arn", "arn:aws:iam::123456789012:server-certificate/some-cert"}, "aws",
+					`"arn:aws:iam::123456789012:server-certificate/some-cert" is not the arn of an acm certificate`),
+				Entry("on gcp", []string{"--type", "cf", "--cert", "some-cert", "--key", "some-key", "--aws-acm"}, "gcp",
+					"--aws-acm and --aws-acm-cert-arn are only supported on aws"),
+			)
+
+			It("returns an error on environments created with --terraform", func() {
+				err := command.Execute([]string{"--type", "cf", "--aws-acm-cert-arn", certificateARN}, storage.State{IAAS: "aws", TFState: "some-tf-state"})
+				Expect(err).To(MatchError("--aws-acm and --aws-acm-cert-arn are not supported on environments created with --terraform, their lbs serve iam server certificates"))
+				Expect(awsCreateLBs.ExecuteCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when dns flags are provided", func() {
+			var recordsPath string
+
+			writeRecords := func(contents string) {
+				recordsFile, err := ioutil.TempFile("", "dns-records")
+				Expect(err).NotTo(HaveOccurred())
+				defer recordsFile.Close()
+
+				_, err = recordsFile.WriteString(contents)
+				Expect(err).NotTo(HaveOccurred())
+
+				recordsPath = recordsFile.Name()
+			}
+
+			AfterEach(func() {
+				os.Remove(recordsPath)
+			})
+
+			It("passes the parent zone and the records to the gcp command", func() {
+				writeRecords(`- name: "@"
+  type: mx
+  ttl: 3600
+  records: ["10 mail.example.com."]
+- name: www
+  type: CNAME
+  records: [router.example.com.]
+`)
+
+				err := command.Execute([]string{"--type", "cf", "--cert", "some-cert", "--key", "some-key", "--domain", "cf.example.com",
+					"--parent-zone", "example-com", "--dns-records", recordsPath}, storage.State{IAAS: "gcp"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(gcpCreateLBs.ExecuteCall.Receives.Config.ParentZone).To(Equal("example-com"))
+				Expect(gcpCreateLBs.ExecuteCall.Receives.Config.DNSRecords).To(Equal([]storage.DNSRecord{
+					{Name: "@", Type: "MX", TTL: 3600, Records: []string{"10 mail.example.com."}},
+					{Name: "www", Type: "CNAME", TTL: 300, Records: []string{"router.example.com."}},
+				}))
+			})
+
+			It("returns an error on aws", func() {
+				err := command.Execute([]string{"--type", "cf", "--cert", "some-cert", "--key", "some-key", "--parent-zone", "example-com"}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError("--parent-zone and --dns-records are only supported on gcp, bbl does not create a route53 zone for the domain of aws lbs"))
+				Expect(awsCreateLBs.ExecuteCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the parent zone name is invalid", func() {
+				err := command.Execute([]string{"--type", "cf", "--parent-zone", "example.com."}, storage.State{IAAS: "gcp"})
+				Expect(err).To(MatchError(`"example.com." is not a valid dns zone name, zone names start with a letter and contain only lowercase letters, numbers and dashes`))
+			})
+
+			It("returns an error when the records cannot be read", func() {
+				err := command.Execute([]string{"--type", "cf", "--dns-records", "/some/missing/records"}, storage.State{IAAS: "gcp"})
+				Expect(err).To(MatchError(ContainSubstring("failed to read dns records: ")))
+			})
+
+			DescribeTable("returns an error when the records are invalid", func(records, expectedError string) {
+				writeRecords(records)
+
+				err := command.Execute([]string{"--type", "cf", "--dns-records", recordsPath}, storage.State{IAAS: "gcp"})
+				Expect(err).To(MatchError(expectedError))
+				Expect(gcpCreateLBs.ExecuteCall.CallCount).To(Equal(0))
+			},
+				Entry("that cannot be parsed", "name: www", "failed to parse dns records: yaml: unmarshal errors:\n  line 1: cannot unmarshal !!map into []commands.dnsRecordFile"),
+				Entry("without a name", "[{type: A, records: [10.0.0.1]}]", `dns record name is required, use "@" for the zone itself`),
+				Entry("with an absolute name", "[{name: www.example.com.com/, type: A, records: [10.0.0.1]}]",
+					`dns record name "www.example.com.com/" must be relative to the zone and contain only letters, numbers, dashes and dots`),
+				Entry("with a name managed by bbl", "[{name: ssh, type: A, records: [10.0.0.1]}]", `dns record "ssh" is managed by bbl`),
+				Entry("with an invalid type", "[{name: www, type: PTR, records: [10.0.0.1]}]",
+					`dns record "www" has invalid type "PTR", valid types are: A, AAAA, CAA, CNAME, MX, NS, SRV, TXT`),
+				Entry("with a CNAME at the apex", `[{name: "@", type: CNAME, records: [example.com.]}]`, `dns record "@" cannot be of type CNAME, the zone has its own`),
+				Entry("without records", "[{name: www, type: A}]", `dns record "www" must have at least one value in records`),
+				Entry("declared twice", "[{name: www, type: A, records: [10.0.0.1]}, {name: www, type: a, records: [10.0.0.2]}]",
+					`dns record "www" of type A is declared twice, list all of its values under records`),
+			)
+		})
+
+		Context("when --spec is provided", func() {
+			var specPath string
+
+			writeSpec := func(contents string) {
+				specFile, err := ioutil.TempFile("", "lb-spec")
+				Expect(err).NotTo(HaveOccurred())
+				defer specFile.Close()
+
+				_, err = specFile.WriteString(contents)
+				Expect(err).NotTo(HaveOccurred())
+
+				specPath = specFile.Name()
+			}
+
+			AfterEach(func() {
+				os.Remove(specPath)
+			})
+
+			It("passes the spec with defaults applied to the iaas specific command", func() {
+				writeSpec(`name: vault
+ports:
+- port: 8200
+`)
+
+				err := command.Execute([]string{"--spec", specPath}, storage.State{IAAS: "gcp"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(gcpCreateLBs.ExecuteCall.Receives.Config).To(Equal(commands.GCPCreateLBsConfig{
+					Spec: storage.LBSpec{
+						Name:        "vault",
+						Ports:       []storage.LBPort{{Port: 8200, InstancePort: 8200, Protocol: "tcp"}},
+						HealthCheck: storage.LBHealthCheck{Protocol: "tcp", Port: 8200},
+					},
+					ForgetGeneratedCert: true,
+				}))
+			})
+
+			It("uses the certificate paths of the spec", func() {
+				writeSpec(`name: credhub-uaa
+ports:
+- port: 443
+  instance_port: 8844
+  protocol: ssl
+health_check:
+  protocol: https
+  port: 8844
+cert: spec-cert
+key: spec-key
+chain: spec-chain
+`)
+
+				err := command.Execute([]string{"--spec", specPath, "--cert", "my-cert"}, storage.State{IAAS: "aws"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(awsCreateLBs.ExecuteCall.Receives.Config).To(Equal(commands.AWSCreateLBsConfig{
+					CertPath:  "spec-cert",
+					KeyPath:   "spec-key",
+					ChainPath: "spec-chain",
+					Spec: storage.LBSpec{
+						Name:        "credhub-uaa",
+						Ports:       []storage.LBPort{{Port: 443, InstancePort: 8844, Protocol: "ssl"}},
+						HealthCheck: storage.LBHealthCheck{Protocol: "https", Port: 8844, Path: "/"},
+					},
+					ForgetGeneratedCert: true,
+				}))
+			})
+
+			It("returns an error when --type is also provided", func() {
+				err := command.Execute([]string{"--spec", "some-spec", "--type", "cf"}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError("--type and --spec cannot be used together"))
+			})
+
+			It("returns an error when the spec cannot be read", func() {
+				err := command.Execute([]string{"--spec", "/some/missing/spec"}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError(ContainSubstring("failed to read lb spec: ")))
+			})
+
+			It("returns an error when the spec cannot be parsed", func() {
+				writeSpec("%%%")
+
+				err := command.Execute([]string{"--spec", specPath}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError(ContainSubstring("failed to parse lb spec: ")))
+			})
+
+			DescribeTable("returns an error when the spec is invalid", func(spec, expectedError string) {
+				writeSpec(spec)
+
+				err := command.Execute([]string{"--spec", specPath}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError(expectedError))
+				Expect(awsCreateLBs.ExecuteCall.CallCount).To(Equal(0))
+			},
+				Entry("without a name", "ports: [{port: 80}]", "lb spec name is required"),
+				Entry("with an invalid name", "{name: Vault_1, ports: [{port: 80}]}",
+					`lb spec name "Vault_1" must start with a letter and contain only lowercase letters, numbers and single dashes`),
+				Entry("with a long name", "{name: some-very-long-name, ports: [{port: 80}]}",
+					`lb spec name "some-very-long-name" must be at most 16 characters`),
+				Entry("with a reserved name", "{name: concourse, ports: [{port: 80}]}", `lb spec name "concourse" is reserved`),
+				Entry("without ports", "name: vault", `lb spec "vault" must have at least one port`),
+				Entry("with an invalid port", "{name: vault, ports: [{port: 70000}]}", `lb spec "vault" has invalid port 70000`),
+				Entry("with an invalid instance port", "{name: vault, ports: [{port: 80, instance_port: -1}]}", `lb spec "vault" has invalid instance port -1`),
+				Entry("with an invalid protocol", "{name: vault, ports: [{port: 80, protocol: udp}]}",
+					`lb spec "vault" has invalid protocol "udp", valid protocols are: tcp, ssl, http, https`),
+				Entry("with a duplicate port", "{name: vault, ports: [{port: 80}, {port: 80, instance_port: 8080}]}", `lb spec "vault" has duplicate port 80`),
+				Entry("with an invalid health check protocol", "{name: vault, ports: [{port: 80}], health_check: {protocol: udp}}",
+					`lb spec "vault" has invalid health check protocol "udp", valid protocols are: tcp, ssl, http, https`),
+				Entry("with an invalid health check port", "{name: vault, ports: [{port: 80}], health_check: {port: 70000}}",
+					`lb spec "vault" has invalid health check port 70000`),
+				Entry("with a tcp health check path", "{name: vault, ports: [{port: 80}], health_check: {path: /health}}",
+					`lb spec "vault" health check path is only supported for http and https`),
+			)
+		})
+
 		Context("failure cases", func() {
 			It("returns an error when state validator fails", func() {
 				stateValidator.ValidateCall.Returns.Error = errors.New("state validator failed")
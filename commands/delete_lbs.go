@@ -16,11 +16,11 @@ type DeleteLBs struct {
 }
 
 type gcpDeleteLBs interface {
-	Execute(state storage.State) error
+	Execute(name string, state storage.State) error
 }
 
 type awsDeleteLBs interface {
-	Execute(state storage.State) error
+	Execute(name string, state storage.State) error
 }
 
 func NewDeleteLBs(gcpDeleteLBs gcpDeleteLBs, awsDeleteLBs awsDeleteLBs,
@@ -52,16 +52,24 @@ func (d DeleteLBs) Execute(subcommandFlags []string, state storage.State) error
 		}
 	}
 
-	if config.skipIfMissing && !lbExists(state.Stack.LBType) && !lbExists(state.LB.Type) {
+	if config.name != "" {
+		if _, ok := findLBSpec(state.LBs, config.name); !ok {
+			if config.skipIfMissing {
+				d.logger.Println(fmt.Sprintf("lb %q does not exist, skipping...", config.name))
+				return nil
+			}
+			return fmt.Errorf("no load balancer named %q has been found for this bbl environment", config.name)
+		}
+	} else if config.skipIfMissing && !lbExists(state.Stack.LBType) && !lbExists(state.LB.Type) && len(state.LBs) == 0 {
 		d.logger.Println("no lb type exists, skipping...")
 		return nil
 	}
 
 	switch state.IAAS {
 	case "gcp":
-		return d.gcpDeleteLBs.Execute(state)
+		return d.gcpDeleteLBs.Execute(config.name, state)
 	case "aws":
-		return d.awsDeleteLBs.Execute(state)
+		return d.awsDeleteLBs.Execute(config.name, state)
 	default:
 		return fmt.Errorf("%q is an invalid iaas type in state, supported iaas types are: [gcp, aws]", state.IAAS)
 	}
@@ -73,6 +81,7 @@ func (DeleteLBs) parseFlags(subcommandFlags []string) (deleteLBsConfig, error) {
 	lbFlags := flags.New("delete-lbs")
 
 	config := deleteLBsConfig{}
+	lbFlags.String(&config.name, "name", "")
 	lbFlags.Bool(&config.skipIfMissing, "skip-if-missing", "", false)
 
 	err := lbFlags.Parse(subcommandFlags)
@@ -102,6 +102,37 @@ var _ = Describe("DeleteLBs", func() {
 			})
 		})
 
+		Context("when --name is provided", func() {
+			It("passes the name to the iaas specific command", func() {
+				err := command.Execute([]string{"--name", "vault"}, storage.State{
+					IAAS: "gcp",
+					LBs:  []storage.LBSpec{{Name: "vault"}},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(gcpDeleteLBs.ExecuteCall.Receives.Name).To(Equal("vault"))
+			})
+
+			It("returns an error when the named lb does not exist", func() {
+				err := command.Execute([]string{"--name", "vault"}, storage.State{
+					IAAS: "aws",
+					Stack: storage.Stack{
+						LBType: "concourse",
+					},
+				})
+				Expect(err).To(MatchError(`no load balancer named "vault" has been found for this bbl environment`))
+				Expect(awsDeleteLBs.ExecuteCall.CallCount).To(Equal(0))
+			})
+
+			It("no-ops when the named lb does not exist and --skip-if-missing is provided", func() {
+				err := command.Execute([]string{"--name", "vault", "--skip-if-missing"}, storage.State{IAAS: "aws"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.PrintlnCall.Receives.Message).To(Equal(`lb "vault" does not exist, skipping...`))
+				Expect(awsDeleteLBs.ExecuteCall.CallCount).To(Equal(0))
+			})
+		})
+
 		Context("when --skip-if-missing is provided", func() {
 			DescribeTable("no-ops", func(state storage.State) {
 				err := command.Execute([]string{
@@ -150,6 +181,10 @@ var _ = Describe("DeleteLBs", func() {
 						Type: "concourse",
 					},
 				}),
+				Entry("deletes the LBs when only LB specs exist in state", storage.State{
+					IAAS: "aws",
+					LBs:  []storage.LBSpec{{Name: "vault"}},
+				}),
 			)
 		})
 
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
+	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
 	"github.com/cloudfoundry/bosh-bootloader/bosh"
 	"github.com/cloudfoundry/bosh-bootloader/flags"
 	"github.com/cloudfoundry/bosh-bootloader/helpers"
@@ -22,6 +23,7 @@ type Destroy struct {
 	logger                  logger
 	stdin                   io.Reader
 	boshManager             boshManager
+	cloudConfigManager      cloudConfigManager
 	vpcStatusChecker        vpcStatusChecker
 	stackManager            stackManager
 	stringGenerator         stringGenerator
@@ -33,6 +35,7 @@ type Destroy struct {
 	stateValidator          stateValidator
 	terraformManager        terraformManager
 	networkInstancesChecker networkInstancesChecker
+	awsPermissionChecker    awsPermissionChecker
 }
 
 type destroyConfig struct {
@@ -73,15 +76,16 @@ type networkInstancesChecker interface {
 }
 
 func NewDestroy(credentialValidator credentialValidator, logger logger, stdin io.Reader,
-	boshManager boshManager, vpcStatusChecker vpcStatusChecker, stackManager stackManager,
+	boshManager boshManager, cloudConfigManager cloudConfigManager, vpcStatusChecker vpcStatusChecker, stackManager stackManager,
 	stringGenerator stringGenerator, infrastructureManager infrastructureManager, awsKeyPairDeleter awsKeyPairDeleter,
 	gcpKeyPairDeleter gcpKeyPairDeleter, certificateDeleter certificateDeleter, stateStore stateStore, stateValidator stateValidator,
-	terraformManager terraformManager, networkInstancesChecker networkInstancesChecker) Destroy {
+	terraformManager terraformManager, networkInstancesChecker networkInstancesChecker, awsPermissionChecker awsPermissionChecker) Destroy {
 	return Destroy{
 		credentialValidator:     credentialValidator,
 		logger:                  logger,
 		stdin:                   stdin,
 		boshManager:             boshManager,
+		cloudConfigManager:      cloudConfigManager,
 		vpcStatusChecker:        vpcStatusChecker,
 		stackManager:            stackManager,
 		stringGenerator:         stringGenerator,
@@ -93,6 +97,7 @@ func NewDestroy(credentialValidator credentialValidator, logger logger, stdin io
 		stateValidator:          stateValidator,
 		terraformManager:        terraformManager,
 		networkInstancesChecker: networkInstancesChecker,
+		awsPermissionChecker:    awsPermissionChecker,
 	}
 }
 
@@ -131,6 +136,13 @@ func (d Destroy) Execute(subcommandFlags []string, state storage.State) error {
 		return err
 	}
 
+	if state.IAAS == "aws" {
+		err = preflightAWS(d.awsPermissionChecker, iam.DestroyActions(usesACM(state)), d.logger)
+		if err != nil {
+			return err
+		}
+	}
+
 	var terraformOutputs map[string]interface{}
 	if state.IAAS == "gcp" {
 		terraformOutputs, err = d.terraformManager.GetOutputs(state)
@@ -287,6 +299,12 @@ func (d Destroy) deleteBOSH(state storage.State, stack cloudformation.Stack) (st
 		return state, nil
 	}
 
+	// The director is about to be deleted, so failing to clean up its configs
+	// should not stop the environment from being destroyed.
+	if err := d.cloudConfigManager.Delete(state); err != nil {
+		d.logger.Println(fmt.Sprintf("failed to delete cloud config and runtime config: %s", err))
+	}
+
 	d.logger.Step("destroying bosh director")
 
 	err := d.boshManager.Delete(state)
@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
+	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
 	"github.com/cloudfoundry/bosh-bootloader/bosh"
 	"github.com/cloudfoundry/bosh-bootloader/commands"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
@@ -22,6 +23,7 @@ var _ = Describe("Destroy", func() {
 	var (
 		destroy                 commands.Destroy
 		boshManager             *fakes.BOSHManager
+		cloudConfigManager      *fakes.CloudConfigManager
 		stackManager            *fakes.StackManager
 		infrastructureManager   *fakes.InfrastructureManager
 		vpcStatusChecker        *fakes.VPCStatusChecker
@@ -36,6 +38,7 @@ var _ = Describe("Destroy", func() {
 		terraformManager        *fakes.TerraformManager
 		terraformManagerError   *fakes.TerraformManagerError
 		networkInstancesChecker *fakes.NetworkInstancesChecker
+		awsPermissionChecker    *fakes.AWSPermissionChecker
 		stdin                   *bytes.Buffer
 	)
 
@@ -48,6 +51,7 @@ var _ = Describe("Destroy", func() {
 		infrastructureManager = &fakes.InfrastructureManager{}
 		boshManager = &fakes.BOSHManager{}
 		boshManager.VersionCall.Returns.Version = "2.0.0"
+		cloudConfigManager = &fakes.CloudConfigManager{}
 		awsKeyPairDeleter = &fakes.AWSKeyPairDeleter{}
 		gcpKeyPairDeleter = &fakes.GCPKeyPairDeleter{}
 		certificateDeleter = &fakes.CertificateDeleter{}
@@ -58,11 +62,12 @@ var _ = Describe("Destroy", func() {
 		terraformManager = &fakes.TerraformManager{}
 		terraformManagerError = &fakes.TerraformManagerError{}
 		networkInstancesChecker = &fakes.NetworkInstancesChecker{}
+		awsPermissionChecker = &fakes.AWSPermissionChecker{}
 
-		destroy = commands.NewDestroy(credentialValidator, logger, stdin, boshManager,
+		destroy = commands.NewDestroy(credentialValidator, logger, stdin, boshManager, cloudConfigManager,
 			vpcStatusChecker, stackManager, stringGenerator, infrastructureManager,
 			awsKeyPairDeleter, gcpKeyPairDeleter, certificateDeleter, stateStore,
-			stateValidator, terraformManager, networkInstancesChecker)
+			stateValidator, terraformManager, networkInstancesChecker, awsPermissionChecker)
 	})
 
 	Describe("Execute", func() {
@@ -109,6 +114,39 @@ var _ = Describe("Destroy", func() {
 			Expect(err).To(MatchError("credentials validator failed"))
 		})
 
+		It("returns an error before the confirmation when the aws credentials are missing permissions", func() {
+			awsPermissionChecker.MissingActionsCall.Returns.Missing = []string{"ec2:TerminateInstances"}
+
+			err := destroy.Execute([]string{}, storage.State{IAAS: "aws", EnvID: "some-lake"})
+			Expect(err).To(MatchError(ContainSubstring("missing permissions required by bbl and the BOSH AWS CPI:\n  ec2:TerminateInstances\n")))
+
+			Expect(awsPermissionChecker.MissingActionsCall.Receives.Actions).To(Equal(iam.DestroyActions(false)))
+			Expect(logger.PromptCall.CallCount).To(Equal(0))
+		})
+
+		It("checks the acm actions when an lb serves an acm certificate", func() {
+			awsPermissionChecker.MissingActionsCall.Returns.Missing = []string{"acm:DeleteCertificate"}
+
+			err := destroy.Execute([]string{}, storage.State{
+				IAAS:  "aws",
+				EnvID: "some-lake",
+				LBs: []storage.LBSpec{{
+					Name:           "some-lb",
+					CertificateARN: "arn:aws:acm:some-region:some-account:certificate/some-id",
+				}},
+			})
+			Expect(err).To(MatchError(ContainSubstring("acm:DeleteCertificate")))
+
+			Expect(awsPermissionChecker.MissingActionsCall.Receives.Actions).To(Equal(iam.DestroyActions(true)))
+		})
+
+		It("does not check aws permissions on gcp", func() {
+			err := destroy.Execute([]string{"--no-confirm"}, storage.State{IAAS: "gcp", NoDirector: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(awsPermissionChecker.MissingActionsCall.CallCount).To(Equal(0))
+		})
+
 		DescribeTable("prompting the user for confirmation",
 			func(response string, proceed bool) {
 				fmt.Fprintf(stdin, "%s\n", response)
@@ -441,6 +479,24 @@ var _ = Describe("Destroy", func() {
 					Expect(logger.StepCall.Messages).To(ContainElement("destroying bosh director"))
 				})
 
+				It("deletes the bbl cloud config and runtime config before deleting the director", func() {
+					err := destroy.Execute([]string{}, state)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(cloudConfigManager.DeleteCall.CallCount).To(Equal(1))
+					Expect(cloudConfigManager.DeleteCall.Receives.State.BOSH).To(Equal(state.BOSH))
+				})
+
+				It("continues destroying when the configs cannot be deleted", func() {
+					cloudConfigManager.DeleteCall.Returns.Error = errors.New("failed to delete configs")
+
+					err := destroy.Execute([]string{}, state)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(logger.PrintlnCall.Messages).To(ContainElement("failed to delete cloud config and runtime config: failed to delete configs"))
+					Expect(boshManager.DeleteCall.CallCount).To(Equal(1))
+				})
+
 				Context("reentrance", func() {
 					Context("when the stack fails to delete", func() {
 						It("removes the bosh properties from state and returns an error", func() {
@@ -482,6 +538,7 @@ var _ = Describe("Destroy", func() {
 							Expect(logger.PrintlnCall.Receives.Message).To(Equal("no BOSH director, skipping..."))
 							Expect(logger.StepCall.Messages).NotTo(ContainElement("destroying bosh director"))
 							Expect(boshManager.DeleteCall.CallCount).To(Equal(0))
+							Expect(cloudConfigManager.DeleteCall.CallCount).To(Equal(0))
 						})
 					})
 
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+	gcpterraform "github.com/cloudfoundry/bosh-bootloader/terraform/gcp"
+)
+
+const DNSCommand = "dns"
+
+type DNS struct {
+	stateValidator   stateValidator
+	terraformManager terraformManager
+	renderer         renderer
+	stdout           io.Writer
+}
+
+type DNSOutput struct {
+	Zones []DNSZoneOutput `json:"zones" yaml:"zones"`
+}
+
+// DNSZoneOutput describes the dns zone created for the domain of a cf lb.
+// When ParentZone is empty, the operator delegates the domain by adding
+// its NameServers to the parent zone.
+type DNSZoneOutput struct {
+	LB          string   `json:"lb" yaml:"lb"`
+	Domain      string   `json:"domain" yaml:"domain"`
+	ParentZone  string   `json:"parent_zone,omitempty" yaml:"parent_zone,omitempty"`
+	NameServers []string `json:"name_servers" yaml:"name_servers"`
+}
+
+func NewDNS(stateValidator stateValidator, terraformManager terraformManager, renderer renderer, stdout io.Writer) DNS {
+	return DNS{
+		stateValidator:   stateValidator,
+		terraformManager: terraformManager,
+		renderer:         renderer,
+		stdout:           stdout,
+	}
+}
+
+func (d DNS) Execute(subcommandFlags []string, state storage.State) error {
+	err := d.stateValidator.Validate()
+	if err != nil {
+		return err
+	}
+
+	if state.IAAS != "gcp" {
+		return errors.New("bbl dns is only supported on gcp, aws lbs do not create a dns zone")
+	}
+
+	var zones []DNSZoneOutput
+	if state.LB.Type == "cf" && state.LB.Domain != "" {
+		zones = append(zones, DNSZoneOutput{
+			Domain:     state.LB.Domain,
+			ParentZone: state.LB.ParentZone,
+		})
+	}
+
+	for _, spec := range state.LBs {
+		if spec.Type == "cf" && spec.Domain != "" {
+			zones = append(zones, DNSZoneOutput{
+				LB:         spec.Name,
+				Domain:     spec.Domain,
+				ParentZone: spec.ParentZone,
+			})
+		}
+	}
+
+	if len(zones) == 0 {
+		return errors.New("no dns zones found, create-lbs --domain creates one")
+	}
+
+	terraformOutputs, err := d.terraformManager.GetOutputs(state)
+	if err != nil {
+		return err
+	}
+
+	for i, zone := range zones {
+		outputName := "system_domain_dns_servers"
+		if zone.LB != "" {
+			outputName = gcpterraform.LBSpecOutputName(zone.LB, outputName)
+		}
+
+		nameServers, ok := terraformOutputs[outputName].([]string)
+		if !ok {
+			return fmt.Errorf("the dns servers of %q are missing from the terraform outputs, run update-lbs to create the zone", zone.Domain)
+		}
+		zones[i].NameServers = nameServers
+	}
+
+	if d.renderer.Structured() {
+		return d.renderer.Render(DNSOutput{Zones: zones})
+	}
+
+	for i, zone := range zones {
+		if i > 0 {
+			fmt.Fprintln(d.stdout)
+		}
+
+		fmt.Fprintf(d.stdout, "%s\n", zone.Domain)
+		if zone.ParentZone != "" {
+			fmt.Fprintf(d.stdout, "  delegated from the %q zone through:\n", zone.ParentZone)
+		} else {
+			fmt.Fprintln(d.stdout, "  add these records to the parent zone to delegate the domain:")
+		}
+
+		domain := strings.TrimSuffix(zone.Domain, ".")
+		for _, nameServer := range zone.NameServers {
+			fmt.Fprintf(d.stdout, "  %s. IN NS %s\n", domain, nameServer)
+		}
+	}
+
+	return nil
+}
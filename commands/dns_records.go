@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const defaultDNSRecordTTL = 300
+
+var (
+	gcpZoneNameRegexp   = regexp.MustCompile(`^[a-z]([a-z0-9-]{0,61}[a-z0-9])?$`)
+	dnsRecordNameRegexp = regexp.MustCompile(`^(\*\.)?[a-z0-9_]([a-z0-9_-]*[a-z0-9])?(\.[a-z0-9_]([a-z0-9_-]*[a-z0-9])?)*$`)
+
+	// reservedDNSRecordNames are the records bbl keeps in the dns zone of
+	// the cf lb.
+	reservedDNSRecordNames = map[string]bool{
+		"*":           true,
+		"bosh":        true,
+		"ssh":         true,
+		"tcp":         true,
+		"doppler":     true,
+		"loggregator": true,
+		"*.ws":        true,
+	}
+)
+
+type dnsRecordFile struct {
+	Name    string   `yaml:"name"`
+	Type    string   `yaml:"type"`
+	TTL     int      `yaml:"ttl"`
+	Records []string `yaml:"records"`
+}
+
+// loadDNSRecords reads a YAML list of records for the dns zone of the lb.
+// Names are relative to the zone and the ttl defaults to 300 seconds.
+func loadDNSRecords(path string) ([]storage.DNSRecord, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dns records: %s", err)
+	}
+
+	var files []dnsRecordFile
+	err = yaml.Unmarshal(contents, &files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dns records: %s", err)
+	}
+
+	var records []storage.DNSRecord
+	seen := map[string]bool{}
+	for _, file := range files {
+		record := storage.DNSRecord{
+			Name:    strings.ToLower(strings.TrimSuffix(file.Name, ".")),
+			Type:    strings.ToUpper(file.Type),
+			TTL:     file.TTL,
+			Records: file.Records,
+		}
+		if record.TTL == 0 {
+			record.TTL = defaultDNSRecordTTL
+		}
+
+		err = validateDNSRecord(record)
+		if err != nil {
+			return nil, err
+		}
+
+		key := record.Name + " " + record.Type
+		if seen[key] {
+			return nil, fmt.Errorf("dns record %q of type %s is declared twice, list all of its values under records", record.Name, record.Type)
+		}
+		seen[key] = true
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func validateDNSRecord(record storage.DNSRecord) error {
+	if record.Name == "" {
+		return fmt.Errorf("dns record name is required, use %q for the zone itself", "@")
+	}
+
+	if record.Name != "@" && !dnsRecordNameRegexp.MatchString(record.Name) {
+		return fmt.Errorf("dns record name %q must be relative to the zone and contain only letters, numbers, dashes and dots", record.Name)
+	}
+
+	if reservedDNSRecordNames[record.Name] {
+		return fmt.Errorf("dns record %q is managed by bbl", record.Name)
+	}
+
+	if !isValidDNSRecordType(record.Type) {
+		return fmt.Errorf("dns record %q has invalid type %q, valid types are: A, AAAA, CAA, CNAME, MX, NS, SRV, TXT", record.Name, record.Type)
+	}
+
+	if record.Name == "@" && (record.Type == "CNAME" || record.Type == "NS") {
+		return fmt.Errorf("dns record %q cannot be of type %s, the zone has its own", record.Name, record.Type)
+	}
+
+	if record.TTL < 0 {
+		return fmt.Errorf("dns record %q has invalid ttl %d", record.Name, record.TTL)
+	}
+
+	if len(record.Records) == 0 {
+		return fmt.Errorf("dns record %q must have at least one value in records", record.Name)
+	}
+
+	return nil
+}
+
+func isValidDNSRecordType(recordType string) bool {
+	switch recordType {
+	case "A", "AAAA", "CAA", "CNAME", "MX", "NS", "SRV", "TXT":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,127 @@
+package commands_test
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DNS", func() {
+	var (
+		stateValidator   *fakes.StateValidator
+		terraformManager *fakes.TerraformManager
+		renderer         *fakes.Renderer
+		stdout           *bytes.Buffer
+		command          commands.DNS
+
+		state storage.State
+	)
+
+	BeforeEach(func() {
+		stateValidator = &fakes.StateValidator{}
+		terraformManager = &fakes.TerraformManager{}
+		renderer = &fakes.Renderer{}
+		stdout = bytes.NewBuffer([]byte{})
+
+		terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
+			"system_domain_dns_servers":               []string{"ns-cloud-a1.googledomains.com.", "ns-cloud-a2.googledomains.com."},
+			"lb_foundation_system_domain_dns_servers": []string{"ns-cloud-b1.googledomains.com."},
+		}
+
+		state = storage.State{
+			IAAS: "gcp",
+			LB: storage.LB{
+				Type:   "cf",
+				Domain: "cf.example.com",
+			},
+			LBs: []storage.LBSpec{
+				{Name: "foundation", Type: "cf", Domain: "foundation.example.com", ParentZone: "example-com"},
+				{Name: "concourse", Type: "concourse"},
+			},
+		}
+
+		command = commands.NewDNS(stateValidator, terraformManager, renderer, stdout)
+	})
+
+	Describe("Execute", func() {
+		It("prints the NS records of each dns zone", func() {
+			err := command.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stateValidator.ValidateCall.CallCount).To(Equal(1))
+			Expect(terraformManager.GetOutputsCall.Receives.BBLState).To(Equal(state))
+			Expect(stdout.String()).To(Equal(`cf.example.com
+  add these records to the parent zone to delegate the domain:
+  cf.example.com. IN NS ns-cloud-a1.googledomains.com.
+  cf.example.com. IN NS ns-cloud-a2.googledomains.com.
+
+foundation.example.com
+  delegated from the "example-com" zone through:
+  foundation.example.com. IN NS ns-cloud-b1.googledomains.com.
+`))
+		})
+
+		It("renders the zones with a structured output", func() {
+			renderer.StructuredCall.Returns.Structured = true
+
+			err := command.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(BeEmpty())
+			Expect(renderer.RenderCall.Receives.Value).To(Equal(commands.DNSOutput{
+				Zones: []commands.DNSZoneOutput{
+					{
+						Domain:      "cf.example.com",
+						NameServers: []string{"ns-cloud-a1.googledomains.com.", "ns-cloud-a2.googledomains.com."},
+					},
+					{
+						LB:          "foundation",
+						Domain:      "foundation.example.com",
+						ParentZone:  "example-com",
+						NameServers: []string{"ns-cloud-b1.googledomains.com."},
+					},
+				},
+			}))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the state validator fails", func() {
+				stateValidator.ValidateCall.Returns.Error = errors.New("state validator failed")
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError("state validator failed"))
+			})
+
+			It("returns an error on aws", func() {
+				err := command.Execute([]string{}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError("bbl dns is only supported on gcp, aws lbs do not create a dns zone"))
+			})
+
+			It("returns an error when no lb has a domain", func() {
+				err := command.Execute([]string{}, storage.State{IAAS: "gcp", LB: storage.LB{Type: "cf"}})
+				Expect(err).To(MatchError("no dns zones found, create-lbs --domain creates one"))
+				Expect(terraformManager.GetOutputsCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the terraform outputs cannot be read", func() {
+				terraformManager.GetOutputsCall.Returns.Error = errors.New("failed to get outputs")
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError("failed to get outputs"))
+			})
+
+			It("returns an error when the dns servers of a zone are missing", func() {
+				terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{}
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError(`the dns servers of "cf.example.com" are missing from the terraform outputs, run update-lbs to create the zone`))
+			})
+		})
+	})
+})
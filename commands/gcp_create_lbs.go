@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 
@@ -10,31 +11,47 @@ import (
 	"github.com/cloudfoundry/multierror"
 )
 
+const maxGCPCertificates = 15
+
 type GCPCreateLBs struct {
-	terraformManager   terraformManager
-	boshClientProvider boshClientProvider
-	cloudConfigManager cloudConfigManager
-	stateStore         stateStore
-	logger             logger
+	terraformManager    terraformManager
+	boshClientProvider  boshClientProvider
+	cloudConfigManager  cloudConfigManager
+	stateStore          stateStore
+	logger              logger
+	gcpPreflightChecker gcpPreflightChecker
 }
 
 type GCPCreateLBsConfig struct {
 	LBType       string
 	CertPath     string
 	KeyPath      string
+	SNICerts     []CertBundle
 	Domain       string
+	ParentZone   string
+	DNSRecords   []storage.DNSRecord
+	Spec         storage.LBSpec
 	SkipIfExists bool
+	Interactive  bool
+
+	// Update replaces the named lb of Spec instead of attaching a new one.
+	Update bool
+
+	// ForgetGeneratedCert drops the generated certificate bbl recorded for
+	// the lb once the lb is created with the certificate of CertPath.
+	ForgetGeneratedCert bool
 }
 
 func NewGCPCreateLBs(terraformManager terraformManager,
 	boshClientProvider boshClientProvider, cloudConfigManager cloudConfigManager,
-	stateStore stateStore, logger logger) GCPCreateLBs {
+	stateStore stateStore, logger logger, gcpPreflightChecker gcpPreflightChecker) GCPCreateLBs {
 	return GCPCreateLBs{
-		terraformManager:   terraformManager,
-		boshClientProvider: boshClientProvider,
-		cloudConfigManager: cloudConfigManager,
-		stateStore:         stateStore,
-		logger:             logger,
+		terraformManager:    terraformManager,
+		boshClientProvider:  boshClientProvider,
+		cloudConfigManager:  cloudConfigManager,
+		stateStore:          stateStore,
+		logger:              logger,
+		gcpPreflightChecker: gcpPreflightChecker,
 	}
 }
 
@@ -44,14 +61,23 @@ func (c GCPCreateLBs) Execute(config GCPCreateLBsConfig, state storage.State) er
 		return err
 	}
 
+	if config.Spec.Name != "" {
+		return c.createFromSpec(config, state)
+	}
+
 	err = c.checkFastFails(config, state)
 	if err != nil {
 		return err
 	}
 
+	err = checkSNISupport(storage.LBSpec{Type: config.LBType}, state.IAAS, config.SNICerts)
+	if err != nil {
+		return err
+	}
+
 	if !state.NoDirector {
 		boshClient := c.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername,
-			state.BOSH.DirectorPassword)
+			state.BOSH.DirectorPassword, state.BOSH.DirectorSSLCA)
 
 		_, err := boshClient.Info()
 		if err != nil {
@@ -69,6 +95,8 @@ func (c GCPCreateLBs) Execute(config GCPCreateLBsConfig, state storage.State) er
 	var cert, key []byte
 	if config.LBType == "cf" {
 		state.LB.Domain = config.Domain
+		state.LB.ParentZone = config.ParentZone
+		state.LB.DNSRecords = config.DNSRecords
 
 		cert, err = ioutil.ReadFile(config.CertPath)
 		if err != nil {
@@ -83,6 +111,21 @@ func (c GCPCreateLBs) Execute(config GCPCreateLBsConfig, state storage.State) er
 		}
 
 		state.LB.Key = string(key)
+
+		state.LB.SNICertificates, err = readSNICertificates(config.SNICerts)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = checkGCPLabelSupport(c.terraformManager, state)
+	if err != nil {
+		return err
+	}
+
+	err = preflightGCP(c.gcpPreflightChecker, state, c.logger)
+	if err != nil {
+		return err
 	}
 
 	state, err = c.terraformManager.Apply(state)
@@ -108,12 +151,16 @@ func (c GCPCreateLBs) Execute(config GCPCreateLBsConfig, state storage.State) er
 		return err
 	}
 
+	if config.ForgetGeneratedCert {
+		state.LBCerts.GeneratedCerts = forgetGeneratedCert(state.LBCerts.GeneratedCerts, config.Spec.Name)
+	}
+
 	if err := c.stateStore.Set(state); err != nil {
 		return err
 	}
 
 	if !state.NoDirector {
-		err = c.cloudConfigManager.Update(state)
+		err = updateCloudConfig(c.cloudConfigManager, state, config.Interactive)
 		if err != nil {
 			return err
 		}
@@ -122,6 +169,142 @@ func (c GCPCreateLBs) Execute(config GCPCreateLBsConfig, state storage.State) er
 	return nil
 }
 
+func (c GCPCreateLBs) createFromSpec(config GCPCreateLBsConfig, state storage.State) error {
+	spec := config.Spec
+
+	err := c.checkSpecFastFails(config, state)
+	if err != nil {
+		return err
+	}
+
+	err = checkSNISupport(spec, state.IAAS, config.SNICerts)
+	if err != nil {
+		return err
+	}
+
+	if !state.NoDirector {
+		boshClient := c.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername,
+			state.BOSH.DirectorPassword, state.BOSH.DirectorSSLCA)
+
+		_, err := boshClient.Info()
+		if err != nil {
+			return BBLNotFound
+		}
+	}
+
+	if _, ok := findLBSpec(state.LBs, spec.Name); ok && !config.Update {
+		if config.SkipIfExists {
+			c.logger.Step(fmt.Sprintf("lb %q exists, skipping...", spec.Name))
+			return nil
+		}
+		return fmt.Errorf("bbl already has a load balancer named %q attached, please remove it before attaching a new one", spec.Name)
+	}
+
+	if spec.Type == "cf" {
+		spec.Domain = config.Domain
+		spec.ParentZone = config.ParentZone
+		spec.DNSRecords = config.DNSRecords
+
+		cert, err := ioutil.ReadFile(config.CertPath)
+		if err != nil {
+			return err
+		}
+		spec.Cert = string(cert)
+
+		key, err := ioutil.ReadFile(config.KeyPath)
+		if err != nil {
+			return err
+		}
+		spec.Key = string(key)
+
+		spec.SNICertificates, err = readSNICertificates(config.SNICerts)
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.Update {
+		state.LBs = replaceLBSpec(state.LBs, spec)
+	} else {
+		state.LBs = append(append([]storage.LBSpec{}, state.LBs...), spec)
+	}
+
+	err = checkGCPLabelSupport(c.terraformManager, state)
+	if err != nil {
+		return err
+	}
+
+	err = preflightGCP(c.gcpPreflightChecker, state, c.logger)
+	if err != nil {
+		return err
+	}
+
+	state, err = c.terraformManager.Apply(state)
+	if err != nil {
+		return handleTerraformError(err, c.stateStore)
+	}
+
+	if config.ForgetGeneratedCert {
+		state.LBCerts.GeneratedCerts = forgetGeneratedCert(state.LBCerts.GeneratedCerts, config.Spec.Name)
+	}
+
+	if err := c.stateStore.Set(state); err != nil {
+		return err
+	}
+
+	if !state.NoDirector {
+		err = updateCloudConfig(c.cloudConfigManager, state, config.Interactive)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkSpecFastFails rejects what a gcp network load balancer cannot do.
+// Target pools only support legacy http health checks, so a tcp health
+// check leaves the pool without one and every instance counts as healthy.
+// Named cf and concourse lbs are generated by bbl and only need a cert and
+// key for cf.
+func (GCPCreateLBs) checkSpecFastFails(config GCPCreateLBsConfig, state storage.State) error {
+	spec := config.Spec
+
+	if state.IAAS != "gcp" {
+		return fmt.Errorf("iaas type must be gcp")
+	}
+
+	if spec.Type == "cf" {
+		if err := validateCertAndKeyFlags(config); err != nil {
+			return err
+		}
+	}
+
+	if err := validateDNSFlags(config, spec.Type); err != nil {
+		return err
+	}
+
+	if spec.Type != "" {
+		return nil
+	}
+
+	for _, port := range spec.Ports {
+		if port.Protocol != "tcp" {
+			return fmt.Errorf("lb spec %q has %s port %d, gcp lbs only support tcp ports", spec.Name, port.Protocol, port.Port)
+		}
+
+		if port.InstancePort != port.Port {
+			return fmt.Errorf("lb spec %q maps port %d to instance port %d, gcp lbs cannot change the port", spec.Name, port.Port, port.InstancePort)
+		}
+	}
+
+	if spec.HealthCheck.Protocol != "tcp" && spec.HealthCheck.Protocol != "http" {
+		return fmt.Errorf("lb spec %q has a %s health check, gcp lbs only support tcp and http health checks", spec.Name, spec.HealthCheck.Protocol)
+	}
+
+	return nil
+}
+
 func (GCPCreateLBs) checkFastFails(config GCPCreateLBsConfig, state storage.State) error {
 	if config.LBType == "" {
 		return fmt.Errorf("--type is a required flag")
@@ -132,26 +315,88 @@ func (GCPCreateLBs) checkFastFails(config GCPCreateLBsConfig, state storage.Stat
 	}
 
 	if config.LBType == "cf" {
-		errs := multierror.NewMultiError("create-lbs")
-		if err := validateCertOrKeyFlag("cert", config.CertPath); err != nil {
+		if err := validateCertAndKeyFlags(config); err != nil {
+			return err
+		}
+	}
+
+	if err := validateDNSFlags(config, config.LBType); err != nil {
+		return err
+	}
+
+	if state.IAAS != "gcp" {
+		return fmt.Errorf("iaas type must be gcp")
+	}
+
+	return nil
+}
+
+// validateCertAndKeyFlags checks the certificate and key of a cf lb and
+// of each of its SNI certificates. An https proxy serves at most
+// maxGCPCertificates certificates.
+func validateCertAndKeyFlags(config GCPCreateLBsConfig) error {
+	if len(config.SNICerts)+1 > maxGCPCertificates {
+		return fmt.Errorf("gcp https proxies serve at most %d certificates", maxGCPCertificates)
+	}
+
+	errs := multierror.NewMultiError("create-lbs")
+	bundles := append([]CertBundle{{CertPath: config.CertPath, KeyPath: config.KeyPath}}, config.SNICerts...)
+	for _, bundle := range bundles {
+		if err := validateCertOrKeyFlag("cert", bundle.CertPath); err != nil {
 			errs.Add(err)
 		}
-		if err := validateCertOrKeyFlag("key", config.KeyPath); err != nil {
+		if err := validateCertOrKeyFlag("key", bundle.KeyPath); err != nil {
 			errs.Add(err)
 		}
+	}
 
-		if errs.Length() > 0 {
-			return errs
-		}
+	if errs.Length() > 0 {
+		return errs
 	}
 
-	if state.IAAS != "gcp" {
-		return fmt.Errorf("iaas type must be gcp")
+	return nil
+}
+
+// validateDNSFlags checks that a parent zone or custom records come with
+// the dns zone that bbl creates for the domain of a cf lb.
+func validateDNSFlags(config GCPCreateLBsConfig, lbType string) error {
+	if config.ParentZone == "" && len(config.DNSRecords) == 0 {
+		return nil
+	}
+
+	if lbType != "cf" {
+		return errors.New("--parent-zone and --dns-records are only supported for cf lbs")
+	}
+
+	if config.Domain == "" {
+		return errors.New("--parent-zone and --dns-records require --domain")
 	}
 
 	return nil
 }
 
+func readSNICertificates(sniCerts []CertBundle) ([]storage.LBCertificate, error) {
+	var certificates []storage.LBCertificate
+	for _, bundle := range sniCerts {
+		cert, err := ioutil.ReadFile(bundle.CertPath)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := ioutil.ReadFile(bundle.KeyPath)
+		if err != nil {
+			return nil, err
+		}
+
+		certificates = append(certificates, storage.LBCertificate{
+			Cert: string(cert),
+			Key:  string(key),
+		})
+	}
+
+	return certificates, nil
+}
+
 func validateCertOrKeyFlag(flagName, path string) error {
 	if path == "" {
 		return fmt.Errorf("--%s is required", flagName)
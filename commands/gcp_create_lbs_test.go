@@ -23,6 +23,7 @@ var _ = Describe("GCPCreateLBs", func() {
 		cloudConfigManager     *fakes.CloudConfigManager
 		stateStore             *fakes.StateStore
 		logger                 *fakes.Logger
+		gcpPreflightChecker    *fakes.GCPPreflightChecker
 		terraformExecutorError *fakes.TerraformExecutorError
 
 		command     commands.GCPCreateLBs
@@ -40,9 +41,10 @@ var _ = Describe("GCPCreateLBs", func() {
 		cloudConfigManager = &fakes.CloudConfigManager{}
 		stateStore = &fakes.StateStore{}
 		logger = &fakes.Logger{}
+		gcpPreflightChecker = &fakes.GCPPreflightChecker{}
 		terraformExecutorError = &fakes.TerraformExecutorError{}
 
-		command = commands.NewGCPCreateLBs(terraformManager, boshClientProvider, cloudConfigManager, stateStore, logger)
+		command = commands.NewGCPCreateLBs(terraformManager, boshClientProvider, cloudConfigManager, stateStore, logger, gcpPreflightChecker)
 
 		tempCertFile, err := ioutil.TempFile("", "cert")
 		Expect(err).NotTo(HaveOccurred())
@@ -88,6 +90,37 @@ var _ = Describe("GCPCreateLBs", func() {
 					},
 				}))
 			})
+
+			It("stores the parent zone and the dns records of the domain", func() {
+				records := []storage.DNSRecord{{Name: "www", Type: "CNAME", TTL: 300, Records: []string{"router.example.com."}}}
+				err := command.Execute(commands.GCPCreateLBsConfig{
+					LBType:     "cf",
+					CertPath:   certPath,
+					KeyPath:    keyPath,
+					Domain:     "some-domain",
+					ParentZone: "some-parent-zone",
+					DNSRecords: records,
+				}, storage.State{
+					IAAS: "gcp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformManager.ApplyCall.Receives.BBLState.LB.ParentZone).To(Equal("some-parent-zone"))
+				Expect(terraformManager.ApplyCall.Receives.BBLState.LB.DNSRecords).To(Equal(records))
+			})
+
+			It("returns an error when a parent zone is provided without a domain", func() {
+				err := command.Execute(commands.GCPCreateLBsConfig{
+					LBType:     "cf",
+					CertPath:   certPath,
+					KeyPath:    keyPath,
+					ParentZone: "some-parent-zone",
+				}, storage.State{
+					IAAS: "gcp",
+				})
+				Expect(err).To(MatchError("--parent-zone and --dns-records require --domain"))
+				Expect(terraformManager.ApplyCall.CallCount).To(Equal(0))
+			})
 		})
 
 		Context("when lb type is concourse", func() {
@@ -106,6 +139,49 @@ var _ = Describe("GCPCreateLBs", func() {
 					},
 				}))
 			})
+
+			It("checks that the quotas can hold the lb before applying terraform", func() {
+				err := command.Execute(commands.GCPCreateLBsConfig{
+					LBType: "concourse",
+				}, storage.State{
+					IAAS: "gcp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(gcpPreflightChecker.QuotaShortfallsCall.Receives.State.LB.Type).To(Equal("concourse"))
+			})
+
+			It("returns an error when the dns zone cannot be labeled with the terraform version", func() {
+				terraformManager.VersionCall.Returns.Version = "0.9.11"
+
+				err := command.Execute(commands.GCPCreateLBsConfig{
+					LBType:   "cf",
+					CertPath: certPath,
+					KeyPath:  keyPath,
+					Domain:   "example.com",
+				}, storage.State{
+					IAAS: "gcp",
+					Tags: map[string]string{"owner": "platform"},
+				})
+				Expect(err).To(MatchError("--tag labels the cloud dns zones of lbs with a --domain, which needs terraform 0.10.0 or later"))
+				Expect(terraformManager.ApplyCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error before applying terraform when the quotas cannot hold the lb", func() {
+				gcpPreflightChecker.QuotaShortfallsCall.Returns.Shortfalls = []string{"FORWARDING_RULES in the project: 2 needed, 1 of 15 available"}
+
+				err := command.Execute(commands.GCPCreateLBsConfig{
+					LBType: "concourse",
+				}, storage.State{
+					IAAS: "gcp",
+					GCP:  storage.GCP{ProjectID: "some-project-id"},
+				})
+				Expect(err).To(MatchError(`The GCP quotas cannot hold the resources bbl creates:
+  FORWARDING_RULES in the project: 2 needed, 1 of 15 available
+Request an increase at https://console.cloud.google.com/iam-admin/quotas?project=some-project-id`))
+
+				Expect(terraformManager.ApplyCall.CallCount).To(Equal(0))
+			})
 		})
 
 		It("saves the updated tfstate", func() {
@@ -189,6 +265,24 @@ var _ = Describe("GCPCreateLBs", func() {
 			}))
 		})
 
+		It("asks for confirmation before updating the cloud config when interactive", func() {
+			err := command.Execute(commands.GCPCreateLBsConfig{
+				LBType:      "concourse",
+				Interactive: true,
+			}, storage.State{
+				IAAS: "gcp",
+				BOSH: storage.BOSH{
+					DirectorUsername: "some-director-username",
+					DirectorPassword: "some-director-password",
+					DirectorAddress:  "some-director-address",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cloudConfigManager.UpdateWithConfirmationCall.CallCount).To(Equal(1))
+			Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(0))
+		})
+
 		It("no-ops if SkipIfExists is supplied and the LBType does not change", func() {
 			err := command.Execute(commands.GCPCreateLBsConfig{
 				LBType:       "concourse",
@@ -248,6 +342,225 @@ var _ = Describe("GCPCreateLBs", func() {
 			})
 		})
 
+		Context("when an lb spec is provided", func() {
+			var (
+				spec          storage.LBSpec
+				incomingState storage.State
+			)
+
+			BeforeEach(func() {
+				spec = storage.LBSpec{
+					Name:        "vault",
+					Ports:       []storage.LBPort{{Port: 8200, InstancePort: 8200, Protocol: "tcp"}},
+					HealthCheck: storage.LBHealthCheck{Protocol: "http", Port: 8200, Path: "/v1/sys/health"},
+				}
+
+				incomingState = storage.State{
+					IAAS: "gcp",
+					LB: storage.LB{
+						Type: "concourse",
+					},
+					LBs: []storage.LBSpec{{Name: "credhub-uaa"}},
+				}
+
+				terraformManager.ApplyCall.Returns.BBLState = storage.State{
+					IAAS:    "gcp",
+					TFState: "some-new-tfstate",
+					LB: storage.LB{
+						Type: "concourse",
+					},
+					LBs: []storage.LBSpec{{Name: "credhub-uaa"}, spec},
+				}
+			})
+
+			It("applies terraform with the lb next to the existing lbs", func() {
+				err := command.Execute(commands.GCPCreateLBsConfig{Spec: spec}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformManager.ApplyCall.Receives.BBLState.LB.Type).To(Equal("concourse"))
+				Expect(terraformManager.ApplyCall.Receives.BBLState.LBs).To(Equal([]storage.LBSpec{{Name: "credhub-uaa"}, spec}))
+				Expect(incomingState.LBs).To(HaveLen(1))
+
+				Expect(stateStore.SetCall.Receives[0].State.TFState).To(Equal("some-new-tfstate"))
+				Expect(cloudConfigManager.UpdateCall.Receives.State.LBs).To(HaveLen(2))
+				Expect(gcpPreflightChecker.QuotaShortfallsCall.Receives.State.LBs).To(Equal([]storage.LBSpec{{Name: "credhub-uaa"}, spec}))
+			})
+
+			It("stores the cert, key and domain of a named cf lb next to the lb type", func() {
+				err := command.Execute(commands.GCPCreateLBsConfig{
+					LBType:   "cf",
+					CertPath: certPath,
+					KeyPath:  keyPath,
+					Domain:   "some-domain",
+					Spec:     storage.LBSpec{Name: "foundation", Type: "cf"},
+				}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformManager.ApplyCall.Receives.BBLState.LB.Type).To(Equal("concourse"))
+				Expect(terraformManager.ApplyCall.Receives.BBLState.LBs).To(Equal([]storage.LBSpec{
+					{Name: "credhub-uaa"},
+					{Name: "foundation", Type: "cf", Cert: certificate, Key: key, Domain: "some-domain"},
+				}))
+			})
+
+			It("stores the parent zone and the dns records of a named cf lb", func() {
+				records := []storage.DNSRecord{{Name: "@", Type: "TXT", TTL: 300, Records: []string{"v=spf1 -all"}}}
+				err := command.Execute(commands.GCPCreateLBsConfig{
+					LBType:     "cf",
+					CertPath:   certPath,
+					KeyPath:    keyPath,
+					Domain:     "some-domain",
+					ParentZone: "some-parent-zone",
+					DNSRecords: records,
+					Spec:       storage.LBSpec{Name: "foundation", Type: "cf"},
+				}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformManager.ApplyCall.Receives.BBLState.LBs[1].ParentZone).To(Equal("some-parent-zone"))
+				Expect(terraformManager.ApplyCall.Receives.BBLState.LBs[1].DNSRecords).To(Equal(records))
+			})
+
+			It("stores the sni certificates of a named cf lb", func() {
+				err := command.Execute(commands.GCPCreateLBsConfig{
+					LBType:   "cf",
+					CertPath: certPath,
+					KeyPath:  keyPath,
+					SNICerts: []commands.CertBundle{{CertPath: certPath, KeyPath: keyPath}},
+					Spec:     storage.LBSpec{Name: "foundation", Type: "cf"},
+				}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformManager.ApplyCall.Receives.BBLState.LBs[1].SNICertificates).To(Equal([]storage.LBCertificate{
+					{Cert: certificate, Key: key},
+				}))
+			})
+
+			It("replaces the named lb when updating", func() {
+				incomingState.LBs = []storage.LBSpec{{Name: "foundation", Type: "cf", Cert: "old-cert", Key: "old-key"}}
+
+				err := command.Execute(commands.GCPCreateLBsConfig{
+					LBType:   "cf",
+					CertPath: certPath,
+					KeyPath:  keyPath,
+					Spec:     incomingState.LBs[0],
+					Update:   true,
+				}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformManager.ApplyCall.Receives.BBLState.LBs).To(Equal([]storage.LBSpec{
+					{Name: "foundation", Type: "cf", Cert: certificate, Key: key},
+				}))
+			})
+
+			It("forgets the generated certificate of the lb once it is created", func() {
+				terraformManager.ApplyCall.Returns.BBLState.LBCerts.GeneratedCerts = []storage.LBGeneratedCert{{LB: "vault"}, {LB: "credhub-uaa"}}
+
+				err := command.Execute(commands.GCPCreateLBsConfig{Spec: spec, ForgetGeneratedCert: true}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stateStore.SetCall.Receives[0].State.LBCerts.GeneratedCerts).To(Equal([]storage.LBGeneratedCert{{LB: "credhub-uaa"}}))
+			})
+
+			It("keeps the generated certificate of the lb when terraform fails", func() {
+				incomingState.LBCerts.GeneratedCerts = []storage.LBGeneratedCert{{LB: "vault"}}
+				terraformManager.ApplyCall.Returns.Error = errors.New("failed to apply")
+
+				err := command.Execute(commands.GCPCreateLBsConfig{Spec: spec, ForgetGeneratedCert: true}, incomingState)
+				Expect(err).To(MatchError("failed to apply"))
+
+				Expect(terraformManager.ApplyCall.Receives.BBLState.LBCerts.GeneratedCerts).To(Equal([]storage.LBGeneratedCert{{LB: "vault"}}))
+				Expect(stateStore.SetCall.CallCount).To(Equal(0))
+			})
+
+			It("skips creating the lb when it exists and --skip-if-exists is provided", func() {
+				incomingState.LBs = []storage.LBSpec{spec}
+
+				err := command.Execute(commands.GCPCreateLBsConfig{Spec: spec, SkipIfExists: true}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.StepCall.Receives.Message).To(Equal(`lb "vault" exists, skipping...`))
+				Expect(terraformManager.ApplyCall.CallCount).To(Equal(0))
+			})
+
+			Context("failure cases", func() {
+				It("returns an error when an lb with the same name exists", func() {
+					incomingState.LBs = []storage.LBSpec{spec}
+
+					err := command.Execute(commands.GCPCreateLBsConfig{Spec: spec}, incomingState)
+					Expect(err).To(MatchError(`bbl already has a load balancer named "vault" attached, please remove it before attaching a new one`))
+				})
+
+				It("returns an error when a named cf lb has no cert and key", func() {
+					err := command.Execute(commands.GCPCreateLBsConfig{
+						LBType: "cf",
+						Spec:   storage.LBSpec{Name: "foundation", Type: "cf"},
+					}, incomingState)
+
+					expectedErrors := multierror.NewMultiError("create-lbs")
+					expectedErrors.Add(errors.New("--cert is required"))
+					expectedErrors.Add(errors.New("--key is required"))
+					Expect(err).To(Equal(expectedErrors))
+				})
+
+				It("returns an error when dns records are provided for an lb that is not cf", func() {
+					err := command.Execute(commands.GCPCreateLBsConfig{
+						Spec:       spec,
+						DNSRecords: []storage.DNSRecord{{Name: "www", Type: "A", Records: []string{"10.0.0.1"}}},
+					}, incomingState)
+					Expect(err).To(MatchError("--parent-zone and --dns-records are only supported for cf lbs"))
+				})
+
+				It("returns an error when sni certificates are provided for an lb that is not cf", func() {
+					err := command.Execute(commands.GCPCreateLBsConfig{
+						Spec:     spec,
+						SNICerts: []commands.CertBundle{{CertPath: certPath, KeyPath: keyPath}},
+					}, incomingState)
+					Expect(err).To(MatchError("only cf lbs terminate tls on gcp, other lbs cannot serve several certificates"))
+				})
+
+				It("returns an error when a port is not tcp", func() {
+					spec.Ports[0].Protocol = "https"
+
+					err := command.Execute(commands.GCPCreateLBsConfig{Spec: spec}, incomingState)
+					Expect(err).To(MatchError(`lb spec "vault" has https port 8200, gcp lbs only support tcp ports`))
+				})
+
+				It("returns an error when a port is mapped to another instance port", func() {
+					spec.Ports[0].InstancePort = 8201
+
+					err := command.Execute(commands.GCPCreateLBsConfig{Spec: spec}, incomingState)
+					Expect(err).To(MatchError(`lb spec "vault" maps port 8200 to instance port 8201, gcp lbs cannot change the port`))
+				})
+
+				It("returns an error when the health check is not tcp or http", func() {
+					spec.HealthCheck.Protocol = "https"
+
+					err := command.Execute(commands.GCPCreateLBsConfig{Spec: spec}, incomingState)
+					Expect(err).To(MatchError(`lb spec "vault" has a https health check, gcp lbs only support tcp and http health checks`))
+				})
+
+				It("returns an error when the bosh director does not exist", func() {
+					boshClient.InfoCall.Returns.Error = errors.New("director not found")
+
+					err := command.Execute(commands.GCPCreateLBsConfig{Spec: spec}, incomingState)
+					Expect(err).To(MatchError(commands.BBLNotFound))
+				})
+
+				It("saves the bbl state from the terraform error when terraform fails", func() {
+					terraformExecutorError.TFStateCall.Returns.TFState = "some-updated-tf-state"
+					terraformExecutorError.ErrorCall.Returns = "failed to apply"
+					terraformManager.ApplyCall.Returns.Error = terraform.NewManagerError(storage.State{}, terraformExecutorError)
+
+					err := command.Execute(commands.GCPCreateLBsConfig{Spec: spec}, incomingState)
+					Expect(err).To(MatchError("failed to apply"))
+
+					Expect(stateStore.SetCall.CallCount).To(Equal(1))
+					Expect(stateStore.SetCall.Receives[0].State.TFState).To(Equal("some-updated-tf-state"))
+					Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(0))
+				})
+			})
+		})
+
 		Context("failure cases", func() {
 			Context("when creating a cf lb and provided cert and key files are empty", func() {
 				BeforeEach(func() {
@@ -21,12 +21,18 @@ func NewGCPDeleteLBs(stateStore stateStore,
 	}
 }
 
-func (g GCPDeleteLBs) Execute(state storage.State) error {
+func (g GCPDeleteLBs) Execute(name string, state storage.State) error {
 	err := g.terraformManager.ValidateVersion()
 	if err != nil {
 		return err
 	}
-	state.LB.Type = ""
+
+	if name != "" {
+		state.LBs = removeLBSpec(state.LBs, name)
+	} else {
+		state.LB.Type = ""
+		state.LBs = nil
+	}
 
 	if !state.NoDirector {
 		err = g.cloudConfigManager.Update(state)
@@ -42,7 +42,7 @@ var _ = Describe("GCPDeleteLBs", func() {
 
 		Context("when bbl has a bosh director", func() {
 			It("updates the cloud config", func() {
-				err := command.Execute(storage.State{
+				err := command.Execute("", storage.State{
 					IAAS: "gcp",
 					BOSH: storage.BOSH{
 						DirectorUsername: "some-director-username",
@@ -74,7 +74,7 @@ var _ = Describe("GCPDeleteLBs", func() {
 
 		Context("when bbl does not have a bosh director", func() {
 			It("does not update the cloud config", func() {
-				err := command.Execute(storage.State{
+				err := command.Execute("", storage.State{
 					IAAS:       "gcp",
 					NoDirector: true,
 					GCP: storage.GCP{
@@ -98,7 +98,7 @@ var _ = Describe("GCPDeleteLBs", func() {
 			region := "some-region"
 			tfState := "some-tf-state"
 
-			err := command.Execute(storage.State{
+			err := command.Execute("", storage.State{
 				EnvID: envID,
 				GCP: storage.GCP{
 					ServiceAccountKey: credentials,
@@ -126,9 +126,38 @@ var _ = Describe("GCPDeleteLBs", func() {
 			}))
 		})
 
+		It("removes the lb specs along with the lb type", func() {
+			err := command.Execute("", storage.State{
+				IAAS: "gcp",
+				LB: storage.LB{
+					Type: "concourse",
+				},
+				LBs: []storage.LBSpec{{Name: "vault"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cloudConfigManager.UpdateCall.Receives.State.LBs).To(BeEmpty())
+			Expect(terraformManager.ApplyCall.Receives.BBLState.LBs).To(BeEmpty())
+		})
+
+		It("removes only the named lb", func() {
+			err := command.Execute("vault", storage.State{
+				IAAS: "gcp",
+				LB: storage.LB{
+					Type: "concourse",
+				},
+				LBs: []storage.LBSpec{{Name: "vault"}, {Name: "foundation", Type: "cf"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cloudConfigManager.UpdateCall.Receives.State.LB.Type).To(Equal("concourse"))
+			Expect(terraformManager.ApplyCall.Receives.BBLState.LB.Type).To(Equal("concourse"))
+			Expect(terraformManager.ApplyCall.Receives.BBLState.LBs).To(Equal([]storage.LBSpec{{Name: "foundation", Type: "cf"}}))
+		})
+
 		Context("state manipulation", func() {
 			It("removes the lb from the state", func() {
-				err := command.Execute(storage.State{
+				err := command.Execute("", storage.State{
 					IAAS: "gcp",
 					LB: storage.LB{
 						Type: "concourse",
@@ -145,7 +174,7 @@ var _ = Describe("GCPDeleteLBs", func() {
 					IAAS: "gcp",
 				}
 
-				err := command.Execute(storage.State{
+				err := command.Execute("", storage.State{
 					IAAS: "gcp",
 					LB: storage.LB{
 						Type: "concourse",
@@ -167,7 +196,7 @@ var _ = Describe("GCPDeleteLBs", func() {
 				}, terraformExecutorError)
 				terraformManager.ApplyCall.Returns.Error = expectedError
 
-				err := command.Execute(storage.State{
+				err := command.Execute("", storage.State{
 					IAAS: "gcp",
 					Stack: storage.Stack{
 						LBType: "concourse",
@@ -186,7 +215,7 @@ var _ = Describe("GCPDeleteLBs", func() {
 			It("fast fails if the terraform version is invalid", func() {
 				terraformManager.ValidateVersionCall.Returns.Error = errors.New("invalid")
 
-				err := command.Execute(storage.State{
+				err := command.Execute("", storage.State{
 					IAAS: "gcp",
 					Stack: storage.Stack{
 						LBType: "concourse",
@@ -198,7 +227,7 @@ var _ = Describe("GCPDeleteLBs", func() {
 			It("returns an error if applier fails with non terraform apply error", func() {
 				terraformManager.ApplyCall.Returns.Error = errors.New("failed to apply")
 
-				err := command.Execute(storage.State{
+				err := command.Execute("", storage.State{
 					IAAS: "gcp",
 					Stack: storage.Stack{
 						LBType: "concourse",
@@ -225,7 +254,7 @@ var _ = Describe("GCPDeleteLBs", func() {
 						{errors.New("failed to set state")},
 					}
 
-					err := command.Execute(storage.State{
+					err := command.Execute("", storage.State{
 						IAAS: "gcp",
 						Stack: storage.Stack{
 							LBType: "concourse",
@@ -251,7 +280,7 @@ var _ = Describe("GCPDeleteLBs", func() {
 						{errors.New("failed to set state")},
 					}
 
-					err := command.Execute(storage.State{
+					err := command.Execute("", storage.State{
 						IAAS: "gcp",
 						Stack: storage.Stack{
 							LBType: "concourse",
@@ -264,7 +293,7 @@ var _ = Describe("GCPDeleteLBs", func() {
 			It("returns an error when updating cloud config fails", func() {
 				cloudConfigManager.UpdateCall.Returns.Error = errors.New("updating cloud config failed")
 
-				err := command.Execute(storage.State{
+				err := command.Execute("", storage.State{
 					IAAS: "gcp",
 					Stack: storage.Stack{
 						LBType: "concourse",
@@ -278,7 +307,7 @@ var _ = Describe("GCPDeleteLBs", func() {
 					{errors.New("failed to set state")},
 				}
 
-				err := command.Execute(storage.State{
+				err := command.Execute("", storage.State{
 					IAAS: "gcp",
 					Stack: storage.Stack{
 						LBType: "concourse",
@@ -22,14 +22,15 @@ const (
 )
 
 type GCPUp struct {
-	stateStore         stateStore
-	keyPairUpdater     keyPairUpdater
-	gcpProvider        gcpProvider
-	boshManager        boshManager
-	cloudConfigManager cloudConfigManager
-	logger             logger
-	terraformManager   terraformManager
-	envIDManager       envIDManager
+	stateStore          stateStore
+	keyPairUpdater      keyPairUpdater
+	gcpProvider         gcpProvider
+	boshManager         boshManager
+	cloudConfigManager  cloudConfigManager
+	logger              logger
+	terraformManager    terraformManager
+	envIDManager        envIDManager
+	gcpPreflightChecker gcpPreflightChecker
 }
 
 type GCPUpConfig struct {
@@ -38,16 +39,27 @@ type GCPUpConfig struct {
 	Zone              string
 	Region            string
 	OpsFilePath       string
+	SSHPrivateKeyPath string
+	SSHPublicKeyPath  string
+	SSHKeyType        string
+	SSHKeyBits        int
 	Name              string
+	NamePrefix        string
+	NameTeam          string
+	NamePurpose       string
+	Tags              []string
 	NoDirector        bool
-}
 
-type gcpKeyPairCreator interface {
-	Create() (string, string, error)
+	CloudConfigOpsFilePaths []string
+	SizingProfile           string
+	ReservedIPCounts        []string
+	StaticIPCounts          []string
+	RuntimeConfigPath       string
+	Interactive             bool
 }
 
 type keyPairUpdater interface {
-	Update() (storage.KeyPair, error)
+	Update(keyPair storage.KeyPair, keyType string, keyBits int) (storage.KeyPair, error)
 }
 
 type gcpProvider interface {
@@ -75,30 +87,32 @@ type boshManager interface {
 }
 
 type envIDManager interface {
-	Sync(storage.State, string) (string, error)
+	Sync(storage.State, helpers.EnvIDNaming) (string, error)
 }
 
 type NewGCPUpArgs struct {
-	StateStore         stateStore
-	KeyPairUpdater     keyPairUpdater
-	GCPProvider        gcpProvider
-	TerraformManager   terraformManager
-	BoshManager        boshManager
-	Logger             logger
-	EnvIDManager       envIDManager
-	CloudConfigManager cloudConfigManager
+	StateStore          stateStore
+	KeyPairUpdater      keyPairUpdater
+	GCPProvider         gcpProvider
+	TerraformManager    terraformManager
+	BoshManager         boshManager
+	Logger              logger
+	EnvIDManager        envIDManager
+	CloudConfigManager  cloudConfigManager
+	GCPPreflightChecker gcpPreflightChecker
 }
 
 func NewGCPUp(args NewGCPUpArgs) GCPUp {
 	return GCPUp{
-		stateStore:         args.StateStore,
-		keyPairUpdater:     args.KeyPairUpdater,
-		gcpProvider:        args.GCPProvider,
-		terraformManager:   args.TerraformManager,
-		boshManager:        args.BoshManager,
-		cloudConfigManager: args.CloudConfigManager,
-		logger:             args.Logger,
-		envIDManager:       args.EnvIDManager,
+		stateStore:          args.StateStore,
+		keyPairUpdater:      args.KeyPairUpdater,
+		gcpProvider:         args.GCPProvider,
+		terraformManager:    args.TerraformManager,
+		boshManager:         args.BoshManager,
+		cloudConfigManager:  args.CloudConfigManager,
+		logger:              args.Logger,
+		envIDManager:        args.EnvIDManager,
+		gcpPreflightChecker: args.GCPPreflightChecker,
 	}
 }
 
@@ -138,11 +152,55 @@ func (u GCPUp) Execute(upConfig GCPUpConfig, state storage.State) error {
 		return err
 	}
 
+	sshKeyPair, err := loadSSHKeyPair(state.KeyPair, upConfig.SSHPrivateKeyPath, upConfig.SSHPublicKeyPath, upConfig.SSHKeyType, upConfig.SSHKeyBits)
+	if err != nil {
+		return err
+	}
+
+	state.Tags, err = loadTags(state.Tags, upConfig.Tags, "gcp")
+	if err != nil {
+		return err
+	}
+
+	err = checkGCPLabelSupport(u.terraformManager, state)
+	if err != nil {
+		return err
+	}
+
+	state.CloudConfig, err = loadCloudConfigOpsFiles(state.CloudConfig, upConfig.CloudConfigOpsFilePaths)
+	if err != nil {
+		return err
+	}
+
+	state.CloudConfig, err = loadSizingProfile(state.CloudConfig, upConfig.SizingProfile)
+	if err != nil {
+		return err
+	}
+
+	state.CloudConfig, err = loadNetworkIPCounts(state.CloudConfig, upConfig.ReservedIPCounts, upConfig.StaticIPCounts)
+	if err != nil {
+		return err
+	}
+
+	state.RuntimeConfig, err = loadRuntimeConfig(state.RuntimeConfig, upConfig.RuntimeConfigPath)
+	if err != nil {
+		return err
+	}
+
 	if err := u.gcpProvider.SetConfig(state.GCP.ServiceAccountKey, state.GCP.ProjectID, state.GCP.Zone); err != nil {
 		return err
 	}
 
-	envID, err := u.envIDManager.Sync(state, upConfig.Name)
+	if err := preflightGCP(u.gcpPreflightChecker, state, u.logger); err != nil {
+		return err
+	}
+
+	envID, err := u.envIDManager.Sync(state, helpers.EnvIDNaming{
+		Name:    upConfig.Name,
+		Prefix:  upConfig.NamePrefix,
+		Team:    upConfig.NameTeam,
+		Purpose: upConfig.NamePurpose,
+	})
 	if err != nil {
 		return err
 	}
@@ -154,7 +212,7 @@ func (u GCPUp) Execute(upConfig GCPUpConfig, state storage.State) error {
 	}
 
 	if state.KeyPair.IsEmpty() {
-		keyPair, err := u.keyPairUpdater.Update()
+		keyPair, err := u.keyPairUpdater.Update(sshKeyPair, upConfig.SSHKeyType, upConfig.SSHKeyBits)
 		if err != nil {
 			return err
 		}
@@ -195,7 +253,7 @@ func (u GCPUp) Execute(upConfig GCPUpConfig, state storage.State) error {
 			return err
 		}
 
-		err := u.cloudConfigManager.Update(state)
+		err := updateCloudConfig(u.cloudConfigManager, state, upConfig.Interactive)
 		if err != nil {
 			return err
 		}
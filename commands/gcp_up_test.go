@@ -10,6 +10,7 @@ import (
 	"github.com/cloudfoundry/bosh-bootloader/bosh"
 	"github.com/cloudfoundry/bosh-bootloader/commands"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/gcp"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 
 	. "github.com/onsi/ginkgo"
@@ -37,6 +38,7 @@ var _ = Describe("GCPUp", func() {
 		boshManager           *fakes.BOSHManager
 		cloudConfigManager    *fakes.CloudConfigManager
 		envIDManager          *fakes.EnvIDManager
+		gcpPreflightChecker   *fakes.GCPPreflightChecker
 		logger                *fakes.Logger
 		terraformManagerError *fakes.TerraformManagerError
 
@@ -58,6 +60,7 @@ var _ = Describe("GCPUp", func() {
 		gcpClientProvider = &fakes.GCPClientProvider{}
 		gcpClient = &fakes.GCPClient{}
 		gcpClientProvider.ClientCall.Returns.Client = gcpClient
+		gcpPreflightChecker = &fakes.GCPPreflightChecker{}
 		gcpClient.GetNetworksCall.Returns.NetworkList = &compute.NetworkList{}
 		logger = &fakes.Logger{}
 		boshManager = &fakes.BOSHManager{}
@@ -122,14 +125,15 @@ var _ = Describe("GCPUp", func() {
 		boshManager.CreateCall.Returns.State = expectedBOSHState
 
 		gcpUp = commands.NewGCPUp(commands.NewGCPUpArgs{
-			StateStore:         stateStore,
-			KeyPairUpdater:     keyPairUpdater,
-			GCPProvider:        gcpClientProvider,
-			TerraformManager:   terraformManager,
-			BoshManager:        boshManager,
-			Logger:             logger,
-			EnvIDManager:       envIDManager,
-			CloudConfigManager: cloudConfigManager,
+			StateStore:          stateStore,
+			KeyPairUpdater:      keyPairUpdater,
+			GCPProvider:         gcpClientProvider,
+			TerraformManager:    terraformManager,
+			BoshManager:         boshManager,
+			Logger:              logger,
+			EnvIDManager:        envIDManager,
+			CloudConfigManager:  cloudConfigManager,
+			GCPPreflightChecker: gcpPreflightChecker,
 		})
 
 		body, err := ioutil.ReadFile("fixtures/terraform_template_no_lb.tf")
@@ -188,6 +192,37 @@ var _ = Describe("GCPUp", func() {
 			Expect(gcpClientProvider.SetConfigCall.Receives.Zone).To(Equal("some-zone"))
 		})
 
+		It("checks the apis, permissions and quotas of the project", func() {
+			err := gcpUp.Execute(commands.GCPUpConfig{
+				ServiceAccountKey: serviceAccountKeyPath,
+				ProjectID:         "some-project-id",
+				Zone:              "some-zone",
+				Region:            "us-west1",
+			}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(gcpPreflightChecker.ValidateServiceAccountKeyCall.Receives.ServiceAccountKey).To(Equal(serviceAccountKey))
+			Expect(gcpPreflightChecker.DisabledAPIsCall.Receives.State.GCP.Region).To(Equal("us-west1"))
+			Expect(gcpPreflightChecker.MissingPermissionsCall.Receives.Permissions).To(Equal(gcp.RequiredPermissions))
+			Expect(gcpPreflightChecker.QuotaShortfallsCall.CallCount).To(Equal(1))
+		})
+
+		It("skips the checks that cannot run", func() {
+			gcpPreflightChecker.MissingPermissionsCall.Returns.Error = errors.New("api not enabled")
+
+			err := gcpUp.Execute(commands.GCPUpConfig{
+				ServiceAccountKey: serviceAccountKeyPath,
+				ProjectID:         "some-project-id",
+				Zone:              "some-zone",
+				Region:            "us-west1",
+			}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PrintlnCall.Messages).To(ContainElement("skipping the gcp iam permission check: api not enabled"))
+			Expect(gcpPreflightChecker.QuotaShortfallsCall.CallCount).To(Equal(1))
+			Expect(terraformManager.ApplyCall.CallCount).To(Equal(1))
+		})
+
 		It("retrieves the env ID", func() {
 			err := gcpUp.Execute(commands.GCPUpConfig{
 				ServiceAccountKey: serviceAccountKeyPath,
@@ -199,7 +234,7 @@ var _ = Describe("GCPUp", func() {
 
 			Expect(envIDManager.SyncCall.CallCount).To(Equal(1))
 			Expect(envIDManager.SyncCall.Receives.State).To(Equal(expectedIAASState))
-			Expect(envIDManager.SyncCall.Receives.Name).To(BeEmpty())
+			Expect(envIDManager.SyncCall.Receives.Naming.Name).To(BeEmpty())
 		})
 
 		It("saves the resulting state with the env ID", func() {
@@ -225,6 +260,229 @@ var _ = Describe("GCPUp", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(keyPairUpdater.UpdateCall.CallCount).To(Equal(1))
+			Expect(keyPairUpdater.UpdateCall.Receives.KeyPair).To(Equal(storage.KeyPair{}))
+			Expect(keyPairUpdater.UpdateCall.Receives.KeyBits).To(Equal(0))
+		})
+
+		It("passes the ssh key size to the key pair updater", func() {
+			err := gcpUp.Execute(commands.GCPUpConfig{
+				ServiceAccountKey: serviceAccountKeyPath,
+				ProjectID:         "some-project-id",
+				Zone:              "some-zone",
+				Region:            "us-west1",
+				SSHKeyBits:        4096,
+			}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(keyPairUpdater.UpdateCall.Receives.KeyBits).To(Equal(4096))
+		})
+
+		It("passes the ssh key type to the key pair updater", func() {
+			err := gcpUp.Execute(commands.GCPUpConfig{
+				ServiceAccountKey: serviceAccountKeyPath,
+				ProjectID:         "some-project-id",
+				Zone:              "some-zone",
+				Region:            "us-west1",
+				SSHKeyType:        "ed25519",
+			}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(keyPairUpdater.UpdateCall.Receives.KeyType).To(Equal("ed25519"))
+			Expect(keyPairUpdater.UpdateCall.Receives.KeyBits).To(Equal(0))
+		})
+
+		It("passes a provided ssh keypair to the key pair updater", func() {
+			privateKey, publicKey := generateSSHKeyPair()
+
+			privateKeyFile, err := ioutil.TempFile("", "ssh-private-key")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.WriteFile(privateKeyFile.Name(), []byte(privateKey), os.ModePerm)).To(Succeed())
+
+			err = gcpUp.Execute(commands.GCPUpConfig{
+				ServiceAccountKey: serviceAccountKeyPath,
+				ProjectID:         "some-project-id",
+				Zone:              "some-zone",
+				Region:            "us-west1",
+				SSHPrivateKeyPath: privateKeyFile.Name(),
+			}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(keyPairUpdater.UpdateCall.Receives.KeyPair).To(Equal(storage.KeyPair{
+				PrivateKey: privateKey,
+				PublicKey:  publicKey,
+			}))
+		})
+
+		It("returns an error when the provided ssh keypair cannot be read", func() {
+			err := gcpUp.Execute(commands.GCPUpConfig{
+				ServiceAccountKey: serviceAccountKeyPath,
+				ProjectID:         "some-project-id",
+				Zone:              "some-zone",
+				Region:            "us-west1",
+				SSHPrivateKeyPath: "/some/missing/key",
+			}, storage.State{})
+			Expect(err).To(MatchError("error reading ssh private key: open /some/missing/key: no such file or directory"))
+
+			Expect(keyPairUpdater.UpdateCall.CallCount).To(Equal(0))
+		})
+
+		Context("when cloud config ops files are provided", func() {
+			var opsFilePath string
+
+			BeforeEach(func() {
+				opsFile, err := ioutil.TempFile("", "cloud-config-ops-file")
+				Expect(err).NotTo(HaveOccurred())
+				opsFilePath = opsFile.Name()
+				Expect(ioutil.WriteFile(opsFilePath, []byte("some-cloud-config-ops"), os.ModePerm)).To(Succeed())
+			})
+
+			It("persists them in the state", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKey:       serviceAccountKeyPath,
+					ProjectID:               "some-project-id",
+					Zone:                    "some-zone",
+					Region:                  "us-west1",
+					CloudConfigOpsFilePaths: []string{opsFilePath},
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stateStore.SetCall.Receives[0].State.CloudConfig.OpsFiles).To(Equal([]string{"some-cloud-config-ops"}))
+				Expect(terraformManager.ApplyCall.Receives.BBLState.CloudConfig.OpsFiles).To(Equal([]string{"some-cloud-config-ops"}))
+			})
+
+			It("keeps the persisted ops files when none are provided", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{}, storage.State{
+					IAAS: "gcp",
+					GCP: storage.GCP{
+						ServiceAccountKey: serviceAccountKey,
+						ProjectID:         "some-project-id",
+						Zone:              "some-zone",
+						Region:            "us-west1",
+					},
+					CloudConfig: storage.CloudConfig{
+						OpsFiles: []string{"some-persisted-ops"},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformManager.ApplyCall.Receives.BBLState.CloudConfig.OpsFiles).To(Equal([]string{"some-persisted-ops"}))
+			})
+
+			It("returns an error when an ops file cannot be read", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKey:       serviceAccountKeyPath,
+					ProjectID:               "some-project-id",
+					Zone:                    "some-zone",
+					Region:                  "us-west1",
+					CloudConfigOpsFilePaths: []string{"/some/missing/ops-file"},
+				}, storage.State{})
+				Expect(err).To(MatchError("error reading cloud-config-ops-file contents: open /some/missing/ops-file: no such file or directory"))
+			})
+		})
+
+		Context("when a sizing profile is provided", func() {
+			It("persists it in the state", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKey: serviceAccountKeyPath,
+					ProjectID:         "some-project-id",
+					Zone:              "some-zone",
+					Region:            "us-west1",
+					SizingProfile:     "small",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stateStore.SetCall.Receives[0].State.CloudConfig.SizingProfile).To(Equal("small"))
+				Expect(terraformManager.ApplyCall.Receives.BBLState.CloudConfig.SizingProfile).To(Equal("small"))
+			})
+
+			It("returns an error when a custom profile cannot be read", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKey: serviceAccountKeyPath,
+					ProjectID:         "some-project-id",
+					Zone:              "some-zone",
+					Region:            "us-west1",
+					SizingProfile:     "/some/missing/profile",
+				}, storage.State{})
+				Expect(err).To(MatchError("error reading sizing-profile contents: open /some/missing/profile: no such file or directory"))
+			})
+		})
+
+		Context("when tags are provided", func() {
+			It("persists them and labels the resources with them", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKey: serviceAccountKeyPath,
+					ProjectID:         "some-project-id",
+					Zone:              "some-zone",
+					Region:            "us-west1",
+					Tags:              []string{"owner=platform", "cost-center=1234"},
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				expectedTags := map[string]string{"owner": "platform", "cost-center": "1234"}
+				Expect(stateStore.SetCall.Receives[0].State.Tags).To(Equal(expectedTags))
+				Expect(terraformManager.ApplyCall.Receives.BBLState.Tags).To(Equal(expectedTags))
+			})
+
+			It("returns an error when the dns zones cannot be labeled with the terraform version", func() {
+				terraformManager.VersionCall.Returns.Version = "0.9.11"
+
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					Tags: []string{"owner=platform"},
+				}, storage.State{
+					IAAS: "gcp",
+					GCP: storage.GCP{
+						ServiceAccountKey: serviceAccountKey,
+						ProjectID:         "some-project-id",
+						Zone:              "some-zone",
+						Region:            "us-west1",
+					},
+					LB: storage.LB{Type: "cf", Domain: "example.com"},
+				})
+				Expect(err).To(MatchError("--tag labels the cloud dns zones of lbs with a --domain, which needs terraform 0.10.0 or later"))
+				Expect(terraformManager.ApplyCall.CallCount).To(Equal(0))
+			})
+
+			It("does not need a newer terraform without an lb domain", func() {
+				terraformManager.VersionCall.Returns.Version = "0.9.11"
+
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKey: serviceAccountKeyPath,
+					ProjectID:         "some-project-id",
+					Zone:              "some-zone",
+					Region:            "us-west1",
+					Tags:              []string{"owner=platform"},
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			DescribeTable("returns an error when a tag is not a valid gcp label",
+				func(tag, expectedError string) {
+					err := gcpUp.Execute(commands.GCPUpConfig{
+						ServiceAccountKey: serviceAccountKeyPath,
+						ProjectID:         "some-project-id",
+						Zone:              "some-zone",
+						Region:            "us-west1",
+						Tags:              []string{tag},
+					}, storage.State{})
+					Expect(err).To(MatchError(expectedError))
+				},
+				Entry("uppercase key", "Owner=platform", `invalid --tag "Owner=platform", gcp label keys must start with a lowercase letter and contain at most 63 lowercase letters, numbers, dashes and underscores`),
+				Entry("key starting with a number", "1owner=platform", `invalid --tag "1owner=platform", gcp label keys must start with a lowercase letter and contain at most 63 lowercase letters, numbers, dashes and underscores`),
+				Entry("uppercase value", "owner=Platform", `invalid --tag "owner=Platform", gcp label values must contain at most 63 lowercase letters, numbers, dashes and underscores`),
+			)
+		})
+
+		Context("when a runtime config is provided", func() {
+			It("returns an error when it cannot be read", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKey: serviceAccountKeyPath,
+					ProjectID:         "some-project-id",
+					Zone:              "some-zone",
+					Region:            "us-west1",
+					RuntimeConfigPath: "/some/missing/runtime-config",
+				}, storage.State{})
+				Expect(err).To(MatchError("error reading runtime-config contents: open /some/missing/runtime-config: no such file or directory"))
+			})
 		})
 
 		It("saves the key pair to the state", func() {
@@ -305,6 +563,22 @@ var _ = Describe("GCPUp", func() {
 			Expect(cloudConfigManager.UpdateCall.Receives.State).To(Equal(expectedBOSHState))
 		})
 
+		It("asks for confirmation before updating the cloud config when interactive", func() {
+			err := gcpUp.Execute(commands.GCPUpConfig{
+				ServiceAccountKey: serviceAccountKeyPath,
+				ProjectID:         "some-project-id",
+				Zone:              "some-zone",
+				Region:            "some-region",
+				Interactive:       true,
+			}, storage.State{
+				EnvID: "bbl-lake-time:stamp",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cloudConfigManager.UpdateWithConfirmationCall.CallCount).To(Equal(1))
+			Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(0))
+		})
+
 		Context("when a name is passed in for env-id", func() {
 			It("passes that name in for the env id manager to use", func() {
 				err := gcpUp.Execute(commands.GCPUpConfig{
@@ -317,7 +591,7 @@ var _ = Describe("GCPUp", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(envIDManager.SyncCall.CallCount).To(Equal(1))
-				Expect(envIDManager.SyncCall.Receives.Name).To(Equal("some-other-env-id"))
+				Expect(envIDManager.SyncCall.Receives.Naming.Name).To(Equal("some-other-env-id"))
 			})
 		})
 
@@ -669,6 +943,23 @@ var _ = Describe("GCPUp", func() {
 				Expect(err).To(MatchError("setting config failed"))
 			})
 
+			It("fast fails before creating anything when the quotas cannot hold the resources", func() {
+				gcpPreflightChecker.QuotaShortfallsCall.Returns.Shortfalls = []string{"STATIC_ADDRESSES in us-west1: 1 needed, 0 of 8 available"}
+
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKey: serviceAccountKeyPath,
+					ProjectID:         "some-project-id",
+					Zone:              "some-zone",
+					Region:            "us-west1",
+				}, storage.State{})
+				Expect(err).To(MatchError(`The GCP quotas cannot hold the resources bbl creates:
+  STATIC_ADDRESSES in us-west1: 1 needed, 0 of 8 available
+Request an increase at https://console.cloud.google.com/iam-admin/quotas?project=some-project-id`))
+
+				Expect(envIDManager.SyncCall.CallCount).To(Equal(0))
+				Expect(terraformManager.ApplyCall.CallCount).To(Equal(0))
+			})
+
 			It("fast fails if a gcp environment with the same name already exists", func() {
 				envIDManager.SyncCall.Returns.Error = errors.New("environment already exists")
 				err := gcpUp.Execute(commands.GCPUpConfig{
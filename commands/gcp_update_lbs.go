@@ -13,9 +13,30 @@ func NewGCPUpdateLBs(gcpCreateLBs gcpCreateLBs) GCPUpdateLBs {
 }
 
 func (g GCPUpdateLBs) Execute(config GCPCreateLBsConfig, state storage.State) error {
+	if config.Spec.Name != "" {
+		config.Update = true
+		if config.Domain == "" {
+			config.Domain = config.Spec.Domain
+		}
+		if config.ParentZone == "" {
+			config.ParentZone = config.Spec.ParentZone
+		}
+		if config.DNSRecords == nil {
+			config.DNSRecords = config.Spec.DNSRecords
+		}
+
+		return g.gcpCreateLBs.Execute(config, state)
+	}
+
 	if config.Domain == "" {
 		config.Domain = state.LB.Domain
 	}
+	if config.ParentZone == "" {
+		config.ParentZone = state.LB.ParentZone
+	}
+	if config.DNSRecords == nil {
+		config.DNSRecords = state.LB.DNSRecords
+	}
 
 	return g.gcpCreateLBs.Execute(config, state)
 }
@@ -74,5 +74,66 @@ var _ = Describe("GCP Update LBs", func() {
 				Expect(gcpCreateLBs.ExecuteCall.Receives.State).To(Equal(state))
 			})
 		})
+
+		Context("when config does not contain dns flags", func() {
+			It("passes the parent zone and dns records from the state", func() {
+				state.LB.ParentZone = "some-parent-zone"
+				state.LB.DNSRecords = []storage.DNSRecord{{Name: "www", Type: "A", TTL: 300, Records: []string{"10.0.0.1"}}}
+
+				err := command.Execute(commands.GCPCreateLBsConfig{LBType: "cf"}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(gcpCreateLBs.ExecuteCall.Receives.Config.ParentZone).To(Equal("some-parent-zone"))
+				Expect(gcpCreateLBs.ExecuteCall.Receives.Config.DNSRecords).To(Equal(state.LB.DNSRecords))
+			})
+
+			It("passes the parent zone and dns records of the named lb", func() {
+				spec := storage.LBSpec{
+					Name:       "foundation",
+					Type:       "cf",
+					Domain:     "foundation-domain",
+					ParentZone: "foundation-parent-zone",
+					DNSRecords: []storage.DNSRecord{{Name: "www", Type: "A", TTL: 300, Records: []string{"10.0.0.2"}}},
+				}
+
+				err := command.Execute(commands.GCPCreateLBsConfig{LBType: "cf", Spec: spec}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(gcpCreateLBs.ExecuteCall.Receives.Config.ParentZone).To(Equal("foundation-parent-zone"))
+				Expect(gcpCreateLBs.ExecuteCall.Receives.Config.DNSRecords).To(Equal(spec.DNSRecords))
+			})
+
+			It("keeps the records given in the config", func() {
+				state.LB.DNSRecords = []storage.DNSRecord{{Name: "www", Type: "A", TTL: 300, Records: []string{"10.0.0.1"}}}
+				records := []storage.DNSRecord{{Name: "api", Type: "A", TTL: 300, Records: []string{"10.0.0.3"}}}
+
+				err := command.Execute(commands.GCPCreateLBsConfig{LBType: "cf", DNSRecords: records}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(gcpCreateLBs.ExecuteCall.Receives.Config.DNSRecords).To(Equal(records))
+			})
+		})
+
+		Context("when config contains a named lb", func() {
+			It("updates the named lb with the system domain of the named lb", func() {
+				spec := storage.LBSpec{Name: "foundation", Type: "cf", Domain: "foundation-domain"}
+				err := command.Execute(commands.GCPCreateLBsConfig{
+					CertPath: "some-cert-path",
+					KeyPath:  "some-key-path",
+					LBType:   "cf",
+					Spec:     spec,
+				}, state)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(gcpCreateLBs.ExecuteCall.Receives.Config).To(Equal(commands.GCPCreateLBsConfig{
+					CertPath: "some-cert-path",
+					KeyPath:  "some-key-path",
+					LBType:   "cf",
+					Domain:   "foundation-domain",
+					Spec:     spec,
+					Update:   true,
+				}))
+			})
+		})
 	})
 })
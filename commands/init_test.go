@@ -1,8 +1,15 @@
 package commands_test
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
 	"testing"
 
+	"golang.org/x/crypto/ssh"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -11,3 +18,18 @@ func TestCommands(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "commands")
 }
+
+func generateSSHKeyPair() (string, string) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).NotTo(HaveOccurred())
+
+	publicKey, err := ssh.NewPublicKey(rsaKey.Public())
+	Expect(err).NotTo(HaveOccurred())
+
+	privateKey := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(rsaKey),
+	})
+
+	return string(privateKey), strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(publicKey)), "\n")
+}
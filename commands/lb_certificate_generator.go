@@ -0,0 +1,212 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry/bosh-bootloader/ssl"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	SelfSignedCertMode = "self-signed"
+	ACMECertMode       = "acme"
+)
+
+type lbCertificateGenerator interface {
+	Generate(state storage.State, cert storage.LBGeneratedCert) (storage.State, CertBundle, error)
+}
+
+type lbKeyPairGenerator interface {
+	GenerateCA(commonName string) (ssl.CAData, error)
+	GenerateForDomain(ca ssl.CAData, domain string) (ssl.KeyPair, error)
+}
+
+type acmeIssuer interface {
+	Issue(directoryURL, email string, accountKey []byte, domain string, challenger ssl.DNSChallenger) (ssl.KeyPair, []byte, error)
+}
+
+// LBCertificateGenerator generates the certificates of lbs created with
+// --generate-cert, either signed by a certificate authority kept in the
+// state or issued by an ACME server after dns-01 challenges in the dns zone
+// of the domain.
+type LBCertificateGenerator struct {
+	keyPairGenerator lbKeyPairGenerator
+	acmeIssuer       acmeIssuer
+	awsDNSChallenger ssl.DNSChallenger
+	gcpDNSChallenger ssl.DNSChallenger
+	logger           logger
+}
+
+func NewLBCertificateGenerator(keyPairGenerator lbKeyPairGenerator, acmeIssuer acmeIssuer,
+	awsDNSChallenger, gcpDNSChallenger ssl.DNSChallenger, logger logger) LBCertificateGenerator {
+	return LBCertificateGenerator{
+		keyPairGenerator: keyPairGenerator,
+		acmeIssuer:       acmeIssuer,
+		awsDNSChallenger: awsDNSChallenger,
+		gcpDNSChallenger: gcpDNSChallenger,
+		logger:           logger,
+	}
+}
+
+// Generate generates the certificate and writes it, its key and its chain
+// to files for the iaas specific lb commands. The returned state records
+// the generated certificate and its expiry so that update-lbs can renew it.
+func (g LBCertificateGenerator) Generate(state storage.State, cert storage.LBGeneratedCert) (storage.State, CertBundle, error) {
+	var (
+		keyPair ssl.KeyPair
+		err     error
+	)
+
+	switch cert.Mode {
+	case SelfSignedCertMode:
+		state, keyPair, err = g.generateSelfSigned(state, cert.Domain)
+	case ACMECertMode:
+		state, keyPair, err = g.issueACME(state, cert.Domain)
+	default:
+		return state, CertBundle{}, fmt.Errorf("%q is not a valid certificate mode", cert.Mode)
+	}
+	if err != nil {
+		return state, CertBundle{}, err
+	}
+
+	info, err := ssl.DescribeCertificate(keyPair.Certificate)
+	if err != nil {
+		return state, CertBundle{}, err
+	}
+	cert.NotAfter = info.NotAfter
+
+	bundle, err := writeKeyPair(keyPair, state.IAAS)
+	if err != nil {
+		return state, CertBundle{}, err
+	}
+
+	state.LBCerts.GeneratedCerts = recordGeneratedCert(state.LBCerts.GeneratedCerts, cert)
+
+	return state, bundle, nil
+}
+
+func (g LBCertificateGenerator) generateSelfSigned(state storage.State, domain string) (storage.State, ssl.KeyPair, error) {
+	if state.LBCerts.CA == "" {
+		g.logger.Step("generating lb certificate authority")
+
+		ca, err := g.keyPairGenerator.GenerateCA("bbl lb ca")
+		if err != nil {
+			return state, ssl.KeyPair{}, err
+		}
+
+		state.LBCerts.CA = string(ca.CA)
+		state.LBCerts.CAPrivateKey = string(ca.PrivateKey)
+	}
+
+	g.logger.Step("generating certificate for %q", domain)
+
+	keyPair, err := g.keyPairGenerator.GenerateForDomain(ssl.CAData{
+		CA:         []byte(state.LBCerts.CA),
+		PrivateKey: []byte(state.LBCerts.CAPrivateKey),
+	}, domain)
+	if err != nil {
+		return state, ssl.KeyPair{}, err
+	}
+
+	return state, keyPair, nil
+}
+
+func (g LBCertificateGenerator) issueACME(state storage.State, domain string) (storage.State, ssl.KeyPair, error) {
+	var challenger ssl.DNSChallenger
+	switch state.IAAS {
+	case "aws":
+		challenger = g.awsDNSChallenger
+	case "gcp":
+		challenger = g.gcpDNSChallenger
+	}
+
+	if state.LBCerts.ACMEEmail == "" {
+		return state, ssl.KeyPair{}, errors.New("--acme-email is required to register with the acme server")
+	}
+
+	directoryURL := state.LBCerts.ACMEDirectoryURL
+	if directoryURL == "" {
+		directoryURL = ssl.LetsEncryptDirectoryURL
+	}
+
+	g.logger.Step("issuing certificate for %q from %s", domain, directoryURL)
+
+	keyPair, accountKey, err := g.acmeIssuer.Issue(directoryURL, state.LBCerts.ACMEEmail, []byte(state.LBCerts.ACMEAccountKey), domain, challenger)
+	if err != nil {
+		return state, ssl.KeyPair{}, err
+	}
+
+	state.LBCerts.ACMEAccountKey = string(accountKey)
+
+	return state, keyPair, nil
+}
+
+// writeKeyPair writes a generated key pair to files in a temporary
+// directory, which the caller removes. The chain goes to a file of its own
+// for aws, on gcp it follows the certificate.
+func writeKeyPair(keyPair ssl.KeyPair, iaas string) (CertBundle, error) {
+	dir, err := ioutil.TempDir("", "bbl-lb-cert")
+	if err != nil {
+		return CertBundle{}, err
+	}
+
+	certificate := keyPair.Certificate
+	if iaas == "gcp" {
+		certificate = append(append([]byte{}, certificate...), keyPair.CA...)
+	}
+
+	bundle := CertBundle{
+		CertPath: filepath.Join(dir, "cert.pem"),
+		KeyPath:  filepath.Join(dir, "key.pem"),
+		Dir:      dir,
+	}
+
+	files := map[string][]byte{
+		bundle.CertPath: certificate,
+		bundle.KeyPath:  keyPair.PrivateKey,
+	}
+
+	if iaas == "aws" && len(keyPair.CA) > 0 {
+		bundle.ChainPath = filepath.Join(dir, "chain.pem")
+		files[bundle.ChainPath] = keyPair.CA
+	}
+
+	for path, contents := range files {
+		err = ioutil.WriteFile(path, contents, os.FileMode(0600))
+		if err != nil {
+			return CertBundle{}, err
+		}
+	}
+
+	return bundle, nil
+}
+
+// recordGeneratedCert replaces the generated certificate of the same lb.
+func recordGeneratedCert(certs []storage.LBGeneratedCert, cert storage.LBGeneratedCert) []storage.LBGeneratedCert {
+	return append(forgetGeneratedCert(certs, cert.LB), cert)
+}
+
+// forgetGeneratedCert removes the generated certificate of the named lb,
+// whose certificate is no longer renewed by bbl.
+func forgetGeneratedCert(certs []storage.LBGeneratedCert, lbName string) []storage.LBGeneratedCert {
+	var remaining []storage.LBGeneratedCert
+	for _, cert := range certs {
+		if cert.LB != lbName {
+			remaining = append(remaining, cert)
+		}
+	}
+	return remaining
+}
+
+func findGeneratedCert(certs []storage.LBGeneratedCert, lbName string) (storage.LBGeneratedCert, bool) {
+	for _, cert := range certs {
+		if cert.LB == lbName {
+			return cert, true
+		}
+	}
+	return storage.LBGeneratedCert{}, false
+}
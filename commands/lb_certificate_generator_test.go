@@ -0,0 +1,216 @@
+package commands_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/ssl"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+	"github.com/cloudfoundry/bosh-bootloader/testhelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LBCertificateGenerator", func() {
+	var bblCertNotAfter = time.Date(2018, time.May, 26, 22, 13, 41, 0, time.UTC)
+
+	var (
+		keyPairGenerator *fakes.LBKeyPairGenerator
+		acmeIssuer       *fakes.ACMEIssuer
+		awsDNSChallenger *fakes.DNSChallenger
+		gcpDNSChallenger *fakes.DNSChallenger
+		logger           *fakes.Logger
+
+		generator commands.LBCertificateGenerator
+	)
+
+	BeforeEach(func() {
+		keyPairGenerator = &fakes.LBKeyPairGenerator{}
+		keyPairGenerator.GenerateCACall.Returns.CA = ssl.CAData{
+			CA:         []byte("some-ca"),
+			PrivateKey: []byte("some-ca-key"),
+		}
+		keyPairGenerator.GenerateForDomainCall.Returns.KeyPair = ssl.KeyPair{
+			CA:          []byte("some-ca"),
+			Certificate: []byte(testhelpers.BBL_CERT),
+			PrivateKey:  []byte("some-key"),
+		}
+
+		acmeIssuer = &fakes.ACMEIssuer{}
+		acmeIssuer.IssueCall.Returns.KeyPair = ssl.KeyPair{
+			CA:          []byte("some-intermediate"),
+			Certificate: []byte(testhelpers.OTHER_BBL_CERT),
+			PrivateKey:  []byte("some-acme-key"),
+		}
+		acmeIssuer.IssueCall.Returns.AccountKey = []byte("some-account-key")
+
+		awsDNSChallenger = &fakes.DNSChallenger{}
+		gcpDNSChallenger = &fakes.DNSChallenger{}
+		logger = &fakes.Logger{}
+
+		generator = commands.NewLBCertificateGenerator(keyPairGenerator, acmeIssuer, awsDNSChallenger, gcpDNSChallenger, logger)
+	})
+
+	Context("self-signed", func() {
+		It("creates a ca and signs a certificate for the domain with it", func() {
+			state, bundle, err := generator.Generate(storage.State{IAAS: "aws"}, storage.LBGeneratedCert{
+				LB:     "some-lb",
+				Mode:   commands.SelfSignedCertMode,
+				Domain: "example.com",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(keyPairGenerator.GenerateCACall.CallCount).To(Equal(1))
+			Expect(keyPairGenerator.GenerateForDomainCall.Receives.CA).To(Equal(ssl.CAData{
+				CA:         []byte("some-ca"),
+				PrivateKey: []byte("some-ca-key"),
+			}))
+			Expect(keyPairGenerator.GenerateForDomainCall.Receives.Domain).To(Equal("example.com"))
+
+			Expect(state.LBCerts.CA).To(Equal("some-ca"))
+			Expect(state.LBCerts.CAPrivateKey).To(Equal("some-ca-key"))
+			Expect(state.LBCerts.GeneratedCerts).To(Equal([]storage.LBGeneratedCert{{
+				LB:       "some-lb",
+				Mode:     commands.SelfSignedCertMode,
+				Domain:   "example.com",
+				NotAfter: bblCertNotAfter,
+			}}))
+
+			Expect(ioutil.ReadFile(bundle.CertPath)).To(Equal([]byte(testhelpers.BBL_CERT)))
+			Expect(ioutil.ReadFile(bundle.KeyPath)).To(Equal([]byte("some-key")))
+			Expect(ioutil.ReadFile(bundle.ChainPath)).To(Equal([]byte("some-ca")))
+			Expect(filepath.Dir(bundle.CertPath)).To(Equal(bundle.Dir))
+		})
+
+		It("reuses the ca of the state", func() {
+			_, _, err := generator.Generate(storage.State{
+				IAAS: "aws",
+				LBCerts: storage.LBCerts{
+					CA:           "existing-ca",
+					CAPrivateKey: "existing-ca-key",
+				},
+			}, storage.LBGeneratedCert{Mode: commands.SelfSignedCertMode, Domain: "example.com"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(keyPairGenerator.GenerateCACall.CallCount).To(Equal(0))
+			Expect(keyPairGenerator.GenerateForDomainCall.Receives.CA).To(Equal(ssl.CAData{
+				CA:         []byte("existing-ca"),
+				PrivateKey: []byte("existing-ca-key"),
+			}))
+		})
+
+		It("appends the ca to the certificate on gcp", func() {
+			_, bundle, err := generator.Generate(storage.State{IAAS: "gcp"}, storage.LBGeneratedCert{
+				Mode:   commands.SelfSignedCertMode,
+				Domain: "example.com",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ioutil.ReadFile(bundle.CertPath)).To(Equal([]byte(testhelpers.BBL_CERT + "some-ca")))
+			Expect(bundle.ChainPath).To(BeEmpty())
+		})
+
+		It("replaces the generated certificate of the same lb", func() {
+			state, _, err := generator.Generate(storage.State{
+				IAAS: "aws",
+				LBCerts: storage.LBCerts{
+					GeneratedCerts: []storage.LBGeneratedCert{
+						{LB: "some-lb", Mode: commands.ACMECertMode, Domain: "old.example.com"},
+						{LB: "other-lb", Mode: commands.SelfSignedCertMode, Domain: "other.example.com"},
+					},
+				},
+			}, storage.LBGeneratedCert{LB: "some-lb", Mode: commands.SelfSignedCertMode, Domain: "example.com"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(state.LBCerts.GeneratedCerts).To(Equal([]storage.LBGeneratedCert{
+				{LB: "other-lb", Mode: commands.SelfSignedCertMode, Domain: "other.example.com"},
+				{LB: "some-lb", Mode: commands.SelfSignedCertMode, Domain: "example.com", NotAfter: bblCertNotAfter},
+			}))
+		})
+	})
+
+	Context("acme", func() {
+		It("issues a certificate with the dns challenger of the iaas", func() {
+			state, bundle, err := generator.Generate(storage.State{
+				IAAS: "gcp",
+				LBCerts: storage.LBCerts{
+					ACMEEmail: "some-email",
+				},
+			}, storage.LBGeneratedCert{Mode: commands.ACMECertMode, Domain: "example.com"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(acmeIssuer.IssueCall.Receives.DirectoryURL).To(Equal(ssl.LetsEncryptDirectoryURL))
+			Expect(acmeIssuer.IssueCall.Receives.Email).To(Equal("some-email"))
+			Expect(acmeIssuer.IssueCall.Receives.AccountKey).To(BeEmpty())
+			Expect(acmeIssuer.IssueCall.Receives.Domain).To(Equal("example.com"))
+			Expect(acmeIssuer.IssueCall.Receives.Challenger).To(BeIdenticalTo(gcpDNSChallenger))
+
+			Expect(state.LBCerts.ACMEAccountKey).To(Equal("some-account-key"))
+			Expect(ioutil.ReadFile(bundle.CertPath)).To(Equal([]byte(testhelpers.OTHER_BBL_CERT + "some-intermediate")))
+			Expect(ioutil.ReadFile(bundle.KeyPath)).To(Equal([]byte("some-acme-key")))
+		})
+
+		It("uses the directory and account key of the state", func() {
+			_, _, err := generator.Generate(storage.State{
+				IAAS: "aws",
+				LBCerts: storage.LBCerts{
+					ACMEEmail:        "some-email",
+					ACMEDirectoryURL: "some-directory",
+					ACMEAccountKey:   "existing-account-key",
+				},
+			}, storage.LBGeneratedCert{Mode: commands.ACMECertMode, Domain: "example.com"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(acmeIssuer.IssueCall.Receives.DirectoryURL).To(Equal("some-directory"))
+			Expect(acmeIssuer.IssueCall.Receives.AccountKey).To(Equal([]byte("existing-account-key")))
+			Expect(acmeIssuer.IssueCall.Receives.Challenger).To(BeIdenticalTo(awsDNSChallenger))
+		})
+	})
+
+	Context("failure cases", func() {
+		It("returns an error when the mode is unknown", func() {
+			_, _, err := generator.Generate(storage.State{}, storage.LBGeneratedCert{Mode: "some-mode"})
+			Expect(err).To(MatchError(`"some-mode" is not a valid certificate mode`))
+		})
+
+		It("returns an error when the ca cannot be generated", func() {
+			keyPairGenerator.GenerateCACall.Returns.Error = errors.New("failed to generate ca")
+
+			_, _, err := generator.Generate(storage.State{}, storage.LBGeneratedCert{Mode: commands.SelfSignedCertMode})
+			Expect(err).To(MatchError("failed to generate ca"))
+		})
+
+		It("returns an error when the certificate cannot be generated", func() {
+			keyPairGenerator.GenerateForDomainCall.Returns.Error = errors.New("failed to generate")
+
+			_, _, err := generator.Generate(storage.State{}, storage.LBGeneratedCert{Mode: commands.SelfSignedCertMode})
+			Expect(err).To(MatchError("failed to generate"))
+		})
+
+		It("returns an error when there is no acme email", func() {
+			_, _, err := generator.Generate(storage.State{}, storage.LBGeneratedCert{Mode: commands.ACMECertMode})
+			Expect(err).To(MatchError("--acme-email is required to register with the acme server"))
+		})
+
+		It("returns an error when the certificate cannot be parsed", func() {
+			keyPairGenerator.GenerateForDomainCall.Returns.KeyPair.Certificate = []byte("some-cert")
+
+			_, _, err := generator.Generate(storage.State{}, storage.LBGeneratedCert{Mode: commands.SelfSignedCertMode})
+			Expect(err).To(MatchError("certificate is not PEM encoded"))
+		})
+
+		It("returns an error when the certificate cannot be issued", func() {
+			acmeIssuer.IssueCall.Returns.Error = errors.New("failed to issue")
+
+			_, _, err := generator.Generate(storage.State{
+				LBCerts: storage.LBCerts{ACMEEmail: "some-email"},
+			}, storage.LBGeneratedCert{Mode: commands.ACMECertMode})
+			Expect(err).To(MatchError("failed to issue"))
+		})
+	})
+})
@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws/acm"
+	"github.com/cloudfoundry/bosh-bootloader/ssl"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+// CertBundle is the paths of a certificate, its key and optional chain.
+type CertBundle struct {
+	CertPath  string
+	KeyPath   string
+	ChainPath string
+
+	// Dir is the temporary directory of a generated bundle, removed once
+	// the lb command has read the files.
+	Dir string
+}
+
+// splitCertBundles pairs repeated --cert, --key and --chain flags in the
+// order they were given. The first bundle is the default certificate of
+// the lb, the others are served through SNI.
+func splitCertBundles(certPaths, keyPaths, chainPaths []string) (CertBundle, []CertBundle, error) {
+	if len(certPaths) > 1 || len(keyPaths) > 1 || len(chainPaths) > 1 {
+		if len(keyPaths) != len(certPaths) {
+			return CertBundle{}, nil, errors.New("every --cert needs a --key of its own")
+		}
+
+		if len(chainPaths) > 0 && len(chainPaths) != len(certPaths) {
+			return CertBundle{}, nil, errors.New("--chain has to be provided for every --cert or not at all")
+		}
+	}
+
+	first := func(paths []string) string {
+		if len(paths) > 0 {
+			return paths[0]
+		}
+		return ""
+	}
+
+	primary := CertBundle{
+		CertPath:  first(certPaths),
+		KeyPath:   first(keyPaths),
+		ChainPath: first(chainPaths),
+	}
+
+	var sniCerts []CertBundle
+	for i := 1; i < len(certPaths); i++ {
+		bundle := CertBundle{CertPath: certPaths[i], KeyPath: keyPaths[i]}
+		if len(chainPaths) > 0 {
+			bundle.ChainPath = chainPaths[i]
+		}
+		sniCerts = append(sniCerts, bundle)
+	}
+
+	return primary, sniCerts, nil
+}
+
+// decryptKeys decrypts the key of the default certificate and the keys of
+// the SNI certificates with the passphrase, and points them at files
+// holding the decrypted keys. It returns the temporary directory of those
+// files, which the caller removes once the lb command has read them.
+func decryptKeys(passphrase string, keyPath *string, sniCerts []CertBundle) (string, error) {
+	if *keyPath == "" {
+		return "", errors.New("--key-passphrase requires --key")
+	}
+
+	dir, err := ioutil.TempDir("", "bbl-lb-key")
+	if err != nil {
+		return "", err
+	}
+
+	keyPaths := []*string{keyPath}
+	for i := range sniCerts {
+		keyPaths = append(keyPaths, &sniCerts[i].KeyPath)
+	}
+
+	for i, path := range keyPaths {
+		keyPEM, err := ioutil.ReadFile(*path)
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+
+		decrypted, err := ssl.DecryptPrivateKey(keyPEM, passphrase)
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("%s: %q", err, *path)
+		}
+
+		decryptedPath := filepath.Join(dir, fmt.Sprintf("key-%d.pem", i))
+		err = ioutil.WriteFile(decryptedPath, decrypted, os.FileMode(0600))
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+
+		*path = decryptedPath
+	}
+
+	return dir, nil
+}
+
+func validateSNICertificates(certificateValidator certificateValidator, command string, sniCerts []CertBundle) error {
+	for _, bundle := range sniCerts {
+		err := certificateValidator.Validate(command, bundle.CertPath, bundle.KeyPath, bundle.ChainPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createCertificate uploads the certificate of the named lb to IAM, or
+// imports it into ACM with --aws-acm, and returns the name it is kept
+// under. A certificate referenced with --aws-acm-cert-arn is used as is.
+func createCertificate(certificateManager certificateManager, guidGenerator guidGenerator,
+	config AWSCreateLBsConfig, name, envID string) (string, error) {
+	if config.ACMCertificateARN != "" {
+		return config.ACMCertificateARN, nil
+	}
+
+	certificateName, err := lbCertificateName(guidGenerator, name, envID, config.ACM)
+	if err != nil {
+		return "", err
+	}
+
+	return certificateManager.Create(config.CertPath, config.KeyPath, config.ChainPath, certificateName)
+}
+
+// uploadSNICertificates uploads the SNI certificates of the named lb to IAM,
+// or imports them into ACM.
+func uploadSNICertificates(certificateManager certificateManager, guidGenerator guidGenerator,
+	name, envID string, sniCerts []CertBundle, useACM bool) ([]storage.LBCertificate, error) {
+	var certificates []storage.LBCertificate
+	for _, bundle := range sniCerts {
+		certificateName, err := lbCertificateName(guidGenerator, name+"-sni", envID, useACM)
+		if err != nil {
+			return nil, err
+		}
+
+		certificateName, err = certificateManager.Create(bundle.CertPath, bundle.KeyPath, bundle.ChainPath, certificateName)
+		if err != nil {
+			return nil, err
+		}
+
+		certificate, err := certificateManager.Describe(certificateName)
+		if err != nil {
+			return nil, err
+		}
+
+		certificates = append(certificates, storage.LBCertificate{
+			Name: certificateName,
+			ARN:  certificate.ARN,
+		})
+	}
+
+	return certificates, nil
+}
+
+func lbCertificateName(guidGenerator guidGenerator, name, envID string, useACM bool) (string, error) {
+	certificateName, err := certificateNameFor(name, guidGenerator, envID)
+	if err != nil {
+		return "", err
+	}
+
+	if useACM {
+		certificateName = acm.NamePrefix + certificateName
+	}
+
+	return certificateName, nil
+}
+
+// validateACMCertificateARN checks that a certificate referenced with
+// --aws-acm-cert-arn lives in the region of the environment, as lbs can
+// only use ACM certificates of their own region.
+func validateACMCertificateARN(arn, region string) error {
+	if arnRegion := acm.CertificateARNRegion(arn); arnRegion != region {
+		return fmt.Errorf("acm certificate %q is in region %q, the lbs of this environment are in %q", arn, arnRegion, region)
+	}
+
+	return nil
+}
+
+// checkSNISupport rejects SNI certificates for an lb that can only serve a
+// single certificate.
+func checkSNISupport(spec storage.LBSpec, iaas string, sniCerts []CertBundle) error {
+	if len(sniCerts) == 0 {
+		return nil
+	}
+
+	switch {
+	case spec.Name == "" && iaas == "aws":
+		return errors.New("the lb created without --name is a classic elb which serves a single certificate, create a named lb with --aws-lb-flavor alb or nlb to serve several")
+	case iaas == "aws" && (spec.Flavor == "" || spec.Flavor == "elb"):
+		return fmt.Errorf("lb %q is a classic elb which serves a single certificate, migrate it with --aws-lb-flavor alb or nlb to serve several", spec.Name)
+	case iaas == "gcp" && spec.Type != "cf":
+		return errors.New("only cf lbs terminate tls on gcp, other lbs cannot serve several certificates")
+	case !lbSpecRequiresCert(spec):
+		return fmt.Errorf("lb %q does not terminate tls, it cannot serve several certificates", spec.Name)
+	}
+
+	return nil
+}
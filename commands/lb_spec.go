@@ -0,0 +1,295 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const maxLBSpecNameLength = 16
+
+var (
+	lbSpecNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z][a-z0-9]*)*$`)
+
+	// reservedLBSpecNames would clash with the resources and vm_extensions
+	// of the cf and concourse lb types.
+	reservedLBSpecNames = map[string]bool{
+		"cf":        true,
+		"concourse": true,
+		"router":    true,
+		"ssh-proxy": true,
+	}
+)
+
+type lbSpecFile struct {
+	Name  string `yaml:"name"`
+	Ports []struct {
+		Port         int    `yaml:"port"`
+		InstancePort int    `yaml:"instance_port"`
+		Protocol     string `yaml:"protocol"`
+	} `yaml:"ports"`
+	HealthCheck struct {
+		Protocol string `yaml:"protocol"`
+		Port     int    `yaml:"port"`
+		Path     string `yaml:"path"`
+	} `yaml:"health_check"`
+	Cert  string `yaml:"cert"`
+	Key   string `yaml:"key"`
+	Chain string `yaml:"chain"`
+}
+
+// lbSpec is a parsed load balancer spec file along with the paths of the
+// certificate it terminates TLS with.
+type lbSpec struct {
+	spec      storage.LBSpec
+	certPath  string
+	keyPath   string
+	chainPath string
+}
+
+func loadLBSpec(path string) (lbSpec, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return lbSpec{}, fmt.Errorf("failed to read lb spec: %s", err)
+	}
+
+	var file lbSpecFile
+	err = yaml.Unmarshal(contents, &file)
+	if err != nil {
+		return lbSpec{}, fmt.Errorf("failed to parse lb spec: %s", err)
+	}
+
+	spec := storage.LBSpec{
+		Name: file.Name,
+		HealthCheck: storage.LBHealthCheck{
+			Protocol: file.HealthCheck.Protocol,
+			Port:     file.HealthCheck.Port,
+			Path:     file.HealthCheck.Path,
+		},
+	}
+
+	for _, port := range file.Ports {
+		lbPort := storage.LBPort{
+			Port:         port.Port,
+			InstancePort: port.InstancePort,
+			Protocol:     port.Protocol,
+		}
+		if lbPort.InstancePort == 0 {
+			lbPort.InstancePort = lbPort.Port
+		}
+		if lbPort.Protocol == "" {
+			lbPort.Protocol = "tcp"
+		}
+		spec.Ports = append(spec.Ports, lbPort)
+	}
+
+	if spec.HealthCheck.Protocol == "" {
+		spec.HealthCheck.Protocol = "tcp"
+	}
+	if spec.HealthCheck.Port == 0 && len(spec.Ports) > 0 {
+		spec.HealthCheck.Port = spec.Ports[0].InstancePort
+	}
+	if spec.HealthCheck.Path == "" && isHTTPProtocol(spec.HealthCheck.Protocol) {
+		spec.HealthCheck.Path = "/"
+	}
+
+	err = validateLBSpec(spec)
+	if err != nil {
+		return lbSpec{}, err
+	}
+
+	return lbSpec{
+		spec:      spec,
+		certPath:  file.Cert,
+		keyPath:   file.Key,
+		chainPath: file.Chain,
+	}, nil
+}
+
+func validateLBSpec(spec storage.LBSpec) error {
+	if spec.Name == "" {
+		return fmt.Errorf("lb spec name is required")
+	}
+
+	if err := validateLBName(spec.Name); err != nil {
+		return err
+	}
+
+	if len(spec.Ports) == 0 {
+		return fmt.Errorf("lb spec %q must have at least one port", spec.Name)
+	}
+
+	ports := map[int]bool{}
+	for _, port := range spec.Ports {
+		if !isValidPort(port.Port) {
+			return fmt.Errorf("lb spec %q has invalid port %d", spec.Name, port.Port)
+		}
+
+		if !isValidPort(port.InstancePort) {
+			return fmt.Errorf("lb spec %q has invalid instance port %d", spec.Name, port.InstancePort)
+		}
+
+		if !isValidLBProtocol(port.Protocol) {
+			return fmt.Errorf("lb spec %q has invalid protocol %q, valid protocols are: tcp, ssl, http, https", spec.Name, port.Protocol)
+		}
+
+		if ports[port.Port] {
+			return fmt.Errorf("lb spec %q has duplicate port %d", spec.Name, port.Port)
+		}
+		ports[port.Port] = true
+	}
+
+	if !isValidLBProtocol(spec.HealthCheck.Protocol) {
+		return fmt.Errorf("lb spec %q has invalid health check protocol %q, valid protocols are: tcp, ssl, http, https", spec.Name, spec.HealthCheck.Protocol)
+	}
+
+	if !isValidPort(spec.HealthCheck.Port) {
+		return fmt.Errorf("lb spec %q has invalid health check port %d", spec.Name, spec.HealthCheck.Port)
+	}
+
+	if spec.HealthCheck.Path != "" && !isHTTPProtocol(spec.HealthCheck.Protocol) {
+		return fmt.Errorf("lb spec %q health check path is only supported for http and https", spec.Name)
+	}
+
+	return nil
+}
+
+func validateLBName(name string) error {
+	if !lbSpecNameRegexp.MatchString(name) {
+		return fmt.Errorf("lb spec name %q must start with a letter and contain only lowercase letters, numbers and single dashes", name)
+	}
+
+	if len(name) > maxLBSpecNameLength {
+		return fmt.Errorf("lb spec name %q must be at most %d characters", name, maxLBSpecNameLength)
+	}
+
+	if reservedLBSpecNames[name] {
+		return fmt.Errorf("lb spec name %q is reserved", name)
+	}
+
+	return nil
+}
+
+// lbSpecRequiresCert reports whether the spec is a named cf or concourse
+// lb or any port of the spec terminates TLS.
+func lbSpecRequiresCert(spec storage.LBSpec) bool {
+	if spec.Type != "" {
+		return true
+	}
+
+	for _, port := range spec.Ports {
+		if port.Protocol == "ssl" || port.Protocol == "https" {
+			return true
+		}
+	}
+	return false
+}
+
+// removeLBSpec returns a copy of the specs without the named one.
+func removeLBSpec(specs []storage.LBSpec, name string) []storage.LBSpec {
+	var remaining []storage.LBSpec
+	for _, spec := range specs {
+		if spec.Name != name {
+			remaining = append(remaining, spec)
+		}
+	}
+	return remaining
+}
+
+// replaceLBSpec returns a copy of the specs with the spec of the same name
+// replaced.
+func replaceLBSpec(specs []storage.LBSpec, replacement storage.LBSpec) []storage.LBSpec {
+	replaced := []storage.LBSpec{}
+	for _, spec := range specs {
+		if spec.Name == replacement.Name {
+			spec = replacement
+		}
+		replaced = append(replaced, spec)
+	}
+	return replaced
+}
+
+// unnamedLBMigration returns the named lb the unnamed lb of an aws
+// environment is migrating into. Until the migration finishes both lbs
+// serve the certificate of the unnamed lb.
+func unnamedLBMigration(state storage.State) (storage.LBSpec, bool) {
+	if !lbExists(state.Stack.LBType) || state.Stack.CertificateName == "" {
+		return storage.LBSpec{}, false
+	}
+
+	for _, spec := range state.LBs {
+		if spec.CertificateName == state.Stack.CertificateName {
+			return spec, true
+		}
+	}
+
+	return storage.LBSpec{}, false
+}
+
+// lbVMExtensions are the names of the vm extensions the cloud config has
+// for a named lb.
+func lbVMExtensions(spec storage.LBSpec) []string {
+	var names []string
+	for _, expanded := range templates.ExpandLBSpecs([]storage.LBSpec{spec}) {
+		names = append(names, expanded.Name+"-lb")
+	}
+	return names
+}
+
+func findLBSpec(specs []storage.LBSpec, name string) (storage.LBSpec, bool) {
+	for _, spec := range specs {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return storage.LBSpec{}, false
+}
+
+// validateLBFlavor rejects an alb for a spec that listens for plain tcp,
+// albs only speak http and https.
+func validateLBFlavor(spec storage.LBSpec) error {
+	for _, expanded := range templates.ExpandLBSpecs([]storage.LBSpec{spec}) {
+		if expanded.Flavor != "alb" {
+			continue
+		}
+
+		for _, port := range expanded.Ports {
+			if port.Protocol == "tcp" {
+				return fmt.Errorf("lb %q listens for tcp on port %d, which an alb cannot serve, use the nlb flavor instead", spec.Name, port.Port)
+			}
+		}
+	}
+
+	return nil
+}
+
+func isValidLBFlavor(flavor string) bool {
+	switch flavor {
+	case "elb", "alb", "nlb":
+		return true
+	default:
+		return false
+	}
+}
+
+func isValidPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+func isValidLBProtocol(protocol string) bool {
+	switch protocol {
+	case "tcp", "ssl", "http", "https":
+		return true
+	default:
+		return false
+	}
+}
+
+func isHTTPProtocol(protocol string) bool {
+	return protocol == "http" || protocol == "https"
+}
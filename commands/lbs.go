@@ -1,13 +1,17 @@
 package commands
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/ssl"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
+	gcpterraform "github.com/cloudfoundry/bosh-bootloader/terraform/gcp"
 )
 
 const (
@@ -19,15 +23,49 @@ type LBs struct {
 	infrastructureManager infrastructureManager
 	stateValidator        stateValidator
 	terraformManager      terraformManager
+	certificateManager    certificateManager
+	renderer              renderer
 	stdout                io.Writer
 }
 
-func NewLBs(credentialValidator credentialValidator, stateValidator stateValidator, infrastructureManager infrastructureManager, terraformManager terraformManager, stdout io.Writer) LBs {
+type LBsOutput struct {
+	CFRouterLB               string   `json:"cf_router_lb,omitempty" yaml:"cf_router_lb,omitempty"`
+	CFRouterLBURL            string   `json:"cf_router_lb_url,omitempty" yaml:"cf_router_lb_url,omitempty"`
+	CFSSHProxyLB             string   `json:"cf_ssh_proxy_lb,omitempty" yaml:"cf_ssh_proxy_lb,omitempty"`
+	CFSSHProxyLBURL          string   `json:"cf_ssh_proxy_lb_url,omitempty" yaml:"cf_ssh_proxy_lb_url,omitempty"`
+	CFTCPRouterLB            string   `json:"cf_tcp_router_lb,omitempty" yaml:"cf_tcp_router_lb,omitempty"`
+	CFWebSocketLB            string   `json:"cf_websocket_lb,omitempty" yaml:"cf_websocket_lb,omitempty"`
+	CFSystemDomainDNSServers []string `json:"cf_system_domain_dns_servers,omitempty" yaml:"cf_system_domain_dns_servers,omitempty"`
+	ConcourseLB              string   `json:"concourse_lb,omitempty" yaml:"concourse_lb,omitempty"`
+	ConcourseLBURL           string   `json:"concourse_lb_url,omitempty" yaml:"concourse_lb_url,omitempty"`
+
+	LBs          []LBSpecOutput        `json:"lbs,omitempty" yaml:"lbs,omitempty"`
+	Certificates []LBCertificateOutput `json:"certificates,omitempty" yaml:"certificates,omitempty"`
+}
+
+type LBSpecOutput struct {
+	Name  string `json:"name" yaml:"name"`
+	LB    string `json:"lb" yaml:"lb"`
+	LBURL string `json:"lb_url,omitempty" yaml:"lb_url,omitempty"`
+}
+
+// LBCertificateOutput describes a certificate served by the lb with the
+// given name, or by the unnamed lb of the given type.
+type LBCertificateOutput struct {
+	LB       string    `json:"lb" yaml:"lb"`
+	Subject  string    `json:"subject" yaml:"subject"`
+	NotAfter time.Time `json:"not_after" yaml:"not_after"`
+}
+
+func NewLBs(credentialValidator credentialValidator, stateValidator stateValidator, infrastructureManager infrastructureManager, terraformManager terraformManager,
+	certificateManager certificateManager, renderer renderer, stdout io.Writer) LBs {
 	return LBs{
 		credentialValidator:   credentialValidator,
 		infrastructureManager: infrastructureManager,
 		stateValidator:        stateValidator,
 		terraformManager:      terraformManager,
+		certificateManager:    certificateManager,
+		renderer:              renderer,
 		stdout:                stdout,
 	}
 }
@@ -38,6 +76,39 @@ func (c LBs) Execute(subcommandFlags []string, state storage.State) error {
 		return err
 	}
 
+	var (
+		jsonOutput bool
+		name       string
+	)
+	lbsFlags := flags.New(LBsCommand)
+	lbsFlags.Bool(&jsonOutput, "", "json", false)
+	lbsFlags.String(&name, "name", "")
+	err = lbsFlags.Parse(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	renderer := c.renderer
+	if jsonOutput {
+		renderer = NewRenderer(c.stdout, JSONOutput)
+	}
+
+	lbType := state.Stack.LBType
+	if state.IAAS == "gcp" {
+		lbType = state.LB.Type
+	}
+
+	specs := state.LBs
+	if name != "" {
+		spec, ok := findLBSpec(state.LBs, name)
+		if !ok {
+			return fmt.Errorf("no load balancer named %q has been found for this bbl environment", name)
+		}
+
+		specs = []storage.LBSpec{spec}
+		lbType = ""
+	}
+
 	switch state.IAAS {
 	case "aws":
 		err = c.credentialValidator.Validate()
@@ -50,59 +121,292 @@ func (c LBs) Execute(subcommandFlags []string, state storage.State) error {
 			return err
 		}
 
-		switch state.Stack.LBType {
+		var output LBsOutput
+		switch lbType {
 		case "cf":
-			fmt.Fprintf(c.stdout, "CF Router LB: %s [%s]\n", stack.Outputs["CFRouterLoadBalancer"], stack.Outputs["CFRouterLoadBalancerURL"])
-			fmt.Fprintf(c.stdout, "CF SSH Proxy LB: %s [%s]\n", stack.Outputs["CFSSHProxyLoadBalancer"], stack.Outputs["CFSSHProxyLoadBalancerURL"])
+			output = LBsOutput{
+				CFRouterLB:      stack.Outputs["CFRouterLoadBalancer"],
+				CFRouterLBURL:   stack.Outputs["CFRouterLoadBalancerURL"],
+				CFSSHProxyLB:    stack.Outputs["CFSSHProxyLoadBalancer"],
+				CFSSHProxyLBURL: stack.Outputs["CFSSHProxyLoadBalancerURL"],
+			}
 		case "concourse":
-			fmt.Fprintf(c.stdout, "Concourse LB: %s [%s]\n", stack.Outputs["ConcourseLoadBalancer"], stack.Outputs["ConcourseLoadBalancerURL"])
-		default:
+			output = LBsOutput{
+				ConcourseLB:    stack.Outputs["ConcourseLoadBalancer"],
+				ConcourseLBURL: stack.Outputs["ConcourseLoadBalancerURL"],
+			}
+		}
+
+		for _, spec := range templates.ExpandLBSpecs(specs) {
+			lbName := templates.LBSpecLoadBalancerName(spec)
+			output.LBs = append(output.LBs, LBSpecOutput{
+				Name:  spec.Name,
+				LB:    stack.Outputs[lbName],
+				LBURL: stack.Outputs[lbName+"URL"],
+			})
+		}
+
+		if !lbExists(lbType) && len(output.LBs) == 0 {
 			return errors.New("no lbs found")
 		}
+
+		output.Certificates, err = c.awsCertificates(lbType, state.Stack.CertificateName, specs)
+		if err != nil {
+			return err
+		}
+
+		if renderer.Structured() {
+			return renderer.Render(output)
+		}
+
+		switch lbType {
+		case "cf":
+			fmt.Fprintf(c.stdout, "CF Router LB: %s [%s]\n", output.CFRouterLB, output.CFRouterLBURL)
+			fmt.Fprintf(c.stdout, "CF SSH Proxy LB: %s [%s]\n", output.CFSSHProxyLB, output.CFSSHProxyLBURL)
+		case "concourse":
+			fmt.Fprintf(c.stdout, "Concourse LB: %s [%s]\n", output.ConcourseLB, output.ConcourseLBURL)
+		}
+
+		for _, lb := range output.LBs {
+			fmt.Fprintf(c.stdout, "%s LB: %s [%s]\n", lb.Name, lb.LB, lb.LBURL)
+		}
+
+		c.printCertificates(output.Certificates)
 	case "gcp":
 		terraformOutputs, err := c.terraformManager.GetOutputs(state)
 		if err != nil {
 			return err
 		}
 
-		switch state.LB.Type {
+		var output LBsOutput
+		switch lbType {
 		case "cf":
-			if len(subcommandFlags) > 0 && subcommandFlags[0] == "--json" {
-				lbOutput, err := json.Marshal(struct {
-					RouterLBIP             string   `json:"cf_router_lb,omitempty"`
-					SSHProxyLBIP           string   `json:"cf_ssh_proxy_lb,omitempty"`
-					TCPRouterLBIP          string   `json:"cf_tcp_router_lb,omitempty"`
-					WebSocketLBIP          string   `json:"cf_websocket_lb,omitempty"`
-					SystemDomainDNSServers []string `json:"cf_system_domain_dns_servers,omitempty"`
-				}{
-					RouterLBIP:             terraformOutputs["router_lb_ip"].(string),
-					SSHProxyLBIP:           terraformOutputs["ssh_proxy_lb_ip"].(string),
-					TCPRouterLBIP:          terraformOutputs["tcp_router_lb_ip"].(string),
-					WebSocketLBIP:          terraformOutputs["ws_lb_ip"].(string),
-					SystemDomainDNSServers: terraformOutputs["system_domain_dns_servers"].([]string),
-				})
+			output.CFRouterLB, err = lbIP(terraformOutputs, "router_lb_ip")
+			if err != nil {
+				return err
+			}
+
+			output.CFSSHProxyLB, err = lbIP(terraformOutputs, "ssh_proxy_lb_ip")
+			if err != nil {
+				return err
+			}
+
+			output.CFTCPRouterLB, err = lbIP(terraformOutputs, "tcp_router_lb_ip")
+			if err != nil {
+				return err
+			}
+
+			output.CFWebSocketLB, err = lbIP(terraformOutputs, "ws_lb_ip")
+			if err != nil {
+				return err
+			}
+
+			if dnsServers, ok := terraformOutputs["system_domain_dns_servers"].([]string); ok {
+				output.CFSystemDomainDNSServers = dnsServers
+			}
+		case "concourse":
+			output.ConcourseLB, err = lbIP(terraformOutputs, "concourse_lb_ip")
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, spec := range specs {
+			if spec.Type != "cf" {
+				ip, err := lbIP(terraformOutputs, gcpterraform.LBSpecOutputName(spec.Name, "lb_ip"))
 				if err != nil {
-					// not tested
 					return err
 				}
 
-				fmt.Fprintf(c.stdout, "%s\n", string(lbOutput))
-			} else {
-				fmt.Fprintf(c.stdout, "CF Router LB: %s\n", terraformOutputs["router_lb_ip"])
-				fmt.Fprintf(c.stdout, "CF SSH Proxy LB: %s\n", terraformOutputs["ssh_proxy_lb_ip"])
-				fmt.Fprintf(c.stdout, "CF TCP Router LB: %s\n", terraformOutputs["tcp_router_lb_ip"])
-				fmt.Fprintf(c.stdout, "CF WebSocket LB: %s\n", terraformOutputs["ws_lb_ip"])
+				output.LBs = append(output.LBs, LBSpecOutput{
+					Name: spec.Name,
+					LB:   ip,
+				})
+				continue
+			}
 
-				if dnsServers, ok := terraformOutputs["system_domain_dns_servers"]; ok {
-					fmt.Fprintf(c.stdout, "CF System Domain DNS servers: %s\n", strings.Join(dnsServers.([]string), " "))
+			for _, lb := range []struct{ suffix, output string }{
+				{"router", "router_lb_ip"},
+				{"ssh-proxy", "ssh_proxy_lb_ip"},
+				{"tcp-router", "tcp_router_lb_ip"},
+				{"ws", "ws_lb_ip"},
+			} {
+				ip, err := lbIP(terraformOutputs, gcpterraform.LBSpecOutputName(spec.Name, lb.output))
+				if err != nil {
+					return err
 				}
+
+				output.LBs = append(output.LBs, LBSpecOutput{
+					Name: fmt.Sprintf("%s-%s", spec.Name, lb.suffix),
+					LB:   ip,
+				})
 			}
-		case "concourse":
-			fmt.Fprintf(c.stdout, "Concourse LB: %s\n", terraformOutputs["concourse_lb_ip"])
-		default:
+		}
+
+		if !lbExists(lbType) && len(output.LBs) == 0 {
 			return errors.New("no lbs found")
 		}
+
+		output.Certificates, err = gcpCertificates(lbType, state.LB, specs)
+		if err != nil {
+			return err
+		}
+
+		if renderer.Structured() {
+			return renderer.Render(output)
+		}
+
+		switch lbType {
+		case "cf":
+			fmt.Fprintf(c.stdout, "CF Router LB: %s\n", output.CFRouterLB)
+			fmt.Fprintf(c.stdout, "CF SSH Proxy LB: %s\n", output.CFSSHProxyLB)
+			fmt.Fprintf(c.stdout, "CF TCP Router LB: %s\n", output.CFTCPRouterLB)
+			fmt.Fprintf(c.stdout, "CF WebSocket LB: %s\n", output.CFWebSocketLB)
+
+			if len(output.CFSystemDomainDNSServers) > 0 {
+				fmt.Fprintf(c.stdout, "CF System Domain DNS servers: %s\n", strings.Join(output.CFSystemDomainDNSServers, " "))
+			}
+		case "concourse":
+			fmt.Fprintf(c.stdout, "Concourse LB: %s\n", output.ConcourseLB)
+		}
+
+		for _, lb := range output.LBs {
+			fmt.Fprintf(c.stdout, "%s LB: %s\n", lb.Name, lb.LB)
+		}
+
+		c.printCertificates(output.Certificates)
 	}
 
 	return nil
 }
+
+// lbIP reads the ip of an lb from the terraform outputs, which lack it when
+// the lb was created by an older bbl or its terraform apply failed.
+func lbIP(terraformOutputs map[string]interface{}, outputName string) (string, error) {
+	ip, ok := terraformOutputs[outputName].(string)
+	if !ok {
+		return "", fmt.Errorf("the terraform output %q is missing, run update-lbs to recreate the lb", outputName)
+	}
+
+	return ip, nil
+}
+
+// awsCertificates describes the iam certificates of the unnamed lb and of
+// the given lb specs.
+func (c LBs) awsCertificates(lbType, certificateName string, specs []storage.LBSpec) ([]LBCertificateOutput, error) {
+	certs, err := awsLBCertificatePEMs(c.certificateManager, lbType, certificateName, specs)
+	if err != nil {
+		return nil, err
+	}
+
+	return describeLBCertificates(certs)
+}
+
+// gcpCertificates describes the certificates of the unnamed lb and of the
+// given lb specs, which bbl keeps in its state on gcp.
+func gcpCertificates(lbType string, lb storage.LB, specs []storage.LBSpec) ([]LBCertificateOutput, error) {
+	return describeLBCertificates(gcpLBCertificatePEMs(lbType, lb, specs))
+}
+
+// lbCertificatePEM is a PEM encoded certificate served by the lb with the
+// given name, or by the unnamed lb of the given type.
+type lbCertificatePEM struct {
+	lb   string
+	cert string
+}
+
+// awsLBCertificatePEMs fetches the iam certificates of the unnamed lb and
+// of the given lb specs.
+func awsLBCertificatePEMs(certificateManager certificateManager, lbType, certificateName string, specs []storage.LBSpec) ([]lbCertificatePEM, error) {
+	var certs []lbCertificatePEM
+	describe := func(lb, certificateName string) error {
+		if certificateName == "" {
+			return nil
+		}
+
+		certificate, err := certificateManager.Describe(certificateName)
+		if err != nil {
+			return err
+		}
+
+		certs = append(certs, lbCertificatePEM{lb, certificate.Body})
+		return nil
+	}
+
+	if lbExists(lbType) {
+		if err := describe(lbType, certificateName); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, spec := range specs {
+		if err := describe(spec.Name, spec.CertificateName); err != nil {
+			return nil, err
+		}
+
+		for _, sniCertificate := range spec.SNICertificates {
+			if err := describe(spec.Name, sniCertificate.Name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return certs, nil
+}
+
+// gcpLBCertificatePEMs lists the certificates of the unnamed lb and of the
+// given lb specs from the state.
+func gcpLBCertificatePEMs(lbType string, lb storage.LB, specs []storage.LBSpec) []lbCertificatePEM {
+	var certs []lbCertificatePEM
+	if lbExists(lbType) && lb.Cert != "" {
+		certs = append(certs, lbCertificatePEM{lbType, lb.Cert})
+		for _, sniCertificate := range lb.SNICertificates {
+			certs = append(certs, lbCertificatePEM{lbType, sniCertificate.Cert})
+		}
+	}
+
+	for _, spec := range specs {
+		if spec.Cert == "" {
+			continue
+		}
+
+		certs = append(certs, lbCertificatePEM{spec.Name, spec.Cert})
+		for _, sniCertificate := range spec.SNICertificates {
+			certs = append(certs, lbCertificatePEM{spec.Name, sniCertificate.Cert})
+		}
+	}
+
+	return certs
+}
+
+func describeLBCertificates(certs []lbCertificatePEM) ([]LBCertificateOutput, error) {
+	var certificates []LBCertificateOutput
+	for _, cert := range certs {
+		output, err := describeLBCertificate(cert.lb, cert.cert)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, output)
+	}
+
+	return certificates, nil
+}
+
+func describeLBCertificate(lb, cert string) (LBCertificateOutput, error) {
+	info, err := ssl.DescribeCertificate([]byte(cert))
+	if err != nil {
+		return LBCertificateOutput{}, fmt.Errorf("failed to describe certificate of lb %q: %s", lb, err)
+	}
+
+	return LBCertificateOutput{
+		LB:       lb,
+		Subject:  info.Subject,
+		NotAfter: info.NotAfter,
+	}, nil
+}
+
+func (c LBs) printCertificates(certificates []LBCertificateOutput) {
+	for _, certificate := range certificates {
+		fmt.Fprintf(c.stdout, "%s certificate: %s [expires %s]\n", certificate.LB, certificate.Subject, certificate.NotAfter.Format(time.RFC3339))
+	}
+}
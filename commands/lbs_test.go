@@ -5,9 +5,11 @@ import (
 	"errors"
 
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
+	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
 	"github.com/cloudfoundry/bosh-bootloader/commands"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
+	"github.com/cloudfoundry/bosh-bootloader/testhelpers"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -19,6 +21,8 @@ var _ = Describe("LBs", func() {
 		infrastructureManager *fakes.InfrastructureManager
 		stateValidator        *fakes.StateValidator
 		terraformManager      *fakes.TerraformManager
+		certificateManager    *fakes.CertificateManager
+		renderer              *fakes.Renderer
 		lbsCommand            commands.LBs
 		stdout                *bytes.Buffer
 		incomingState         storage.State
@@ -36,9 +40,11 @@ var _ = Describe("LBs", func() {
 			"ws_lb_ip":         "some-ws-lb-ip",
 			"concourse_lb_ip":  "some-concourse-lb-ip",
 		}
+		certificateManager = &fakes.CertificateManager{}
+		renderer = &fakes.Renderer{}
 		stdout = bytes.NewBuffer([]byte{})
 
-		lbsCommand = commands.NewLBs(credentialValidator, stateValidator, infrastructureManager, terraformManager, stdout)
+		lbsCommand = commands.NewLBs(credentialValidator, stateValidator, infrastructureManager, terraformManager, certificateManager, renderer, stdout)
 	})
 
 	Describe("Execute", func() {
@@ -100,6 +106,165 @@ var _ = Describe("LBs", func() {
 				Expect(stdout.String()).To(ContainSubstring("Concourse LB: some-lb-name [http://some.lb.url]"))
 			})
 
+			It("renders LB names and URLs when structured output is requested", func() {
+				renderer.StructuredCall.Returns.Structured = true
+				infrastructureManager.DescribeCall.Returns.Stack = cloudformation.Stack{
+					Name: "some-stack-name",
+					Outputs: map[string]string{
+						"ConcourseLoadBalancer":    "some-lb-name",
+						"ConcourseLoadBalancerURL": "http://some.lb.url",
+					},
+				}
+
+				incomingState.Stack = storage.Stack{
+					LBType: "concourse",
+					Name:   "some-stack-name",
+				}
+				err := lbsCommand.Execute([]string{}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(renderer.RenderCall.Receives.Value).To(Equal(commands.LBsOutput{
+					ConcourseLB:    "some-lb-name",
+					ConcourseLBURL: "http://some.lb.url",
+				}))
+				Expect(stdout.String()).To(BeEmpty())
+			})
+
+			It("prints LB names and URLs for lb specs", func() {
+				infrastructureManager.DescribeCall.Returns.Stack = cloudformation.Stack{
+					Name: "some-stack-name",
+					Outputs: map[string]string{
+						"ConcourseLoadBalancer":     "some-lb-name",
+						"ConcourseLoadBalancerURL":  "http://some.lb.url",
+						"CredhubUaaLoadBalancer":    "some-credhub-uaa-lb-name",
+						"CredhubUaaLoadBalancerURL": "http://some.credhub-uaa.lb.url",
+					},
+				}
+
+				incomingState.Stack = storage.Stack{
+					LBType: "concourse",
+					Name:   "some-stack-name",
+				}
+				incomingState.LBs = []storage.LBSpec{{Name: "credhub-uaa"}}
+				err := lbsCommand.Execute([]string{}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stdout.String()).To(ContainSubstring("Concourse LB: some-lb-name [http://some.lb.url]"))
+				Expect(stdout.String()).To(ContainSubstring("credhub-uaa LB: some-credhub-uaa-lb-name [http://some.credhub-uaa.lb.url]"))
+			})
+
+			It("renders lb specs without an lb type when structured output is requested", func() {
+				renderer.StructuredCall.Returns.Structured = true
+				infrastructureManager.DescribeCall.Returns.Stack = cloudformation.Stack{
+					Outputs: map[string]string{
+						"VaultLoadBalancer":    "some-vault-lb-name",
+						"VaultLoadBalancerURL": "http://some.vault.lb.url",
+					},
+				}
+
+				incomingState.Stack = storage.Stack{
+					LBType: "none",
+				}
+				incomingState.LBs = []storage.LBSpec{{Name: "vault"}}
+				err := lbsCommand.Execute([]string{}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(renderer.RenderCall.Receives.Value).To(Equal(commands.LBsOutput{
+					LBs: []commands.LBSpecOutput{
+						{
+							Name:  "vault",
+							LB:    "some-vault-lb-name",
+							LBURL: "http://some.vault.lb.url",
+						},
+					},
+				}))
+			})
+
+			It("prints only the elbs of the named lb when --name is provided", func() {
+				infrastructureManager.DescribeCall.Returns.Stack = cloudformation.Stack{
+					Outputs: map[string]string{
+						"ConcourseLoadBalancer":             "some-lb-name",
+						"FoundationRouterLoadBalancer":      "some-router-lb-name",
+						"FoundationRouterLoadBalancerURL":   "http://some.router.lb.url",
+						"FoundationSshProxyLoadBalancer":    "some-ssh-proxy-lb-name",
+						"FoundationSshProxyLoadBalancerURL": "http://some.ssh-proxy.lb.url",
+						"VaultLoadBalancer":                 "some-vault-lb-name",
+					},
+				}
+
+				incomingState.Stack = storage.Stack{
+					LBType: "concourse",
+				}
+				incomingState.LBs = []storage.LBSpec{{Name: "vault"}, {Name: "foundation", Type: "cf"}}
+				err := lbsCommand.Execute([]string{"--name", "foundation"}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stdout.String()).To(Equal(`foundation-router LB: some-router-lb-name [http://some.router.lb.url]
+foundation-ssh-proxy LB: some-ssh-proxy-lb-name [http://some.ssh-proxy.lb.url]
+`))
+			})
+
+			It("prints the albs and nlbs of lb specs of those flavors", func() {
+				infrastructureManager.DescribeCall.Returns.Stack = cloudformation.Stack{
+					Outputs: map[string]string{
+						"FoundationRouterApplicationLoadBalancer":    "some-router-alb-name",
+						"FoundationRouterApplicationLoadBalancerURL": "http://some.router.alb.url",
+						"FoundationSshProxyNetworkLoadBalancer":      "some-ssh-proxy-nlb-name",
+						"FoundationSshProxyNetworkLoadBalancerURL":   "http://some.ssh-proxy.nlb.url",
+					},
+				}
+
+				incomingState.LBs = []storage.LBSpec{{Name: "foundation", Type: "cf", Flavor: "alb"}}
+				err := lbsCommand.Execute([]string{"--name", "foundation"}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stdout.String()).To(Equal(`foundation-router LB: some-router-alb-name [http://some.router.alb.url]
+foundation-ssh-proxy LB: some-ssh-proxy-nlb-name [http://some.ssh-proxy.nlb.url]
+`))
+			})
+
+			It("prints the subject and expiry of each certificate of an lb", func() {
+				infrastructureManager.DescribeCall.Returns.Stack = cloudformation.Stack{
+					Outputs: map[string]string{
+						"VaultApplicationLoadBalancer":    "some-vault-alb-name",
+						"VaultApplicationLoadBalancerURL": "http://some.vault.alb.url",
+					},
+				}
+				certificateManager.DescribeCall.Stub = func(certificateName string) (iam.Certificate, error) {
+					return iam.Certificate{Name: certificateName, Body: testhelpers.BBL_CERT}, nil
+				}
+
+				incomingState.LBs = []storage.LBSpec{{
+					Name:            "vault",
+					Flavor:          "alb",
+					Ports:           []storage.LBPort{{Port: 443, InstancePort: 8200, Protocol: "https"}},
+					CertificateName: "some-certificate-name",
+					SNICertificates: []storage.LBCertificate{{Name: "some-sni-certificate-name"}},
+				}}
+				err := lbsCommand.Execute([]string{}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certificateManager.DescribeCall.CallCount).To(Equal(2))
+				Expect(certificateManager.DescribeCall.Receives.CertificateName).To(Equal("some-sni-certificate-name"))
+				Expect(stdout.String()).To(Equal(`vault LB: some-vault-alb-name [http://some.vault.alb.url]
+vault certificate: bbl-intermediate [expires 2018-05-26T22:13:41Z]
+vault certificate: bbl-intermediate [expires 2018-05-26T22:13:41Z]
+`))
+			})
+
+			It("returns an error when a certificate cannot be described", func() {
+				certificateManager.DescribeCall.Returns.Error = errors.New("failed to describe")
+				incomingState.Stack = storage.Stack{LBType: "cf", CertificateName: "some-certificate-name"}
+
+				err := lbsCommand.Execute([]string{}, incomingState)
+				Expect(err).To(MatchError("failed to describe"))
+			})
+
+			It("returns an error when the named lb does not exist", func() {
+				err := lbsCommand.Execute([]string{"--name", "foundation"}, incomingState)
+				Expect(err).To(MatchError(`no load balancer named "foundation" has been found for this bbl environment`))
+			})
+
 			It("returns error when lb type is not cf or concourse", func() {
 				incomingState.Stack = storage.Stack{
 					LBType: "",
@@ -205,6 +370,80 @@ var _ = Describe("LBs", func() {
 				})
 			})
 
+			It("renders LB ips when structured output is requested", func() {
+				renderer.StructuredCall.Returns.Structured = true
+				incomingState.LB = storage.LB{
+					Type: "cf",
+				}
+				err := lbsCommand.Execute([]string{}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(renderer.RenderCall.Receives.Value).To(Equal(commands.LBsOutput{
+					CFRouterLB:    "some-router-lb-ip",
+					CFSSHProxyLB:  "some-ssh-proxy-lb-ip",
+					CFTCPRouterLB: "some-tcp-router-lb-ip",
+					CFWebSocketLB: "some-ws-lb-ip",
+				}))
+				Expect(stdout.String()).To(BeEmpty())
+			})
+
+			It("returns an error when the renderer fails", func() {
+				renderer.StructuredCall.Returns.Structured = true
+				renderer.RenderCall.Returns.Error = errors.New("failed to render")
+				incomingState.LB = storage.LB{
+					Type: "concourse",
+				}
+				err := lbsCommand.Execute([]string{}, incomingState)
+				Expect(err).To(MatchError("failed to render"))
+			})
+
+			It("prints LB ips for lb specs", func() {
+				terraformManager.GetOutputsCall.Returns.Outputs["lb_credhub_uaa_lb_ip"] = "some-credhub-uaa-lb-ip"
+				incomingState.LBs = []storage.LBSpec{{Name: "credhub-uaa"}}
+
+				err := lbsCommand.Execute([]string{}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stdout.String()).To(Equal("credhub-uaa LB: some-credhub-uaa-lb-ip\n"))
+			})
+
+			It("prints LB ips for named cf lbs", func() {
+				for _, output := range []string{"router", "ssh_proxy", "tcp_router", "ws"} {
+					terraformManager.GetOutputsCall.Returns.Outputs["lb_foundation_"+output+"_lb_ip"] = "some-foundation-" + output + "-lb-ip"
+				}
+				incomingState.LBs = []storage.LBSpec{{Name: "foundation", Type: "cf"}}
+
+				err := lbsCommand.Execute([]string{"--name", "foundation"}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stdout.String()).To(Equal(`foundation-router LB: some-foundation-router-lb-ip
+foundation-ssh-proxy LB: some-foundation-ssh_proxy-lb-ip
+foundation-tcp-router LB: some-foundation-tcp_router-lb-ip
+foundation-ws LB: some-foundation-ws-lb-ip
+`))
+			})
+
+			It("prints the subject and expiry of each certificate of the cf lb", func() {
+				incomingState.LB = storage.LB{
+					Type:            "cf",
+					Cert:            testhelpers.BBL_CERT,
+					SNICertificates: []storage.LBCertificate{{Cert: testhelpers.BBL_CERT}},
+				}
+				err := lbsCommand.Execute([]string{}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stdout.String()).To(ContainSubstring(`cf certificate: bbl-intermediate [expires 2018-05-26T22:13:41Z]
+cf certificate: bbl-intermediate [expires 2018-05-26T22:13:41Z]
+`))
+			})
+
+			It("returns an error when a certificate cannot be parsed", func() {
+				incomingState.LB = storage.LB{Type: "cf", Cert: "some-cert"}
+
+				err := lbsCommand.Execute([]string{}, incomingState)
+				Expect(err).To(MatchError(`failed to describe certificate of lb "cf": certificate is not PEM encoded`))
+			})
+
 			It("prints LB ips for lb type concourse", func() {
 				incomingState.LB = storage.LB{
 					Type: "concourse",
@@ -223,6 +462,21 @@ var _ = Describe("LBs", func() {
 					Expect(err).To(MatchError("failed to return terraform output"))
 				})
 
+				It("returns an error when the terraform outputs lack the ip of an lb", func() {
+					delete(terraformManager.GetOutputsCall.Returns.Outputs, "ws_lb_ip")
+					incomingState.LB = storage.LB{
+						Type: "cf",
+					}
+					err := lbsCommand.Execute([]string{}, incomingState)
+					Expect(err).To(MatchError(`the terraform output "ws_lb_ip" is missing, run update-lbs to recreate the lb`))
+				})
+
+				It("returns an error when the terraform outputs lack the ip of an lb spec", func() {
+					incomingState.LBs = []storage.LBSpec{{Name: "credhub-uaa"}}
+					err := lbsCommand.Execute([]string{}, incomingState)
+					Expect(err).To(MatchError(`the terraform output "lb_credhub_uaa_lb_ip" is missing, run update-lbs to recreate the lb`))
+				})
+
 				It("returns an nice error message when no lb type is found", func() {
 					incomingState.LB = storage.LB{
 						Type: "",
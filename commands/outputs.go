@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	OutputsCommand = "outputs"
+)
+
+type Outputs struct {
+	logger                logger
+	stateValidator        stateValidator
+	terraformManager      terraformManager
+	infrastructureManager infrastructureManager
+	renderer              renderer
+}
+
+type OutputsOutput struct {
+	IAAS           string                 `json:"iaas" yaml:"iaas"`
+	EnvID          string                 `json:"env_id" yaml:"env_id"`
+	Director       *DirectorOutput        `json:"director,omitempty" yaml:"director,omitempty"`
+	Infrastructure map[string]interface{} `json:"infrastructure" yaml:"infrastructure"`
+}
+
+type DirectorOutput struct {
+	Name     string `json:"name" yaml:"name"`
+	Address  string `json:"address" yaml:"address"`
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+	CACert   string `json:"ca_cert" yaml:"ca_cert"`
+}
+
+func NewOutputs(logger logger, stateValidator stateValidator, terraformManager terraformManager, infrastructureManager infrastructureManager, renderer renderer) Outputs {
+	return Outputs{
+		logger:                logger,
+		stateValidator:        stateValidator,
+		terraformManager:      terraformManager,
+		infrastructureManager: infrastructureManager,
+		renderer:              renderer,
+	}
+}
+
+func (o Outputs) Execute(subcommandFlags []string, state storage.State) error {
+	err := o.stateValidator.Validate()
+	if err != nil {
+		return err
+	}
+
+	infrastructure, err := o.getInfrastructureOutputs(state)
+	if err != nil {
+		return err
+	}
+
+	output := OutputsOutput{
+		IAAS:           state.IAAS,
+		EnvID:          state.EnvID,
+		Infrastructure: infrastructure,
+	}
+
+	if !state.NoDirector {
+		output.Director = &DirectorOutput{
+			Name:     state.BOSH.DirectorName,
+			Address:  state.BOSH.DirectorAddress,
+			Username: state.BOSH.DirectorUsername,
+			Password: state.BOSH.DirectorPassword,
+			CACert:   state.BOSH.DirectorSSLCA,
+		}
+	}
+
+	if o.renderer.Structured() {
+		return o.renderer.Render(output)
+	}
+
+	o.logger.Println(fmt.Sprintf("iaas: %s", output.IAAS))
+	o.logger.Println(fmt.Sprintf("env_id: %s", output.EnvID))
+	if output.Director != nil {
+		o.logger.Println(fmt.Sprintf("director_name: %s", output.Director.Name))
+		o.logger.Println(fmt.Sprintf("director_address: %s", output.Director.Address))
+		o.logger.Println(fmt.Sprintf("director_username: %s", output.Director.Username))
+	}
+
+	var names []string
+	for name := range output.Infrastructure {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := output.Infrastructure[name]
+		if values, ok := value.([]string); ok {
+			value = strings.Join(values, " ")
+		}
+		o.logger.Println(fmt.Sprintf("%s: %v", name, value))
+	}
+
+	return nil
+}
+
+func (o Outputs) getInfrastructureOutputs(state storage.State) (map[string]interface{}, error) {
+	outputs := map[string]interface{}{}
+
+	switch {
+	case state.IAAS == "aws" && state.Stack.Name != "":
+		stack, err := o.infrastructureManager.Describe(state.Stack.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		for name, value := range stack.Outputs {
+			outputs[name] = value
+		}
+	case state.TFState != "":
+		terraformOutputs, err := o.terraformManager.GetOutputs(state)
+		if err != nil {
+			return nil, err
+		}
+
+		for name, value := range terraformOutputs {
+			outputs[name] = value
+		}
+	}
+
+	return outputs, nil
+}
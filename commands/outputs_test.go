@@ -0,0 +1,176 @@
+package commands_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Outputs", func() {
+	var (
+		logger                *fakes.Logger
+		stateValidator        *fakes.StateValidator
+		terraformManager      *fakes.TerraformManager
+		infrastructureManager *fakes.InfrastructureManager
+		renderer              *fakes.Renderer
+		outputs               commands.Outputs
+		state                 storage.State
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		stateValidator = &fakes.StateValidator{}
+		terraformManager = &fakes.TerraformManager{}
+		infrastructureManager = &fakes.InfrastructureManager{}
+		renderer = &fakes.Renderer{}
+
+		state = storage.State{
+			IAAS:    "gcp",
+			EnvID:   "some-env-id",
+			TFState: "some-tf-state",
+			BOSH: storage.BOSH{
+				DirectorName:     "some-director-name",
+				DirectorAddress:  "some-director-address",
+				DirectorUsername: "some-director-username",
+				DirectorPassword: "some-director-password",
+				DirectorSSLCA:    "some-director-ca-cert",
+			},
+		}
+
+		terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
+			"network_name":              "some-network-name",
+			"external_ip":               "some-external-ip",
+			"system_domain_dns_servers": []string{"name-server-1.", "name-server-2."},
+		}
+
+		outputs = commands.NewOutputs(logger, stateValidator, terraformManager, infrastructureManager, renderer)
+	})
+
+	Describe("Execute", func() {
+		It("prints the outputs and director information", func() {
+			err := outputs.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stateValidator.ValidateCall.CallCount).To(Equal(1))
+			Expect(terraformManager.GetOutputsCall.Receives.BBLState).To(Equal(state))
+			Expect(logger.PrintlnCall.Messages).To(Equal([]string{
+				"iaas: gcp",
+				"env_id: some-env-id",
+				"director_name: some-director-name",
+				"director_address: some-director-address",
+				"director_username: some-director-username",
+				"external_ip: some-external-ip",
+				"network_name: some-network-name",
+				"system_domain_dns_servers: name-server-1. name-server-2.",
+			}))
+		})
+
+		Context("when structured output is requested", func() {
+			BeforeEach(func() {
+				renderer.StructuredCall.Returns.Structured = true
+			})
+
+			It("renders the outputs and director information", func() {
+				err := outputs.Execute([]string{}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(renderer.RenderCall.Receives.Value).To(Equal(commands.OutputsOutput{
+					IAAS:  "gcp",
+					EnvID: "some-env-id",
+					Director: &commands.DirectorOutput{
+						Name:     "some-director-name",
+						Address:  "some-director-address",
+						Username: "some-director-username",
+						Password: "some-director-password",
+						CACert:   "some-director-ca-cert",
+					},
+					Infrastructure: map[string]interface{}{
+						"network_name":              "some-network-name",
+						"external_ip":               "some-external-ip",
+						"system_domain_dns_servers": []string{"name-server-1.", "name-server-2."},
+					},
+				}))
+				Expect(logger.PrintlnCall.CallCount).To(Equal(0))
+			})
+
+			It("omits the director when bbl does not manage it", func() {
+				state.NoDirector = true
+
+				err := outputs.Execute([]string{}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(renderer.RenderCall.Receives.Value.(commands.OutputsOutput).Director).To(BeNil())
+			})
+
+			It("renders the cloudformation outputs for an aws stack", func() {
+				infrastructureManager.DescribeCall.Returns.Stack = cloudformation.Stack{
+					Outputs: map[string]string{
+						"BOSHEIP": "some-bosh-eip",
+					},
+				}
+
+				err := outputs.Execute([]string{}, storage.State{
+					IAAS:       "aws",
+					EnvID:      "some-env-id",
+					NoDirector: true,
+					Stack: storage.Stack{
+						Name: "some-stack-name",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(infrastructureManager.DescribeCall.Receives.StackName).To(Equal("some-stack-name"))
+				Expect(terraformManager.GetOutputsCall.CallCount).To(Equal(0))
+				Expect(renderer.RenderCall.Receives.Value).To(Equal(commands.OutputsOutput{
+					IAAS:  "aws",
+					EnvID: "some-env-id",
+					Infrastructure: map[string]interface{}{
+						"BOSHEIP": "some-bosh-eip",
+					},
+				}))
+			})
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the state validator fails", func() {
+				stateValidator.ValidateCall.Returns.Error = errors.New("failed to validate state")
+
+				err := outputs.Execute([]string{}, state)
+				Expect(err).To(MatchError("failed to validate state"))
+			})
+
+			It("returns an error when the terraform manager fails", func() {
+				terraformManager.GetOutputsCall.Returns.Error = errors.New("failed to get terraform output")
+
+				err := outputs.Execute([]string{}, state)
+				Expect(err).To(MatchError("failed to get terraform output"))
+			})
+
+			It("returns an error when the infrastructure manager fails", func() {
+				infrastructureManager.DescribeCall.Returns.Error = errors.New("failed to describe stack")
+
+				err := outputs.Execute([]string{}, storage.State{
+					IAAS: "aws",
+					Stack: storage.Stack{
+						Name: "some-stack-name",
+					},
+				})
+				Expect(err).To(MatchError("failed to describe stack"))
+			})
+
+			It("returns an error when the renderer fails", func() {
+				renderer.StructuredCall.Returns.Structured = true
+				renderer.RenderCall.Returns.Error = errors.New("failed to render")
+
+				err := outputs.Execute([]string{}, state)
+				Expect(err).To(MatchError("failed to render"))
+			})
+		})
+	})
+})
@@ -0,0 +1,280 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws"
+	"github.com/cloudfoundry/bosh-bootloader/aws/acm"
+	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/gcp"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const PreflightCommand = "preflight"
+
+type Preflight struct {
+	awsCredentialValidator credentialValidator
+	configProvider         configProvider
+	awsPermissionChecker   awsPermissionChecker
+	gcpProvider            gcpProvider
+	gcpPreflightChecker    gcpPreflightChecker
+	envGetter              envGetter
+	logger                 logger
+}
+
+type awsPermissionChecker interface {
+	MissingActions(actions []string) ([]string, error)
+}
+
+type gcpPreflightChecker interface {
+	ValidateServiceAccountKey(serviceAccountKey string) error
+	DisabledAPIs(state storage.State) ([]string, error)
+	MissingPermissions(permissions []string) ([]string, error)
+	QuotaShortfalls(state storage.State) ([]string, error)
+}
+
+type preflightConfig struct {
+	iaas                 string
+	awsAccessKeyID       string
+	awsSecretAccessKey   string
+	awsRegion            string
+	gcpServiceAccountKey string
+	gcpProjectID         string
+	gcpZone              string
+	gcpRegion            string
+}
+
+func NewPreflight(awsCredentialValidator credentialValidator, configProvider configProvider, awsPermissionChecker awsPermissionChecker,
+	gcpProvider gcpProvider, gcpPreflightChecker gcpPreflightChecker, envGetter envGetter, logger logger) Preflight {
+	return Preflight{
+		awsCredentialValidator: awsCredentialValidator,
+		configProvider:         configProvider,
+		awsPermissionChecker:   awsPermissionChecker,
+		gcpProvider:            gcpProvider,
+		gcpPreflightChecker:    gcpPreflightChecker,
+		envGetter:              envGetter,
+		logger:                 logger,
+	}
+}
+
+// Execute checks that the credentials of the environment allow every action
+// bbl needs and, on gcp, that the project enables the apis and has the quotas
+// for the resources of up. Before up, the credentials are given like they are
+// to up.
+func (p Preflight) Execute(subcommandFlags []string, state storage.State) error {
+	var config preflightConfig
+	preflightFlags := flags.New(PreflightCommand)
+	preflightFlags.String(&config.iaas, "iaas", p.envGetter.Get("BBL_IAAS"))
+	preflightFlags.String(&config.awsAccessKeyID, "aws-access-key-id", p.envGetter.Get("BBL_AWS_ACCESS_KEY_ID"))
+	preflightFlags.String(&config.awsSecretAccessKey, "aws-secret-access-key", p.envGetter.Get("BBL_AWS_SECRET_ACCESS_KEY"))
+	preflightFlags.String(&config.awsRegion, "aws-region", p.envGetter.Get("BBL_AWS_REGION"))
+	preflightFlags.String(&config.gcpServiceAccountKey, "gcp-service-account-key", p.envGetter.Get("BBL_GCP_SERVICE_ACCOUNT_KEY"))
+	preflightFlags.String(&config.gcpProjectID, "gcp-project-id", p.envGetter.Get("BBL_GCP_PROJECT_ID"))
+	preflightFlags.String(&config.gcpZone, "gcp-zone", p.envGetter.Get("BBL_GCP_ZONE"))
+	preflightFlags.String(&config.gcpRegion, "gcp-region", p.envGetter.Get("BBL_GCP_REGION"))
+	err := preflightFlags.Parse(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	iaas := state.IAAS
+	if iaas == "" {
+		iaas = config.iaas
+	}
+
+	switch iaas {
+	case "aws":
+	case "":
+		return errors.New("--iaas [gcp, aws] must be provided or BBL_IAAS must be set")
+	case "gcp":
+		return p.executeGCP(config, state)
+	default:
+		return fmt.Errorf("%q is an invalid iaas type, supported values are: [gcp, aws]", iaas)
+	}
+
+	if state.IAAS == "" {
+		if config.awsAccessKeyID == "" || config.awsSecretAccessKey == "" || config.awsRegion == "" {
+			return errors.New("--aws-access-key-id, --aws-secret-access-key and --aws-region must be provided")
+		}
+
+		p.configProvider.SetConfig(aws.Config{
+			AccessKeyID:     config.awsAccessKeyID,
+			SecretAccessKey: config.awsSecretAccessKey,
+			Region:          config.awsRegion,
+		})
+	} else {
+		err = p.awsCredentialValidator.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	p.logger.Step("simulating the aws policies of the credentials")
+	actions := iam.RequiredActions(true)
+	missing, err := p.awsPermissionChecker.MissingActions(actions)
+	if err != nil {
+		return err
+	}
+
+	err = missingAWSActionsError(missing)
+	if err != nil {
+		return err
+	}
+
+	p.logger.Println(fmt.Sprintf("the aws credentials allow the %d actions bbl and the BOSH AWS CPI need", len(actions)))
+
+	return nil
+}
+
+func (p Preflight) executeGCP(config preflightConfig, state storage.State) error {
+	if state.IAAS == "" {
+		if config.gcpServiceAccountKey == "" || config.gcpProjectID == "" || config.gcpZone == "" || config.gcpRegion == "" {
+			return errors.New("--gcp-service-account-key, --gcp-project-id, --gcp-zone and --gcp-region must be provided")
+		}
+
+		serviceAccountKey, err := parseServiceAccountKey(config.gcpServiceAccountKey)
+		if err != nil {
+			return err
+		}
+
+		state = storage.State{
+			IAAS: "gcp",
+			GCP: storage.GCP{
+				ServiceAccountKey: serviceAccountKey,
+				ProjectID:         config.gcpProjectID,
+				Zone:              config.gcpZone,
+				Region:            config.gcpRegion,
+			},
+		}
+	}
+
+	err := p.gcpPreflightChecker.ValidateServiceAccountKey(state.GCP.ServiceAccountKey)
+	if err != nil {
+		return err
+	}
+
+	err = p.gcpProvider.SetConfig(state.GCP.ServiceAccountKey, state.GCP.ProjectID, state.GCP.Zone)
+	if err != nil {
+		return err
+	}
+
+	p.logger.Step("checking the apis, iam permissions and quotas of the gcp project")
+	err = checkGCP(p.gcpPreflightChecker, state, func(check string, err error) error {
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	p.logger.Println(fmt.Sprintf("the gcp project %s enables the apis, grants the %d permissions and has the quotas bbl and the BOSH Google CPI need",
+		state.GCP.ProjectID, len(gcp.RequiredPermissions)))
+
+	return nil
+}
+
+// preflightAWS fails before up, create-lbs or destroy change anything when
+// the credentials are missing one of the actions the command needs. When the
+// policies cannot be simulated, for example without
+// iam:SimulatePrincipalPolicy, the command goes on as it did before the check.
+func preflightAWS(awsPermissionChecker awsPermissionChecker, actions []string, logger logger) error {
+	missing, err := awsPermissionChecker.MissingActions(actions)
+	if err != nil {
+		logger.Println(fmt.Sprintf("skipping the aws permission check: %s", err))
+		return nil
+	}
+
+	return missingAWSActionsError(missing)
+}
+
+// usesACM tells whether an lb of the state serves a certificate from ACM.
+func usesACM(state storage.State) bool {
+	certificates := []string{state.Stack.CertificateName}
+	for _, certificate := range state.LB.SNICertificates {
+		certificates = append(certificates, certificate.Name, certificate.ARN)
+	}
+	for _, spec := range state.LBs {
+		certificates = append(certificates, spec.CertificateName, spec.CertificateARN)
+		for _, certificate := range spec.SNICertificates {
+			certificates = append(certificates, certificate.Name, certificate.ARN)
+		}
+	}
+
+	for _, certificate := range certificates {
+		if acm.IsCertificate(certificate) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func missingAWSActionsError(missing []string) error {
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("The AWS credentials provided are missing permissions required by bbl and the BOSH AWS CPI:\n  %s\nPlease refer to the bbl README:\nhttps://github.com/cloudfoundry/bosh-bootloader#configure-aws.",
+		strings.Join(missing, "\n  "))
+}
+
+// preflightGCP fails before up or create-lbs change anything when an api
+// bbl calls is not enabled, the service account is missing permissions or
+// the quotas cannot hold the resources terraform is about to create. A check
+// that cannot run, for example without the Cloud Resource Manager API, is
+// skipped and the command goes on as it did before the check.
+func preflightGCP(gcpPreflightChecker gcpPreflightChecker, state storage.State, logger logger) error {
+	err := gcpPreflightChecker.ValidateServiceAccountKey(state.GCP.ServiceAccountKey)
+	if err != nil {
+		return err
+	}
+
+	return checkGCP(gcpPreflightChecker, state, func(check string, err error) error {
+		logger.Println(fmt.Sprintf("skipping the gcp %s check: %s", check, err))
+		return nil
+	})
+}
+
+// checkGCP runs the gcp checks in turn and passes the errors of the checks
+// that cannot run to failedCheck, which decides whether to go on.
+func checkGCP(gcpPreflightChecker gcpPreflightChecker, state storage.State, failedCheck func(check string, err error) error) error {
+	disabled, err := gcpPreflightChecker.DisabledAPIs(state)
+	if err != nil {
+		if err := failedCheck("api", err); err != nil {
+			return err
+		}
+	}
+
+	if len(disabled) > 0 {
+		return fmt.Errorf("The following APIs are not enabled in the GCP project %s:\n  %s\nEnable them with:\n  gcloud services enable %s --project %s",
+			state.GCP.ProjectID, strings.Join(disabled, "\n  "), strings.Join(disabled, " "), state.GCP.ProjectID)
+	}
+
+	missing, err := gcpPreflightChecker.MissingPermissions(gcp.RequiredPermissions)
+	if err != nil {
+		if err := failedCheck("iam permission", err); err != nil {
+			return err
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("The GCP service account is missing permissions required by bbl and the BOSH Google CPI:\n  %s\nPlease refer to the bbl README:\nhttps://github.com/cloudfoundry/bosh-bootloader#configure-gcp.",
+			strings.Join(missing, "\n  "))
+	}
+
+	shortfalls, err := gcpPreflightChecker.QuotaShortfalls(state)
+	if err != nil {
+		if err := failedCheck("quota", err); err != nil {
+			return err
+		}
+	}
+
+	if len(shortfalls) > 0 {
+		return fmt.Errorf("The GCP quotas cannot hold the resources bbl creates:\n  %s\nRequest an increase at https://console.cloud.google.com/iam-admin/quotas?project=%s",
+			strings.Join(shortfalls, "\n  "), state.GCP.ProjectID)
+	}
+
+	return nil
+}
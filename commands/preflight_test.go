@@ -0,0 +1,250 @@
+package commands_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws"
+	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/gcp"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Preflight", func() {
+	var (
+		credentialValidator  *fakes.CredentialValidator
+		clientProvider       *fakes.ClientProvider
+		awsPermissionChecker *fakes.AWSPermissionChecker
+		gcpClientProvider    *fakes.GCPClientProvider
+		gcpPreflightChecker  *fakes.GCPPreflightChecker
+		envGetter            *fakes.EnvGetter
+		logger               *fakes.Logger
+		command              commands.Preflight
+
+		state storage.State
+	)
+
+	BeforeEach(func() {
+		credentialValidator = &fakes.CredentialValidator{}
+		clientProvider = &fakes.ClientProvider{}
+		awsPermissionChecker = &fakes.AWSPermissionChecker{}
+		gcpClientProvider = &fakes.GCPClientProvider{}
+		gcpPreflightChecker = &fakes.GCPPreflightChecker{}
+		envGetter = &fakes.EnvGetter{}
+		logger = &fakes.Logger{}
+
+		state = storage.State{
+			IAAS: "aws",
+			AWS: storage.AWS{
+				AccessKeyID:     "some-access-key-id",
+				SecretAccessKey: "some-secret-access-key",
+				Region:          "some-region",
+			},
+		}
+
+		command = commands.NewPreflight(credentialValidator, clientProvider, awsPermissionChecker, gcpClientProvider, gcpPreflightChecker, envGetter, logger)
+	})
+
+	Describe("Execute", func() {
+		It("checks the required actions with the credentials of the environment", func() {
+			err := command.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(credentialValidator.ValidateCall.CallCount).To(Equal(1))
+			Expect(clientProvider.SetConfigCall.CallCount).To(Equal(0))
+			Expect(awsPermissionChecker.MissingActionsCall.Receives.Actions).To(Equal(iam.RequiredActions(true)))
+			Expect(logger.StepCall.Messages).To(Equal([]string{"simulating the aws policies of the credentials"}))
+			Expect(logger.PrintlnCall.Receives.Message).To(MatchRegexp(`^the aws credentials allow the \d+ actions bbl and the BOSH AWS CPI need$`))
+		})
+
+		It("checks the credentials given before up", func() {
+			err := command.Execute([]string{
+				"--iaas", "aws",
+				"--aws-access-key-id", "new-access-key-id",
+				"--aws-secret-access-key", "new-secret-access-key",
+				"--aws-region", "new-region",
+			}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(credentialValidator.ValidateCall.CallCount).To(Equal(0))
+			Expect(clientProvider.SetConfigCall.Receives.Config).To(Equal(aws.Config{
+				AccessKeyID:     "new-access-key-id",
+				SecretAccessKey: "new-secret-access-key",
+				Region:          "new-region",
+			}))
+			Expect(awsPermissionChecker.MissingActionsCall.CallCount).To(Equal(1))
+		})
+
+		It("reads the credentials from the environment before up", func() {
+			envGetter.Values = map[string]string{
+				"BBL_IAAS":                  "aws",
+				"BBL_AWS_ACCESS_KEY_ID":     "env-access-key-id",
+				"BBL_AWS_SECRET_ACCESS_KEY": "env-secret-access-key",
+				"BBL_AWS_REGION":            "env-region",
+			}
+
+			err := command.Execute([]string{}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(clientProvider.SetConfigCall.Receives.Config).To(Equal(aws.Config{
+				AccessKeyID:     "env-access-key-id",
+				SecretAccessKey: "env-secret-access-key",
+				Region:          "env-region",
+			}))
+		})
+
+		Context("on gcp", func() {
+			BeforeEach(func() {
+				state = storage.State{
+					IAAS: "gcp",
+					GCP: storage.GCP{
+						ServiceAccountKey: `{"type": "service_account"}`,
+						ProjectID:         "some-project-id",
+						Zone:              "some-zone",
+						Region:            "some-region",
+					},
+				}
+			})
+
+			It("checks the key, apis, permissions and quotas of the environment", func() {
+				err := command.Execute([]string{}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(gcpPreflightChecker.ValidateServiceAccountKeyCall.Receives.ServiceAccountKey).To(Equal(`{"type": "service_account"}`))
+				Expect(gcpClientProvider.SetConfigCall.Receives.ProjectID).To(Equal("some-project-id"))
+				Expect(gcpPreflightChecker.DisabledAPIsCall.Receives.State).To(Equal(state))
+				Expect(gcpPreflightChecker.MissingPermissionsCall.Receives.Permissions).To(Equal(gcp.RequiredPermissions))
+				Expect(gcpPreflightChecker.QuotaShortfallsCall.Receives.State).To(Equal(state))
+				Expect(logger.StepCall.Messages).To(Equal([]string{"checking the apis, iam permissions and quotas of the gcp project"}))
+				Expect(logger.PrintlnCall.Receives.Message).To(MatchRegexp(`^the gcp project some-project-id enables the apis, grants the \d+ permissions and has the quotas bbl and the BOSH Google CPI need$`))
+			})
+
+			It("checks the credentials given before up", func() {
+				err := command.Execute([]string{
+					"--iaas", "gcp",
+					"--gcp-service-account-key", `{"type": "service_account", "client_email": "bbl@example.com"}`,
+					"--gcp-project-id", "new-project-id",
+					"--gcp-zone", "new-zone",
+					"--gcp-region", "new-region",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(gcpClientProvider.SetConfigCall.Receives.ServiceAccountKey).To(Equal(`{"type": "service_account", "client_email": "bbl@example.com"}`))
+				Expect(gcpClientProvider.SetConfigCall.Receives.ProjectID).To(Equal("new-project-id"))
+				Expect(gcpClientProvider.SetConfigCall.Receives.Zone).To(Equal("new-zone"))
+				Expect(gcpPreflightChecker.QuotaShortfallsCall.Receives.State.GCP.Region).To(Equal("new-region"))
+			})
+
+			It("returns an error listing the disabled apis", func() {
+				gcpPreflightChecker.DisabledAPIsCall.Returns.Disabled = []string{"compute.googleapis.com", "dns.googleapis.com"}
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError(`The following APIs are not enabled in the GCP project some-project-id:
+  compute.googleapis.com
+  dns.googleapis.com
+Enable them with:
+  gcloud services enable compute.googleapis.com dns.googleapis.com --project some-project-id`))
+				Expect(gcpPreflightChecker.MissingPermissionsCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error listing the missing permissions", func() {
+				gcpPreflightChecker.MissingPermissionsCall.Returns.Missing = []string{"compute.addresses.create", "dns.managedZones.create"}
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError(`The GCP service account is missing permissions required by bbl and the BOSH Google CPI:
+  compute.addresses.create
+  dns.managedZones.create
+Please refer to the bbl README:
+https://github.com/cloudfoundry/bosh-bootloader#configure-gcp.`))
+			})
+
+			It("returns an error listing the quota shortfalls", func() {
+				gcpPreflightChecker.QuotaShortfallsCall.Returns.Shortfalls = []string{"STATIC_ADDRESSES in some-region: 2 needed, 1 of 8 available"}
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError(`The GCP quotas cannot hold the resources bbl creates:
+  STATIC_ADDRESSES in some-region: 2 needed, 1 of 8 available
+Request an increase at https://console.cloud.google.com/iam-admin/quotas?project=some-project-id`))
+			})
+
+			It("returns an error when a check cannot run", func() {
+				gcpPreflightChecker.MissingPermissionsCall.Returns.Error = errors.New("api not enabled")
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError("api not enabled"))
+			})
+
+			It("returns an error when the service account key is invalid", func() {
+				gcpPreflightChecker.ValidateServiceAccountKeyCall.Returns.Error = errors.New("invalid key")
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError("invalid key"))
+				Expect(gcpClientProvider.SetConfigCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the client cannot be configured", func() {
+				gcpClientProvider.SetConfigCall.Returns.Error = errors.New("failed to set config")
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError("failed to set config"))
+			})
+
+			It("returns an error when credentials are missing before up", func() {
+				err := command.Execute([]string{"--iaas", "gcp", "--gcp-project-id", "some-project-id"}, storage.State{})
+				Expect(err).To(MatchError("--gcp-service-account-key, --gcp-project-id, --gcp-zone and --gcp-region must be provided"))
+			})
+		})
+
+		Context("failure cases", func() {
+			It("returns an error listing the missing actions", func() {
+				awsPermissionChecker.MissingActionsCall.Returns.Missing = []string{"iam:UploadServerCertificate", "route53:CreateHostedZone"}
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError(`The AWS credentials provided are missing permissions required by bbl and the BOSH AWS CPI:
+  iam:UploadServerCertificate
+  route53:CreateHostedZone
+Please refer to the bbl README:
+https://github.com/cloudfoundry/bosh-bootloader#configure-aws.`))
+			})
+
+			It("returns an error when the policies cannot be simulated", func() {
+				awsPermissionChecker.MissingActionsCall.Returns.Error = errors.New("access denied")
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError("access denied"))
+			})
+
+			It("returns an error when the credential validator fails", func() {
+				credentialValidator.ValidateCall.Returns.Error = errors.New("failed to validate aws credentials")
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError("failed to validate aws credentials"))
+				Expect(awsPermissionChecker.MissingActionsCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the flags cannot be parsed", func() {
+				err := command.Execute([]string{"--unknown-flag"}, state)
+				Expect(err).To(MatchError("flag provided but not defined: -unknown-flag"))
+			})
+
+			It("returns an error when no iaas is known", func() {
+				err := command.Execute([]string{}, storage.State{})
+				Expect(err).To(MatchError("--iaas [gcp, aws] must be provided or BBL_IAAS must be set"))
+			})
+
+			It("returns an error for an invalid iaas", func() {
+				err := command.Execute([]string{"--iaas", "azure"}, storage.State{})
+				Expect(err).To(MatchError(`"azure" is an invalid iaas type, supported values are: [gcp, aws]`))
+			})
+
+			It("returns an error when credentials are missing before up", func() {
+				err := command.Execute([]string{"--iaas", "aws", "--aws-region", "some-region"}, storage.State{})
+				Expect(err).To(MatchError("--aws-access-key-id, --aws-secret-access-key and --aws-region must be provided"))
+			})
+		})
+	})
+})
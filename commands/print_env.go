@@ -1,14 +1,31 @@
 package commands
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/cloudfoundry/bosh-bootloader/flags"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
 const (
 	PrintEnvCommand = "print-env"
+
+	credhubPort = "8844"
+	jumpboxPort = "22"
+
+	// jumpboxPrivateKeyFile is where print-env keeps the jumpbox private key
+	// in the state dir, so that every run overwrites the same file.
+	jumpboxPrivateKeyFile = "jumpbox-private-key"
 )
 
 type PrintEnv struct {
@@ -16,43 +33,247 @@ type PrintEnv struct {
 	logger                logger
 	terraformManager      terraformManager
 	infrastructureManager infrastructureManager
+	renderer              renderer
+	stateDir              string
+}
+
+type PrintEnvOutput struct {
+	BOSHClient        string `json:"BOSH_CLIENT,omitempty" yaml:"BOSH_CLIENT,omitempty"`
+	BOSHClientSecret  string `json:"BOSH_CLIENT_SECRET,omitempty" yaml:"BOSH_CLIENT_SECRET,omitempty"`
+	BOSHEnvironment   string `json:"BOSH_ENVIRONMENT" yaml:"BOSH_ENVIRONMENT"`
+	BOSHCACert        string `json:"BOSH_CA_CERT,omitempty" yaml:"BOSH_CA_CERT,omitempty"`
+	BOSHAllProxy      string `json:"BOSH_ALL_PROXY,omitempty" yaml:"BOSH_ALL_PROXY,omitempty"`
+	JumpboxURL        string `json:"JUMPBOX_URL,omitempty" yaml:"JUMPBOX_URL,omitempty"`
+	JumpboxPrivateKey string `json:"JUMPBOX_PRIVATE_KEY,omitempty" yaml:"JUMPBOX_PRIVATE_KEY,omitempty"`
+	CredhubServer     string `json:"CREDHUB_SERVER,omitempty" yaml:"CREDHUB_SERVER,omitempty"`
+	CredhubCACert     string `json:"CREDHUB_CA_CERT,omitempty" yaml:"CREDHUB_CA_CERT,omitempty"`
+	CredhubUser       string `json:"CREDHUB_USER,omitempty" yaml:"CREDHUB_USER,omitempty"`
+	CredhubPassword   string `json:"CREDHUB_PASSWORD,omitempty" yaml:"CREDHUB_PASSWORD,omitempty"`
+}
+
+type printEnvConfig struct {
+	shell      string
+	caCertFile string
+}
+
+type envVariable struct {
+	name  string
+	value string
+	quote bool
 }
 
 type envSetter interface {
 	Set(key, value string) error
 }
 
-func NewPrintEnv(logger logger, stateValidator stateValidator, terraformManager terraformManager, infrastructureManager infrastructureManager) PrintEnv {
+func NewPrintEnv(logger logger, stateValidator stateValidator, terraformManager terraformManager, infrastructureManager infrastructureManager, renderer renderer,
+	stateDir string) PrintEnv {
 	return PrintEnv{
 		stateValidator:        stateValidator,
 		logger:                logger,
 		terraformManager:      terraformManager,
 		infrastructureManager: infrastructureManager,
+		renderer:              renderer,
+		stateDir:              stateDir,
 	}
 }
 
 func (p PrintEnv) Execute(args []string, state storage.State) error {
-	err := p.stateValidator.Validate()
+	config, err := p.parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	err = p.stateValidator.Validate()
 	if err != nil {
 		return err
 	}
 
+	structured := config.shell == "json" || (config.shell == "" && p.renderer.Structured())
+
+	var output PrintEnvOutput
 	if !state.NoDirector {
-		p.logger.Println(fmt.Sprintf("export BOSH_CLIENT=%s", state.BOSH.DirectorUsername))
-		p.logger.Println(fmt.Sprintf("export BOSH_CLIENT_SECRET=%s", state.BOSH.DirectorPassword))
-		p.logger.Println(fmt.Sprintf("export BOSH_ENVIRONMENT=%s", state.BOSH.DirectorAddress))
-		p.logger.Println(fmt.Sprintf("export BOSH_CA_CERT='%s'", state.BOSH.DirectorSSLCA))
+		output, err = p.directorEnvironment(state, config, structured)
+		if err != nil {
+			return err
+		}
 	} else {
 		directorAddress, err := p.getExternalIP(state)
 		if err != nil {
 			return err
 		}
-		p.logger.Println(fmt.Sprintf("export BOSH_ENVIRONMENT=https://%s:25555", directorAddress))
+		output = PrintEnvOutput{
+			BOSHEnvironment: fmt.Sprintf("https://%s:25555", directorAddress),
+		}
+	}
+
+	if config.shell == "" && structured {
+		return p.renderer.Render(output)
+	}
+
+	if config.shell == "json" {
+		contents, err := json.Marshal(output)
+		if err != nil {
+			// not tested
+			return err
+		}
+		p.logger.Println(string(contents))
+		return nil
+	}
+
+	for _, variable := range environmentVariables(output, config.caCertFile != "") {
+		p.logger.Println(formatEnvVariable(config.shell, variable))
 	}
 
 	return nil
 }
 
+func (PrintEnv) parseFlags(args []string) (printEnvConfig, error) {
+	printEnvFlags := flags.New("print-env")
+
+	config := printEnvConfig{}
+	printEnvFlags.String(&config.shell, "shell", "")
+	printEnvFlags.String(&config.caCertFile, "ca-cert-file", "")
+
+	err := printEnvFlags.Parse(args)
+	if err != nil {
+		return printEnvConfig{}, err
+	}
+
+	switch config.shell {
+	case "", "bash", "fish", "powershell", "dotenv", "json":
+	default:
+		return printEnvConfig{}, fmt.Errorf("--shell must be one of \"bash\", \"fish\", \"powershell\", \"dotenv\" or \"json\", got %q", config.shell)
+	}
+
+	return config, nil
+}
+
+// directorEnvironment writes the jumpbox private key to the state dir for
+// the BOSH_ALL_PROXY of shell output. The proxy is only needed to reach a
+// director on a private address through its jumpbox user, a director with a
+// public address is reached directly. Structured output leaves the key and
+// the proxy out rather than write a file nobody may read.
+func (p PrintEnv) directorEnvironment(state storage.State, config printEnvConfig, structured bool) (PrintEnvOutput, error) {
+	output := PrintEnvOutput{
+		BOSHClient:       state.BOSH.DirectorUsername,
+		BOSHClientSecret: state.BOSH.DirectorPassword,
+		BOSHEnvironment:  state.BOSH.DirectorAddress,
+		BOSHCACert:       state.BOSH.DirectorSSLCA,
+	}
+
+	if config.caCertFile != "" {
+		err := ioutil.WriteFile(config.caCertFile, []byte(state.BOSH.DirectorSSLCA), os.FileMode(0644))
+		if err != nil {
+			return PrintEnvOutput{}, fmt.Errorf("failed to write ca cert file: %s", err)
+		}
+		output.BOSHCACert = config.caCertFile
+	}
+
+	variables := map[string]interface{}{}
+	err := yaml.Unmarshal([]byte(state.BOSH.Variables), &variables)
+	if err != nil {
+		return PrintEnvOutput{}, fmt.Errorf("failed to parse bosh variables: %s", err)
+	}
+
+	directorHost := state.BOSH.DirectorAddress
+	if directorURL, err := url.Parse(state.BOSH.DirectorAddress); err == nil && directorURL.Host != "" {
+		directorHost = strings.Split(directorURL.Host, ":")[0]
+	}
+
+	jumpboxPrivateKey := variableProperty(variables, "jumpbox_ssh", "private_key")
+	if jumpboxPrivateKey != "" {
+		output.JumpboxURL = fmt.Sprintf("%s:%s", directorHost, jumpboxPort)
+	}
+
+	if jumpboxPrivateKey != "" && isPrivateHost(directorHost) && !structured {
+		privateKeyPath := filepath.Join(p.stateDir, jumpboxPrivateKeyFile)
+		err = ioutil.WriteFile(privateKeyPath, []byte(jumpboxPrivateKey), os.FileMode(0600))
+		if err != nil {
+			return PrintEnvOutput{}, fmt.Errorf("failed to write jumpbox private key: %s", err)
+		}
+
+		// WriteFile keeps the mode of a file written by an earlier run.
+		err = os.Chmod(privateKeyPath, os.FileMode(0600))
+		if err != nil {
+			return PrintEnvOutput{}, fmt.Errorf("failed to write jumpbox private key: %s", err)
+		}
+
+		output.JumpboxPrivateKey = privateKeyPath
+		output.BOSHAllProxy = fmt.Sprintf("ssh+socks5://jumpbox@%s?private-key=%s", output.JumpboxURL, output.JumpboxPrivateKey)
+	}
+
+	if credhubPassword, ok := variables["credhub_cli_password"].(string); ok && credhubPassword != "" {
+		output.CredhubServer = fmt.Sprintf("https://%s:%s", directorHost, credhubPort)
+		output.CredhubCACert = variableProperty(variables, "credhub_tls", "ca")
+		output.CredhubUser = "credhub-cli"
+		output.CredhubPassword = credhubPassword
+	}
+
+	return output, nil
+}
+
+func isPrivateHost(host string) bool {
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsPrivate()
+}
+
+func variableProperty(variables map[string]interface{}, name, property string) string {
+	variable, ok := variables[name].(map[interface{}]interface{})
+	if !ok {
+		return ""
+	}
+
+	value, _ := variable[property].(string)
+	return value
+}
+
+func environmentVariables(output PrintEnvOutput, caCertIsFile bool) []envVariable {
+	candidates := []envVariable{
+		{name: "BOSH_CLIENT", value: output.BOSHClient},
+		{name: "BOSH_CLIENT_SECRET", value: output.BOSHClientSecret},
+		{name: "BOSH_ENVIRONMENT", value: output.BOSHEnvironment},
+		{name: "BOSH_CA_CERT", value: output.BOSHCACert, quote: !caCertIsFile},
+		{name: "BOSH_ALL_PROXY", value: output.BOSHAllProxy},
+		{name: "JUMPBOX_URL", value: output.JumpboxURL},
+		{name: "JUMPBOX_PRIVATE_KEY", value: output.JumpboxPrivateKey},
+		{name: "CREDHUB_SERVER", value: output.CredhubServer},
+		{name: "CREDHUB_CA_CERT", value: output.CredhubCACert, quote: true},
+		{name: "CREDHUB_USER", value: output.CredhubUser},
+		{name: "CREDHUB_PASSWORD", value: output.CredhubPassword},
+	}
+
+	var variables []envVariable
+	for _, candidate := range candidates {
+		if candidate.name == "BOSH_ENVIRONMENT" || candidate.value != "" {
+			variables = append(variables, candidate)
+		}
+	}
+
+	return variables
+}
+
+func formatEnvVariable(shell string, variable envVariable) string {
+	switch shell {
+	case "fish":
+		escaped := strings.Replace(variable.value, `\`, `\\`, -1)
+		return fmt.Sprintf("set -x %s '%s'", variable.name, strings.Replace(escaped, "'", `\'`, -1))
+	case "powershell":
+		return fmt.Sprintf("$env:%s='%s'", variable.name, strings.Replace(variable.value, "'", "''", -1))
+	case "dotenv":
+		if strings.Contains(variable.value, "\n") {
+			escaped := strings.Replace(variable.value, `"`, `\"`, -1)
+			return fmt.Sprintf(`%s="%s"`, variable.name, strings.Replace(escaped, "\n", `\n`, -1))
+		}
+		return fmt.Sprintf("%s=%s", variable.name, variable.value)
+	default:
+		if variable.quote {
+			return fmt.Sprintf("export %s='%s'", variable.name, variable.value)
+		}
+		return fmt.Sprintf("export %s=%s", variable.name, variable.value)
+	}
+}
+
 func (p PrintEnv) getExternalIP(state storage.State) (string, error) {
 	switch state.IAAS {
 	case "aws":
@@ -2,12 +2,16 @@ package commands_test
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
 	"github.com/cloudfoundry/bosh-bootloader/commands"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
@@ -17,8 +21,10 @@ var _ = Describe("PrintEnv", func() {
 		stateValidator        *fakes.StateValidator
 		terraformManager      *fakes.TerraformManager
 		infrastructureManager *fakes.InfrastructureManager
+		renderer              *fakes.Renderer
 		printEnv              commands.PrintEnv
 		state                 storage.State
+		stateDir              string
 	)
 
 	BeforeEach(func() {
@@ -26,6 +32,7 @@ var _ = Describe("PrintEnv", func() {
 		stateValidator = &fakes.StateValidator{}
 		terraformManager = &fakes.TerraformManager{}
 		infrastructureManager = &fakes.InfrastructureManager{}
+		renderer = &fakes.Renderer{}
 
 		state = storage.State{
 			BOSH: storage.BOSH{
@@ -36,7 +43,15 @@ var _ = Describe("PrintEnv", func() {
 			},
 		}
 
-		printEnv = commands.NewPrintEnv(logger, stateValidator, terraformManager, infrastructureManager)
+		var err error
+		stateDir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		printEnv = commands.NewPrintEnv(logger, stateValidator, terraformManager, infrastructureManager, renderer, stateDir)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(stateDir)
 	})
 
 	It("prints the correct environment variables for the bosh cli", func() {
@@ -48,6 +63,268 @@ var _ = Describe("PrintEnv", func() {
 		Expect(logger.PrintlnCall.Messages).To(ContainElement("export BOSH_ENVIRONMENT=some-director-address"))
 	})
 
+	Context("when a shell is provided", func() {
+		DescribeTable("prints the environment variables for the shell", func(shell string, expectedLines []string) {
+			state.BOSH.DirectorSSLCA = "some-director-ca-cert"
+
+			err := printEnv.Execute([]string{"--shell", shell}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PrintlnCall.Messages).To(Equal(expectedLines))
+		},
+			Entry("bash", "bash", []string{
+				"export BOSH_CLIENT=some-director-username",
+				"export BOSH_CLIENT_SECRET=some-director-password",
+				"export BOSH_ENVIRONMENT=some-director-address",
+				"export BOSH_CA_CERT='some-director-ca-cert'",
+			}),
+			Entry("fish", "fish", []string{
+				"set -x BOSH_CLIENT 'some-director-username'",
+				"set -x BOSH_CLIENT_SECRET 'some-director-password'",
+				"set -x BOSH_ENVIRONMENT 'some-director-address'",
+				"set -x BOSH_CA_CERT 'some-director-ca-cert'",
+			}),
+			Entry("powershell", "powershell", []string{
+				"$env:BOSH_CLIENT='some-director-username'",
+				"$env:BOSH_CLIENT_SECRET='some-director-password'",
+				"$env:BOSH_ENVIRONMENT='some-director-address'",
+				"$env:BOSH_CA_CERT='some-director-ca-cert'",
+			}),
+			Entry("dotenv", "dotenv", []string{
+				"BOSH_CLIENT=some-director-username",
+				"BOSH_CLIENT_SECRET=some-director-password",
+				"BOSH_ENVIRONMENT=some-director-address",
+				"BOSH_CA_CERT=some-director-ca-cert",
+			}),
+		)
+
+		It("escapes multi-line values for dotenv", func() {
+			state.BOSH.DirectorSSLCA = "-----BEGIN CERTIFICATE-----\nsome-cert\n-----END CERTIFICATE-----"
+
+			err := printEnv.Execute([]string{"--shell", "dotenv"}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PrintlnCall.Messages).To(ContainElement(`BOSH_CA_CERT="-----BEGIN CERTIFICATE-----\nsome-cert\n-----END CERTIFICATE-----"`))
+		})
+
+		It("escapes backslashes and single quotes for fish", func() {
+			state.BOSH.DirectorPassword = `some-\'password\`
+
+			err := printEnv.Execute([]string{"--shell", "fish"}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PrintlnCall.Messages).To(ContainElement(`set -x BOSH_CLIENT_SECRET 'some-\\\'password\\'`))
+		})
+
+		It("prints the environment variables as json", func() {
+			err := printEnv.Execute([]string{"--shell", "json"}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PrintlnCall.Receives.Message).To(MatchJSON(`{
+				"BOSH_CLIENT": "some-director-username",
+				"BOSH_CLIENT_SECRET": "some-director-password",
+				"BOSH_ENVIRONMENT": "some-director-address",
+				"BOSH_CA_CERT": "some-director-ca-cert"
+			}`))
+			Expect(renderer.StructuredCall.CallCount).To(Equal(0))
+		})
+
+		It("returns an error when the shell is not supported", func() {
+			err := printEnv.Execute([]string{"--shell", "tcsh"}, state)
+			Expect(err).To(MatchError(`--shell must be one of "bash", "fish", "powershell", "dotenv" or "json", got "tcsh"`))
+		})
+	})
+
+	Context("when a ca cert file is provided", func() {
+		var caCertPath string
+
+		BeforeEach(func() {
+			tempDir, err := ioutil.TempDir("", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			caCertPath = filepath.Join(tempDir, "bosh-ca.crt")
+		})
+
+		It("writes the ca cert to the file and exports its path", func() {
+			err := printEnv.Execute([]string{"--ca-cert-file", caCertPath}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			caCert, err := ioutil.ReadFile(caCertPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(caCert)).To(Equal("some-director-ca-cert"))
+
+			Expect(logger.PrintlnCall.Messages).To(ContainElement("export BOSH_CA_CERT=" + caCertPath))
+		})
+
+		It("returns an error when the file cannot be written", func() {
+			err := printEnv.Execute([]string{"--ca-cert-file", "/some/missing/dir/bosh-ca.crt"}, state)
+			Expect(err).To(MatchError(ContainSubstring("failed to write ca cert file")))
+		})
+	})
+
+	Context("when the director on a private address has a jumpbox user", func() {
+		BeforeEach(func() {
+			state.BOSH.DirectorAddress = "https://10.0.0.6:25555"
+			state.BOSH.Variables = "jumpbox_ssh:\n  private_key: some-jumpbox-private-key\n"
+		})
+
+		It("prints the jumpbox and proxy variables with the private key written to the state dir", func() {
+			err := printEnv.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			privateKeyPath := filepath.Join(stateDir, "jumpbox-private-key")
+			Expect(logger.PrintlnCall.Messages).To(ContainElement("export JUMPBOX_URL=10.0.0.6:22"))
+			Expect(logger.PrintlnCall.Messages).To(ContainElement("export JUMPBOX_PRIVATE_KEY=" + privateKeyPath))
+
+			privateKey, err := ioutil.ReadFile(privateKeyPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(privateKey)).To(Equal("some-jumpbox-private-key"))
+
+			info, err := os.Stat(privateKeyPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode()).To(Equal(os.FileMode(0600)))
+
+			Expect(logger.PrintlnCall.Messages).To(ContainElement("export BOSH_ALL_PROXY=ssh+socks5://jumpbox@10.0.0.6:22?private-key=" + privateKeyPath))
+		})
+
+		It("overwrites the private key of an earlier run", func() {
+			privateKeyPath := filepath.Join(stateDir, "jumpbox-private-key")
+			err := ioutil.WriteFile(privateKeyPath, []byte("some-old-private-key"), os.FileMode(0644))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = printEnv.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			privateKey, err := ioutil.ReadFile(privateKeyPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(privateKey)).To(Equal("some-jumpbox-private-key"))
+
+			info, err := os.Stat(privateKeyPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode()).To(Equal(os.FileMode(0600)))
+
+			files, err := ioutil.ReadDir(stateDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+		})
+
+		It("does not print the proxy or write the private key for a director on a public address", func() {
+			state.BOSH.DirectorAddress = "https://35.0.0.6:25555"
+
+			err := printEnv.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PrintlnCall.Messages).To(ContainElement("export JUMPBOX_URL=35.0.0.6:22"))
+			for _, message := range logger.PrintlnCall.Messages {
+				Expect(message).NotTo(ContainSubstring("JUMPBOX_PRIVATE_KEY"))
+				Expect(message).NotTo(ContainSubstring("BOSH_ALL_PROXY"))
+			}
+
+			_, err = os.Stat(filepath.Join(stateDir, "jumpbox-private-key"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("does not write the private key for json output", func() {
+			err := printEnv.Execute([]string{"--shell", "json"}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PrintlnCall.Receives.Message).To(ContainSubstring(`"JUMPBOX_URL":"10.0.0.6:22"`))
+			Expect(logger.PrintlnCall.Receives.Message).NotTo(ContainSubstring("JUMPBOX_PRIVATE_KEY"))
+			Expect(logger.PrintlnCall.Receives.Message).NotTo(ContainSubstring("BOSH_ALL_PROXY"))
+
+			_, err = os.Stat(filepath.Join(stateDir, "jumpbox-private-key"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("does not write the private key for structured output", func() {
+			renderer.StructuredCall.Returns.Structured = true
+
+			err := printEnv.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(renderer.RenderCall.Receives.Value).To(Equal(commands.PrintEnvOutput{
+				BOSHClient:       "some-director-username",
+				BOSHClientSecret: "some-director-password",
+				BOSHEnvironment:  "https://10.0.0.6:25555",
+				BOSHCACert:       "some-director-ca-cert",
+				JumpboxURL:       "10.0.0.6:22",
+			}))
+
+			_, err = os.Stat(filepath.Join(stateDir, "jumpbox-private-key"))
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+
+	Context("when the director has credhub enabled", func() {
+		BeforeEach(func() {
+			state.BOSH.DirectorAddress = "https://10.0.0.6:25555"
+			state.BOSH.Variables = "credhub_cli_password: some-credhub-password\ncredhub_tls:\n  ca: some-credhub-ca\n"
+		})
+
+		It("prints the credhub variables", func() {
+			err := printEnv.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.PrintlnCall.Messages).To(ContainElement("export CREDHUB_SERVER=https://10.0.0.6:8844"))
+			Expect(logger.PrintlnCall.Messages).To(ContainElement("export CREDHUB_CA_CERT='some-credhub-ca'"))
+			Expect(logger.PrintlnCall.Messages).To(ContainElement("export CREDHUB_USER=credhub-cli"))
+			Expect(logger.PrintlnCall.Messages).To(ContainElement("export CREDHUB_PASSWORD=some-credhub-password"))
+		})
+	})
+
+	It("does not print jumpbox or credhub variables when they are not enabled", func() {
+		err := printEnv.Execute([]string{}, state)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, message := range logger.PrintlnCall.Messages {
+			Expect(message).NotTo(ContainSubstring("JUMPBOX"))
+			Expect(message).NotTo(ContainSubstring("CREDHUB"))
+			Expect(message).NotTo(ContainSubstring("BOSH_ALL_PROXY"))
+		}
+	})
+
+	Context("when structured output is requested", func() {
+		BeforeEach(func() {
+			renderer.StructuredCall.Returns.Structured = true
+		})
+
+		It("renders the environment variables", func() {
+			err := printEnv.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(renderer.RenderCall.Receives.Value).To(Equal(commands.PrintEnvOutput{
+				BOSHClient:       "some-director-username",
+				BOSHClientSecret: "some-director-password",
+				BOSHEnvironment:  "some-director-address",
+				BOSHCACert:       "some-director-ca-cert",
+			}))
+			Expect(logger.PrintlnCall.CallCount).To(Equal(0))
+		})
+
+		It("renders only the BOSH_ENVIRONMENT when there is no director", func() {
+			terraformManager.GetOutputsCall.Returns.Outputs = map[string]interface{}{
+				"external_ip": "some-external-ip",
+			}
+
+			err := printEnv.Execute([]string{}, storage.State{
+				IAAS:       "gcp",
+				NoDirector: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(renderer.RenderCall.Receives.Value).To(Equal(commands.PrintEnvOutput{
+				BOSHEnvironment: "https://some-external-ip:25555",
+			}))
+		})
+
+		It("returns an error when the renderer fails", func() {
+			renderer.RenderCall.Returns.Error = errors.New("failed to render")
+
+			err := printEnv.Execute([]string{}, state)
+			Expect(err).To(MatchError("failed to render"))
+		})
+	})
+
 	Context("when print-env is called on a bbl env with no director", func() {
 		Context("aws", func() {
 			It("prints only the BOSH_ENVIRONMENT", func() {
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	TextOutput = "text"
+	JSONOutput = "json"
+	YAMLOutput = "yaml"
+)
+
+type renderer interface {
+	Structured() bool
+	Render(value interface{}) error
+}
+
+type Renderer struct {
+	stdout io.Writer
+	output string
+}
+
+func NewRenderer(stdout io.Writer, output string) Renderer {
+	return Renderer{
+		stdout: stdout,
+		output: output,
+	}
+}
+
+func (r Renderer) Structured() bool {
+	return r.output == JSONOutput || r.output == YAMLOutput
+}
+
+func (r Renderer) Render(value interface{}) error {
+	var (
+		contents []byte
+		err      error
+	)
+
+	switch r.output {
+	case JSONOutput:
+		contents, err = json.Marshal(jsonCompatible(value))
+		if err != nil {
+			return err
+		}
+		contents = append(contents, '\n')
+	case YAMLOutput:
+		contents, err = yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("output format %q cannot render structured output", r.output)
+	}
+
+	_, err = r.stdout.Write(contents)
+	return err
+}
+
+// jsonCompatible converts the map[interface{}]interface{} values produced by
+// yaml.Unmarshal into maps that encoding/json is able to marshal.
+func jsonCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		converted := map[string]interface{}{}
+		for key, element := range v {
+			converted[fmt.Sprintf("%v", key)] = jsonCompatible(element)
+		}
+		return converted
+	case map[string]interface{}:
+		converted := map[string]interface{}{}
+		for key, element := range v {
+			converted[key] = jsonCompatible(element)
+		}
+		return converted
+	case []interface{}:
+		converted := []interface{}{}
+		for _, element := range v {
+			converted = append(converted, jsonCompatible(element))
+		}
+		return converted
+	}
+
+	return value
+}
@@ -0,0 +1,71 @@
+package commands_test
+
+import (
+	"bytes"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Renderer", func() {
+	var stdout *bytes.Buffer
+
+	BeforeEach(func() {
+		stdout = bytes.NewBuffer([]byte{})
+	})
+
+	Describe("Structured", func() {
+		It("returns true for json and yaml output", func() {
+			Expect(commands.NewRenderer(stdout, "json").Structured()).To(BeTrue())
+			Expect(commands.NewRenderer(stdout, "yaml").Structured()).To(BeTrue())
+		})
+
+		It("returns false for text output", func() {
+			Expect(commands.NewRenderer(stdout, "text").Structured()).To(BeFalse())
+		})
+	})
+
+	Describe("Render", func() {
+		var value interface{}
+
+		BeforeEach(func() {
+			value = struct {
+				Name  string   `json:"name" yaml:"name"`
+				Zones []string `json:"zones" yaml:"zones"`
+			}{
+				Name:  "some-name",
+				Zones: []string{"some-zone"},
+			}
+		})
+
+		It("renders the value as json", func() {
+			err := commands.NewRenderer(stdout, "json").Render(value)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(MatchJSON(`{"name":"some-name","zones":["some-zone"]}`))
+		})
+
+		It("renders the value as yaml", func() {
+			err := commands.NewRenderer(stdout, "yaml").Render(value)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(Equal("name: some-name\nzones:\n- some-zone\n"))
+		})
+
+		It("renders values unmarshaled from yaml as json", func() {
+			err := commands.NewRenderer(stdout, "json").Render(map[interface{}]interface{}{
+				"tags": []interface{}{map[interface{}]interface{}{"key": "value"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(MatchJSON(`{"tags":[{"key":"value"}]}`))
+		})
+
+		It("returns an error when the output format is text", func() {
+			err := commands.NewRenderer(stdout, "text").Render(value)
+			Expect(err).To(MatchError(`output format "text" cannot render structured output`))
+		})
+	})
+})
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/cloudfoundry/bosh-bootloader/ssl"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+type sshKeyPairGenerator interface {
+	Generate(keyType string, bits int) (string, string, error)
+}
+
+func loadSSHKeyPair(keyPair storage.KeyPair, privateKeyPath, publicKeyPath, keyType string, keyBits int) (storage.KeyPair, error) {
+	switch {
+	case keyType != "" && keyType != ssl.RSASSHKeyType && keyType != ssl.ED25519SSHKeyType:
+		return storage.KeyPair{}, fmt.Errorf("--ssh-key-type must be %q or %q", ssl.RSASSHKeyType, ssl.ED25519SSHKeyType)
+	case keyType == ssl.ED25519SSHKeyType && keyBits != 0:
+		return storage.KeyPair{}, errors.New("--ssh-key-bits cannot be used with --ssh-key-type ed25519")
+	case keyBits != 0 && keyBits < ssl.MinimumSSHKeyBits:
+		return storage.KeyPair{}, fmt.Errorf("--ssh-key-bits must be at least %d", ssl.MinimumSSHKeyBits)
+	case privateKeyPath == "" && publicKeyPath != "":
+		return storage.KeyPair{}, errors.New("--ssh-public-key requires --ssh-private-key")
+	case privateKeyPath != "" && keyBits != 0:
+		return storage.KeyPair{}, errors.New("--ssh-key-bits cannot be used with --ssh-private-key")
+	case privateKeyPath != "" && keyType != "":
+		return storage.KeyPair{}, errors.New("--ssh-key-type cannot be used with --ssh-private-key")
+	case privateKeyPath == "" && keyPair.PrivateKey != "" && (keyBits != 0 || keyType != ""):
+		return storage.KeyPair{}, errors.New("--ssh-key-bits and --ssh-key-type cannot be used for an existing environment, the SSH keypair cannot be changed.")
+	case privateKeyPath == "":
+		return keyPair, nil
+	}
+
+	privateKey, err := ioutil.ReadFile(privateKeyPath)
+	if err != nil {
+		return storage.KeyPair{}, fmt.Errorf("error reading ssh private key: %v", err)
+	}
+
+	var publicKey []byte
+	if publicKeyPath != "" {
+		publicKey, err = ioutil.ReadFile(publicKeyPath)
+		if err != nil {
+			return storage.KeyPair{}, fmt.Errorf("error reading ssh public key: %v", err)
+		}
+	}
+
+	parsedPrivateKey, parsedPublicKey, err := ssl.ParseSSHKeyPair(privateKey, publicKey)
+	if err != nil {
+		return storage.KeyPair{}, err
+	}
+
+	if keyPair.PrivateKey != "" && keyPair.PrivateKey != parsedPrivateKey {
+		return storage.KeyPair{}, errors.New("The SSH keypair cannot be changed for an existing environment.")
+	}
+
+	keyPair.PrivateKey = parsedPrivateKey
+	keyPair.PublicKey = parsedPublicKey
+
+	return keyPair, nil
+}
@@ -23,22 +23,33 @@ const (
 	DirectorCACertPropertyName   = "director ca cert"
 )
 
+var propertyKeys = map[string]string{
+	EnvIDPropertyName:            "env_id",
+	SSHKeyPropertyName:           "ssh_key",
+	DirectorUsernamePropertyName: "director_username",
+	DirectorPasswordPropertyName: "director_password",
+	DirectorAddressPropertyName:  "director_address",
+	DirectorCACertPropertyName:   "director_ca_cert",
+}
+
 type StateQuery struct {
 	logger                logger
 	stateValidator        stateValidator
 	terraformManager      terraformManager
 	infrastructureManager infrastructureManager
+	renderer              renderer
 	propertyName          string
 }
 
 type getPropertyFunc func(storage.State) string
 
-func NewStateQuery(logger logger, stateValidator stateValidator, terraformManager terraformManager, infrastructureManager infrastructureManager, propertyName string) StateQuery {
+func NewStateQuery(logger logger, stateValidator stateValidator, terraformManager terraformManager, infrastructureManager infrastructureManager, renderer renderer, propertyName string) StateQuery {
 	return StateQuery{
 		logger:                logger,
 		stateValidator:        stateValidator,
 		terraformManager:      terraformManager,
 		infrastructureManager: infrastructureManager,
+		renderer:              renderer,
 		propertyName:          propertyName,
 	}
 }
@@ -81,6 +92,12 @@ func (s StateQuery) Execute(subcommandFlags []string, state storage.State) error
 		return fmt.Errorf("Could not retrieve %s, please make sure you are targeting the proper state dir.", s.propertyName)
 	}
 
+	if s.renderer.Structured() {
+		return s.renderer.Render(map[string]string{
+			propertyKeys[s.propertyName]: propertyValue,
+		})
+	}
+
 	s.logger.Println(propertyValue)
 	return nil
 }
@@ -21,6 +21,7 @@ var _ = Describe("StateQuery", func() {
 		fakeStateValidator        *fakes.StateValidator
 		fakeTerraformManager      *fakes.TerraformManager
 		fakeInfrastructureManager *fakes.InfrastructureManager
+		fakeRenderer              *fakes.Renderer
 	)
 
 	BeforeEach(func() {
@@ -28,6 +29,7 @@ var _ = Describe("StateQuery", func() {
 		fakeStateValidator = &fakes.StateValidator{}
 		fakeTerraformManager = &fakes.TerraformManager{}
 		fakeInfrastructureManager = &fakes.InfrastructureManager{}
+		fakeRenderer = &fakes.Renderer{}
 	})
 
 	Describe("Execute", func() {
@@ -47,7 +49,7 @@ var _ = Describe("StateQuery", func() {
 
 			DescribeTable("prints out the director information",
 				func(propertyName, expectedOutput string) {
-					command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, propertyName)
+					command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, fakeRenderer, propertyName)
 
 					err := command.Execute([]string{}, state)
 					Expect(err).NotTo(HaveOccurred())
@@ -59,6 +61,34 @@ var _ = Describe("StateQuery", func() {
 				Entry("director-password", "director password", "some-director-password"),
 				Entry("director-ssl-ca", "director ca cert", "some-director-ssl-ca"),
 			)
+
+			DescribeTable("renders the director information when structured output is requested",
+				func(propertyName, expectedKey, expectedValue string) {
+					fakeRenderer.StructuredCall.Returns.Structured = true
+					command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, fakeRenderer, propertyName)
+
+					err := command.Execute([]string{}, state)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeRenderer.RenderCall.Receives.Value).To(Equal(map[string]string{
+						expectedKey: expectedValue,
+					}))
+					Expect(fakeLogger.PrintlnCall.CallCount).To(Equal(0))
+				},
+				Entry("director-address", "director address", "director_address", "some-director-address"),
+				Entry("director-username", "director username", "director_username", "some-director-username"),
+				Entry("director-password", "director password", "director_password", "some-director-password"),
+				Entry("director-ssl-ca", "director ca cert", "director_ca_cert", "some-director-ssl-ca"),
+			)
+
+			It("returns an error when the renderer fails", func() {
+				fakeRenderer.StructuredCall.Returns.Structured = true
+				fakeRenderer.RenderCall.Returns.Error = errors.New("failed to render")
+				command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, fakeRenderer, "director address")
+
+				err := command.Execute([]string{}, state)
+				Expect(err).To(MatchError("failed to render"))
+			})
 		})
 
 		Context("bbl does not manage the bosh director", func() {
@@ -73,7 +103,7 @@ var _ = Describe("StateQuery", func() {
 
 			DescribeTable("prints out the director information",
 				func(propertyName string) {
-					command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, propertyName)
+					command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, fakeRenderer, propertyName)
 
 					err := command.Execute([]string{}, state)
 					Expect(err).To(MatchError("Error BBL does not manage this director."))
@@ -84,7 +114,7 @@ var _ = Describe("StateQuery", func() {
 			)
 
 			It("prints the env id", func() {
-				command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, "environment id")
+				command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, fakeRenderer, "environment id")
 
 				err := command.Execute([]string{}, state)
 				Expect(err).NotTo(HaveOccurred())
@@ -100,7 +130,7 @@ var _ = Describe("StateQuery", func() {
 
 					state.IAAS = "gcp"
 
-					command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, "director address")
+					command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, fakeRenderer, "director address")
 					err := command.Execute([]string{}, state)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(fakeLogger.PrintlnCall.Receives.Message).To(Equal("https://some-external-ip:25555"))
@@ -117,7 +147,7 @@ var _ = Describe("StateQuery", func() {
 
 					state.IAAS = "aws"
 
-					command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, "director address")
+					command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, fakeRenderer, "director address")
 					err := command.Execute([]string{}, state)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(fakeLogger.PrintlnCall.Receives.Message).To(Equal("https://some-external-ip:25555"))
@@ -128,7 +158,7 @@ var _ = Describe("StateQuery", func() {
 		Context("failure cases", func() {
 			It("returns an error when the state validator fails", func() {
 				fakeStateValidator.ValidateCall.Returns.Error = errors.New("state validator failed")
-				command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, "")
+				command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, fakeRenderer, "")
 
 				err := command.Execute([]string{}, storage.State{
 					BOSH: storage.BOSH{},
@@ -139,7 +169,7 @@ var _ = Describe("StateQuery", func() {
 
 			It("returns an error when the terraform output provider fails", func() {
 				fakeTerraformManager.GetOutputsCall.Returns.Error = errors.New("failed to get terraform output")
-				command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, "director address")
+				command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, fakeRenderer, "director address")
 
 				err := command.Execute([]string{}, storage.State{
 					IAAS:       "gcp",
@@ -151,7 +181,7 @@ var _ = Describe("StateQuery", func() {
 
 			It("returns an error when the infrastructure manager fails", func() {
 				fakeInfrastructureManager.DescribeCall.Returns.Error = errors.New("failed to describe stack")
-				command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, "director address")
+				command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, fakeRenderer, "director address")
 
 				err := command.Execute([]string{}, storage.State{
 					IAAS:       "aws",
@@ -162,7 +192,7 @@ var _ = Describe("StateQuery", func() {
 			})
 
 			It("returns an error when an external ip cannot be found", func() {
-				command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, "director address")
+				command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, fakeRenderer, "director address")
 
 				err := command.Execute([]string{}, storage.State{
 					IAAS:       "lol",
@@ -174,7 +204,7 @@ var _ = Describe("StateQuery", func() {
 
 			It("returns an error when the state value is empty", func() {
 				propertyName := fmt.Sprintf("%s-%d", "some-name", rand.Int())
-				command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, propertyName)
+				command := commands.NewStateQuery(fakeLogger, fakeStateValidator, fakeTerraformManager, fakeInfrastructureManager, fakeRenderer, propertyName)
 				err := command.Execute([]string{}, storage.State{
 					BOSH: storage.BOSH{},
 				})
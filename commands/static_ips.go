@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-bootloader/cloudconfig"
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	StaticIPsCommand = "static-ips"
+)
+
+type StaticIPs struct {
+	logger             logger
+	stateValidator     stateValidator
+	cloudConfigManager cloudConfigManager
+	stateStore         stateStore
+	renderer           renderer
+}
+
+type StaticIPsOutput struct {
+	Network string   `json:"network" yaml:"network"`
+	IPs     []string `json:"ips" yaml:"ips"`
+}
+
+type staticIPsConfig struct {
+	network string
+	count   int
+}
+
+func NewStaticIPs(logger logger, stateValidator stateValidator, cloudConfigManager cloudConfigManager, stateStore stateStore, renderer renderer) StaticIPs {
+	return StaticIPs{
+		logger:             logger,
+		stateValidator:     stateValidator,
+		cloudConfigManager: cloudConfigManager,
+		stateStore:         stateStore,
+		renderer:           renderer,
+	}
+}
+
+func (s StaticIPs) Execute(args []string, state storage.State) error {
+	config, err := s.parseFlags(args)
+	if err != nil {
+		return err
+	}
+
+	err = s.stateValidator.Validate()
+	if err != nil {
+		return err
+	}
+
+	network := state.CloudConfig.Networks[config.network]
+
+	ips := network.StaticIPs
+	if config.count > 0 {
+		cloudConfig, err := s.cloudConfigManager.Generate(state)
+		if err != nil {
+			return err
+		}
+
+		ips, err = cloudconfig.AllocateStaticIPs(cloudConfig, config.network, state.CloudConfig.Networks, config.count)
+		if err != nil {
+			return err
+		}
+
+		networks := map[string]storage.CloudConfigNetwork{}
+		for name, n := range state.CloudConfig.Networks {
+			networks[name] = n
+		}
+
+		network.StaticIPs = append(append([]string{}, network.StaticIPs...), ips...)
+		networks[config.network] = network
+		state.CloudConfig.Networks = networks
+
+		err = s.stateStore.Set(state)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.renderer.Structured() {
+		return s.renderer.Render(StaticIPsOutput{
+			Network: config.network,
+			IPs:     ips,
+		})
+	}
+
+	for _, ip := range ips {
+		s.logger.Println(ip)
+	}
+
+	return nil
+}
+
+func (StaticIPs) parseFlags(args []string) (staticIPsConfig, error) {
+	staticIPsFlags := flags.New("static-ips")
+
+	config := staticIPsConfig{}
+	staticIPsFlags.String(&config.network, "network", "")
+	staticIPsFlags.Int(&config.count, "count", 0)
+
+	err := staticIPsFlags.Parse(args)
+	if err != nil {
+		return staticIPsConfig{}, err
+	}
+
+	if config.network == "" {
+		return staticIPsConfig{}, errors.New("--network is required")
+	}
+
+	if !isNetworkName(config.network) {
+		return staticIPsConfig{}, fmt.Errorf("--network must be one of: %s", strings.Join(cloudconfig.NetworkNames, ", "))
+	}
+
+	if config.count < 0 {
+		return staticIPsConfig{}, errors.New("--count must not be negative")
+	}
+
+	return config, nil
+}
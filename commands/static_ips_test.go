@@ -0,0 +1,156 @@
+package commands_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StaticIPs", func() {
+	var (
+		logger             *fakes.Logger
+		stateValidator     *fakes.StateValidator
+		cloudConfigManager *fakes.CloudConfigManager
+		stateStore         *fakes.StateStore
+		renderer           *fakes.Renderer
+		staticIPs          commands.StaticIPs
+		state              storage.State
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		stateValidator = &fakes.StateValidator{}
+		cloudConfigManager = &fakes.CloudConfigManager{}
+		stateStore = &fakes.StateStore{}
+		renderer = &fakes.Renderer{}
+
+		cloudConfigManager.GenerateCall.Returns.CloudConfig = `networks:
+- name: private
+  subnets:
+  - static: [10.0.31.252-10.0.31.254]
+- name: default
+  subnets:
+  - static: [10.0.31.190-10.0.31.254]
+`
+
+		state = storage.State{
+			IAAS: "aws",
+			CloudConfig: storage.CloudConfig{
+				OpsFiles: []string{"some-ops-file"},
+				Networks: map[string]storage.CloudConfigNetwork{
+					"private": {
+						StaticIPCount: 3,
+						StaticIPs:     []string{"10.0.31.252"},
+					},
+				},
+			},
+		}
+
+		staticIPs = commands.NewStaticIPs(logger, stateValidator, cloudConfigManager, stateStore, renderer)
+	})
+
+	Describe("Execute", func() {
+		It("allocates unallocated static ips and records them in the state", func() {
+			err := staticIPs.Execute([]string{"--network", "private", "--count", "2"}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stateValidator.ValidateCall.CallCount).To(Equal(1))
+			Expect(cloudConfigManager.GenerateCall.Receives.State).To(Equal(state))
+
+			Expect(stateStore.SetCall.CallCount).To(Equal(1))
+			Expect(stateStore.SetCall.Receives[0].State.CloudConfig.OpsFiles).To(Equal([]string{"some-ops-file"}))
+			Expect(stateStore.SetCall.Receives[0].State.CloudConfig.Networks).To(Equal(map[string]storage.CloudConfigNetwork{
+				"private": {
+					StaticIPCount: 3,
+					StaticIPs:     []string{"10.0.31.252", "10.0.31.253", "10.0.31.254"},
+				},
+			}))
+
+			Expect(logger.PrintlnCall.Messages).To(Equal([]string{"10.0.31.253", "10.0.31.254"}))
+		})
+
+		It("does not modify the incoming state", func() {
+			err := staticIPs.Execute([]string{"--network", "default", "--count", "1"}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(state.CloudConfig.Networks).To(HaveLen(1))
+			Expect(stateStore.SetCall.Receives[0].State.CloudConfig.Networks["default"].StaticIPs).To(Equal([]string{"10.0.31.190"}))
+		})
+
+		It("prints the already allocated ips when no count is given", func() {
+			err := staticIPs.Execute([]string{"--network", "private"}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cloudConfigManager.GenerateCall.CallCount).To(Equal(0))
+			Expect(stateStore.SetCall.CallCount).To(Equal(0))
+			Expect(logger.PrintlnCall.Messages).To(Equal([]string{"10.0.31.252"}))
+		})
+
+		It("renders the allocated ips when structured output is requested", func() {
+			renderer.StructuredCall.Returns.Structured = true
+
+			err := staticIPs.Execute([]string{"--network", "private", "--count", "1"}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(renderer.RenderCall.Receives.Value).To(Equal(commands.StaticIPsOutput{
+				Network: "private",
+				IPs:     []string{"10.0.31.253"},
+			}))
+			Expect(logger.PrintlnCall.CallCount).To(Equal(0))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the network is missing", func() {
+				err := staticIPs.Execute([]string{"--count", "1"}, state)
+				Expect(err).To(MatchError("--network is required"))
+			})
+
+			It("returns an error when the network is not generated by bbl", func() {
+				err := staticIPs.Execute([]string{"--network", "public"}, state)
+				Expect(err).To(MatchError("--network must be one of: private, default"))
+			})
+
+			It("returns an error when the count is negative", func() {
+				err := staticIPs.Execute([]string{"--network", "private", "--count", "-1"}, state)
+				Expect(err).To(MatchError("--count must not be negative"))
+			})
+
+			It("returns an error when the flags cannot be parsed", func() {
+				err := staticIPs.Execute([]string{"--unknown-flag"}, state)
+				Expect(err).To(MatchError("flag provided but not defined: -unknown-flag"))
+			})
+
+			It("returns an error when the state validator fails", func() {
+				stateValidator.ValidateCall.Returns.Error = errors.New("state validator failed")
+
+				err := staticIPs.Execute([]string{"--network", "private"}, state)
+				Expect(err).To(MatchError("state validator failed"))
+			})
+
+			It("returns an error when the cloud config cannot be generated", func() {
+				cloudConfigManager.GenerateCall.Returns.Error = errors.New("failed to generate")
+
+				err := staticIPs.Execute([]string{"--network", "private", "--count", "1"}, state)
+				Expect(err).To(MatchError("failed to generate"))
+			})
+
+			It("returns an error when there are not enough static ips", func() {
+				err := staticIPs.Execute([]string{"--network", "private", "--count", "3"}, state)
+				Expect(err).To(MatchError(`network "private" only has 2 unallocated static ips, 3 requested`))
+				Expect(stateStore.SetCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the state cannot be saved", func() {
+				stateStore.SetCall.Returns = []fakes.SetCallReturn{{Error: errors.New("failed to save state")}}
+
+				err := staticIPs.Execute([]string{"--network", "private", "--count", "1"}, state)
+				Expect(err).To(MatchError("failed to save state"))
+			})
+		})
+	})
+})
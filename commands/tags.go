@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+	"github.com/coreos/go-semver/semver"
+)
+
+const (
+	// maxAWSTags leaves room for the Name and bbl-env-id tags bbl adds
+	// next to the 50 tags aws allows on a resource.
+	maxAWSTags           = 48
+	maxAWSTagKeyLength   = 128
+	maxAWSTagValueLength = 256
+	awsReservedTagPrefix = "aws:"
+	maxGCPLabels         = 64
+
+	// minimumGCPLabelsTerraformVersion is the first terraform version with
+	// a separate google provider, whose dns zones accept labels.
+	minimumGCPLabelsTerraformVersion = "0.10.0"
+)
+
+var (
+	gcpLabelKeyRegexp   = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,62}$`)
+	gcpLabelValueRegexp = regexp.MustCompile(`^[a-z0-9_-]{0,63}$`)
+)
+
+// loadTags parses the KEY=VALUE tags given to up. Tags that are given
+// replace the tags of the state, so that up without --tag keeps them. On gcp
+// the tags become labels, which only allow lowercase keys and values.
+func loadTags(tags map[string]string, values []string, iaas string) (map[string]string, error) {
+	if len(values) == 0 {
+		return tags, nil
+	}
+
+	parsed := map[string]string{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid --tag %q, expected KEY=VALUE", value)
+		}
+
+		key := parts[0]
+		if _, ok := parsed[key]; ok {
+			return nil, fmt.Errorf("invalid --tag %q, %q is given twice", value, key)
+		}
+
+		err := validateTag(iaas, key, parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid --tag %q, %s", value, err)
+		}
+
+		parsed[key] = parts[1]
+	}
+
+	switch {
+	case iaas == "aws" && len(parsed) > maxAWSTags:
+		return nil, fmt.Errorf("too many tags, aws resources allow at most %d tags next to the ones bbl adds", maxAWSTags)
+	case iaas == "gcp" && len(parsed) > maxGCPLabels:
+		return nil, fmt.Errorf("too many tags, gcp resources allow at most %d labels", maxGCPLabels)
+	}
+
+	return parsed, nil
+}
+
+func validateTag(iaas, key, value string) error {
+	switch iaas {
+	case "aws":
+		if key == "bbl-env-id" || key == "Name" {
+			return fmt.Errorf("the %q tag is managed by bbl", key)
+		}
+
+		if strings.HasPrefix(strings.ToLower(key), awsReservedTagPrefix) {
+			return fmt.Errorf("keys starting with %q are reserved by aws", awsReservedTagPrefix)
+		}
+
+		if len(key) > maxAWSTagKeyLength {
+			return fmt.Errorf("keys allow at most %d characters", maxAWSTagKeyLength)
+		}
+
+		if len(value) > maxAWSTagValueLength {
+			return fmt.Errorf("values allow at most %d characters", maxAWSTagValueLength)
+		}
+	case "gcp":
+		if !gcpLabelKeyRegexp.MatchString(key) {
+			return errors.New("gcp label keys must start with a lowercase letter and contain at most 63 lowercase letters, numbers, dashes and underscores")
+		}
+
+		if !gcpLabelValueRegexp.MatchString(value) {
+			return errors.New("gcp label values must contain at most 63 lowercase letters, numbers, dashes and underscores")
+		}
+	}
+
+	return nil
+}
+
+// checkGCPLabelSupport fails before terraform runs when the tags would label
+// a cloud dns zone with a terraform whose built-in google provider does not
+// accept labels. The other gcp resources bbl creates are never labeled.
+func checkGCPLabelSupport(terraformManager terraformManager, state storage.State) error {
+	if len(state.Tags) == 0 || !hasLBDomain(state) {
+		return nil
+	}
+
+	version, err := terraformManager.Version()
+	if err != nil {
+		return err
+	}
+
+	currentVersion, err := semver.NewVersion(version)
+	if err != nil {
+		return err
+	}
+
+	if currentVersion.LessThan(*semver.New(minimumGCPLabelsTerraformVersion)) {
+		return fmt.Errorf("--tag labels the cloud dns zones of lbs with a --domain, which needs terraform %s or later", minimumGCPLabelsTerraformVersion)
+	}
+
+	return nil
+}
+
+func hasLBDomain(state storage.State) bool {
+	if state.LB.Domain != "" {
+		return true
+	}
+
+	for _, spec := range state.LBs {
+		if spec.Domain != "" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -32,13 +32,28 @@ type upConfig struct {
 	awsSecretAccessKey   string
 	awsRegion            string
 	awsBOSHAZ            string
+	awsDualStack         bool
 	gcpServiceAccountKey string
 	gcpProjectID         string
 	gcpZone              string
 	gcpRegion            string
 	iaas                 string
 	name                 string
+	namePrefix           string
+	nameTeam             string
+	namePurpose          string
+	tags                 []string
 	opsFile              string
+	cloudConfigOpsFiles  []string
+	sizingProfile        string
+	runtimeConfig        string
+	reservedIPCounts     []string
+	staticIPCounts       []string
+	interactive          bool
+	sshPrivateKey        string
+	sshPublicKey         string
+	sshKeyType           string
+	sshKeyBits           int
 	noDirector           bool
 	terraform            bool
 }
@@ -89,14 +104,30 @@ func (u Up) Execute(args []string, state storage.State) error {
 	switch desiredIAAS {
 	case "aws":
 		err = u.awsUp.Execute(AWSUpConfig{
-			AccessKeyID:     config.awsAccessKeyID,
-			SecretAccessKey: config.awsSecretAccessKey,
-			Region:          config.awsRegion,
-			BOSHAZ:          config.awsBOSHAZ,
-			OpsFilePath:     config.opsFile,
-			Name:            config.name,
-			NoDirector:      config.noDirector,
-			Terraform:       config.terraform,
+			AccessKeyID:       config.awsAccessKeyID,
+			SecretAccessKey:   config.awsSecretAccessKey,
+			Region:            config.awsRegion,
+			BOSHAZ:            config.awsBOSHAZ,
+			DualStack:         config.awsDualStack,
+			OpsFilePath:       config.opsFile,
+			SSHPrivateKeyPath: config.sshPrivateKey,
+			SSHPublicKeyPath:  config.sshPublicKey,
+			SSHKeyType:        config.sshKeyType,
+			SSHKeyBits:        config.sshKeyBits,
+			Name:              config.name,
+			NamePrefix:        config.namePrefix,
+			NameTeam:          config.nameTeam,
+			NamePurpose:       config.namePurpose,
+			Tags:              config.tags,
+			NoDirector:        config.noDirector,
+			Terraform:         config.terraform,
+
+			CloudConfigOpsFilePaths: config.cloudConfigOpsFiles,
+			SizingProfile:           config.sizingProfile,
+			RuntimeConfigPath:       config.runtimeConfig,
+			ReservedIPCounts:        config.reservedIPCounts,
+			StaticIPCounts:          config.staticIPCounts,
+			Interactive:             config.interactive,
 		}, state)
 	case "gcp":
 		err = u.gcpUp.Execute(GCPUpConfig{
@@ -105,8 +136,23 @@ func (u Up) Execute(args []string, state storage.State) error {
 			Zone:              config.gcpZone,
 			Region:            config.gcpRegion,
 			OpsFilePath:       config.opsFile,
+			SSHPrivateKeyPath: config.sshPrivateKey,
+			SSHPublicKeyPath:  config.sshPublicKey,
+			SSHKeyType:        config.sshKeyType,
+			SSHKeyBits:        config.sshKeyBits,
 			Name:              config.name,
+			NamePrefix:        config.namePrefix,
+			NameTeam:          config.nameTeam,
+			NamePurpose:       config.namePurpose,
+			Tags:              config.tags,
 			NoDirector:        config.noDirector,
+
+			CloudConfigOpsFilePaths: config.cloudConfigOpsFiles,
+			SizingProfile:           config.sizingProfile,
+			RuntimeConfigPath:       config.runtimeConfig,
+			ReservedIPCounts:        config.reservedIPCounts,
+			StaticIPCounts:          config.staticIPCounts,
+			Interactive:             config.interactive,
 		}, state)
 	default:
 		return fmt.Errorf("%q is an invalid iaas type, supported values are: [gcp, aws]", desiredIAAS)
@@ -130,6 +176,7 @@ func (u Up) parseArgs(args []string) (upConfig, error) {
 	upFlags.String(&config.awsSecretAccessKey, "aws-secret-access-key", u.envGetter.Get("BBL_AWS_SECRET_ACCESS_KEY"))
 	upFlags.String(&config.awsRegion, "aws-region", u.envGetter.Get("BBL_AWS_REGION"))
 	upFlags.String(&config.awsBOSHAZ, "aws-bosh-az", u.envGetter.Get("BBL_AWS_BOSH_AZ"))
+	upFlags.Bool(&config.awsDualStack, "", "aws-dual-stack", false)
 
 	upFlags.String(&config.gcpServiceAccountKey, "gcp-service-account-key", u.envGetter.Get("BBL_GCP_SERVICE_ACCOUNT_KEY"))
 	upFlags.String(&config.gcpProjectID, "gcp-project-id", u.envGetter.Get("BBL_GCP_PROJECT_ID"))
@@ -137,14 +184,32 @@ func (u Up) parseArgs(args []string) (upConfig, error) {
 	upFlags.String(&config.gcpRegion, "gcp-region", u.envGetter.Get("BBL_GCP_REGION"))
 
 	upFlags.String(&config.name, "name", "")
+	upFlags.String(&config.namePrefix, "name-prefix", "")
+	upFlags.String(&config.nameTeam, "name-team", "")
+	upFlags.String(&config.namePurpose, "name-purpose", "")
+	upFlags.StringSlice(&config.tags, "tag", nil)
 	upFlags.String(&config.opsFile, "ops-file", "")
+	upFlags.StringSlice(&config.cloudConfigOpsFiles, "cloud-config-ops-file", nil)
+	upFlags.String(&config.sizingProfile, "sizing-profile", "")
+	upFlags.String(&config.runtimeConfig, "runtime-config", "")
+	upFlags.StringSlice(&config.reservedIPCounts, "reserved-ip-count", nil)
+	upFlags.StringSlice(&config.staticIPCounts, "static-ip-count", nil)
+	upFlags.String(&config.sshPrivateKey, "ssh-private-key", "")
+	upFlags.String(&config.sshPublicKey, "ssh-public-key", "")
+	upFlags.String(&config.sshKeyType, "ssh-key-type", "")
+	upFlags.Int(&config.sshKeyBits, "ssh-key-bits", 0)
 	upFlags.Bool(&config.noDirector, "", "no-director", false)
 	upFlags.Bool(&config.terraform, "", "terraform", false)
+	upFlags.Bool(&config.interactive, "", "interactive", false)
 
 	err := upFlags.Parse(args)
 	if err != nil {
 		return upConfig{}, err
 	}
 
+	if config.name != "" && (config.namePrefix != "" || config.nameTeam != "" || config.namePurpose != "") {
+		return upConfig{}, errors.New("--name cannot be used with --name-prefix, --name-team or --name-purpose, which generate the name")
+	}
+
 	return config, nil
 }
@@ -187,6 +187,265 @@ var _ = Describe("Up", func() {
 			})
 		})
 
+		Context("when ssh keypair flags are provided", func() {
+			It("populates the aws config with the ssh keypair options", func() {
+				fakeEnvGetter.Values = map[string]string{
+					"BBL_AWS_ACCESS_KEY_ID":     "access-key-id-from-env",
+					"BBL_AWS_SECRET_ACCESS_KEY": "secret-access-key-from-env",
+					"BBL_AWS_REGION":            "region-from-env",
+				}
+
+				err := command.Execute([]string{
+					"--iaas", "aws",
+					"--ssh-private-key", "some-private-key-path",
+					"--ssh-public-key", "some-public-key-path",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig).To(Equal(commands.AWSUpConfig{
+					AccessKeyID:       "access-key-id-from-env",
+					SecretAccessKey:   "secret-access-key-from-env",
+					Region:            "region-from-env",
+					SSHPrivateKeyPath: "some-private-key-path",
+					SSHPublicKeyPath:  "some-public-key-path",
+				}))
+			})
+
+			It("populates the gcp config with the ssh key type and size", func() {
+				fakeEnvGetter.Values = map[string]string{
+					"BBL_GCP_SERVICE_ACCOUNT_KEY": "some-service-account-key-env",
+					"BBL_GCP_PROJECT_ID":          "some-project-id-env",
+					"BBL_GCP_ZONE":                "some-zone-env",
+					"BBL_GCP_REGION":              "some-region-env",
+				}
+
+				err := command.Execute([]string{
+					"--iaas", "gcp",
+					"--ssh-key-type", "rsa",
+					"--ssh-key-bits", "4096",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig).To(Equal(commands.GCPUpConfig{
+					ServiceAccountKey: "some-service-account-key-env",
+					ProjectID:         "some-project-id-env",
+					Zone:              "some-zone-env",
+					Region:            "some-region-env",
+					SSHKeyType:        "rsa",
+					SSHKeyBits:        4096,
+				}))
+			})
+		})
+
+		Context("when cloud config ops files are provided", func() {
+			It("populates the aws config with every cloud config ops file", func() {
+				fakeEnvGetter.Values = map[string]string{
+					"BBL_AWS_ACCESS_KEY_ID":     "access-key-id-from-env",
+					"BBL_AWS_SECRET_ACCESS_KEY": "secret-access-key-from-env",
+					"BBL_AWS_REGION":            "region-from-env",
+				}
+
+				err := command.Execute([]string{
+					"--iaas", "aws",
+					"--cloud-config-ops-file", "some-ops-file-path",
+					"--cloud-config-ops-file", "some-other-ops-file-path",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig).To(Equal(commands.AWSUpConfig{
+					AccessKeyID:             "access-key-id-from-env",
+					SecretAccessKey:         "secret-access-key-from-env",
+					Region:                  "region-from-env",
+					CloudConfigOpsFilePaths: []string{"some-ops-file-path", "some-other-ops-file-path"},
+				}))
+			})
+
+			It("populates the gcp config with every cloud config ops file", func() {
+				fakeEnvGetter.Values = map[string]string{
+					"BBL_GCP_SERVICE_ACCOUNT_KEY": "some-service-account-key-env",
+					"BBL_GCP_PROJECT_ID":          "some-project-id-env",
+					"BBL_GCP_ZONE":                "some-zone-env",
+					"BBL_GCP_REGION":              "some-region-env",
+				}
+
+				err := command.Execute([]string{
+					"--iaas", "gcp",
+					"--cloud-config-ops-file", "some-ops-file-path",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig).To(Equal(commands.GCPUpConfig{
+					ServiceAccountKey:       "some-service-account-key-env",
+					ProjectID:               "some-project-id-env",
+					Zone:                    "some-zone-env",
+					Region:                  "some-region-env",
+					CloudConfigOpsFilePaths: []string{"some-ops-file-path"},
+				}))
+			})
+
+			It("populates the aws config with --interactive", func() {
+				fakeEnvGetter.Values = map[string]string{
+					"BBL_AWS_ACCESS_KEY_ID":     "access-key-id-from-env",
+					"BBL_AWS_SECRET_ACCESS_KEY": "secret-access-key-from-env",
+					"BBL_AWS_REGION":            "region-from-env",
+				}
+
+				err := command.Execute([]string{
+					"--iaas", "aws",
+					"--interactive",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.Interactive).To(BeTrue())
+			})
+
+			It("populates the aws config with the sizing profile", func() {
+				fakeEnvGetter.Values = map[string]string{
+					"BBL_AWS_ACCESS_KEY_ID":     "access-key-id-from-env",
+					"BBL_AWS_SECRET_ACCESS_KEY": "secret-access-key-from-env",
+					"BBL_AWS_REGION":            "region-from-env",
+				}
+
+				err := command.Execute([]string{
+					"--iaas", "aws",
+					"--sizing-profile", "small",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.SizingProfile).To(Equal("small"))
+			})
+
+			It("populates the gcp config with the sizing profile", func() {
+				fakeEnvGetter.Values = map[string]string{
+					"BBL_GCP_SERVICE_ACCOUNT_KEY": "some-service-account-key-env",
+					"BBL_GCP_PROJECT_ID":          "some-project-id-env",
+					"BBL_GCP_ZONE":                "some-zone-env",
+					"BBL_GCP_REGION":              "some-region-env",
+				}
+
+				err := command.Execute([]string{
+					"--iaas", "gcp",
+					"--sizing-profile", "/some/sizing-profile.yml",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.SizingProfile).To(Equal("/some/sizing-profile.yml"))
+			})
+
+			It("populates the aws config with the runtime config path", func() {
+				fakeEnvGetter.Values = map[string]string{
+					"BBL_AWS_ACCESS_KEY_ID":     "access-key-id-from-env",
+					"BBL_AWS_SECRET_ACCESS_KEY": "secret-access-key-from-env",
+					"BBL_AWS_REGION":            "region-from-env",
+				}
+
+				err := command.Execute([]string{
+					"--iaas", "aws",
+					"--runtime-config", "/some/runtime-config.yml",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.RuntimeConfigPath).To(Equal("/some/runtime-config.yml"))
+			})
+
+			It("populates the gcp config with the runtime config path", func() {
+				fakeEnvGetter.Values = map[string]string{
+					"BBL_GCP_SERVICE_ACCOUNT_KEY": "some-service-account-key-env",
+					"BBL_GCP_PROJECT_ID":          "some-project-id-env",
+					"BBL_GCP_ZONE":                "some-zone-env",
+					"BBL_GCP_REGION":              "some-region-env",
+				}
+
+				err := command.Execute([]string{
+					"--iaas", "gcp",
+					"--runtime-config", "/some/runtime-config.yml",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.RuntimeConfigPath).To(Equal("/some/runtime-config.yml"))
+			})
+
+			It("populates the aws config with dual stack", func() {
+				fakeEnvGetter.Values = map[string]string{
+					"BBL_AWS_ACCESS_KEY_ID":     "access-key-id-from-env",
+					"BBL_AWS_SECRET_ACCESS_KEY": "secret-access-key-from-env",
+					"BBL_AWS_REGION":            "region-from-env",
+				}
+
+				err := command.Execute([]string{
+					"--iaas", "aws",
+					"--terraform",
+					"--aws-dual-stack",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.DualStack).To(BeTrue())
+			})
+
+			It("populates the aws config with the reserved and static ip counts", func() {
+				fakeEnvGetter.Values = map[string]string{
+					"BBL_AWS_ACCESS_KEY_ID":     "access-key-id-from-env",
+					"BBL_AWS_SECRET_ACCESS_KEY": "secret-access-key-from-env",
+					"BBL_AWS_REGION":            "region-from-env",
+				}
+
+				err := command.Execute([]string{
+					"--iaas", "aws",
+					"--reserved-ip-count", "private=8",
+					"--static-ip-count", "private=128",
+					"--static-ip-count", "default=16",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.ReservedIPCounts).To(Equal([]string{"private=8"}))
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.StaticIPCounts).To(Equal([]string{"private=128", "default=16"}))
+			})
+
+			It("populates the gcp config with the reserved and static ip counts", func() {
+				fakeEnvGetter.Values = map[string]string{
+					"BBL_GCP_SERVICE_ACCOUNT_KEY": "some-service-account-key-env",
+					"BBL_GCP_PROJECT_ID":          "some-project-id-env",
+					"BBL_GCP_ZONE":                "some-zone-env",
+					"BBL_GCP_REGION":              "some-region-env",
+				}
+
+				err := command.Execute([]string{
+					"--iaas", "gcp",
+					"--reserved-ip-count", "default=4",
+					"--static-ip-count", "private=32",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.ReservedIPCounts).To(Equal([]string{"default=4"}))
+				Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.StaticIPCounts).To(Equal([]string{"private=32"}))
+			})
+
+			It("populates the aws and gcp configs with the tags", func() {
+				fakeEnvGetter.Values = map[string]string{
+					"BBL_AWS_ACCESS_KEY_ID":     "access-key-id-from-env",
+					"BBL_AWS_SECRET_ACCESS_KEY": "secret-access-key-from-env",
+					"BBL_AWS_REGION":            "region-from-env",
+				}
+
+				err := command.Execute([]string{
+					"--iaas", "aws",
+					"--tag", "owner=platform",
+					"--tag", "cost-center=1234",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.Tags).To(Equal([]string{"owner=platform", "cost-center=1234"}))
+
+				err = command.Execute([]string{
+					"--iaas", "gcp",
+					"--tag", "owner=platform",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.Tags).To(Equal([]string{"owner=platform"}))
+			})
+		})
+
 		Context("when gcp args are provided through environment variables", func() {
 			BeforeEach(func() {
 				fakeEnvGetter.Values = map[string]string{
@@ -468,6 +727,36 @@ var _ = Describe("Up", func() {
 			})
 		})
 
+		Context("when the user provides name generation flags", func() {
+			It("passes them in the aws up config", func() {
+				err := command.Execute([]string{
+					"--iaas", "aws",
+					"--name-prefix", "acme",
+					"--name-team", "payments",
+					"--name-purpose", "staging",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.NamePrefix).To(Equal("acme"))
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.NameTeam).To(Equal("payments"))
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.NamePurpose).To(Equal("staging"))
+			})
+
+			It("passes them in the gcp up config", func() {
+				err := command.Execute([]string{
+					"--iaas", "gcp",
+					"--name-prefix", "acme",
+					"--name-team", "payments",
+					"--name-purpose", "staging",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.NamePrefix).To(Equal("acme"))
+				Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.NameTeam).To(Equal("payments"))
+				Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.NamePurpose).To(Equal("staging"))
+			})
+		})
+
 		Context("when the user provides the name flag", func() {
 			It("passes the name flag in the up config", func() {
 				err := command.Execute([]string{
@@ -479,6 +768,16 @@ var _ = Describe("Up", func() {
 				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.Name).To(Equal("a-better-name"))
 			})
 
+			It("returns an error when name generation flags are provided too", func() {
+				err := command.Execute([]string{
+					"--iaas", "aws",
+					"--name", "a-better-name",
+					"--name-team", "payments",
+				}, storage.State{})
+				Expect(err).To(MatchError("--name cannot be used with --name-prefix, --name-team or --name-purpose, which generate the name"))
+				Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(0))
+			})
+
 			Context("when bbl-state contains an env-id", func() {
 				var (
 					name  = "some-name"
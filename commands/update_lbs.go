@@ -1,18 +1,39 @@
 package commands
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws/acm"
 	"github.com/cloudfoundry/bosh-bootloader/flags"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
-const UpdateLBsCommand = "update-lbs"
+const (
+	UpdateLBsCommand = "update-lbs"
+
+	// generatedCertRenewal is how long before its expiry update-lbs renews
+	// a certificate generated with --generate-cert.
+	generatedCertRenewal = 30 * 24 * time.Hour
+)
 
 type updateLBConfig struct {
+	name          string
 	certPath      string
 	keyPath       string
 	chainPath     string
+	sniCerts      []CertBundle
 	domain        string
+	parentZone    string
+	dnsRecords    string
+	passphrase    string
+	awsLBFlavor   string
+	awsACM        bool
+	awsACMCert    string
 	skipIfMissing bool
+	generatedDir  string
 }
 
 type UpdateLBs struct {
@@ -22,6 +43,7 @@ type UpdateLBs struct {
 	stateValidator       stateValidator
 	logger               logger
 	boshManager          boshManager
+	certGenerator        lbCertificateGenerator
 }
 
 type awsUpdateLBs interface {
@@ -33,7 +55,7 @@ type gcpUpdateLBs interface {
 }
 
 func NewUpdateLBs(awsUpdateLBs awsUpdateLBs, gcpUpdateLBs gcpUpdateLBs, certificateValidator certificateValidator,
-	stateValidator stateValidator, logger logger, boshManager boshManager) UpdateLBs {
+	stateValidator stateValidator, logger logger, boshManager boshManager, certGenerator lbCertificateGenerator) UpdateLBs {
 
 	return UpdateLBs{
 		awsUpdateLBs:         awsUpdateLBs,
@@ -42,6 +64,7 @@ func NewUpdateLBs(awsUpdateLBs awsUpdateLBs, gcpUpdateLBs gcpUpdateLBs, certific
 		stateValidator:       stateValidator,
 		logger:               logger,
 		boshManager:          boshManager,
+		certGenerator:        certGenerator,
 	}
 }
 
@@ -56,6 +79,14 @@ func (u UpdateLBs) Execute(subcommandFlags []string, state storage.State) error
 		return err
 	}
 
+	if config.passphrase != "" {
+		decryptedDir, err := decryptKeys(config.passphrase, &config.keyPath, config.sniCerts)
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(decryptedDir)
+	}
+
 	if !state.NoDirector {
 		err = fastFailBOSHVersion(u.boshManager)
 		if err != nil {
@@ -63,6 +94,48 @@ func (u UpdateLBs) Execute(subcommandFlags []string, state storage.State) error
 		}
 	}
 
+	if config.awsLBFlavor != "" {
+		if state.IAAS != "aws" {
+			return errors.New("--aws-lb-flavor is only supported on aws")
+		}
+
+		if config.name == "" {
+			return errors.New("--aws-lb-flavor requires --name, the name of the lb to migrate or of the lb the unnamed classic elb migrates into")
+		}
+
+		if state.TFState != "" {
+			return errors.New("--aws-lb-flavor is not supported on environments created with --terraform, their lbs are classic elbs")
+		}
+	}
+
+	if config.awsACM || config.awsACMCert != "" {
+		if state.IAAS != "aws" {
+			return errors.New("--aws-acm and --aws-acm-cert-arn are only supported on aws")
+		}
+
+		if state.TFState != "" {
+			return errors.New("--aws-acm and --aws-acm-cert-arn are not supported on environments created with --terraform, their lbs serve iam server certificates")
+		}
+	}
+
+	var dnsRecords []storage.DNSRecord
+	if config.parentZone != "" || config.dnsRecords != "" {
+		if state.IAAS != "gcp" {
+			return errors.New("--parent-zone and --dns-records are only supported on gcp, bbl does not create a route53 zone for the domain of aws lbs")
+		}
+
+		if config.dnsRecords != "" {
+			dnsRecords, err = loadDNSRecords(config.dnsRecords)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if config.name != "" {
+		return u.updateNamed(config, dnsRecords, state)
+	}
+
 	lbExists := lbExists(state.Stack.LBType) || lbExists(state.LB.Type)
 	if config.skipIfMissing && !lbExists {
 		u.logger.Println("no lb type exists, skipping...")
@@ -73,7 +146,26 @@ func (u UpdateLBs) Execute(subcommandFlags []string, state storage.State) error
 		return LBNotFound
 	}
 
-	err = u.certificateValidator.Validate(UpdateLBsCommand, config.certPath, config.keyPath, config.chainPath)
+	if spec, ok := unnamedLBMigration(state); ok {
+		return fmt.Errorf("the unnamed lb is migrating into lb %q, finish the migration with `bbl update-lbs --name %s --aws-lb-flavor %s` first", spec.Name, spec.Name, spec.Flavor)
+	}
+
+	err = checkSNISupport(storage.LBSpec{Type: state.LB.Type}, state.IAAS, config.sniCerts)
+	if err != nil {
+		return err
+	}
+
+	state, current, err := u.renewGeneratedCert(&config, state)
+	if err != nil {
+		return err
+	}
+
+	if current {
+		return nil
+	}
+	defer os.RemoveAll(config.generatedDir)
+
+	err = u.validateCertificates(config)
 	if err != nil {
 		return err
 	}
@@ -81,19 +173,25 @@ func (u UpdateLBs) Execute(subcommandFlags []string, state storage.State) error
 	switch state.IAAS {
 	case "gcp":
 		if err := u.gcpUpdateLBs.Execute(GCPCreateLBsConfig{
-			LBType:   state.LB.Type,
-			CertPath: config.certPath,
-			KeyPath:  config.keyPath,
-			Domain:   config.domain,
+			LBType:     state.LB.Type,
+			CertPath:   config.certPath,
+			KeyPath:    config.keyPath,
+			SNICerts:   config.sniCerts,
+			Domain:     config.domain,
+			ParentZone: config.parentZone,
+			DNSRecords: dnsRecords,
 		}, state); err != nil {
 			return err
 		}
 	case "aws":
 		if err := u.awsUpdateLBs.Execute(AWSCreateLBsConfig{
-			LBType:    state.Stack.LBType,
-			CertPath:  config.certPath,
-			KeyPath:   config.keyPath,
-			ChainPath: config.chainPath,
+			LBType:            state.Stack.LBType,
+			CertPath:          config.certPath,
+			KeyPath:           config.keyPath,
+			ChainPath:         config.chainPath,
+			SNICerts:          config.sniCerts,
+			ACM:               config.awsACM,
+			ACMCertificateARN: config.awsACMCert,
 		}, state); err != nil {
 			return err
 		}
@@ -101,14 +199,162 @@ func (u UpdateLBs) Execute(subcommandFlags []string, state storage.State) error
 	return nil
 }
 
+func (u UpdateLBs) updateNamed(config updateLBConfig, dnsRecords []storage.DNSRecord, state storage.State) error {
+	spec, ok := findLBSpec(state.LBs, config.name)
+	if !ok && config.awsLBFlavor != "" && lbExists(state.Stack.LBType) {
+		if err := validateLBName(config.name); err != nil {
+			return err
+		}
+
+		return u.awsUpdateLBs.Execute(AWSCreateLBsConfig{
+			LBType:   state.Stack.LBType,
+			Spec:     storage.LBSpec{Name: config.name, Type: state.Stack.LBType},
+			LBFlavor: config.awsLBFlavor,
+		}, state)
+	}
+
+	if !ok {
+		if config.skipIfMissing {
+			u.logger.Println(fmt.Sprintf("lb %q does not exist, skipping...", config.name))
+			return nil
+		}
+		return fmt.Errorf("no load balancer named %q has been found for this bbl environment", config.name)
+	}
+
+	if config.awsLBFlavor != "" {
+		return u.awsUpdateLBs.Execute(AWSCreateLBsConfig{
+			LBType:   spec.Type,
+			Spec:     spec,
+			LBFlavor: config.awsLBFlavor,
+		}, state)
+	}
+
+	if !lbSpecRequiresCert(spec) {
+		return fmt.Errorf("lb %q does not terminate tls, there is no certificate to update", config.name)
+	}
+
+	if migrating, ok := unnamedLBMigration(state); ok && migrating.Name == spec.Name {
+		return fmt.Errorf("the unnamed lb is migrating into lb %q, finish the migration with `bbl update-lbs --name %s --aws-lb-flavor %s` first", spec.Name, spec.Name, spec.Flavor)
+	}
+
+	err := checkSNISupport(spec, state.IAAS, config.sniCerts)
+	if err != nil {
+		return err
+	}
+
+	state, current, err := u.renewGeneratedCert(&config, state)
+	if err != nil {
+		return err
+	}
+
+	if current {
+		return nil
+	}
+	defer os.RemoveAll(config.generatedDir)
+
+	err = u.validateCertificates(config)
+	if err != nil {
+		return err
+	}
+
+	switch state.IAAS {
+	case "gcp":
+		return u.gcpUpdateLBs.Execute(GCPCreateLBsConfig{
+			LBType:     spec.Type,
+			CertPath:   config.certPath,
+			KeyPath:    config.keyPath,
+			SNICerts:   config.sniCerts,
+			Domain:     config.domain,
+			ParentZone: config.parentZone,
+			DNSRecords: dnsRecords,
+			Spec:       spec,
+		}, state)
+	case "aws":
+		return u.awsUpdateLBs.Execute(AWSCreateLBsConfig{
+			LBType:            spec.Type,
+			CertPath:          config.certPath,
+			KeyPath:           config.keyPath,
+			ChainPath:         config.chainPath,
+			SNICerts:          config.sniCerts,
+			Spec:              spec,
+			ACM:               config.awsACM,
+			ACMCertificateARN: config.awsACMCert,
+		}, state)
+	}
+
+	return nil
+}
+
+// renewGeneratedCert generates a new certificate for an lb created with
+// --generate-cert when no certificate is given. A given certificate
+// or acm certificate replaces the generated one, which is then no longer
+// renewed. The returned bool is true when the generated certificate is not
+// close to its expiry and nothing else about the lb changes, in which case
+// there is nothing to update.
+func (u UpdateLBs) renewGeneratedCert(config *updateLBConfig, state storage.State) (storage.State, bool, error) {
+	if config.certPath != "" || config.awsACMCert != "" {
+		state.LBCerts.GeneratedCerts = forgetGeneratedCert(state.LBCerts.GeneratedCerts, config.name)
+		return state, false, nil
+	}
+
+	cert, ok := findGeneratedCert(state.LBCerts.GeneratedCerts, config.name)
+	if !ok {
+		return state, false, nil
+	}
+
+	sameDomain := config.domain == "" || config.domain == cert.Domain
+	otherChanges := config.parentZone != "" || config.dnsRecords != "" || len(config.sniCerts) > 0 || config.awsACM
+	if sameDomain && !otherChanges && timeNow().Add(generatedCertRenewal).Before(cert.NotAfter) {
+		u.logger.Println(fmt.Sprintf("generated certificate is valid until %s, skipping...", cert.NotAfter.Format(time.RFC3339)))
+		return state, true, nil
+	}
+
+	if config.domain != "" {
+		cert.Domain = config.domain
+	}
+
+	u.logger.Step("renewing generated certificate")
+
+	state, bundle, err := u.certGenerator.Generate(state, cert)
+	if err != nil {
+		return state, false, err
+	}
+
+	config.certPath, config.keyPath, config.chainPath = bundle.CertPath, bundle.KeyPath, bundle.ChainPath
+	config.generatedDir = bundle.Dir
+
+	return state, false, nil
+}
+
+func (u UpdateLBs) validateCertificates(config updateLBConfig) error {
+	if config.awsACMCert != "" {
+		return nil
+	}
+
+	err := u.certificateValidator.Validate(UpdateLBsCommand, config.certPath, config.keyPath, config.chainPath)
+	if err != nil {
+		return err
+	}
+
+	return validateSNICertificates(u.certificateValidator, UpdateLBsCommand, config.sniCerts)
+}
+
 func (UpdateLBs) parseFlags(subcommandFlags []string) (updateLBConfig, error) {
 	lbFlags := flags.New("update-lbs")
 
 	config := updateLBConfig{}
-	lbFlags.String(&config.certPath, "cert", "")
-	lbFlags.String(&config.keyPath, "key", "")
-	lbFlags.String(&config.chainPath, "chain", "")
+	lbFlags.String(&config.name, "name", "")
+	var certPaths, keyPaths, chainPaths []string
+	lbFlags.StringSlice(&certPaths, "cert", nil)
+	lbFlags.StringSlice(&keyPaths, "key", nil)
+	lbFlags.StringSlice(&chainPaths, "chain", nil)
 	lbFlags.String(&config.domain, "domain", "")
+	lbFlags.String(&config.parentZone, "parent-zone", "")
+	lbFlags.String(&config.dnsRecords, "dns-records", "")
+	lbFlags.String(&config.passphrase, "key-passphrase", "")
+	lbFlags.String(&config.awsLBFlavor, "aws-lb-flavor", "")
+	lbFlags.Bool(&config.awsACM, "", "aws-acm", false)
+	lbFlags.String(&config.awsACMCert, "aws-acm-cert-arn", "")
 	lbFlags.Bool(&config.skipIfMissing, "skip-if-missing", "", false)
 
 	err := lbFlags.Parse(subcommandFlags)
@@ -116,5 +362,30 @@ func (UpdateLBs) parseFlags(subcommandFlags []string) (updateLBConfig, error) {
 		return config, err
 	}
 
+	primary, sniCerts, err := splitCertBundles(certPaths, keyPaths, chainPaths)
+	if err != nil {
+		return config, err
+	}
+	config.certPath, config.keyPath, config.chainPath = primary.CertPath, primary.KeyPath, primary.ChainPath
+	config.sniCerts = sniCerts
+
+	if config.awsACMCert != "" {
+		if len(certPaths) > 0 || len(keyPaths) > 0 || len(chainPaths) > 0 || config.passphrase != "" || config.awsACM {
+			return config, errors.New("--aws-acm-cert-arn cannot be used with --cert, --key, --chain, --key-passphrase or --aws-acm")
+		}
+
+		if !acm.IsCertificateARN(config.awsACMCert) {
+			return config, fmt.Errorf("%q is not the arn of an acm certificate", config.awsACMCert)
+		}
+	}
+
+	if config.parentZone != "" && !gcpZoneNameRegexp.MatchString(config.parentZone) {
+		return config, fmt.Errorf("%q is not a valid dns zone name, zone names start with a letter and contain only lowercase letters, numbers and dashes", config.parentZone)
+	}
+
+	if config.awsLBFlavor != "" && !isValidLBFlavor(config.awsLBFlavor) {
+		return config, fmt.Errorf("%q is not a valid aws lb flavor, valid flavors are: elb, alb and nlb", config.awsLBFlavor)
+	}
+
 	return config, nil
 }
@@ -1,7 +1,14 @@
 package commands_test
 
 import (
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/cloudfoundry/bosh-bootloader/commands"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
@@ -26,6 +33,7 @@ var _ = Describe("Update LBs", func() {
 		logger               *fakes.Logger
 		awsUpdateLBs         *fakes.AWSUpdateLBs
 		gcpUpdateLBs         *fakes.GCPUpdateLBs
+		certGenerator        *fakes.LBCertificateGenerator
 	)
 
 	BeforeEach(func() {
@@ -37,6 +45,7 @@ var _ = Describe("Update LBs", func() {
 		boshManager = &fakes.BOSHManager{}
 		awsUpdateLBs = &fakes.AWSUpdateLBs{}
 		gcpUpdateLBs = &fakes.GCPUpdateLBs{}
+		certGenerator = &fakes.LBCertificateGenerator{}
 
 		boshManager.VersionCall.Returns.Version = "2.0.0"
 
@@ -62,7 +71,7 @@ var _ = Describe("Update LBs", func() {
 		chainFilePath, err = testhelpers.WriteContentsToTempFile("some-chain-contents")
 		Expect(err).NotTo(HaveOccurred())
 
-		command = commands.NewUpdateLBs(awsUpdateLBs, gcpUpdateLBs, certificateValidator, stateValidator, logger, boshManager)
+		command = commands.NewUpdateLBs(awsUpdateLBs, gcpUpdateLBs, certificateValidator, stateValidator, logger, boshManager, certGenerator)
 	})
 
 	Describe("Execute", func() {
@@ -215,6 +224,349 @@ var _ = Describe("Update LBs", func() {
 			)
 		})
 
+		Context("when --name is provided", func() {
+			var spec storage.LBSpec
+
+			BeforeEach(func() {
+				spec = storage.LBSpec{Name: "foundation", Type: "cf", CertificateName: "some-foundation-certificate"}
+				incomingState.LBs = []storage.LBSpec{{Name: "vault"}, spec}
+			})
+
+			It("updates the named lb instead of the lb type", func() {
+				err := command.Execute([]string{
+					"--name", "foundation",
+					"--cert", "my-cert",
+					"--key", "my-key",
+				}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certificateValidator.ValidateCall.CallCount).To(Equal(1))
+				Expect(awsUpdateLBs.ExecuteCall.Receives.Config).To(Equal(commands.AWSCreateLBsConfig{
+					LBType:   "cf",
+					CertPath: "my-cert",
+					KeyPath:  "my-key",
+					Spec:     spec,
+				}))
+			})
+
+			It("returns an error when the named lb does not exist", func() {
+				err := command.Execute([]string{"--name", "other"}, incomingState)
+				Expect(err).To(MatchError(`no load balancer named "other" has been found for this bbl environment`))
+			})
+
+			It("no-ops when the named lb does not exist and --skip-if-missing is provided", func() {
+				err := command.Execute([]string{"--name", "other", "--skip-if-missing"}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.PrintlnCall.Receives.Message).To(Equal(`lb "other" does not exist, skipping...`))
+				Expect(awsUpdateLBs.ExecuteCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the named lb has no certificate", func() {
+				err := command.Execute([]string{"--name", "vault", "--cert", "my-cert", "--key", "my-key"}, incomingState)
+				Expect(err).To(MatchError(`lb "vault" does not terminate tls, there is no certificate to update`))
+			})
+		})
+
+		Context("when --key-passphrase is provided", func() {
+			It("validates and passes the decrypted key", func() {
+				block, _ := pem.Decode([]byte(testhelpers.BBL_KEY))
+				encryptedBlock, err := x509.EncryptPEMBlock(rand.Reader, block.Type, block.Bytes, []byte("some-passphrase"), x509.PEMCipherAES256)
+				Expect(err).NotTo(HaveOccurred())
+
+				encryptedKeyPath, err := testhelpers.WriteContentsToTempFile(string(pem.EncodeToMemory(encryptedBlock)))
+				Expect(err).NotTo(HaveOccurred())
+
+				var decryptedKey []byte
+				awsUpdateLBs.ExecuteCall.Stub = func(config commands.AWSCreateLBsConfig, state storage.State) error {
+					decryptedKey, err = ioutil.ReadFile(config.KeyPath)
+					return err
+				}
+
+				err = command.Execute([]string{
+					"--cert", certFilePath,
+					"--key", encryptedKeyPath,
+					"--key-passphrase", "some-passphrase",
+				}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				keyPath := awsUpdateLBs.ExecuteCall.Receives.Config.KeyPath
+				Expect(certificateValidator.ValidateCall.Receives.KeyPath).To(Equal(keyPath))
+				Expect(decryptedKey).To(Equal([]byte(testhelpers.BBL_KEY + "\n")))
+
+				_, err = os.Stat(filepath.Dir(keyPath))
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+		})
+
+		Context("when the lb has a generated certificate", func() {
+			var generated storage.LBGeneratedCert
+
+			BeforeEach(func() {
+				generated = storage.LBGeneratedCert{Mode: commands.ACMECertMode, Domain: "example.com"}
+				incomingState.LBCerts.GeneratedCerts = []storage.LBGeneratedCert{generated}
+
+				certGenerator.GenerateCall.Returns.State = incomingState
+				certGenerator.GenerateCall.Returns.CertBundle = commands.CertBundle{
+					CertPath:  "renewed-cert",
+					KeyPath:   "renewed-key",
+					ChainPath: "renewed-chain",
+				}
+			})
+
+			It("renews the certificate when no certificate is provided", func() {
+				err := command.Execute([]string{}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certGenerator.GenerateCall.Receives.Cert).To(Equal(generated))
+				Expect(logger.StepCall.Messages).To(ContainElement("renewing generated certificate"))
+				Expect(awsUpdateLBs.ExecuteCall.Receives.Config).To(Equal(commands.AWSCreateLBsConfig{
+					LBType:    "concourse",
+					CertPath:  "renewed-cert",
+					KeyPath:   "renewed-key",
+					ChainPath: "renewed-chain",
+				}))
+			})
+
+			It("removes the renewed certificate files once the lb is updated", func() {
+				dir, err := ioutil.TempDir("", "")
+				Expect(err).NotTo(HaveOccurred())
+				certGenerator.GenerateCall.Returns.CertBundle.Dir = dir
+
+				err = command.Execute([]string{}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(awsUpdateLBs.ExecuteCall.CallCount).To(Equal(1))
+				_, err = os.Stat(dir)
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+
+			It("renews the certificate for another domain", func() {
+				err := command.Execute([]string{"--domain", "other.example.com"}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certGenerator.GenerateCall.Receives.Cert.Domain).To(Equal("other.example.com"))
+			})
+
+			It("stops renewing the certificate when a certificate is provided", func() {
+				err := command.Execute([]string{"--cert", "my-cert", "--key", "my-key"}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certGenerator.GenerateCall.CallCount).To(Equal(0))
+				Expect(awsUpdateLBs.ExecuteCall.Receives.State.LBCerts.GeneratedCerts).To(BeEmpty())
+			})
+
+			Context("when the certificate is not close to its expiry", func() {
+				BeforeEach(func() {
+					now := time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+					commands.SetTimeNow(func() time.Time { return now })
+
+					incomingState.LBCerts.GeneratedCerts[0].NotAfter = now.Add(31 * 24 * time.Hour)
+				})
+
+				AfterEach(func() {
+					commands.ResetTimeNow()
+				})
+
+				It("does not renew the certificate", func() {
+					err := command.Execute([]string{}, incomingState)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(certGenerator.GenerateCall.CallCount).To(Equal(0))
+					Expect(awsUpdateLBs.ExecuteCall.CallCount).To(Equal(0))
+					Expect(logger.PrintlnCall.Messages).To(ContainElement("generated certificate is valid until 2018-02-01T00:00:00Z, skipping..."))
+				})
+
+				It("renews the certificate within 30 days of its expiry", func() {
+					incomingState.LBCerts.GeneratedCerts[0].NotAfter = time.Date(2018, time.January, 30, 0, 0, 0, 0, time.UTC)
+
+					err := command.Execute([]string{}, incomingState)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(certGenerator.GenerateCall.CallCount).To(Equal(1))
+				})
+
+				It("renews the certificate when the domain changes", func() {
+					err := command.Execute([]string{"--domain", "other.example.com"}, incomingState)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(certGenerator.GenerateCall.CallCount).To(Equal(1))
+				})
+			})
+
+			It("returns an error when the certificate cannot be renewed", func() {
+				certGenerator.GenerateCall.Returns.Error = errors.New("failed to generate")
+
+				err := command.Execute([]string{}, incomingState)
+				Expect(err).To(MatchError("failed to generate"))
+				Expect(awsUpdateLBs.ExecuteCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when acm is used", func() {
+			const certificateARN = "arn:aws:acm:us-east-1:123456789012:certificate/some-id"
+
+			It("passes --aws-acm to the aws command", func() {
+				err := command.Execute([]string{"--cert", "my-cert", "--key", "my-key", "--aws-acm"}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(awsUpdateLBs.ExecuteCall.Receives.Config.ACM).To(BeTrue())
+			})
+
+			It("passes the arn of --aws-acm-cert-arn without validating certificate files", func() {
+				incomingState.LBCerts.GeneratedCerts = []storage.LBGeneratedCert{{Mode: commands.SelfSignedCertMode, Domain: "example.com"}}
+
+				err := command.Execute([]string{"--aws-acm-cert-arn", certificateARN}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certificateValidator.ValidateCall.CallCount).To(Equal(0))
+				Expect(certGenerator.GenerateCall.CallCount).To(Equal(0))
+				Expect(awsUpdateLBs.ExecuteCall.Receives.Config).To(Equal(commands.AWSCreateLBsConfig{
+					LBType:            "concourse",
+					ACMCertificateARN: certificateARN,
+				}))
+				Expect(awsUpdateLBs.ExecuteCall.Receives.State.LBCerts.GeneratedCerts).To(BeEmpty())
+			})
+
+			It("returns an error when --aws-acm-cert-arn is used with --cert", func() {
+				err := command.Execute([]string{"--aws-acm-cert-arn", certificateARN, "--cert", "my-cert"}, incomingState)
+				Expect(err).To(MatchError("--aws-acm-cert-arn cannot be used with --cert, --key, --chain, --key-passphrase or --aws-acm"))
+			})
+
+			It("returns an error when the arn is not of an acm certificate", func() {
+				err := command.Execute([]string{"--aws-acm-cert-arn", "some-arn"}, incomingState)
+				Expect(err).To(MatchError(`"some-arn" is not the arn of an acm certificate`))
+			})
+
+			It("returns an error on gcp", func() {
+				incomingState.IAAS = "gcp"
+
+				err := command.Execute([]string{"--aws-acm-cert-arn", certificateARN}, incomingState)
+				Expect(err).To(MatchError("--aws-acm and --aws-acm-cert-arn are only supported on aws"))
+			})
+
+			It("returns an error on environments created with --terraform", func() {
+				incomingState.TFState = "some-tf-state"
+
+				err := command.Execute([]string{"--aws-acm-cert-arn", certificateARN}, incomingState)
+				Expect(err).To(MatchError("--aws-acm and --aws-acm-cert-arn are not supported on environments created with --terraform, their lbs serve iam server certificates"))
+			})
+		})
+
+		Context("when dns flags are provided", func() {
+			var recordsPath string
+
+			BeforeEach(func() {
+				var err error
+				recordsPath, err = testhelpers.WriteContentsToTempFile("[{name: www, type: A, records: [10.0.0.1]}]")
+				Expect(err).NotTo(HaveOccurred())
+
+				incomingState.IAAS = "gcp"
+				incomingState.LB = storage.LB{Type: "cf", Domain: "cf.example.com"}
+				incomingState.LBs = []storage.LBSpec{{Name: "foundation", Type: "cf", Domain: "foundation.example.com"}}
+			})
+
+			It("passes the parent zone and the records to the gcp command", func() {
+				err := command.Execute([]string{"--cert", "my-cert", "--key", "my-key", "--parent-zone", "example-com", "--dns-records", recordsPath}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(gcpUpdateLBs.ExecuteCall.Receives.Config.ParentZone).To(Equal("example-com"))
+				Expect(gcpUpdateLBs.ExecuteCall.Receives.Config.DNSRecords).To(Equal([]storage.DNSRecord{
+					{Name: "www", Type: "A", TTL: 300, Records: []string{"10.0.0.1"}},
+				}))
+			})
+
+			It("passes them for the named lb", func() {
+				err := command.Execute([]string{"--name", "foundation", "--cert", "my-cert", "--key", "my-key", "--parent-zone", "example-com"}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(gcpUpdateLBs.ExecuteCall.Receives.Config.Spec.Name).To(Equal("foundation"))
+				Expect(gcpUpdateLBs.ExecuteCall.Receives.Config.ParentZone).To(Equal("example-com"))
+			})
+
+			It("returns an error on aws", func() {
+				incomingState.IAAS = "aws"
+
+				err := command.Execute([]string{"--cert", "my-cert", "--key", "my-key", "--dns-records", recordsPath}, incomingState)
+				Expect(err).To(MatchError("--parent-zone and --dns-records are only supported on gcp, bbl does not create a route53 zone for the domain of aws lbs"))
+			})
+
+			It("returns an error when the records are invalid", func() {
+				recordsPath, err := testhelpers.WriteContentsToTempFile("[{name: www, type: A}]")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = command.Execute([]string{"--cert", "my-cert", "--key", "my-key", "--dns-records", recordsPath}, incomingState)
+				Expect(err).To(MatchError(`dns record "www" must have at least one value in records`))
+				Expect(gcpUpdateLBs.ExecuteCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when --aws-lb-flavor is provided", func() {
+			BeforeEach(func() {
+				incomingState.LBs = []storage.LBSpec{{Name: "vault"}}
+			})
+
+			It("migrates the named lb to the flavor without a certificate", func() {
+				err := command.Execute([]string{"--name", "vault", "--aws-lb-flavor", "nlb"}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(certificateValidator.ValidateCall.CallCount).To(Equal(0))
+				Expect(awsUpdateLBs.ExecuteCall.Receives.Config).To(Equal(commands.AWSCreateLBsConfig{
+					Spec:     storage.LBSpec{Name: "vault"},
+					LBFlavor: "nlb",
+				}))
+			})
+
+			It("migrates the unnamed lb into a new lb with the given name", func() {
+				err := command.Execute([]string{"--name", "ci", "--aws-lb-flavor", "nlb"}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(awsUpdateLBs.ExecuteCall.Receives.Config).To(Equal(commands.AWSCreateLBsConfig{
+					LBType:   "concourse",
+					Spec:     storage.LBSpec{Name: "ci", Type: "concourse"},
+					LBFlavor: "nlb",
+				}))
+			})
+
+			It("returns an error when the new name is not a valid lb name", func() {
+				err := command.Execute([]string{"--name", "concourse", "--aws-lb-flavor", "nlb"}, incomingState)
+				Expect(err).To(MatchError(`lb spec name "concourse" is reserved`))
+				Expect(awsUpdateLBs.ExecuteCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error without --name", func() {
+				err := command.Execute([]string{"--aws-lb-flavor", "nlb"}, incomingState)
+				Expect(err).To(MatchError("--aws-lb-flavor requires --name, the name of the lb to migrate or of the lb the unnamed classic elb migrates into"))
+			})
+
+			It("returns an error on environments created with --terraform", func() {
+				incomingState.TFState = "some-tf-state"
+
+				err := command.Execute([]string{"--name", "vault", "--aws-lb-flavor", "nlb"}, incomingState)
+				Expect(err).To(MatchError("--aws-lb-flavor is not supported on environments created with --terraform, their lbs are classic elbs"))
+			})
+
+			It("returns an error when the certificate of the unnamed lb is updated during its migration", func() {
+				incomingState.LBs = append(incomingState.LBs, storage.LBSpec{Name: "ci", Type: "concourse", Flavor: "nlb", CertificateName: "some-certificate-name"})
+
+				err := command.Execute([]string{"--cert", "my-cert", "--key", "my-key"}, incomingState)
+				Expect(err).To(MatchError("the unnamed lb is migrating into lb \"ci\", finish the migration with `bbl update-lbs --name ci --aws-lb-flavor nlb` first"))
+				Expect(awsUpdateLBs.ExecuteCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error on gcp", func() {
+				incomingState.IAAS = "gcp"
+
+				err := command.Execute([]string{"--name", "vault", "--aws-lb-flavor", "nlb"}, incomingState)
+				Expect(err).To(MatchError("--aws-lb-flavor is only supported on aws"))
+			})
+
+			It("returns an error when the flavor is invalid", func() {
+				err := command.Execute([]string{"--name", "vault", "--aws-lb-flavor", "clb"}, incomingState)
+				Expect(err).To(MatchError(`"clb" is not a valid aws lb flavor, valid flavors are: elb, alb and nlb`))
+			})
+		})
+
 		Describe("failure cases", func() {
 			It("returns an error when invalid flags are provided", func() {
 				err := command.Execute([]string{
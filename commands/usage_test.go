@@ -31,13 +31,17 @@ Usage:
   bbl [GLOBAL OPTIONS] COMMAND [OPTIONS]
 
 Global Options:
-  --help      [-h]       Prints usage
-  --state-dir            Directory containing bbl-state.json
-  --debug                Prints debugging output
+  --help      [-h]                Prints usage
+  --state-dir                     Directory containing bbl-state.json
+  --debug                         Prints debugging output
+  --output                        Output format for query commands: "text", "json" or "yaml" (Defaults to "text")
+  --skip-director-ssl-validation  Skips verifying the BOSH director certificate (insecure)
 
 Commands:
   bosh-deployment-vars   Prints required variables for BOSH deployment
+  certs                  Prints certificates and their expiry
   cloud-config           Prints suggested cloud configuration for BOSH environment
+  cost                   Estimates the monthly cost of the environment
   create-lbs             Attaches load balancer(s)
   delete-lbs             Deletes attached load balancer(s)
   destroy                Tears down BOSH director infrastructure
@@ -45,11 +49,15 @@ Commands:
   director-username      Prints BOSH director username
   director-password      Prints BOSH director password
   director-ca-cert       Prints BOSH director CA certificate
+  dns                    Prints the NS records delegating the lb dns zones (gcp only)
   env-id                 Prints environment ID
+  preflight              Checks that the credentials and project allow every action bbl needs
   print-env              Prints BOSH friendly environment variables
   help                   Prints usage
   lbs                    Prints attached load balancer(s)
+  outputs                Prints infrastructure outputs and BOSH director information
   ssh-key                Prints SSH private key
+  static-ips             Allocates static IPs from a generated network
   up                     Deploys BOSH director on AWS
   update-lbs             Updates load balancer(s)
   version                Prints version
@@ -66,9 +74,11 @@ Commands:
   bbl [GLOBAL OPTIONS] my-command [OPTIONS]
 
 Global Options:
-  --help      [-h]       Prints usage
-  --state-dir            Directory containing bbl-state.json
-  --debug                Prints debugging output
+  --help      [-h]                Prints usage
+  --state-dir                     Directory containing bbl-state.json
+  --debug                         Prints debugging output
+  --output                        Output format for query commands: "text", "json" or "yaml" (Defaults to "text")
+  --skip-director-ssl-validation  Skips verifying the BOSH director certificate (insecure)
 
 [my-command command options]
   some message
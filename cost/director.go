@@ -0,0 +1,130 @@
+package cost
+
+import (
+	"fmt"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	boshDeploymentAsset = "vendor/github.com/cloudfoundry/bosh-deployment/bosh.yml"
+	cpiOpsFileAsset     = "vendor/github.com/cloudfoundry/bosh-deployment/%s/cpi.yml"
+	directorCloudPath   = "/resource_pools/name=vms/cloud_properties?"
+)
+
+// directorSize is the vm type of the director along with the size of its
+// ephemeral or root disk and of its persistent disk.
+type directorSize struct {
+	vmType string
+	diskGB int
+}
+
+type directorManifest struct {
+	ResourcePools []struct {
+		Name            string                  `yaml:"name"`
+		CloudProperties directorCloudProperties `yaml:"cloud_properties"`
+	} `yaml:"resource_pools"`
+	DiskPools []struct {
+		DiskSize int `yaml:"disk_size"`
+	} `yaml:"disk_pools"`
+}
+
+type directorCloudProperties struct {
+	InstanceType  string `yaml:"instance_type"`
+	MachineType   string `yaml:"machine_type"`
+	EphemeralDisk struct {
+		Size int `yaml:"size"`
+	} `yaml:"ephemeral_disk"`
+	RootDiskSizeGB int `yaml:"root_disk_size_gb"`
+}
+
+type opsFileOperation struct {
+	Path  string      `yaml:"path"`
+	Value interface{} `yaml:"value"`
+}
+
+// deployedDirectorSize reads the size of the director from the manifest it
+// was deployed with.
+func deployedDirectorSize(manifest string) (directorSize, error) {
+	var deployed directorManifest
+	err := yaml.Unmarshal([]byte(manifest), &deployed)
+	if err != nil {
+		return directorSize{}, fmt.Errorf("failed to parse director manifest: %s", err)
+	}
+
+	var cloudProperties directorCloudProperties
+	for _, pool := range deployed.ResourcePools {
+		if pool.Name == "vms" {
+			cloudProperties = pool.CloudProperties
+		}
+	}
+
+	return newDirectorSize(cloudProperties, deployed), nil
+}
+
+// bundledDirectorSize reads the size of a new director from the bosh
+// manifest and the cpi ops file of the iaas that bbl deploys it with.
+func bundledDirectorSize(asset func(string) ([]byte, error), iaas string) (directorSize, error) {
+	contents, err := asset(boshDeploymentAsset)
+	if err != nil {
+		return directorSize{}, err
+	}
+
+	var manifest directorManifest
+	err = yaml.Unmarshal(contents, &manifest)
+	if err != nil {
+		return directorSize{}, fmt.Errorf("failed to parse director manifest: %s", err)
+	}
+
+	contents, err = asset(fmt.Sprintf(cpiOpsFileAsset, iaas))
+	if err != nil {
+		return directorSize{}, err
+	}
+
+	var operations []opsFileOperation
+	err = yaml.Unmarshal(contents, &operations)
+	if err != nil {
+		return directorSize{}, fmt.Errorf("failed to parse cpi ops file: %s", err)
+	}
+
+	var cloudProperties directorCloudProperties
+	for _, operation := range operations {
+		if operation.Path != directorCloudPath {
+			continue
+		}
+
+		value, err := yaml.Marshal(operation.Value)
+		if err != nil {
+			// not tested
+			return directorSize{}, err
+		}
+
+		err = yaml.Unmarshal(value, &cloudProperties)
+		if err != nil {
+			return directorSize{}, fmt.Errorf("failed to parse cpi ops file: %s", err)
+		}
+	}
+
+	return newDirectorSize(cloudProperties, manifest), nil
+}
+
+func newDirectorSize(cloudProperties directorCloudProperties, manifest directorManifest) directorSize {
+	size := directorSize{
+		vmType: cloudProperties.InstanceType,
+		diskGB: megabytesToGB(cloudProperties.EphemeralDisk.Size) + cloudProperties.RootDiskSizeGB,
+	}
+
+	if size.vmType == "" {
+		size.vmType = cloudProperties.MachineType
+	}
+
+	for _, pool := range manifest.DiskPools {
+		size.diskGB += megabytesToGB(pool.DiskSize)
+	}
+
+	return size
+}
+
+func megabytesToGB(megabytes int) int {
+	return (megabytes + 1023) / 1024
+}
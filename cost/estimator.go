@@ -0,0 +1,293 @@
+package cost
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	awsNATInstanceType = "t2.medium"
+
+	customLBType = "custom"
+)
+
+// Estimate is the monthly cost of the resources of an environment. Items
+// break the cost down per resource, LBs per type of load balancer.
+type Estimate struct {
+	IAAS       string   `json:"iaas" yaml:"iaas"`
+	Region     string   `json:"region" yaml:"region"`
+	PricesAsOf string   `json:"prices_as_of" yaml:"prices_as_of"`
+	Items      []Item   `json:"items" yaml:"items"`
+	LBs        []LBCost `json:"lbs,omitempty" yaml:"lbs,omitempty"`
+	Total      float64  `json:"total" yaml:"total"`
+}
+
+// Item is the monthly cost of Quantity resources of a kind, or of Quantity
+// GB for disks. LB names the load balancer the resources belong to, either
+// the type of the unnamed lb or the name of a named lb.
+type Item struct {
+	Resource string  `json:"resource" yaml:"resource"`
+	Type     string  `json:"type,omitempty" yaml:"type,omitempty"`
+	LB       string  `json:"lb,omitempty" yaml:"lb,omitempty"`
+	LBType   string  `json:"lb_type,omitempty" yaml:"lb_type,omitempty"`
+	Quantity int     `json:"quantity" yaml:"quantity"`
+	Monthly  float64 `json:"monthly" yaml:"monthly"`
+}
+
+// LBCost is the monthly cost of the load balancers of a type: cf,
+// concourse, or custom for the lbs created with create-lbs --spec.
+type LBCost struct {
+	Type    string  `json:"type" yaml:"type"`
+	Monthly float64 `json:"monthly" yaml:"monthly"`
+}
+
+type Estimator struct {
+	asset func(string) ([]byte, error)
+}
+
+// NewEstimator returns an estimator that sizes new directors after the
+// bosh-deployment files returned by asset.
+func NewEstimator(asset func(string) ([]byte, error)) Estimator {
+	return Estimator{
+		asset: asset,
+	}
+}
+
+// Estimate prices the resources bbl creates for the state. A state without
+// an env id describes an environment that up is about to create.
+func (e Estimator) Estimate(state storage.State, table PriceTable) (Estimate, error) {
+	var (
+		estimate Estimate
+		err      error
+	)
+
+	switch state.IAAS {
+	case "aws":
+		estimate, err = e.estimateAWS(state, table)
+	case "gcp":
+		estimate, err = e.estimateGCP(state, table)
+	default:
+		return Estimate{}, fmt.Errorf("invalid iaas: %q", state.IAAS)
+	}
+	if err != nil {
+		return Estimate{}, err
+	}
+
+	estimate.PricesAsOf = table.AsOf
+
+	lbCosts := map[string]int{}
+	for _, item := range estimate.Items {
+		estimate.Total += item.Monthly
+
+		if item.LBType == "" {
+			continue
+		}
+
+		i, ok := lbCosts[item.LBType]
+		if !ok {
+			i = len(estimate.LBs)
+			lbCosts[item.LBType] = i
+			estimate.LBs = append(estimate.LBs, LBCost{Type: item.LBType})
+		}
+		estimate.LBs[i].Monthly += item.Monthly
+	}
+
+	return estimate, nil
+}
+
+func (e Estimator) estimateAWS(state storage.State, table PriceTable) (Estimate, error) {
+	prices, ok := table.AWS[state.AWS.Region]
+	if !ok {
+		return Estimate{}, fmt.Errorf("no aws prices for region %q in the price table, add them with --price-table", state.AWS.Region)
+	}
+
+	hourly := func(price float64, quantity int) float64 {
+		return price * float64(quantity) * table.HoursPerMonth
+	}
+
+	instance := func(instanceType string) (float64, error) {
+		price, ok := prices.Instances[instanceType]
+		if !ok {
+			return 0, fmt.Errorf("no price for aws instance type %q in region %q in the price table, add it with --price-table", instanceType, state.AWS.Region)
+		}
+		return hourly(price, 1), nil
+	}
+
+	estimate := Estimate{IAAS: "aws", Region: state.AWS.Region}
+
+	if !state.NoDirector {
+		size, err := e.directorSize(state)
+		if err != nil {
+			return Estimate{}, err
+		}
+
+		monthly, err := instance(size.vmType)
+		if err != nil {
+			return Estimate{}, err
+		}
+
+		estimate.Items = append(estimate.Items,
+			Item{Resource: "director vm", Type: size.vmType, Quantity: 1, Monthly: monthly},
+			Item{Resource: "director disks", Type: "gp2", Quantity: size.diskGB, Monthly: prices.GP2GBMonth * float64(size.diskGB)},
+		)
+	}
+
+	monthly, err := instance(awsNATInstanceType)
+	if err != nil {
+		return Estimate{}, err
+	}
+
+	estimate.Items = append(estimate.Items,
+		Item{Resource: "nat instance", Type: awsNATInstanceType, Quantity: 1, Monthly: monthly},
+		Item{Resource: "elastic ip", Quantity: 2, Monthly: hourly(prices.ElasticIP, 2)},
+	)
+
+	lbPrice := func(flavor string) (string, float64) {
+		switch flavor {
+		case "alb":
+			return "alb", prices.ALB
+		case "nlb":
+			return "nlb", prices.NLB
+		default:
+			return "elb", prices.ELB
+		}
+	}
+
+	// Terraform gives cf a tcp router elb and a dns zone on top of the
+	// router and ssh proxy elbs of cloudformation.
+	terraform := state.TFState != "" || state.LB.Type != ""
+	lbType := state.Stack.LBType
+	if terraform {
+		lbType = state.LB.Type
+	}
+
+	switch lbType {
+	case "concourse":
+		estimate.Items = append(estimate.Items, Item{Resource: "elb", LB: lbType, LBType: lbType, Quantity: 1, Monthly: hourly(prices.ELB, 1)})
+	case "cf":
+		elbs := 2
+		if terraform {
+			elbs = 3
+		}
+		estimate.Items = append(estimate.Items, Item{Resource: "elb", LB: lbType, LBType: lbType, Quantity: elbs, Monthly: hourly(prices.ELB, elbs)})
+
+		if terraform && state.LB.Domain != "" {
+			estimate.Items = append(estimate.Items, Item{Resource: "hosted zone", LB: lbType, LBType: lbType, Quantity: 1, Monthly: prices.HostedZoneMonth})
+		}
+	}
+
+	for _, spec := range state.LBs {
+		specLBType := spec.Type
+		if specLBType == "" {
+			specLBType = customLBType
+		}
+
+		for _, expanded := range templates.ExpandLBSpecs([]storage.LBSpec{spec}) {
+			flavors := []string{expanded.Flavor}
+			if expanded.PreviousFlavor != "" && expanded.PreviousFlavor != expanded.Flavor {
+				flavors = append(flavors, expanded.PreviousFlavor)
+			}
+
+			for _, flavor := range flavors {
+				resource, price := lbPrice(flavor)
+				estimate.Items = append(estimate.Items, Item{Resource: resource, LB: spec.Name, LBType: specLBType, Quantity: 1, Monthly: hourly(price, 1)})
+			}
+		}
+	}
+
+	return estimate, nil
+}
+
+func (e Estimator) estimateGCP(state storage.State, table PriceTable) (Estimate, error) {
+	prices, ok := table.GCP[state.GCP.Region]
+	if !ok {
+		return Estimate{}, fmt.Errorf("no gcp prices for region %q in the price table, add them with --price-table", state.GCP.Region)
+	}
+
+	hourly := func(price float64, quantity int) float64 {
+		return price * float64(quantity) * table.HoursPerMonth
+	}
+
+	estimate := Estimate{IAAS: "gcp", Region: state.GCP.Region}
+
+	if !state.NoDirector {
+		size, err := e.directorSize(state)
+		if err != nil {
+			return Estimate{}, err
+		}
+
+		price, ok := prices.MachineTypes[size.vmType]
+		if !ok {
+			return Estimate{}, fmt.Errorf("no price for gcp machine type %q in region %q in the price table, add it with --price-table", size.vmType, state.GCP.Region)
+		}
+
+		estimate.Items = append(estimate.Items,
+			Item{Resource: "director vm", Type: size.vmType, Quantity: 1, Monthly: hourly(price, 1)},
+			Item{Resource: "director disks", Type: "pd-standard", Quantity: size.diskGB, Monthly: prices.PDStandardGBMonth * float64(size.diskGB)},
+		)
+	}
+
+	estimate.Items = append(estimate.Items, Item{Resource: "address", Quantity: 1, Monthly: hourly(prices.Address, 1)})
+
+	var forwardingRules []int
+	addLB := func(lb, lbType string, addresses, rules int, domain string) {
+		estimate.Items = append(estimate.Items, Item{Resource: "address", LB: lb, LBType: lbType, Quantity: addresses, Monthly: hourly(prices.Address, addresses)})
+
+		forwardingRules = append(forwardingRules, len(estimate.Items))
+		estimate.Items = append(estimate.Items, Item{Resource: "forwarding rule", LB: lb, LBType: lbType, Quantity: rules})
+
+		if domain != "" {
+			estimate.Items = append(estimate.Items, Item{Resource: "managed zone", LB: lb, LBType: lbType, Quantity: 1, Monthly: prices.ManagedZoneMonth})
+		}
+	}
+
+	// A cf lb has a global address for http(s) and regional addresses for
+	// the ssh proxy, the tcp router and websockets.
+	switch state.LB.Type {
+	case "concourse":
+		addLB(state.LB.Type, state.LB.Type, 1, 2, "")
+	case "cf":
+		addLB(state.LB.Type, state.LB.Type, 4, 7, state.LB.Domain)
+	}
+
+	for _, spec := range state.LBs {
+		switch spec.Type {
+		case "cf":
+			addLB(spec.Name, spec.Type, 4, 7, spec.Domain)
+		case "concourse":
+			addLB(spec.Name, spec.Type, 1, 2, "")
+		default:
+			addLB(spec.Name, customLBType, 1, len(spec.Ports), "")
+		}
+	}
+
+	// The first five forwarding rules are billed together, their cost is
+	// shared by the lbs according to their number of rules.
+	totalRules := 0
+	for _, i := range forwardingRules {
+		totalRules += estimate.Items[i].Quantity
+	}
+
+	if totalRules > 0 {
+		rulesMonthly := hourly(prices.ForwardingRules, 1)
+		if totalRules > 5 {
+			rulesMonthly += hourly(prices.AdditionalForwardingRule, totalRules-5)
+		}
+
+		for _, i := range forwardingRules {
+			estimate.Items[i].Monthly = rulesMonthly * float64(estimate.Items[i].Quantity) / float64(totalRules)
+		}
+	}
+
+	return estimate, nil
+}
+
+func (e Estimator) directorSize(state storage.State) (directorSize, error) {
+	if state.BOSH.Manifest != "" {
+		return deployedDirectorSize(state.BOSH.Manifest)
+	}
+
+	return bundledDirectorSize(e.asset, state.IAAS)
+}
@@ -0,0 +1,338 @@
+package cost_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/cost"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	boshManifest = `name: bosh
+disk_pools:
+- name: disks
+  disk_size: 32_768
+`
+
+	awsCPIOpsFile = `- type: replace
+  path: /releases/-
+  value:
+    name: bosh-aws-cpi
+- type: replace
+  path: /resource_pools/name=vms/cloud_properties?
+  value:
+    instance_type: m4.xlarge
+    ephemeral_disk: {size: 25_000, type: gp2}
+    availability_zone: ((az))
+`
+
+	gcpCPIOpsFile = `- type: replace
+  path: /resource_pools/name=vms/cloud_properties?
+  value:
+    zone: ((zone))
+    machine_type: n1-standard-1
+    root_disk_size_gb: 40
+    root_disk_type: pd-standard
+`
+)
+
+var _ = Describe("Estimator", func() {
+	var (
+		assets    map[string]string
+		estimator cost.Estimator
+		table     cost.PriceTable
+	)
+
+	BeforeEach(func() {
+		assets = map[string]string{
+			"vendor/github.com/cloudfoundry/bosh-deployment/bosh.yml":    boshManifest,
+			"vendor/github.com/cloudfoundry/bosh-deployment/aws/cpi.yml": awsCPIOpsFile,
+			"vendor/github.com/cloudfoundry/bosh-deployment/gcp/cpi.yml": gcpCPIOpsFile,
+		}
+
+		estimator = cost.NewEstimator(func(name string) ([]byte, error) {
+			contents, ok := assets[name]
+			if !ok {
+				return nil, errors.New("asset not found: " + name)
+			}
+			return []byte(contents), nil
+		})
+
+		table = cost.PriceTable{
+			AsOf:          "2025-01",
+			HoursPerMonth: 100,
+			AWS: map[string]cost.AWSPrices{
+				"us-east-1": {
+					Instances: map[string]float64{
+						"m4.xlarge": 0.25,
+						"m4.large":  0.125,
+						"t2.medium": 0.0625,
+					},
+					GP2GBMonth:      0.125,
+					ElasticIP:       0.015625,
+					ELB:             0.03125,
+					ALB:             0.0625,
+					NLB:             0.125,
+					HostedZoneMonth: 0.5,
+				},
+			},
+			GCP: map[string]cost.GCPPrices{
+				"us-central1": {
+					MachineTypes: map[string]float64{
+						"n1-standard-1": 0.0625,
+					},
+					PDStandardGBMonth:        0.125,
+					Address:                  0.0078125,
+					ForwardingRules:          0.25,
+					AdditionalForwardingRule: 0.0625,
+					ManagedZoneMonth:         0.25,
+				},
+			},
+		}
+	})
+
+	Describe("Estimate", func() {
+		Context("on aws", func() {
+			var state storage.State
+
+			BeforeEach(func() {
+				state = storage.State{
+					IAAS: "aws",
+					AWS:  storage.AWS{Region: "us-east-1"},
+				}
+			})
+
+			It("prices the director sized by the bundled bosh-deployment files, the nat instance and the elastic ips", func() {
+				estimate, err := estimator.Estimate(state, table)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(estimate).To(Equal(cost.Estimate{
+					IAAS:       "aws",
+					Region:     "us-east-1",
+					PricesAsOf: "2025-01",
+					Items: []cost.Item{
+						{Resource: "director vm", Type: "m4.xlarge", Quantity: 1, Monthly: 25},
+						{Resource: "director disks", Type: "gp2", Quantity: 57, Monthly: 7.125},
+						{Resource: "nat instance", Type: "t2.medium", Quantity: 1, Monthly: 6.25},
+						{Resource: "elastic ip", Quantity: 2, Monthly: 3.125},
+					},
+					Total: 41.5,
+				}))
+			})
+
+			It("sizes a deployed director after its manifest", func() {
+				state.BOSH.Manifest = `resource_pools:
+- name: vms
+  cloud_properties:
+    instance_type: m4.large
+    ephemeral_disk: {size: 10_240, type: gp2}
+disk_pools:
+- name: disks
+  disk_size: 65_536
+`
+
+				estimate, err := estimator.Estimate(state, table)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(estimate.Items[:2]).To(Equal([]cost.Item{
+					{Resource: "director vm", Type: "m4.large", Quantity: 1, Monthly: 12.5},
+					{Resource: "director disks", Type: "gp2", Quantity: 74, Monthly: 9.25},
+				}))
+			})
+
+			It("skips the director with --no-director", func() {
+				state.NoDirector = true
+
+				estimate, err := estimator.Estimate(state, table)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(estimate.Items).To(Equal([]cost.Item{
+					{Resource: "nat instance", Type: "t2.medium", Quantity: 1, Monthly: 6.25},
+					{Resource: "elastic ip", Quantity: 2, Monthly: 3.125},
+				}))
+				Expect(estimate.Total).To(Equal(9.375))
+			})
+
+			It("prices the router and ssh proxy elbs of a cloudformation cf lb", func() {
+				state.NoDirector = true
+				state.Stack.LBType = "cf"
+
+				estimate, err := estimator.Estimate(state, table)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(estimate.Items[2:]).To(Equal([]cost.Item{
+					{Resource: "elb", LB: "cf", LBType: "cf", Quantity: 2, Monthly: 6.25},
+				}))
+				Expect(estimate.LBs).To(Equal([]cost.LBCost{{Type: "cf", Monthly: 6.25}}))
+			})
+
+			It("prices the tcp router elb and the hosted zone of a terraform cf lb", func() {
+				state.NoDirector = true
+				state.TFState = "some-tf-state"
+				state.LB = storage.LB{Type: "cf", Domain: "cf.example.com"}
+
+				estimate, err := estimator.Estimate(state, table)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(estimate.Items[2:]).To(Equal([]cost.Item{
+					{Resource: "elb", LB: "cf", LBType: "cf", Quantity: 3, Monthly: 9.375},
+					{Resource: "hosted zone", LB: "cf", LBType: "cf", Quantity: 1, Monthly: 0.5},
+				}))
+				Expect(estimate.LBs).To(Equal([]cost.LBCost{{Type: "cf", Monthly: 9.875}}))
+			})
+
+			It("prices named lbs by flavor and type, including a previous flavor still attached", func() {
+				state.NoDirector = true
+				state.Stack.LBType = "concourse"
+				state.LBs = []storage.LBSpec{
+					{Name: "foundation", Type: "cf", Flavor: "alb"},
+					{Name: "grpc", Flavor: "nlb", PreviousFlavor: "elb", Ports: []storage.LBPort{{Port: 8443, InstancePort: 8443, Protocol: "tcp"}}},
+				}
+
+				estimate, err := estimator.Estimate(state, table)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(estimate.Items[2:]).To(Equal([]cost.Item{
+					{Resource: "elb", LB: "concourse", LBType: "concourse", Quantity: 1, Monthly: 3.125},
+					{Resource: "alb", LB: "foundation", LBType: "cf", Quantity: 1, Monthly: 6.25},
+					{Resource: "nlb", LB: "foundation", LBType: "cf", Quantity: 1, Monthly: 12.5},
+					{Resource: "nlb", LB: "grpc", LBType: "custom", Quantity: 1, Monthly: 12.5},
+					{Resource: "elb", LB: "grpc", LBType: "custom", Quantity: 1, Monthly: 3.125},
+				}))
+				Expect(estimate.LBs).To(Equal([]cost.LBCost{
+					{Type: "concourse", Monthly: 3.125},
+					{Type: "cf", Monthly: 18.75},
+					{Type: "custom", Monthly: 15.625},
+				}))
+				Expect(estimate.Total).To(Equal(46.875))
+			})
+
+			Context("failure cases", func() {
+				It("returns an error when the region is missing from the price table", func() {
+					state.AWS.Region = "sa-east-1"
+
+					_, err := estimator.Estimate(state, table)
+					Expect(err).To(MatchError(`no aws prices for region "sa-east-1" in the price table, add them with --price-table`))
+				})
+
+				It("returns an error when the instance type of the director is missing from the price table", func() {
+					delete(table.AWS["us-east-1"].Instances, "m4.xlarge")
+
+					_, err := estimator.Estimate(state, table)
+					Expect(err).To(MatchError(`no price for aws instance type "m4.xlarge" in region "us-east-1" in the price table, add it with --price-table`))
+				})
+
+				It("returns an error when a bosh-deployment file cannot be read", func() {
+					delete(assets, "vendor/github.com/cloudfoundry/bosh-deployment/aws/cpi.yml")
+
+					_, err := estimator.Estimate(state, table)
+					Expect(err).To(MatchError("asset not found: vendor/github.com/cloudfoundry/bosh-deployment/aws/cpi.yml"))
+				})
+
+				It("returns an error when the cpi ops file cannot be parsed", func() {
+					assets["vendor/github.com/cloudfoundry/bosh-deployment/aws/cpi.yml"] = "%%%"
+
+					_, err := estimator.Estimate(state, table)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse cpi ops file:")))
+				})
+
+				It("returns an error when the director manifest cannot be parsed", func() {
+					state.BOSH.Manifest = "%%%"
+
+					_, err := estimator.Estimate(state, table)
+					Expect(err).To(MatchError(ContainSubstring("failed to parse director manifest:")))
+				})
+			})
+		})
+
+		Context("on gcp", func() {
+			var state storage.State
+
+			BeforeEach(func() {
+				state = storage.State{
+					IAAS: "gcp",
+					GCP:  storage.GCP{Region: "us-central1"},
+				}
+			})
+
+			It("prices the director sized by the bundled bosh-deployment files and its address", func() {
+				estimate, err := estimator.Estimate(state, table)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(estimate).To(Equal(cost.Estimate{
+					IAAS:       "gcp",
+					Region:     "us-central1",
+					PricesAsOf: "2025-01",
+					Items: []cost.Item{
+						{Resource: "director vm", Type: "n1-standard-1", Quantity: 1, Monthly: 6.25},
+						{Resource: "director disks", Type: "pd-standard", Quantity: 72, Monthly: 9},
+						{Resource: "address", Quantity: 1, Monthly: 0.78125},
+					},
+					Total: 16.03125,
+				}))
+			})
+
+			It("prices the forwarding rules of the lbs together and shares them by number of rules", func() {
+				state.NoDirector = true
+				state.LB = storage.LB{Type: "cf", Domain: "cf.example.com"}
+				state.LBs = []storage.LBSpec{
+					{Name: "ci", Type: "concourse"},
+					{Name: "grpc", Ports: []storage.LBPort{{Port: 8443}}},
+				}
+
+				estimate, err := estimator.Estimate(state, table)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(estimate.Items).To(Equal([]cost.Item{
+					{Resource: "address", Quantity: 1, Monthly: 0.78125},
+					{Resource: "address", LB: "cf", LBType: "cf", Quantity: 4, Monthly: 3.125},
+					{Resource: "forwarding rule", LB: "cf", LBType: "cf", Quantity: 7, Monthly: 39.375},
+					{Resource: "managed zone", LB: "cf", LBType: "cf", Quantity: 1, Monthly: 0.25},
+					{Resource: "address", LB: "ci", LBType: "concourse", Quantity: 1, Monthly: 0.78125},
+					{Resource: "forwarding rule", LB: "ci", LBType: "concourse", Quantity: 2, Monthly: 11.25},
+					{Resource: "address", LB: "grpc", LBType: "custom", Quantity: 1, Monthly: 0.78125},
+					{Resource: "forwarding rule", LB: "grpc", LBType: "custom", Quantity: 1, Monthly: 5.625},
+				}))
+				Expect(estimate.LBs).To(Equal([]cost.LBCost{
+					{Type: "cf", Monthly: 42.75},
+					{Type: "concourse", Monthly: 12.03125},
+					{Type: "custom", Monthly: 6.40625},
+				}))
+			})
+
+			It("prices the first five forwarding rules together", func() {
+				state.NoDirector = true
+				state.LB = storage.LB{Type: "concourse"}
+
+				estimate, err := estimator.Estimate(state, table)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(estimate.Items[2]).To(Equal(cost.Item{Resource: "forwarding rule", LB: "concourse", LBType: "concourse", Quantity: 2, Monthly: 25}))
+			})
+
+			Context("failure cases", func() {
+				It("returns an error when the region is missing from the price table", func() {
+					state.GCP.Region = "europe-north1"
+
+					_, err := estimator.Estimate(state, table)
+					Expect(err).To(MatchError(`no gcp prices for region "europe-north1" in the price table, add them with --price-table`))
+				})
+
+				It("returns an error when the machine type of the director is missing from the price table", func() {
+					delete(table.GCP["us-central1"].MachineTypes, "n1-standard-1")
+
+					_, err := estimator.Estimate(state, table)
+					Expect(err).To(MatchError(`no price for gcp machine type "n1-standard-1" in region "us-central1" in the price table, add it with --price-table`))
+				})
+			})
+		})
+
+		It("returns an error for an invalid iaas", func() {
+			_, err := estimator.Estimate(storage.State{IAAS: "azure"}, table)
+			Expect(err).To(MatchError(`invalid iaas: "azure"`))
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package cost_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCost(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "cost")
+}
@@ -0,0 +1,243 @@
+package cost
+
+import (
+	"errors"
+	"fmt"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// PriceTable holds the on-demand prices, in US dollars, of the resources
+// bbl creates in each region. Prices are hourly unless their name says
+// otherwise, and monthly costs assume HoursPerMonth hours.
+type PriceTable struct {
+	AsOf          string               `yaml:"as_of"`
+	HoursPerMonth float64              `yaml:"hours_per_month"`
+	AWS           map[string]AWSPrices `yaml:"aws"`
+	GCP           map[string]GCPPrices `yaml:"gcp"`
+}
+
+type AWSPrices struct {
+	Instances       map[string]float64 `yaml:"instances"`
+	GP2GBMonth      float64            `yaml:"gp2_gb_month"`
+	ElasticIP       float64            `yaml:"elastic_ip"`
+	ELB             float64            `yaml:"elb"`
+	ALB             float64            `yaml:"alb"`
+	NLB             float64            `yaml:"nlb"`
+	HostedZoneMonth float64            `yaml:"hosted_zone_month"`
+}
+
+// GCPPrices prices forwarding rules the way gcp bills them: the first five
+// rules of a project cost ForwardingRules together, every other rule costs
+// AdditionalForwardingRule.
+type GCPPrices struct {
+	MachineTypes             map[string]float64 `yaml:"machine_types"`
+	PDStandardGBMonth        float64            `yaml:"pd_standard_gb_month"`
+	Address                  float64            `yaml:"address"`
+	ForwardingRules          float64            `yaml:"forwarding_rules"`
+	AdditionalForwardingRule float64            `yaml:"additional_forwarding_rule"`
+	ManagedZoneMonth         float64            `yaml:"managed_zone_month"`
+}
+
+// ParsePriceTable reads a price table in the format of DefaultPriceTable.
+func ParsePriceTable(contents string) (PriceTable, error) {
+	var table PriceTable
+	err := yaml.Unmarshal([]byte(contents), &table)
+	if err != nil {
+		return PriceTable{}, fmt.Errorf("failed to parse price table: %s", err)
+	}
+
+	if len(table.AWS) == 0 && len(table.GCP) == 0 {
+		return PriceTable{}, errors.New("price table has no aws or gcp regions")
+	}
+
+	return table, nil
+}
+
+// Merge returns the table with the prices of other added to it. A region of
+// other updates the region of the table with the same name price by price,
+// so that a partial table updates the bundled one. Prices missing from other,
+// or zero, keep the price of the table.
+func (t PriceTable) Merge(other PriceTable) PriceTable {
+	merged := PriceTable{
+		AsOf:          t.AsOf,
+		HoursPerMonth: t.HoursPerMonth,
+		AWS:           map[string]AWSPrices{},
+		GCP:           map[string]GCPPrices{},
+	}
+
+	if other.AsOf != "" {
+		merged.AsOf = other.AsOf
+	}
+
+	if other.HoursPerMonth != 0 {
+		merged.HoursPerMonth = other.HoursPerMonth
+	}
+
+	for _, aws := range []map[string]AWSPrices{t.AWS, other.AWS} {
+		for region, prices := range aws {
+			merged.AWS[region] = merged.AWS[region].merge(prices)
+		}
+	}
+
+	for _, gcp := range []map[string]GCPPrices{t.GCP, other.GCP} {
+		for region, prices := range gcp {
+			merged.GCP[region] = merged.GCP[region].merge(prices)
+		}
+	}
+
+	return merged
+}
+
+func (p AWSPrices) merge(other AWSPrices) AWSPrices {
+	return AWSPrices{
+		Instances:       mergePrices(p.Instances, other.Instances),
+		GP2GBMonth:      mergePrice(p.GP2GBMonth, other.GP2GBMonth),
+		ElasticIP:       mergePrice(p.ElasticIP, other.ElasticIP),
+		ELB:             mergePrice(p.ELB, other.ELB),
+		ALB:             mergePrice(p.ALB, other.ALB),
+		NLB:             mergePrice(p.NLB, other.NLB),
+		HostedZoneMonth: mergePrice(p.HostedZoneMonth, other.HostedZoneMonth),
+	}
+}
+
+func (p GCPPrices) merge(other GCPPrices) GCPPrices {
+	return GCPPrices{
+		MachineTypes:             mergePrices(p.MachineTypes, other.MachineTypes),
+		PDStandardGBMonth:        mergePrice(p.PDStandardGBMonth, other.PDStandardGBMonth),
+		Address:                  mergePrice(p.Address, other.Address),
+		ForwardingRules:          mergePrice(p.ForwardingRules, other.ForwardingRules),
+		AdditionalForwardingRule: mergePrice(p.AdditionalForwardingRule, other.AdditionalForwardingRule),
+		ManagedZoneMonth:         mergePrice(p.ManagedZoneMonth, other.ManagedZoneMonth),
+	}
+}
+
+func mergePrices(prices, other map[string]float64) map[string]float64 {
+	merged := map[string]float64{}
+	for _, m := range []map[string]float64{prices, other} {
+		for name, price := range m {
+			merged[name] = mergePrice(merged[name], price)
+		}
+	}
+
+	return merged
+}
+
+func mergePrice(price, other float64) float64 {
+	if other != 0 {
+		return other
+	}
+
+	return price
+}
+
+// DefaultPriceTable is the price table bundled with bbl. It lists the
+// resources bbl creates in the most used regions, --price-table adds other
+// regions or newer prices.
+const DefaultPriceTable = `as_of: "2024-06"
+hours_per_month: 730
+
+aws:
+  us-east-1: &aws-us-east
+    instances:
+      t2.medium: 0.0464
+      m4.large: 0.10
+      m4.xlarge: 0.20
+    gp2_gb_month: 0.10
+    elastic_ip: 0.005
+    elb: 0.025
+    alb: 0.0225
+    nlb: 0.0225
+    hosted_zone_month: 0.50
+  us-east-2: *aws-us-east
+  us-west-2: *aws-us-east
+  us-west-1:
+    instances:
+      t2.medium: 0.0552
+      m4.large: 0.117
+      m4.xlarge: 0.234
+    gp2_gb_month: 0.12
+    elastic_ip: 0.005
+    elb: 0.028
+    alb: 0.0252
+    nlb: 0.0252
+    hosted_zone_month: 0.50
+  eu-west-1:
+    instances:
+      t2.medium: 0.05
+      m4.large: 0.111
+      m4.xlarge: 0.222
+    gp2_gb_month: 0.11
+    elastic_ip: 0.005
+    elb: 0.028
+    alb: 0.0252
+    nlb: 0.0252
+    hosted_zone_month: 0.50
+  eu-central-1:
+    instances:
+      t2.medium: 0.0536
+      m4.large: 0.12
+      m4.xlarge: 0.24
+    gp2_gb_month: 0.119
+    elastic_ip: 0.005
+    elb: 0.027
+    alb: 0.027
+    nlb: 0.027
+    hosted_zone_month: 0.50
+  ap-southeast-1:
+    instances:
+      t2.medium: 0.0584
+      m4.large: 0.125
+      m4.xlarge: 0.25
+    gp2_gb_month: 0.12
+    elastic_ip: 0.005
+    elb: 0.028
+    alb: 0.0252
+    nlb: 0.0252
+    hosted_zone_month: 0.50
+  ap-northeast-1:
+    instances:
+      t2.medium: 0.0608
+      m4.large: 0.129
+      m4.xlarge: 0.258
+    gp2_gb_month: 0.12
+    elastic_ip: 0.005
+    elb: 0.027
+    alb: 0.0243
+    nlb: 0.0243
+    hosted_zone_month: 0.50
+
+gcp:
+  us-central1: &gcp-us
+    machine_types:
+      n1-standard-1: 0.0475
+      n1-standard-2: 0.095
+      n1-standard-4: 0.19
+    pd_standard_gb_month: 0.04
+    address: 0.005
+    forwarding_rules: 0.025
+    additional_forwarding_rule: 0.01
+    managed_zone_month: 0.20
+  us-east1: *gcp-us
+  us-west1: *gcp-us
+  europe-west1:
+    machine_types:
+      n1-standard-1: 0.0523
+      n1-standard-2: 0.1046
+      n1-standard-4: 0.2092
+    pd_standard_gb_month: 0.04
+    address: 0.005
+    forwarding_rules: 0.025
+    additional_forwarding_rule: 0.01
+    managed_zone_month: 0.20
+  asia-east1:
+    machine_types:
+      n1-standard-1: 0.055
+      n1-standard-2: 0.11
+      n1-standard-4: 0.22
+    pd_standard_gb_month: 0.04
+    address: 0.005
+    forwarding_rules: 0.025
+    additional_forwarding_rule: 0.01
+    managed_zone_month: 0.20
+`
@@ -0,0 +1,117 @@
+package cost_test
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/cost"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PriceTable", func() {
+	Describe("ParsePriceTable", func() {
+		It("parses the bundled price table", func() {
+			table, err := cost.ParsePriceTable(cost.DefaultPriceTable)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(table.AsOf).To(Equal("2024-06"))
+			Expect(table.HoursPerMonth).To(Equal(730.0))
+			Expect(table.AWS["us-east-1"].Instances).To(HaveKeyWithValue("m4.xlarge", 0.20))
+			Expect(table.AWS["us-west-2"]).To(Equal(table.AWS["us-east-1"]))
+			Expect(table.GCP["us-central1"].MachineTypes).To(HaveKeyWithValue("n1-standard-1", 0.0475))
+			Expect(table.GCP["us-east1"]).To(Equal(table.GCP["us-central1"]))
+		})
+
+		It("prices the instance types bbl creates in every bundled region", func() {
+			table, err := cost.ParsePriceTable(cost.DefaultPriceTable)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, prices := range table.AWS {
+				Expect(prices.Instances).To(HaveKey("t2.medium"))
+				Expect(prices.Instances).To(HaveKey("m4.xlarge"))
+			}
+
+			for _, prices := range table.GCP {
+				Expect(prices.MachineTypes).To(HaveKey("n1-standard-1"))
+			}
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the table is not valid yaml", func() {
+				_, err := cost.ParsePriceTable("%%%")
+				Expect(err).To(MatchError(ContainSubstring("failed to parse price table:")))
+			})
+
+			It("returns an error when the table has no regions", func() {
+				_, err := cost.ParsePriceTable("as_of: 2025-01")
+				Expect(err).To(MatchError("price table has no aws or gcp regions"))
+			})
+		})
+	})
+
+	Describe("Merge", func() {
+		var table cost.PriceTable
+
+		BeforeEach(func() {
+			table = cost.PriceTable{
+				AsOf:          "2024-06",
+				HoursPerMonth: 730,
+				AWS: map[string]cost.AWSPrices{
+					"us-east-1": {Instances: map[string]float64{"m4.large": 0.10, "t2.medium": 0.0464}, ELB: 0.025, ElasticIP: 0.005},
+					"eu-west-1": {Instances: map[string]float64{"m4.large": 0.111}, ELB: 0.028},
+				},
+				GCP: map[string]cost.GCPPrices{
+					"us-central1": {MachineTypes: map[string]float64{"n1-standard-1": 0.0475}, Address: 0.005, ForwardingRules: 0.025},
+				},
+			}
+		})
+
+		It("adds the regions of the other table", func() {
+			merged := table.Merge(cost.PriceTable{
+				AsOf: "2025-01",
+				AWS: map[string]cost.AWSPrices{
+					"sa-east-1": {Instances: map[string]float64{"m4.large": 0.159}, ELB: 0.034},
+				},
+			})
+
+			Expect(merged.AsOf).To(Equal("2025-01"))
+			Expect(merged.HoursPerMonth).To(Equal(730.0))
+			Expect(merged.AWS).To(HaveLen(3))
+			Expect(merged.AWS["sa-east-1"]).To(Equal(cost.AWSPrices{Instances: map[string]float64{"m4.large": 0.159}, ELB: 0.034}))
+			Expect(merged.AWS["eu-west-1"]).To(Equal(table.AWS["eu-west-1"]))
+			Expect(merged.GCP).To(Equal(table.GCP))
+		})
+
+		It("updates the regions of the table price by price", func() {
+			merged := table.Merge(cost.PriceTable{
+				AWS: map[string]cost.AWSPrices{
+					"us-east-1": {Instances: map[string]float64{"m4.large": 0.096, "m5.large": 0.096}, ELB: 0.03},
+				},
+				GCP: map[string]cost.GCPPrices{
+					"us-central1": {Address: 0.004},
+				},
+			})
+
+			Expect(merged.AWS["us-east-1"]).To(Equal(cost.AWSPrices{
+				Instances: map[string]float64{"m4.large": 0.096, "m5.large": 0.096, "t2.medium": 0.0464},
+				ELB:       0.03,
+				ElasticIP: 0.005,
+			}))
+			Expect(merged.GCP["us-central1"]).To(Equal(cost.GCPPrices{
+				MachineTypes:    map[string]float64{"n1-standard-1": 0.0475},
+				Address:         0.004,
+				ForwardingRules: 0.025,
+			}))
+		})
+
+		It("does not modify the table", func() {
+			table.Merge(cost.PriceTable{
+				AWS: map[string]cost.AWSPrices{
+					"us-east-1": {Instances: map[string]float64{"m4.large": 0.096}, ELB: 0.03},
+				},
+			})
+
+			Expect(table.AWS["us-east-1"].ELB).To(Equal(0.025))
+			Expect(table.AWS["us-east-1"].Instances["m4.large"]).To(Equal(0.10))
+		})
+	})
+})
@@ -0,0 +1,119 @@
+package fakes
+
+import "github.com/aws/aws-sdk-go/service/acm"
+
+type ACMClient struct {
+	ImportCertificateCall struct {
+		CallCount int
+		Receives  struct {
+			Input *acm.ImportCertificateInput
+		}
+		Returns struct {
+			Output *acm.ImportCertificateOutput
+			Error  error
+		}
+	}
+
+	DescribeCertificateCall struct {
+		CallCount int
+		Receives  struct {
+			Input *acm.DescribeCertificateInput
+		}
+		Returns struct {
+			Output *acm.DescribeCertificateOutput
+			Error  error
+		}
+	}
+
+	GetCertificateCall struct {
+		CallCount int
+		Receives  struct {
+			Input *acm.GetCertificateInput
+		}
+		Returns struct {
+			Output *acm.GetCertificateOutput
+			Error  error
+		}
+	}
+
+	DeleteCertificateCall struct {
+		CallCount int
+		Receives  struct {
+			Input *acm.DeleteCertificateInput
+		}
+		Returns struct {
+			Output *acm.DeleteCertificateOutput
+			Error  error
+		}
+	}
+
+	ListCertificatesCall struct {
+		CallCount int
+		Receives  struct {
+			Inputs []*acm.ListCertificatesInput
+		}
+		Returns struct {
+			Outputs []*acm.ListCertificatesOutput
+			Error   error
+		}
+	}
+
+	ListTagsForCertificateCall struct {
+		CallCount int
+		Receives  struct {
+			Inputs []*acm.ListTagsForCertificateInput
+		}
+		Returns struct {
+			Outputs []*acm.ListTagsForCertificateOutput
+			Error   error
+		}
+	}
+}
+
+func (c *ACMClient) ImportCertificate(input *acm.ImportCertificateInput) (*acm.ImportCertificateOutput, error) {
+	c.ImportCertificateCall.CallCount++
+	c.ImportCertificateCall.Receives.Input = input
+
+	return c.ImportCertificateCall.Returns.Output, c.ImportCertificateCall.Returns.Error
+}
+
+func (c *ACMClient) DescribeCertificate(input *acm.DescribeCertificateInput) (*acm.DescribeCertificateOutput, error) {
+	c.DescribeCertificateCall.CallCount++
+	c.DescribeCertificateCall.Receives.Input = input
+
+	return c.DescribeCertificateCall.Returns.Output, c.DescribeCertificateCall.Returns.Error
+}
+
+func (c *ACMClient) GetCertificate(input *acm.GetCertificateInput) (*acm.GetCertificateOutput, error) {
+	c.GetCertificateCall.CallCount++
+	c.GetCertificateCall.Receives.Input = input
+
+	return c.GetCertificateCall.Returns.Output, c.GetCertificateCall.Returns.Error
+}
+
+func (c *ACMClient) DeleteCertificate(input *acm.DeleteCertificateInput) (*acm.DeleteCertificateOutput, error) {
+	c.DeleteCertificateCall.CallCount++
+	c.DeleteCertificateCall.Receives.Input = input
+
+	return c.DeleteCertificateCall.Returns.Output, c.DeleteCertificateCall.Returns.Error
+}
+
+func (c *ACMClient) ListCertificates(input *acm.ListCertificatesInput) (*acm.ListCertificatesOutput, error) {
+	c.ListCertificatesCall.CallCount++
+	c.ListCertificatesCall.Receives.Inputs = append(c.ListCertificatesCall.Receives.Inputs, input)
+
+	if len(c.ListCertificatesCall.Returns.Outputs) < c.ListCertificatesCall.CallCount {
+		return &acm.ListCertificatesOutput{}, c.ListCertificatesCall.Returns.Error
+	}
+	return c.ListCertificatesCall.Returns.Outputs[c.ListCertificatesCall.CallCount-1], c.ListCertificatesCall.Returns.Error
+}
+
+func (c *ACMClient) ListTagsForCertificate(input *acm.ListTagsForCertificateInput) (*acm.ListTagsForCertificateOutput, error) {
+	c.ListTagsForCertificateCall.CallCount++
+	c.ListTagsForCertificateCall.Receives.Inputs = append(c.ListTagsForCertificateCall.Receives.Inputs, input)
+
+	if len(c.ListTagsForCertificateCall.Returns.Outputs) < c.ListTagsForCertificateCall.CallCount {
+		return &acm.ListTagsForCertificateOutput{}, c.ListTagsForCertificateCall.Returns.Error
+	}
+	return c.ListTagsForCertificateCall.Returns.Outputs[c.ListTagsForCertificateCall.CallCount-1], c.ListTagsForCertificateCall.Returns.Error
+}
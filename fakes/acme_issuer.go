@@ -0,0 +1,31 @@
+package fakes
+
+import "github.com/cloudfoundry/bosh-bootloader/ssl"
+
+type ACMEIssuer struct {
+	IssueCall struct {
+		CallCount int
+		Receives  struct {
+			DirectoryURL string
+			Email        string
+			AccountKey   []byte
+			Domain       string
+			Challenger   ssl.DNSChallenger
+		}
+		Returns struct {
+			KeyPair    ssl.KeyPair
+			AccountKey []byte
+			Error      error
+		}
+	}
+}
+
+func (i *ACMEIssuer) Issue(directoryURL, email string, accountKey []byte, domain string, challenger ssl.DNSChallenger) (ssl.KeyPair, []byte, error) {
+	i.IssueCall.CallCount++
+	i.IssueCall.Receives.DirectoryURL = directoryURL
+	i.IssueCall.Receives.Email = email
+	i.IssueCall.Receives.AccountKey = accountKey
+	i.IssueCall.Receives.Domain = domain
+	i.IssueCall.Receives.Challenger = challenger
+	return i.IssueCall.Returns.KeyPair, i.IssueCall.Returns.AccountKey, i.IssueCall.Returns.Error
+}
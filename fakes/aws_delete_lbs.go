@@ -6,6 +6,7 @@ type AWSDeleteLBs struct {
 	ExecuteCall struct {
 		CallCount int
 		Receives  struct {
+			Name  string
 			State storage.State
 		}
 
@@ -15,8 +16,9 @@ type AWSDeleteLBs struct {
 	}
 }
 
-func (a *AWSDeleteLBs) Execute(state storage.State) error {
+func (a *AWSDeleteLBs) Execute(name string, state storage.State) error {
 	a.ExecuteCall.CallCount++
+	a.ExecuteCall.Receives.Name = name
 	a.ExecuteCall.Receives.State = state
 	return a.ExecuteCall.Returns.Error
 }
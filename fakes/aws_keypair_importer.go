@@ -0,0 +1,23 @@
+package fakes
+
+import "github.com/cloudfoundry/bosh-bootloader/aws/ec2"
+
+type KeyPairImporter struct {
+	ImportCall struct {
+		CallCount int
+		Receives  struct {
+			KeyPair ec2.KeyPair
+		}
+		Returns struct {
+			KeyPair ec2.KeyPair
+			Error   error
+		}
+	}
+}
+
+func (k *KeyPairImporter) Import(keyPair ec2.KeyPair) (ec2.KeyPair, error) {
+	k.ImportCall.CallCount++
+	k.ImportCall.Receives.KeyPair = keyPair
+
+	return k.ImportCall.Returns.KeyPair, k.ImportCall.Returns.Error
+}
@@ -0,0 +1,20 @@
+package fakes
+
+type AWSPermissionChecker struct {
+	MissingActionsCall struct {
+		CallCount int
+		Receives  struct {
+			Actions []string
+		}
+		Returns struct {
+			Missing []string
+			Error   error
+		}
+	}
+}
+
+func (c *AWSPermissionChecker) MissingActions(actions []string) ([]string, error) {
+	c.MissingActionsCall.CallCount++
+	c.MissingActionsCall.Receives.Actions = actions
+	return c.MissingActionsCall.Returns.Missing, c.MissingActionsCall.Returns.Error
+}
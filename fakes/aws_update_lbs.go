@@ -9,6 +9,7 @@ type AWSUpdateLBs struct {
 	Name        string
 	ExecuteCall struct {
 		CallCount int
+		Stub      func(commands.AWSCreateLBsConfig, storage.State) error
 		Receives  struct {
 			Config commands.AWSCreateLBsConfig
 			State  storage.State
@@ -23,5 +24,10 @@ func (u *AWSUpdateLBs) Execute(config commands.AWSCreateLBsConfig, state storage
 	u.ExecuteCall.CallCount++
 	u.ExecuteCall.Receives.Config = config
 	u.ExecuteCall.Receives.State = state
+
+	if u.ExecuteCall.Stub != nil {
+		return u.ExecuteCall.Stub(config, state)
+	}
+
 	return u.ExecuteCall.Returns.Error
 }
@@ -2,6 +2,17 @@ package fakes
 
 import "github.com/cloudfoundry/bosh-bootloader/bosh"
 
+type UpdateConfigReceive struct {
+	Type string
+	Name string
+	Yaml []byte
+}
+
+type DeleteConfigReceive struct {
+	Type string
+	Name string
+}
+
 type BOSHClient struct {
 	UpdateCloudConfigCall struct {
 		CallCount int
@@ -13,6 +24,63 @@ type BOSHClient struct {
 		}
 	}
 
+	CloudConfigCall struct {
+		CallCount int
+		Returns   struct {
+			CloudConfig string
+			Error       error
+		}
+	}
+
+	UpdateRuntimeConfigCall struct {
+		CallCount int
+		Receives  struct {
+			Name string
+			Yaml []byte
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	UpdateCPIConfigCall struct {
+		CallCount int
+		Receives  struct {
+			Yaml []byte
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	ConfigCall struct {
+		CallCount int
+		Receives  struct {
+			Type string
+			Name string
+		}
+		Returns struct {
+			Config string
+			Error  error
+		}
+	}
+
+	UpdateConfigCall struct {
+		CallCount int
+		Receives  []UpdateConfigReceive
+		Returns   struct {
+			Error error
+		}
+	}
+
+	DeleteConfigCall struct {
+		CallCount int
+		Receives  []DeleteConfigReceive
+		Returns   struct {
+			Error error
+		}
+	}
+
 	InfoCall struct {
 		CallCount int
 		Returns   struct {
@@ -20,6 +88,58 @@ type BOSHClient struct {
 			Error error
 		}
 	}
+
+	DeploymentsCall struct {
+		CallCount int
+		Returns   struct {
+			Deployments []bosh.Deployment
+			Error       error
+		}
+	}
+
+	VMsCall struct {
+		CallCount int
+		Receives  struct {
+			Deployment string
+		}
+		Returns struct {
+			VMs   []bosh.VM
+			Error error
+		}
+	}
+
+	TasksCall struct {
+		CallCount int
+		Receives  struct {
+			States []string
+		}
+		Returns struct {
+			Tasks []bosh.Task
+			Error error
+		}
+	}
+
+	TaskCall struct {
+		CallCount int
+		Receives  struct {
+			ID int
+		}
+		Returns struct {
+			Task  bosh.Task
+			Error error
+		}
+	}
+
+	WaitForTaskCall struct {
+		CallCount int
+		Receives  struct {
+			ID int
+		}
+		Returns struct {
+			Task  bosh.Task
+			Error error
+		}
+	}
 }
 
 func (c *BOSHClient) UpdateCloudConfig(yaml []byte) error {
@@ -28,7 +148,80 @@ func (c *BOSHClient) UpdateCloudConfig(yaml []byte) error {
 	return c.UpdateCloudConfigCall.Returns.Error
 }
 
+func (c *BOSHClient) CloudConfig() (string, error) {
+	c.CloudConfigCall.CallCount++
+	return c.CloudConfigCall.Returns.CloudConfig, c.CloudConfigCall.Returns.Error
+}
+
+func (c *BOSHClient) UpdateRuntimeConfig(name string, yaml []byte) error {
+	c.UpdateRuntimeConfigCall.CallCount++
+	c.UpdateRuntimeConfigCall.Receives.Name = name
+	c.UpdateRuntimeConfigCall.Receives.Yaml = yaml
+	return c.UpdateRuntimeConfigCall.Returns.Error
+}
+
+func (c *BOSHClient) UpdateCPIConfig(yaml []byte) error {
+	c.UpdateCPIConfigCall.CallCount++
+	c.UpdateCPIConfigCall.Receives.Yaml = yaml
+	return c.UpdateCPIConfigCall.Returns.Error
+}
+
+func (c *BOSHClient) Config(configType, name string) (string, error) {
+	c.ConfigCall.CallCount++
+	c.ConfigCall.Receives.Type = configType
+	c.ConfigCall.Receives.Name = name
+	return c.ConfigCall.Returns.Config, c.ConfigCall.Returns.Error
+}
+
+func (c *BOSHClient) UpdateConfig(configType, name string, yaml []byte) error {
+	c.UpdateConfigCall.CallCount++
+	c.UpdateConfigCall.Receives = append(c.UpdateConfigCall.Receives, UpdateConfigReceive{
+		Type: configType,
+		Name: name,
+		Yaml: yaml,
+	})
+	return c.UpdateConfigCall.Returns.Error
+}
+
+func (c *BOSHClient) DeleteConfig(configType, name string) error {
+	c.DeleteConfigCall.CallCount++
+	c.DeleteConfigCall.Receives = append(c.DeleteConfigCall.Receives, DeleteConfigReceive{
+		Type: configType,
+		Name: name,
+	})
+	return c.DeleteConfigCall.Returns.Error
+}
+
 func (c *BOSHClient) Info() (bosh.Info, error) {
 	c.InfoCall.CallCount++
 	return c.InfoCall.Returns.Info, c.InfoCall.Returns.Error
 }
+
+func (c *BOSHClient) Deployments() ([]bosh.Deployment, error) {
+	c.DeploymentsCall.CallCount++
+	return c.DeploymentsCall.Returns.Deployments, c.DeploymentsCall.Returns.Error
+}
+
+func (c *BOSHClient) VMs(deployment string) ([]bosh.VM, error) {
+	c.VMsCall.CallCount++
+	c.VMsCall.Receives.Deployment = deployment
+	return c.VMsCall.Returns.VMs, c.VMsCall.Returns.Error
+}
+
+func (c *BOSHClient) Tasks(states ...string) ([]bosh.Task, error) {
+	c.TasksCall.CallCount++
+	c.TasksCall.Receives.States = states
+	return c.TasksCall.Returns.Tasks, c.TasksCall.Returns.Error
+}
+
+func (c *BOSHClient) Task(id int) (bosh.Task, error) {
+	c.TaskCall.CallCount++
+	c.TaskCall.Receives.ID = id
+	return c.TaskCall.Returns.Task, c.TaskCall.Returns.Error
+}
+
+func (c *BOSHClient) WaitForTask(id int) (bosh.Task, error) {
+	c.WaitForTaskCall.CallCount++
+	c.WaitForTaskCall.Receives.ID = id
+	return c.WaitForTaskCall.Returns.Task, c.WaitForTaskCall.Returns.Error
+}
@@ -11,8 +11,11 @@ type CertificateManager struct {
 			Chain           string
 			CertificateName string
 		}
-		Returns struct {
-			Error error
+		Certificates     []string
+		CertificateNames []string
+		Returns          struct {
+			CertificateName string
+			Error           error
 		}
 	}
 
@@ -21,7 +24,8 @@ type CertificateManager struct {
 		Receives  struct {
 			CertificateName string
 		}
-		Returns struct {
+		CertificateNames []string
+		Returns          struct {
 			Error error
 		}
 	}
@@ -39,19 +43,32 @@ type CertificateManager struct {
 	}
 }
 
-func (c *CertificateManager) Create(certificate, privatekey, chain, certificateName string) error {
+// Create returns the name it receives, like the IAM certificate manager,
+// unless CreateCall.Returns.CertificateName is set.
+func (c *CertificateManager) Create(certificate, privatekey, chain, certificateName string) (string, error) {
 	c.CreateCall.CallCount++
 	c.CreateCall.Receives.Certificate = certificate
 	c.CreateCall.Receives.PrivateKey = privatekey
 	c.CreateCall.Receives.Chain = chain
 	c.CreateCall.Receives.CertificateName = certificateName
+	c.CreateCall.Certificates = append(c.CreateCall.Certificates, certificate)
+	c.CreateCall.CertificateNames = append(c.CreateCall.CertificateNames, certificateName)
+
+	if c.CreateCall.Returns.Error != nil {
+		return "", c.CreateCall.Returns.Error
+	}
+
+	if c.CreateCall.Returns.CertificateName != "" {
+		return c.CreateCall.Returns.CertificateName, nil
+	}
 
-	return c.CreateCall.Returns.Error
+	return certificateName, nil
 }
 
 func (c *CertificateManager) Delete(certificateName string) error {
 	c.DeleteCall.CallCount++
 	c.DeleteCall.Receives.CertificateName = certificateName
+	c.DeleteCall.CertificateNames = append(c.DeleteCall.CertificateNames, certificateName)
 	return c.DeleteCall.Returns.Error
 }
 
@@ -2,7 +2,8 @@ package fakes
 
 type CertificateValidator struct {
 	ValidateCall struct {
-		Returns struct {
+		CallCount int
+		Returns   struct {
 			Error error
 		}
 		Receives struct {
@@ -15,6 +16,7 @@ type CertificateValidator struct {
 }
 
 func (c *CertificateValidator) Validate(command, certificatePath, keyPath, chainPath string) error {
+	c.ValidateCall.CallCount++
 	c.ValidateCall.Receives.Command = command
 	c.ValidateCall.Receives.CertificatePath = certificatePath
 	c.ValidateCall.Receives.KeyPath = keyPath
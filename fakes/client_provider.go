@@ -2,9 +2,12 @@ package fakes
 
 import (
 	"github.com/cloudfoundry/bosh-bootloader/aws"
+	"github.com/cloudfoundry/bosh-bootloader/aws/acm"
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
 	"github.com/cloudfoundry/bosh-bootloader/aws/ec2"
 	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
+	"github.com/cloudfoundry/bosh-bootloader/aws/route53"
+	"github.com/cloudfoundry/bosh-bootloader/aws/sts"
 )
 
 type ClientProvider struct {
@@ -32,6 +35,24 @@ type ClientProvider struct {
 			IAMClient iam.Client
 		}
 	}
+	GetRoute53ClientCall struct {
+		CallCount int
+		Returns   struct {
+			Route53Client route53.Client
+		}
+	}
+	GetACMClientCall struct {
+		CallCount int
+		Returns   struct {
+			ACMClient acm.Client
+		}
+	}
+	GetSTSClientCall struct {
+		CallCount int
+		Returns   struct {
+			STSClient sts.Client
+		}
+	}
 }
 
 func (c *ClientProvider) SetConfig(config aws.Config) {
@@ -53,3 +74,18 @@ func (c *ClientProvider) GetIAMClient() iam.Client {
 	c.GetIAMClientCall.CallCount++
 	return c.GetIAMClientCall.Returns.IAMClient
 }
+
+func (c *ClientProvider) GetRoute53Client() route53.Client {
+	c.GetRoute53ClientCall.CallCount++
+	return c.GetRoute53ClientCall.Returns.Route53Client
+}
+
+func (c *ClientProvider) GetACMClient() acm.Client {
+	c.GetACMClientCall.CallCount++
+	return c.GetACMClientCall.Returns.ACMClient
+}
+
+func (c *ClientProvider) GetSTSClient() sts.Client {
+	c.GetSTSClientCall.CallCount++
+	return c.GetSTSClientCall.Returns.STSClient
+}
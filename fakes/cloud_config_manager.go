@@ -14,6 +14,34 @@ type CloudConfigManager struct {
 			Error error
 		}
 	}
+	UpdateWithConfirmationCall struct {
+		CallCount int
+		Receives  struct {
+			State storage.State
+		}
+		Returns struct {
+			Error error
+		}
+	}
+	DeleteCall struct {
+		CallCount int
+		Receives  struct {
+			State storage.State
+		}
+		Returns struct {
+			Error error
+		}
+	}
+	DiffCall struct {
+		CallCount int
+		Receives  struct {
+			State storage.State
+		}
+		Returns struct {
+			Diff  string
+			Error error
+		}
+	}
 	GenerateCall struct {
 		CallCount int
 		Receives  struct {
@@ -37,3 +65,21 @@ func (c *CloudConfigManager) Generate(state storage.State) (string, error) {
 	c.GenerateCall.Receives.State = state
 	return c.GenerateCall.Returns.CloudConfig, c.GenerateCall.Returns.Error
 }
+
+func (c *CloudConfigManager) UpdateWithConfirmation(state storage.State) error {
+	c.UpdateWithConfirmationCall.CallCount++
+	c.UpdateWithConfirmationCall.Receives.State = state
+	return c.UpdateWithConfirmationCall.Returns.Error
+}
+
+func (c *CloudConfigManager) Diff(state storage.State) (string, error) {
+	c.DiffCall.CallCount++
+	c.DiffCall.Receives.State = state
+	return c.DiffCall.Returns.Diff, c.DiffCall.Returns.Error
+}
+
+func (c *CloudConfigManager) Delete(state storage.State) error {
+	c.DeleteCall.CallCount++
+	c.DeleteCall.Receives.State = state
+	return c.DeleteCall.Returns.Error
+}
@@ -0,0 +1,27 @@
+package fakes
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/cost"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+type CostEstimator struct {
+	EstimateCall struct {
+		CallCount int
+		Receives  struct {
+			State      storage.State
+			PriceTable cost.PriceTable
+		}
+		Returns struct {
+			Estimate cost.Estimate
+			Error    error
+		}
+	}
+}
+
+func (c *CostEstimator) Estimate(state storage.State, table cost.PriceTable) (cost.Estimate, error) {
+	c.EstimateCall.CallCount++
+	c.EstimateCall.Receives.State = state
+	c.EstimateCall.Receives.PriceTable = table
+	return c.EstimateCall.Returns.Estimate, c.EstimateCall.Returns.Error
+}
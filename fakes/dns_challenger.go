@@ -0,0 +1,44 @@
+package fakes
+
+type DNSChallenger struct {
+	PresentCall struct {
+		CallCount int
+		Receives  struct {
+			Domain string
+			FQDN   string
+			Value  string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+	CleanUpCall struct {
+		CallCount int
+		Receives  struct {
+			Domain string
+			FQDN   string
+			Value  string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func (c *DNSChallenger) Present(domain, fqdn, value string) error {
+	c.PresentCall.CallCount++
+	c.PresentCall.Receives.Domain = domain
+	c.PresentCall.Receives.FQDN = fqdn
+	c.PresentCall.Receives.Value = value
+
+	return c.PresentCall.Returns.Error
+}
+
+func (c *DNSChallenger) CleanUp(domain, fqdn, value string) error {
+	c.CleanUpCall.CallCount++
+	c.CleanUpCall.Receives.Domain = domain
+	c.CleanUpCall.Receives.FQDN = fqdn
+	c.CleanUpCall.Receives.Value = value
+
+	return c.CleanUpCall.Returns.Error
+}
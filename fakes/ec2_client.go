@@ -10,7 +10,8 @@ type EC2Client struct {
 			Input *awsec2.ImportKeyPairInput
 		}
 		Returns struct {
-			Error error
+			Output *awsec2.ImportKeyPairOutput
+			Error  error
 		}
 	}
 
@@ -63,12 +64,34 @@ type EC2Client struct {
 			Error  error
 		}
 	}
+
+	DescribeInstanceTypeOfferingsCall struct {
+		CallCount int
+		Receives  struct {
+			Input *awsec2.DescribeInstanceTypeOfferingsInput
+		}
+		Returns struct {
+			Output *awsec2.DescribeInstanceTypeOfferingsOutput
+			Error  error
+		}
+	}
+
+	DescribeVpcsCall struct {
+		CallCount int
+		Receives  struct {
+			Input *awsec2.DescribeVpcsInput
+		}
+		Returns struct {
+			Output *awsec2.DescribeVpcsOutput
+			Error  error
+		}
+	}
 }
 
 func (c *EC2Client) ImportKeyPair(input *awsec2.ImportKeyPairInput) (*awsec2.ImportKeyPairOutput, error) {
 	c.ImportKeyPairCall.Receives.Input = input
 
-	return nil, c.ImportKeyPairCall.Returns.Error
+	return c.ImportKeyPairCall.Returns.Output, c.ImportKeyPairCall.Returns.Error
 }
 
 func (c *EC2Client) DescribeKeyPairs(input *awsec2.DescribeKeyPairsInput) (*awsec2.DescribeKeyPairsOutput, error) {
@@ -100,3 +123,17 @@ func (c *EC2Client) DescribeInstances(input *awsec2.DescribeInstancesInput) (*aw
 
 	return c.DescribeInstancesCall.Returns.Output, c.DescribeInstancesCall.Returns.Error
 }
+
+func (c *EC2Client) DescribeInstanceTypeOfferings(input *awsec2.DescribeInstanceTypeOfferingsInput) (*awsec2.DescribeInstanceTypeOfferingsOutput, error) {
+	c.DescribeInstanceTypeOfferingsCall.CallCount++
+	c.DescribeInstanceTypeOfferingsCall.Receives.Input = input
+
+	return c.DescribeInstanceTypeOfferingsCall.Returns.Output, c.DescribeInstanceTypeOfferingsCall.Returns.Error
+}
+
+func (c *EC2Client) DescribeVpcs(input *awsec2.DescribeVpcsInput) (*awsec2.DescribeVpcsOutput, error) {
+	c.DescribeVpcsCall.CallCount++
+	c.DescribeVpcsCall.Receives.Input = input
+
+	return c.DescribeVpcsCall.Returns.Output, c.DescribeVpcsCall.Returns.Error
+}
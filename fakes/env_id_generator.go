@@ -1,16 +1,30 @@
 package fakes
 
+import "github.com/cloudfoundry/bosh-bootloader/helpers"
+
 type EnvIDGenerator struct {
 	GenerateCall struct {
+		Stub      func(helpers.EnvIDNaming, int) (string, error)
 		CallCount int
-		Returns   struct {
+		Receives  struct {
+			Naming    helpers.EnvIDNaming
+			MaxLength int
+		}
+		Returns struct {
 			EnvID string
 			Error error
 		}
 	}
 }
 
-func (e *EnvIDGenerator) Generate() (string, error) {
+func (e *EnvIDGenerator) Generate(naming helpers.EnvIDNaming, maxLength int) (string, error) {
 	e.GenerateCall.CallCount++
+	e.GenerateCall.Receives.Naming = naming
+	e.GenerateCall.Receives.MaxLength = maxLength
+
+	if e.GenerateCall.Stub != nil {
+		return e.GenerateCall.Stub(naming, maxLength)
+	}
+
 	return e.GenerateCall.Returns.EnvID, e.GenerateCall.Returns.Error
 }
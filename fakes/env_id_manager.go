@@ -1,13 +1,16 @@
 package fakes
 
-import "github.com/cloudfoundry/bosh-bootloader/storage"
+import (
+	"github.com/cloudfoundry/bosh-bootloader/helpers"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
 
 type EnvIDManager struct {
 	SyncCall struct {
 		CallCount int
 		Receives  struct {
-			State storage.State
-			Name  string
+			State  storage.State
+			Naming helpers.EnvIDNaming
 		}
 		Returns struct {
 			EnvID string
@@ -16,10 +19,10 @@ type EnvIDManager struct {
 	}
 }
 
-func (e *EnvIDManager) Sync(state storage.State, name string) (string, error) {
+func (e *EnvIDManager) Sync(state storage.State, naming helpers.EnvIDNaming) (string, error) {
 	e.SyncCall.CallCount++
 
 	e.SyncCall.Receives.State = state
-	e.SyncCall.Receives.Name = name
+	e.SyncCall.Receives.Naming = naming
 	return e.SyncCall.Returns.EnvID, e.SyncCall.Returns.Error
 }
@@ -1,6 +1,9 @@
 package fakes
 
-import compute "google.golang.org/api/compute/v1"
+import (
+	compute "google.golang.org/api/compute/v1"
+	dns "google.golang.org/api/dns/v1"
+)
 
 type GCPClient struct {
 	ProjectIDCall struct {
@@ -43,6 +46,65 @@ type GCPClient struct {
 			Error       error
 		}
 	}
+	ListMachineTypesCall struct {
+		CallCount int
+		Receives  struct {
+			Zone string
+		}
+		Returns struct {
+			MachineTypeList *compute.MachineTypeList
+			Error           error
+		}
+	}
+	ListManagedZonesCall struct {
+		CallCount int
+		Returns   struct {
+			ManagedZones *dns.ManagedZonesListResponse
+			Error        error
+		}
+	}
+	ChangeRecordSetsCall struct {
+		CallCount int
+		Receives  struct {
+			ManagedZone string
+			Change      *dns.Change
+		}
+		Returns struct {
+			Change *dns.Change
+			Error  error
+		}
+	}
+	GetChangeCall struct {
+		CallCount int
+		Receives  struct {
+			ManagedZone string
+			ChangeID    string
+		}
+		Returns struct {
+			Change *dns.Change
+			Error  error
+		}
+	}
+	GetRegionCall struct {
+		CallCount int
+		Receives  struct {
+			Region string
+		}
+		Returns struct {
+			Region *compute.Region
+			Error  error
+		}
+	}
+	TestIAMPermissionsCall struct {
+		CallCount int
+		Receives  struct {
+			Permissions []string
+		}
+		Returns struct {
+			Permissions []string
+			Error       error
+		}
+	}
 }
 
 func (g *GCPClient) ProjectID() string {
@@ -71,3 +133,40 @@ func (g *GCPClient) GetNetworks(name string) (*compute.NetworkList, error) {
 	g.GetNetworksCall.Receives.Name = name
 	return g.GetNetworksCall.Returns.NetworkList, g.GetNetworksCall.Returns.Error
 }
+
+func (g *GCPClient) ListMachineTypes(zone string) (*compute.MachineTypeList, error) {
+	g.ListMachineTypesCall.CallCount++
+	g.ListMachineTypesCall.Receives.Zone = zone
+	return g.ListMachineTypesCall.Returns.MachineTypeList, g.ListMachineTypesCall.Returns.Error
+}
+
+func (g *GCPClient) ListManagedZones() (*dns.ManagedZonesListResponse, error) {
+	g.ListManagedZonesCall.CallCount++
+	return g.ListManagedZonesCall.Returns.ManagedZones, g.ListManagedZonesCall.Returns.Error
+}
+
+func (g *GCPClient) ChangeRecordSets(managedZone string, change *dns.Change) (*dns.Change, error) {
+	g.ChangeRecordSetsCall.CallCount++
+	g.ChangeRecordSetsCall.Receives.ManagedZone = managedZone
+	g.ChangeRecordSetsCall.Receives.Change = change
+	return g.ChangeRecordSetsCall.Returns.Change, g.ChangeRecordSetsCall.Returns.Error
+}
+
+func (g *GCPClient) GetChange(managedZone, changeID string) (*dns.Change, error) {
+	g.GetChangeCall.CallCount++
+	g.GetChangeCall.Receives.ManagedZone = managedZone
+	g.GetChangeCall.Receives.ChangeID = changeID
+	return g.GetChangeCall.Returns.Change, g.GetChangeCall.Returns.Error
+}
+
+func (g *GCPClient) GetRegion(region string) (*compute.Region, error) {
+	g.GetRegionCall.CallCount++
+	g.GetRegionCall.Receives.Region = region
+	return g.GetRegionCall.Returns.Region, g.GetRegionCall.Returns.Error
+}
+
+func (g *GCPClient) TestIAMPermissions(permissions []string) ([]string, error) {
+	g.TestIAMPermissionsCall.CallCount++
+	g.TestIAMPermissionsCall.Receives.Permissions = permissions
+	return g.TestIAMPermissionsCall.Returns.Permissions, g.TestIAMPermissionsCall.Returns.Error
+}
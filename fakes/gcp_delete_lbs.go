@@ -6,6 +6,7 @@ type GCPDeleteLBs struct {
 	ExecuteCall struct {
 		CallCount int
 		Receives  struct {
+			Name  string
 			State storage.State
 		}
 
@@ -15,8 +16,9 @@ type GCPDeleteLBs struct {
 	}
 }
 
-func (g *GCPDeleteLBs) Execute(state storage.State) error {
+func (g *GCPDeleteLBs) Execute(name string, state storage.State) error {
 	g.ExecuteCall.CallCount++
+	g.ExecuteCall.Receives.Name = name
 	g.ExecuteCall.Receives.State = state
 	return g.ExecuteCall.Returns.Error
 }
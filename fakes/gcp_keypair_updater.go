@@ -5,15 +5,23 @@ import "github.com/cloudfoundry/bosh-bootloader/storage"
 type GCPKeyPairUpdater struct {
 	UpdateCall struct {
 		CallCount int
-		Returns   struct {
+		Receives  struct {
+			KeyPair storage.KeyPair
+			KeyType string
+			KeyBits int
+		}
+		Returns struct {
 			KeyPair storage.KeyPair
 			Error   error
 		}
 	}
 }
 
-func (g *GCPKeyPairUpdater) Update() (storage.KeyPair, error) {
+func (g *GCPKeyPairUpdater) Update(keyPair storage.KeyPair, keyType string, keyBits int) (storage.KeyPair, error) {
 	g.UpdateCall.CallCount++
+	g.UpdateCall.Receives.KeyPair = keyPair
+	g.UpdateCall.Receives.KeyType = keyType
+	g.UpdateCall.Receives.KeyBits = keyBits
 
 	return g.UpdateCall.Returns.KeyPair, g.UpdateCall.Returns.Error
 }
@@ -0,0 +1,69 @@
+package fakes
+
+import "github.com/cloudfoundry/bosh-bootloader/storage"
+
+type GCPPreflightChecker struct {
+	ValidateServiceAccountKeyCall struct {
+		CallCount int
+		Receives  struct {
+			ServiceAccountKey string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+	DisabledAPIsCall struct {
+		CallCount int
+		Receives  struct {
+			State storage.State
+		}
+		Returns struct {
+			Disabled []string
+			Error    error
+		}
+	}
+	MissingPermissionsCall struct {
+		CallCount int
+		Receives  struct {
+			Permissions []string
+		}
+		Returns struct {
+			Missing []string
+			Error   error
+		}
+	}
+	QuotaShortfallsCall struct {
+		CallCount int
+		Receives  struct {
+			State storage.State
+		}
+		Returns struct {
+			Shortfalls []string
+			Error      error
+		}
+	}
+}
+
+func (c *GCPPreflightChecker) ValidateServiceAccountKey(serviceAccountKey string) error {
+	c.ValidateServiceAccountKeyCall.CallCount++
+	c.ValidateServiceAccountKeyCall.Receives.ServiceAccountKey = serviceAccountKey
+	return c.ValidateServiceAccountKeyCall.Returns.Error
+}
+
+func (c *GCPPreflightChecker) DisabledAPIs(state storage.State) ([]string, error) {
+	c.DisabledAPIsCall.CallCount++
+	c.DisabledAPIsCall.Receives.State = state
+	return c.DisabledAPIsCall.Returns.Disabled, c.DisabledAPIsCall.Returns.Error
+}
+
+func (c *GCPPreflightChecker) MissingPermissions(permissions []string) ([]string, error) {
+	c.MissingPermissionsCall.CallCount++
+	c.MissingPermissionsCall.Receives.Permissions = permissions
+	return c.MissingPermissionsCall.Returns.Missing, c.MissingPermissionsCall.Returns.Error
+}
+
+func (c *GCPPreflightChecker) QuotaShortfalls(state storage.State) ([]string, error) {
+	c.QuotaShortfallsCall.CallCount++
+	c.QuotaShortfallsCall.Receives.State = state
+	return c.QuotaShortfallsCall.Returns.Shortfalls, c.QuotaShortfallsCall.Returns.Error
+}
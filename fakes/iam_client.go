@@ -35,6 +35,18 @@ type IAMClient struct {
 			Error  error
 		}
 	}
+
+	SimulatePrincipalPolicyCall struct {
+		CallCount int
+		Stub      func(*iam.SimulatePrincipalPolicyInput) (*iam.SimulatePolicyResponse, error)
+		Receives  struct {
+			Inputs []*iam.SimulatePrincipalPolicyInput
+		}
+		Returns struct {
+			Output *iam.SimulatePolicyResponse
+			Error  error
+		}
+	}
 }
 
 func (c *IAMClient) UploadServerCertificate(input *iam.UploadServerCertificateInput) (*iam.UploadServerCertificateOutput, error) {
@@ -54,3 +66,14 @@ func (c *IAMClient) DeleteServerCertificate(input *iam.DeleteServerCertificateIn
 	c.DeleteServerCertificateCall.Receives.Input = input
 	return c.DeleteServerCertificateCall.Returns.Output, c.DeleteServerCertificateCall.Returns.Error
 }
+
+func (c *IAMClient) SimulatePrincipalPolicy(input *iam.SimulatePrincipalPolicyInput) (*iam.SimulatePolicyResponse, error) {
+	c.SimulatePrincipalPolicyCall.CallCount++
+	c.SimulatePrincipalPolicyCall.Receives.Inputs = append(c.SimulatePrincipalPolicyCall.Receives.Inputs, input)
+
+	if c.SimulatePrincipalPolicyCall.Stub != nil {
+		return c.SimulatePrincipalPolicyCall.Stub(input)
+	}
+
+	return c.SimulatePrincipalPolicyCall.Returns.Output, c.SimulatePrincipalPolicyCall.Returns.Error
+}
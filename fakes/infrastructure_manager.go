@@ -1,6 +1,9 @@
 package fakes
 
-import "github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
+import (
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
 
 type InfrastructureManager struct {
 	CreateCall struct {
@@ -11,9 +14,11 @@ type InfrastructureManager struct {
 			StackName        string
 			LBType           string
 			LBCertificateARN string
+			LBSpecs          []storage.LBSpec
 			AZs              []string
 			BOSHAZ           string
 			EnvID            string
+			Tags             map[string]string
 		}
 		Returns struct {
 			Stack cloudformation.Stack
@@ -29,8 +34,10 @@ type InfrastructureManager struct {
 			StackName        string
 			LBType           string
 			LBCertificateARN string
+			LBSpecs          []storage.LBSpec
 			BOSHAZ           string
 			EnvID            string
+			Tags             map[string]string
 		}
 		Returns struct {
 			Stack cloudformation.Stack
@@ -70,15 +77,17 @@ type InfrastructureManager struct {
 	}
 }
 
-func (m *InfrastructureManager) Create(keyPairName string, azs []string, stackName, boshAZ, lbType, lbCertificateARN, envID string) (cloudformation.Stack, error) {
+func (m *InfrastructureManager) Create(keyPairName string, azs []string, stackName, boshAZ, lbType, lbCertificateARN string, lbSpecs []storage.LBSpec, envID string, tags map[string]string) (cloudformation.Stack, error) {
 	m.CreateCall.CallCount++
 	m.CreateCall.Receives.StackName = stackName
 	m.CreateCall.Receives.LBType = lbType
 	m.CreateCall.Receives.LBCertificateARN = lbCertificateARN
+	m.CreateCall.Receives.LBSpecs = lbSpecs
 	m.CreateCall.Receives.KeyPairName = keyPairName
 	m.CreateCall.Receives.AZs = azs
 	m.CreateCall.Receives.BOSHAZ = boshAZ
 	m.CreateCall.Receives.EnvID = envID
+	m.CreateCall.Receives.Tags = tags
 
 	if m.CreateCall.Stub != nil {
 		return m.CreateCall.Stub(keyPairName, azs, stackName, lbType, envID)
@@ -87,15 +96,17 @@ func (m *InfrastructureManager) Create(keyPairName string, azs []string, stackNa
 	return m.CreateCall.Returns.Stack, m.CreateCall.Returns.Error
 }
 
-func (m *InfrastructureManager) Update(keyPairName string, azs []string, stackName, boshAZ, lbType, lbCertificateARN, envID string) (cloudformation.Stack, error) {
+func (m *InfrastructureManager) Update(keyPairName string, azs []string, stackName, boshAZ, lbType, lbCertificateARN string, lbSpecs []storage.LBSpec, envID string, tags map[string]string) (cloudformation.Stack, error) {
 	m.UpdateCall.CallCount++
 	m.UpdateCall.Receives.KeyPairName = keyPairName
 	m.UpdateCall.Receives.AZs = azs
 	m.UpdateCall.Receives.StackName = stackName
 	m.UpdateCall.Receives.LBType = lbType
 	m.UpdateCall.Receives.LBCertificateARN = lbCertificateARN
+	m.UpdateCall.Receives.LBSpecs = lbSpecs
 	m.UpdateCall.Receives.BOSHAZ = boshAZ
 	m.UpdateCall.Receives.EnvID = envID
+	m.UpdateCall.Receives.Tags = tags
 	return m.UpdateCall.Returns.Stack, m.UpdateCall.Returns.Error
 }
 
@@ -0,0 +1,20 @@
+package fakes
+
+type InstanceTypeRetriever struct {
+	RetrieveCall struct {
+		CallCount int
+		Receives  struct {
+			Region string
+		}
+		Returns struct {
+			InstanceTypes []string
+			Error         error
+		}
+	}
+}
+
+func (i *InstanceTypeRetriever) Retrieve(region string) ([]string, error) {
+	i.RetrieveCall.CallCount++
+	i.RetrieveCall.Receives.Region = region
+	return i.RetrieveCall.Returns.InstanceTypes, i.RetrieveCall.Returns.Error
+}
@@ -0,0 +1,28 @@
+package fakes
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+type LBCertificateGenerator struct {
+	GenerateCall struct {
+		CallCount int
+		Receives  struct {
+			State storage.State
+			Cert  storage.LBGeneratedCert
+		}
+		Returns struct {
+			State      storage.State
+			CertBundle commands.CertBundle
+			Error      error
+		}
+	}
+}
+
+func (g *LBCertificateGenerator) Generate(state storage.State, cert storage.LBGeneratedCert) (storage.State, commands.CertBundle, error) {
+	g.GenerateCall.CallCount++
+	g.GenerateCall.Receives.State = state
+	g.GenerateCall.Receives.Cert = cert
+	return g.GenerateCall.Returns.State, g.GenerateCall.Returns.CertBundle, g.GenerateCall.Returns.Error
+}
@@ -0,0 +1,40 @@
+package fakes
+
+import "github.com/cloudfoundry/bosh-bootloader/ssl"
+
+type LBKeyPairGenerator struct {
+	GenerateCACall struct {
+		CallCount int
+		Receives  struct {
+			CommonName string
+		}
+		Returns struct {
+			CA    ssl.CAData
+			Error error
+		}
+	}
+	GenerateForDomainCall struct {
+		CallCount int
+		Receives  struct {
+			CA     ssl.CAData
+			Domain string
+		}
+		Returns struct {
+			KeyPair ssl.KeyPair
+			Error   error
+		}
+	}
+}
+
+func (g *LBKeyPairGenerator) GenerateCA(commonName string) (ssl.CAData, error) {
+	g.GenerateCACall.CallCount++
+	g.GenerateCACall.Receives.CommonName = commonName
+	return g.GenerateCACall.Returns.CA, g.GenerateCACall.Returns.Error
+}
+
+func (g *LBKeyPairGenerator) GenerateForDomain(ca ssl.CAData, domain string) (ssl.KeyPair, error) {
+	g.GenerateForDomainCall.CallCount++
+	g.GenerateForDomainCall.Receives.CA = ca
+	g.GenerateForDomainCall.Receives.Domain = domain
+	return g.GenerateForDomainCall.Returns.KeyPair, g.GenerateForDomainCall.Returns.Error
+}
@@ -0,0 +1,20 @@
+package fakes
+
+type MachineTypeRetriever struct {
+	RetrieveCall struct {
+		CallCount int
+		Receives  struct {
+			Zones []string
+		}
+		Returns struct {
+			MachineTypes []string
+			Error        error
+		}
+	}
+}
+
+func (m *MachineTypeRetriever) Retrieve(zone string) ([]string, error) {
+	m.RetrieveCall.CallCount++
+	m.RetrieveCall.Receives.Zones = append(m.RetrieveCall.Receives.Zones, zone)
+	return m.RetrieveCall.Returns.MachineTypes, m.RetrieveCall.Returns.Error
+}
@@ -0,0 +1,31 @@
+package fakes
+
+type Renderer struct {
+	StructuredCall struct {
+		CallCount int
+		Returns   struct {
+			Structured bool
+		}
+	}
+
+	RenderCall struct {
+		CallCount int
+		Receives  struct {
+			Value interface{}
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func (r *Renderer) Structured() bool {
+	r.StructuredCall.CallCount++
+	return r.StructuredCall.Returns.Structured
+}
+
+func (r *Renderer) Render(value interface{}) error {
+	r.RenderCall.CallCount++
+	r.RenderCall.Receives.Value = value
+	return r.RenderCall.Returns.Error
+}
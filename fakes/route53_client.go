@@ -0,0 +1,58 @@
+package fakes
+
+import "github.com/aws/aws-sdk-go/service/route53"
+
+type Route53Client struct {
+	ListHostedZonesByNameCall struct {
+		CallCount int
+		Receives  struct {
+			Input *route53.ListHostedZonesByNameInput
+		}
+		Returns struct {
+			Output *route53.ListHostedZonesByNameOutput
+			Error  error
+		}
+	}
+
+	ChangeResourceRecordSetsCall struct {
+		CallCount int
+		Receives  struct {
+			Input *route53.ChangeResourceRecordSetsInput
+		}
+		Returns struct {
+			Output *route53.ChangeResourceRecordSetsOutput
+			Error  error
+		}
+	}
+
+	WaitUntilResourceRecordSetsChangedCall struct {
+		CallCount int
+		Receives  struct {
+			Input *route53.GetChangeInput
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func (c *Route53Client) ListHostedZonesByName(input *route53.ListHostedZonesByNameInput) (*route53.ListHostedZonesByNameOutput, error) {
+	c.ListHostedZonesByNameCall.CallCount++
+	c.ListHostedZonesByNameCall.Receives.Input = input
+
+	return c.ListHostedZonesByNameCall.Returns.Output, c.ListHostedZonesByNameCall.Returns.Error
+}
+
+func (c *Route53Client) ChangeResourceRecordSets(input *route53.ChangeResourceRecordSetsInput) (*route53.ChangeResourceRecordSetsOutput, error) {
+	c.ChangeResourceRecordSetsCall.CallCount++
+	c.ChangeResourceRecordSetsCall.Receives.Input = input
+
+	return c.ChangeResourceRecordSetsCall.Returns.Output, c.ChangeResourceRecordSetsCall.Returns.Error
+}
+
+func (c *Route53Client) WaitUntilResourceRecordSetsChanged(input *route53.GetChangeInput) error {
+	c.WaitUntilResourceRecordSetsChangedCall.CallCount++
+	c.WaitUntilResourceRecordSetsChangedCall.Receives.Input = input
+
+	return c.WaitUntilResourceRecordSetsChangedCall.Returns.Error
+}
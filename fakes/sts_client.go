@@ -0,0 +1,22 @@
+package fakes
+
+import "github.com/aws/aws-sdk-go/service/sts"
+
+type STSClient struct {
+	GetCallerIdentityCall struct {
+		CallCount int
+		Receives  struct {
+			Input *sts.GetCallerIdentityInput
+		}
+		Returns struct {
+			Output *sts.GetCallerIdentityOutput
+			Error  error
+		}
+	}
+}
+
+func (c *STSClient) GetCallerIdentity(input *sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+	c.GetCallerIdentityCall.CallCount++
+	c.GetCallerIdentityCall.Receives.Input = input
+	return c.GetCallerIdentityCall.Returns.Output, c.GetCallerIdentityCall.Returns.Error
+}
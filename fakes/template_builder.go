@@ -1,6 +1,9 @@
 package fakes
 
-import "github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
+import (
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
 
 type TemplateBuilder struct {
 	BuildCall struct {
@@ -9,6 +12,7 @@ type TemplateBuilder struct {
 			AZs              []string
 			LBType           string
 			LBCertificateARN string
+			LBSpecs          []storage.LBSpec
 			IAMUserName      string
 			EnvID            string
 			BOSHAZ           string
@@ -19,11 +23,12 @@ type TemplateBuilder struct {
 	}
 }
 
-func (b *TemplateBuilder) Build(keyPairName string, azs []string, lbType string, lbCertificateARN string, iamUserName string, envID string, boshAZ string) templates.Template {
+func (b *TemplateBuilder) Build(keyPairName string, azs []string, lbType string, lbCertificateARN string, lbSpecs []storage.LBSpec, iamUserName string, envID string, boshAZ string) templates.Template {
 	b.BuildCall.Receives.KeyPairName = keyPairName
 	b.BuildCall.Receives.AZs = azs
 	b.BuildCall.Receives.LBType = lbType
 	b.BuildCall.Receives.LBCertificateARN = lbCertificateARN
+	b.BuildCall.Receives.LBSpecs = lbSpecs
 	b.BuildCall.Receives.IAMUserName = iamUserName
 	b.BuildCall.Receives.EnvID = envID
 	b.BuildCall.Receives.BOSHAZ = boshAZ
@@ -0,0 +1,26 @@
+package fakes
+
+type VPCChecker struct {
+	ExistsCall struct {
+		Stub      func(string) (bool, error)
+		CallCount int
+		Receives  struct {
+			Name string
+		}
+		Returns struct {
+			Exists bool
+			Error  error
+		}
+	}
+}
+
+func (v *VPCChecker) Exists(name string) (bool, error) {
+	v.ExistsCall.CallCount++
+	v.ExistsCall.Receives.Name = name
+
+	if v.ExistsCall.Stub != nil {
+		return v.ExistsCall.Stub(name)
+	}
+
+	return v.ExistsCall.Returns.Exists, v.ExistsCall.Returns.Error
+}
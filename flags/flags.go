@@ -3,6 +3,7 @@ package flags
 import (
 	"flag"
 	"io/ioutil"
+	"strings"
 )
 
 type Flags struct {
@@ -30,6 +31,15 @@ func (f Flags) String(v *string, name string, value string) {
 	f.set.StringVar(v, name, value, "")
 }
 
+func (f Flags) Int(v *int, name string, value int) {
+	f.set.IntVar(v, name, value, "")
+}
+
+func (f Flags) StringSlice(v *[]string, name string, value []string) {
+	*v = value
+	f.set.Var((*stringSlice)(v), name, "")
+}
+
 func (f Flags) Parse(args []string) error {
 	return f.set.Parse(args)
 }
@@ -37,3 +47,14 @@ func (f Flags) Parse(args []string) error {
 func (f Flags) Args() []string {
 	return f.set.Args()
 }
+
+type stringSlice []string
+
+func (s *stringSlice) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSlice) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
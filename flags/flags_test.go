@@ -12,12 +12,16 @@ var _ = Describe("Flags", func() {
 		f         flags.Flags
 		boolVal   bool
 		stringVal string
+		intVal    int
+		sliceVal  []string
 	)
 
 	BeforeEach(func() {
 		f = flags.New("test")
 		f.Bool(&boolVal, "b", "bool", false)
 		f.String(&stringVal, "string", "")
+		f.Int(&intVal, "int", 0)
+		f.StringSlice(&sliceVal, "slice", nil)
 	})
 
 	Describe("Parse", func() {
@@ -49,6 +53,33 @@ var _ = Describe("Flags", func() {
 				Expect(stringVal).To(Equal("string_value"))
 			})
 		})
+
+		Context("StringSlice flags", func() {
+			It("collects every occurrence of the flag", func() {
+				err := f.Parse([]string{"--slice", "first", "--slice=second"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sliceVal).To(Equal([]string{"first", "second"}))
+			})
+
+			It("is empty when the flag is not provided", func() {
+				err := f.Parse([]string{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sliceVal).To(BeEmpty())
+			})
+		})
+
+		Context("Int flags", func() {
+			It("can parse int fields from flags", func() {
+				err := f.Parse([]string{"--int", "4096"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(intVal).To(Equal(4096))
+			})
+
+			It("returns an error when the value is not an int", func() {
+				err := f.Parse([]string{"--int", "not-an-int"})
+				Expect(err).To(HaveOccurred())
+			})
+		})
 	})
 
 	Describe("Args", func() {
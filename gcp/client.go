@@ -1,9 +1,16 @@
 package gcp
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+
+	"google.golang.org/api/googleapi"
 
 	compute "google.golang.org/api/compute/v1"
+	dns "google.golang.org/api/dns/v1"
 )
 
 type Client interface {
@@ -12,12 +19,21 @@ type Client interface {
 	SetCommonInstanceMetadata(metadata *compute.Metadata) (*compute.Operation, error)
 	ListInstances() (*compute.InstanceList, error)
 	GetNetworks(name string) (*compute.NetworkList, error)
+	ListMachineTypes(zone string) (*compute.MachineTypeList, error)
+	ListManagedZones() (*dns.ManagedZonesListResponse, error)
+	ChangeRecordSets(managedZone string, change *dns.Change) (*dns.Change, error)
+	GetChange(managedZone, changeID string) (*dns.Change, error)
+	GetRegion(region string) (*compute.Region, error)
+	TestIAMPermissions(permissions []string) ([]string, error)
 }
 
 type GCPClient struct {
-	service   *compute.Service
-	projectID string
-	zone      string
+	service                 *compute.Service
+	dnsService              *dns.Service
+	httpClient              *http.Client
+	resourceManagerBasePath string
+	projectID               string
+	zone                    string
 }
 
 func (c GCPClient) ProjectID() string {
@@ -40,3 +56,64 @@ func (c GCPClient) GetNetworks(name string) (*compute.NetworkList, error) {
 	networksListCall := c.service.Networks.List(c.projectID)
 	return networksListCall.Filter(fmt.Sprintf("name eq %s", name)).Do()
 }
+
+func (c GCPClient) ListMachineTypes(zone string) (*compute.MachineTypeList, error) {
+	machineTypeList := &compute.MachineTypeList{}
+	err := c.service.MachineTypes.List(c.projectID, zone).Pages(context.Background(), func(page *compute.MachineTypeList) error {
+		machineTypeList.Items = append(machineTypeList.Items, page.Items...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return machineTypeList, nil
+}
+
+func (c GCPClient) ListManagedZones() (*dns.ManagedZonesListResponse, error) {
+	return c.dnsService.ManagedZones.List(c.projectID).Do()
+}
+
+func (c GCPClient) ChangeRecordSets(managedZone string, change *dns.Change) (*dns.Change, error) {
+	return c.dnsService.Changes.Create(c.projectID, managedZone, change).Do()
+}
+
+func (c GCPClient) GetChange(managedZone, changeID string) (*dns.Change, error) {
+	return c.dnsService.Changes.Get(c.projectID, managedZone, changeID).Do()
+}
+
+func (c GCPClient) GetRegion(region string) (*compute.Region, error) {
+	return c.service.Regions.Get(c.projectID, region).Do()
+}
+
+// TestIAMPermissions returns the given permissions that the service account
+// holds on the project. The resource manager has no client in the vendored
+// google apis, so the request is made with the authenticated http client.
+func (c GCPClient) TestIAMPermissions(permissions []string) ([]string, error) {
+	body, err := json.Marshal(map[string][]string{"permissions": permissions})
+	if err != nil {
+		return nil, err
+	}
+
+	url := googleapi.ResolveRelative(c.resourceManagerBasePath, fmt.Sprintf("v1/projects/%s:testIamPermissions", c.projectID))
+	response, err := c.httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer googleapi.CloseBody(response)
+
+	err = googleapi.CheckResponse(response)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Permissions []string `json:"permissions"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Permissions, nil
+}
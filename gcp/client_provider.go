@@ -8,10 +8,16 @@ import (
 	"golang.org/x/oauth2/jwt"
 
 	compute "google.golang.org/api/compute/v1"
+	dns "google.golang.org/api/dns/v1"
 )
 
 const (
 	GoogleComputeAuth = "https://www.googleapis.com/auth/compute"
+	GoogleDNSAuth     = "https://www.googleapis.com/auth/ndev.clouddns.readwrite"
+
+	GoogleCloudPlatformReadOnlyAuth = "https://www.googleapis.com/auth/cloud-platform.read-only"
+
+	resourceManagerBasePath = "https://cloudresourcemanager.googleapis.com/"
 )
 
 func gcpHTTPClientFunc(config *jwt.Config) *http.Client {
@@ -32,29 +38,42 @@ func NewClientProvider(gcpBasePath string) *ClientProvider {
 }
 
 func (p *ClientProvider) SetConfig(serviceAccountKey, projectID, zone string) error {
-	authURL := GoogleComputeAuth
+	scopes := []string{GoogleComputeAuth, GoogleDNSAuth, GoogleCloudPlatformReadOnlyAuth}
 	if p.basePath != "" {
-		authURL = p.basePath
+		scopes = []string{p.basePath}
 	}
 
-	config, err := google.JWTConfigFromJSON([]byte(serviceAccountKey), authURL)
+	config, err := google.JWTConfigFromJSON([]byte(serviceAccountKey), scopes...)
+	if err != nil {
+		return err
+	}
+
+	httpClient := gcpHTTPClient(config)
+
+	service, err := compute.New(httpClient)
 	if err != nil {
 		return err
 	}
 
-	service, err := compute.New(gcpHTTPClient(config))
+	dnsService, err := dns.New(httpClient)
 	if err != nil {
 		return err
 	}
 
+	managerBasePath := resourceManagerBasePath
 	if p.basePath != "" {
 		service.BasePath = p.basePath
+		dnsService.BasePath = p.basePath
+		managerBasePath = p.basePath
 	}
 
 	p.client = GCPClient{
-		service:   service,
-		projectID: projectID,
-		zone:      zone,
+		service:                 service,
+		dnsService:              dnsService,
+		httpClient:              httpClient,
+		resourceManagerBasePath: managerBasePath,
+		projectID:               projectID,
+		zone:                    zone,
 	}
 
 	return nil
@@ -0,0 +1,73 @@
+package gcp_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry/bosh-bootloader/gcp"
+	"golang.org/x/oauth2/jwt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GCPClient", func() {
+	var (
+		server      *httptest.Server
+		requestPath string
+		requestBody string
+		status      int
+		response    string
+
+		client gcp.Client
+	)
+
+	BeforeEach(func() {
+		status = http.StatusOK
+		response = `{"permissions": ["compute.networks.create"]}`
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestPath = r.URL.Path
+			body, _ := ioutil.ReadAll(r.Body)
+			requestBody = string(body)
+
+			w.WriteHeader(status)
+			w.Write([]byte(response))
+		}))
+
+		gcp.SetGCPHTTPClient(func(*jwt.Config) *http.Client {
+			return http.DefaultClient
+		})
+
+		clientProvider := gcp.NewClientProvider(server.URL + "/")
+		err := clientProvider.SetConfig(`{"type": "service_account"}`, "some-project-id", "some-zone")
+		Expect(err).NotTo(HaveOccurred())
+
+		client = clientProvider.Client()
+	})
+
+	AfterEach(func() {
+		server.Close()
+		gcp.ResetGCPHTTPClient()
+	})
+
+	Describe("TestIAMPermissions", func() {
+		It("returns the permissions the service account holds on the project", func() {
+			permissions, err := client.TestIAMPermissions([]string{"compute.networks.create", "dns.managedZones.create"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(permissions).To(Equal([]string{"compute.networks.create"}))
+			Expect(requestPath).To(Equal("/v1/projects/some-project-id:testIamPermissions"))
+			Expect(requestBody).To(MatchJSON(`{"permissions": ["compute.networks.create", "dns.managedZones.create"]}`))
+		})
+
+		It("returns an error when the request fails", func() {
+			status = http.StatusForbidden
+			response = `{"error": {"code": 403, "message": "Cloud Resource Manager API has not been used"}}`
+
+			_, err := client.TestIAMPermissions([]string{"compute.networks.create"})
+			Expect(err).To(MatchError(ContainSubstring("Cloud Resource Manager API has not been used")))
+		})
+	})
+})
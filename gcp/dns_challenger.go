@@ -0,0 +1,98 @@
+package gcp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	dns "google.golang.org/api/dns/v1"
+)
+
+var sleep = time.Sleep
+
+// DNSChallenger answers ACME dns-01 challenges with TXT records in the
+// public Cloud DNS managed zone the domain resolves through.
+type DNSChallenger struct {
+	clientProvider clientProvider
+}
+
+func NewDNSChallenger(clientProvider clientProvider) DNSChallenger {
+	return DNSChallenger{
+		clientProvider: clientProvider,
+	}
+}
+
+func (d DNSChallenger) Present(domain, fqdn, value string) error {
+	return d.change(domain, &dns.Change{Additions: []*dns.ResourceRecordSet{challengeRecord(fqdn, value)}})
+}
+
+func (d DNSChallenger) CleanUp(domain, fqdn, value string) error {
+	return d.change(domain, &dns.Change{Deletions: []*dns.ResourceRecordSet{challengeRecord(fqdn, value)}})
+}
+
+func challengeRecord(fqdn, value string) *dns.ResourceRecordSet {
+	return &dns.ResourceRecordSet{
+		Name:    fqdn,
+		Type:    "TXT",
+		Ttl:     60,
+		Rrdatas: []string{fmt.Sprintf("%q", value)},
+	}
+}
+
+func (d DNSChallenger) change(domain string, change *dns.Change) error {
+	client := d.clientProvider.Client()
+
+	managedZone, err := d.managedZone(client, domain)
+	if err != nil {
+		return err
+	}
+
+	change, err = client.ChangeRecordSets(managedZone, change)
+	if err != nil {
+		return err
+	}
+
+	for change.Status != "done" {
+		sleep(5 * time.Second)
+
+		change, err = client.GetChange(managedZone, change.Id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// managedZone returns the public managed zone closest to the domain: the
+// zone of the domain itself once bbl has created and delegated it, or the
+// parent zone it is delegated from when the lb is being created.
+func (DNSChallenger) managedZone(client Client, domain string) (string, error) {
+	dnsName := strings.TrimSuffix(domain, ".") + "."
+
+	zones, err := client.ListManagedZones()
+	if err != nil {
+		return "", err
+	}
+
+	var closest *dns.ManagedZone
+	for _, zone := range zones.ManagedZones {
+		if zone.Visibility == "private" {
+			continue
+		}
+
+		if zone.DnsName != dnsName && !strings.HasSuffix(dnsName, "."+zone.DnsName) {
+			continue
+		}
+
+		if closest == nil || len(zone.DnsName) > len(closest.DnsName) {
+			closest = zone
+		}
+	}
+
+	if closest == nil {
+		return "", fmt.Errorf("no public cloud dns managed zone has been found for %q or its parent domains, the acme challenges need a zone the domain resolves through", domain)
+	}
+
+	return closest.Name, nil
+}
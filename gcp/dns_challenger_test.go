@@ -0,0 +1,121 @@
+package gcp_test
+
+import (
+	"errors"
+	"time"
+
+	dns "google.golang.org/api/dns/v1"
+
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/gcp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DNSChallenger", func() {
+	var (
+		challenger        gcp.DNSChallenger
+		gcpClientProvider *fakes.GCPClientProvider
+		gcpClient         *fakes.GCPClient
+		sleeps            []time.Duration
+	)
+
+	BeforeEach(func() {
+		gcpClientProvider = &fakes.GCPClientProvider{}
+		gcpClient = &fakes.GCPClient{}
+		gcpClientProvider.ClientCall.Returns.Client = gcpClient
+
+		gcpClient.ListManagedZonesCall.Returns.ManagedZones = &dns.ManagedZonesListResponse{
+			ManagedZones: []*dns.ManagedZone{
+				{Name: "other-zone", DnsName: "other.example.com."},
+				{Name: "some-zone", DnsName: "example.com."},
+			},
+		}
+		gcpClient.ChangeRecordSetsCall.Returns.Change = &dns.Change{Id: "some-change-id", Status: "done"}
+
+		sleeps = nil
+		gcp.SetSleep(func(d time.Duration) {
+			sleeps = append(sleeps, d)
+		})
+
+		challenger = gcp.NewDNSChallenger(gcpClientProvider)
+	})
+
+	AfterEach(func() {
+		gcp.ResetSleep()
+	})
+
+	Describe("Present", func() {
+		It("adds the challenge record to the managed zone of the domain", func() {
+			err := challenger.Present("example.com", "_acme-challenge.example.com.", "some-value")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(gcpClient.ChangeRecordSetsCall.Receives.ManagedZone).To(Equal("some-zone"))
+			Expect(gcpClient.ChangeRecordSetsCall.Receives.Change.Additions).To(Equal([]*dns.ResourceRecordSet{{
+				Name:    "_acme-challenge.example.com.",
+				Type:    "TXT",
+				Ttl:     60,
+				Rrdatas: []string{`"some-value"`},
+			}}))
+			Expect(gcpClient.GetChangeCall.CallCount).To(Equal(0))
+		})
+
+		It("waits until the change is done", func() {
+			gcpClient.ChangeRecordSetsCall.Returns.Change = &dns.Change{Id: "some-change-id", Status: "pending"}
+			gcpClient.GetChangeCall.Returns.Change = &dns.Change{Id: "some-change-id", Status: "done"}
+
+			err := challenger.Present("example.com", "_acme-challenge.example.com.", "some-value")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sleeps).To(Equal([]time.Duration{5 * time.Second}))
+			Expect(gcpClient.GetChangeCall.Receives.ManagedZone).To(Equal("some-zone"))
+			Expect(gcpClient.GetChangeCall.Receives.ChangeID).To(Equal("some-change-id"))
+		})
+
+		It("adds the challenge record to the parent zone when the domain has no zone yet", func() {
+			err := challenger.Present("cf.example.com", "_acme-challenge.cf.example.com.", "some-value")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(gcpClient.ChangeRecordSetsCall.Receives.ManagedZone).To(Equal("some-zone"))
+		})
+
+		It("prefers the zone of the domain over its parent zone", func() {
+			err := challenger.Present("other.example.com", "_acme-challenge.other.example.com.", "some-value")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(gcpClient.ChangeRecordSetsCall.Receives.ManagedZone).To(Equal("other-zone"))
+		})
+
+		It("ignores private zones", func() {
+			gcpClient.ListManagedZonesCall.Returns.ManagedZones.ManagedZones = append(gcpClient.ListManagedZonesCall.Returns.ManagedZones.ManagedZones,
+				&dns.ManagedZone{Name: "private-zone", DnsName: "cf.example.com.", Visibility: "private"})
+
+			err := challenger.Present("cf.example.com", "_acme-challenge.cf.example.com.", "some-value")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(gcpClient.ChangeRecordSetsCall.Receives.ManagedZone).To(Equal("some-zone"))
+		})
+
+		It("returns an error when there is no public managed zone for the domain", func() {
+			err := challenger.Present("example.org", "_acme-challenge.example.org.", "some-value")
+			Expect(err).To(MatchError(`no public cloud dns managed zone has been found for "example.org" or its parent domains, the acme challenges need a zone the domain resolves through`))
+		})
+
+		It("returns an error when the managed zones cannot be listed", func() {
+			gcpClient.ListManagedZonesCall.Returns.Error = errors.New("failed to list")
+
+			err := challenger.Present("example.com", "_acme-challenge.example.com.", "some-value")
+			Expect(err).To(MatchError("failed to list"))
+		})
+	})
+
+	Describe("CleanUp", func() {
+		It("deletes the challenge record", func() {
+			err := challenger.CleanUp("example.com", "_acme-challenge.example.com.", "some-value")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(gcpClient.ChangeRecordSetsCall.Receives.Change.Additions).To(BeEmpty())
+			Expect(gcpClient.ChangeRecordSetsCall.Receives.Change.Deletions).To(HaveLen(1))
+		})
+	})
+})
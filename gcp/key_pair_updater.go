@@ -1,30 +1,23 @@
 package gcp
 
 import (
-	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
 	"fmt"
-	"io"
 	"strings"
 
 	compute "google.golang.org/api/compute/v1"
 
 	"github.com/cloudfoundry/bosh-bootloader/storage"
-
-	"golang.org/x/crypto/ssh"
 )
 
 type KeyPairUpdater struct {
-	random                io.Reader
-	rsaKeyGenerator       rsaKeyGenerator
-	sshPublicKeyGenerator sshPublicKeyGenerator
-	clientProvider        clientProvider
-	logger                logger
+	keyPairGenerator keyPairGenerator
+	clientProvider   clientProvider
+	logger           logger
 }
 
-type rsaKeyGenerator func(io.Reader, int) (*rsa.PrivateKey, error)
-type sshPublicKeyGenerator func(interface{}) (ssh.PublicKey, error)
+type keyPairGenerator interface {
+	Generate(keyType string, bits int) (string, string, error)
+}
 
 type clientProvider interface {
 	Client() Client
@@ -34,20 +27,22 @@ type logger interface {
 	Step(string, ...interface{})
 }
 
-func NewKeyPairUpdater(random io.Reader, generateRSAKey rsaKeyGenerator, generateSSHPublicKey sshPublicKeyGenerator, clientProvider clientProvider, logger logger) KeyPairUpdater {
+func NewKeyPairUpdater(keyPairGenerator keyPairGenerator, clientProvider clientProvider, logger logger) KeyPairUpdater {
 	return KeyPairUpdater{
-		random:                random,
-		rsaKeyGenerator:       generateRSAKey,
-		sshPublicKeyGenerator: generateSSHPublicKey,
-		clientProvider:        clientProvider,
-		logger:                logger,
+		keyPairGenerator: keyPairGenerator,
+		clientProvider:   clientProvider,
+		logger:           logger,
 	}
 }
 
-func (k KeyPairUpdater) Update() (storage.KeyPair, error) {
-	privateKey, publicKey, err := k.createKeyPair()
-	if err != nil {
-		return storage.KeyPair{}, err
+func (k KeyPairUpdater) Update(keyPair storage.KeyPair, keyType string, keyBits int) (storage.KeyPair, error) {
+	privateKey, publicKey := keyPair.PrivateKey, keyPair.PublicKey
+	if publicKey == "" {
+		var err error
+		privateKey, publicKey, err = k.keyPairGenerator.Generate(keyType, keyBits)
+		if err != nil {
+			return storage.KeyPair{}, err
+		}
 	}
 
 	client := k.clientProvider.Client()
@@ -92,27 +87,3 @@ func (k KeyPairUpdater) Update() (storage.KeyPair, error) {
 		PublicKey:  publicKey,
 	}, nil
 }
-
-func (keyPairUpdater KeyPairUpdater) createKeyPair() (string, string, error) {
-	rsaKey, err := keyPairUpdater.rsaKeyGenerator(keyPairUpdater.random, 2048)
-	if err != nil {
-		return "", "", err
-	}
-
-	publicKey, err := keyPairUpdater.sshPublicKeyGenerator(rsaKey.Public())
-	if err != nil {
-		return "", "", err
-	}
-
-	rawPublicKey := string(ssh.MarshalAuthorizedKey(publicKey))
-	rawPublicKey = strings.TrimSuffix(rawPublicKey, "\n")
-
-	privateKey := pem.EncodeToMemory(
-		&pem.Block{
-			Type:  "RSA PRIVATE KEY",
-			Bytes: x509.MarshalPKCS1PrivateKey(rsaKey),
-		},
-	)
-
-	return string(privateKey), rawPublicKey, nil
-}
@@ -1,32 +1,28 @@
 package gcp_test
 
 import (
-	"crypto/rand"
-	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
 	"errors"
-	"io"
-	"strings"
 
 	compute "google.golang.org/api/compute/v1"
 
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
 	"github.com/cloudfoundry/bosh-bootloader/gcp"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
-	"golang.org/x/crypto/ssh"
 )
 
 var _ = Describe("KeyPairUpdater", func() {
 	var (
 		keyPairUpdater    gcp.KeyPairUpdater
+		keyPairGenerator  *fakes.SSHKeyPairGenerator
 		gcpClientProvider *fakes.GCPClientProvider
 		gcpClient         *fakes.GCPClient
 		logger            *fakes.Logger
 	)
 
 	BeforeEach(func() {
+		keyPairGenerator = &fakes.SSHKeyPairGenerator{}
 		gcpClientProvider = &fakes.GCPClientProvider{}
 		gcpClient = &fakes.GCPClient{}
 		logger = &fakes.Logger{}
@@ -39,36 +35,44 @@ var _ = Describe("KeyPairUpdater", func() {
 			},
 		}
 		gcpClient.ProjectIDCall.Returns.ProjectID = "some-project-id"
-		keyPairUpdater = gcp.NewKeyPairUpdater(rand.Reader, rsa.GenerateKey, ssh.NewPublicKey, gcpClientProvider, logger)
+		keyPairGenerator.GenerateCall.Returns.PrivateKey = "some-private-key"
+		keyPairGenerator.GenerateCall.Returns.PublicKey = "ssh-rsa some-public-key"
+
+		keyPairUpdater = gcp.NewKeyPairUpdater(keyPairGenerator, gcpClientProvider, logger)
 	})
 
 	It("generates a keypair", func() {
-		keyPair, err := keyPairUpdater.Update()
-		Expect(err).NotTo(HaveOccurred())
-		Expect(keyPair.PrivateKey).NotTo(BeEmpty())
-		Expect(keyPair.PublicKey).NotTo(BeEmpty())
-		Expect(keyPair.PublicKey).NotTo(ContainSubstring("\n"))
-
-		pemBlock, rest := pem.Decode([]byte(keyPair.PrivateKey))
-		Expect(rest).To(HaveLen(0))
-		Expect(pemBlock.Type).To(Equal("RSA PRIVATE KEY"))
-
-		parsedPrivateKey, err := x509.ParsePKCS1PrivateKey(pemBlock.Bytes)
-		Expect(err).NotTo(HaveOccurred())
-
-		err = parsedPrivateKey.Validate()
-		Expect(err).NotTo(HaveOccurred())
-
-		newPublicKey, err := ssh.NewPublicKey(parsedPrivateKey.Public())
+		keyPair, err := keyPairUpdater.Update(storage.KeyPair{}, "rsa", 4096)
 		Expect(err).NotTo(HaveOccurred())
 
-		rawPublicKey := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(newPublicKey)), "\n")
+		Expect(keyPairGenerator.GenerateCall.CallCount).To(Equal(1))
+		Expect(keyPairGenerator.GenerateCall.Receives.KeyType).To(Equal("rsa"))
+		Expect(keyPairGenerator.GenerateCall.Receives.Bits).To(Equal(4096))
+		Expect(keyPair).To(Equal(storage.KeyPair{
+			PrivateKey: "some-private-key",
+			PublicKey:  "ssh-rsa some-public-key",
+		}))
+	})
 
-		Expect(rawPublicKey).To(Equal(keyPair.PublicKey))
+	Context("when a keypair is provided", func() {
+		It("adds the provided public key to the project metadata", func() {
+			keyPair, err := keyPairUpdater.Update(storage.KeyPair{
+				PrivateKey: "some-provided-private-key",
+				PublicKey:  "ssh-ed25519 some-provided-public-key",
+			}, "", 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(keyPairGenerator.GenerateCall.CallCount).To(Equal(0))
+			Expect(keyPair).To(Equal(storage.KeyPair{
+				PrivateKey: "some-provided-private-key",
+				PublicKey:  "ssh-ed25519 some-provided-public-key",
+			}))
+			Expect(*gcpClient.SetCommonInstanceMetadataCall.Receives.Metadata.Items[0].Value).To(Equal("vcap:ssh-ed25519 some-provided-public-key vcap"))
+		})
 	})
 
 	It("retrieves the project for the given project id", func() {
-		_, err := keyPairUpdater.Update()
+		_, err := keyPairUpdater.Update(storage.KeyPair{}, "", 0)
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(gcpClientProvider.ClientCall.CallCount).To(Equal(1))
@@ -77,7 +81,7 @@ var _ = Describe("KeyPairUpdater", func() {
 	})
 
 	It("updates common metadata for given project id", func() {
-		_, err := keyPairUpdater.Update()
+		_, err := keyPairUpdater.Update(storage.KeyPair{}, "", 0)
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(gcpClient.SetCommonInstanceMetadataCall.CallCount).To(Equal(1))
@@ -106,7 +110,7 @@ var _ = Describe("KeyPairUpdater", func() {
 				},
 			},
 		}
-		_, err := keyPairUpdater.Update()
+		_, err := keyPairUpdater.Update(storage.KeyPair{}, "", 0)
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(gcpClient.SetCommonInstanceMetadataCall.Receives.Metadata.Items).To(HaveLen(2))
@@ -119,38 +123,24 @@ var _ = Describe("KeyPairUpdater", func() {
 	})
 
 	Context("failure cases", func() {
-		It("returns an error when the rsaKeyGenerator fails", func() {
-			keyPairUpdater = gcp.NewKeyPairUpdater(rand.Reader,
-				func(_ io.Reader, _ int) (*rsa.PrivateKey, error) {
-					return nil, errors.New("rsa key generator failed")
-				},
-				ssh.NewPublicKey, gcpClientProvider, logger)
-
-			_, err := keyPairUpdater.Update()
-			Expect(err).To(MatchError("rsa key generator failed"))
-		})
-
-		It("returns an error when the ssh public key generator fails", func() {
-			keyPairUpdater = gcp.NewKeyPairUpdater(rand.Reader, rsa.GenerateKey,
-				func(_ interface{}) (ssh.PublicKey, error) {
-					return nil, errors.New("ssh public key gen failed")
-				}, gcpClientProvider, logger)
+		It("returns an error when the keypair cannot be generated", func() {
+			keyPairGenerator.GenerateCall.Returns.Error = errors.New("keypair generator failed")
 
-			_, err := keyPairUpdater.Update()
-			Expect(err).To(MatchError("ssh public key gen failed"))
+			_, err := keyPairUpdater.Update(storage.KeyPair{}, "", 0)
+			Expect(err).To(MatchError("keypair generator failed"))
 		})
 
 		It("returns an error when project could not be found", func() {
 			gcpClient.GetProjectCall.Returns.Error = errors.New("project could not be found")
 
-			_, err := keyPairUpdater.Update()
+			_, err := keyPairUpdater.Update(storage.KeyPair{}, "", 0)
 			Expect(err).To(MatchError("project could not be found"))
 		})
 
 		It("returns an error when set common instance metadata fails", func() {
 			gcpClient.SetCommonInstanceMetadataCall.Returns.Error = errors.New("updating ssh-key failed")
 
-			_, err := keyPairUpdater.Update()
+			_, err := keyPairUpdater.Update(storage.KeyPair{}, "", 0)
 			Expect(err).To(MatchError("updating ssh-key failed"))
 		})
 	})
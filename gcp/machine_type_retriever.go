@@ -0,0 +1,25 @@
+package gcp
+
+type MachineTypeRetriever struct {
+	clientProvider clientProvider
+}
+
+func NewMachineTypeRetriever(clientProvider clientProvider) MachineTypeRetriever {
+	return MachineTypeRetriever{
+		clientProvider: clientProvider,
+	}
+}
+
+func (m MachineTypeRetriever) Retrieve(zone string) ([]string, error) {
+	machineTypeList, err := m.clientProvider.Client().ListMachineTypes(zone)
+	if err != nil {
+		return []string{}, err
+	}
+
+	machineTypes := []string{}
+	for _, machineType := range machineTypeList.Items {
+		machineTypes = append(machineTypes, machineType.Name)
+	}
+
+	return machineTypes, nil
+}
@@ -0,0 +1,53 @@
+package gcp_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/gcp"
+	compute "google.golang.org/api/compute/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MachineTypeRetriever", func() {
+	var (
+		client               *fakes.GCPClient
+		gcpClientProvider    *fakes.GCPClientProvider
+		machineTypeRetriever gcp.MachineTypeRetriever
+	)
+
+	BeforeEach(func() {
+		client = &fakes.GCPClient{}
+		gcpClientProvider = &fakes.GCPClientProvider{}
+		gcpClientProvider.ClientCall.Returns.Client = client
+		machineTypeRetriever = gcp.NewMachineTypeRetriever(gcpClientProvider)
+	})
+
+	Describe("Retrieve", func() {
+		It("returns the names of the machine types available in the zone", func() {
+			client.ListMachineTypesCall.Returns.MachineTypeList = &compute.MachineTypeList{
+				Items: []*compute.MachineType{
+					{Name: "n1-standard-1"},
+					{Name: "n1-standard-2"},
+				},
+			}
+
+			machineTypes, err := machineTypeRetriever.Retrieve("some-zone")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.ListMachineTypesCall.Receives.Zone).To(Equal("some-zone"))
+			Expect(machineTypes).To(Equal([]string{"n1-standard-1", "n1-standard-2"}))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the machine types cannot be listed", func() {
+				client.ListMachineTypesCall.Returns.Error = errors.New("failed to list machine types")
+
+				_, err := machineTypeRetriever.Retrieve("some-zone")
+				Expect(err).To(MatchError("failed to list machine types"))
+			})
+		})
+	})
+})
@@ -0,0 +1,257 @@
+package gcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+	gcpterraform "github.com/cloudfoundry/bosh-bootloader/terraform/gcp"
+)
+
+const (
+	ComputeAPI = "compute.googleapis.com"
+	DNSAPI     = "dns.googleapis.com"
+
+	accessNotConfiguredReason = "accessNotConfigured"
+
+	// maxTestedPermissions is the number of permissions the resource
+	// manager tests in a single request.
+	maxTestedPermissions = 100
+)
+
+// regionalQuotaMetrics maps the resources of the terraform templates that
+// live in the region to the compute quota that limits them. Quotas the
+// region does not report are looked up in the project quotas.
+var regionalQuotaMetrics = map[string]string{
+	"google_compute_address":         "STATIC_ADDRESSES",
+	"google_compute_forwarding_rule": "FORWARDING_RULES",
+	"google_compute_target_pool":     "TARGET_POOLS",
+}
+
+// projectQuotaMetrics maps the global resources of the terraform templates
+// to the compute quota of the project that limits them.
+var projectQuotaMetrics = map[string]string{
+	"google_compute_backend_service":        "BACKEND_SERVICES",
+	"google_compute_firewall":               "FIREWALLS",
+	"google_compute_global_address":         "STATIC_ADDRESSES",
+	"google_compute_global_forwarding_rule": "FORWARDING_RULES",
+	"google_compute_http_health_check":      "HEALTH_CHECKS",
+	"google_compute_network":                "NETWORKS",
+	"google_compute_ssl_certificate":        "SSL_CERTIFICATES",
+	"google_compute_subnetwork":             "SUBNETWORKS",
+	"google_compute_target_http_proxy":      "TARGET_HTTP_PROXIES",
+	"google_compute_target_https_proxy":     "TARGET_HTTPS_PROXIES",
+	"google_compute_url_map":                "URL_MAPS",
+}
+
+type templateGenerator interface {
+	Generate(storage.State) string
+}
+
+type PreflightChecker struct {
+	clientProvider    clientProvider
+	templateGenerator templateGenerator
+}
+
+type quotaKey struct {
+	scope  string
+	metric string
+}
+
+func NewPreflightChecker(clientProvider clientProvider, templateGenerator templateGenerator) PreflightChecker {
+	return PreflightChecker{
+		clientProvider:    clientProvider,
+		templateGenerator: templateGenerator,
+	}
+}
+
+// ValidateServiceAccountKey checks that the key is the json key of a
+// service account rather than, for example, user credentials.
+func (p PreflightChecker) ValidateServiceAccountKey(serviceAccountKey string) error {
+	var key struct {
+		Type        string `json:"type"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+	}
+	err := json.Unmarshal([]byte(serviceAccountKey), &key)
+	if err != nil {
+		return fmt.Errorf("the GCP service account key is not valid json: %s", err)
+	}
+
+	if key.Type != "service_account" {
+		return fmt.Errorf("the GCP service account key has type %q, a key of type \"service_account\" is required", key.Type)
+	}
+
+	if key.ClientEmail == "" || key.PrivateKey == "" {
+		return errors.New("the GCP service account key is missing its client_email or private_key")
+	}
+
+	return nil
+}
+
+// DisabledAPIs returns the APIs bbl calls for the state that are not
+// enabled in the project. The DNS API is only needed by lbs with a domain.
+func (p PreflightChecker) DisabledAPIs(state storage.State) ([]string, error) {
+	client := p.clientProvider.Client()
+
+	var disabled []string
+	_, err := client.GetRegion(state.GCP.Region)
+	switch {
+	case isAccessNotConfigured(err):
+		disabled = append(disabled, ComputeAPI)
+	case err != nil:
+		return nil, err
+	}
+
+	if needsDNS(state) {
+		_, err = client.ListManagedZones()
+		switch {
+		case isAccessNotConfigured(err):
+			disabled = append(disabled, DNSAPI)
+		case err != nil:
+			return nil, err
+		}
+	}
+
+	return disabled, nil
+}
+
+// MissingPermissions returns the given permissions that the service account
+// does not hold on the project, sorted.
+func (p PreflightChecker) MissingPermissions(permissions []string) ([]string, error) {
+	client := p.clientProvider.Client()
+
+	granted := map[string]bool{}
+	for start := 0; start < len(permissions); start += maxTestedPermissions {
+		end := start + maxTestedPermissions
+		if end > len(permissions) {
+			end = len(permissions)
+		}
+
+		held, err := client.TestIAMPermissions(permissions[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("failed to test the iam permissions of the service account: %s", err)
+		}
+
+		for _, permission := range held {
+			granted[permission] = true
+		}
+	}
+
+	var missing []string
+	for _, permission := range permissions {
+		if !granted[permission] {
+			missing = append(missing, permission)
+		}
+	}
+
+	sort.Strings(missing)
+
+	return missing, nil
+}
+
+// QuotaShortfalls returns a line for each compute quota that cannot hold the
+// resources the terraform templates of the state still have to create.
+// Quotas the region and the project do not report are not checked.
+func (p PreflightChecker) QuotaShortfalls(state storage.State) ([]string, error) {
+	resources, err := gcpterraform.NewResources(p.templateGenerator.Generate(state), state.TFState)
+	if err != nil {
+		return nil, err
+	}
+
+	client := p.clientProvider.Client()
+
+	region, err := client.GetRegion(state.GCP.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := client.GetProject()
+	if err != nil {
+		return nil, err
+	}
+
+	regionQuotas := quotasByMetric(region.Quotas)
+	projectQuotas := quotasByMetric(project.Quotas)
+
+	needed := map[quotaKey]int{}
+	for resourceType, count := range resources {
+		if metric, ok := regionalQuotaMetrics[resourceType]; ok {
+			if _, ok := regionQuotas[metric]; ok {
+				needed[quotaKey{scope: state.GCP.Region, metric: metric}] += count
+				continue
+			}
+
+			if _, ok := projectQuotas[metric]; ok {
+				needed[quotaKey{metric: metric}] += count
+			}
+			continue
+		}
+
+		if metric, ok := projectQuotaMetrics[resourceType]; ok {
+			if _, ok := projectQuotas[metric]; ok {
+				needed[quotaKey{metric: metric}] += count
+			}
+		}
+	}
+
+	var shortfalls []string
+	for key, count := range needed {
+		quota, scope := projectQuotas[key.metric], "the project"
+		if key.scope != "" {
+			quota, scope = regionQuotas[key.metric], key.scope
+		}
+
+		available := int(quota.Limit - quota.Usage)
+		if count > available {
+			shortfalls = append(shortfalls, fmt.Sprintf("%s in %s: %d needed, %d of %.0f available", key.metric, scope, count, available, quota.Limit))
+		}
+	}
+
+	sort.Strings(shortfalls)
+
+	return shortfalls, nil
+}
+
+func quotasByMetric(quotas []*compute.Quota) map[string]*compute.Quota {
+	byMetric := map[string]*compute.Quota{}
+	for _, quota := range quotas {
+		byMetric[quota.Metric] = quota
+	}
+
+	return byMetric
+}
+
+func isAccessNotConfigured(err error) bool {
+	apiErr, ok := err.(*googleapi.Error)
+	if !ok {
+		return false
+	}
+
+	for _, item := range apiErr.Errors {
+		if item.Reason == accessNotConfiguredReason {
+			return true
+		}
+	}
+
+	return false
+}
+
+func needsDNS(state storage.State) bool {
+	if state.LB.Domain != "" {
+		return true
+	}
+
+	for _, spec := range state.LBs {
+		if spec.Domain != "" {
+			return true
+		}
+	}
+
+	return false
+}
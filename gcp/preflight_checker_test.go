@@ -0,0 +1,208 @@
+package gcp_test
+
+import (
+	"errors"
+
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/gcp"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PreflightChecker", func() {
+	var (
+		gcpClientProvider *fakes.GCPClientProvider
+		gcpClient         *fakes.GCPClient
+		templateGenerator *fakes.TemplateGenerator
+		checker           gcp.PreflightChecker
+
+		state           storage.State
+		accessNotConfig *googleapi.Error
+	)
+
+	BeforeEach(func() {
+		gcpClientProvider = &fakes.GCPClientProvider{}
+		gcpClient = &fakes.GCPClient{}
+		gcpClientProvider.ClientCall.Returns.Client = gcpClient
+		templateGenerator = &fakes.TemplateGenerator{}
+
+		state = storage.State{
+			IAAS: "gcp",
+			GCP: storage.GCP{
+				Region: "some-region",
+			},
+		}
+
+		accessNotConfig = &googleapi.Error{
+			Code:   403,
+			Errors: []googleapi.ErrorItem{{Reason: "accessNotConfigured"}},
+		}
+
+		checker = gcp.NewPreflightChecker(gcpClientProvider, templateGenerator)
+	})
+
+	Describe("ValidateServiceAccountKey", func() {
+		It("accepts the key of a service account", func() {
+			err := checker.ValidateServiceAccountKey(`{"type": "service_account", "client_email": "bbl@example.com", "private_key": "some-private-key"}`)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns an error when the key is not json", func() {
+			err := checker.ValidateServiceAccountKey("%%%")
+			Expect(err).To(MatchError("the GCP service account key is not valid json: invalid character '%' looking for beginning of value"))
+		})
+
+		It("returns an error when the key is not a service account key", func() {
+			err := checker.ValidateServiceAccountKey(`{"type": "authorized_user"}`)
+			Expect(err).To(MatchError(`the GCP service account key has type "authorized_user", a key of type "service_account" is required`))
+		})
+
+		It("returns an error when the key is missing its private key", func() {
+			err := checker.ValidateServiceAccountKey(`{"type": "service_account", "client_email": "bbl@example.com"}`)
+			Expect(err).To(MatchError("the GCP service account key is missing its client_email or private_key"))
+		})
+	})
+
+	Describe("DisabledAPIs", func() {
+		It("returns no apis when compute is enabled", func() {
+			disabled, err := checker.DisabledAPIs(state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(disabled).To(BeEmpty())
+			Expect(gcpClient.GetRegionCall.Receives.Region).To(Equal("some-region"))
+			Expect(gcpClient.ListManagedZonesCall.CallCount).To(Equal(0))
+		})
+
+		It("returns the compute api when it is not enabled", func() {
+			gcpClient.GetRegionCall.Returns.Error = accessNotConfig
+
+			disabled, err := checker.DisabledAPIs(state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(disabled).To(Equal([]string{"compute.googleapis.com"}))
+		})
+
+		It("returns the dns api when an lb with a domain needs it", func() {
+			state.LBs = []storage.LBSpec{{Name: "some-lb", Domain: "example.com"}}
+			gcpClient.ListManagedZonesCall.Returns.Error = accessNotConfig
+
+			disabled, err := checker.DisabledAPIs(state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(disabled).To(Equal([]string{"dns.googleapis.com"}))
+		})
+
+		It("returns an error when the region cannot be retrieved", func() {
+			gcpClient.GetRegionCall.Returns.Error = errors.New("failed to get region")
+
+			_, err := checker.DisabledAPIs(state)
+			Expect(err).To(MatchError("failed to get region"))
+		})
+	})
+
+	Describe("MissingPermissions", func() {
+		It("returns the permissions the service account does not hold", func() {
+			gcpClient.TestIAMPermissionsCall.Returns.Permissions = []string{"compute.networks.create"}
+
+			missing, err := checker.MissingPermissions([]string{"compute.networks.create", "dns.managedZones.create", "compute.addresses.create"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(missing).To(Equal([]string{"compute.addresses.create", "dns.managedZones.create"}))
+			Expect(gcpClient.TestIAMPermissionsCall.Receives.Permissions).To(Equal([]string{"compute.networks.create", "dns.managedZones.create", "compute.addresses.create"}))
+		})
+
+		It("tests the required permissions in batches", func() {
+			permissions := make([]string, 150)
+			for i := range permissions {
+				permissions[i] = "compute.networks.create"
+			}
+
+			_, err := checker.MissingPermissions(permissions)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(gcpClient.TestIAMPermissionsCall.CallCount).To(Equal(2))
+			Expect(gcpClient.TestIAMPermissionsCall.Receives.Permissions).To(HaveLen(50))
+		})
+
+		It("returns an error when the permissions cannot be tested", func() {
+			gcpClient.TestIAMPermissionsCall.Returns.Error = errors.New("api not enabled")
+
+			_, err := checker.MissingPermissions([]string{"compute.networks.create"})
+			Expect(err).To(MatchError("failed to test the iam permissions of the service account: api not enabled"))
+		})
+	})
+
+	Describe("QuotaShortfalls", func() {
+		BeforeEach(func() {
+			templateGenerator.GenerateCall.Returns.Template = `resource "google_compute_network" "bbl-network" {
+}
+
+resource "google_compute_address" "bosh-external-ip" {
+}
+
+resource "google_compute_address" "cf-ws" {
+}
+
+resource "google_compute_forwarding_rule" "cf-ws-https" {
+}
+
+resource "google_compute_global_forwarding_rule" "cf-http-forwarding-rule" {
+}
+`
+			gcpClient.GetRegionCall.Returns.Region = &compute.Region{
+				Quotas: []*compute.Quota{
+					{Metric: "STATIC_ADDRESSES", Limit: 8, Usage: 7},
+					{Metric: "CPUS", Limit: 24, Usage: 0},
+				},
+			}
+			gcpClient.GetProjectCall.Returns.Project = &compute.Project{
+				Quotas: []*compute.Quota{
+					{Metric: "NETWORKS", Limit: 5, Usage: 1},
+					{Metric: "FORWARDING_RULES", Limit: 15, Usage: 14},
+				},
+			}
+		})
+
+		It("returns the quotas that cannot hold the resources of the templates", func() {
+			shortfalls, err := checker.QuotaShortfalls(state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(templateGenerator.GenerateCall.Receives.State).To(Equal(state))
+			Expect(shortfalls).To(Equal([]string{
+				"FORWARDING_RULES in the project: 2 needed, 1 of 15 available",
+				"STATIC_ADDRESSES in some-region: 2 needed, 1 of 8 available",
+			}))
+		})
+
+		It("does not count the resources that already exist", func() {
+			state.TFState = `{"modules": [{"resources": {
+				"google_compute_address.bosh-external-ip": {},
+				"google_compute_forwarding_rule.cf-ws-https": {}
+			}}]}`
+
+			shortfalls, err := checker.QuotaShortfalls(state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(shortfalls).To(BeEmpty())
+		})
+
+		It("returns an error when the region cannot be retrieved", func() {
+			gcpClient.GetRegionCall.Returns.Error = errors.New("failed to get region")
+
+			_, err := checker.QuotaShortfalls(state)
+			Expect(err).To(MatchError("failed to get region"))
+		})
+
+		It("returns an error when the project cannot be retrieved", func() {
+			gcpClient.GetProjectCall.Returns.Error = errors.New("failed to get project")
+
+			_, err := checker.QuotaShortfalls(state)
+			Expect(err).To(MatchError("failed to get project"))
+		})
+	})
+})
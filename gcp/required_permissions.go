@@ -0,0 +1,97 @@
+package gcp
+
+// RequiredPermissions lists the permissions that bbl, the terraform
+// templates and the BOSH Google CPI use on the project on behalf of the
+// service account. They are all part of roles/editor.
+var RequiredPermissions = []string{
+	// bbl
+	"compute.instances.list",
+	"compute.machineTypes.list",
+	"compute.networks.list",
+	"compute.projects.get",
+	"compute.projects.setCommonInstanceMetadata",
+	"compute.regions.get",
+	"dns.changes.create",
+	"dns.changes.get",
+	"dns.managedZones.list",
+
+	// networking and load balancers
+	"compute.addresses.create",
+	"compute.addresses.delete",
+	"compute.addresses.get",
+	"compute.backendServices.create",
+	"compute.backendServices.delete",
+	"compute.backendServices.get",
+	"compute.firewalls.create",
+	"compute.firewalls.delete",
+	"compute.firewalls.get",
+	"compute.forwardingRules.create",
+	"compute.forwardingRules.delete",
+	"compute.forwardingRules.get",
+	"compute.globalAddresses.create",
+	"compute.globalAddresses.delete",
+	"compute.globalAddresses.get",
+	"compute.globalForwardingRules.create",
+	"compute.globalForwardingRules.delete",
+	"compute.globalForwardingRules.get",
+	"compute.httpHealthChecks.create",
+	"compute.httpHealthChecks.delete",
+	"compute.httpHealthChecks.get",
+	"compute.instanceGroups.create",
+	"compute.instanceGroups.delete",
+	"compute.instanceGroups.get",
+	"compute.networks.create",
+	"compute.networks.delete",
+	"compute.networks.get",
+	"compute.sslCertificates.create",
+	"compute.sslCertificates.delete",
+	"compute.sslCertificates.get",
+	"compute.subnetworks.create",
+	"compute.subnetworks.delete",
+	"compute.subnetworks.get",
+	"compute.targetHttpProxies.create",
+	"compute.targetHttpProxies.delete",
+	"compute.targetHttpProxies.get",
+	"compute.targetHttpsProxies.create",
+	"compute.targetHttpsProxies.delete",
+	"compute.targetHttpsProxies.get",
+	"compute.targetPools.create",
+	"compute.targetPools.delete",
+	"compute.targetPools.get",
+	"compute.urlMaps.create",
+	"compute.urlMaps.delete",
+	"compute.urlMaps.get",
+	"dns.managedZones.create",
+	"dns.managedZones.delete",
+	"dns.managedZones.get",
+	"dns.resourceRecordSets.list",
+
+	// BOSH Google CPI
+	"compute.addresses.use",
+	"compute.disks.create",
+	"compute.disks.createSnapshot",
+	"compute.disks.delete",
+	"compute.disks.get",
+	"compute.disks.use",
+	"compute.images.create",
+	"compute.images.delete",
+	"compute.images.get",
+	"compute.images.useReadOnly",
+	"compute.instanceGroups.update",
+	"compute.instances.attachDisk",
+	"compute.instances.create",
+	"compute.instances.delete",
+	"compute.instances.detachDisk",
+	"compute.instances.get",
+	"compute.instances.setLabels",
+	"compute.instances.setMetadata",
+	"compute.instances.setTags",
+	"compute.regionOperations.get",
+	"compute.snapshots.delete",
+	"compute.snapshots.get",
+	"compute.subnetworks.use",
+	"compute.subnetworks.useExternalIp",
+	"compute.targetPools.addInstance",
+	"compute.targetPools.removeInstance",
+	"compute.zoneOperations.get",
+}
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"io"
 	"math/big"
+	"strings"
 	"time"
 )
 
+const envIDSuffixCharacters = "abcdefghijklmnopqrstuvwxyz0123456789"
+
 type EnvIDGenerator struct {
 	reader io.Reader
 }
@@ -18,14 +21,70 @@ func NewEnvIDGenerator(reader io.Reader) EnvIDGenerator {
 	}
 }
 
-func (e EnvIDGenerator) Generate() (string, error) {
+// Generate names a new environment after a lake. Without a prefix, team or
+// purpose the name is "bbl-env-<lake>-<timestamp>", or "bbl-<lake>-<suffix>"
+// with a short random suffix when that does not fit in maxLength. Otherwise
+// the name is "<prefix>-<team>-<purpose>-<lake>", the prefix defaulting to
+// "bbl", and the lake is replaced with a short random suffix when it does not
+// fit, so that a retry after a taken name generates a different one.
+func (e EnvIDGenerator) Generate(naming EnvIDNaming, maxLength int) (string, error) {
 	lake, err := e.randomLake()
 	if err != nil {
 		return "", err
 	}
-	timestamp := time.Now().UTC().Format("2006-01-02t15-04z")
 
-	return fmt.Sprintf("bbl-env-%s-%s", lake, timestamp), nil
+	if naming.Prefix == "" && naming.Team == "" && naming.Purpose == "" {
+		timestamp := time.Now().UTC().Format("2006-01-02t15-04z")
+
+		envID := fmt.Sprintf("bbl-env-%s-%s", lake, timestamp)
+		if len(envID) <= maxLength {
+			return envID, nil
+		}
+
+		suffix, err := e.randomSuffix(4)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("bbl-%s-%s", lake, suffix), nil
+	}
+
+	prefix := naming.Prefix
+	if prefix == "" {
+		prefix = "bbl"
+	}
+
+	var parts []string
+	for _, part := range []string{prefix, naming.Team, naming.Purpose} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	envID := strings.Join(append(parts, lake), "-")
+	if len(envID) <= maxLength {
+		return envID, nil
+	}
+
+	suffix, err := e.randomSuffix(4)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(append(parts, suffix), "-"), nil
+}
+
+func (e EnvIDGenerator) randomSuffix(length int) (string, error) {
+	suffix := make([]byte, length)
+	for i := range suffix {
+		index, err := rand.Int(e.reader, big.NewInt(int64(len(envIDSuffixCharacters))))
+		if err != nil {
+			return "", err
+		}
+		suffix[i] = envIDSuffixCharacters[index.Int64()]
+	}
+
+	return string(suffix), nil
 }
 
 func (e EnvIDGenerator) randomLake() (string, error) {
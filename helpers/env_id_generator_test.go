@@ -12,14 +12,57 @@ import (
 
 var _ = Describe("EnvIDGenerator", func() {
 	Describe("Generate", func() {
-		It("generates a env id with a lake and timestamp", func() {
-			generator := helpers.NewEnvIDGenerator(rand.Reader)
+		var generator helpers.EnvIDGenerator
+
+		BeforeEach(func() {
+			generator = helpers.NewEnvIDGenerator(rand.Reader)
+		})
 
-			envID, err := generator.Generate()
+		It("generates a env id with a lake and timestamp", func() {
+			envID, err := generator.Generate(helpers.EnvIDNaming{}, 63)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(envID).To(MatchRegexp(`bbl-env-([a-z]+-{1}){1,2}\d{4}-\d{2}-\d{2}t\d{2}-\d{2}z`))
 		})
 
+		It("generates a env id with a lake and a short suffix when the timestamp does not fit", func() {
+			envID, err := generator.Generate(helpers.EnvIDNaming{}, 19)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envID).To(MatchRegexp(`^bbl-([a-z]+-{1}){1,2}[a-z0-9]{4}$`))
+			Expect(len(envID)).To(BeNumerically("<=", 19))
+		})
+
+		It("generates a env id out of the prefix, team, purpose and a lake", func() {
+			envID, err := generator.Generate(helpers.EnvIDNaming{
+				Prefix:  "acme",
+				Team:    "payments",
+				Purpose: "staging",
+			}, 63)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envID).To(MatchRegexp(`^acme-payments-staging-[a-z]+(-[a-z]+)?$`))
+		})
+
+		It("defaults the prefix to bbl", func() {
+			envID, err := generator.Generate(helpers.EnvIDNaming{Team: "payments"}, 63)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envID).To(MatchRegexp(`^bbl-payments-[a-z]+(-[a-z]+)?$`))
+		})
+
+		It("replaces the lake with a short suffix when it does not fit", func() {
+			naming := helpers.EnvIDNaming{
+				Prefix:  "acme",
+				Team:    "payments",
+				Purpose: "staging",
+			}
+
+			envID, err := generator.Generate(naming, 26)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(envID).To(MatchRegexp(`^acme-payments-staging-[a-z0-9]{4}$`))
+
+			otherEnvID, err := generator.Generate(naming, 26)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(otherEnvID).NotTo(Equal(envID))
+		})
+
 		Context("when there are errors", func() {
 			It("it returns the error", func() {
 				anError := errors.New("banana")
@@ -28,7 +71,7 @@ var _ = Describe("EnvIDGenerator", func() {
 
 				generator := helpers.NewEnvIDGenerator(&badReader)
 
-				_, err := generator.Generate()
+				_, err := generator.Generate(helpers.EnvIDNaming{}, 63)
 				Expect(err).To(Equal(anError))
 			})
 		})
@@ -4,21 +4,71 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"unicode"
 
 	"github.com/cloudfoundry/bosh-bootloader/gcp"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
-var matchString = regexp.MatchString
+const maxEnvIDAttempts = 5
+
+var (
+	matchString = regexp.MatchString
+
+	envIDPartRegexp = regexp.MustCompile(`^[a-z0-9](?:[-a-z0-9]*[a-z0-9])?$`)
+)
+
+// EnvIDNaming names a new environment. Name is used as is, otherwise the
+// env id generator builds a name out of Prefix, Team and Purpose.
+type EnvIDNaming struct {
+	Name    string
+	Prefix  string
+	Team    string
+	Purpose string
+
+	// Terraform tells that an aws environment is created by terraform,
+	// which names its elbs after the env id.
+	Terraform bool
+}
+
+// envIDResourceLimit is the longest name an iaas accepts for a resource
+// that bbl names after the env id, along with the longest suffix or prefix
+// bbl adds to the env id in that name.
+type envIDResourceLimit struct {
+	resource  string
+	maxLength int
+	affix     string
+}
+
+func (l envIDResourceLimit) maxEnvIDLength() int {
+	return l.maxLength - len(l.affix)
+}
+
+var (
+	gcpEnvIDLimits = []envIDResourceLimit{
+		{resource: "gcp resource", maxLength: 63, affix: "-cf-ssh-proxy-open"},
+	}
+
+	awsEnvIDLimits = []envIDResourceLimit{
+		{resource: "iam user", maxLength: 64, affix: "_bosh_user"},
+		{resource: "cloudformation stack", maxLength: 128, affix: "stack-"},
+		{resource: "key pair", maxLength: 255, affix: "keypair-"},
+	}
+
+	awsTerraformEnvIDLimits = []envIDResourceLimit{
+		{resource: "elb", maxLength: 32, affix: "-concourse-lb"},
+	}
+)
 
 type EnvIDManager struct {
 	envIDGenerator        envIDGenerator
 	gcpClientProvider     gcpClientProvider
 	infrastructureManager infrastructureManager
+	vpcChecker            vpcChecker
 }
 
 type envIDGenerator interface {
-	Generate() (string, error)
+	Generate(naming EnvIDNaming, maxLength int) (string, error)
 }
 
 type infrastructureManager interface {
@@ -29,59 +79,157 @@ type gcpClientProvider interface {
 	Client() gcp.Client
 }
 
+type vpcChecker interface {
+	Exists(name string) (bool, error)
+}
+
 func NewEnvIDManager(envIDGenerator envIDGenerator, gcpClientProvider gcpClientProvider,
-	infrastructureManager infrastructureManager) EnvIDManager {
+	infrastructureManager infrastructureManager, vpcChecker vpcChecker) EnvIDManager {
 	return EnvIDManager{
 		envIDGenerator:        envIDGenerator,
 		gcpClientProvider:     gcpClientProvider,
 		infrastructureManager: infrastructureManager,
+		vpcChecker:            vpcChecker,
 	}
 }
 
-func (e EnvIDManager) Sync(state storage.State, envID string) (string, error) {
+// Sync returns the env id of the state, or names a new environment. The
+// length of the name is checked against the resources of the iaas before
+// looking for an existing environment with that name. Generated names are
+// generated again when they are taken.
+func (e EnvIDManager) Sync(state storage.State, naming EnvIDNaming) (string, error) {
 	if state.EnvID != "" {
 		return state.EnvID, nil
 	}
 
-	err := e.checkFastFail(state.IAAS, envID)
+	err := e.validateNaming(naming)
 	if err != nil {
 		return "", err
 	}
 
-	err = e.validateName(envID)
-	if err != nil {
-		return "", err
+	limit, ok := envIDLimit(state.IAAS, naming.Terraform)
+
+	if naming.Name != "" {
+		if ok && len(naming.Name) > limit.maxEnvIDLength() {
+			return "", envIDTooLongError(naming.Name, limit)
+		}
+
+		taken, err := e.exists(state.IAAS, naming.Name)
+		if err != nil {
+			return "", err
+		}
+
+		if taken {
+			return "", fmt.Errorf("It looks like a bbl environment already exists with the name '%s'. Please provide a different name.", naming.Name)
+		}
+
+		return naming.Name, nil
+	}
+
+	maxLength := limit.maxEnvIDLength()
+
+	var envID string
+	for attempt := 0; attempt < maxEnvIDAttempts; attempt++ {
+		envID, err = e.envIDGenerator.Generate(naming, maxLength)
+		if err != nil {
+			return "", err
+		}
+
+		if ok && len(envID) > maxLength {
+			return "", envIDTooLongError(envID, limit)
+		}
+
+		taken, err := e.exists(state.IAAS, envID)
+		if err != nil {
+			return "", err
+		}
+
+		if !taken {
+			return envID, nil
+		}
+	}
+
+	return "", fmt.Errorf("It looks like a bbl environment already exists with the name '%s' and %d generated names were taken. Please provide a name.", envID, maxEnvIDAttempts)
+}
+
+// envIDLimit returns the most constraining resource name limit of the iaas.
+func envIDLimit(iaas string, terraform bool) (envIDResourceLimit, bool) {
+	var limits []envIDResourceLimit
+	switch iaas {
+	case "gcp":
+		limits = gcpEnvIDLimits
+	case "aws":
+		limits = awsEnvIDLimits
+		if terraform {
+			limits = append(append([]envIDResourceLimit{}, limits...), awsTerraformEnvIDLimits...)
+		}
+	default:
+		return envIDResourceLimit{maxLength: 63}, false
 	}
 
-	if envID != "" {
-		return envID, nil
+	limit := limits[0]
+	for _, l := range limits[1:] {
+		if l.maxEnvIDLength() < limit.maxEnvIDLength() {
+			limit = l
+		}
 	}
 
-	return e.envIDGenerator.Generate()
+	return limit, true
+}
+
+func envIDTooLongError(envID string, limit envIDResourceLimit) error {
+	return fmt.Errorf("The name '%s' is %d characters long, %s names allow at most %d characters for the name. Please provide a shorter name.",
+		envID, len(envID), limit.resource, limit.maxEnvIDLength())
 }
 
-func (e EnvIDManager) checkFastFail(iaas, envID string) error {
+// exists looks for the resources that bbl creates first for an
+// environment: the network on gcp, and on aws the cloudformation stack or
+// the vpc tagged by terraform.
+func (e EnvIDManager) exists(iaas, envID string) (bool, error) {
 	switch iaas {
 	case "gcp":
 		gcpClient := e.gcpClientProvider.Client()
 		networkName := envID + "-network"
 		networkList, err := gcpClient.GetNetworks(networkName)
 		if err != nil {
-			return err
-		}
-		if len(networkList.Items) > 0 {
-			return errors.New(fmt.Sprintf("It looks like a bbl environment already exists with the name '%s'. Please provide a different name.", envID))
+			return false, err
 		}
+		return len(networkList.Items) > 0, nil
 	case "aws":
 		stackName := "stack-" + envID
 		stackExists, err := e.infrastructureManager.Exists(stackName)
 		if err != nil {
-			return err
+			return false, err
 		}
 		if stackExists {
-			return errors.New(fmt.Sprintf("It looks like a bbl environment already exists with the name '%s'. Please provide a different name.", envID))
+			return true, nil
+		}
+
+		return e.vpcChecker.Exists(envID + "-vpc")
+	}
+	return false, nil
+}
+
+func (e EnvIDManager) validateNaming(naming EnvIDNaming) error {
+	err := e.validateName(naming.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, part := range []struct{ flag, value string }{
+		{"--name-prefix", naming.Prefix},
+		{"--name-team", naming.Team},
+		{"--name-purpose", naming.Purpose},
+	} {
+		if part.value != "" && !envIDPartRegexp.MatchString(part.value) {
+			return fmt.Errorf("%s %q must contain only lowercase letters, numbers and dashes", part.flag, part.value)
 		}
 	}
+
+	if naming.Prefix != "" && !unicode.IsLetter(rune(naming.Prefix[0])) {
+		return fmt.Errorf("--name-prefix %q must start with a letter", naming.Prefix)
+	}
+
 	return nil
 }
 
@@ -7,6 +7,7 @@ import (
 	"github.com/cloudfoundry/bosh-bootloader/helpers"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	compute "google.golang.org/api/compute/v1"
 )
@@ -17,6 +18,7 @@ var _ = Describe("EnvIDManager", func() {
 		gcpClientProvider     *fakes.GCPClientProvider
 		gcpClient             *fakes.GCPClient
 		infrastructureManager *fakes.InfrastructureManager
+		vpcChecker            *fakes.VPCChecker
 		envIDManager          helpers.EnvIDManager
 	)
 
@@ -28,15 +30,18 @@ var _ = Describe("EnvIDManager", func() {
 		gcpClient = &fakes.GCPClient{}
 		gcpClientProvider.ClientCall.Returns.Client = gcpClient
 
+		gcpClient.GetNetworksCall.Returns.NetworkList = &compute.NetworkList{}
+
 		infrastructureManager = &fakes.InfrastructureManager{}
+		vpcChecker = &fakes.VPCChecker{}
 
-		envIDManager = helpers.NewEnvIDManager(envIDGenerator, gcpClientProvider, infrastructureManager)
+		envIDManager = helpers.NewEnvIDManager(envIDGenerator, gcpClientProvider, infrastructureManager, vpcChecker)
 	})
 
 	Describe("Sync", func() {
 		Context("when no previous env id exists", func() {
 			It("calls env id generator if name is not passed in", func() {
-				envID, err := envIDManager.Sync(storage.State{}, "")
+				envID, err := envIDManager.Sync(storage.State{}, helpers.EnvIDNaming{})
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(envIDGenerator.GenerateCall.CallCount).To(Equal(1))
@@ -44,7 +49,7 @@ var _ = Describe("EnvIDManager", func() {
 			})
 
 			It("uses the name passed in if an environment does not exist", func() {
-				envID, err := envIDManager.Sync(storage.State{}, "some-other-env-id")
+				envID, err := envIDManager.Sync(storage.State{}, helpers.EnvIDNaming{Name: "some-other-env-id"})
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(envIDGenerator.GenerateCall.CallCount).To(Equal(0))
@@ -60,7 +65,7 @@ var _ = Describe("EnvIDManager", func() {
 					}
 					_, err := envIDManager.Sync(storage.State{
 						IAAS: "gcp",
-					}, "existing")
+					}, helpers.EnvIDNaming{Name: "existing"})
 
 					Expect(gcpClient.GetNetworksCall.CallCount).To(Equal(1))
 					Expect(gcpClient.GetNetworksCall.Receives.Name).To(Equal("existing-network"))
@@ -74,19 +79,109 @@ var _ = Describe("EnvIDManager", func() {
 					infrastructureManager.ExistsCall.Returns.Exists = true
 					_, err := envIDManager.Sync(storage.State{
 						IAAS: "aws",
-					}, "existing")
+					}, helpers.EnvIDNaming{Name: "existing"})
 
 					Expect(infrastructureManager.ExistsCall.CallCount).To(Equal(1))
 					Expect(infrastructureManager.ExistsCall.Receives.StackName).To(Equal("stack-existing"))
 
 					Expect(err).To(MatchError("It looks like a bbl environment already exists with the name 'existing'. Please provide a different name."))
 				})
+
+				It("fails if a vpc of a pre-existing terraform environment has the name", func() {
+					vpcChecker.ExistsCall.Returns.Exists = true
+					_, err := envIDManager.Sync(storage.State{
+						IAAS: "aws",
+					}, helpers.EnvIDNaming{Name: "existing"})
+
+					Expect(vpcChecker.ExistsCall.CallCount).To(Equal(1))
+					Expect(vpcChecker.ExistsCall.Receives.Name).To(Equal("existing-vpc"))
+
+					Expect(err).To(MatchError("It looks like a bbl environment already exists with the name 'existing'. Please provide a different name."))
+				})
+
+				It("fails before any api call if the name does not fit in the iam user name", func() {
+					_, err := envIDManager.Sync(storage.State{
+						IAAS: "aws",
+					}, helpers.EnvIDNaming{Name: "some-very-long-name-that-does-not-fit-in-the-iam-user-name"})
+
+					Expect(err).To(MatchError("The name 'some-very-long-name-that-does-not-fit-in-the-iam-user-name' is 58 characters long, iam user names allow at most 54 characters for the name. Please provide a shorter name."))
+					Expect(infrastructureManager.ExistsCall.CallCount).To(Equal(0))
+				})
+
+				It("fails before any api call if the name does not fit in the elb names of terraform", func() {
+					_, err := envIDManager.Sync(storage.State{
+						IAAS: "aws",
+					}, helpers.EnvIDNaming{Name: "some-twenty-chars-xy", Terraform: true})
+
+					Expect(err).To(MatchError("The name 'some-twenty-chars-xy' is 20 characters long, elb names allow at most 19 characters for the name. Please provide a shorter name."))
+					Expect(infrastructureManager.ExistsCall.CallCount).To(Equal(0))
+					Expect(vpcChecker.ExistsCall.CallCount).To(Equal(0))
+				})
+			})
+
+			Context("for gcp names", func() {
+				It("fails before any api call if the name does not fit in the gcp resource names", func() {
+					_, err := envIDManager.Sync(storage.State{
+						IAAS: "gcp",
+					}, helpers.EnvIDNaming{Name: "some-name-that-is-far-too-long-for-gcp-resources"})
+
+					Expect(err).To(MatchError("The name 'some-name-that-is-far-too-long-for-gcp-resources' is 48 characters long, gcp resource names allow at most 45 characters for the name. Please provide a shorter name."))
+					Expect(gcpClient.GetNetworksCall.CallCount).To(Equal(0))
+				})
+			})
+
+			Context("when the name is generated", func() {
+				It("passes the naming and the longest env id of the iaas to the generator", func() {
+					naming := helpers.EnvIDNaming{Prefix: "acme", Team: "payments", Purpose: "staging", Terraform: true}
+
+					_, err := envIDManager.Sync(storage.State{IAAS: "aws"}, naming)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(envIDGenerator.GenerateCall.Receives.Naming).To(Equal(naming))
+					Expect(envIDGenerator.GenerateCall.Receives.MaxLength).To(Equal(19))
+				})
+
+				It("generates another name when the generated name is taken", func() {
+					names := []string{"bbl-taken", "bbl-free"}
+					envIDGenerator.GenerateCall.Stub = func(helpers.EnvIDNaming, int) (string, error) {
+						return names[envIDGenerator.GenerateCall.CallCount-1], nil
+					}
+					vpcChecker.ExistsCall.Stub = func(name string) (bool, error) {
+						return name == "bbl-taken-vpc", nil
+					}
+
+					envID, err := envIDManager.Sync(storage.State{IAAS: "aws"}, helpers.EnvIDNaming{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(envIDGenerator.GenerateCall.CallCount).To(Equal(2))
+					Expect(envID).To(Equal("bbl-free"))
+				})
+
+				It("returns an error when every generated name is taken", func() {
+					gcpClient.GetNetworksCall.Returns.NetworkList = &compute.NetworkList{
+						Items: []*compute.Network{{}},
+					}
+
+					_, err := envIDManager.Sync(storage.State{IAAS: "gcp"}, helpers.EnvIDNaming{})
+					Expect(err).To(MatchError("It looks like a bbl environment already exists with the name 'some-env-id' and 5 generated names were taken. Please provide a name."))
+					Expect(envIDGenerator.GenerateCall.CallCount).To(Equal(5))
+				})
+
+				It("returns an error when the generated name does not fit", func() {
+					envIDGenerator.GenerateCall.Returns.EnvID = "acme-payments-staging"
+
+					_, err := envIDManager.Sync(storage.State{IAAS: "aws"}, helpers.EnvIDNaming{
+						Prefix: "acme", Team: "payments", Purpose: "staging", Terraform: true,
+					})
+					Expect(err).To(MatchError("The name 'acme-payments-staging' is 21 characters long, elb names allow at most 19 characters for the name. Please provide a shorter name."))
+					Expect(infrastructureManager.ExistsCall.CallCount).To(Equal(0))
+				})
 			})
 		})
 
 		Context("when an env id exists in the state", func() {
 			It("returns the existing env id", func() {
-				envID, err := envIDManager.Sync(storage.State{EnvID: "some-previous-env-id"}, "")
+				envID, err := envIDManager.Sync(storage.State{EnvID: "some-previous-env-id"}, helpers.EnvIDNaming{})
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(envIDGenerator.GenerateCall.CallCount).To(Equal(0))
@@ -100,7 +195,7 @@ var _ = Describe("EnvIDManager", func() {
 
 				_, err := envIDManager.Sync(storage.State{
 					IAAS: "gcp",
-				}, "existing")
+				}, helpers.EnvIDNaming{Name: "existing"})
 
 				Expect(err).To(MatchError("failed to get network list"))
 			})
@@ -110,23 +205,44 @@ var _ = Describe("EnvIDManager", func() {
 
 				_, err := envIDManager.Sync(storage.State{
 					IAAS: "aws",
-				}, "existing")
+				}, helpers.EnvIDNaming{Name: "existing"})
 
 				Expect(err).To(MatchError("failed to check stack existence"))
 			})
 
 			It("returns an error with a helpful message when an invalid name is provided", func() {
-				_, err := envIDManager.Sync(storage.State{}, "some_bad_name")
+				_, err := envIDManager.Sync(storage.State{}, helpers.EnvIDNaming{Name: "some_bad_name"})
 
 				Expect(err).To(MatchError("Names must start with a letter and be alphanumeric or hyphenated."))
 			})
 
+			DescribeTable("returns an error when a part of the generated name is invalid", func(naming helpers.EnvIDNaming, expectedError string) {
+				_, err := envIDManager.Sync(storage.State{}, naming)
+				Expect(err).To(MatchError(expectedError))
+				Expect(envIDGenerator.GenerateCall.CallCount).To(Equal(0))
+			},
+				Entry("prefix", helpers.EnvIDNaming{Prefix: "Acme"}, `--name-prefix "Acme" must contain only lowercase letters, numbers and dashes`),
+				Entry("prefix starting with a number", helpers.EnvIDNaming{Prefix: "42"}, `--name-prefix "42" must start with a letter`),
+				Entry("team", helpers.EnvIDNaming{Team: "pay_ments"}, `--name-team "pay_ments" must contain only lowercase letters, numbers and dashes`),
+				Entry("purpose", helpers.EnvIDNaming{Purpose: "staging-"}, `--name-purpose "staging-" must contain only lowercase letters, numbers and dashes`),
+			)
+
+			It("returns an error when the vpc checker fails", func() {
+				vpcChecker.ExistsCall.Returns.Error = errors.New("failed to describe vpcs")
+
+				_, err := envIDManager.Sync(storage.State{
+					IAAS: "aws",
+				}, helpers.EnvIDNaming{Name: "existing"})
+
+				Expect(err).To(MatchError("failed to describe vpcs"))
+			})
+
 			It("returns an error when regex match string fails", func() {
 				helpers.SetMatchString(func(string, string) (bool, error) {
 					return false, errors.New("failed to match string")
 				})
 
-				_, err := envIDManager.Sync(storage.State{}, "some-name")
+				_, err := envIDManager.Sync(storage.State{}, helpers.EnvIDNaming{Name: "some-name"})
 
 				Expect(err).To(MatchError("failed to match string"))
 
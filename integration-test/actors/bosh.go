@@ -8,8 +8,8 @@ func NewBOSH() BOSH {
 	return BOSH{}
 }
 
-func (BOSH) DirectorExists(address, username, password string) bool {
-	client := bosh.NewClient(address, username, password)
+func (BOSH) DirectorExists(address, username, password, caCert string) bool {
+	client := bosh.NewClient(address, username, password, caCert, false)
 
 	_, err := client.Info()
 	return err == nil
@@ -0,0 +1,187 @@
+package ssl
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/acme"
+)
+
+const LetsEncryptDirectoryURL = "https://acme-v02.api.letsencrypt.org/directory"
+
+// DNSChallenger publishes and removes the TXT records of ACME dns-01
+// challenges in the dns zone of a domain.
+type DNSChallenger interface {
+	Present(domain, fqdn, value string) error
+	CleanUp(domain, fqdn, value string) error
+}
+
+type ACMEIssuer struct {
+	generateKey keyGenerator
+}
+
+func NewACMEIssuer(keyGenerator keyGenerator) ACMEIssuer {
+	return ACMEIssuer{
+		generateKey: keyGenerator,
+	}
+}
+
+// Issue obtains a certificate for the domain and its wildcard subdomain
+// from the ACME server at directoryURL, answering its dns-01 challenges
+// through the challenger. An empty account key registers a new account
+// for email. The account key is returned so that renewals reuse the
+// account.
+func (i ACMEIssuer) Issue(directoryURL, email string, accountKeyPEM []byte, domain string, challenger DNSChallenger) (KeyPair, []byte, error) {
+	ctx := context.Background()
+
+	accountKeyPEM, accountKey, err := i.accountKey(accountKeyPEM)
+	if err != nil {
+		return KeyPair{}, nil, err
+	}
+
+	client := &acme.Client{
+		Key:          accountKey,
+		DirectoryURL: directoryURL,
+	}
+
+	_, err = client.Register(ctx, &acme.Account{Contact: []string{"mailto:" + email}}, acme.AcceptTOS)
+	if err != nil && err != acme.ErrAccountAlreadyExists {
+		return KeyPair{}, nil, fmt.Errorf("failed to register acme account: %s", err)
+	}
+
+	wildcard := "*." + domain
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(wildcard, domain))
+	if err != nil {
+		return KeyPair{}, nil, fmt.Errorf("failed to order certificate: %s", err)
+	}
+
+	for _, authzURL := range order.AuthzURLs {
+		err = i.authorize(ctx, client, authzURL, domain, challenger)
+		if err != nil {
+			return KeyPair{}, nil, err
+		}
+	}
+
+	order, err = client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return KeyPair{}, nil, fmt.Errorf("failed to order certificate: %s", err)
+	}
+
+	certPrivateKey, err := i.generateKey(rand.Reader, 2048)
+	if err != nil {
+		return KeyPair{}, nil, err
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: wildcard},
+		DNSNames: []string{wildcard, domain},
+	}, certPrivateKey)
+	if err != nil {
+		return KeyPair{}, nil, err
+	}
+
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return KeyPair{}, nil, fmt.Errorf("failed to issue certificate: %s", err)
+	}
+
+	if len(chain) == 0 {
+		return KeyPair{}, nil, errors.New("acme server returned no certificate")
+	}
+
+	var ca []byte
+	for _, der := range chain[1:] {
+		ca = append(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+
+	return KeyPair{
+		CA:          ca,
+		Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: chain[0]}),
+		PrivateKey: pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(certPrivateKey),
+		}),
+	}, accountKeyPEM, nil
+}
+
+func (i ACMEIssuer) accountKey(accountKeyPEM []byte) ([]byte, *rsa.PrivateKey, error) {
+	if len(accountKeyPEM) == 0 {
+		key, err := i.generateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		}), key, nil
+	}
+
+	block, _ := pem.Decode(accountKeyPEM)
+	if block == nil {
+		return nil, nil, errors.New("acme account key is not PEM encoded")
+	}
+
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse acme account key: %s", err)
+	}
+
+	return accountKeyPEM, key, nil
+}
+
+// authorize answers the dns-01 challenge of a pending authorization. The
+// domain and its wildcard share a challenge record, so each one is cleaned
+// up before the next is presented.
+func (i ACMEIssuer) authorize(ctx context.Context, client *acme.Client, authzURL, domain string, challenger DNSChallenger) error {
+	authz, err := client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return fmt.Errorf("failed to get authorization: %s", err)
+	}
+
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+
+	var challenge *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == "dns-01" {
+			challenge = c
+			break
+		}
+	}
+
+	if challenge == nil {
+		return fmt.Errorf("acme server offers no dns-01 challenge for %q", authz.Identifier.Value)
+	}
+
+	value, err := client.DNS01ChallengeRecord(challenge.Token)
+	if err != nil {
+		return err
+	}
+
+	fqdn := fmt.Sprintf("_acme-challenge.%s.", authz.Identifier.Value)
+	err = challenger.Present(domain, fqdn, value)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Accept(ctx, challenge)
+	if err == nil {
+		_, err = client.WaitAuthorization(ctx, authz.URI)
+	}
+
+	cleanUpErr := challenger.CleanUp(domain, fqdn, value)
+
+	if err != nil {
+		return fmt.Errorf("failed to answer dns-01 challenge for %q: %s", authz.Identifier.Value, err)
+	}
+
+	return cleanUpErr
+}
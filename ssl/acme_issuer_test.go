@@ -0,0 +1,146 @@
+package ssl_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/ssl"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeACMEServer answers the requests of an ACME client without checking
+// their signatures and validates every challenge it is asked to.
+func fakeACMEServer() *httptest.Server {
+	var (
+		mutex    sync.Mutex
+		accepted bool
+	)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		url := server.URL
+		w.Header().Set("Replay-Nonce", "some-nonce")
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/directory":
+			fmt.Fprintf(w, `{"newNonce": "%[1]s/nonce", "newAccount": "%[1]s/account", "newOrder": "%[1]s/order"}`, url)
+		case "/nonce":
+			w.WriteHeader(http.StatusOK)
+		case "/account":
+			w.Header().Set("Location", url+"/account/1")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"status": "valid"}`)
+		case "/order":
+			w.Header().Set("Location", url+"/order/1")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"status": "pending", "authorizations": ["%[1]s/authz/1"], "finalize": "%[1]s/finalize"}`, url)
+		case "/order/1":
+			fmt.Fprintf(w, `{"status": "ready", "authorizations": ["%[1]s/authz/1"], "finalize": "%[1]s/finalize"}`, url)
+		case "/authz/1":
+			status := "pending"
+			if accepted {
+				status = "valid"
+			}
+			fmt.Fprintf(w, `{"status": %q, "identifier": {"type": "dns", "value": "example.com"},
+				"challenges": [{"type": "http-01", "url": "%[2]s/chal/0", "token": "other-token"},
+				{"type": "dns-01", "url": "%[2]s/chal/1", "token": "some-token"}]}`, status, url)
+		case "/chal/1":
+			accepted = true
+			fmt.Fprintf(w, `{"type": "dns-01", "url": "%s/chal/1", "token": "some-token", "status": "processing"}`, url)
+		case "/finalize":
+			w.Header().Set("Location", url+"/order/1")
+			fmt.Fprintf(w, `{"status": "valid", "certificate": "%s/cert"}`, url)
+		case "/cert":
+			w.Header().Set("Content-Type", "application/pem-certificate-chain")
+			fmt.Fprintf(w, "%s\n%s\n", certificatePEM, caPEM)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return server
+}
+
+var _ = Describe("ACMEIssuer", func() {
+	var (
+		server     *httptest.Server
+		challenger *fakes.DNSChallenger
+		issuer     ssl.ACMEIssuer
+
+		fakePrivateKeyGenerator *fakes.PrivateKeyGenerator
+	)
+
+	BeforeEach(func() {
+		server = fakeACMEServer()
+		challenger = &fakes.DNSChallenger{}
+
+		fakePrivateKeyGenerator = &fakes.PrivateKeyGenerator{}
+		fakePrivateKeyGenerator.GenerateKeyCall.Stub = func() (*rsa.PrivateKey, error) {
+			return rsa.GenerateKey(rand.Reader, 1024)
+		}
+
+		issuer = ssl.NewACMEIssuer(fakePrivateKeyGenerator.GenerateKey)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("issues a certificate after answering the dns-01 challenges", func() {
+		keyPair, accountKey, err := issuer.Issue(server.URL+"/directory", "some-email", nil, "example.com", challenger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(challenger.PresentCall.CallCount).To(Equal(1))
+		Expect(challenger.PresentCall.Receives.Domain).To(Equal("example.com"))
+		Expect(challenger.PresentCall.Receives.FQDN).To(Equal("_acme-challenge.example.com."))
+		Expect(challenger.PresentCall.Receives.Value).NotTo(BeEmpty())
+		Expect(challenger.CleanUpCall.CallCount).To(Equal(1))
+		Expect(challenger.CleanUpCall.Receives.FQDN).To(Equal("_acme-challenge.example.com."))
+
+		Expect(strings.TrimSpace(string(keyPair.Certificate))).To(Equal(certificatePEM))
+		Expect(strings.TrimSpace(string(keyPair.CA))).To(Equal(caPEM))
+		Expect(string(keyPair.PrivateKey)).To(ContainSubstring("RSA PRIVATE KEY"))
+		Expect(string(accountKey)).To(ContainSubstring("RSA PRIVATE KEY"))
+		Expect(fakePrivateKeyGenerator.GenerateKeyCall.CallCount).To(Equal(2))
+	})
+
+	It("reuses the given account key", func() {
+		_, accountKey, err := issuer.Issue(server.URL+"/directory", "some-email", []byte(caPrivateKeyPEM), "example.com", challenger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(string(accountKey)).To(Equal(caPrivateKeyPEM))
+		Expect(fakePrivateKeyGenerator.GenerateKeyCall.CallCount).To(Equal(1))
+	})
+
+	Context("failure cases", func() {
+		It("returns an error when the account key cannot be parsed", func() {
+			_, _, err := issuer.Issue(server.URL+"/directory", "some-email", []byte("some-invalid-key"), "example.com", challenger)
+			Expect(err).To(MatchError("acme account key is not PEM encoded"))
+		})
+
+		It("returns an error when the account cannot be registered", func() {
+			_, _, err := issuer.Issue(server.URL+"/missing", "some-email", nil, "example.com", challenger)
+			Expect(err).To(MatchError(ContainSubstring("failed to register acme account:")))
+		})
+
+		It("returns an error when the challenge record cannot be presented", func() {
+			challenger.PresentCall.Returns.Error = errors.New("failed to present")
+
+			_, _, err := issuer.Issue(server.URL+"/directory", "some-email", nil, "example.com", challenger)
+			Expect(err).To(MatchError("failed to present"))
+		})
+	})
+})
@@ -0,0 +1,44 @@
+package ssl
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CertificateInfo describes the leaf certificate of a PEM bundle.
+type CertificateInfo struct {
+	Subject  string
+	Issuer   string
+	DNSNames []string
+	SANs     []string
+	NotAfter time.Time
+}
+
+func DescribeCertificate(certificatePEM []byte) (CertificateInfo, error) {
+	block, _ := pem.Decode(certificatePEM)
+	if block == nil {
+		return CertificateInfo{}, errors.New("certificate is not PEM encoded")
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return CertificateInfo{}, fmt.Errorf("failed to parse certificate: %s", err)
+	}
+
+	var sans []string
+	sans = append(sans, certificate.DNSNames...)
+	for _, ip := range certificate.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	return CertificateInfo{
+		Subject:  certificate.Subject.CommonName,
+		Issuer:   certificate.Issuer.CommonName,
+		DNSNames: certificate.DNSNames,
+		SANs:     sans,
+		NotAfter: certificate.NotAfter,
+	}, nil
+}
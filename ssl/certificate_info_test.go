@@ -0,0 +1,32 @@
+package ssl_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/ssl"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DescribeCertificate", func() {
+	It("returns the subject, issuer, sans and expiry of the certificate", func() {
+		info, err := ssl.DescribeCertificate([]byte(certificatePEM))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(info.Subject).To(Equal("127.0.0.1"))
+		Expect(info.Issuer).To(Equal("BOSH Bootloader"))
+		Expect(info.SANs).To(Equal([]string{"52.0.112.12"}))
+		Expect(info.NotAfter).To(Equal(time.Date(2018, time.August, 9, 0, 52, 52, 0, time.UTC)))
+	})
+
+	It("returns an error when the certificate is not PEM encoded", func() {
+		_, err := ssl.DescribeCertificate([]byte("some-certificate"))
+		Expect(err).To(MatchError("certificate is not PEM encoded"))
+	})
+
+	It("returns an error when the certificate cannot be parsed", func() {
+		_, err := ssl.DescribeCertificate([]byte("-----BEGIN CERTIFICATE-----\nYWJj\n-----END CERTIFICATE-----"))
+		Expect(err).To(MatchError(ContainSubstring("failed to parse certificate")))
+	})
+})
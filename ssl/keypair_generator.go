@@ -5,6 +5,7 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"fmt"
 	"io"
 	"net"
 
@@ -95,3 +96,77 @@ func (g KeyPairGenerator) Generate(caCommonName, commonName string) (KeyPair, er
 		}),
 	}, nil
 }
+
+// GenerateCA returns a new certificate authority and its private key.
+func (g KeyPairGenerator) GenerateCA(commonName string) (CAData, error) {
+	caPrivateKey, err := g.generateKey(rand.Reader, 2048)
+	if err != nil {
+		return CAData{}, err
+	}
+	caKey := certstrappkix.NewKey(&caPrivateKey.PublicKey, caPrivateKey)
+
+	caCertificate, err := g.createCertificateAuthority(caKey, "Cloud Foundry", 10, "Cloud Foundry", "USA", "CA",
+		"San Francisco", commonName)
+	if err != nil {
+		return CAData{}, err
+	}
+
+	pemCA, err := caCertificate.Export()
+	if err != nil {
+		return CAData{}, err
+	}
+
+	return CAData{
+		CA: pemCA,
+		PrivateKey: pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(caPrivateKey),
+		}),
+	}, nil
+}
+
+// GenerateForDomain returns a certificate for the domain and its wildcard
+// subdomain, signed by the given certificate authority.
+func (g KeyPairGenerator) GenerateForDomain(ca CAData, domain string) (KeyPair, error) {
+	caCertificate, err := certstrappkix.NewCertificateFromPEM(ca.CA)
+	if err != nil {
+		return KeyPair{}, fmt.Errorf("failed to parse ca: %s", err)
+	}
+
+	caKey, err := certstrappkix.NewKeyFromPrivateKeyPEM(ca.PrivateKey)
+	if err != nil {
+		return KeyPair{}, fmt.Errorf("failed to parse ca private key: %s", err)
+	}
+
+	certPrivateKey, err := g.generateKey(rand.Reader, 2048)
+	if err != nil {
+		return KeyPair{}, err
+	}
+	certKey := certstrappkix.NewKey(&certPrivateKey.PublicKey, certPrivateKey)
+
+	wildcard := "*." + domain
+	csr, err := g.createCertificateSigningRequest(certKey, "Cloud Foundry", nil, []string{wildcard, domain}, "Cloud Foundry",
+		"USA", "CA", "San Francisco", wildcard)
+	if err != nil {
+		return KeyPair{}, err
+	}
+
+	certificate, err := g.createCertificateHost(caCertificate, caKey, csr, 1)
+	if err != nil {
+		return KeyPair{}, err
+	}
+
+	pemCertificate, err := certificate.Export()
+	if err != nil {
+		return KeyPair{}, err
+	}
+
+	return KeyPair{
+		CA:          ca.CA,
+		Certificate: pemCertificate,
+		PrivateKey: pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(certPrivateKey),
+		}),
+	}, nil
+}
@@ -170,6 +170,83 @@ var _ = Describe("KeyPairGenerator", func() {
 			})
 		})
 	})
+
+	Describe("GenerateCA", func() {
+		It("generates a certificate authority and its private key", func() {
+			caData, err := generator.GenerateCA("bbl lb ca")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeCertstrapPKIX.CreateCertificateAuthorityCall.Receives.Key.Private).To(Equal(caPrivateKey))
+			Expect(fakeCertstrapPKIX.CreateCertificateAuthorityCall.Receives.Years).To(Equal(10))
+			Expect(fakeCertstrapPKIX.CreateCertificateAuthorityCall.Receives.CommonName).To(Equal("bbl lb ca"))
+
+			Expect(strings.TrimSpace(string(caData.CA))).To(Equal(caPEM))
+			Expect(strings.TrimSpace(string(caData.PrivateKey))).To(Equal(caPrivateKeyPEM))
+		})
+
+		It("errors when create certificate authority fails", func() {
+			fakeCertstrapPKIX.CreateCertificateAuthorityCall.Returns.Error = errors.New("create certificate authority failed")
+
+			_, err := generator.GenerateCA("bbl lb ca")
+			Expect(err).To(MatchError("create certificate authority failed"))
+		})
+	})
+
+	Describe("GenerateForDomain", func() {
+		var caData ssl.CAData
+
+		BeforeEach(func() {
+			caData = ssl.CAData{CA: []byte(caPEM), PrivateKey: []byte(caPrivateKeyPEM)}
+			fakePrivateKeyGenerator.GenerateKeyCall.Stub = func() (*rsa.PrivateKey, error) {
+				return privateKey, nil
+			}
+		})
+
+		It("generates a wildcard certificate for the domain signed by the ca", func() {
+			keyPair, err := generator.GenerateForDomain(caData, "example.com")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeCertstrapPKIX.CreateCertificateAuthorityCall.CallCount).To(Equal(0))
+
+			Expect(fakeCertstrapPKIX.CreateCertificateSigningRequestCall.Receives.Key.Private).To(Equal(privateKey))
+			Expect(fakeCertstrapPKIX.CreateCertificateSigningRequestCall.Receives.IpList).To(BeNil())
+			Expect(fakeCertstrapPKIX.CreateCertificateSigningRequestCall.Receives.DomainList).To(Equal([]string{"*.example.com", "example.com"}))
+			Expect(fakeCertstrapPKIX.CreateCertificateSigningRequestCall.Receives.CommonName).To(Equal("*.example.com"))
+
+			crtAuth, err := fakeCertstrapPKIX.CreateCertificateHostCall.Receives.CrtAuth.Export()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.TrimSpace(string(crtAuth))).To(Equal(caPEM))
+			Expect(fakeCertstrapPKIX.CreateCertificateHostCall.Receives.KeyAuth.Private).To(Equal(caPrivateKey))
+			Expect(fakeCertstrapPKIX.CreateCertificateHostCall.Receives.Years).To(Equal(1))
+
+			Expect(keyPair.CA).To(Equal([]byte(caPEM)))
+			Expect(strings.TrimSpace(string(keyPair.Certificate))).To(Equal(certificatePEM))
+			Expect(strings.TrimSpace(string(keyPair.PrivateKey))).To(Equal(privateKeyPEM))
+		})
+
+		Context("failure cases", func() {
+			It("errors when the ca cannot be parsed", func() {
+				caData.CA = []byte("some-invalid-ca")
+
+				_, err := generator.GenerateForDomain(caData, "example.com")
+				Expect(err).To(MatchError(ContainSubstring("failed to parse ca:")))
+			})
+
+			It("errors when the ca private key cannot be parsed", func() {
+				caData.PrivateKey = []byte("some-invalid-key")
+
+				_, err := generator.GenerateForDomain(caData, "example.com")
+				Expect(err).To(MatchError(ContainSubstring("failed to parse ca private key:")))
+			})
+
+			It("errors when create certificate host fails", func() {
+				fakeCertstrapPKIX.CreateCertificateHostCall.Returns.Error = errors.New("could not generate certificate host")
+
+				_, err := generator.GenerateForDomain(caData, "example.com")
+				Expect(err).To(MatchError("could not generate certificate host"))
+			})
+		})
+	})
 })
 
 func decodeAndParsePrivateKey(privateKeyPEM string) (*rsa.PrivateKey, *rsa.PublicKey, error) {
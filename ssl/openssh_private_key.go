@@ -0,0 +1,65 @@
+package ssl
+
+import (
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/ssh"
+)
+
+// marshalOpenSSHPrivateKey encodes an unencrypted ed25519 private key in the
+// "openssh-key-v1" format ssh-keygen writes, which is the only PEM format
+// OpenSSH reads ed25519 keys from.
+func marshalOpenSSHPrivateKey(random io.Reader, privateKey ed25519.PrivateKey) ([]byte, error) {
+	var check [4]byte
+	if _, err := io.ReadFull(random, check[:]); err != nil {
+		return nil, err
+	}
+
+	publicKey, err := ssh.NewPublicKey(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	key := struct {
+		Check1  uint32
+		Check2  uint32
+		Keytype string
+		Pub     []byte
+		Priv    []byte
+		Comment string
+	}{
+		Check1:  binary.BigEndian.Uint32(check[:]),
+		Check2:  binary.BigEndian.Uint32(check[:]),
+		Keytype: ssh.KeyAlgoED25519,
+		Pub:     []byte(privateKey.Public().(ed25519.PublicKey)),
+		Priv:    []byte(privateKey),
+	}
+
+	privateKeyBlock := ssh.Marshal(key)
+	for i := 1; len(privateKeyBlock)%8 != 0; i++ {
+		privateKeyBlock = append(privateKeyBlock, byte(i))
+	}
+
+	envelope := struct {
+		CipherName   string
+		KdfName      string
+		KdfOpts      string
+		NumKeys      uint32
+		PubKey       []byte
+		PrivKeyBlock []byte
+	}{
+		CipherName:   "none",
+		KdfName:      "none",
+		NumKeys:      1,
+		PubKey:       publicKey.Marshal(),
+		PrivKeyBlock: privateKeyBlock,
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "OPENSSH PRIVATE KEY",
+		Bytes: append([]byte("openssh-key-v1\x00"), ssh.Marshal(envelope)...),
+	}), nil
+}
@@ -0,0 +1,56 @@
+package ssl
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// DecryptPrivateKey decrypts a PEM encoded private key protected by a
+// passphrase, either a traditional encrypted PEM block or an encrypted
+// PKCS#8 key. Keys that are not encrypted are returned unchanged.
+func DecryptPrivateKey(keyPEM []byte, passphrase string) ([]byte, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+
+	if block.Type == "ENCRYPTED PRIVATE KEY" {
+		der, err := decryptPKCS8(block.Bytes, passphrase)
+		if err == nil && !isPrivateKey(der) {
+			err = errors.New("decryption password incorrect")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt private key: %s", err)
+		}
+
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	}
+
+	if !x509.IsEncryptedPEMBlock(block) {
+		return keyPEM, nil
+	}
+
+	der, err := x509.DecryptPEMBlock(block, []byte(passphrase))
+	if err == nil && !isPrivateKey(der) {
+		// the padding check of DecryptPEMBlock misses some wrong passphrases
+		err = x509.IncorrectPasswordError
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt private key: %s", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: der}), nil
+}
+
+func isPrivateKey(der []byte) bool {
+	if _, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return true
+	}
+	if _, err := x509.ParseECPrivateKey(der); err == nil {
+		return true
+	}
+	_, err := x509.ParsePKCS8PrivateKey(der)
+	return err == nil
+}
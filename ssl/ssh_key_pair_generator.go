@@ -0,0 +1,148 @@
+package ssl
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	DefaultSSHKeyBits = 2048
+	MinimumSSHKeyBits = 2048
+
+	RSASSHKeyType     = "rsa"
+	ED25519SSHKeyType = "ed25519"
+)
+
+type sshPublicKeyGenerator func(interface{}) (ssh.PublicKey, error)
+
+type SSHKeyPairGenerator struct {
+	random               io.Reader
+	generateKey          keyGenerator
+	generateSSHPublicKey sshPublicKeyGenerator
+}
+
+func NewSSHKeyPairGenerator(random io.Reader, generateKey keyGenerator, generateSSHPublicKey sshPublicKeyGenerator) SSHKeyPairGenerator {
+	return SSHKeyPairGenerator{
+		random:               random,
+		generateKey:          generateKey,
+		generateSSHPublicKey: generateSSHPublicKey,
+	}
+}
+
+// Generate returns a new private key and its public key in authorized_keys
+// format. The key type defaults to rsa, bits only apply to rsa keys.
+func (g SSHKeyPairGenerator) Generate(keyType string, bits int) (string, string, error) {
+	switch keyType {
+	case "", RSASSHKeyType:
+		return g.generateRSA(bits)
+	case ED25519SSHKeyType:
+		if bits != 0 {
+			return "", "", errors.New("ssh key size cannot be set for ed25519 keys")
+		}
+		return g.generateED25519()
+	default:
+		return "", "", fmt.Errorf("ssh key type must be %s or %s", RSASSHKeyType, ED25519SSHKeyType)
+	}
+}
+
+func (g SSHKeyPairGenerator) generateRSA(bits int) (string, string, error) {
+	if bits == 0 {
+		bits = DefaultSSHKeyBits
+	}
+
+	if bits < MinimumSSHKeyBits {
+		return "", "", fmt.Errorf("ssh key size must be at least %d bits", MinimumSSHKeyBits)
+	}
+
+	rsaKey, err := g.generateKey(g.random, bits)
+	if err != nil {
+		return "", "", err
+	}
+
+	publicKey, err := g.generateSSHPublicKey(rsaKey.Public())
+	if err != nil {
+		return "", "", err
+	}
+
+	privateKey := pem.EncodeToMemory(
+		&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(rsaKey),
+		},
+	)
+
+	return string(privateKey), marshalAuthorizedKey(publicKey), nil
+}
+
+func (g SSHKeyPairGenerator) generateED25519() (string, string, error) {
+	public, private, err := ed25519.GenerateKey(g.random)
+	if err != nil {
+		return "", "", err
+	}
+
+	publicKey, err := g.generateSSHPublicKey(public)
+	if err != nil {
+		return "", "", err
+	}
+
+	privateKey, err := marshalOpenSSHPrivateKey(g.random, private)
+	if err != nil {
+		return "", "", err
+	}
+
+	return string(privateKey), marshalAuthorizedKey(publicKey), nil
+}
+
+// ParseSSHKeyPair validates a user supplied private key and returns it along
+// with its public key in authorized_keys format. When a public key is given it
+// must match the private key.
+func ParseSSHKeyPair(privateKey, publicKey []byte) (string, string, error) {
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return "", "", errors.New("ssh private key is not PEM encoded")
+	}
+
+	if x509.IsEncryptedPEMBlock(block) {
+		return "", "", errors.New("ssh private key must not be encrypted with a passphrase")
+	}
+
+	rawKey, err := ssh.ParseRawPrivateKey(privateKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse ssh private key: %s", err)
+	}
+
+	if rsaKey, ok := rawKey.(*rsa.PrivateKey); ok && rsaKey.N.BitLen() < MinimumSSHKeyBits {
+		return "", "", fmt.Errorf("ssh private key must be at least %d bits", MinimumSSHKeyBits)
+	}
+
+	signer, err := ssh.NewSignerFromKey(rawKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse ssh private key: %s", err)
+	}
+
+	if len(bytes.TrimSpace(publicKey)) != 0 {
+		parsedPublicKey, _, _, _, err := ssh.ParseAuthorizedKey(publicKey)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to parse ssh public key: %s", err)
+		}
+
+		if !bytes.Equal(parsedPublicKey.Marshal(), signer.PublicKey().Marshal()) {
+			return "", "", errors.New("ssh public key does not match ssh private key")
+		}
+	}
+
+	return string(privateKey), marshalAuthorizedKey(signer.PublicKey()), nil
+}
+
+func marshalAuthorizedKey(publicKey ssh.PublicKey) string {
+	return strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(publicKey)), "\n")
+}
@@ -0,0 +1,222 @@
+package ssl_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/ssl"
+
+	"golang.org/x/crypto/ssh"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SSHKeyPairGenerator", func() {
+	var (
+		generator               ssl.SSHKeyPairGenerator
+		fakePrivateKeyGenerator *fakes.PrivateKeyGenerator
+	)
+
+	BeforeEach(func() {
+		fakePrivateKeyGenerator = &fakes.PrivateKeyGenerator{}
+		fakePrivateKeyGenerator.GenerateKeyCall.Stub = func() (*rsa.PrivateKey, error) {
+			return rsa.GenerateKey(rand.Reader, 2048)
+		}
+
+		generator = ssl.NewSSHKeyPairGenerator(rand.Reader, fakePrivateKeyGenerator.GenerateKey, ssh.NewPublicKey)
+	})
+
+	Describe("Generate", func() {
+		It("generates an rsa keypair", func() {
+			privateKey, publicKey, err := generator.Generate("", 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(publicKey).NotTo(ContainSubstring("\n"))
+
+			pemBlock, rest := pem.Decode([]byte(privateKey))
+			Expect(rest).To(HaveLen(0))
+			Expect(pemBlock.Type).To(Equal("RSA PRIVATE KEY"))
+
+			parsedPrivateKey, err := x509.ParsePKCS1PrivateKey(pemBlock.Bytes)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = parsedPrivateKey.Validate()
+			Expect(err).NotTo(HaveOccurred())
+
+			newPublicKey, err := ssh.NewPublicKey(parsedPrivateKey.Public())
+			Expect(err).NotTo(HaveOccurred())
+
+			rawPublicKey := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(newPublicKey)), "\n")
+			Expect(rawPublicKey).To(Equal(publicKey))
+		})
+
+		It("defaults to a 2048 bit key", func() {
+			_, _, err := generator.Generate("", 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakePrivateKeyGenerator.GenerateKeyCall.Receives[0].Bits).To(Equal(2048))
+		})
+
+		It("generates a key with the requested number of bits", func() {
+			_, _, err := generator.Generate("", 4096)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakePrivateKeyGenerator.GenerateKeyCall.Receives[0].Bits).To(Equal(4096))
+		})
+
+		It("generates an ed25519 keypair", func() {
+			privateKey, publicKey, err := generator.Generate("ed25519", 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(publicKey).To(HavePrefix("ssh-ed25519 "))
+			Expect(publicKey).NotTo(ContainSubstring("\n"))
+
+			pemBlock, rest := pem.Decode([]byte(privateKey))
+			Expect(rest).To(HaveLen(0))
+			Expect(pemBlock.Type).To(Equal("OPENSSH PRIVATE KEY"))
+
+			signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+			Expect(err).NotTo(HaveOccurred())
+
+			rawPublicKey := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(signer.PublicKey())), "\n")
+			Expect(rawPublicKey).To(Equal(publicKey))
+
+			Expect(fakePrivateKeyGenerator.GenerateKeyCall.CallCount).To(Equal(0))
+		})
+
+		It("generates an ed25519 keypair that ParseSSHKeyPair accepts", func() {
+			privateKey, publicKey, err := generator.Generate("ed25519", 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			parsedPrivateKey, parsedPublicKey, err := ssl.ParseSSHKeyPair([]byte(privateKey), []byte(publicKey))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsedPrivateKey).To(Equal(privateKey))
+			Expect(parsedPublicKey).To(Equal(publicKey))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when bits are requested for an ed25519 key", func() {
+				_, _, err := generator.Generate("ed25519", 4096)
+				Expect(err).To(MatchError("ssh key size cannot be set for ed25519 keys"))
+			})
+
+			It("returns an error when the key type is unknown", func() {
+				_, _, err := generator.Generate("dsa", 0)
+				Expect(err).To(MatchError("ssh key type must be rsa or ed25519"))
+			})
+
+			It("returns an error when the requested number of bits is too small", func() {
+				_, _, err := generator.Generate("", 1024)
+				Expect(err).To(MatchError("ssh key size must be at least 2048 bits"))
+
+				Expect(fakePrivateKeyGenerator.GenerateKeyCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the rsa key generator fails", func() {
+				fakePrivateKeyGenerator.GenerateKeyCall.Stub = nil
+				fakePrivateKeyGenerator.GenerateKeyCall.Returns.Error = errors.New("rsa key generator failed")
+
+				_, _, err := generator.Generate("", 0)
+				Expect(err).To(MatchError("rsa key generator failed"))
+			})
+
+			It("returns an error when the ssh public key generator fails", func() {
+				generator = ssl.NewSSHKeyPairGenerator(rand.Reader, rsa.GenerateKey,
+					func(_ interface{}) (ssh.PublicKey, error) {
+						return nil, errors.New("ssh public key gen failed")
+					})
+
+				_, _, err := generator.Generate("", 0)
+				Expect(err).To(MatchError("ssh public key gen failed"))
+			})
+		})
+	})
+
+	Describe("ParseSSHKeyPair", func() {
+		var (
+			privateKey []byte
+			publicKey  []byte
+		)
+
+		BeforeEach(func() {
+			privateKey = []byte(privateKeyPEM)
+
+			rsaKey, err := ssh.ParseRawPrivateKey(privateKey)
+			Expect(err).NotTo(HaveOccurred())
+
+			sshPublicKey, err := ssh.NewPublicKey(rsaKey.(*rsa.PrivateKey).Public())
+			Expect(err).NotTo(HaveOccurred())
+
+			publicKey = ssh.MarshalAuthorizedKey(sshPublicKey)
+		})
+
+		It("returns the private key and the matching public key", func() {
+			parsedPrivateKey, parsedPublicKey, err := ssl.ParseSSHKeyPair(privateKey, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(parsedPrivateKey).To(Equal(privateKeyPEM))
+			Expect(parsedPublicKey).To(Equal(strings.TrimSuffix(string(publicKey), "\n")))
+		})
+
+		It("accepts a matching public key with a comment", func() {
+			publicKey = append([]byte(strings.TrimSpace(string(publicKey))), []byte(" someone@example.com\n")...)
+
+			_, parsedPublicKey, err := ssl.ParseSSHKeyPair(privateKey, publicKey)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(parsedPublicKey).To(HavePrefix("ssh-rsa "))
+			Expect(parsedPublicKey).NotTo(ContainSubstring("someone@example.com"))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the private key is not PEM encoded", func() {
+				_, _, err := ssl.ParseSSHKeyPair([]byte("not-a-key"), nil)
+				Expect(err).To(MatchError("ssh private key is not PEM encoded"))
+			})
+
+			It("returns an error when the private key is encrypted", func() {
+				block, err := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY", []byte("some-key"), []byte("some-passphrase"), x509.PEMCipherAES256)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _, err = ssl.ParseSSHKeyPair(pem.EncodeToMemory(block), nil)
+				Expect(err).To(MatchError("ssh private key must not be encrypted with a passphrase"))
+			})
+
+			It("returns an error when the private key cannot be parsed", func() {
+				_, _, err := ssl.ParseSSHKeyPair(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: []byte("garbage")}), nil)
+				Expect(err.Error()).To(HavePrefix("failed to parse ssh private key"))
+			})
+
+			It("returns an error when the rsa private key is too small", func() {
+				smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _, err = ssl.ParseSSHKeyPair(pem.EncodeToMemory(&pem.Block{
+					Type:  "RSA PRIVATE KEY",
+					Bytes: x509.MarshalPKCS1PrivateKey(smallKey),
+				}), nil)
+				Expect(err).To(MatchError("ssh private key must be at least 2048 bits"))
+			})
+
+			It("returns an error when the public key cannot be parsed", func() {
+				_, _, err := ssl.ParseSSHKeyPair(privateKey, []byte("not-a-public-key"))
+				Expect(err.Error()).To(HavePrefix("failed to parse ssh public key"))
+			})
+
+			It("returns an error when the public key does not match the private key", func() {
+				otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+				Expect(err).NotTo(HaveOccurred())
+
+				otherPublicKey, err := ssh.NewPublicKey(otherKey.Public())
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _, err = ssl.ParseSSHKeyPair(privateKey, ssh.MarshalAuthorizedKey(otherPublicKey))
+				Expect(err).To(MatchError("ssh public key does not match ssh private key"))
+			})
+		})
+	})
+})
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"time"
 )
 
 var (
@@ -26,6 +27,7 @@ type AWS struct {
 	AccessKeyID     string `json:"accessKeyId"`
 	SecretAccessKey string `json:"secretAccessKey"`
 	Region          string `json:"region"`
+	DualStack       bool   `json:"dualStack,omitempty"`
 }
 
 type GCP struct {
@@ -43,24 +45,133 @@ type Stack struct {
 }
 
 type LB struct {
-	Type   string `json:"type"`
-	Cert   string `json:"cert"`
-	Key    string `json:"key"`
-	Domain string `json:"domain,omitempty"`
+	Type            string          `json:"type"`
+	Cert            string          `json:"cert"`
+	Key             string          `json:"key"`
+	Domain          string          `json:"domain,omitempty"`
+	ParentZone      string          `json:"parentZone,omitempty"`
+	DNSRecords      []DNSRecord     `json:"dnsRecords,omitempty"`
+	SNICertificates []LBCertificate `json:"sniCertificates,omitempty"`
+}
+
+// DNSRecord is a record of the dns zone created for Domain, next to the
+// records bbl keeps for the cf lb. Name is relative to the zone, "@" being
+// the zone itself.
+type DNSRecord struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	TTL     int      `json:"ttl"`
+	Records []string `json:"records"`
+}
+
+// LBCertificate is an additional certificate an lb serves through SNI. On
+// AWS it is uploaded to IAM under Name, on GCP its contents are kept here.
+type LBCertificate struct {
+	Name string `json:"name,omitempty"`
+	ARN  string `json:"arn,omitempty"`
+	Cert string `json:"cert,omitempty"`
+	Key  string `json:"key,omitempty"`
+}
+
+// LBSpec is a named load balancer, which can coexist with the unnamed lb
+// of the environment and with other named lbs. It is either declared with
+// create-lbs --spec or, when Type is set, one of the cf and concourse lb
+// types created with create-lbs --name. On AWS, Flavor picks a classic elb,
+// an alb or an nlb, and PreviousFlavor is the flavor that stays attached
+// while the lb migrates to a new one.
+type LBSpec struct {
+	Name            string          `json:"name"`
+	Type            string          `json:"type,omitempty"`
+	Ports           []LBPort        `json:"ports"`
+	HealthCheck     LBHealthCheck   `json:"healthCheck"`
+	CertificateName string          `json:"certificateName,omitempty"`
+	CertificateARN  string          `json:"certificateARN,omitempty"`
+	Cert            string          `json:"cert,omitempty"`
+	Key             string          `json:"key,omitempty"`
+	Domain          string          `json:"domain,omitempty"`
+	ParentZone      string          `json:"parentZone,omitempty"`
+	DNSRecords      []DNSRecord     `json:"dnsRecords,omitempty"`
+	Flavor          string          `json:"flavor,omitempty"`
+	PreviousFlavor  string          `json:"previousFlavor,omitempty"`
+	SNICertificates []LBCertificate `json:"sniCertificates,omitempty"`
+}
+
+// LBCerts holds what bbl needs to renew the certificates it generated for
+// lbs created with --generate-cert: the certificate authority of
+// self-signed certificates, the ACME account of certificates issued by an
+// ACME server and, for each such lb, how its certificate was generated.
+type LBCerts struct {
+	CA               string            `json:"ca,omitempty"`
+	CAPrivateKey     string            `json:"caPrivateKey,omitempty"`
+	ACMEDirectoryURL string            `json:"acmeDirectoryURL,omitempty"`
+	ACMEEmail        string            `json:"acmeEmail,omitempty"`
+	ACMEAccountKey   string            `json:"acmeAccountKey,omitempty"`
+	GeneratedCerts   []LBGeneratedCert `json:"generatedCerts,omitempty"`
+}
+
+// LBGeneratedCert is a certificate bbl generated for the lb named LB, or
+// for the lb created without --name when LB is empty. Mode is either
+// "self-signed" or "acme". NotAfter is the expiry of the certificate,
+// which update-lbs renews when it is close.
+type LBGeneratedCert struct {
+	LB       string    `json:"lb,omitempty"`
+	Mode     string    `json:"mode"`
+	Domain   string    `json:"domain"`
+	NotAfter time.Time `json:"notAfter"`
+}
+
+type LBPort struct {
+	Port         int    `json:"port"`
+	InstancePort int    `json:"instancePort"`
+	Protocol     string `json:"protocol"`
+}
+
+type LBHealthCheck struct {
+	Protocol string `json:"protocol"`
+	Port     int    `json:"port"`
+	Path     string `json:"path,omitempty"`
+}
+
+type RuntimeConfig struct {
+	Contents string `json:"contents,omitempty"`
+}
+
+type CloudConfig struct {
+	OpsFiles            []string `json:"opsFiles,omitempty"`
+	SizingProfile       string   `json:"sizingProfile,omitempty"`
+	CustomSizingProfile string   `json:"customSizingProfile,omitempty"`
+
+	Networks map[string]CloudConfigNetwork `json:"networks,omitempty"`
+}
+
+type CloudConfigNetwork struct {
+	ReservedIPCount int      `json:"reservedIPCount,omitempty"`
+	StaticIPCount   int      `json:"staticIPCount,omitempty"`
+	StaticIPs       []string `json:"staticIPs,omitempty"`
 }
 
 type State struct {
-	Version    int     `json:"version"`
-	IAAS       string  `json:"iaas"`
-	NoDirector bool    `json:"noDirector"`
-	AWS        AWS     `json:"aws,omitempty"`
-	GCP        GCP     `json:"gcp,omitempty"`
-	KeyPair    KeyPair `json:"keyPair,omitempty"`
-	BOSH       BOSH    `json:"bosh,omitempty"`
-	Stack      Stack   `json:"stack"`
-	EnvID      string  `json:"envID"`
-	TFState    string  `json:"tfState"`
-	LB         LB      `json:"lb"`
+	Version    int      `json:"version"`
+	IAAS       string   `json:"iaas"`
+	NoDirector bool     `json:"noDirector"`
+	AWS        AWS      `json:"aws,omitempty"`
+	GCP        GCP      `json:"gcp,omitempty"`
+	KeyPair    KeyPair  `json:"keyPair,omitempty"`
+	BOSH       BOSH     `json:"bosh,omitempty"`
+	Stack      Stack    `json:"stack"`
+	EnvID      string   `json:"envID"`
+	TFState    string   `json:"tfState"`
+	LB         LB       `json:"lb"`
+	LBs        []LBSpec `json:"lbs,omitempty"`
+	LBCerts    LBCerts  `json:"lbCerts"`
+
+	// Tags are the KEY=VALUE tags given to up. They tag the aws resources
+	// bbl creates, label the gcp resources that support labels and tag the
+	// vms the director creates.
+	Tags map[string]string `json:"tags,omitempty"`
+
+	CloudConfig   CloudConfig   `json:"cloudConfig"`
+	RuntimeConfig RuntimeConfig `json:"runtimeConfig"`
 }
 
 type Store struct {
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
@@ -56,6 +57,33 @@ var _ = Describe("Store", func() {
 					Cert: "some-cert",
 					Key:  "some-key",
 				},
+				LBs: []storage.LBSpec{
+					{
+						Name: "some-lb",
+						Ports: []storage.LBPort{
+							{Port: 443, InstancePort: 8443, Protocol: "ssl"},
+						},
+						HealthCheck: storage.LBHealthCheck{
+							Protocol: "http",
+							Port:     8080,
+							Path:     "/health",
+						},
+						CertificateName: "some-lb-certificate-name",
+						CertificateARN:  "some-lb-certificate-arn",
+					},
+					{
+						Name:           "some-cf",
+						Type:           "cf",
+						Cert:           "some-cf-cert",
+						Key:            "some-cf-key",
+						Domain:         "some-cf-domain",
+						Flavor:         "alb",
+						PreviousFlavor: "elb",
+						SNICertificates: []storage.LBCertificate{
+							{Cert: "some-sni-cert", Key: "some-sni-key"},
+						},
+					},
+				},
 				BOSH: storage.BOSH{
 					DirectorName:           "some-director-name",
 					DirectorUsername:       "some-director-username",
@@ -93,6 +121,23 @@ var _ = Describe("Store", func() {
 				},
 				EnvID:   "some-env-id",
 				TFState: "some-tf-state",
+				LBCerts: storage.LBCerts{
+					CA:             "some-lb-ca",
+					CAPrivateKey:   "some-lb-ca-private-key",
+					GeneratedCerts: []storage.LBGeneratedCert{{LB: "some-cf", Mode: "self-signed", Domain: "some-cf-domain", NotAfter: time.Date(2018, time.May, 26, 22, 13, 41, 0, time.UTC)}},
+				},
+				CloudConfig: storage.CloudConfig{
+					OpsFiles: []string{"some-cloud-config-ops"},
+					Networks: map[string]storage.CloudConfigNetwork{
+						"private": {
+							StaticIPCount: 128,
+							StaticIPs:     []string{"10.0.31.190"},
+						},
+					},
+				},
+				RuntimeConfig: storage.RuntimeConfig{
+					Contents: "some-runtime-config",
+				},
 			})
 			Expect(err).NotTo(HaveOccurred())
 
@@ -123,6 +168,24 @@ var _ = Describe("Store", func() {
 					"cert": "some-cert",
 					"key": "some-key"
 				},
+				"lbs": [{
+					"name": "some-lb",
+					"ports": [{"port": 443, "instancePort": 8443, "protocol": "ssl"}],
+					"healthCheck": {"protocol": "http", "port": 8080, "path": "/health"},
+					"certificateName": "some-lb-certificate-name",
+					"certificateARN": "some-lb-certificate-arn"
+				}, {
+					"name": "some-cf",
+					"type": "cf",
+					"ports": null,
+					"healthCheck": {"protocol": "", "port": 0},
+					"cert": "some-cf-cert",
+					"key": "some-cf-key",
+					"domain": "some-cf-domain",
+					"flavor": "alb",
+					"previousFlavor": "elb",
+					"sniCertificates": [{"cert": "some-sni-cert", "key": "some-sni-key"}]
+				}],
 				"bosh":{
 					"directorName": "some-director-name",
 					"directorUsername": "some-director-username",
@@ -160,7 +223,24 @@ var _ = Describe("Store", func() {
 					"boshAZ": "some-bosh-az"
 				},
 				"envID": "some-env-id",
-				"tfState": "some-tf-state"
+				"tfState": "some-tf-state",
+				"lbCerts": {
+					"ca": "some-lb-ca",
+					"caPrivateKey": "some-lb-ca-private-key",
+					"generatedCerts": [{"lb": "some-cf", "mode": "self-signed", "domain": "some-cf-domain", "notAfter": "2018-05-26T22:13:41Z"}]
+				},
+				"cloudConfig": {
+					"opsFiles": ["some-cloud-config-ops"],
+					"networks": {
+						"private": {
+							"staticIPCount": 128,
+							"staticIPs": ["10.0.31.190"]
+						}
+					}
+				},
+				"runtimeConfig": {
+					"contents": "some-runtime-config"
+				}
 			}`))
 
 			fileInfo, err := os.Stat(filepath.Join(tempDir, "bbl-state.json"))
@@ -109,9 +109,7 @@ resource "aws_security_group" "nat_security_group" {
     security_groups = ["${aws_security_group.internal_security_group.id}"]
   }
 
-  tags {
-    Name = "${var.env_id}-nat-security-group"
-  }
+  tags = "${merge(var.tags, map("Name", "${var.env_id}-nat-security-group"))}"
 }
 
 variable "nat_ssh_key_pair_name" {}
@@ -125,9 +123,7 @@ resource "aws_instance" "nat" {
   key_name               = "${var.nat_ssh_key_pair_name}"
   vpc_security_group_ids = ["${aws_security_group.nat_security_group.id}"]
 
-  tags {
-    Name = "${var.env_id}-nat"
-  }
+  tags = "${merge(var.tags, map("Name", "${var.env_id}-nat"))}"
 }
 
 resource "aws_eip" "nat_eip" {
@@ -182,9 +178,7 @@ resource "aws_security_group" "internal_security_group" {
     to_port      = -1
   }
 
-  tags {
-    Name = "${var.env_id}-internal-security-group"
-  }
+  tags = "${merge(var.tags, map("Name", "${var.env_id}-internal-security-group"))}"
 }
 
 output "internal_security_group" {
@@ -235,9 +229,7 @@ resource "aws_security_group" "bosh_security_group" {
     security_groups = ["${aws_security_group.internal_security_group.id}"]
   }
 
-  tags {
-    Name = "${var.env_id}-bosh-security-group"
-  }
+  tags = "${merge(var.tags, map("Name", "${var.env_id}-bosh-security-group"))}"
 }
 
 output "bosh_security_group" {
@@ -276,13 +268,12 @@ resource "aws_subnet" "bosh_subnet" {
   cidr_block        = "${var.bosh_subnet_cidr}"
   availability_zone = "${var.bosh_availability_zone}"
 
-  tags {
-    Name = "${var.env_id}-bosh-subnet"
-  }
+  tags = "${merge(var.tags, map("Name", "${var.env_id}-bosh-subnet"))}"
 }
 
 resource "aws_route_table" "bosh_route_table" {
   vpc_id = "${aws_vpc.vpc.id}"
+  tags   = "${var.tags}"
 
   route {
     cidr_block = "0.0.0.0/0"
@@ -313,13 +304,12 @@ resource "aws_subnet" "internal_subnets" {
   cidr_block        = "${cidrsubnet("10.0.0.0/16", 4, count.index+1)}"
   availability_zone = "${element(var.availability_zones, count.index)}"
 
-  tags {
-    Name = "${var.env_id}-internal-subnet${count.index}"
-  }
+  tags = "${merge(var.tags, map("Name", "${var.env_id}-internal-subnet${count.index}"))}"
 }
 
 resource "aws_route_table" "internal_route_table" {
   vpc_id = "${aws_vpc.vpc.id}"
+  tags   = "${var.tags}"
 
   route {
     cidr_block = "0.0.0.0/0"
@@ -349,6 +339,11 @@ variable "env_id" {
   type = "string"
 }
 
+variable "tags" {
+  type    = "map"
+  default = {}
+}
+
 variable "vpc_cidr" {
   type = "string"
   default = "10.0.0.0/16"
@@ -359,13 +354,12 @@ resource "aws_vpc" "vpc" {
   instance_tenancy     = "default"
   enable_dns_hostnames = true
 
-  tags {
-    Name = "${var.env_id}-vpc"
-  }
+  tags = "${merge(var.tags, map("Name", "${var.env_id}-vpc"))}"
 }
 
 resource "aws_internet_gateway" "ig" {
   vpc_id = "${aws_vpc.vpc.id}"
+  tags   = "${var.tags}"
 }
 
 output "vpc_id" {
@@ -378,13 +372,12 @@ resource "aws_subnet" "lb_subnets" {
   cidr_block        = "${cidrsubnet("10.0.0.0/20", 4, count.index+2)}"
   availability_zone = "${element(var.availability_zones, count.index)}"
 
-  tags {
-    Name = "${var.env_id}-lb-subnet${count.index}"
-  }
+  tags = "${merge(var.tags, map("Name", "${var.env_id}-lb-subnet${count.index}"))}"
 }
 
 resource "aws_route_table" "lb_route_table" {
   vpc_id = "${aws_vpc.vpc.id}"
+  tags   = "${var.tags}"
 
   route {
     cidr_block = "0.0.0.0/0"
@@ -436,9 +429,7 @@ resource "aws_security_group" "concourse_lb_security_group" {
     to_port     = 443
   }
 
-  tags {
-    Name = "${var.env_name}-concourse-lb-security-group"
-  }
+  tags = "${merge(var.tags, map("Name", "${var.env_id}-concourse-lb-security-group"))}"
 }
 
 resource "aws_security_group" "concourse_lb_internal_security_group" {
@@ -460,13 +451,11 @@ resource "aws_security_group" "concourse_lb_internal_security_group" {
     to_port     = 2222
   }
 
-  tags {
-    Name = "${var.env_name}-concourse-lb-internal-security-group"
-  }
+  tags = "${merge(var.tags, map("Name", "${var.env_id}-concourse-lb-internal-security-group"))}"
 }
 
 resource "aws_elb" "concourse_lb" {
-  name                      = "${var.env_name}-concourse-lb"
+  name                      = "${var.env_id}-concourse-lb"
   cross_zone_load_balancing = true
 
   health_check {
@@ -501,6 +490,8 @@ resource "aws_elb" "concourse_lb" {
 
   security_groups = ["${aws_security_group.concourse_lb_security_group.id}"]
   subnets         = ["${aws_subnet.lb_subnets.*.id}"]
+
+  tags = "${var.tags}"
 }
 
 output "concourse_lb_name" {
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/cloudfoundry/bosh-bootloader/storage"
+	"github.com/cloudfoundry/bosh-bootloader/terraform"
 )
 
 type InputGenerator struct {
@@ -33,7 +34,7 @@ func (i InputGenerator) Generate(state storage.State) (map[string]string, error)
 		return map[string]string{}, err
 	}
 
-	return map[string]string{
+	input := map[string]string{
 		"env_id":                 state.EnvID,
 		"nat_ssh_key_pair_name":  state.KeyPair.Name,
 		"access_key":             state.AWS.AccessKeyID,
@@ -41,5 +42,11 @@ func (i InputGenerator) Generate(state storage.State) (map[string]string, error)
 		"region":                 state.AWS.Region,
 		"bosh_availability_zone": state.Stack.BOSHAZ,
 		"availability_zones":     string(azsString),
-	}, nil
+	}
+
+	if len(state.Tags) > 0 {
+		input["tags"] = terraform.MapVar(state.Tags)
+	}
+
+	return input, nil
 }
@@ -61,6 +61,16 @@ var _ = Describe("InputGenerator", func() {
 		}))
 	})
 
+	It("returns the tags of the state", func() {
+		inputs, err := inputGenerator.Generate(storage.State{
+			IAAS: "aws",
+			Tags: map[string]string{"owner": "platform", "cost-center": "1234"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(inputs).To(HaveKeyWithValue("tags", `{"cost-center"="1234", "owner"="platform"}`))
+	})
+
 	Context("failure cases", func() {
 		Context("when the availability zone retriever fails", func() {
 			It("returns an error", func() {
@@ -34,6 +34,8 @@ func (o OutputGenerator) Generate(state storage.State) (map[string]interface{},
 		"internal_security_group":       "internal_security_group",
 		"internal_subnet_ids":           "internal_subnet_ids",
 		"internal_subnet_cidrs":         "internal_subnet_cidrs",
+		"vpc_ipv6_cidr":                 "vpc_ipv6_cidr",
+		"internal_subnet_ipv6_cidrs":    "internal_subnet_ipv6_cidrs",
 	}
 
 	for tfKey, outputKey := range outputMapping {
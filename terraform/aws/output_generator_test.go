@@ -67,6 +67,25 @@ var _ = Describe("OutputGenerator", func() {
 		})
 	})
 
+	Context("when dual stack is enabled", func() {
+		It("returns the ipv6 outputs", func() {
+			executor.OutputsCall.Returns.Outputs["vpc_ipv6_cidr"] = "some-vpc-ipv6-cidr"
+			executor.OutputsCall.Returns.Outputs["internal_subnet_ipv6_cidrs"] = "some-internal-subnet-ipv6-cidrs"
+
+			outputs, err := outputGenerator.Generate(storage.State{
+				IAAS:    "aws",
+				TFState: "some-tf-state",
+				AWS: storage.AWS{
+					DualStack: true,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(outputs["vpc_ipv6_cidr"]).To(Equal("some-vpc-ipv6-cidr"))
+			Expect(outputs["internal_subnet_ipv6_cidrs"]).To(Equal("some-internal-subnet-ipv6-cidrs"))
+		})
+	})
+
 	Context("failure cases", func() {
 		Context("when the executor fails to retrieve the outputs", func() {
 			It("returns an error", func() {
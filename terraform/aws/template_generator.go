@@ -16,6 +16,12 @@ func NewTemplateGenerator() TemplateGenerator {
 func (t TemplateGenerator) Generate(state storage.State) string {
 	template := BaseTemplate
 
+	if state.AWS.DualStack {
+		template = dualStack(template)
+	}
+
+	// Only the unnamed lb is rendered, create-lbs rejects --name and --spec
+	// on environments created with --terraform.
 	switch state.LB.Type {
 	case "concourse":
 		template = strings.Join([]string{template, LBSubnetTemplate, ConcourseLBTemplate}, "\n")
@@ -25,3 +31,40 @@ func (t TemplateGenerator) Generate(state storage.State) string {
 
 	return template
 }
+
+// dualStack gives the VPC an amazon provided ipv6 block, carves a /64 out of
+// it for every internal subnet and routes their ipv6 traffic through an
+// egress only internet gateway.
+func dualStack(template string) string {
+	template = strings.Replace(template, `  instance_tenancy     = "default"
+  enable_dns_hostnames = true
+`, `  instance_tenancy     = "default"
+  enable_dns_hostnames = true
+  assign_generated_ipv6_cidr_block = true
+`, 1)
+
+	template = strings.Replace(template, `  cidr_block        = "${cidrsubnet("10.0.0.0/16", 4, count.index+1)}"
+  availability_zone = "${element(var.availability_zones, count.index)}"
+`, `  cidr_block        = "${cidrsubnet("10.0.0.0/16", 4, count.index+1)}"
+  ipv6_cidr_block   = "${cidrsubnet(aws_vpc.vpc.ipv6_cidr_block, 8, count.index+1)}"
+  availability_zone = "${element(var.availability_zones, count.index)}"
+  assign_ipv6_address_on_creation = true
+`, 1)
+
+	template = strings.Replace(template, `  route {
+    cidr_block = "0.0.0.0/0"
+    instance_id = "${aws_instance.nat.id}"
+  }
+`, `  route {
+    cidr_block = "0.0.0.0/0"
+    instance_id = "${aws_instance.nat.id}"
+  }
+
+  route {
+    ipv6_cidr_block = "::/0"
+    egress_only_gateway_id = "${aws_egress_only_internet_gateway.egress_only_ig.id}"
+  }
+`, 1)
+
+	return strings.Join([]string{template, DualStackTemplate}, "\n")
+}
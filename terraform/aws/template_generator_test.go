@@ -37,5 +37,17 @@ var _ = Describe("TemplateGenerator", func() {
 			Entry("when a concourse lb type is provided", "fixtures/template_concourse_lb.tf", "concourse"),
 			Entry("when a cf lb type is provided", "fixtures/template_cf_lb.tf", "cf"),
 		)
+
+		It("adds ipv6 to the vpc and internal subnets when dual stack is enabled", func() {
+			expectedTemplate, err := ioutil.ReadFile("fixtures/template_dual_stack.tf")
+			Expect(err).NotTo(HaveOccurred())
+
+			template := templateGenerator.Generate(storage.State{
+				AWS: storage.AWS{
+					DualStack: true,
+				},
+			})
+			Expect(template).To(Equal(string(expectedTemplate)))
+		})
 	})
 })
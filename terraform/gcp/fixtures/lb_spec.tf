@@ -0,0 +1,51 @@
+output "lb_credhub_uaa_target_pool" {
+  value = "${google_compute_target_pool.lb-credhub-uaa.name}"
+}
+
+output "lb_credhub_uaa_lb_ip" {
+  value = "${google_compute_address.lb-credhub-uaa.address}"
+}
+
+resource "google_compute_firewall" "lb-credhub-uaa" {
+  name    = "${var.env_id}-credhub-uaa-open"
+  network = "${google_compute_network.bbl-network.name}"
+
+  allow {
+    protocol = "tcp"
+    ports    = ["8844", "8443", "8080"]
+  }
+
+  target_tags = ["${google_compute_target_pool.lb-credhub-uaa.name}"]
+}
+
+resource "google_compute_address" "lb-credhub-uaa" {
+  name = "${var.env_id}-credhub-uaa"
+}
+
+resource "google_compute_http_health_check" "lb-credhub-uaa" {
+  name         = "${var.env_id}-credhub-uaa"
+  port         = 8080
+  request_path = "/healthz"
+}
+
+resource "google_compute_target_pool" "lb-credhub-uaa" {
+  name = "${var.env_id}-credhub-uaa"
+
+  health_checks = ["${google_compute_http_health_check.lb-credhub-uaa.name}"]
+}
+
+resource "google_compute_forwarding_rule" "lb-credhub-uaa-8844" {
+  name        = "${var.env_id}-credhub-uaa-8844"
+  target      = "${google_compute_target_pool.lb-credhub-uaa.self_link}"
+  port_range  = "8844"
+  ip_protocol = "TCP"
+  ip_address  = "${google_compute_address.lb-credhub-uaa.address}"
+}
+
+resource "google_compute_forwarding_rule" "lb-credhub-uaa-8443" {
+  name        = "${var.env_id}-credhub-uaa-8443"
+  target      = "${google_compute_target_pool.lb-credhub-uaa.self_link}"
+  port_range  = "8443"
+  ip_protocol = "TCP"
+  ip_address  = "${google_compute_address.lb-credhub-uaa.address}"
+}
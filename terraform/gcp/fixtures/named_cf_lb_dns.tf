@@ -0,0 +1,336 @@
+variable "lb_foundation_ssl_certificate" {
+  type = "string"
+}
+
+variable "lb_foundation_ssl_certificate_private_key" {
+  type = "string"
+}
+
+output "lb_foundation_router_backend_service" {
+  value = "${google_compute_backend_service.foundation-router-lb-backend-service.name}"
+}
+
+output "lb_foundation_router_lb_ip" {
+    value = "${google_compute_global_address.foundation-cf-address.address}"
+}
+
+output "lb_foundation_ssh_proxy_lb_ip" {
+    value = "${google_compute_address.foundation-cf-ssh-proxy.address}"
+}
+
+output "lb_foundation_tcp_router_lb_ip" {
+    value = "${google_compute_address.foundation-cf-tcp-router.address}"
+}
+
+output "lb_foundation_ws_lb_ip" {
+    value = "${google_compute_address.foundation-cf-ws.address}"
+}
+
+resource "google_compute_firewall" "foundation-firewall-cf" {
+  name       = "${var.env_id}-foundation-cf-open"
+  depends_on = ["google_compute_network.bbl-network"]
+  network    = "${google_compute_network.bbl-network.name}"
+
+  allow {
+    protocol = "tcp"
+    ports    = ["80", "443"]
+  }
+
+  source_ranges = ["0.0.0.0/0"]
+
+  target_tags = ["${google_compute_backend_service.foundation-router-lb-backend-service.name}"]
+}
+
+resource "google_compute_global_address" "foundation-cf-address" {
+  name = "${var.env_id}-foundation-cf"
+}
+
+resource "google_compute_global_forwarding_rule" "foundation-cf-http-forwarding-rule" {
+  name       = "${var.env_id}-foundation-cf-http"
+  ip_address = "${google_compute_global_address.foundation-cf-address.address}"
+  target     = "${google_compute_target_http_proxy.foundation-cf-http-lb-proxy.self_link}"
+  port_range = "80"
+}
+
+resource "google_compute_global_forwarding_rule" "foundation-cf-https-forwarding-rule" {
+  name       = "${var.env_id}-foundation-cf-https"
+  ip_address = "${google_compute_global_address.foundation-cf-address.address}"
+  target     = "${google_compute_target_https_proxy.foundation-cf-https-lb-proxy.self_link}"
+  port_range = "443"
+}
+
+resource "google_compute_target_http_proxy" "foundation-cf-http-lb-proxy" {
+  name        = "${var.env_id}-foundation-http-proxy"
+  description = "really a load balancer but listed as an http proxy"
+  url_map     = "${google_compute_url_map.foundation-cf-https-lb-url-map.self_link}"
+}
+
+resource "google_compute_target_https_proxy" "foundation-cf-https-lb-proxy" {
+  name             = "${var.env_id}-foundation-https-proxy"
+  description      = "really a load balancer but listed as an https proxy"
+  url_map          = "${google_compute_url_map.foundation-cf-https-lb-url-map.self_link}"
+  ssl_certificates = ["${google_compute_ssl_certificate.foundation-cf-cert.self_link}"]
+}
+
+resource "google_compute_ssl_certificate" "foundation-cf-cert" {
+  name_prefix = "${var.env_id}-foundation"
+  description = "user provided ssl private key / ssl certificate pair"
+  private_key = "${file(var.lb_foundation_ssl_certificate_private_key)}"
+  certificate = "${file(var.lb_foundation_ssl_certificate)}"
+  lifecycle {
+	create_before_destroy = true
+  }
+}
+
+resource "google_compute_url_map" "foundation-cf-https-lb-url-map" {
+  name = "${var.env_id}-foundation-cf-http"
+
+  default_service = "${google_compute_backend_service.foundation-router-lb-backend-service.self_link}"
+}
+
+resource "google_compute_http_health_check" "foundation-cf-public-health-check" {
+  name                = "${var.env_id}-foundation-cf"
+  port                = 8080
+  request_path        = "/health"
+}
+
+resource "google_compute_firewall" "foundation-cf-health-check" {
+  name       = "${var.env_id}-foundation-cf-health-check"
+  depends_on = ["google_compute_network.bbl-network"]
+  network    = "${google_compute_network.bbl-network.name}"
+
+  allow {
+    protocol = "tcp"
+    ports    = ["8080", "80"]
+  }
+
+  source_ranges = ["130.211.0.0/22"]
+  target_tags   = ["${google_compute_backend_service.foundation-router-lb-backend-service.name}"]
+}
+
+output "lb_foundation_ssh_proxy_target_pool" {
+  value = "${google_compute_target_pool.foundation-cf-ssh-proxy.name}"
+}
+
+resource "google_compute_address" "foundation-cf-ssh-proxy" {
+  name = "${var.env_id}-foundation-cf-ssh-proxy"
+}
+
+resource "google_compute_firewall" "foundation-cf-ssh-proxy" {
+  name       = "${var.env_id}-foundation-cf-ssh-proxy-open"
+  depends_on = ["google_compute_network.bbl-network"]
+  network    = "${google_compute_network.bbl-network.name}"
+
+  allow {
+    protocol = "tcp"
+    ports    = ["2222"]
+  }
+
+  target_tags = ["${google_compute_target_pool.foundation-cf-ssh-proxy.name}"]
+}
+
+resource "google_compute_target_pool" "foundation-cf-ssh-proxy" {
+  name = "${var.env_id}-foundation-cf-ssh-proxy"
+}
+
+resource "google_compute_forwarding_rule" "foundation-cf-ssh-proxy" {
+  name        = "${var.env_id}-foundation-cf-ssh-proxy"
+  target      = "${google_compute_target_pool.foundation-cf-ssh-proxy.self_link}"
+  port_range  = "2222"
+  ip_protocol = "TCP"
+  ip_address  = "${google_compute_address.foundation-cf-ssh-proxy.address}"
+}
+
+output "lb_foundation_tcp_router_target_pool" {
+  value = "${google_compute_target_pool.foundation-cf-tcp-router.name}"
+}
+
+resource "google_compute_firewall" "foundation-cf-tcp-router" {
+  name       = "${var.env_id}-foundation-cf-tcp-router"
+  depends_on = ["google_compute_network.bbl-network"]
+  network    = "${google_compute_network.bbl-network.name}"
+
+  allow {
+    protocol = "tcp"
+    ports    = ["1024-32768"]
+  }
+
+  target_tags = ["${google_compute_target_pool.foundation-cf-tcp-router.name}"]
+}
+
+resource "google_compute_address" "foundation-cf-tcp-router" {
+  name = "${var.env_id}-foundation-cf-tcp-router"
+}
+
+resource "google_compute_http_health_check" "foundation-cf-tcp-router" {
+  name                = "${var.env_id}-foundation-cf-tcp-router"
+  port                = 80
+  request_path        = "/health"
+}
+
+resource "google_compute_target_pool" "foundation-cf-tcp-router" {
+  name = "${var.env_id}-foundation-cf-tcp-router"
+
+  health_checks = [
+    "${google_compute_http_health_check.foundation-cf-tcp-router.name}",
+  ]
+}
+
+resource "google_compute_forwarding_rule" "foundation-cf-tcp-router" {
+  name        = "${var.env_id}-foundation-cf-tcp-router"
+  target      = "${google_compute_target_pool.foundation-cf-tcp-router.self_link}"
+  port_range  = "1024-32768"
+  ip_protocol = "TCP"
+  ip_address  = "${google_compute_address.foundation-cf-tcp-router.address}"
+}
+
+output "lb_foundation_ws_target_pool" {
+  value = "${google_compute_target_pool.foundation-cf-ws.name}"
+}
+
+resource "google_compute_address" "foundation-cf-ws" {
+  name = "${var.env_id}-foundation-cf-ws"
+}
+
+resource "google_compute_target_pool" "foundation-cf-ws" {
+  name = "${var.env_id}-foundation-cf-ws"
+
+  health_checks = ["${google_compute_http_health_check.foundation-cf-public-health-check.name}"]
+}
+
+resource "google_compute_forwarding_rule" "foundation-cf-ws-https" {
+  name        = "${var.env_id}-foundation-cf-ws-https"
+  target      = "${google_compute_target_pool.foundation-cf-ws.self_link}"
+  port_range  = "443"
+  ip_protocol = "TCP"
+  ip_address  = "${google_compute_address.foundation-cf-ws.address}"
+}
+
+resource "google_compute_forwarding_rule" "foundation-cf-ws-http" {
+  name        = "${var.env_id}-foundation-cf-ws-http"
+  target      = "${google_compute_target_pool.foundation-cf-ws.self_link}"
+  port_range  = "80"
+  ip_protocol = "TCP"
+  ip_address  = "${google_compute_address.foundation-cf-ws.address}"
+}
+
+resource "google_compute_instance_group" "foundation-router-lb-0" {
+  name        = "${var.env_id}-foundation-router-lb-0-z1"
+  description = "terraform generated instance group that is multi-zone for https loadbalancing"
+  zone        = "z1"
+}
+
+resource "google_compute_instance_group" "foundation-router-lb-1" {
+  name        = "${var.env_id}-foundation-router-lb-1-z2"
+  description = "terraform generated instance group that is multi-zone for https loadbalancing"
+  zone        = "z2"
+}
+
+resource "google_compute_backend_service" "foundation-router-lb-backend-service" {
+  name        = "${var.env_id}-foundation-router-lb"
+  port_name   = "http"
+  protocol    = "HTTP"
+  timeout_sec = 900
+  enable_cdn  = false
+
+  backend {
+    group = "${google_compute_instance_group.foundation-router-lb-0.self_link}"
+  }
+
+  backend {
+    group = "${google_compute_instance_group.foundation-router-lb-1.self_link}"
+  }
+
+  health_checks = ["${google_compute_http_health_check.foundation-cf-public-health-check.self_link}"]
+}
+
+variable "lb_foundation_system_domain" {
+  type = "string"
+}
+
+resource "google_dns_managed_zone" "foundation-env_dns_zone" {
+  name        = "${var.env_id}-foundation-zone"
+  dns_name    = "${var.lb_foundation_system_domain}."
+  description = "DNS zone for the ${var.env_id} environment"
+}
+
+output "lb_foundation_system_domain_dns_servers" {
+  value = "${google_dns_managed_zone.foundation-env_dns_zone.name_servers}"
+}
+
+resource "google_dns_record_set" "foundation-wildcard-dns" {
+  name       = "*.${google_dns_managed_zone.foundation-env_dns_zone.dns_name}"
+  depends_on = ["google_compute_global_address.foundation-cf-address"]
+  type       = "A"
+  ttl        = 300
+
+  managed_zone = "${google_dns_managed_zone.foundation-env_dns_zone.name}"
+
+  rrdatas = ["${google_compute_global_address.foundation-cf-address.address}"]
+}
+
+resource "google_dns_record_set" "foundation-bosh-dns" {
+  name       = "bosh.${google_dns_managed_zone.foundation-env_dns_zone.dns_name}"
+  depends_on = ["google_compute_address.bosh-external-ip"]
+  type       = "A"
+  ttl        = 300
+
+  managed_zone = "${google_dns_managed_zone.foundation-env_dns_zone.name}"
+
+  rrdatas = ["${google_compute_address.bosh-external-ip.address}"]
+}
+
+resource "google_dns_record_set" "foundation-cf-ssh-proxy" {
+  name       = "ssh.${google_dns_managed_zone.foundation-env_dns_zone.dns_name}"
+  depends_on = ["google_compute_address.foundation-cf-ssh-proxy"]
+  type       = "A"
+  ttl        = 300
+
+  managed_zone = "${google_dns_managed_zone.foundation-env_dns_zone.name}"
+
+  rrdatas = ["${google_compute_address.foundation-cf-ssh-proxy.address}"]
+}
+
+resource "google_dns_record_set" "foundation-tcp-dns" {
+  name       = "tcp.${google_dns_managed_zone.foundation-env_dns_zone.dns_name}"
+  depends_on = ["google_compute_address.foundation-cf-tcp-router"]
+  type       = "A"
+  ttl        = 300
+
+  managed_zone = "${google_dns_managed_zone.foundation-env_dns_zone.name}"
+
+  rrdatas = ["${google_compute_address.foundation-cf-tcp-router.address}"]
+}
+
+resource "google_dns_record_set" "foundation-doppler-dns" {
+  name       = "doppler.${google_dns_managed_zone.foundation-env_dns_zone.dns_name}"
+  depends_on = ["google_compute_address.foundation-cf-ws"]
+  type       = "A"
+  ttl        = 300
+
+  managed_zone = "${google_dns_managed_zone.foundation-env_dns_zone.name}"
+
+  rrdatas = ["${google_compute_address.foundation-cf-ws.address}"]
+}
+
+resource "google_dns_record_set" "foundation-loggregator-dns" {
+  name       = "loggregator.${google_dns_managed_zone.foundation-env_dns_zone.dns_name}"
+  depends_on = ["google_compute_address.foundation-cf-ws"]
+  type       = "A"
+  ttl        = 300
+
+  managed_zone = "${google_dns_managed_zone.foundation-env_dns_zone.name}"
+
+  rrdatas = ["${google_compute_address.foundation-cf-ws.address}"]
+}
+
+resource "google_dns_record_set" "foundation-wildcard-ws-dns" {
+  name       = "*.ws.${google_dns_managed_zone.foundation-env_dns_zone.dns_name}"
+  depends_on = ["google_compute_address.foundation-cf-ws"]
+  type       = "A"
+  ttl        = 300
+
+  managed_zone = "${google_dns_managed_zone.foundation-env_dns_zone.name}"
+
+  rrdatas = ["${google_compute_address.foundation-cf-ws.address}"]
+}
@@ -1,11 +1,13 @@
 package gcp
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 
 	"github.com/cloudfoundry/bosh-bootloader/storage"
+	"github.com/cloudfoundry/bosh-bootloader/terraform"
 )
 
 var tempDir func(dir, prefix string) (string, error) = ioutil.TempDir
@@ -39,6 +41,14 @@ func (i InputGenerator) Generate(state storage.State) (map[string]string, error)
 		"system_domain": state.LB.Domain,
 	}
 
+	if len(state.Tags) > 0 {
+		input["labels"] = terraform.MapVar(state.Tags)
+	}
+
+	if state.LB.ParentZone != "" {
+		input["parent_dns_zone"] = state.LB.ParentZone
+	}
+
 	if state.LB.Cert != "" && state.LB.Key != "" {
 		certPath := filepath.Join(dir, "cert")
 		err = writeFile(certPath, []byte(state.LB.Cert), os.ModePerm)
@@ -55,5 +65,77 @@ func (i InputGenerator) Generate(state storage.State) (map[string]string, error)
 		input["ssl_certificate_private_key"] = keyPath
 	}
 
+	err = writeSNICertificates(input, dir, "", state.LB.SNICertificates)
+	if err != nil {
+		return map[string]string{}, err
+	}
+
+	for _, spec := range state.LBs {
+		if spec.Type != "cf" {
+			continue
+		}
+
+		if spec.Domain != "" {
+			input[LBSpecOutputName(spec.Name, "system_domain")] = spec.Domain
+		}
+
+		if spec.ParentZone != "" {
+			input[LBSpecOutputName(spec.Name, "parent_dns_zone")] = spec.ParentZone
+		}
+
+		certPath := filepath.Join(dir, spec.Name+"-cert")
+		err = writeFile(certPath, []byte(spec.Cert), os.ModePerm)
+		if err != nil {
+			return map[string]string{}, err
+		}
+		input[LBSpecOutputName(spec.Name, "ssl_certificate")] = certPath
+
+		keyPath := filepath.Join(dir, spec.Name+"-key")
+		err = writeFile(keyPath, []byte(spec.Key), os.ModePerm)
+		if err != nil {
+			return map[string]string{}, err
+		}
+		input[LBSpecOutputName(spec.Name, "ssl_certificate_private_key")] = keyPath
+
+		err = writeSNICertificates(input, dir, spec.Name, spec.SNICertificates)
+		if err != nil {
+			return map[string]string{}, err
+		}
+	}
+
 	return input, nil
 }
+
+// writeSNICertificates writes the SNI certificates of the cf lb with the
+// given name, or of the unnamed cf lb, and adds their paths to the input.
+func writeSNICertificates(input map[string]string, dir, specName string, certificates []storage.LBCertificate) error {
+	variableName := func(variable string) string {
+		if specName == "" {
+			return variable
+		}
+		return LBSpecOutputName(specName, variable)
+	}
+
+	prefix := ""
+	if specName != "" {
+		prefix = specName + "-"
+	}
+
+	for i, certificate := range certificates {
+		certPath := filepath.Join(dir, fmt.Sprintf("%ssni-cert-%d", prefix, i))
+		err := writeFile(certPath, []byte(certificate.Cert), os.ModePerm)
+		if err != nil {
+			return err
+		}
+		input[variableName(fmt.Sprintf("sni_ssl_certificate_%d", i))] = certPath
+
+		keyPath := filepath.Join(dir, fmt.Sprintf("%ssni-key-%d", prefix, i))
+		err = writeFile(keyPath, []byte(certificate.Key), os.ModePerm)
+		if err != nil {
+			return err
+		}
+		input[variableName(fmt.Sprintf("sni_ssl_certificate_private_key_%d", i))] = keyPath
+	}
+
+	return nil
+}
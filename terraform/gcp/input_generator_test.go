@@ -99,6 +99,78 @@ var _ = Describe("InputGenerator", func() {
 		Expect(string(sslCertificatePrivateKey)).To(Equal("some-key"))
 	})
 
+	It("returns the cert, key and domain variables of named cf lbs", func() {
+		state.LBs = []storage.LBSpec{
+			{Name: "vault"},
+			{Name: "foundation", Type: "cf", Cert: "foundation-cert", Key: "foundation-key", Domain: "foundation-domain"},
+		}
+
+		inputs, err := inputGenerator.Generate(state)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(inputs).To(HaveKeyWithValue("lb_foundation_system_domain", "foundation-domain"))
+		Expect(inputs).To(HaveKeyWithValue("lb_foundation_ssl_certificate", filepath.Join(tempDir, "foundation-cert")))
+		Expect(inputs).To(HaveKeyWithValue("lb_foundation_ssl_certificate_private_key", filepath.Join(tempDir, "foundation-key")))
+		Expect(inputs).NotTo(HaveKey("lb_vault_ssl_certificate"))
+
+		sslCertificate, err := ioutil.ReadFile(inputs["lb_foundation_ssl_certificate"])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(sslCertificate)).To(Equal("foundation-cert"))
+
+		sslCertificatePrivateKey, err := ioutil.ReadFile(inputs["lb_foundation_ssl_certificate_private_key"])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(sslCertificatePrivateKey)).To(Equal("foundation-key"))
+	})
+
+	It("returns the parent dns zones of the unnamed and named cf lbs", func() {
+		state.LB.ParentZone = "some-parent-zone"
+		state.LBs = []storage.LBSpec{{Name: "foundation", Type: "cf", Domain: "foundation-domain", ParentZone: "other-parent-zone"}}
+
+		inputs, err := inputGenerator.Generate(state)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(inputs).To(HaveKeyWithValue("parent_dns_zone", "some-parent-zone"))
+		Expect(inputs).To(HaveKeyWithValue("lb_foundation_parent_dns_zone", "other-parent-zone"))
+	})
+
+	It("returns the tags of the state as labels", func() {
+		state.Tags = map[string]string{"owner": "platform", "cost-center": "1234"}
+
+		inputs, err := inputGenerator.Generate(state)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(inputs).To(HaveKeyWithValue("labels", `{"cost-center"="1234", "owner"="platform"}`))
+	})
+
+	It("returns the sni cert and key variables of the unnamed and named cf lbs", func() {
+		state.LB.Cert = "some-cert"
+		state.LB.Key = "some-key"
+		state.LB.SNICertificates = []storage.LBCertificate{{Cert: "some-sni-cert", Key: "some-sni-key"}}
+		state.LBs = []storage.LBSpec{{
+			Name:            "foundation",
+			Type:            "cf",
+			Cert:            "foundation-cert",
+			Key:             "foundation-key",
+			SNICertificates: []storage.LBCertificate{{Cert: "foundation-sni-cert", Key: "foundation-sni-key"}},
+		}}
+
+		inputs, err := inputGenerator.Generate(state)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(inputs).To(HaveKeyWithValue("sni_ssl_certificate_0", filepath.Join(tempDir, "sni-cert-0")))
+		Expect(inputs).To(HaveKeyWithValue("sni_ssl_certificate_private_key_0", filepath.Join(tempDir, "sni-key-0")))
+		Expect(inputs).To(HaveKeyWithValue("lb_foundation_sni_ssl_certificate_0", filepath.Join(tempDir, "foundation-sni-cert-0")))
+		Expect(inputs).To(HaveKeyWithValue("lb_foundation_sni_ssl_certificate_private_key_0", filepath.Join(tempDir, "foundation-sni-key-0")))
+
+		sslCertificate, err := ioutil.ReadFile(inputs["lb_foundation_sni_ssl_certificate_0"])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(sslCertificate)).To(Equal("foundation-sni-cert"))
+
+		sslCertificatePrivateKey, err := ioutil.ReadFile(inputs["sni_ssl_certificate_private_key_0"])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(sslCertificatePrivateKey)).To(Equal("some-sni-key"))
+	})
+
 	Context("failure cases", func() {
 		It("returns an error if temp dir cannot be created", func() {
 			gcp.SetTempDir(func(dir, prefix string) (string, error) {
@@ -6,6 +6,19 @@ import (
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
+// cfOutputs are the outputs of the cf lb template that bbl reads, named
+// lbs prefix them with LBSpecOutputName.
+var cfOutputs = []string{
+	"router_backend_service",
+	"ssh_proxy_target_pool",
+	"tcp_router_target_pool",
+	"ws_target_pool",
+	"router_lb_ip",
+	"ssh_proxy_lb_ip",
+	"tcp_router_lb_ip",
+	"ws_lb_ip",
+}
+
 type executor interface {
 	Output(string, string) (string, error)
 }
@@ -147,5 +160,30 @@ func (g OutputGenerator) Generate(bblState storage.State) (map[string]interface{
 		outputs["concourse_lb_ip"] = concourseLBIP
 	}
 
+	for _, spec := range bblState.LBs {
+		specOutputs := []string{"target_pool", "lb_ip"}
+		if spec.Type == "cf" {
+			specOutputs = cfOutputs
+		}
+
+		for _, output := range specOutputs {
+			name := LBSpecOutputName(spec.Name, output)
+			value, err := g.executor.Output(bblState.TFState, name)
+			if err != nil {
+				return map[string]interface{}{}, err
+			}
+			outputs[name] = value
+		}
+
+		if spec.Type == "cf" && spec.Domain != "" {
+			name := LBSpecOutputName(spec.Name, "system_domain_dns_servers")
+			dnsServersRaw, err := g.executor.Output(bblState.TFState, name)
+			if err != nil {
+				return map[string]interface{}{}, err
+			}
+			outputs[name] = strings.Split(dnsServersRaw, ",\n")
+		}
+	}
+
 	return outputs, nil
 }
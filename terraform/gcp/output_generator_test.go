@@ -287,6 +287,83 @@ var _ = Describe("OutputGenerator", func() {
 		})
 	})
 
+	Context("when lb specs exist", func() {
+		BeforeEach(func() {
+			executor.OutputCall.Stub = func(output string) (string, error) {
+				switch output {
+				case "lb_credhub_uaa_target_pool":
+					return "some-credhub-uaa-target-pool", nil
+				case "lb_credhub_uaa_lb_ip":
+					return "some-credhub-uaa-lb-ip", nil
+				default:
+					return "some-" + output, nil
+				}
+			}
+		})
+
+		It("returns terraform outputs related to every lb spec", func() {
+			outputs, err := outputGenerator.Generate(storage.State{
+				IAAS:    "gcp",
+				TFState: "some-tf-state",
+				LBs: []storage.LBSpec{
+					{Name: "credhub-uaa"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(outputs).To(HaveKeyWithValue("lb_credhub_uaa_target_pool", "some-credhub-uaa-target-pool"))
+			Expect(outputs).To(HaveKeyWithValue("lb_credhub_uaa_lb_ip", "some-credhub-uaa-lb-ip"))
+			Expect(outputs).NotTo(HaveKey("concourse_target_pool"))
+		})
+
+		It("returns the cf lb outputs of named cf lbs", func() {
+			executor.OutputCall.Stub = func(output string) (string, error) {
+				if output == "lb_foundation_system_domain_dns_servers" {
+					return "ns1.example.com,\nns2.example.com", nil
+				}
+				return "some-" + output, nil
+			}
+
+			outputs, err := outputGenerator.Generate(storage.State{
+				IAAS:    "gcp",
+				TFState: "some-tf-state",
+				LBs: []storage.LBSpec{
+					{Name: "foundation", Type: "cf", Domain: "some-domain"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(outputs).To(HaveKeyWithValue("lb_foundation_router_backend_service", "some-lb_foundation_router_backend_service"))
+			Expect(outputs).To(HaveKeyWithValue("lb_foundation_ssh_proxy_target_pool", "some-lb_foundation_ssh_proxy_target_pool"))
+			Expect(outputs).To(HaveKeyWithValue("lb_foundation_tcp_router_target_pool", "some-lb_foundation_tcp_router_target_pool"))
+			Expect(outputs).To(HaveKeyWithValue("lb_foundation_ws_target_pool", "some-lb_foundation_ws_target_pool"))
+			Expect(outputs).To(HaveKeyWithValue("lb_foundation_router_lb_ip", "some-lb_foundation_router_lb_ip"))
+			Expect(outputs).To(HaveKeyWithValue("lb_foundation_ssh_proxy_lb_ip", "some-lb_foundation_ssh_proxy_lb_ip"))
+			Expect(outputs).To(HaveKeyWithValue("lb_foundation_tcp_router_lb_ip", "some-lb_foundation_tcp_router_lb_ip"))
+			Expect(outputs).To(HaveKeyWithValue("lb_foundation_ws_lb_ip", "some-lb_foundation_ws_lb_ip"))
+			Expect(outputs).To(HaveKeyWithValue("lb_foundation_system_domain_dns_servers", []string{"ns1.example.com", "ns2.example.com"}))
+			Expect(outputs).NotTo(HaveKey("lb_foundation_target_pool"))
+		})
+
+		It("returns an error when the outputter fails", func() {
+			executor.OutputCall.Stub = func(output string) (string, error) {
+				if output == "lb_vault_lb_ip" {
+					return "", errors.New("failed to get lb_vault_lb_ip")
+				}
+				return "", nil
+			}
+
+			_, err := outputGenerator.Generate(storage.State{
+				IAAS:    "gcp",
+				TFState: "some-tf-state",
+				LBs: []storage.LBSpec{
+					{Name: "vault"},
+				},
+			})
+			Expect(err).To(MatchError("failed to get lb_vault_lb_ip"))
+		})
+	})
+
 	Context("when tfState is empty", func() {
 		BeforeEach(func() {
 			executor.OutputCall.Stub = func(output string) (string, error) {
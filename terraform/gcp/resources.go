@@ -0,0 +1,43 @@
+package gcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// NewResources returns the number of resources of each type that applying
+// the template creates, that is the resources of the template that are not
+// in the terraform state yet.
+func NewResources(template, tfState string) (map[string]int, error) {
+	existing := map[string]bool{}
+	if tfState != "" {
+		var state struct {
+			Modules []struct {
+				Resources map[string]json.RawMessage `json:"resources"`
+			} `json:"modules"`
+		}
+		err := json.Unmarshal([]byte(tfState), &state)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the terraform state: %s", err)
+		}
+
+		for _, module := range state.Modules {
+			for key := range module.Resources {
+				parts := strings.SplitN(key, ".", 3)
+				if len(parts) >= 2 {
+					existing[parts[0]+"."+parts[1]] = true
+				}
+			}
+		}
+	}
+
+	resources := map[string]int{}
+	for _, match := range resourceRegexp.FindAllStringSubmatch(template, -1) {
+		if !existing[match[1]+"."+match[2]] {
+			resources[match[1]]++
+		}
+	}
+
+	return resources, nil
+}
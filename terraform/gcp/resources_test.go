@@ -0,0 +1,62 @@
+package gcp_test
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/terraform/gcp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewResources", func() {
+	var template string
+
+	BeforeEach(func() {
+		template = `resource "google_compute_network" "bbl-network" {
+}
+
+resource "google_compute_address" "bosh-external-ip" {
+}
+
+resource "google_compute_address" "cf-ws" {
+}
+
+resource "google_compute_forwarding_rule" "cf-ws-https" {
+}
+`
+	})
+
+	It("counts the resources of each type in the template", func() {
+		resources, err := gcp.NewResources(template, "")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(resources).To(Equal(map[string]int{
+			"google_compute_network":         1,
+			"google_compute_address":         2,
+			"google_compute_forwarding_rule": 1,
+		}))
+	})
+
+	It("leaves out the resources that are in the terraform state", func() {
+		resources, err := gcp.NewResources(template, `{
+			"version": 3,
+			"modules": [{
+				"path": ["root"],
+				"resources": {
+					"google_compute_network.bbl-network": {},
+					"google_compute_address.bosh-external-ip": {}
+				}
+			}]
+		}`)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(resources).To(Equal(map[string]int{
+			"google_compute_address":         1,
+			"google_compute_forwarding_rule": 1,
+		}))
+	})
+
+	It("returns an error when the terraform state cannot be parsed", func() {
+		_, err := gcp.NewResources(template, "%%%")
+		Expect(err).To(MatchError("failed to parse the terraform state: invalid character '%' looking for beginning of value"))
+	})
+})
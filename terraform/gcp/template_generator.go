@@ -2,6 +2,7 @@ package gcp
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/cloudfoundry/bosh-bootloader/storage"
@@ -26,6 +27,26 @@ const backendBase = `resource "google_compute_backend_service" "router-lb-backen
 }
 `
 
+const dnsDelegationTemplate = `variable "parent_dns_zone" {
+  type = "string"
+}
+
+resource "google_dns_record_set" "env_dns_zone_delegation" {
+  name = "${google_dns_managed_zone.env_dns_zone.dns_name}"
+  type = "NS"
+  ttl  = 300
+
+  managed_zone = "${var.parent_dns_zone}"
+
+  rrdatas = ["${google_dns_managed_zone.env_dns_zone.name_servers}"]
+}
+`
+
+const labelsTemplate = `variable "labels" {
+  type = "map"
+}
+`
+
 func NewTemplateGenerator(zones zones) TemplateGenerator {
 	return TemplateGenerator{
 		zones: zones,
@@ -41,12 +62,256 @@ func (t TemplateGenerator) Generate(state storage.State) string {
 		instanceGroups := t.GenerateInstanceGroups(state.GCP.Region)
 		backendService := t.GenerateBackendService(state.GCP.Region)
 
-		template = strings.Join([]string{template, CFLBTemplate, instanceGroups, backendService}, "\n")
+		template = strings.Join([]string{template, cfLBTemplate(len(state.LB.SNICertificates)), instanceGroups, backendService}, "\n")
 
 		if state.LB.Domain != "" {
-			template = strings.Join([]string{template, CFDNSTemplate}, "\n")
+			template = strings.Join([]string{template, dnsTemplate(state.LB.ParentZone, state.LB.DNSRecords)}, "\n")
+		}
+	}
+
+	for _, spec := range state.LBs {
+		switch spec.Type {
+		case "cf":
+			template = strings.Join([]string{template, t.GenerateNamedCFLB(state.GCP.Region, spec)}, "\n")
+		case "concourse":
+			template = strings.Join([]string{template, t.GenerateLBSpec(concourseLBSpec(spec.Name))}, "\n")
+		default:
+			template = strings.Join([]string{template, t.GenerateLBSpec(spec)}, "\n")
 		}
 	}
+
+	if len(state.Tags) > 0 {
+		template = labelTemplate(template)
+	}
+
+	return template
+}
+
+// labelTemplate labels the resources of the template with the tags of the
+// environment. Of the resources bbl creates, the google provider only
+// accepts labels on the cloud dns zones.
+func labelTemplate(template string) string {
+	template = dnsZoneRegexp.ReplaceAllString(template, "$0\n  labels = \"${var.labels}\"")
+
+	return strings.Join([]string{template, labelsTemplate}, "\n")
+}
+
+// LBSpecOutputName returns the name of a terraform output of the load
+// balancer generated for the given spec, e.g. "lb_credhub_uaa_target_pool".
+func LBSpecOutputName(specName, output string) string {
+	return fmt.Sprintf("lb_%s_%s", strings.Replace(specName, "-", "_", -1), output)
+}
+
+var (
+	resourceRegexp = regexp.MustCompile(`resource "([a-z_]+)" "([a-z0-9_-]+)"`)
+	variableRegexp = regexp.MustCompile(`variable "([a-z0-9_]+)"`)
+	outputRegexp   = regexp.MustCompile(`output "([a-z_]+)"`)
+	dnsZoneRegexp  = regexp.MustCompile(`resource "google_dns_managed_zone" "[a-z0-9_-]+" \{`)
+)
+
+const cfLBCertificate = `${google_compute_ssl_certificate.cf-cert.self_link}`
+
+// cfLBTemplate returns the cf lb template whose https proxy serves the
+// given number of SNI certificates next to its default certificate.
+func cfLBTemplate(sniCertificates int) string {
+	if sniCertificates == 0 {
+		return CFLBTemplate
+	}
+
+	certificates := []string{fmt.Sprintf("%q", cfLBCertificate)}
+	var sniTemplates []string
+	for i := 0; i < sniCertificates; i++ {
+		certificates = append(certificates, fmt.Sprintf(`"${google_compute_ssl_certificate.cf-sni-cert-%d.self_link}"`, i))
+		sniTemplates = append(sniTemplates, fmt.Sprintf(`variable "sni_ssl_certificate_%[1]d" {
+  type = "string"
+}
+
+variable "sni_ssl_certificate_private_key_%[1]d" {
+  type = "string"
+}
+
+resource "google_compute_ssl_certificate" "cf-sni-cert-%[1]d" {
+  name_prefix = "${var.env_id}-sni-%[1]d-"
+  description = "user provided ssl private key / ssl certificate pair served through sni"
+  private_key = "${file(var.sni_ssl_certificate_private_key_%[1]d)}"
+  certificate = "${file(var.sni_ssl_certificate_%[1]d)}"
+  lifecycle {
+	create_before_destroy = true
+  }
+}
+`, i))
+	}
+
+	template := strings.Replace(CFLBTemplate,
+		fmt.Sprintf("ssl_certificates = [%q]", cfLBCertificate),
+		fmt.Sprintf("ssl_certificates = [%s]", strings.Join(certificates, ", ")), 1)
+
+	return strings.Join(append([]string{template}, sniTemplates...), "\n")
+}
+
+// GenerateNamedCFLB returns the cf lb template with its resources,
+// variables, outputs and cloud resource names scoped to the named lb, so
+// that it does not clash with the unnamed cf lb or other named cf lbs.
+// Variables and outputs are named like the outputs of lb specs.
+func (t TemplateGenerator) GenerateNamedCFLB(region string, spec storage.LBSpec) string {
+	parts := []string{cfLBTemplate(len(spec.SNICertificates)), t.GenerateInstanceGroups(region), t.GenerateBackendService(region)}
+	if spec.Domain != "" {
+		parts = append(parts, dnsTemplate(spec.ParentZone, spec.DNSRecords))
+	}
+	template := strings.Join(parts, "\n")
+
+	for _, match := range resourceRegexp.FindAllStringSubmatch(template, -1) {
+		resourceType, resourceName := regexp.QuoteMeta(match[1]), regexp.QuoteMeta(match[2])
+
+		declaration := regexp.MustCompile(fmt.Sprintf(`resource "%s" "%s"`, resourceType, resourceName))
+		template = declaration.ReplaceAllString(template, fmt.Sprintf(`resource "%s" "%s-%s"`, match[1], spec.Name, match[2]))
+
+		reference := regexp.MustCompile(fmt.Sprintf(`\b%s\.%s([."])`, resourceType, resourceName))
+		template = reference.ReplaceAllString(template, fmt.Sprintf("%s.%s-%s$1", match[1], spec.Name, match[2]))
+	}
+
+	for _, match := range variableRegexp.FindAllStringSubmatch(template, -1) {
+		name := LBSpecOutputName(spec.Name, match[1])
+		template = strings.Replace(template, match[0], fmt.Sprintf(`variable "%s"`, name), -1)
+		template = regexp.MustCompile(fmt.Sprintf(`\bvar\.%s\b`, match[1])).ReplaceAllString(template, "var."+name)
+	}
+
+	template = outputRegexp.ReplaceAllStringFunc(template, func(output string) string {
+		return fmt.Sprintf(`output "%s"`, LBSpecOutputName(spec.Name, outputRegexp.FindStringSubmatch(output)[1]))
+	})
+
+	template = strings.Replace(template, `"${var.env_id}-`, fmt.Sprintf(`"${var.env_id}-%s-`, spec.Name), -1)
+	template = strings.Replace(template, `"${var.env_id}"`, fmt.Sprintf(`"${var.env_id}-%s"`, spec.Name), -1)
+
+	return template
+}
+
+// dnsTemplate returns the dns zone of a cf lb along with the NS records
+// that delegate it from its parent zone and its custom records.
+func dnsTemplate(parentZone string, records []storage.DNSRecord) string {
+	parts := []string{CFDNSTemplate}
+	if parentZone != "" {
+		parts = append(parts, dnsDelegationTemplate)
+	}
+
+	for i, record := range records {
+		name := "${google_dns_managed_zone.env_dns_zone.dns_name}"
+		if record.Name != "@" {
+			name = record.Name + "." + name
+		}
+
+		var rrdatas []string
+		for _, value := range record.Records {
+			if record.Type == "TXT" && !strings.HasPrefix(value, `"`) {
+				value = fmt.Sprintf("%q", value)
+			}
+			rrdatas = append(rrdatas, fmt.Sprintf("%q", value))
+		}
+
+		parts = append(parts, fmt.Sprintf(`resource "google_dns_record_set" "dns-record-%d" {
+  name = "%s"
+  type = "%s"
+  ttl  = %d
+
+  managed_zone = "${google_dns_managed_zone.env_dns_zone.name}"
+
+  rrdatas = [%s]
+}
+`, i, name, record.Type, record.TTL, strings.Join(rrdatas, ", ")))
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+// concourseLBSpec is the network load balancer of a named concourse lb,
+// which passes https and ssh through to the web instances like the
+// unnamed concourse lb.
+func concourseLBSpec(name string) storage.LBSpec {
+	return storage.LBSpec{
+		Name: name,
+		Ports: []storage.LBPort{
+			{Port: 443, InstancePort: 443, Protocol: "tcp"},
+			{Port: 2222, InstancePort: 2222, Protocol: "tcp"},
+		},
+		HealthCheck: storage.LBHealthCheck{Protocol: "tcp", Port: 443},
+	}
+}
+
+// GenerateLBSpec returns a network load balancer for the spec: a target
+// pool behind one forwarding rule per port, sharing a single address.
+func (t TemplateGenerator) GenerateLBSpec(spec storage.LBSpec) string {
+	healthCheckPortOpen := false
+	firewallPorts := []string{}
+	for _, port := range spec.Ports {
+		firewallPorts = append(firewallPorts, fmt.Sprintf(`"%d"`, port.Port))
+		if port.Port == spec.HealthCheck.Port {
+			healthCheckPortOpen = true
+		}
+	}
+
+	if !healthCheckPortOpen {
+		firewallPorts = append(firewallPorts, fmt.Sprintf(`"%d"`, spec.HealthCheck.Port))
+	}
+
+	template := fmt.Sprintf(`output "%[2]s" {
+  value = "${google_compute_target_pool.lb-%[1]s.name}"
+}
+
+output "%[3]s" {
+  value = "${google_compute_address.lb-%[1]s.address}"
+}
+
+resource "google_compute_firewall" "lb-%[1]s" {
+  name    = "${var.env_id}-%[1]s-open"
+  network = "${google_compute_network.bbl-network.name}"
+
+  allow {
+    protocol = "tcp"
+    ports    = [%[4]s]
+  }
+
+  target_tags = ["${google_compute_target_pool.lb-%[1]s.name}"]
+}
+
+resource "google_compute_address" "lb-%[1]s" {
+  name = "${var.env_id}-%[1]s"
+}
+`, spec.Name, LBSpecOutputName(spec.Name, "target_pool"), LBSpecOutputName(spec.Name, "lb_ip"), strings.Join(firewallPorts, ", "))
+
+	if spec.HealthCheck.Protocol == "http" {
+		template += fmt.Sprintf(`
+resource "google_compute_http_health_check" "lb-%[1]s" {
+  name         = "${var.env_id}-%[1]s"
+  port         = %[2]d
+  request_path = "%[3]s"
+}
+
+resource "google_compute_target_pool" "lb-%[1]s" {
+  name = "${var.env_id}-%[1]s"
+
+  health_checks = ["${google_compute_http_health_check.lb-%[1]s.name}"]
+}
+`, spec.Name, spec.HealthCheck.Port, spec.HealthCheck.Path)
+	} else {
+		template += fmt.Sprintf(`
+resource "google_compute_target_pool" "lb-%[1]s" {
+  name = "${var.env_id}-%[1]s"
+}
+`, spec.Name)
+	}
+
+	for _, port := range spec.Ports {
+		template += fmt.Sprintf(`
+resource "google_compute_forwarding_rule" "lb-%[1]s-%[2]d" {
+  name        = "${var.env_id}-%[1]s-%[2]d"
+  target      = "${google_compute_target_pool.lb-%[1]s.self_link}"
+  port_range  = "%[2]d"
+  ip_protocol = "TCP"
+  ip_address  = "${google_compute_address.lb-%[1]s.address}"
+}
+`, spec.Name, port.Port)
+	}
+
 	return template
 }
 
@@ -49,6 +49,225 @@ var _ = Describe("TemplateGenerator", func() {
 			Entry("when a cf lb type is provided", "fixtures/gcp_template_cf_lb.tf", "some-region", "cf", ""),
 			Entry("when a cf lb type is provided with a domain", "fixtures/gcp_template_cf_lb_dns.tf", "some-region", "cf", "some-domain"),
 		)
+
+		It("labels the dns zones with the tags of the environment", func() {
+			template := templateGenerator.Generate(storage.State{
+				GCP:  storage.GCP{Region: "some-region"},
+				LB:   storage.LB{Type: "cf", Domain: "some-domain"},
+				LBs:  []storage.LBSpec{{Name: "other", Type: "cf", Domain: "other-domain"}},
+				Tags: map[string]string{"owner": "platform"},
+			})
+
+			Expect(template).To(ContainSubstring("resource \"google_dns_managed_zone\" \"env_dns_zone\" {\n  labels = \"${var.labels}\"\n"))
+			Expect(template).To(ContainSubstring("resource \"google_dns_managed_zone\" \"other-env_dns_zone\" {\n  labels = \"${var.labels}\"\n"))
+			Expect(template).To(ContainSubstring("variable \"labels\" {\n  type = \"map\"\n}\n"))
+			Expect(template).NotTo(MatchRegexp(`resource "google_compute_[a-z_]+" "[a-z0-9_-]+" \{\n  labels`))
+		})
+
+		It("leaves out the labels without tags", func() {
+			template := templateGenerator.Generate(storage.State{
+				GCP: storage.GCP{Region: "some-region"},
+				LB:  storage.LB{Type: "cf", Domain: "some-domain"},
+			})
+
+			Expect(template).NotTo(ContainSubstring("labels"))
+		})
+	})
+
+	Describe("GenerateLBSpec", func() {
+		It("returns a network load balancer with an http health check", func() {
+			expectedTemplate, err := ioutil.ReadFile("fixtures/lb_spec.tf")
+			Expect(err).NotTo(HaveOccurred())
+
+			template := templateGenerator.GenerateLBSpec(storage.LBSpec{
+				Name: "credhub-uaa",
+				Ports: []storage.LBPort{
+					{Port: 8844, InstancePort: 8844, Protocol: "tcp"},
+					{Port: 8443, InstancePort: 8443, Protocol: "tcp"},
+				},
+				HealthCheck: storage.LBHealthCheck{
+					Protocol: "http",
+					Port:     8080,
+					Path:     "/healthz",
+				},
+			})
+			Expect(template).To(Equal(string(expectedTemplate)))
+		})
+
+		It("leaves the target pool without a health check for tcp health checks", func() {
+			template := templateGenerator.GenerateLBSpec(storage.LBSpec{
+				Name:        "vault",
+				Ports:       []storage.LBPort{{Port: 8200, InstancePort: 8200, Protocol: "tcp"}},
+				HealthCheck: storage.LBHealthCheck{Protocol: "tcp", Port: 8200},
+			})
+
+			Expect(template).To(ContainSubstring(`ports    = ["8200"]`))
+			Expect(template).To(ContainSubstring(`resource "google_compute_forwarding_rule" "lb-vault-8200"`))
+			Expect(template).NotTo(ContainSubstring("google_compute_http_health_check"))
+		})
+
+		It("is included in the generated template for every lb spec", func() {
+			template := templateGenerator.Generate(storage.State{
+				LB: storage.LB{
+					Type: "concourse",
+				},
+				LBs: []storage.LBSpec{
+					{
+						Name:        "vault",
+						Ports:       []storage.LBPort{{Port: 8200, InstancePort: 8200, Protocol: "tcp"}},
+						HealthCheck: storage.LBHealthCheck{Protocol: "tcp", Port: 8200},
+					},
+				},
+			})
+
+			Expect(template).To(ContainSubstring(`output "concourse_target_pool"`))
+			Expect(template).To(ContainSubstring(`output "lb_vault_target_pool"`))
+		})
+	})
+
+	Describe("GenerateNamedCFLB", func() {
+		BeforeEach(func() {
+			zones.GetCall.Returns.Zones = []string{"z1", "z2"}
+		})
+
+		It("returns the cf lb template scoped to the name of the lb", func() {
+			expectedTemplate, err := ioutil.ReadFile("fixtures/named_cf_lb_dns.tf")
+			Expect(err).NotTo(HaveOccurred())
+
+			template := templateGenerator.GenerateNamedCFLB("some-region", storage.LBSpec{
+				Name:   "foundation",
+				Type:   "cf",
+				Domain: "some-domain",
+			})
+
+			Expect(zones.GetCall.Receives.Region).To(Equal("some-region"))
+			Expect(template).To(Equal(string(expectedTemplate)))
+		})
+
+		It("leaves out the dns zone without a domain", func() {
+			template := templateGenerator.GenerateNamedCFLB("some-region", storage.LBSpec{
+				Name: "foundation",
+				Type: "cf",
+			})
+
+			Expect(template).To(ContainSubstring(`output "lb_foundation_router_backend_service"`))
+			Expect(template).NotTo(ContainSubstring("google_dns_managed_zone"))
+		})
+
+		It("is included in the generated template next to the unnamed cf lb", func() {
+			template := templateGenerator.Generate(storage.State{
+				LB: storage.LB{
+					Type: "cf",
+				},
+				LBs: []storage.LBSpec{
+					{Name: "foundation", Type: "cf"},
+					{Name: "ci", Type: "concourse"},
+				},
+			})
+
+			Expect(template).To(ContainSubstring(`output "router_backend_service"`))
+			Expect(template).To(ContainSubstring(`output "lb_foundation_router_backend_service"`))
+			Expect(template).To(ContainSubstring(`resource "google_compute_target_pool" "lb-ci"`))
+			Expect(template).To(ContainSubstring(`ports    = ["443", "2222"]`))
+		})
+	})
+
+	Describe("sni certificates", func() {
+		It("adds a certificate to the https proxy of the cf lb for each sni certificate", func() {
+			template := templateGenerator.Generate(storage.State{
+				LB: storage.LB{
+					Type:            "cf",
+					SNICertificates: []storage.LBCertificate{{Cert: "some-cert"}, {Cert: "other-cert"}},
+				},
+			})
+
+			Expect(template).To(ContainSubstring(`ssl_certificates = ["${google_compute_ssl_certificate.cf-cert.self_link}", "${google_compute_ssl_certificate.cf-sni-cert-0.self_link}", "${google_compute_ssl_certificate.cf-sni-cert-1.self_link}"]`))
+			Expect(template).To(ContainSubstring(`variable "sni_ssl_certificate_1"`))
+			Expect(template).To(ContainSubstring(`certificate = "${file(var.sni_ssl_certificate_1)}"`))
+		})
+
+		It("scopes the sni certificates of a named cf lb to its name", func() {
+			template := templateGenerator.GenerateNamedCFLB("some-region", storage.LBSpec{
+				Name:            "foundation",
+				Type:            "cf",
+				SNICertificates: []storage.LBCertificate{{Cert: "some-cert"}},
+			})
+
+			Expect(template).To(ContainSubstring(`ssl_certificates = ["${google_compute_ssl_certificate.foundation-cf-cert.self_link}", "${google_compute_ssl_certificate.foundation-cf-sni-cert-0.self_link}"]`))
+			Expect(template).To(ContainSubstring(`variable "lb_foundation_sni_ssl_certificate_private_key_0"`))
+			Expect(template).To(ContainSubstring(`private_key = "${file(var.lb_foundation_sni_ssl_certificate_private_key_0)}"`))
+			Expect(template).To(ContainSubstring(`name_prefix = "${var.env_id}-foundation-sni-0-"`))
+		})
+	})
+
+	Describe("dns", func() {
+		It("delegates the dns zone from the parent zone and adds the custom records", func() {
+			template := templateGenerator.Generate(storage.State{
+				LB: storage.LB{
+					Type:       "cf",
+					Domain:     "some-domain",
+					ParentZone: "some-parent-zone",
+					DNSRecords: []storage.DNSRecord{
+						{Name: "api", Type: "CNAME", TTL: 300, Records: []string{"api.example.com."}},
+						{Name: "@", Type: "TXT", TTL: 60, Records: []string{"v=spf1 -all", `"quoted"`}},
+					},
+				},
+			})
+
+			Expect(template).To(ContainSubstring(`variable "parent_dns_zone"`))
+			Expect(template).To(ContainSubstring(`resource "google_dns_record_set" "env_dns_zone_delegation" {
+  name = "${google_dns_managed_zone.env_dns_zone.dns_name}"
+  type = "NS"
+  ttl  = 300
+
+  managed_zone = "${var.parent_dns_zone}"
+
+  rrdatas = ["${google_dns_managed_zone.env_dns_zone.name_servers}"]
+}`))
+			Expect(template).To(ContainSubstring(`resource "google_dns_record_set" "dns-record-0" {
+  name = "api.${google_dns_managed_zone.env_dns_zone.dns_name}"
+  type = "CNAME"
+  ttl  = 300
+
+  managed_zone = "${google_dns_managed_zone.env_dns_zone.name}"
+
+  rrdatas = ["api.example.com."]
+}`))
+			Expect(template).To(ContainSubstring(`resource "google_dns_record_set" "dns-record-1" {
+  name = "${google_dns_managed_zone.env_dns_zone.dns_name}"
+  type = "TXT"
+  ttl  = 60
+
+  managed_zone = "${google_dns_managed_zone.env_dns_zone.name}"
+
+  rrdatas = ["\"v=spf1 -all\"", "\"quoted\""]
+}`))
+		})
+
+		It("leaves out the delegation without a parent zone", func() {
+			template := templateGenerator.Generate(storage.State{
+				LB: storage.LB{Type: "cf", Domain: "some-domain"},
+			})
+
+			Expect(template).To(ContainSubstring("google_dns_managed_zone"))
+			Expect(template).NotTo(ContainSubstring("parent_dns_zone"))
+		})
+
+		It("scopes the delegation and records of a named cf lb to its name", func() {
+			template := templateGenerator.GenerateNamedCFLB("some-region", storage.LBSpec{
+				Name:       "foundation",
+				Type:       "cf",
+				Domain:     "some-domain",
+				ParentZone: "some-parent-zone",
+				DNSRecords: []storage.DNSRecord{{Name: "api", Type: "A", TTL: 300, Records: []string{"10.0.0.1"}}},
+			})
+
+			Expect(template).To(ContainSubstring(`variable "lb_foundation_parent_dns_zone"`))
+			Expect(template).To(ContainSubstring(`resource "google_dns_record_set" "foundation-env_dns_zone_delegation"`))
+			Expect(template).To(ContainSubstring(`managed_zone = "${var.lb_foundation_parent_dns_zone}"`))
+			Expect(template).To(ContainSubstring(`resource "google_dns_record_set" "foundation-dns-record-0"`))
+			Expect(template).To(ContainSubstring(`name = "api.${google_dns_managed_zone.foundation-env_dns_zone.dns_name}"`))
+		})
 	})
 
 	Describe("GenerateBackendService", func() {
@@ -2,6 +2,8 @@ package terraform
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
@@ -28,3 +30,21 @@ func (i InputGenerator) Generate(state storage.State) (map[string]string, error)
 		return map[string]string{}, fmt.Errorf("invalid iaas: %q", state.IAAS)
 	}
 }
+
+// MapVar formats a map as the value of a terraform map variable passed with
+// -var, e.g. {"cost-center"="1234", "owner"="platform"}, sorting the keys so
+// that the value is stable.
+func MapVar(values map[string]string) string {
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%q=%q", key, values[key]))
+	}
+
+	return fmt.Sprintf("{%s}", strings.Join(pairs, ", "))
+}
@@ -79,4 +79,17 @@ var _ = Describe("InputGenerator", func() {
 			})
 		})
 	})
+
+	Describe("MapVar", func() {
+		It("formats the map as a terraform map sorted by key", func() {
+			Expect(terraform.MapVar(map[string]string{
+				"owner":       "platform",
+				"cost-center": "1234",
+			})).To(Equal(`{"cost-center"="1234", "owner"="platform"}`))
+		})
+
+		It("formats an empty map", func() {
+			Expect(terraform.MapVar(map[string]string{})).To(Equal("{}"))
+		})
+	})
 })